@@ -0,0 +1,172 @@
+package commoncfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// AWSService defines which AWS service holds the value.
+type AWSService string
+
+const (
+	AWSSecretsManager AWSService = "secretsmanager"
+	AWSParameterStore AWSService = "ssm"
+
+	DefaultAWSTimeout     = 10 * time.Second
+	DefaultAWSSessionName = "common-sdk"
+)
+
+var (
+	ErrAWSNameMissing        = errors.New("aws secret or parameter name is missing")
+	ErrAWSServiceUnsupported = errors.New("unsupported aws service")
+	ErrAWSValueEmpty         = errors.New("aws returned no value")
+)
+
+// AWSSource describes a value stored in AWS Secrets Manager or SSM Parameter Store.
+// Credentials are resolved through the default AWS credential chain (environment,
+// shared config, web identity on EKS, instance profile on EC2) and can optionally
+// be exchanged for the credentials of another IAM role.
+type AWSSource struct {
+	Service AWSService `yaml:"service" json:"service" default:"secretsmanager" mapstructure:"service" validate:"oneof=secretsmanager ssm"`
+	// Region of the secret or parameter. Falls back to the region of the AWS environment.
+	Region string `yaml:"region" json:"region" mapstructure:"region"`
+	// Name is the secret ID or ARN for Secrets Manager, or the parameter name for SSM.
+	Name string `yaml:"name" json:"name" mapstructure:"name"`
+	// VersionStage selects the Secrets Manager version stage, defaults to AWSCURRENT.
+	VersionStage string `yaml:"versionStage" json:"versionStage" mapstructure:"versionStage"`
+	// JSONPath extracts a single value if the secret is a JSON document, e.g. $.password.
+	JSONPath string `yaml:"jsonPath" json:"jsonPath" mapstructure:"jsonPath"`
+	// RoleARN is an optional IAM role assumed before reading the value.
+	RoleARN string `yaml:"roleArn" json:"roleArn" mapstructure:"roleArn"`
+	// ExternalID is passed when assuming RoleARN.
+	ExternalID string `yaml:"externalId" json:"externalId" mapstructure:"externalId"`
+	// SessionName is the role session name used when assuming RoleARN.
+	SessionName string `yaml:"sessionName" json:"sessionName" mapstructure:"sessionName"`
+	// Endpoint overrides the AWS service endpoint, e.g. for VPC endpoints or local testing.
+	Endpoint string `yaml:"endpoint" json:"endpoint" mapstructure:"endpoint"`
+	// Timeout bounds the whole lookup including role assumption.
+	Timeout time.Duration `yaml:"timeout" json:"timeout" mapstructure:"timeout"`
+}
+
+// loadFromAWS reads the value referenced by src from Secrets Manager or SSM Parameter Store.
+func loadFromAWS(src *AWSSource) ([]byte, error) {
+	if strings.TrimSpace(src.Name) == "" {
+		return nil, ErrAWSNameMissing
+	}
+
+	timeout := src.Timeout
+	if timeout <= 0 {
+		timeout = DefaultAWSTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	awsCfg, err := newAWSConfig(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var value string
+
+	switch src.Service {
+	case AWSSecretsManager, "":
+		value, err = getAWSSecret(ctx, secretsmanager.NewFromConfig(awsCfg), src)
+	case AWSParameterStore:
+		value, err = getAWSParameter(ctx, ssm.NewFromConfig(awsCfg), src)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrAWSServiceUnsupported, src.Service)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(src.JSONPath) != "" {
+		return jsonQuery(value, src.JSONPath)
+	}
+
+	return []byte(value), nil
+}
+
+func newAWSConfig(ctx context.Context, src *AWSSource) (aws.Config, error) {
+	opts := make([]func(*config.LoadOptions) error, 0, 2)
+	if src.Region != "" {
+		opts = append(opts, config.WithRegion(src.Region))
+	}
+
+	if src.Endpoint != "" {
+		opts = append(opts, config.WithBaseEndpoint(src.Endpoint))
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	if src.RoleARN != "" {
+		sessionName := src.SessionName
+		if sessionName == "" {
+			sessionName = DefaultAWSSessionName
+		}
+
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsCfg), src.RoleARN,
+			func(o *stscreds.AssumeRoleOptions) {
+				o.RoleSessionName = sessionName
+				if src.ExternalID != "" {
+					o.ExternalID = aws.String(src.ExternalID)
+				}
+			},
+		)
+		awsCfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return awsCfg, nil
+}
+
+func getAWSSecret(ctx context.Context, client *secretsmanager.Client, src *AWSSource) (string, error) {
+	input := &secretsmanager.GetSecretValueInput{SecretId: aws.String(src.Name)}
+	if src.VersionStage != "" {
+		input.VersionStage = aws.String(src.VersionStage)
+	}
+
+	out, err := client.GetSecretValue(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", src.Name, err)
+	}
+
+	switch {
+	case out.SecretString != nil:
+		return *out.SecretString, nil
+	case out.SecretBinary != nil:
+		return string(out.SecretBinary), nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrAWSValueEmpty, src.Name)
+}
+
+func getAWSParameter(ctx context.Context, client *ssm.Client, src *AWSSource) (string, error) {
+	out, err := client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(src.Name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get parameter %s: %w", src.Name, err)
+	}
+
+	if out.Parameter == nil || out.Parameter.Value == nil {
+		return "", fmt.Errorf("%w: %s", ErrAWSValueEmpty, src.Name)
+	}
+
+	return *out.Parameter.Value, nil
+}
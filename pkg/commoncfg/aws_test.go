@@ -0,0 +1,168 @@
+package commoncfg_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+const assumeRoleResponse = `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASSUMEDKEY</AccessKeyId>
+      <SecretAccessKey>assumed-secret</SecretAccessKey>
+      <SessionToken>assumed-token</SessionToken>
+      <Expiration>2099-01-01T00:00:00Z</Expiration>
+    </Credentials>
+    <AssumedRoleUser>
+      <Arn>arn:aws:sts::123456789012:assumed-role/reader/common-sdk</Arn>
+      <AssumedRoleId>ARO123:common-sdk</AssumedRoleId>
+    </AssumedRoleUser>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`
+
+// newAWSServer emulates the Secrets Manager, SSM and STS APIs on a single endpoint.
+func newAWSServer(t *testing.T, assumed *atomic.Bool) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") == "" {
+			// STS uses the query protocol without a target header.
+			assumed.Store(true)
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = w.Write([]byte(assumeRoleResponse))
+
+			return
+		}
+
+		if assumed.Load() && !strings.Contains(r.Header.Get("Authorization"), "Credential=ASSUMEDKEY/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var body map[string]any
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "secretsmanager.GetSecretValue":
+			switch body["SecretId"] {
+			case "plain":
+				_, _ = w.Write([]byte(`{"Name":"plain","SecretString":"s3cr3t"}`))
+			case "json":
+				_, _ = w.Write([]byte(`{"Name":"json","SecretString":"{\"password\":\"from-json\"}"}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"not found"}`))
+			}
+		case "AmazonSSM.GetParameter":
+			if body["WithDecryption"] != true {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"Parameter":{"Name":"/app/token","Type":"SecureString","Value":"param-value"}}`))
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestExtractValueFromSourceRef_AWS(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "STATICKEY")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "static-secret")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+
+	tests := []struct {
+		name         string
+		aws          commoncfg.AWSSource
+		want         string
+		wantAssumed  bool
+		wantErr      bool
+		wantSentinel error
+	}{
+		{
+			name: "secrets manager string",
+			aws:  commoncfg.AWSSource{Service: commoncfg.AWSSecretsManager, Name: "plain"},
+			want: "s3cr3t",
+		},
+		{
+			name: "secrets manager json path",
+			aws:  commoncfg.AWSSource{Name: "json", JSONPath: "$.password"},
+			want: "from-json",
+		},
+		{
+			name: "ssm parameter",
+			aws:  commoncfg.AWSSource{Service: commoncfg.AWSParameterStore, Name: "/app/token"},
+			want: "param-value",
+		},
+		{
+			name:        "assume role",
+			aws:         commoncfg.AWSSource{Name: "plain", RoleARN: "arn:aws:iam::123456789012:role/reader", ExternalID: "ext"},
+			want:        "s3cr3t",
+			wantAssumed: true,
+		},
+		{
+			name:    "unknown secret",
+			aws:     commoncfg.AWSSource{Name: "missing"},
+			wantErr: true,
+		},
+		{
+			name:         "missing name",
+			aws:          commoncfg.AWSSource{},
+			wantErr:      true,
+			wantSentinel: commoncfg.ErrAWSNameMissing,
+		},
+		{
+			name:         "unsupported service",
+			aws:          commoncfg.AWSSource{Service: "s3", Name: "plain"},
+			wantErr:      true,
+			wantSentinel: commoncfg.ErrAWSServiceUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assumed atomic.Bool
+
+			server := newAWSServer(t, &assumed)
+
+			src := tt.aws
+			src.Region = "eu-central-1"
+			src.Endpoint = server.URL
+
+			value, err := commoncfg.ExtractValueFromSourceRef(&commoncfg.SourceRef{
+				Source: commoncfg.AWSSourceValue,
+				AWS:    src,
+			})
+
+			if tt.wantErr {
+				require.Error(t, err)
+
+				if tt.wantSentinel != nil {
+					require.ErrorIs(t, err, tt.wantSentinel)
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(value))
+			assert.Equal(t, tt.wantAssumed, assumed.Load())
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package commoncfg
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoCACertificates is returned when a CA bundle directory holds no PEM certificates.
+var ErrNoCACertificates = errors.New("no CA certificates found")
+
+// caBundleExts are the file extensions of the PEM files of a CA bundle directory.
+var caBundleExts = []string{".pem", ".crt", ".cer"}
+
+// appendCAs appends the CA certificates referenced by certRef to the pool. A file source
+// whose path is a directory references all PEM files of the directory, e.g. trust anchors
+// delivered as several rotating files. Hidden files and directories are skipped, so the
+// ..data links of mounted Kubernetes secrets and config maps are only read once.
+func appendCAs(pool *x509.CertPool, certRef *SourceRef) error {
+	if certRef.Source == FileSourceValue {
+		info, err := os.Stat(certRef.File.Path)
+		if err == nil && info.IsDir() {
+			return appendCADir(pool, certRef)
+		}
+	}
+
+	caCert, err := ExtractValueFromSourceRef(certRef)
+	if err != nil {
+		return err
+	}
+
+	pool.AppendCertsFromPEM(caCert)
+
+	return nil
+}
+
+// appendCADir appends the certificates of all PEM files of the directory of certRef.
+func appendCADir(pool *x509.CertPool, certRef *SourceRef) error {
+	dir := certRef.File.Path
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	found := false
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || !isCABundleFile(name) {
+			continue
+		}
+
+		// entries of mounted volumes are symlinks, so stat the target
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		fileRef := *certRef
+		fileRef.File.Path = filepath.Join(dir, name)
+
+		caCert, err := ExtractValueFromSourceRef(&fileRef)
+		if err != nil {
+			return fmt.Errorf("reading CA file %s: %w", fileRef.File.Path, err)
+		}
+
+		if pool.AppendCertsFromPEM(caCert) {
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("%w in directory %s", ErrNoCACertificates, dir)
+	}
+
+	return nil
+}
+
+func isCABundleFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range caBundleExts {
+		if ext == e {
+			return true
+		}
+	}
+
+	return false
+}
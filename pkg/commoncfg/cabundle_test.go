@@ -0,0 +1,65 @@
+package commoncfg_test
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestLoadCACertPool_Directory(t *testing.T) {
+	certA, _ := generateTestCert(t)
+	certB, _ := generateTestCert(t)
+	ignored, _ := generateTestCert(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.pem"), certA, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.crt"), certB, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a certificate"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden.pem"), ignored, 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub.pem"), 0o700))
+
+	expected := x509.NewCertPool()
+	expected.AppendCertsFromPEM(certA)
+	expected.AppendCertsFromPEM(certB)
+
+	ref := commoncfg.SourceRef{
+		Source: commoncfg.FileSourceValue,
+		File:   commoncfg.CredentialFile{Path: dir, Format: commoncfg.BinaryFileFormat},
+	}
+
+	t.Run("merges the PEM files of the directory", func(t *testing.T) {
+		pool, err := commoncfg.LoadCACertPool(&ref)
+		require.NoError(t, err)
+		assert.True(t, expected.Equal(pool))
+	})
+
+	t.Run("merges directories with other CAs", func(t *testing.T) {
+		certC, _ := generateTestCert(t)
+
+		pool, err := commoncfg.LoadMTLSCACertPool(&commoncfg.MTLS{
+			ServerCA: &ref,
+			RootCAs:  []commoncfg.SourceRef{{Source: commoncfg.EmbeddedSourceValue, Value: string(certC)}},
+		})
+		require.NoError(t, err)
+
+		expected := expected.Clone()
+		expected.AppendCertsFromPEM(certC)
+		assert.True(t, expected.Equal(pool))
+	})
+
+	t.Run("errors on directories without certificates", func(t *testing.T) {
+		empty := commoncfg.SourceRef{
+			Source: commoncfg.FileSourceValue,
+			File:   commoncfg.CredentialFile{Path: t.TempDir(), Format: commoncfg.BinaryFileFormat},
+		}
+
+		_, err := commoncfg.LoadCACertPool(&empty)
+		assert.ErrorIs(t, err, commoncfg.ErrNoCACertificates)
+	})
+}
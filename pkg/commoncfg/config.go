@@ -5,6 +5,7 @@ package commoncfg
 import (
 	"errors"
 	"runtime/debug"
+	"slices"
 	"time"
 )
 
@@ -26,6 +27,21 @@ type SecretType string
 // Protocol represents the communication protocol.
 type Protocol string
 
+// Compression is the compression of the exported telemetry.
+type Compression string
+
+// Temporality is the temporality of the exported metrics.
+type Temporality string
+
+// TLSVersion is a TLS protocol version.
+type TLSVersion string
+
+// SamplerType is the sampler deciding which traces are recorded, as named by OTEL_TRACES_SAMPLER.
+type SamplerType string
+
+// Propagator is the format the trace context is propagated in, as named by OTEL_PROPAGATORS.
+type Propagator string
+
 // All supported OAuth2 client authentication methods.
 // Based on OAuth2 RFC6749, JWT RFC7523 and OIDC specs.
 type OAuth2ClientAuthMethod string
@@ -37,8 +53,25 @@ const (
 	UnixTimeLogger    LoggerTimeType = "unix"
 	PatternTimeLogger LoggerTimeType = "pattern"
 
-	GRPCProtocol Protocol = "grpc"
-	HTTPProtocol Protocol = "http"
+	GRPCProtocol   Protocol = "grpc"
+	HTTPProtocol   Protocol = "http"
+	StdoutProtocol Protocol = "stdout" // writes the telemetry to stdout, for local development
+	FileProtocol   Protocol = "file"   // writes the telemetry to the file at Path
+	// PrometheusProtocol exposes the metrics to be scraped by Prometheus, it is only
+	// supported by the metric exporters.
+	PrometheusProtocol Protocol = "prometheus"
+
+	NoCompression   Compression = "none"
+	GzipCompression Compression = "gzip"
+
+	// CumulativeTemporality exports the values accumulated since the start of the process.
+	CumulativeTemporality Temporality = "cumulative"
+	// DeltaTemporality exports the change of the values since the last export, as required
+	// by backends such as Dynatrace.
+	DeltaTemporality Temporality = "delta"
+
+	TLS12Version TLSVersion = "1.2"
+	TLS13Version TLSVersion = "1.3"
 
 	InsecureSecretType SecretType = "insecure"
 	MTLSSecretType     SecretType = "mtls"
@@ -46,12 +79,30 @@ const (
 	BasicSecretType    SecretType = "basic"
 	OAuth2SecretType   SecretType = "oauth2"
 
-	EmbeddedSourceValue SourceValueType = "embedded"
-	EnvSourceValue      SourceValueType = "env"
-	FileSourceValue     SourceValueType = "file"
+	AlwaysOnSampler                SamplerType = "always_on"
+	AlwaysOffSampler               SamplerType = "always_off"
+	TraceIDRatioSampler            SamplerType = "traceidratio"
+	ParentBasedAlwaysOnSampler     SamplerType = "parentbased_always_on"
+	ParentBasedAlwaysOffSampler    SamplerType = "parentbased_always_off"
+	ParentBasedTraceIDRatioSampler SamplerType = "parentbased_traceidratio"
+
+	TraceContextPropagator Propagator = "tracecontext" // W3C Trace Context
+	BaggagePropagator      Propagator = "baggage"      // W3C Baggage
+	B3Propagator           Propagator = "b3"           // B3 single header
+	B3MultiPropagator      Propagator = "b3multi"      // B3 multiple headers
+	JaegerPropagator       Propagator = "jaeger"
+
+	EmbeddedSourceValue   SourceValueType = "embedded"
+	EnvSourceValue        SourceValueType = "env"
+	FileSourceValue       SourceValueType = "file"
+	VaultSourceValue      SourceValueType = "vault"
+	AWSSourceValue        SourceValueType = "aws"
+	KubernetesSourceValue SourceValueType = "kubernetes"
+	ExecSourceValue       SourceValueType = "exec"
 
 	JSONFileFormat   FileFormat = "json"
 	YAMLFileFormat   FileFormat = "yaml"
+	TOMLFileFormat   FileFormat = "toml"
 	BinaryFileFormat FileFormat = "binary"
 
 	OAuth2ClientSecretBasic OAuth2ClientAuthMethod = "basic"   // Basic auth header
@@ -80,21 +131,69 @@ type FeatureFlags struct {
 	PollingInterval time.Duration `yaml:"pollingInterval" json:"pollingInterval" default:"60s"`
 }
 
-// FeatureGates are a set of key=value pairs that describe service features.
-type FeatureGates map[string]bool
-
+// FeatureGates are the rules of the service features by feature name. A gate is either
+// a boolean, switching the feature on or off for everybody, or a FeatureGate rule:
+//
+//	featureGates:
+//	  simple: true
+//	  rollout:
+//	    enabled: true
+//	    percentage: 20
+//	    tenants: [tenant-a]
+//
+// Use DynamicFeatureGates to toggle them at runtime when the configuration is reloaded.
+type FeatureGates map[string]FeatureGate
+
+// IsFeatureEnabled reports whether the feature is enabled for all tenants.
 func (fg FeatureGates) IsFeatureEnabled(feature string) bool {
-	v, ok := fg[feature]
-	return ok && v
+	gate, ok := fg[feature]
+	return ok && gate.enabledForAll()
+}
+
+// IsFeatureEnabledFor reports whether the feature is enabled for the tenant, either because
+// it is enabled for all tenants, the tenant is allowlisted or the tenant is part of the
+// percentage rollout. An empty tenant ID is only part of features enabled for all tenants.
+func (fg FeatureGates) IsFeatureEnabledFor(feature, tenantID string) bool {
+	gate, ok := fg[feature]
+	return ok && gate.enabledFor(feature, tenantID)
 }
 
+// EnabledFeatures returns the sorted names of all features that are switched on for all tenants.
+func (fg FeatureGates) EnabledFeatures() []string {
+	enabled := make([]string, 0, len(fg))
+	for feature, gate := range fg {
+		if gate.enabledForAll() {
+			enabled = append(enabled, feature)
+		}
+	}
+
+	slices.Sort(enabled)
+
+	return enabled
+}
+
+// Feature reports whether the feature is enabled for all tenants,
+// or returns ErrFeatureNotFound if it has no gate.
 func (fg FeatureGates) Feature(feature string) (bool, error) {
-	v, ok := fg[feature]
+	gate, ok := fg[feature]
 	if !ok {
 		return false, ErrFeatureNotFound
 	}
 
-	return v, nil
+	return gate.enabledForAll(), nil
+}
+
+// FeatureGate is the rule of a feature. A disabled gate is off for all tenants. An enabled
+// gate without Percentage and Tenants is on for all tenants; otherwise it is only on for the
+// allowlisted Tenants and the Percentage share of the other tenants.
+type FeatureGate struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Percentage of the tenants the feature is rolled out to. The tenants are selected by a
+	// stable hash of the feature name and tenant ID, so raising the percentage keeps the
+	// feature enabled for the tenants which already had it.
+	Percentage int `yaml:"percentage" json:"percentage" validate:"min=0,max=100"`
+	// Tenants is the allowlist of the IDs of the tenants the feature is always enabled for.
+	Tenants []string `yaml:"tenants" json:"tenants"`
 }
 
 // Application holds minimal application configuration.
@@ -104,14 +203,16 @@ type Application struct {
 	Labels           map[string]string `yaml:"labels" json:"labels"`
 	BuildInfo        BuildInfo
 	RuntimeBuildInfo *debug.BuildInfo
+	// Instance identifies the running instance, see LoadInstance. It is not part of the configuration files.
+	Instance Instance `yaml:"-" json:"-" mapstructure:"-"`
 }
 
 type Status struct {
 	Enabled bool `yaml:"enabled" json:"enabled"`
 	// Status.Address is the address to listen on for status reporting
-	Address string `yaml:"address" json:"address" default:":8888"`
+	Address string `yaml:"address" json:"address" default:":8888" validate:"hostport"`
 	// Timeout defines a timeout duration for all checks
-	Timeout time.Duration `yaml:"timeout" json:"timeout" default:"10s"`
+	Timeout time.Duration `yaml:"timeout" json:"timeout" default:"10s" validate:"min=0s"`
 	// Status.Profiling enables profiling on the status server
 	Profiling bool `yaml:"profiling" json:"profiling"`
 }
@@ -119,14 +220,29 @@ type Status struct {
 // Logger holds the configuration for logging.
 type Logger struct {
 	Source    bool            `yaml:"source" json:"source"`
-	Format    LoggerFormat    `yaml:"format" json:"format" default:"json"`
+	Format    LoggerFormat    `yaml:"format" json:"format" default:"json" validate:"oneof=json text"`
 	Level     string          `yaml:"level" json:"level" default:"info"`
 	Formatter LoggerFormatter `yaml:"formatter" json:"formatter"`
+	Elevation LoggerElevation `yaml:"elevation" json:"elevation"`
+}
+
+// LoggerElevation holds configuration for elevating the log level of single requests,
+// e.g. to debug the requests of a support ticket.
+type LoggerElevation struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Header is the HTTP header or gRPC metadata key whose presence elevates the log level
+	// of a request. Its value is added to the logs of the request.
+	Header string `yaml:"header" json:"header" default:"X-Support-Ticket"`
+	// Level is the log level of elevated requests.
+	Level string `yaml:"level" json:"level" default:"debug"`
+	// MaxConcurrent bounds the number of requests elevated at the same time,
+	// further requests are logged with the configured level.
+	MaxConcurrent int `yaml:"maxConcurrent" json:"maxConcurrent" default:"10" validate:"min=0"`
 }
 
 // LoggerTime holds configuration for the time formatting in logs.
 type LoggerTime struct {
-	Type      LoggerTimeType `yaml:"type" json:"type" default:"unix"`
+	Type      LoggerTimeType `yaml:"type" json:"type" default:"unix" validate:"oneof=unix pattern"`
 	Pattern   string         `yaml:"pattern" json:"pattern" default:"Mon Jan 02 15:04:05 -0700 2006"`
 	Precision string         `yaml:"precision" json:"precision" default:"1us"`
 }
@@ -165,39 +281,167 @@ type Telemetry struct {
 	Traces            Trace  `yaml:"traces" json:"traces"`
 	Metrics           Metric `yaml:"metrics" json:"metrics"`
 	Logs              Log    `yaml:"logs" json:"logs"`
+	// Propagators are the formats the trace context is injected in and extracted from,
+	// e.g. [tracecontext, baggage, b3] to interoperate with Zipkin. Defaults to tracecontext and baggage.
+	Propagators []Propagator `yaml:"propagators" json:"propagators"`
+	// ResourceAttributes are added to the resource of all signals, e.g. k8s.pod.name from
+	// an environment variable. They override the attributes derived from the application.
+	ResourceAttributes map[string]SourceRef `yaml:"resourceAttributes" json:"resourceAttributes"`
 }
 
 // Trace defines settings for distributed tracing.
 type Trace struct {
-	Enabled   bool      `yaml:"enabled" json:"enabled"`
-	Protocol  Protocol  `yaml:"protocol" json:"protocol"`
-	Host      SourceRef `yaml:"host" json:"host"`
-	URL       string    `yaml:"url" json:"url"`
+	Enabled  bool      `yaml:"enabled" json:"enabled"`
+	Protocol Protocol  `yaml:"protocol" json:"protocol" validate:"oneof=grpc http stdout file"`
+	Host     SourceRef `yaml:"host" json:"host"`
+	URL      string    `yaml:"url" json:"url"`
+	// Path is the file the traces are written to with the file protocol.
+	Path      string    `yaml:"path" json:"path"`
 	SecretRef SecretRef `yaml:"secretRef" json:"secretRef"`
+	// Compression compresses the exported telemetry with gzip, defaults to none.
+	Compression Compression  `yaml:"compression" json:"compression" default:"none" validate:"oneof=none gzip"`
+	Sampler     TraceSampler `yaml:"sampler" json:"sampler"`
+	// Batch configures the batching of the exported spans.
+	Batch TelemetryBatch `yaml:"batch" json:"batch"`
+	// ShutdownTimeout bounds the flush of the pending spans on shutdown, defaults to 5s.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" json:"shutdownTimeout" default:"5s"`
+	// Headers are sent with every export request, e.g. a tenant or routing header
+	// required by the collector.
+	Headers map[string]SourceRef `yaml:"headers" json:"headers"`
+	// ResourceAttributes are added to the resource of the traces only, they override the
+	// resource attributes of the telemetry.
+	ResourceAttributes map[string]SourceRef `yaml:"resourceAttributes" json:"resourceAttributes"`
+	// Exporters are additional endpoints the traces are exported to, e.g. while migrating
+	// between observability backends.
+	Exporters []TelemetryExporter `yaml:"exporters" json:"exporters"`
+}
+
+// TraceSampler configures which traces are recorded and exported.
+type TraceSampler struct {
+	// Type is the sampler, e.g. parentbased_traceidratio to record a ratio of the new
+	// traces and follow the decision of the caller for the others.
+	Type SamplerType `yaml:"type" json:"type" default:"always_on" validate:"oneof=always_on always_off traceidratio parentbased_always_on parentbased_always_off parentbased_traceidratio"`
+	// Ratio is the ratio of the new traces recorded by the traceidratio samplers, from 0 to 1.
+	Ratio float64 `yaml:"ratio" json:"ratio" validate:"min=0,max=1"`
 }
 
 // Log defines settings for structured logging export.
 type Log struct {
-	Enabled   bool      `yaml:"enabled" json:"enabled"`
-	Protocol  Protocol  `yaml:"protocol" json:"protocol"`
-	Host      SourceRef `yaml:"host" json:"host"`
-	URL       string    `yaml:"url" json:"url"`
+	Enabled  bool      `yaml:"enabled" json:"enabled"`
+	Protocol Protocol  `yaml:"protocol" json:"protocol" validate:"oneof=grpc http stdout file"`
+	Host     SourceRef `yaml:"host" json:"host"`
+	URL      string    `yaml:"url" json:"url"`
+	// Path is the file the logs are written to with the file protocol.
+	Path      string    `yaml:"path" json:"path"`
 	SecretRef SecretRef `yaml:"secretRef" json:"secretRef"`
+	// Compression compresses the exported telemetry with gzip, defaults to none.
+	Compression Compression `yaml:"compression" json:"compression" default:"none" validate:"oneof=none gzip"`
+	// Headers are sent with every export request, e.g. a tenant or routing header
+	// required by the collector.
+	Headers map[string]SourceRef `yaml:"headers" json:"headers"`
+	// Batch configures the batching of the exported log records.
+	Batch TelemetryBatch `yaml:"batch" json:"batch"`
+	// ShutdownTimeout bounds the flush of the pending log records on shutdown, defaults to 5s.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" json:"shutdownTimeout" default:"5s"`
+	// ResourceAttributes are added to the resource of the logs only, they override the
+	// resource attributes of the telemetry.
+	ResourceAttributes map[string]SourceRef `yaml:"resourceAttributes" json:"resourceAttributes"`
+	// Exporters are additional endpoints the logs are exported to.
+	Exporters []TelemetryExporter `yaml:"exporters" json:"exporters"`
 }
 
 // Metric defines settings for metrics export and Prometheus.
 type Metric struct {
-	Enabled    bool       `yaml:"enabled" json:"enabled"`
-	Protocol   Protocol   `yaml:"protocol" json:"protocol"`
-	Host       SourceRef  `yaml:"host" json:"host"`
-	URL        string     `yaml:"url" json:"url"`
-	SecretRef  SecretRef  `yaml:"secretRef" json:"secretRef"`
-	Prometheus Prometheus `yaml:"prometheus" json:"prometheus"`
+	Enabled  bool      `yaml:"enabled" json:"enabled"`
+	Protocol Protocol  `yaml:"protocol" json:"protocol" validate:"oneof=grpc http stdout file prometheus"`
+	Host     SourceRef `yaml:"host" json:"host"`
+	URL      string    `yaml:"url" json:"url"`
+	// Path is the file the metrics are written to with the file protocol.
+	Path      string    `yaml:"path" json:"path"`
+	SecretRef SecretRef `yaml:"secretRef" json:"secretRef"`
+	// Compression compresses the exported telemetry with gzip, defaults to none.
+	Compression Compression `yaml:"compression" json:"compression" default:"none" validate:"oneof=none gzip"`
+	// Temporality of the exported metrics, defaults to cumulative. It does not apply to
+	// the metrics exposed to Prometheus, which are always cumulative.
+	Temporality Temporality `yaml:"temporality" json:"temporality" default:"cumulative" validate:"oneof=cumulative delta"`
+	Prometheus  Prometheus  `yaml:"prometheus" json:"prometheus"`
+	// Headers are sent with every export request, e.g. a tenant or routing header
+	// required by the collector.
+	Headers map[string]SourceRef `yaml:"headers" json:"headers"`
+	// Interval is the interval the metrics are collected and exported at, defaults to 2s.
+	Interval time.Duration `yaml:"interval" json:"interval" default:"2s"`
+	// ShutdownTimeout bounds the flush of the pending metrics on shutdown, defaults to 5s.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" json:"shutdownTimeout" default:"5s"`
+	// HostMetrics collects the CPU, memory and network metrics of the host.
+	HostMetrics bool `yaml:"hostMetrics" json:"hostMetrics"`
+	// ProcessMetrics collects the CPU, memory and thread metrics of the process.
+	ProcessMetrics bool `yaml:"processMetrics" json:"processMetrics"`
+	// Views change how the metrics of the matching instruments are aggregated and exported,
+	// e.g. the bucket boundaries of a histogram.
+	Views []MetricView `yaml:"views" json:"views"`
+	// ResourceAttributes are added to the resource of the metrics only, they override the
+	// resource attributes of the telemetry.
+	ResourceAttributes map[string]SourceRef `yaml:"resourceAttributes" json:"resourceAttributes"`
+	// Exporters are additional endpoints the metrics are exported to, e.g. an exporter
+	// with the prometheus protocol to expose the metrics pushed to a collector.
+	Exporters []TelemetryExporter `yaml:"exporters" json:"exporters"`
+}
+
+// MetricView changes the metric stream of the instruments matching the instrument name
+// and meter. Buckets, Drop and Attributes apply to all matching instruments, while
+// Name requires the instrument name to match one instrument only.
+type MetricView struct {
+	// Instrument is the name of the instruments, * and ? match any characters, e.g. http.server.*.
+	Instrument string `yaml:"instrument" json:"instrument" validate:"required"`
+	// Meter is the name of the meter the instruments are created by, any meter if empty.
+	Meter string `yaml:"meter" json:"meter"`
+	// Name renames the metric.
+	Name string `yaml:"name" json:"name"`
+	// Description replaces the description of the metric.
+	Description string `yaml:"description" json:"description"`
+	// Drop drops the metrics of the instruments.
+	Drop bool `yaml:"drop" json:"drop"`
+	// Buckets are the explicit bucket boundaries of histograms, e.g. [0.05, 0.1, 0.25, 0.5, 1].
+	Buckets []float64 `yaml:"buckets" json:"buckets"`
+	// Attributes are the attribute keys kept, all other attributes are removed. All
+	// attributes are kept if empty.
+	Attributes []string `yaml:"attributes" json:"attributes"`
+}
+
+// TelemetryBatch configures how the spans or log records of a signal are batched before
+// they are exported. Zero values keep the defaults of the OpenTelemetry SDK, except for
+// the trace timeout which defaults to 2s.
+type TelemetryBatch struct {
+	// Timeout is the maximum delay before the pending records are exported.
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+	// MaxQueueSize is the maximum number of records buffered, further records are dropped.
+	MaxQueueSize int `yaml:"maxQueueSize" json:"maxQueueSize" validate:"min=0"`
+	// MaxExportBatchSize is the maximum number of records exported in one request.
+	MaxExportBatchSize int `yaml:"maxExportBatchSize" json:"maxExportBatchSize" validate:"min=0"`
+}
+
+// TelemetryExporter defines an additional exporter of a signal, e.g. an OTLP endpoint
+// with its own protocol and credentials.
+type TelemetryExporter struct {
+	// Name identifies the exporter in errors, e.g. internal-collector.
+	Name     string    `yaml:"name" json:"name"`
+	Protocol Protocol  `yaml:"protocol" json:"protocol" validate:"oneof=grpc http stdout file prometheus"`
+	Host     SourceRef `yaml:"host" json:"host"`
+	URL      string    `yaml:"url" json:"url"`
+	// Path is the file the telemetry is written to with the file protocol.
+	Path      string    `yaml:"path" json:"path"`
+	SecretRef SecretRef `yaml:"secretRef" json:"secretRef"`
+	// Compression compresses the exported telemetry with gzip, defaults to none.
+	Compression Compression `yaml:"compression" json:"compression" default:"none" validate:"oneof=none gzip"`
+	// Temporality of the metrics exported by this exporter, defaults to cumulative.
+	Temporality Temporality `yaml:"temporality" json:"temporality" default:"cumulative" validate:"oneof=cumulative delta"`
+	// Headers are sent with every export request of this exporter.
+	Headers map[string]SourceRef `yaml:"headers" json:"headers"`
 }
 
 // SecretRef defines how credentials or certificates are provided.
 type SecretRef struct {
-	Type     SecretType `yaml:"type" json:"type"`
+	Type     SecretType `yaml:"type" json:"type" validate:"oneof=insecure mtls api-token basic oauth2"`
 	MTLS     MTLS       `yaml:"mtls" json:"mtls"`
 	APIToken SourceRef  `yaml:"apiToken" json:"apiToken"`
 	OAuth2   OAuth2     `yaml:"oauth2" json:"oauth2"`
@@ -209,10 +453,36 @@ type MTLS struct {
 	Cert    SourceRef `yaml:"cert" json:"cert" mapstructure:"cert"`
 	CertKey SourceRef `yaml:"certKey" json:"certKey" mapstructure:"certKey"`
 
+	// PKCS12 is a PKCS#12 (.p12/.pfx) bundle holding the client certificate, its private key
+	// and optionally the certificate chain. If set, it is used instead of Cert and CertKey.
+	PKCS12 *SourceRef `yaml:"pkcs12,omitempty" json:"pkcs12,omitempty" mapstructure:"pkcs12"`
+	// PKCS12Passphrase is the passphrase the PKCS12 bundle is encrypted with.
+	PKCS12Passphrase *SourceRef `yaml:"pkcs12Passphrase,omitempty" json:"pkcs12Passphrase,omitempty" mapstructure:"pkcs12Passphrase"`
+
+	// ServerCA and RootCAs are the PEM encoded CA certificates merged into the CA pool.
+	// A file source may reference a directory, in which case all PEM files of the
+	// directory (.pem, .crt, .cer) are loaded.
 	ServerCA *SourceRef  `yaml:"serverCa" json:"serverCa" mapstructure:"serverCa"`
 	RootCAs  []SourceRef `yaml:"rootCAs,omitempty" json:"rootCAs,omitempty" mapstructure:"rootCAs"`
 
 	Attributes *TLSAttributes `yaml:"attributes" json:"attributes" mapstructure:"attributes"`
+
+	// Revocation enables revocation checks of the peer certificate chain.
+	Revocation *TLSRevocation `yaml:"revocation,omitempty" json:"revocation,omitempty" mapstructure:"revocation"`
+}
+
+// TLSRevocation configures the revocation checks of the peer certificate chain.
+type TLSRevocation struct {
+	// CRLs are certificate revocation lists, PEM or DER encoded. The certificates of the
+	// peer chain are checked against the CRLs signed by their issuer; certificates whose
+	// issuer has no CRL configured are not checked.
+	CRLs []SourceRef `yaml:"crls,omitempty" json:"crls,omitempty" mapstructure:"crls"`
+	// OCSPStapling checks the OCSP response stapled by the peer for its certificate.
+	OCSPStapling bool `yaml:"ocspStapling" json:"ocspStapling" mapstructure:"ocspStapling"`
+	// SoftFail accepts the peer certificate if its revocation status cannot be determined,
+	// e.g. the peer did not staple an OCSP response or a CRL is outdated. Revoked
+	// certificates are always rejected.
+	SoftFail bool `yaml:"softFail" json:"softFail" mapstructure:"softFail"`
 }
 
 type TLSAttributes struct {
@@ -239,6 +509,16 @@ type TLSAttributes struct {
 	// false, the size of TLS records may be adjusted in an attempt to
 	// improve latency.
 	DynamicRecordSizingDisabled bool `yaml:"dynamicRecordSizingDisabled" json:"dynamicRecordSizingDisabled" mapstructure:"dynamicRecordSizingDisabled"`
+
+	// MinVersion is the minimum TLS version, defaults to 1.2. Set it to 1.3 in environments
+	// mandating TLS 1.3 only.
+	MinVersion TLSVersion `yaml:"minVersion" json:"minVersion" mapstructure:"minVersion" validate:"oneof=1.2 1.3"`
+	// MaxVersion is the maximum TLS version, defaults to 1.3.
+	MaxVersion TLSVersion `yaml:"maxVersion" json:"maxVersion" mapstructure:"maxVersion" validate:"oneof=1.2 1.3"`
+	// CipherSuites restricts the TLS 1.2 cipher suites to the named ones, e.g.
+	// TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384. Only the secure cipher suites of crypto/tls
+	// are supported; the TLS 1.3 cipher suites are not configurable.
+	CipherSuites []string `yaml:"cipherSuites" json:"cipherSuites" mapstructure:"cipherSuites"`
 }
 
 // Audit holds the audit log library configuration.
@@ -247,8 +527,180 @@ type Audit struct {
 
 	HTTPClient HTTPClient `yaml:"httpClient" json:"httpClient"`
 
-	// Optional set of additional properties to be added to OTLP log object. Must be added as a literal string to maintain casing.
+	// Optional set of additional properties to be added to OTLP log object, as YAML or JSON. Must be added as a literal string to maintain casing.
 	AdditionalProperties string `yaml:"additionalProperties" json:"additionalProperties"`
+
+	// MustAudit configures the confirmed export of events of critical operations, e.g. key purges.
+	MustAudit AuditMustAudit `yaml:"mustAudit" json:"mustAudit"`
+
+	// Limits bounds the size of the event values, which collectors drop silently if they are too large.
+	Limits AuditLimits `yaml:"limits" json:"limits"`
+
+	// Sender configures the batching and retries of the events exported asynchronously.
+	Sender AuditSender `yaml:"sender" json:"sender"`
+
+	// HashChain links the events into a tamper-evident hash chain.
+	HashChain AuditHashChain `yaml:"hashChain" json:"hashChain"`
+
+	// Masking masks the personal data in the map and struct values of the events, e.g. of
+	// configuration updates, with the same rules as the masking of the logger fields.
+	Masking LoggerFieldsMasking `yaml:"masking" json:"masking"`
+}
+
+// AuditHashChain configures the hash chain of the audit events: each event carries the hash
+// of the previous event and its own hash, computed over its content and the previous hash,
+// so deleted or altered events can be detected in the audit store.
+type AuditHashChain struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// HMACKey keys the hashes with HMAC-SHA256, so the chain cannot be recomputed without
+	// the key after an event was altered. The hashes are plain SHA-256 if it is not set.
+	HMACKey *SourceRef `yaml:"hmacKey,omitempty" json:"hmacKey,omitempty"`
+}
+
+// AuditSender configures the asynchronous export of audit events, which are queued in memory
+// and exported in batches.
+type AuditSender struct {
+	// QueueSize is the maximum number of queued events. Events are rejected while the queue is full.
+	QueueSize int `yaml:"queueSize" json:"queueSize" default:"1000" validate:"min=1"`
+	// BatchSize is the maximum number of events exported in one request.
+	BatchSize int `yaml:"batchSize" json:"batchSize" default:"100" validate:"min=1"`
+	// FlushInterval is the maximum time an event waits in the queue for its batch to fill up.
+	FlushInterval time.Duration `yaml:"flushInterval" json:"flushInterval" default:"1s" validate:"min=0s"`
+	// MaxRetries is the number of retries of a failed export before its events are dead-lettered or dropped.
+	MaxRetries int `yaml:"maxRetries" json:"maxRetries" default:"5" validate:"min=0"`
+	// MinBackoff is the wait before the first retry, it doubles with each further retry.
+	MinBackoff time.Duration `yaml:"minBackoff" json:"minBackoff" default:"100ms" validate:"min=0s"`
+	// MaxBackoff bounds the wait between two retries.
+	MaxBackoff time.Duration `yaml:"maxBackoff" json:"maxBackoff" default:"30s" validate:"min=0s"`
+	// BufferDir enables the write-ahead buffer of the queued events, so they survive a crash
+	// or restart and are exported on the next start. The failed exports are then retried
+	// until they succeed, regardless of MaxRetries. It must be on persistent storage.
+	BufferDir string `yaml:"bufferDir" json:"bufferDir"`
+	// MaxBufferFileSize is the size at which the buffer file is rotated, e.g. 16MiB.
+	MaxBufferFileSize ByteSize `yaml:"maxBufferFileSize" json:"maxBufferFileSize" default:"16MiB" validate:"min=0"`
+	// Encoding is the format of the exported events, for endpoints which cannot ingest OTLP.
+	Encoding AuditEncoding `yaml:"encoding" json:"encoding" default:"otlp" validate:"oneof=otlp cloudevents json"`
+	// Transport delivers the exported events to the endpoint of the audit logger, a NATS subject or a Kafka topic.
+	Transport AuditTransport `yaml:"transport" json:"transport" default:"http" validate:"oneof=http nats kafka"`
+	// NATS configures the nats transport.
+	NATS AuditNATS `yaml:"nats" json:"nats"`
+	// Kafka configures the kafka transport.
+	Kafka AuditKafka `yaml:"kafka" json:"kafka"`
+	// Filter reduces the volume of the exported events.
+	Filter AuditFilter `yaml:"filter" json:"filter"`
+	// DeadLetterDir keeps the events whose export exhausted the retries, one file per failed
+	// export, instead of dropping them. The files can be moved to the spool directory of
+	// MustAudit to export them with ReplaySpool. It is ignored with the write-ahead buffer.
+	DeadLetterDir string `yaml:"deadLetterDir" json:"deadLetterDir"`
+}
+
+// AuditFilter configures which events the AuditSender drops before they are queued.
+// Mandatory events, e.g. failed logins, rejected requests and key purges, and events
+// of failed or denied operations are never dropped.
+type AuditFilter struct {
+	// DropReads drops the read events, e.g. configurationRead.
+	DropReads bool `yaml:"dropReads" json:"dropReads"`
+	// SampleRates keeps the given percentage of the events of an event type, e.g. keyRotate: 10.
+	SampleRates map[string]int `yaml:"sampleRates" json:"sampleRates"`
+	// Tenants keeps only the events of the listed tenants. The events of all tenants are kept if empty.
+	Tenants []string `yaml:"tenants" json:"tenants"`
+}
+
+// AuditTransport defines how the AuditSender delivers the events.
+type AuditTransport string
+
+const (
+	// AuditHTTPTransport posts the events to the endpoint of the audit logger.
+	AuditHTTPTransport AuditTransport = "http"
+	// AuditNATSTransport publishes the events to a NATS subject.
+	AuditNATSTransport AuditTransport = "nats"
+	// AuditKafkaTransport produces the events to a Kafka topic.
+	AuditKafkaTransport AuditTransport = "kafka"
+)
+
+// AuditNATS configures the publishing of the audit events to a NATS subject.
+type AuditNATS struct {
+	// URL is the address of the NATS server, e.g. nats://nats:4222, or tls://nats:4222 to require TLS.
+	URL     string `yaml:"url" json:"url"`
+	Subject string `yaml:"subject" json:"subject"`
+	// Token, or User and Password, authenticate the connection.
+	Token    *SourceRef `yaml:"token,omitempty" json:"token,omitempty"`
+	User     *SourceRef `yaml:"user,omitempty" json:"user,omitempty"`
+	Password *SourceRef `yaml:"password,omitempty" json:"password,omitempty"`
+	// MTLS configures the TLS of the connection, e.g. with a client certificate.
+	MTLS *MTLS `yaml:"mtls,omitempty" json:"mtls,omitempty"`
+}
+
+// AuditKafka configures the producing of the audit events to a Kafka topic.
+type AuditKafka struct {
+	// Brokers are the addresses of the bootstrap brokers, e.g. kafka-0:9092.
+	Brokers []string `yaml:"brokers" json:"brokers"`
+	Topic   string   `yaml:"topic" json:"topic"`
+	// SecretRef authenticates the connection: mtls with a client certificate, or basic with
+	// SASL and the mechanism of SASLMechanism. Without it, or with insecure, the connection
+	// is not authenticated.
+	SecretRef *SecretRef `yaml:"secretRef,omitempty" json:"secretRef,omitempty"`
+	// SASLMechanism is the SASL mechanism of the basic credentials.
+	SASLMechanism AuditKafkaSASLMechanism `yaml:"saslMechanism" json:"saslMechanism" default:"plain" validate:"oneof=plain scram-sha-256 scram-sha-512"`
+	// TLS connects to the brokers with TLS, verified against the system CA certificates, if
+	// the SecretRef is not mtls, e.g. to protect the SASL credentials.
+	TLS bool `yaml:"tls" json:"tls"`
+}
+
+// AuditKafkaSASLMechanism defines the SASL mechanism of the kafka transport.
+type AuditKafkaSASLMechanism string
+
+const (
+	// AuditKafkaSASLPlain sends the username and password in plain text.
+	AuditKafkaSASLPlain AuditKafkaSASLMechanism = "plain"
+	// AuditKafkaSASLSCRAMSHA256 authenticates with SCRAM-SHA-256.
+	AuditKafkaSASLSCRAMSHA256 AuditKafkaSASLMechanism = "scram-sha-256"
+	// AuditKafkaSASLSCRAMSHA512 authenticates with SCRAM-SHA-512.
+	AuditKafkaSASLSCRAMSHA512 AuditKafkaSASLMechanism = "scram-sha-512"
+)
+
+// AuditEncoding defines the format the AuditSender exports the events in.
+type AuditEncoding string
+
+const (
+	// AuditOTLPEncoding exports the events as OTLP logs in JSON.
+	AuditOTLPEncoding AuditEncoding = "otlp"
+	// AuditCloudEventsEncoding exports the events as a batch of CloudEvents 1.0 in JSON.
+	AuditCloudEventsEncoding AuditEncoding = "cloudevents"
+	// AuditJSONEncoding exports the events as a JSON array of plain objects.
+	AuditJSONEncoding AuditEncoding = "json"
+)
+
+// AuditLimits configures the truncation of large attribute values of audit events,
+// e.g. the old and new values of configuration updates holding whole documents.
+type AuditLimits struct {
+	// MaxValueSize is the maximum size of an attribute value, e.g. 16KiB. Larger values are
+	// truncated and end with the TruncationMarker. Values are not truncated if it is 0, e.g.
+	// because the configuration was not loaded with its defaults.
+	MaxValueSize ByteSize `yaml:"maxValueSize" json:"maxValueSize" default:"16KiB" validate:"min=0"`
+	// TruncationMarker is appended to truncated values, so they cannot be mistaken for complete ones.
+	TruncationMarker string `yaml:"truncationMarker" json:"truncationMarker" default:"...[truncated]"`
+}
+
+// AuditFailurePolicy defines what happens if an event which must be audited cannot be exported.
+type AuditFailurePolicy string
+
+const (
+	// AuditFailClosed returns an error, so the critical operation must not proceed.
+	AuditFailClosed AuditFailurePolicy = "fail"
+	// AuditSpool writes the event durably to a local spool, from which it is exported later.
+	AuditSpool AuditFailurePolicy = "spool"
+)
+
+// AuditMustAudit configures the export of events which must be audited before the operation proceeds.
+type AuditMustAudit struct {
+	// Timeout bounds the export of an event including its retries.
+	Timeout time.Duration `yaml:"timeout" json:"timeout" default:"10s" validate:"min=0s"`
+	// FailurePolicy applies if the event could not be exported within the timeout.
+	FailurePolicy AuditFailurePolicy `yaml:"failurePolicy" json:"failurePolicy" default:"fail" validate:"oneof=fail spool"`
+	// SpoolDir is the directory events are spooled to with the spool failure policy.
+	// It must be on persistent storage to survive restarts.
+	SpoolDir string `yaml:"spoolDir" json:"spoolDir"`
 }
 
 // BasicAuth holds basic auth configuration for audit library.
@@ -267,7 +719,7 @@ type OAuth2 struct {
 type OAuth2Credentials struct {
 	ClientID SourceRef `yaml:"clientID" json:"clientID" mapstructure:"clientID"`
 
-	AuthMethod OAuth2ClientAuthMethod `yaml:"authMethod" json:"authMethod" default:"post" mapstructure:"authMethod"`
+	AuthMethod OAuth2ClientAuthMethod `yaml:"authMethod" json:"authMethod" default:"post" mapstructure:"authMethod" validate:"oneof=basic post jwt private none"`
 
 	// Option A: client_secret authentication
 	ClientSecret *SourceRef `yaml:"clientSecret,omitempty" json:"clientSecret,omitempty" mapstructure:"clientSecret"`
@@ -279,20 +731,29 @@ type OAuth2Credentials struct {
 
 // SourceRef defines a reference to a source for retrieving a value.
 type SourceRef struct {
-	Source SourceValueType `yaml:"source" json:"source" default:"embedded" mapstructure:"source"`
-	Env    string          `yaml:"env" json:"env" mapstructure:"env"`
-	File   CredentialFile  `yaml:"file" json:"file" mapstructure:"file"`
-	Value  string          `yaml:"value" json:"value" mapstructure:"value"`
+	Source     SourceValueType  `yaml:"source" json:"source" default:"embedded" mapstructure:"source" validate:"oneof=embedded env file vault aws kubernetes exec"`
+	Env        string           `yaml:"env" json:"env" mapstructure:"env"`
+	File       CredentialFile   `yaml:"file" json:"file" mapstructure:"file"`
+	Vault      VaultSource      `yaml:"vault" json:"vault" mapstructure:"vault"`
+	AWS        AWSSource        `yaml:"aws" json:"aws" mapstructure:"aws"`
+	Kubernetes KubernetesSource `yaml:"kubernetes" json:"kubernetes" mapstructure:"kubernetes"`
+	Exec       ExecSource       `yaml:"exec" json:"exec" mapstructure:"exec"`
+	Value      string           `yaml:"value" json:"value" mapstructure:"value"`
 }
 
 // CredentialFile describes a file-based credential.
 type CredentialFile struct {
 	Path     string     `yaml:"path" json:"path" mapstructure:"path"`
-	Format   FileFormat `yaml:"format" json:"format" mapstructure:"format"`
+	Format   FileFormat `yaml:"format" json:"format" mapstructure:"format" validate:"oneof=json yaml toml binary"`
 	JSONPath string     `yaml:"jsonPath" json:"jsonPath" mapstructure:"jsonPath"`
+	// Encryption decrypts a SOPS or age encrypted file before the value is extracted,
+	// see SOPSFileEncryption and AgeFileEncryption. The file is read as is if not set.
+	Encryption FileEncryption `yaml:"encryption" json:"encryption" mapstructure:"encryption" validate:"oneof=sops age"`
 }
 
 // Prometheus defines configuration for Prometheus integration.
+// If enabled, the metrics are exposed to Prometheus instead of being exported to the
+// endpoint of the signal. They are still exported to the additional exporters.
 type Prometheus struct {
 	Enabled bool `yaml:"enabled" json:"enabled"`
 }
@@ -301,14 +762,14 @@ type Prometheus struct {
 // business gRPC server if any.
 type GRPCServer struct {
 	Enabled bool   `yaml:"enabled" json:"enabled"`
-	Address string `yaml:"address" json:"address" default:":9092"`
+	Address string `yaml:"address" json:"address" default:":9092" validate:"hostport"`
 	Flags   Flags  `yaml:"flags" json:"flags"`
-	// MaxSendMsgSize returns a ServerOption to set the max message size in bytes the server can send.
-	// If this is not set, gRPC uses the default `2147483647`.
-	MaxSendMsgSize int `yaml:"maxSendMsgSize" json:"maxSendMsgSize" default:"2147483647"`
-	// MaxRecvMsgSize returns a ServerOption to set the max message size in bytes the server can receive.
-	// If this is not set, gRPC uses the default 4MB.
-	MaxRecvMsgSize int `yaml:"maxRecvMsgSize" json:"maxRecvMsgSize" default:"125829120"`
+	// MaxSendMsgSize returns a ServerOption to set the max message size in bytes the server can send,
+	// e.g. 2147483647 or 120MB. If this is not set, gRPC uses the default `2147483647`.
+	MaxSendMsgSize ByteSize `yaml:"maxSendMsgSize" json:"maxSendMsgSize" default:"2147483647" validate:"min=1"`
+	// MaxRecvMsgSize returns a ServerOption to set the max message size in bytes the server can receive,
+	// e.g. 125829120 or 120MB. If this is not set, gRPC uses the default 4MB.
+	MaxRecvMsgSize ByteSize `yaml:"maxRecvMsgSize" json:"maxRecvMsgSize" default:"120MiB" validate:"min=1"`
 	// MinTime is the minimum amount of time a client should wait before sending
 	// a keepalive ping.
 	EfPolMinTime time.Duration `yaml:"efPolMinTime" json:"efPolMinTime" default:"180s"` // The current default value is 5 minutes.
@@ -317,6 +778,55 @@ type GRPCServer struct {
 	// streams, server will send GOAWAY and close the connection.
 	EfPolPermitWithoutStream bool                 `yaml:"efPolPermitWithoutStream" json:"efPolPermitWithoutStream"` // false by default.
 	Attributes               GRPCServerAttributes `yaml:"attributes" json:"attributes"`
+
+	// FaultInjection injects faults into the calls of the server for chaos experiments.
+	FaultInjection GRPCFaultInjection `yaml:"faultInjection" json:"faultInjection"`
+
+	// Preset selects the keepalive, message size, interceptor and TLS settings of a
+	// deployment topology, see GRPCServerPreset. The fields above which are set to
+	// another value than their default override the preset.
+	Preset GRPCServerPreset `yaml:"preset" json:"preset" validate:"oneof=edge internal sidecar"`
+	// TLS configures the server certificate and, for mutual TLS, the CA certificates
+	// of the clients. It is required by the edge and internal presets.
+	TLS *MTLS `yaml:"tls" json:"tls"`
+}
+
+// GRPCServerPreset is a named set of gRPC server settings for a deployment topology.
+type GRPCServerPreset string
+
+const (
+	// EdgeGRPCServerPreset is tuned for internet-facing servers: small messages,
+	// strict keepalive enforcement, short-lived connections, panic recovery and
+	// TLS 1.3 with optional client certificates.
+	EdgeGRPCServerPreset GRPCServerPreset = "edge"
+	// InternalGRPCServerPreset is tuned for cluster-internal servers: medium messages,
+	// long-lived connections rebalanced every 30 minutes, panic recovery and
+	// mutual TLS.
+	InternalGRPCServerPreset GRPCServerPreset = "internal"
+	// SidecarGRPCServerPreset is tuned for servers reached over localhost: large
+	// messages, connections which are never closed for idleness or age, and TLS
+	// only if configured.
+	SidecarGRPCServerPreset GRPCServerPreset = "sidecar"
+)
+
+// HTTPServer specifies the HTTP server configuration e.g. used by the
+// business HTTP server if any.
+type HTTPServer struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Address string `yaml:"address" json:"address" default:":8080" validate:"hostport"`
+	// ReadHeaderTimeout is the amount of time allowed to read request headers.
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" json:"readHeaderTimeout" default:"2s" validate:"min=0s"`
+	// ReadTimeout is the maximum duration for reading the entire request, including the body.
+	// Zero means no timeout.
+	ReadTimeout time.Duration `yaml:"readTimeout" json:"readTimeout" validate:"min=0s"`
+	// WriteTimeout is the maximum duration before timing out writes of the response.
+	// Zero means no timeout.
+	WriteTimeout time.Duration `yaml:"writeTimeout" json:"writeTimeout" validate:"min=0s"`
+	// IdleTimeout is the maximum amount of time to wait for the next request when keep-alives are enabled.
+	IdleTimeout time.Duration `yaml:"idleTimeout" json:"idleTimeout" default:"120s" validate:"min=0s"`
+	// DrainTimeout is the maximum amount of time Shutdown waits for in-flight requests
+	// before the remaining connections are closed forcibly.
+	DrainTimeout time.Duration `yaml:"drainTimeout" json:"drainTimeout" default:"30s" validate:"min=0s"`
 }
 
 type Flags struct {
@@ -360,13 +870,85 @@ type GRPCClient struct {
 	Attributes GRPCClientAttributes `yaml:"attributes" json:"attributes"`
 	Pool       GRPCPool             `yaml:"pool" json:"pool"`
 	SecretRef  *SecretRef           `yaml:"secretRef" json:"secretRef"`
+
+	// Targets splits the calls between several weighted targets, e.g. a stable and a canary
+	// deployment. If set, the calls are routed to the targets instead of the Address.
+	Targets []GRPCTarget `yaml:"targets" json:"targets"`
+	// TargetOverrideHeader is the outgoing metadata key forcing calls to the target
+	// with the given name, regardless of the weights, e.g. for test traffic.
+	TargetOverrideHeader string `yaml:"targetOverrideHeader" json:"targetOverrideHeader" default:"x-grpc-target"`
+
+	// Retry retries failed unary calls within a retry budget shared by all methods of the client.
+	Retry GRPCRetry `yaml:"retry" json:"retry"`
+
+	// FaultInjection injects faults into the calls of the client for chaos experiments.
+	FaultInjection GRPCFaultInjection `yaml:"faultInjection" json:"faultInjection"`
+}
+
+// GRPCFaultInjection injects latency, errors and connection resets into a percentage of
+// the calls of a gRPC client or server for chaos experiments. The faults are drawn
+// independently for every call, so a call can be delayed and fail.
+type GRPCFaultInjection struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// FeatureGate names the feature gate toggling the injection at runtime. If set, faults
+	// are only injected while the gate is enabled for all tenants.
+	FeatureGate string `yaml:"featureGate" json:"featureGate"`
+	// Methods restricts the injection to the full method names or their prefixes,
+	// e.g. /kms.v1.KeyService/ for all methods of a service.
+	Methods []string `yaml:"methods" json:"methods"`
+
+	// DelayPercentage is the percentage of the calls delayed by Delay.
+	DelayPercentage float64       `yaml:"delayPercentage" json:"delayPercentage" validate:"min=0,max=100"`
+	Delay           time.Duration `yaml:"delay" json:"delay" validate:"min=0s"`
+	// ErrorPercentage is the percentage of the calls failing with ErrorCode without being processed.
+	ErrorPercentage float64 `yaml:"errorPercentage" json:"errorPercentage" validate:"min=0,max=100"`
+	ErrorCode       string  `yaml:"errorCode" json:"errorCode" default:"UNAVAILABLE"`
+	// ResetPercentage is the percentage of the calls which are processed, but whose response
+	// is lost as if the connection was reset; they fail with UNAVAILABLE.
+	ResetPercentage float64 `yaml:"resetPercentage" json:"resetPercentage" validate:"min=0,max=100"`
+}
+
+// GRPCRetry configures the retries of failed unary calls of a gRPC client.
+type GRPCRetry struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// MaxAttempts is the maximum number of attempts of a call, including the first one.
+	MaxAttempts int `yaml:"maxAttempts" json:"maxAttempts" default:"3" validate:"min=1"`
+	// InitialBackoff is the backoff before the first retry, it is doubled for every further retry.
+	InitialBackoff time.Duration `yaml:"initialBackoff" json:"initialBackoff" default:"100ms" validate:"min=0s"`
+	// MaxBackoff caps the backoff between two attempts.
+	MaxBackoff time.Duration `yaml:"maxBackoff" json:"maxBackoff" default:"2s" validate:"min=0s"`
+	// RetryableCodes are the gRPC status codes which are retried, e.g. UNAVAILABLE.
+	RetryableCodes []string `yaml:"retryableCodes" json:"retryableCodes" default:"[\"UNAVAILABLE\"]"`
+	// Budget limits the retries of all calls of the client.
+	Budget GRPCRetryBudget `yaml:"budget" json:"budget"`
+}
+
+// GRPCRetryBudget limits the retries to a share of the calls over a sliding window,
+// so retries cannot amplify the load of a backend which is already failing.
+type GRPCRetryBudget struct {
+	// Ratio is the maximum number of retries relative to the number of calls, e.g. 0.2 for 20%.
+	Ratio float64 `yaml:"ratio" json:"ratio" default:"0.2" validate:"min=0,max=1"`
+	// Window is the duration of the sliding window the calls and retries are counted in.
+	Window time.Duration `yaml:"window" json:"window" default:"10s" validate:"min=1s"`
+	// MinRetries is the number of retries always allowed within the window, so clients
+	// with little traffic can retry as well.
+	MinRetries int `yaml:"minRetries" json:"minRetries" default:"10" validate:"min=0"`
+}
+
+// GRPCTarget is a weighted target of a gRPC client.
+type GRPCTarget struct {
+	// Name identifies the target, e.g. stable or canary.
+	Name    string `yaml:"name" json:"name" validate:"required"`
+	Address string `yaml:"address" json:"address" validate:"required"`
+	// Weight is the share of the calls routed to the target, as percentage of the sum of all weights.
+	Weight int `yaml:"weight" json:"weight" validate:"min=0,max=100"`
 }
 
 type GRPCPool struct {
-	InitialCapacity int           `yaml:"initialCapacity" json:"initialCapacity" default:"1"`
-	MaxCapacity     int           `yaml:"maxCapacity" json:"maxCapacity" default:"1"`
-	IdleTimeout     time.Duration `yaml:"idleTimeout" json:"idleTimeout" default:"5s"`
-	MaxLifeDuration time.Duration `yaml:"maxLifeDuration" json:"maxLifeDuration" default:"60s"`
+	InitialCapacity int           `yaml:"initialCapacity" json:"initialCapacity" default:"1" validate:"min=0"`
+	MaxCapacity     int           `yaml:"maxCapacity" json:"maxCapacity" default:"1" validate:"min=1"`
+	IdleTimeout     time.Duration `yaml:"idleTimeout" json:"idleTimeout" default:"5s" validate:"min=0s"`
+	MaxLifeDuration time.Duration `yaml:"maxLifeDuration" json:"maxLifeDuration" default:"60s" validate:"min=0s"`
 }
 
 type GRPCClientAttributes struct {
@@ -377,7 +959,7 @@ type GRPCClientAttributes struct {
 }
 
 type HTTPClient struct {
-	Timeout time.Duration `yaml:"timeout" json:"timeout" default:"10s" mapstructure:"timeout"`
+	Timeout time.Duration `yaml:"timeout" json:"timeout" default:"10s" mapstructure:"timeout" validate:"min=0s"`
 
 	APIToken            *SourceRef               `yaml:"apiToken" json:"apiToken" mapstructure:"apiToken"`
 	BasicAuth           *BasicAuth               `yaml:"basicAuth" json:"basicAuth" mapstructure:"basicAuth"`
@@ -386,6 +968,18 @@ type HTTPClient struct {
 	TransportAttributes *HTTPTransportAttributes `yaml:"transportAttributes" json:"transportAttributes" mapstructure:"transportAttributes"`
 }
 
+// RemoteConfig holds the configuration of a remote config source, which fetches a
+// configuration or a fragment of it from an HTTP(S) endpoint (see remotecfg).
+type RemoteConfig struct {
+	URL string `yaml:"url" json:"url" validate:"required,url"`
+	// Format of the fetched document.
+	Format FileFormat `yaml:"format" json:"format" default:"yaml" validate:"oneof=json yaml toml"`
+	// PollInterval is the time between two requests checking the document for changes.
+	PollInterval time.Duration `yaml:"pollInterval" json:"pollInterval" default:"30s" validate:"min=1s"`
+	// HTTPClient configures the timeout and authentication of the requests.
+	HTTPClient HTTPClient `yaml:"httpClient" json:"httpClient"`
+}
+
 type HTTPTransportAttributes struct {
 	// TLSHandshakeTimeout specifies the maximum amount of time to
 	// wait for a TLS handshake. Zero means no timeout.
@@ -0,0 +1,215 @@
+package commoncfg
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RedactedValue replaces the values of secrets in a Change.
+const RedactedValue = "[REDACTED]"
+
+// ChangeType is the kind of a Change.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// Change is a changed value of a configuration.
+type Change struct {
+	// Path is the path of the value, using the yaml field names, e.g. grpcClient.targets[0].weight.
+	Path string     `yaml:"path" json:"path"`
+	Type ChangeType `yaml:"type" json:"type"`
+	// Old is the previous value, it is nil if the value was added.
+	Old any `yaml:"old,omitempty" json:"old,omitempty"`
+	// New is the current value, it is nil if the value was removed.
+	New any `yaml:"new,omitempty" json:"new,omitempty"`
+}
+
+// String returns the change in a form suitable for logs, e.g. audit.endpoint: "a" -> "b".
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("%s: added %#v", c.Path, c.New)
+	case ChangeRemoved:
+		return fmt.Sprintf("%s: removed %#v", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("%s: %#v -> %#v", c.Path, c.Old, c.New)
+	}
+}
+
+// sensitiveKeys are the field and map keys, in lower case, whose values are redacted.
+var sensitiveKeys = []string{
+	"password", "passphrase", "secret", "secretid", "clientsecret",
+	"token", "apitoken", "apikey", "privatekey",
+}
+
+// Diff returns the values which differ between two configurations, e.g. to log what a
+// reload changed or to review configuration changes in CI. The configurations are
+// compared value by value, so a struct which was added results in a change for each of
+// its values; nil pointers, empty slices and empty maps have no values.
+//
+// The changes are sorted by path. Secrets are never exposed: the values of SourceRef
+// and of fields and map keys named like a secret, e.g. password or token, are replaced by
+// RedactedValue, while the change itself is still reported.
+func Diff(oldCfg, newCfg any) []Change {
+	oldValues := flattenConfig(oldCfg)
+	newValues := flattenConfig(newCfg)
+
+	paths := make([]string, 0, len(oldValues)+len(newValues))
+	for path := range oldValues {
+		paths = append(paths, path)
+	}
+
+	for path := range newValues {
+		if _, ok := oldValues[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+
+	slices.Sort(paths)
+
+	var changes []Change
+
+	for _, path := range paths {
+		oldValue, inOld := oldValues[path]
+		newValue, inNew := newValues[path]
+
+		switch {
+		case !inOld:
+			changes = append(changes, Change{Path: path, Type: ChangeAdded, New: newValue.safe()})
+		case !inNew:
+			changes = append(changes, Change{Path: path, Type: ChangeRemoved, Old: oldValue.safe()})
+		case !reflect.DeepEqual(oldValue.value, newValue.value):
+			change := Change{Path: path, Type: ChangeModified, Old: oldValue.safe(), New: newValue.safe()}
+			if oldValue.secret || newValue.secret {
+				change.Old, change.New = RedactedValue, RedactedValue
+			}
+
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+// leafValue is a scalar value of a configuration.
+type leafValue struct {
+	value  any
+	secret bool
+}
+
+// safe returns the value, or RedactedValue for secrets.
+func (v leafValue) safe() any {
+	if v.secret {
+		return RedactedValue
+	}
+
+	return v.value
+}
+
+// flattenConfig returns the scalar values of a configuration by path.
+func flattenConfig(cfg any) map[string]leafValue {
+	values := make(map[string]leafValue)
+
+	if cfg != nil {
+		flattenValue(values, "", reflect.ValueOf(cfg), false)
+	}
+
+	return values
+}
+
+func flattenValue(values map[string]leafValue, path string, val reflect.Value, secret bool) {
+	switch val.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !val.IsNil() {
+			flattenValue(values, path, val.Elem(), secret)
+		}
+
+		return
+	case reflect.Struct:
+		if val.Type() == reflect.TypeFor[SourceRef]() {
+			// a source reference only locates the secret, only its value is one
+			flattenStruct(values, path, val, false)
+			return
+		}
+
+		if val.Type() != reflect.TypeFor[time.Time]() {
+			flattenStruct(values, path, val, secret)
+			return
+		}
+	case reflect.Slice, reflect.Array:
+		if val.Type().Elem().Kind() != reflect.Uint8 {
+			for i := range val.Len() {
+				flattenValue(values, fmt.Sprintf("%s[%d]", path, i), val.Index(i), secret)
+			}
+
+			return
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key())
+			flattenValue(values, fmt.Sprintf("%s[%s]", path, key), iter.Value(), secret || isSensitiveKey(key))
+		}
+
+		return
+	case reflect.Invalid, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return
+	default:
+	}
+
+	values[path] = leafValue{value: leafInterface(val), secret: secret}
+}
+
+func flattenStruct(values map[string]leafValue, path string, val reflect.Value, secret bool) {
+	typ := val.Type()
+	isSourceRef := typ == reflect.TypeFor[SourceRef]()
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, inline := fieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		fieldPath := joinPath(path, name)
+		if inline {
+			fieldPath = path
+		}
+
+		fieldSecret := secret || isSensitiveKey(name) || (isSourceRef && field.Name == "Value")
+
+		flattenValue(values, fieldPath, val.Field(i), fieldSecret)
+	}
+}
+
+// leafInterface returns the value of a scalar, durations as in the configuration files, e.g. 10s.
+func leafInterface(val reflect.Value) any {
+	if val.Type() == reflect.TypeFor[time.Duration]() || val.Type() == reflect.TypeFor[Duration]() {
+		return time.Duration(val.Int()).String()
+	}
+
+	if val.Kind() == reflect.Slice {
+		return string(val.Bytes())
+	}
+
+	if val.Kind() == reflect.Array {
+		return fmt.Sprint(val.Interface())
+	}
+
+	return val.Interface()
+}
+
+func isSensitiveKey(name string) bool {
+	return slices.Contains(sensitiveKeys, strings.ToLower(name))
+}
@@ -0,0 +1,91 @@
+package commoncfg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestDiff(t *testing.T) {
+	oldCfg := &commoncfg.BaseConfig{
+		Application: commoncfg.Application{
+			Name:   "svc",
+			Labels: map[string]string{"team": "a", "password": "hunter2"},
+		},
+		Audit: commoncfg.Audit{
+			Endpoint: "http://old",
+			MustAudit: commoncfg.AuditMustAudit{
+				Timeout: 10 * time.Second,
+			},
+		},
+	}
+
+	newCfg := &commoncfg.BaseConfig{
+		Application: commoncfg.Application{
+			Name:   "svc",
+			Labels: map[string]string{"region": "eu", "password": "hunter3"},
+		},
+		Audit: commoncfg.Audit{
+			Endpoint: "http://new",
+			MustAudit: commoncfg.AuditMustAudit{
+				Timeout: 5 * time.Second,
+			},
+		},
+	}
+
+	changes := commoncfg.Diff(oldCfg, newCfg)
+
+	assert.Equal(t, []commoncfg.Change{
+		{Path: "application.labels[password]", Type: commoncfg.ChangeModified, Old: commoncfg.RedactedValue, New: commoncfg.RedactedValue},
+		{Path: "application.labels[region]", Type: commoncfg.ChangeAdded, New: "eu"},
+		{Path: "application.labels[team]", Type: commoncfg.ChangeRemoved, Old: "a"},
+		{Path: "audit.endpoint", Type: commoncfg.ChangeModified, Old: "http://old", New: "http://new"},
+		{Path: "audit.mustAudit.timeout", Type: commoncfg.ChangeModified, Old: "10s", New: "5s"},
+	}, changes)
+
+	assert.Equal(t, `audit.endpoint: "http://old" -> "http://new"`, changes[3].String())
+}
+
+func TestDiff_RedactsSourceRefValues(t *testing.T) {
+	oldCfg := commoncfg.SecretRef{
+		Type: commoncfg.ApiTokenSecretType,
+		APIToken: commoncfg.SourceRef{
+			Source: commoncfg.EmbeddedSourceValue,
+			Value:  "old-token",
+		},
+	}
+
+	newCfg := oldCfg
+	newCfg.APIToken.Value = "new-token"
+	newCfg.APIToken.Source = commoncfg.EnvSourceValue
+
+	changes := commoncfg.Diff(oldCfg, newCfg)
+
+	assert.Equal(t, []commoncfg.Change{
+		{Path: "apiToken.source", Type: commoncfg.ChangeModified, Old: commoncfg.EmbeddedSourceValue, New: commoncfg.EnvSourceValue},
+		{Path: "apiToken.value", Type: commoncfg.ChangeModified, Old: commoncfg.RedactedValue, New: commoncfg.RedactedValue},
+	}, changes)
+	assert.NotContains(t, changes[1].String(), "token\"")
+}
+
+func TestDiff_NoChanges(t *testing.T) {
+	cfg := &commoncfg.BaseConfig{Application: commoncfg.Application{Name: "svc"}}
+
+	assert.Empty(t, commoncfg.Diff(cfg, cfg))
+	assert.Empty(t, commoncfg.Diff(nil, nil))
+}
+
+func TestDiff_AddedPointers(t *testing.T) {
+	oldCfg := commoncfg.MTLS{}
+	newCfg := commoncfg.MTLS{
+		Revocation: &commoncfg.TLSRevocation{OCSPStapling: true},
+	}
+
+	assert.Equal(t, []commoncfg.Change{
+		{Path: "revocation.ocspStapling", Type: commoncfg.ChangeAdded, New: true},
+		{Path: "revocation.softFail", Type: commoncfg.ChangeAdded, New: false},
+	}, commoncfg.Diff(oldCfg, newCfg))
+}
@@ -0,0 +1,137 @@
+package commoncfg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"time"
+)
+
+const (
+	DefaultExecTimeout       = 10 * time.Second
+	DefaultExecMaxOutputSize = 1 << 20
+
+	// execStderrLimit bounds the stderr of a failed command included in the error.
+	execStderrLimit = 512
+)
+
+var (
+	ErrExecCommandMissing  = errors.New("exec command is missing")
+	ErrExecTimeout         = errors.New("exec command timed out")
+	ErrExecFailed          = errors.New("exec command failed")
+	ErrExecEmptyOutput     = errors.New("exec command returned no output")
+	ErrExecOutputTooLarge  = errors.New("exec command output exceeds the maximum size")
+	ansiEscapeSequenceExpr = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+)
+
+// ExecSource describes a command whose output is the value, e.g. `aws ecr get-login-password`
+// or a custom secret fetcher. The command is run directly, not by a shell, so arguments are
+// never interpreted. It inherits the environment of the process.
+//
+// The output is sanitized before it is used: terminal escape sequences and surrounding
+// whitespace, e.g. the trailing newline, are removed. Empty outputs are rejected, as are
+// outputs larger than MaxOutputSize. The output is never part of an error, only the
+// beginning of stderr is.
+type ExecSource struct {
+	// Command is the executable, looked up in the PATH if it contains no path separator.
+	Command string   `yaml:"command" json:"command" mapstructure:"command"`
+	Args    []string `yaml:"args,omitempty" json:"args,omitempty" mapstructure:"args"`
+	// Env holds additional environment variables in the KEY=VALUE form.
+	Env []string `yaml:"env,omitempty" json:"env,omitempty" mapstructure:"env"`
+	// Dir is the working directory of the command; defaults to the one of the process.
+	Dir string `yaml:"dir" json:"dir" mapstructure:"dir"`
+	// Timeout bounds the run of the command, which is killed afterwards.
+	Timeout time.Duration `yaml:"timeout" json:"timeout" mapstructure:"timeout"`
+	// MaxOutputSize bounds the size of the output in bytes; defaults to 1 MiB.
+	MaxOutputSize int `yaml:"maxOutputSize" json:"maxOutputSize" mapstructure:"maxOutputSize"`
+	// Format and JSONPath select a value of a structured output, as for file sources.
+	Format   FileFormat `yaml:"format" json:"format" mapstructure:"format" validate:"oneof=json yaml toml binary"`
+	JSONPath string     `yaml:"jsonPath" json:"jsonPath" mapstructure:"jsonPath"`
+}
+
+func loadFromExec(src *ExecSource) ([]byte, error) {
+	if src.Command == "" {
+		return nil, ErrExecCommandMissing
+	}
+
+	timeout := src.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExecTimeout
+	}
+
+	maxSize := src.MaxOutputSize
+	if maxSize <= 0 {
+		maxSize = DefaultExecMaxOutputSize
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: maxSize}
+	stderr := &limitedBuffer{limit: execStderrLimit, truncate: true}
+
+	cmd := exec.CommandContext(ctx, src.Command, src.Args...) //nolint:gosec
+	cmd.Dir = src.Dir
+	cmd.Env = append(os.Environ(), src.Env...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// do not wait for the pipes of orphaned child processes after the command was killed
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, fmt.Errorf("%w: %s after %s", ErrExecTimeout, src.Command, timeout)
+	case stdout.exceeded:
+		return nil, fmt.Errorf("%w of %d bytes: %s", ErrExecOutputTooLarge, maxSize, src.Command)
+	case err != nil:
+		return nil, fmt.Errorf("%w: %s: %w: %s", ErrExecFailed, src.Command, err, sanitizeExecOutput(stderr.Bytes()))
+	}
+
+	value := sanitizeExecOutput(stdout.Bytes())
+	if len(value) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrExecEmptyOutput, src.Command)
+	}
+
+	return parseFile(value, CredentialFile{Format: src.Format, JSONPath: src.JSONPath})
+}
+
+// sanitizeExecOutput removes terminal escape sequences and surrounding whitespace.
+func sanitizeExecOutput(output []byte) []byte {
+	return bytes.TrimSpace(ansiEscapeSequenceExpr.ReplaceAll(output, nil))
+}
+
+// limitedBuffer buffers up to limit bytes. Writes beyond the limit fail, which stops the
+// copy from the command, unless truncate is set, in which case they are discarded.
+type limitedBuffer struct {
+	// buf is not embedded, so io.Copy cannot bypass the limit with bytes.Buffer.ReadFrom
+	buf      bytes.Buffer
+	limit    int
+	truncate bool
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - b.buf.Len()
+	if len(p) <= remaining {
+		return b.buf.Write(p)
+	}
+
+	b.exceeded = true
+	b.buf.Write(p[:max(remaining, 0)])
+
+	if b.truncate {
+		return len(p), nil
+	}
+
+	return 0, ErrExecOutputTooLarge
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
@@ -0,0 +1,96 @@
+package commoncfg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func execRef(script string) *commoncfg.SourceRef {
+	return &commoncfg.SourceRef{
+		Source: commoncfg.ExecSourceValue,
+		Exec: commoncfg.ExecSource{
+			Command: "sh",
+			Args:    []string{"-c", script},
+		},
+	}
+}
+
+func TestExtractValueFromSourceRef_Exec(t *testing.T) {
+	t.Run("returns the sanitized output", func(t *testing.T) {
+		val, err := commoncfg.ExtractValueFromSourceRef(execRef(`printf '\033[32m  s3cret \033[0m\n'`))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("s3cret"), val)
+	})
+
+	t.Run("passes the environment and working directory", func(t *testing.T) {
+		dir := t.TempDir()
+
+		ref := execRef(`echo "$TOKEN_PREFIX-$(pwd)"`)
+		ref.Exec.Env = []string{"TOKEN_PREFIX=abc"}
+		ref.Exec.Dir = dir
+
+		val, err := commoncfg.ExtractValueFromSourceRef(ref)
+		require.NoError(t, err)
+		assert.Equal(t, "abc-"+dir, string(val))
+	})
+
+	t.Run("applies the json path", func(t *testing.T) {
+		ref := execRef(`echo '{"data":{"password":"from-json"}}'`)
+		ref.Exec.Format = commoncfg.JSONFileFormat
+		ref.Exec.JSONPath = "$.data.password"
+
+		val, err := commoncfg.ExtractValueFromSourceRef(ref)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("from-json"), val)
+	})
+
+	t.Run("errors on failed commands without exposing the output", func(t *testing.T) {
+		_, err := commoncfg.ExtractValueFromSourceRef(execRef(`echo leaked; echo denied >&2; exit 3`))
+		require.ErrorIs(t, err, commoncfg.ErrExecFailed)
+		assert.Contains(t, err.Error(), "denied")
+		assert.NotContains(t, err.Error(), "leaked")
+	})
+
+	t.Run("errors on timeouts", func(t *testing.T) {
+		ref := execRef(`sleep 5`)
+		ref.Exec.Timeout = 100 * time.Millisecond
+
+		start := time.Now()
+		_, err := commoncfg.ExtractValueFromSourceRef(ref)
+		require.ErrorIs(t, err, commoncfg.ErrExecTimeout)
+		assert.Less(t, time.Since(start), 3*time.Second)
+	})
+
+	t.Run("errors on empty output", func(t *testing.T) {
+		_, err := commoncfg.ExtractValueFromSourceRef(execRef(`echo "   "`))
+		assert.ErrorIs(t, err, commoncfg.ErrExecEmptyOutput)
+	})
+
+	t.Run("errors on too large output", func(t *testing.T) {
+		ref := execRef(`head -c 2048 /dev/zero | tr '\0' a`)
+		ref.Exec.MaxOutputSize = 1024
+
+		_, err := commoncfg.ExtractValueFromSourceRef(ref)
+		assert.ErrorIs(t, err, commoncfg.ErrExecOutputTooLarge)
+	})
+
+	t.Run("errors on unknown commands", func(t *testing.T) {
+		ref := &commoncfg.SourceRef{
+			Source: commoncfg.ExecSourceValue,
+			Exec:   commoncfg.ExecSource{Command: "does-not-exist-commoncfg"},
+		}
+
+		_, err := commoncfg.ExtractValueFromSourceRef(ref)
+		assert.ErrorIs(t, err, commoncfg.ErrExecFailed)
+	})
+
+	t.Run("errors without command", func(t *testing.T) {
+		_, err := commoncfg.ExtractValueFromSourceRef(&commoncfg.SourceRef{Source: commoncfg.ExecSourceValue})
+		assert.ErrorIs(t, err, commoncfg.ErrExecCommandMissing)
+	})
+}
@@ -0,0 +1,92 @@
+package commoncfg
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrNoCertificateFound = errors.New("no certificate found in PEM data")
+
+// CertificateExpiry holds the validity metadata of a certificate.
+type CertificateExpiry struct {
+	Subject      string    `json:"subject"`
+	SerialNumber string    `json:"serialNumber"`
+	NotBefore    time.Time `json:"notBefore"`
+	NotAfter     time.Time `json:"notAfter"`
+}
+
+// NewCertificateExpiry extracts the expiry metadata of the given certificate.
+func NewCertificateExpiry(cert *x509.Certificate) CertificateExpiry {
+	return CertificateExpiry{
+		Subject:      cert.Subject.String(),
+		SerialNumber: cert.SerialNumber.String(),
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+	}
+}
+
+// Remaining returns the validity left at the given time; it is negative once the certificate expired.
+func (e CertificateExpiry) Remaining(now time.Time) time.Duration {
+	return e.NotAfter.Sub(now)
+}
+
+// DaysRemaining returns the remaining validity in (fractional) days at the given time.
+func (e CertificateExpiry) DaysRemaining(now time.Time) float64 {
+	return math.Round(e.Remaining(now).Hours()/24*100) / 100
+}
+
+// IsExpired reports whether the certificate is expired at the given time.
+func (e CertificateExpiry) IsExpired(now time.Time) bool {
+	return !now.Before(e.NotAfter)
+}
+
+// ParseCertificateExpiry returns the expiry metadata of the first certificate in the PEM data,
+// which is the leaf certificate for certificate chains.
+func ParseCertificateExpiry(pemData []byte) (CertificateExpiry, error) {
+	for {
+		var block *pem.Block
+
+		block, pemData = pem.Decode(pemData)
+		if block == nil {
+			return CertificateExpiry{}, ErrNoCertificateFound
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return CertificateExpiry{}, err
+		}
+
+		return NewCertificateExpiry(cert), nil
+	}
+}
+
+// LoadMTLSCertificateExpiry loads the client certificate of the mTLS configuration
+// and returns its expiry metadata.
+func LoadMTLSCertificateExpiry(cfg *MTLS) (CertificateExpiry, error) {
+	if cfg == nil {
+		return CertificateExpiry{}, ErrMTLSIsNil
+	}
+
+	if cfg.usesPKCS12() {
+		cert, err := LoadPKCS12Certificate(cfg.PKCS12, cfg.PKCS12Passphrase)
+		if err != nil {
+			return CertificateExpiry{}, err
+		}
+
+		return NewCertificateExpiry(cert.Leaf), nil
+	}
+
+	certPEMBlock, err := ExtractValueFromSourceRef(&cfg.Cert)
+	if err != nil {
+		return CertificateExpiry{}, err
+	}
+
+	return ParseCertificateExpiry(certPEMBlock)
+}
@@ -0,0 +1,63 @@
+package commoncfg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestParseCertificateExpiry(t *testing.T) {
+	certPEM, keyPEM := generateTestCert(t)
+
+	t.Run("leaf after other blocks", func(t *testing.T) {
+		expiry, err := commoncfg.ParseCertificateExpiry(append(keyPEM, certPEM...))
+		require.NoError(t, err)
+		assert.Equal(t, "CN=test", expiry.Subject)
+		assert.Equal(t, "1", expiry.SerialNumber)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), expiry.NotAfter, time.Minute)
+	})
+
+	t.Run("no certificate", func(t *testing.T) {
+		_, err := commoncfg.ParseCertificateExpiry(keyPEM)
+		assert.ErrorIs(t, err, commoncfg.ErrNoCertificateFound)
+	})
+
+	t.Run("invalid certificate", func(t *testing.T) {
+		_, err := commoncfg.ParseCertificateExpiry([]byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestCertificateExpiryRemaining(t *testing.T) {
+	notAfter := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	expiry := commoncfg.CertificateExpiry{NotAfter: notAfter}
+
+	assert.InDelta(t, 30.5, expiry.DaysRemaining(notAfter.Add(-30*24*time.Hour-12*time.Hour)), 0.001)
+	assert.False(t, expiry.IsExpired(notAfter.Add(-time.Second)))
+	assert.True(t, expiry.IsExpired(notAfter))
+	assert.Negative(t, expiry.Remaining(notAfter.Add(time.Hour)))
+}
+
+func TestLoadMTLSCertificateExpiry(t *testing.T) {
+	certPEM, keyPEM := generateTestCert(t)
+
+	_, err := commoncfg.LoadMTLSCertificateExpiry(nil)
+	require.ErrorIs(t, err, commoncfg.ErrMTLSIsNil)
+
+	mtls := &commoncfg.MTLS{
+		Cert:    commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(certPEM)},
+		CertKey: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(keyPEM)},
+	}
+
+	expiry, err := commoncfg.LoadMTLSCertificateExpiry(mtls)
+	require.NoError(t, err)
+
+	cert, err := commoncfg.LoadMTLSClientCertificate(mtls)
+	require.NoError(t, err)
+	require.NotNil(t, cert.Leaf)
+	assert.Equal(t, cert.Leaf.NotAfter, expiry.NotAfter)
+}
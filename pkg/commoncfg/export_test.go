@@ -0,0 +1,6 @@
+package commoncfg
+
+// SetResolverLoadFunc replaces the function used by the resolver to load values.
+func SetResolverLoadFunc(r *Resolver, load func(*SourceRef) ([]byte, error)) {
+	r.load = load
+}
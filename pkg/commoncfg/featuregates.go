@@ -0,0 +1,284 @@
+package commoncfg
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// AllFeatures subscribes to the changes of all feature gates, see DynamicFeatureGates.Subscribe.
+const AllFeatures = "*"
+
+// FeatureGateListener is called with the new state of a feature gate which was changed,
+// enabled reports whether it is now enabled for all tenants (see FeatureGates.IsFeatureEnabled).
+// Listeners of gates with tenant rules evaluate them with DynamicFeatureGates.IsFeatureEnabledFor.
+type FeatureGateListener func(feature string, enabled bool)
+
+// DynamicFeatureGates holds feature gates which can change at runtime, e.g. when the config
+// file is reloaded (see Loader.Watch) or a remote source changes, and notifies the
+// components which subscribed to a gate about its changes. A gate which is not set is
+// disabled, so removing an enabled gate notifies its listeners as well.
+//
+// DynamicFeatureGates is safe for concurrent use.
+type DynamicFeatureGates struct {
+	mu        sync.RWMutex
+	gates     FeatureGates
+	listeners map[string][]*featureGateSubscription
+}
+
+type featureGateSubscription struct {
+	listener FeatureGateListener
+}
+
+// NewDynamicFeatureGates creates dynamic feature gates with the initial gates.
+func NewDynamicFeatureGates(gates FeatureGates) *DynamicFeatureGates {
+	return &DynamicFeatureGates{
+		gates:     maps.Clone(gates),
+		listeners: make(map[string][]*featureGateSubscription),
+	}
+}
+
+// IsFeatureEnabled reports whether the feature is currently enabled for all tenants.
+func (d *DynamicFeatureGates) IsFeatureEnabled(feature string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.gates.IsFeatureEnabled(feature)
+}
+
+// IsFeatureEnabledFor reports whether the feature is currently enabled for the tenant.
+func (d *DynamicFeatureGates) IsFeatureEnabledFor(feature, tenantID string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.gates.IsFeatureEnabledFor(feature, tenantID)
+}
+
+// Feature returns the current state of the feature, or ErrFeatureNotFound if it is not set.
+func (d *DynamicFeatureGates) Feature(feature string) (bool, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.gates.Feature(feature)
+}
+
+// EnabledFeatures returns the sorted names of all features that are currently switched on.
+func (d *DynamicFeatureGates) EnabledFeatures() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.gates.EnabledFeatures()
+}
+
+// Gates returns a copy of the current feature gates.
+func (d *DynamicFeatureGates) Gates() FeatureGates {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return maps.Clone(d.gates)
+}
+
+// Subscribe registers a listener called each time the gate of the feature changes, or each
+// time any gate changes for AllFeatures. The listener is called synchronously by Update, so it
+// must not block. The returned function removes the subscription.
+func (d *DynamicFeatureGates) Subscribe(feature string, listener FeatureGateListener) func() {
+	sub := &featureGateSubscription{listener: listener}
+
+	d.mu.Lock()
+	d.listeners[feature] = append(d.listeners[feature], sub)
+	d.mu.Unlock()
+
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		d.listeners[feature] = slices.DeleteFunc(d.listeners[feature], func(s *featureGateSubscription) bool {
+			return s == sub
+		})
+
+		if len(d.listeners[feature]) == 0 {
+			delete(d.listeners, feature)
+		}
+	}
+}
+
+// Update replaces the feature gates and notifies the listeners of the changed gates,
+// in the order of the feature names.
+func (d *DynamicFeatureGates) Update(gates FeatureGates) {
+	type notification struct {
+		feature string
+		enabled bool
+		subs    []*featureGateSubscription
+	}
+
+	d.mu.Lock()
+
+	features := slices.Collect(maps.Keys(d.gates))
+	for feature := range gates {
+		if _, ok := d.gates[feature]; !ok {
+			features = append(features, feature)
+		}
+	}
+
+	slices.Sort(features)
+
+	var notifications []notification
+
+	for _, feature := range features {
+		if d.gates[feature].equal(gates[feature]) {
+			continue
+		}
+
+		subs := slices.Concat(d.listeners[feature], d.listeners[AllFeatures])
+		if len(subs) > 0 {
+			notifications = append(notifications, notification{feature: feature, enabled: gates.IsFeatureEnabled(feature), subs: subs})
+		}
+	}
+
+	d.gates = maps.Clone(gates)
+	d.mu.Unlock()
+
+	// notify outside the lock, so listeners can read the gates
+	for _, n := range notifications {
+		for _, sub := range n.subs {
+			sub.listener(n.feature, n.enabled)
+		}
+	}
+}
+
+// OnReload updates the gates from the feature gates of a reloaded configuration, which is a
+// BaseConfig or a struct embedding one. It can be passed to Loader.Watch directly or be
+// called from another ReloadFunc. Failed reloads keep the current gates.
+func (d *DynamicFeatureGates) OnReload(cfg any, _ []Change, err error) {
+	if err != nil {
+		return
+	}
+
+	gates, ok := featureGatesOf(reflect.ValueOf(cfg))
+	if ok {
+		d.Update(gates)
+	}
+}
+
+// featureGatesOf returns the FeatureGates field of a struct or of its embedded structs.
+func featureGatesOf(val reflect.Value) (FeatureGates, bool) {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil, false
+		}
+
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	for i := range val.NumField() {
+		field := val.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Type == reflect.TypeFor[FeatureGates]() {
+			return val.Field(i).Interface().(FeatureGates), true //nolint:forcetypeassert
+		}
+
+		if _, inline := fieldName(field); inline {
+			if gates, ok := featureGatesOf(val.Field(i)); ok {
+				return gates, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// enabledForAll reports whether the gate is on without restriction to tenants.
+func (g FeatureGate) enabledForAll() bool {
+	return g.Enabled && (g.Percentage >= 100 || g.Percentage == 0 && len(g.Tenants) == 0)
+}
+
+// enabledFor reports whether the gate of the feature is on for the tenant.
+func (g FeatureGate) enabledFor(feature, tenantID string) bool {
+	switch {
+	case !g.Enabled:
+		return false
+	case g.enabledForAll():
+		return true
+	case tenantID == "":
+		return false
+	case slices.Contains(g.Tenants, tenantID):
+		return true
+	default:
+		return rolloutBucket(feature, tenantID) < g.Percentage
+	}
+}
+
+// equal reports whether both gates have the same rule, where a missing gate is a disabled one.
+func (g FeatureGate) equal(other FeatureGate) bool {
+	return g.Enabled == other.Enabled && g.Percentage == other.Percentage && slices.Equal(g.Tenants, other.Tenants)
+}
+
+// rolloutBucket assigns the tenant to one of 100 buckets of the feature. The feature name is
+// part of the hash, so the rollouts of different features select different tenants.
+func rolloutBucket(feature, tenantID string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(feature))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(tenantID))
+
+	return int(h.Sum32() % 100)
+}
+
+// UnmarshalJSON accepts a boolean as a shorthand for a gate without tenant rules.
+func (g *FeatureGate) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if json.Unmarshal(data, &enabled) == nil {
+		*g = FeatureGate{Enabled: enabled}
+		return nil
+	}
+
+	type rule FeatureGate
+
+	return json.Unmarshal(data, (*rule)(g))
+}
+
+// UnmarshalYAML accepts a boolean as a shorthand for a gate without tenant rules.
+func (g *FeatureGate) UnmarshalYAML(unmarshal func(any) error) error {
+	var enabled bool
+	if unmarshal(&enabled) == nil {
+		*g = FeatureGate{Enabled: enabled}
+		return nil
+	}
+
+	type rule FeatureGate
+
+	return unmarshal((*rule)(g))
+}
+
+// featureGateDecodeHook decodes booleans, and strings holding one, e.g. from environment
+// variables, into a FeatureGate when the configuration is loaded.
+func featureGateDecodeHook(from, to reflect.Type, data any) (any, error) {
+	if to != reflect.TypeFor[FeatureGate]() {
+		return data, nil
+	}
+
+	switch from.Kind() {
+	case reflect.Bool:
+		return FeatureGate{Enabled: data.(bool)}, nil //nolint:forcetypeassert
+	case reflect.String:
+		enabled, err := strconv.ParseBool(data.(string)) //nolint:forcetypeassert
+		if err != nil {
+			return data, nil //nolint:nilerr // left to the decoder to report
+		}
+
+		return FeatureGate{Enabled: enabled}, nil
+	default:
+		return data, nil
+	}
+}
@@ -0,0 +1,174 @@
+package commoncfg_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+type featureEvent struct {
+	feature string
+	enabled bool
+}
+
+func TestDynamicFeatureGates(t *testing.T) {
+	gates := commoncfg.NewDynamicFeatureGates(commoncfg.FeatureGates{"a": {Enabled: true}, "b": {Enabled: false}})
+
+	var events, all []featureEvent
+
+	unsubscribe := gates.Subscribe("b", func(feature string, enabled bool) {
+		events = append(events, featureEvent{feature, enabled})
+	})
+	gates.Subscribe(commoncfg.AllFeatures, func(feature string, enabled bool) {
+		all = append(all, featureEvent{feature, enabled})
+	})
+
+	gates.Update(commoncfg.FeatureGates{"b": {Enabled: true}, "c": {Enabled: true}, "d": {Enabled: false}})
+
+	assert.Equal(t, []featureEvent{{"b", true}}, events)
+	assert.Equal(t, []featureEvent{{"a", false}, {"b", true}, {"c", true}}, all)
+	assert.False(t, gates.IsFeatureEnabled("a"))
+	assert.True(t, gates.IsFeatureEnabled("b"))
+	assert.Equal(t, []string{"b", "c"}, gates.EnabledFeatures())
+
+	_, err := gates.Feature("a")
+	require.ErrorIs(t, err, commoncfg.ErrFeatureNotFound)
+
+	unsubscribe()
+	gates.Update(commoncfg.FeatureGates{"b": {Enabled: false}, "c": {Enabled: true}})
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, featureEvent{"b", false}, all[len(all)-1])
+}
+
+func TestDynamicFeatureGatesGatesIsCopy(t *testing.T) {
+	initial := commoncfg.FeatureGates{"a": {Enabled: true}}
+	gates := commoncfg.NewDynamicFeatureGates(initial)
+
+	initial["a"] = commoncfg.FeatureGate{}
+	gates.Gates()["a"] = commoncfg.FeatureGate{}
+
+	assert.True(t, gates.IsFeatureEnabled("a"))
+}
+
+func TestDynamicFeatureGatesOnReload(t *testing.T) {
+	type config struct {
+		commoncfg.BaseConfig `yaml:",inline"`
+
+		Custom string
+	}
+
+	gates := commoncfg.NewDynamicFeatureGates(nil)
+
+	var events []featureEvent
+
+	gates.Subscribe("a", func(feature string, enabled bool) {
+		events = append(events, featureEvent{feature, enabled})
+	})
+
+	gates.OnReload(&config{BaseConfig: commoncfg.BaseConfig{FeatureGates: commoncfg.FeatureGates{"a": {Enabled: true}}}}, nil, nil)
+	assert.Equal(t, []featureEvent{{"a", true}}, events)
+
+	gates.OnReload(nil, nil, assert.AnError)
+	gates.OnReload(&struct{ Name string }{}, nil, nil)
+
+	assert.True(t, gates.IsFeatureEnabled("a"))
+	assert.Len(t, events, 1)
+}
+
+func TestFeatureGatesIsFeatureEnabledFor(t *testing.T) {
+	gates := commoncfg.FeatureGates{
+		"all":       {Enabled: true},
+		"off":       {Enabled: false, Tenants: []string{"t1"}},
+		"allowlist": {Enabled: true, Tenants: []string{"t1"}},
+		"full":      {Enabled: true, Percentage: 100},
+		"rollout":   {Enabled: true, Percentage: 20, Tenants: []string{"t1"}},
+	}
+
+	assert.True(t, gates.IsFeatureEnabledFor("all", "t2"))
+	assert.True(t, gates.IsFeatureEnabledFor("all", ""))
+	assert.False(t, gates.IsFeatureEnabledFor("off", "t1"))
+	assert.True(t, gates.IsFeatureEnabledFor("allowlist", "t1"))
+	assert.False(t, gates.IsFeatureEnabledFor("allowlist", "t2"))
+	assert.True(t, gates.IsFeatureEnabledFor("full", "t2"))
+	assert.True(t, gates.IsFeatureEnabledFor("rollout", "t1"))
+	assert.False(t, gates.IsFeatureEnabledFor("rollout", ""))
+	assert.False(t, gates.IsFeatureEnabledFor("missing", "t1"))
+
+	assert.Equal(t, []string{"all", "full"}, gates.EnabledFeatures())
+	assert.False(t, gates.IsFeatureEnabled("rollout"))
+}
+
+func TestFeatureGatesPercentageRollout(t *testing.T) {
+	gates := commoncfg.FeatureGates{
+		"a": {Enabled: true, Percentage: 20},
+		"b": {Enabled: true, Percentage: 50},
+	}
+
+	enabled := 0
+
+	for i := range 1000 {
+		tenant := fmt.Sprintf("tenant-%d", i)
+
+		if gates.IsFeatureEnabledFor("a", tenant) {
+			enabled++
+		}
+	}
+
+	assert.InDelta(t, 200, enabled, 50)
+
+	// raising the percentage keeps the tenants of the feature
+	gates["a"] = commoncfg.FeatureGate{Enabled: true, Percentage: 50}
+	for i := range 1000 {
+		tenant := fmt.Sprintf("tenant-%d", i)
+		if (commoncfg.FeatureGates{"a": {Enabled: true, Percentage: 20}}).IsFeatureEnabledFor("a", tenant) {
+			assert.True(t, gates.IsFeatureEnabledFor("a", tenant))
+		}
+	}
+}
+
+func TestFeatureGateUnmarshal(t *testing.T) {
+	var gates commoncfg.FeatureGates
+
+	err := json.Unmarshal([]byte(`{"a":true,"b":{"enabled":true,"percentage":10,"tenants":["t1"]}}`), &gates)
+	require.NoError(t, err)
+	assert.Equal(t, commoncfg.FeatureGates{
+		"a": {Enabled: true},
+		"b": {Enabled: true, Percentage: 10, Tenants: []string{"t1"}},
+	}, gates)
+
+	gates = nil
+	err = yaml.Unmarshal([]byte("a: false\nb:\n  enabled: true\n  tenants: [t1]\n"), &gates)
+	require.NoError(t, err)
+	assert.Equal(t, commoncfg.FeatureGates{
+		"a": {},
+		"b": {Enabled: true, Tenants: []string{"t1"}},
+	}, gates)
+}
+
+func TestLoadConfigFeatureGates(t *testing.T) {
+	tmpdir := t.TempDir()
+	content := "featureGates:\n  simple: true\n  rollout:\n    enabled: true\n    percentage: 30\n    tenants: [t1]\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "config.yaml"), []byte(content), 0o600))
+	t.Setenv("FEATUREGATES_SIMPLE", "false")
+
+	cfg := &commoncfg.BaseConfig{}
+	err := commoncfg.NewLoader(cfg, commoncfg.WithPaths(tmpdir), commoncfg.WithEnvOverride(""), commoncfg.WithValidation()).LoadConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, commoncfg.FeatureGates{
+		"simple":  {},
+		"rollout": {Enabled: true, Percentage: 30, Tenants: []string{"t1"}},
+	}, cfg.FeatureGates)
+
+	invalid := &commoncfg.BaseConfig{FeatureGates: commoncfg.FeatureGates{"a": {Enabled: true, Percentage: 101}}}
+	require.ErrorIs(t, invalid.Validate(), commoncfg.ErrInvalidConfig)
+}
@@ -0,0 +1,22 @@
+package commoncfg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Fingerprint computes a stable SHA-256 digest of the given configuration.
+// The configuration is serialized as JSON before hashing, so two configurations
+// holding the same values produce the same fingerprint. The digest is returned
+// hex encoded and never exposes the configuration values themselves.
+func Fingerprint(cfg any) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
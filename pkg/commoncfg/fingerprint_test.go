@@ -0,0 +1,37 @@
+package commoncfg_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestFingerprint(t *testing.T) {
+	cfg := commoncfg.BaseConfig{Application: commoncfg.Application{Name: "app"}}
+
+	first, err := commoncfg.Fingerprint(cfg)
+	require.NoError(t, err)
+	assert.Len(t, first, 64)
+
+	second, err := commoncfg.Fingerprint(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	cfg.Application.Name = "other"
+	changed, err := commoncfg.Fingerprint(cfg)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, changed)
+
+	_, err = commoncfg.Fingerprint(make(chan int))
+	assert.Error(t, err)
+}
+
+func TestFeatureGatesEnabledFeatures(t *testing.T) {
+	fg := commoncfg.FeatureGates{"zeta": {Enabled: true}, "alpha": {Enabled: true}, "beta": {Enabled: false}}
+
+	assert.Equal(t, []string{"alpha", "zeta"}, fg.EnabledFeatures())
+	assert.Empty(t, commoncfg.FeatureGates(nil).EnabledFeatures())
+}
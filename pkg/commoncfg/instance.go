@@ -0,0 +1,188 @@
+package commoncfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// PodUIDEnv is the environment variable holding the pod UID, usually set from the
+	// Kubernetes downward API (fieldRef: metadata.uid).
+	PodUIDEnv = "POD_UID"
+
+	instanceFileSuffix = ".instance.json"
+	defInstanceName    = "application"
+)
+
+var (
+	// instanceLocks holds the locks of the state files used by this process until it exits.
+	instanceLocks   = make(map[string]*os.File)
+	instanceLocksMu sync.Mutex
+)
+
+// Instance identifies a running instance of the application across restarts.
+type Instance struct {
+	// ID is a stable UUID of the instance, derived from the pod UID when available.
+	ID string `json:"id"`
+	// RestartCount is the number of times the instance was restarted.
+	RestartCount int64 `json:"restartCount"`
+}
+
+type instanceConfig struct {
+	name     string
+	stateDir string
+	podUID   string
+}
+
+// InstanceOption configures LoadInstance.
+type InstanceOption func(*instanceConfig)
+
+// WithInstanceName sets the application name used to name the state file. Defaults to "application".
+// Path separators and characters other than letters, digits, '.', '-' and '_' are not used in the file name.
+func WithInstanceName(name string) InstanceOption {
+	return func(c *instanceConfig) {
+		if name != "" {
+			c.name = name
+		}
+	}
+}
+
+// WithInstanceStateDir sets the directory of the state file. Defaults to os.TempDir().
+// To count container restarts in Kubernetes, the directory must outlive the container,
+// e.g. an emptyDir volume.
+func WithInstanceStateDir(dir string) InstanceOption {
+	return func(c *instanceConfig) {
+		if dir != "" {
+			c.stateDir = dir
+		}
+	}
+}
+
+// WithInstancePodUID sets the pod UID the instance ID is derived from. Defaults to the POD_UID environment variable.
+func WithInstancePodUID(uid string) InstanceOption {
+	return func(c *instanceConfig) {
+		c.podUID = uid
+	}
+}
+
+// LoadInstance returns the identity of the running instance and increments its restart counter.
+//
+// The identity is persisted to a state file. If the file exists and belongs to the
+// same pod, the ID is kept and the restart counter is incremented; otherwise a new
+// identity is created. The ID is derived from the pod UID when available, so it is
+// the same for all containers of a pod, or generated randomly otherwise.
+//
+// The state file is locked until the process exits, where supported. If another process
+// of the application holds the lock, e.g. a second process on the same host sharing the
+// state directory, a new identity is returned without restart tracking, so the processes
+// do not share an instance ID unless it is derived from the pod UID.
+//
+// If the state file cannot be written, the instance is still returned together with
+// the error, so callers can decide to continue without restart tracking.
+func LoadInstance(opts ...InstanceOption) (Instance, error) {
+	cfg := &instanceConfig{
+		name:     defInstanceName,
+		stateDir: os.TempDir(),
+		podUID:   strings.TrimSpace(os.Getenv(PodUIDEnv)),
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+
+	id := uuid.NewString()
+	if cfg.podUID != "" {
+		id = uuid.NewSHA1(uuid.NameSpaceURL, []byte("k8s://pod/"+cfg.podUID+"/"+cfg.name)).String()
+	}
+
+	path := filepath.Join(cfg.stateDir, instanceFileName(cfg.name)+instanceFileSuffix)
+	inst := Instance{ID: id}
+
+	locked, err := lockInstanceFile(path)
+	if err != nil {
+		return inst, fmt.Errorf("locking instance state: %w", err)
+	}
+
+	if !locked {
+		return inst, nil
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var previous Instance
+		// a corrupted state file starts a new identity
+		if json.Unmarshal(data, &previous) == nil && previous.ID != "" &&
+			(cfg.podUID == "" || previous.ID == id) {
+			inst = Instance{ID: previous.ID, RestartCount: previous.RestartCount + 1}
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return inst, fmt.Errorf("reading instance state: %w", err)
+	}
+
+	data, err = json.Marshal(inst)
+	if err != nil {
+		return inst, err
+	}
+
+	err = os.WriteFile(path, data, 0o600)
+	if err != nil {
+		return inst, fmt.Errorf("writing instance state: %w", err)
+	}
+
+	return inst, nil
+}
+
+// lockInstanceFile locks the state file for this process, and reports false if another
+// process holds the lock.
+func lockInstanceFile(path string) (bool, error) {
+	instanceLocksMu.Lock()
+	defer instanceLocksMu.Unlock()
+
+	if _, ok := instanceLocks[path]; ok {
+		return true, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return false, err
+	}
+
+	locked, err := tryLockFile(file)
+	if err != nil || !locked {
+		_ = file.Close()
+		return false, err
+	}
+
+	instanceLocks[path] = file
+
+	return true, nil
+}
+
+// instanceFileName returns the name of the state file of the application, keeping the
+// file within the state directory whatever the name of the application.
+func instanceFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, filepath.Base(name))
+
+	if strings.Trim(name, ".") == "" {
+		return defInstanceName
+	}
+
+	return name
+}
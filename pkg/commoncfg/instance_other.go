@@ -0,0 +1,10 @@
+//go:build !unix
+
+package commoncfg
+
+import "os"
+
+// tryLockFile does not lock the file on platforms without flock.
+func tryLockFile(*os.File) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,102 @@
+package commoncfg_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestLoadInstanceCountsRestarts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(commoncfg.PodUIDEnv, "")
+
+	first, err := commoncfg.LoadInstance(commoncfg.WithInstanceStateDir(dir), commoncfg.WithInstanceName("svc"))
+	require.NoError(t, err)
+	require.NoError(t, uuid.Validate(first.ID))
+	assert.Equal(t, int64(0), first.RestartCount)
+
+	second, err := commoncfg.LoadInstance(commoncfg.WithInstanceStateDir(dir), commoncfg.WithInstanceName("svc"))
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, int64(1), second.RestartCount)
+
+	// another application gets its own identity
+	other, err := commoncfg.LoadInstance(commoncfg.WithInstanceStateDir(dir), commoncfg.WithInstanceName("other"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, other.ID)
+	assert.Equal(t, int64(0), other.RestartCount)
+}
+
+func TestLoadInstanceFromPodUID(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(commoncfg.PodUIDEnv, "2f1c3b9e-7a4d-4c8e-9d2b-1a5f6e7c8d9a")
+
+	first, err := commoncfg.LoadInstance(commoncfg.WithInstanceStateDir(dir))
+	require.NoError(t, err)
+
+	// the ID is derived from the pod UID even without a state file
+	require.NoError(t, os.RemoveAll(dir))
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+
+	again, err := commoncfg.LoadInstance(commoncfg.WithInstanceStateDir(dir))
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, int64(0), again.RestartCount)
+
+	restarted, err := commoncfg.LoadInstance(commoncfg.WithInstanceStateDir(dir))
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, restarted.ID)
+	assert.Equal(t, int64(1), restarted.RestartCount)
+
+	// a new pod reusing the state directory starts over
+	moved, err := commoncfg.LoadInstance(commoncfg.WithInstanceStateDir(dir), commoncfg.WithInstancePodUID("new-pod"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, moved.ID)
+	assert.Equal(t, int64(0), moved.RestartCount)
+}
+
+func TestLoadInstanceCorruptedState(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(commoncfg.PodUIDEnv, "")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "application.instance.json"), []byte("{"), 0o600))
+
+	inst, err := commoncfg.LoadInstance(commoncfg.WithInstanceStateDir(dir))
+	require.NoError(t, err)
+	assert.NotEmpty(t, inst.ID)
+	assert.Equal(t, int64(0), inst.RestartCount)
+}
+
+func TestLoadInstanceStateNotWritable(t *testing.T) {
+	t.Setenv(commoncfg.PodUIDEnv, "")
+
+	inst, err := commoncfg.LoadInstance(commoncfg.WithInstanceStateDir(filepath.Join(t.TempDir(), "missing")))
+	require.Error(t, err)
+	assert.NotEmpty(t, inst.ID)
+}
+
+func TestLoadInstanceSanitizesName(t *testing.T) {
+	dir := t.TempDir()
+	stateDir := filepath.Join(dir, "state")
+	require.NoError(t, os.MkdirAll(stateDir, 0o700))
+	t.Setenv(commoncfg.PodUIDEnv, "")
+
+	for name, file := range map[string]string{
+		"../escape": "escape.instance.json",
+		"a/b c":     "b_c.instance.json",
+		"..":        "application.instance.json",
+	} {
+		_, err := commoncfg.LoadInstance(commoncfg.WithInstanceStateDir(stateDir), commoncfg.WithInstanceName(name))
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(stateDir, file), name)
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
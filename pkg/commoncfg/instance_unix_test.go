@@ -0,0 +1,40 @@
+//go:build unix
+
+package commoncfg_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestLoadInstanceLockedByAnotherProcess(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(commoncfg.PodUIDEnv, "")
+
+	path := filepath.Join(dir, "locked.instance.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"3b2f5c1e-0d8a-4f6b-9c7e-5a4d3c2b1a09","restartCount":4}`), 0o600))
+
+	// another open file description behaves like another process
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = file.Close() })
+	require.NoError(t, syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB))
+
+	inst, err := commoncfg.LoadInstance(commoncfg.WithInstanceStateDir(dir), commoncfg.WithInstanceName("locked"))
+	require.NoError(t, err)
+	assert.NotEqual(t, "3b2f5c1e-0d8a-4f6b-9c7e-5a4d3c2b1a09", inst.ID)
+	assert.Equal(t, int64(0), inst.RestartCount)
+
+	// the state of the lock holder is not modified
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"restartCount":4`)
+}
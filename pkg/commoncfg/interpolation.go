@@ -0,0 +1,35 @@
+package commoncfg
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envPlaceholder matches $${...} escapes, ${VAR} and ${VAR:-default} placeholders.
+var envPlaceholder = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// ExpandEnv replaces the ${VAR} and ${VAR:-default} placeholders in s with the values of
+// the environment variables. The default is used if the variable is unset or empty, and a
+// placeholder without default is replaced by an empty string. Use $${ to write a literal ${.
+// Other occurrences of $, e.g. $VAR, are left untouched.
+func ExpandEnv(s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+
+	return envPlaceholder.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+
+		groups := envPlaceholder.FindStringSubmatch(match)
+
+		value := os.Getenv(groups[1])
+		if value == "" {
+			value = groups[2]
+		}
+
+		return value
+	})
+}
@@ -0,0 +1,38 @@
+package commoncfg_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("TEST_INTERPOLATION_HOST", "db.example.com")
+	t.Setenv("TEST_INTERPOLATION_EMPTY", "")
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "no placeholder", input: "host: localhost", want: "host: localhost"},
+		{name: "set variable", input: "host: ${TEST_INTERPOLATION_HOST}", want: "host: db.example.com"},
+		{name: "default ignored when set", input: "${TEST_INTERPOLATION_HOST:-localhost}", want: "db.example.com"},
+		{name: "default for unset variable", input: "${TEST_INTERPOLATION_UNSET:-localhost:8080}", want: "localhost:8080"},
+		{name: "default for empty variable", input: "${TEST_INTERPOLATION_EMPTY:-fallback}", want: "fallback"},
+		{name: "empty default", input: "a${TEST_INTERPOLATION_UNSET:-}b", want: "ab"},
+		{name: "unset variable without default", input: "a${TEST_INTERPOLATION_UNSET}b", want: "ab"},
+		{name: "several placeholders", input: "${TEST_INTERPOLATION_HOST}:${TEST_INTERPOLATION_PORT:-5432}", want: "db.example.com:5432"},
+		{name: "escaped placeholder", input: "$${TEST_INTERPOLATION_HOST}", want: "${TEST_INTERPOLATION_HOST}"},
+		{name: "plain dollar untouched", input: "pa$$word $TEST_INTERPOLATION_HOST", want: "pa$$word $TEST_INTERPOLATION_HOST"},
+		{name: "invalid name untouched", input: "${1INVALID}", want: "${1INVALID}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, commoncfg.ExpandEnv(tt.input))
+		})
+	}
+}
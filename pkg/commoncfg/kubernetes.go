@@ -0,0 +1,387 @@
+package commoncfg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// KubernetesResourceKind defines the kind of Kubernetes resource holding the value.
+type KubernetesResourceKind string
+
+const (
+	KubernetesSecret    KubernetesResourceKind = "secret"
+	KubernetesConfigMap KubernetesResourceKind = "configmap"
+
+	DefaultKubernetesTimeout       = 10 * time.Second
+	DefaultKubernetesWatchBackoff  = 5 * time.Second
+	DefaultKubernetesTokenPath     = "/var/run/secrets/kubernetes.io/serviceaccount/token" //nolint:gosec
+	DefaultKubernetesCAPath        = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	DefaultKubernetesNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+var (
+	ErrKubernetesNameMissing     = errors.New("kubernetes resource name is missing")
+	ErrKubernetesKeyMissing      = errors.New("kubernetes resource key is missing")
+	ErrKubernetesKeyNotFound     = errors.New("key not found in kubernetes resource")
+	ErrKubernetesKindUnsupported = errors.New("unsupported kubernetes resource kind")
+	ErrKubernetesNotInCluster    = errors.New("kubernetes api server address is unknown, not running in a cluster")
+)
+
+// KubernetesSource describes a key of a Kubernetes Secret or ConfigMap read via the API server.
+// By default the in-cluster service account credentials are used.
+type KubernetesSource struct {
+	Kind KubernetesResourceKind `yaml:"kind" json:"kind" default:"secret" mapstructure:"kind" validate:"oneof=secret configmap"`
+	// Namespace of the resource. Defaults to the namespace of the pod.
+	Namespace string `yaml:"namespace" json:"namespace" mapstructure:"namespace"`
+	Name      string `yaml:"name" json:"name" mapstructure:"name"`
+	Key       string `yaml:"key" json:"key" mapstructure:"key"`
+	// APIServer overrides the API server URL; defaults to the in-cluster KUBERNETES_SERVICE_HOST/PORT.
+	APIServer string `yaml:"apiServer" json:"apiServer" mapstructure:"apiServer"`
+	// TokenPath overrides the service account token used as bearer token.
+	TokenPath string `yaml:"tokenPath" json:"tokenPath" mapstructure:"tokenPath"`
+	// CAPath overrides the CA bundle used to verify the API server.
+	CAPath string `yaml:"caPath" json:"caPath" mapstructure:"caPath"`
+	// Timeout bounds a single read of the resource.
+	Timeout time.Duration `yaml:"timeout" json:"timeout" mapstructure:"timeout"`
+}
+
+type kubernetesObject struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Data       map[string]string `json:"data"`
+	BinaryData map[string]string `json:"binaryData"`
+}
+
+type kubernetesWatchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+type kubernetesClient struct {
+	httpClient *http.Client
+	baseURL    string
+	tokenPath  string
+	src        *KubernetesSource
+	namespace  string
+	resource   string
+}
+
+// loadFromKubernetes reads the configured key of a Kubernetes Secret or ConfigMap.
+func loadFromKubernetes(src *KubernetesSource) ([]byte, error) {
+	client, err := newKubernetesClient(src)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := src.Timeout
+	if timeout <= 0 {
+		timeout = DefaultKubernetesTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	value, _, err := client.get(ctx)
+
+	return value, err
+}
+
+// WatchKubernetesSource keeps watching the Kubernetes resource referenced by src and
+// calls onChange with the initial value and each time the value of the key changes.
+// Transient errors are passed to onChange as well, after which the watch is re-established.
+// It blocks until ctx is cancelled and then returns the context error.
+func WatchKubernetesSource(ctx context.Context, src *KubernetesSource, onChange func(value []byte, err error)) error {
+	client, err := newKubernetesClient(src)
+	if err != nil {
+		return err
+	}
+
+	var (
+		last            []byte
+		resourceVersion string
+	)
+
+	for {
+		resourceVersion, err = client.watch(ctx, resourceVersion, func(value []byte) {
+			if last != nil && bytes.Equal(last, value) {
+				return
+			}
+
+			last = value
+			onChange(value, nil)
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err == nil {
+			// the server ended the watch, e.g. after its timeout, so re-watch from the last version
+			continue
+		}
+
+		onChange(nil, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(DefaultKubernetesWatchBackoff):
+		}
+	}
+}
+
+func newKubernetesClient(src *KubernetesSource) (*kubernetesClient, error) {
+	if strings.TrimSpace(src.Name) == "" {
+		return nil, ErrKubernetesNameMissing
+	}
+
+	if strings.TrimSpace(src.Key) == "" {
+		return nil, ErrKubernetesKeyMissing
+	}
+
+	var resource string
+
+	switch src.Kind {
+	case KubernetesSecret, "":
+		resource = "secrets"
+	case KubernetesConfigMap:
+		resource = "configmaps"
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrKubernetesKindUnsupported, src.Kind)
+	}
+
+	baseURL := strings.TrimRight(src.APIServer, "/")
+	if baseURL == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, ErrKubernetesNotInCluster
+		}
+
+		baseURL = "https://" + net.JoinHostPort(host, port)
+	}
+
+	namespace := src.Namespace
+	if namespace == "" {
+		data, err := os.ReadFile(DefaultKubernetesNamespacePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine pod namespace: %w", err)
+		}
+
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	tokenPath := src.TokenPath
+	if tokenPath == "" {
+		tokenPath = DefaultKubernetesTokenPath
+	}
+
+	httpClient, err := newKubernetesHTTPClient(src.CAPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kubernetesClient{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		tokenPath:  tokenPath,
+		src:        src,
+		namespace:  namespace,
+		resource:   resource,
+	}, nil
+}
+
+func newKubernetesHTTPClient(caPath string) (*http.Client, error) {
+	if caPath == "" {
+		caPath = DefaultKubernetesCAPath
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return &http.Client{}, nil
+	}
+
+	transport = transport.Clone()
+
+	caPEM, err := os.ReadFile(caPath)
+	switch {
+	case err == nil:
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", caPath)
+		}
+
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read kubernetes CA: %w", err)
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// get reads the resource and returns the value of the key and the resource version.
+func (c *kubernetesClient) get(ctx context.Context) ([]byte, string, error) {
+	resp, err := c.do(ctx, c.resourcePath()+"/"+url.PathEscape(c.src.Name))
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	var obj kubernetesObject
+
+	err = json.NewDecoder(resp.Body).Decode(&obj)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode kubernetes %s: %w", c.src.Kind, err)
+	}
+
+	value, err := c.extract(&obj)
+
+	return value, obj.Metadata.ResourceVersion, err
+}
+
+// watch streams the changes of the resource from the resource version, reading the resource
+// first if the version is empty. If the server ends the watch, it returns the resource version
+// of the last change to watch from; if the watch fails, it returns an empty version.
+func (c *kubernetesClient) watch(ctx context.Context, resourceVersion string, onValue func([]byte)) (string, error) {
+	if resourceVersion == "" {
+		value, version, err := c.get(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		onValue(value)
+
+		resourceVersion = version
+	}
+
+	query := url.Values{}
+	query.Set("watch", "true")
+	query.Set("allowWatchBookmarks", "true")
+	query.Set("fieldSelector", "metadata.name="+c.src.Name)
+	query.Set("resourceVersion", resourceVersion)
+
+	resp, err := c.do(ctx, c.resourcePath()+"?"+query.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		var event kubernetesWatchEvent
+
+		err = json.Unmarshal(scanner.Bytes(), &event)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode kubernetes watch event: %w", err)
+		}
+
+		switch event.Type {
+		case "ADDED", "MODIFIED", "BOOKMARK":
+			var obj kubernetesObject
+
+			err = json.Unmarshal(event.Object, &obj)
+			if err != nil {
+				return "", fmt.Errorf("failed to decode kubernetes %s: %w", c.src.Kind, err)
+			}
+
+			if obj.Metadata.ResourceVersion != "" {
+				resourceVersion = obj.Metadata.ResourceVersion
+			}
+
+			if event.Type == "BOOKMARK" {
+				continue
+			}
+
+			value, err := c.extract(&obj)
+			if err != nil {
+				return "", err
+			}
+
+			onValue(value)
+		case "DELETED":
+			return "", fmt.Errorf("kubernetes %s %s/%s was deleted", c.src.Kind, c.namespace, c.src.Name)
+		case "ERROR":
+			return "", fmt.Errorf("kubernetes watch failed: %s", string(event.Object))
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return "", err
+	}
+
+	return resourceVersion, nil
+}
+
+func (c *kubernetesClient) extract(obj *kubernetesObject) ([]byte, error) {
+	key := c.src.Key
+
+	if v, ok := obj.Data[key]; ok {
+		if c.resource == "secrets" {
+			return base64.StdEncoding.DecodeString(v)
+		}
+
+		return []byte(v), nil
+	}
+
+	if v, ok := obj.BinaryData[key]; ok {
+		return base64.StdEncoding.DecodeString(v)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrKubernetesKeyNotFound, key)
+}
+
+func (c *kubernetesClient) resourcePath() string {
+	return c.baseURL + "/api/v1/namespaces/" + url.PathEscape(c.namespace) + "/" + c.resource
+}
+
+func (c *kubernetesClient) do(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	// The projected service account token is rotated by the kubelet, hence read it for every request.
+	token, err := os.ReadFile(c.tokenPath)
+	switch {
+	case err == nil:
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return nil, fmt.Errorf("kubernetes api responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,302 @@
+package commoncfg_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+type kubernetesFixture struct {
+	server    *httptest.Server
+	tokenPath string
+	caPath    string
+	updates   chan string
+	endWatch  chan struct{}
+
+	mu            sync.Mutex
+	gets          int
+	watchVersions []string
+}
+
+func newKubernetesFixture(t *testing.T) *kubernetesFixture {
+	t.Helper()
+
+	f := &kubernetesFixture{updates: make(chan string), endWatch: make(chan struct{})}
+
+	var version atomic.Int64
+
+	version.Store(1)
+
+	secret := func(password string) map[string]any {
+		return map[string]any{
+			"metadata": map[string]any{"name": "db", "resourceVersion": strconv.FormatInt(version.Load(), 10)},
+			"data":     map[string]string{"password": base64.StdEncoding.EncodeToString([]byte(password))},
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/namespaces/apps/secrets/db", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sa-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		f.mu.Lock()
+		f.gets++
+		f.mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(secret("initial"))
+	})
+	mux.HandleFunc("GET /api/v1/namespaces/apps/configmaps/settings", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"metadata":   map[string]any{"name": "settings"},
+			"data":       map[string]string{"mode": "strict"},
+			"binaryData": map[string]string{"blob": base64.StdEncoding.EncodeToString([]byte{0x1, 0x2})},
+		})
+	})
+	mux.HandleFunc("GET /api/v1/namespaces/apps/secrets", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("watch") != "true" || r.URL.Query().Get("fieldSelector") != "metadata.name=db" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		f.mu.Lock()
+		f.watchVersions = append(f.watchVersions, r.URL.Query().Get("resourceVersion"))
+		f.mu.Unlock()
+
+		flusher, _ := w.(http.Flusher)
+		enc := json.NewEncoder(w)
+
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-f.endWatch:
+				return
+			case password := <-f.updates:
+				version.Add(1)
+				_ = enc.Encode(map[string]any{"type": "MODIFIED", "object": secret(password)})
+				flusher.Flush()
+			}
+		}
+	})
+
+	f.server = httptest.NewTLSServer(mux)
+	t.Cleanup(f.server.Close)
+
+	dir := t.TempDir()
+	f.tokenPath = filepath.Join(dir, "token")
+	f.caPath = filepath.Join(dir, "ca.crt")
+
+	require.NoError(t, os.WriteFile(f.tokenPath, []byte("sa-token\n"), 0o600))
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(f.caPath, caPEM, 0o600))
+
+	return f
+}
+
+func (f *kubernetesFixture) source(kind commoncfg.KubernetesResourceKind, name, key string) commoncfg.KubernetesSource {
+	return commoncfg.KubernetesSource{
+		Kind:      kind,
+		Namespace: "apps",
+		Name:      name,
+		Key:       key,
+		APIServer: f.server.URL,
+		TokenPath: f.tokenPath,
+		CAPath:    f.caPath,
+	}
+}
+
+func TestExtractValueFromSourceRef_Kubernetes(t *testing.T) {
+	f := newKubernetesFixture(t)
+
+	tests := []struct {
+		name    string
+		src     commoncfg.KubernetesSource
+		want    []byte
+		wantErr error
+	}{
+		{
+			name: "secret key",
+			src:  f.source(commoncfg.KubernetesSecret, "db", "password"),
+			want: []byte("initial"),
+		},
+		{
+			name: "configmap key",
+			src:  f.source(commoncfg.KubernetesConfigMap, "settings", "mode"),
+			want: []byte("strict"),
+		},
+		{
+			name: "configmap binary key",
+			src:  f.source(commoncfg.KubernetesConfigMap, "settings", "blob"),
+			want: []byte{0x1, 0x2},
+		},
+		{
+			name:    "missing key",
+			src:     f.source(commoncfg.KubernetesSecret, "db", "username"),
+			wantErr: commoncfg.ErrKubernetesKeyNotFound,
+		},
+		{
+			name:    "missing name",
+			src:     f.source(commoncfg.KubernetesSecret, "", "password"),
+			wantErr: commoncfg.ErrKubernetesNameMissing,
+		},
+		{
+			name:    "missing key name",
+			src:     f.source(commoncfg.KubernetesSecret, "db", ""),
+			wantErr: commoncfg.ErrKubernetesKeyMissing,
+		},
+		{
+			name:    "unsupported kind",
+			src:     f.source("pod", "db", "password"),
+			wantErr: commoncfg.ErrKubernetesKindUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := commoncfg.ExtractValueFromSourceRef(&commoncfg.SourceRef{
+				Source:     commoncfg.KubernetesSourceValue,
+				Kubernetes: tt.src,
+			})
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, value)
+		})
+	}
+}
+
+func TestExtractValueFromSourceRef_KubernetesUnauthorized(t *testing.T) {
+	f := newKubernetesFixture(t)
+	src := f.source(commoncfg.KubernetesSecret, "db", "password")
+	src.TokenPath = filepath.Join(t.TempDir(), "missing")
+
+	_, err := commoncfg.ExtractValueFromSourceRef(&commoncfg.SourceRef{
+		Source:     commoncfg.KubernetesSourceValue,
+		Kubernetes: src,
+	})
+	require.ErrorContains(t, err, fmt.Sprint(http.StatusUnauthorized))
+}
+
+func TestExtractValueFromSourceRef_KubernetesNotInCluster(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+
+	_, err := commoncfg.ExtractValueFromSourceRef(&commoncfg.SourceRef{
+		Source:     commoncfg.KubernetesSourceValue,
+		Kubernetes: commoncfg.KubernetesSource{Name: "db", Key: "password"},
+	})
+	require.ErrorIs(t, err, commoncfg.ErrKubernetesNotInCluster)
+}
+
+func TestWatchKubernetesSource(t *testing.T) {
+	f := newKubernetesFixture(t)
+	src := f.source(commoncfg.KubernetesSecret, "db", "password")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	values := make(chan string, 4)
+	done := make(chan error)
+
+	go func() {
+		done <- commoncfg.WatchKubernetesSource(ctx, &src, func(value []byte, err error) {
+			if err == nil {
+				values <- string(value)
+			}
+		})
+	}()
+
+	receive := func() string {
+		select {
+		case v := <-values:
+			return v
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for value")
+			return ""
+		}
+	}
+
+	assert.Equal(t, "initial", receive())
+
+	f.updates <- "initial" // unchanged values are not reported
+	f.updates <- "rotated"
+	assert.Equal(t, "rotated", receive())
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatchKubernetesSourceRewatchesAfterServerEnd(t *testing.T) {
+	f := newKubernetesFixture(t)
+	src := f.source(commoncfg.KubernetesSecret, "db", "password")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	values := make(chan string, 4)
+	errs := make(chan error, 4)
+	done := make(chan error)
+
+	go func() {
+		done <- commoncfg.WatchKubernetesSource(ctx, &src, func(value []byte, err error) {
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			values <- string(value)
+		})
+	}()
+
+	receive := func() string {
+		select {
+		case v := <-values:
+			return v
+		case err := <-errs:
+			t.Fatalf("unexpected watch error: %v", err)
+		case <-time.After(commoncfg.DefaultKubernetesWatchBackoff / 2):
+			t.Fatal("timed out waiting for value")
+		}
+
+		return ""
+	}
+
+	assert.Equal(t, "initial", receive())
+
+	f.updates <- "rotated"
+	assert.Equal(t, "rotated", receive())
+
+	// the server ends the watch, which is re-established without backoff from the last version
+	f.endWatch <- struct{}{}
+	f.updates <- "rotated-again"
+	assert.Equal(t, "rotated-again", receive())
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	assert.Equal(t, 1, f.gets)
+	assert.Equal(t, []string{"1", "2"}, f.watchVersions)
+}
@@ -0,0 +1,151 @@
+package commoncfg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+var ErrNoConfigLayer = errors.New("none of the config layers exists")
+
+// LoadLayered loads the configuration from several files or directories merged in order,
+// e.g. a base configuration, an environment overlay and a local override:
+//
+//	err := commoncfg.LoadLayered(cfg, "config/base.yaml", "config/prod.yaml", "config/local")
+//
+// A directory layer consists of all config files of the directory (see viper.SupportedExts)
+// in lexical order. Layers which do not exist are skipped, but at least one must exist.
+//
+// Later layers take precedence over earlier ones:
+//   - maps are merged key by key, recursively; keys are case-insensitive
+//   - slices are replaced as a whole, they are never appended or merged by index
+//   - all other values are replaced
+//   - null values are ignored and keep the value of the earlier layers
+//
+// Use NewLoader with WithLayers to combine layers with other options, e.g. WithEnvOverride.
+func LoadLayered(cfg any, paths ...string) error {
+	return NewLoader(cfg, WithLayers(paths...)).LoadConfig()
+}
+
+// readLayers merges the settings of all layers into v.
+func (l *Loader) readLayers(v *viper.Viper) error {
+	merged := make(map[string]any)
+	found := 0
+
+	for _, path := range l.layers {
+		files, err := layerFiles(path)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			settings, err := l.readLayer(file)
+			if err != nil {
+				return fmt.Errorf("reading config layer %s: %w", file, err)
+			}
+
+			mergeSettings(merged, settings)
+
+			found++
+		}
+	}
+
+	if found == 0 {
+		return fmt.Errorf("%w: %s", ErrNoConfigLayer, strings.Join(l.layers, ", "))
+	}
+
+	return v.MergeConfigMap(merged)
+}
+
+// readLayer returns the settings of a single config file, with lower case keys.
+func (l *Loader) readLayer(file string) (map[string]any, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.expandEnv {
+		data = []byte(ExpandEnv(string(data)))
+	}
+
+	layer := viper.New()
+	layer.SetConfigType(configType(file))
+
+	err = layer.ReadConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return layer.AllSettings(), nil
+}
+
+// layerFiles returns the config files of a layer, which is either a file or a directory.
+func layerFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !slices.Contains(viper.SupportedExts, configType(entry.Name())) {
+			continue
+		}
+
+		files = append(files, filepath.Join(path, entry.Name()))
+	}
+
+	// os.ReadDir already sorts by name, sort anyway to make the order explicit
+	slices.Sort(files)
+
+	return files, nil
+}
+
+func configType(file string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(file), "."))
+}
+
+// mergeSettings merges src into dst: maps are merged recursively, all other values are replaced.
+func mergeSettings(dst, src map[string]any) {
+	for key, value := range src {
+		if value == nil {
+			continue
+		}
+
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+
+		if srcIsMap && dstIsMap {
+			mergeSettings(dstMap, srcMap)
+			continue
+		}
+
+		if srcIsMap {
+			// copy to keep later merges from modifying the settings of the layer
+			copied := make(map[string]any, len(srcMap))
+			mergeSettings(copied, srcMap)
+			value = copied
+		}
+
+		dst[key] = value
+	}
+}
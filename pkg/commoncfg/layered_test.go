@@ -0,0 +1,187 @@
+package commoncfg_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+type layeredConfig struct {
+	Name     string            `yaml:"name"`
+	Replicas int               `yaml:"replicas"`
+	Hosts    []string          `yaml:"hosts"`
+	Labels   map[string]string `yaml:"labels"`
+	Database struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	} `yaml:"database"`
+}
+
+func writeLayer(t *testing.T, path, content string) string {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoadLayered(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	base := writeLayer(t, filepath.Join(dir, "base.yaml"), `
+name: service
+replicas: 1
+hosts: [a, b, c]
+labels:
+  team: kms
+  tier: backend
+database:
+  host: localhost
+  port: 5432
+`)
+	overlay := writeLayer(t, filepath.Join(dir, "prod.json"), `{
+  "hosts": ["prod"],
+  "labels": {"tier": "critical"},
+  "database": {"host": "db.prod", "port": null}
+}`)
+	local := filepath.Join(dir, "local")
+	writeLayer(t, filepath.Join(local, "20-replicas.yaml"), "replicas: 5\n")
+	writeLayer(t, filepath.Join(local, "10-replicas.yaml"), "replicas: 3\nname: local\n")
+	writeLayer(t, filepath.Join(local, "README.md"), "not a config file")
+
+	cfg := &layeredConfig{}
+
+	// Act
+	err := commoncfg.LoadLayered(cfg, base, overlay, filepath.Join(dir, "missing.yaml"), local)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "local", cfg.Name)
+	assert.Equal(t, 5, cfg.Replicas)
+	assert.Equal(t, []string{"prod"}, cfg.Hosts)
+	assert.Equal(t, map[string]string{"team": "kms", "tier": "critical"}, cfg.Labels)
+	assert.Equal(t, "db.prod", cfg.Database.Host)
+	assert.Equal(t, 5432, cfg.Database.Port)
+}
+
+func TestLoadLayeredWithOptions(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	base := writeLayer(t, filepath.Join(dir, "base.yaml"), "name: ${TEST_LAYERED_NAME}\nreplicas: 1\n")
+	overlay := writeLayer(t, filepath.Join(dir, "overlay.yaml"), "replicas: 2\n")
+
+	t.Setenv("TEST_LAYERED_NAME", "expanded")
+	t.Setenv("LAYERED_REPLICAS", "7")
+
+	cfg := &layeredConfig{}
+
+	// Act
+	err := commoncfg.NewLoader(cfg,
+		commoncfg.WithLayers(base, overlay),
+		commoncfg.WithEnvExpansion(),
+		commoncfg.WithEnvOverride("LAYERED"),
+	).LoadConfig()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "expanded", cfg.Name)
+	assert.Equal(t, 7, cfg.Replicas)
+}
+
+func TestLoadLayeredErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("no layer exists", func(t *testing.T) {
+		err := commoncfg.LoadLayered(&layeredConfig{}, filepath.Join(dir, "missing.yaml"), filepath.Join(dir, "missing"))
+		require.ErrorIs(t, err, commoncfg.ErrNoConfigLayer)
+	})
+
+	t.Run("invalid layer", func(t *testing.T) {
+		invalid := writeLayer(t, filepath.Join(dir, "invalid.yaml"), "name: [")
+
+		err := commoncfg.LoadLayered(&layeredConfig{}, invalid)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid.yaml")
+	})
+
+	t.Run("unknown keys", func(t *testing.T) {
+		unknown := writeLayer(t, filepath.Join(dir, "unknown.yaml"), "unknown: true")
+
+		err := commoncfg.LoadLayered(&layeredConfig{}, unknown)
+		require.Error(t, err)
+	})
+}
+
+func TestLoaderWithOverlay(t *testing.T) {
+	dir := t.TempDir()
+	base := writeLayer(t, filepath.Join(dir, "base.yaml"), `
+name: service
+labels:
+  team: kms
+database:
+  host: localhost
+  port: 5432
+`)
+
+	t.Run("merged over layers", func(t *testing.T) {
+		cfg := &layeredConfig{}
+		err := commoncfg.NewLoader(cfg,
+			commoncfg.WithLayers(base),
+			commoncfg.WithOverlay([]byte(`{"labels": {"tier": "critical"}, "database": {"host": "db"}}`), commoncfg.JSONFileFormat),
+			commoncfg.WithOverlay([]byte("replicas = 3\n"), commoncfg.TOMLFileFormat),
+		).LoadConfig()
+		require.NoError(t, err)
+
+		assert.Equal(t, "service", cfg.Name)
+		assert.Equal(t, 3, cfg.Replicas)
+		assert.Equal(t, map[string]string{"team": "kms", "tier": "critical"}, cfg.Labels)
+		assert.Equal(t, "db", cfg.Database.Host)
+		assert.Equal(t, 5432, cfg.Database.Port)
+	})
+
+	t.Run("merged over config file", func(t *testing.T) {
+		writeLayer(t, filepath.Join(dir, "file", "config.yaml"), "name: file\nreplicas: 1\n")
+
+		cfg := &layeredConfig{}
+		err := commoncfg.NewLoader(cfg,
+			commoncfg.WithPaths(filepath.Join(dir, "file")),
+			commoncfg.WithOverlay([]byte("replicas: 2\n"), commoncfg.YAMLFileFormat),
+		).LoadConfig()
+		require.NoError(t, err)
+
+		assert.Equal(t, "file", cfg.Name)
+		assert.Equal(t, 2, cfg.Replicas)
+	})
+
+	t.Run("without config files", func(t *testing.T) {
+		cfg := &layeredConfig{}
+		err := commoncfg.NewLoader(cfg,
+			commoncfg.WithLayers(filepath.Join(dir, "missing.yaml")),
+			commoncfg.WithOverlay([]byte("name: overlay\n"), commoncfg.YAMLFileFormat),
+		).LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "overlay", cfg.Name)
+
+		cfg = &layeredConfig{}
+		err = commoncfg.NewLoader(cfg,
+			commoncfg.WithPaths(filepath.Join(dir, "missing")),
+			commoncfg.WithOverlay([]byte("name: overlay\n"), commoncfg.YAMLFileFormat),
+		).LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "overlay", cfg.Name)
+	})
+
+	t.Run("invalid overlay", func(t *testing.T) {
+		err := commoncfg.NewLoader(&layeredConfig{},
+			commoncfg.WithLayers(base),
+			commoncfg.WithOverlay([]byte("name: [\n"), commoncfg.YAMLFileFormat),
+		).LoadConfig()
+		require.Error(t, err)
+	})
+}
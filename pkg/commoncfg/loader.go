@@ -1,17 +1,20 @@
 package commoncfg
 
 import (
+	"bytes"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/creasty/defaults"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/oliveagle/jsonpath"
+	"github.com/pelletier/go-toml/v2"
 	"github.com/samber/oops"
 	"github.com/spf13/viper"
 )
@@ -25,6 +28,8 @@ var (
 	ErrMTLSIsNil           = errors.New("missing mTLS configuration: value is nil")
 	ErrCertificateIsNil    = errors.New("missing certificate configuration: value is nil")
 	ErrKeyCertificateIsNil = errors.New("missing key certificate configuration: value is nil")
+	ErrUnknownTLSVersion   = errors.New("unknown tls version")
+	ErrUnknownCipherSuite  = errors.New("unknown or insecure tls cipher suite")
 )
 
 // Loader is used to load configuration from a `config.yaml` file.
@@ -38,12 +43,23 @@ type Loader struct {
 	useEnv     bool
 	fileName   string
 	fileFormat FileFormat
+	validate   bool
+	expandEnv  bool
+	layers     []string
+	overlays   []overlay
+	configFile string
 
 	decoderConfig mapstructure.DecoderConfig
 }
 
 type Option func(*Loader)
 
+// overlay is a config document merged over the config file or layers.
+type overlay struct {
+	data   []byte
+	format FileFormat
+}
+
 // NewLoader creates a new config loader
 // Uses config.yaml as default config file
 func NewLoader(cfg any, options ...Option) *Loader {
@@ -89,6 +105,23 @@ func DisableViperErrorUnused() Option {
 	}
 }
 
+// WithValidation validates the loaded configuration (see ValidateStruct) after the defaults
+// have been applied. LoadConfig then fails with a *ValidationError listing all invalid values.
+func WithValidation() Option {
+	return func(l *Loader) {
+		l.validate = true
+	}
+}
+
+// WithEnvExpansion replaces ${ENV_VAR} and ${ENV_VAR:-default} placeholders in the config
+// file with the values of the environment variables before the file is parsed (see ExpandEnv),
+// so a single config template can serve multiple environments.
+func WithEnvExpansion() Option {
+	return func(l *Loader) {
+		l.expandEnv = true
+	}
+}
+
 // WithFile sets the file name and type of the config file
 func WithFile(name string, extension FileFormat) Option {
 	return func(l *Loader) {
@@ -114,6 +147,25 @@ func WithEnvOverride(prefix string) Option {
 	}
 }
 
+// WithLayers loads the configuration from several files or directories merged in order,
+// e.g. a base configuration, an environment overlay and a local override, instead of
+// searching a single config file in the paths (see LoadLayered for the merge semantics).
+func WithLayers(paths ...string) Option {
+	return func(l *Loader) {
+		l.layers = paths
+	}
+}
+
+// WithOverlay merges a config document over the config file or layers, e.g. a complete
+// configuration or a fragment fetched from a central config service (see remotecfg), with
+// the merge semantics of LoadLayered. Overlays are merged in the order of the options.
+// If overlays are set, the config file and layers are optional.
+func WithOverlay(data []byte, format FileFormat) Option {
+	return func(l *Loader) {
+		l.overlays = append(l.overlays, overlay{data: data, format: format})
+	}
+}
+
 // LoadConfig is a convenience function to load the config file from the specified paths
 func LoadConfig[T any | BaseConfig](cfg T, defaults map[string]any, paths ...string) error {
 	loader := NewLoader(cfg, WithDefaults(defaults), WithPaths(paths...))
@@ -140,16 +192,15 @@ func (l *Loader) LoadConfig() error {
 		v.AutomaticEnv()
 	}
 
-	err := v.ReadInConfig()
+	err := l.readConfig(v)
 	if err != nil {
-		return oops.
-			In("Config Loader").
-			Wrapf(err, "Failed reading config file")
+		return err
 	}
 
 	err = v.Unmarshal(l.cfg,
 		func(c *mapstructure.DecoderConfig) {
 			c.ErrorUnused = l.decoderConfig.ErrorUnused // error if there are unknown keys in the config
+			c.DecodeHook = mapstructure.ComposeDecodeHookFunc(unitsDecodeHook, c.DecodeHook, featureGateDecodeHook)
 		},
 	)
 	if err != nil {
@@ -169,7 +220,110 @@ func (l *Loader) LoadConfig() error {
 	//
 	//      foo := &ExampleBasic{}
 	//      Set(foo)
-	return defaults.Set(l.cfg)
+	err = defaults.Set(l.cfg)
+	if err != nil {
+		return err
+	}
+
+	if l.validate {
+		err = ValidateStruct(l.cfg)
+		if err != nil {
+			return oops.
+				In("Config Loader").
+				Wrapf(err, "Invalid configuration")
+		}
+	}
+
+	return nil
+}
+
+// readConfig reads either the configured layers or the single config file found in the
+// paths, and merges the overlays over them.
+func (l *Loader) readConfig(v *viper.Viper) error {
+	err := l.readConfigFiles(v)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range l.overlays {
+		err = l.readOverlay(v, o)
+		if err != nil {
+			return oops.
+				In("Config Loader").
+				Wrapf(err, "Failed reading config overlay")
+		}
+	}
+
+	return nil
+}
+
+// readConfigFiles reads either the configured layers or the single config file found in the
+// paths. Missing files are ignored if overlays are set.
+func (l *Loader) readConfigFiles(v *viper.Viper) error {
+	if len(l.layers) > 0 {
+		err := l.readLayers(v)
+		if err != nil && (len(l.overlays) == 0 || !errors.Is(err, ErrNoConfigLayer)) {
+			return oops.
+				In("Config Loader").
+				Wrapf(err, "Failed reading config layers")
+		}
+
+		return nil
+	}
+
+	var notFound viper.ConfigFileNotFoundError
+
+	err := v.ReadInConfig()
+	if errors.As(err, &notFound) && len(l.overlays) > 0 {
+		return nil
+	}
+
+	if err != nil {
+		return oops.
+			In("Config Loader").
+			Wrapf(err, "Failed reading config file")
+	}
+
+	l.configFile = v.ConfigFileUsed()
+
+	if l.expandEnv {
+		err = expandConfigFile(v)
+		if err != nil {
+			return oops.
+				In("Config Loader").
+				Wrapf(err, "Failed expanding environment variables in config file")
+		}
+	}
+
+	return nil
+}
+
+// readOverlay merges the settings of the overlay into v.
+func (l *Loader) readOverlay(v *viper.Viper, o overlay) error {
+	data := o.data
+	if l.expandEnv {
+		data = []byte(ExpandEnv(string(data)))
+	}
+
+	layer := viper.New()
+	layer.SetConfigType(string(o.format))
+
+	err := layer.ReadConfig(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	return v.MergeConfigMap(layer.AllSettings())
+}
+
+// expandConfigFile reads the config file found by viper again with the environment variables expanded.
+func expandConfigFile(v *viper.Viper) error {
+	data, err := os.ReadFile(v.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+
+	return v.ReadConfig(bytes.NewReader([]byte(ExpandEnv(string(data)))))
 }
 
 func ExtractValueFromSourceRef(cred *SourceRef) ([]byte, error) {
@@ -193,7 +347,20 @@ func ExtractValueFromSourceRef(cred *SourceRef) ([]byte, error) {
 			return nil, err
 		}
 
+		data, err = decryptFile(data, cred.File)
+		if err != nil {
+			return nil, err
+		}
+
 		return parseFile(data, cred.File)
+	case VaultSourceValue:
+		return loadFromVault(&cred.Vault)
+	case AWSSourceValue:
+		return loadFromAWS(&cred.AWS)
+	case KubernetesSourceValue:
+		return loadFromKubernetes(&cred.Kubernetes)
+	case ExecSourceValue:
+		return loadFromExec(&cred.Exec)
 	}
 
 	return nil, fmt.Errorf("no credential found, based on given credentials source: %s", cred.Source)
@@ -208,6 +375,10 @@ func LoadMTLSClientCertificate(cfg *MTLS) (*tls.Certificate, error) {
 		return nil, ErrMTLSIsNil
 	}
 
+	if cfg.usesPKCS12() {
+		return LoadPKCS12Certificate(cfg.PKCS12, cfg.PKCS12Passphrase)
+	}
+
 	certPEMBlock, err := ExtractValueFromSourceRef(&cfg.Cert)
 	if err != nil {
 		return nil, err
@@ -223,7 +394,7 @@ func LoadMTLSClientCertificate(cfg *MTLS) (*tls.Certificate, error) {
 		return nil, err
 	}
 
-	return &cert, nil
+	return withLeaf(&cert)
 }
 
 func LoadMTLSCACertPool(cfg *MTLS) (*x509.CertPool, error) {
@@ -256,13 +427,11 @@ func LoadCACertPool(certRef *SourceRef) (*x509.CertPool, error) {
 
 	caCertPool := x509.NewCertPool()
 
-	caCert, err := ExtractValueFromSourceRef(certRef)
+	err := appendCAs(caCertPool, certRef)
 	if err != nil {
 		return nil, err
 	}
 
-	caCertPool.AppendCertsFromPEM(caCert)
-
 	return caCertPool, nil
 }
 
@@ -275,12 +444,10 @@ func LoadCAsCertPool(certRefs []SourceRef) (*x509.CertPool, error) {
 	caCertPool := x509.NewCertPool()
 
 	for _, cert := range certRefs {
-		caCert, err := ExtractValueFromSourceRef(&cert)
+		err := appendCAs(caCertPool, &cert)
 		if err != nil {
 			return nil, err
 		}
-
-		caCertPool.AppendCertsFromPEM(caCert)
 	}
 
 	return caCertPool, nil
@@ -310,7 +477,7 @@ func LoadClientCertificate(certRef, keyRef *SourceRef) (*tls.Certificate, error)
 		return nil, err
 	}
 
-	return &cert, nil
+	return withLeaf(&cert)
 }
 
 func LoadMTLSConfig(cfg *MTLS) (*tls.Config, error) {
@@ -332,7 +499,12 @@ func LoadMTLSConfig(cfg *MTLS) (*tls.Config, error) {
 		tlsConfig.InsecureSkipVerify = cfg.Attributes.InsecureSkipVerify
 		tlsConfig.ServerName = cfg.Attributes.ServerName
 		tlsConfig.SessionTicketsDisabled = cfg.Attributes.SessionTicketsDisabled
-		tlsConfig.SessionTicketsDisabled = cfg.Attributes.SessionTicketsDisabled
+		tlsConfig.DynamicRecordSizingDisabled = cfg.Attributes.DynamicRecordSizingDisabled
+
+		err = applyTLSVersions(tlsConfig, cfg.Attributes)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	caCertPool, err := LoadMTLSCACertPool(cfg)
@@ -342,9 +514,78 @@ func LoadMTLSConfig(cfg *MTLS) (*tls.Config, error) {
 
 	tlsConfig.RootCAs = caCertPool
 
+	if cfg.Revocation != nil {
+		tlsConfig.VerifyConnection, err = newRevocationVerifier(cfg.Revocation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return tlsConfig, nil
 }
 
+// applyTLSVersions sets the TLS versions and cipher suites of the attributes, if configured.
+func applyTLSVersions(tlsConfig *tls.Config, attrs *TLSAttributes) error {
+	if attrs.MinVersion != "" {
+		version, err := tlsVersion(attrs.MinVersion)
+		if err != nil {
+			return err
+		}
+
+		tlsConfig.MinVersion = version
+	}
+
+	if attrs.MaxVersion != "" {
+		version, err := tlsVersion(attrs.MaxVersion)
+		if err != nil {
+			return err
+		}
+
+		tlsConfig.MaxVersion = version
+	}
+
+	if len(attrs.CipherSuites) > 0 {
+		ids, err := CipherSuiteIDs(attrs.CipherSuites)
+		if err != nil {
+			return err
+		}
+
+		tlsConfig.CipherSuites = ids
+	}
+
+	return nil
+}
+
+func tlsVersion(version TLSVersion) (uint16, error) {
+	switch version {
+	case TLS12Version:
+		return tls.VersionTLS12, nil
+	case TLS13Version:
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownTLSVersion, version)
+	}
+}
+
+// CipherSuiteIDs returns the IDs of the named cipher suites. Only the secure cipher suites
+// of crypto/tls, as returned by tls.CipherSuites, are supported.
+func CipherSuiteIDs(names []string) ([]uint16, error) {
+	ids := make([]uint16, 0, len(names))
+
+	for _, name := range names {
+		idx := slices.IndexFunc(tls.CipherSuites(), func(suite *tls.CipherSuite) bool {
+			return suite.Name == name
+		})
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCipherSuite, name)
+		}
+
+		ids = append(ids, tls.CipherSuites()[idx].ID)
+	}
+
+	return ids, nil
+}
+
 func env(names ...string) string {
 	for _, name := range names {
 		val := os.Getenv(strings.TrimSpace(name))
@@ -364,6 +605,12 @@ func parseFile(data []byte, file CredentialFile) ([]byte, error) {
 		}
 
 		return jsonQuery(string(data), file.JSONPath)
+	case TOMLFileFormat:
+		if strings.TrimSpace(file.JSONPath) == "" {
+			return data, nil
+		}
+
+		return tomlQuery(data, file.JSONPath)
 	case YAMLFileFormat, BinaryFileFormat:
 		return data, nil
 	default:
@@ -379,7 +626,23 @@ func jsonQuery(data, path string) ([]byte, error) {
 		return nil, err
 	}
 
-	result, err := jsonpath.JsonPathLookup(jsonData, path)
+	return pathQuery(jsonData, path)
+}
+
+// tomlQuery looks up the value of a TOML document with a JSONPath expression, e.g. $.db.password.
+func tomlQuery(data []byte, path string) ([]byte, error) {
+	var tomlData map[string]any
+
+	err := toml.Unmarshal(data, &tomlData)
+	if err != nil {
+		return nil, err
+	}
+
+	return pathQuery(tomlData, path)
+}
+
+func pathQuery(data any, path string) ([]byte, error) {
+	result, err := jsonpath.JsonPathLookup(data, path)
 	if err != nil {
 		return nil, err
 	}
@@ -391,3 +654,20 @@ func jsonQuery(data, path string) ([]byte, error) {
 
 	return []byte(r), nil
 }
+
+// withLeaf makes sure the parsed leaf certificate is available,
+// so the expiry can be inspected without parsing the certificate again.
+func withLeaf(cert *tls.Certificate) (*tls.Certificate, error) {
+	if cert.Leaf != nil {
+		return cert, nil
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	cert.Leaf = leaf
+
+	return cert, nil
+}
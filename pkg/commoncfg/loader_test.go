@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
@@ -177,6 +178,12 @@ func TestWithFile(t *testing.T) {
 			format: commoncfg.JSONFileFormat,
 			cfg:    "{\"key1\": \"foo\"}",
 		},
+		{
+			name:   "Should use toml file",
+			file:   "tomltest",
+			format: commoncfg.TOMLFileFormat,
+			cfg:    "key1 = \"foo\"\n\n[sub]\nkey3 = \"bar\"",
+		},
 		{
 			name:   "Should error on file with unsupported format",
 			file:   "test",
@@ -285,6 +292,40 @@ func TestWithEnvOverride(t *testing.T) {
 	}
 }
 
+func TestWithEnvExpansion(t *testing.T) {
+	// Arrange
+	config := "key1: ${TEST_EXPAND_KEY1}\nkey2: ${TEST_EXPAND_KEY2:-42}\nsub:\n  key3: \"$${literal}\""
+	tmpdir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tmpdir, "config.yaml"), []byte(config), 0o644)
+	require.NoError(t, err)
+
+	t.Setenv("TEST_EXPAND_KEY1", "expanded")
+
+	t.Run("placeholders are expanded", func(t *testing.T) {
+		cfg := &MyConfig{}
+
+		// Act
+		err := commoncfg.NewLoader(cfg, commoncfg.WithPaths(tmpdir), commoncfg.WithEnvExpansion()).LoadConfig()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "expanded", cfg.Key1)
+		assert.Equal(t, 42, cfg.Key2)
+		assert.Equal(t, "${literal}", cfg.Sub.Key3)
+	})
+
+	t.Run("placeholders are kept without the option", func(t *testing.T) {
+		cfg := &MyConfig{}
+
+		// Act
+		err := commoncfg.NewLoader(cfg, commoncfg.WithPaths(tmpdir)).LoadConfig()
+
+		// Assert
+		require.Error(t, err)
+	})
+}
+
 func TestLoadValueFromSourceRef(t *testing.T) {
 	t.Run("loads embedded value", func(t *testing.T) {
 		ref := commoncfg.SourceRef{
@@ -513,6 +554,59 @@ func TestExtractValueFromSourceRef_JSONFileNonStringResult(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestExtractValueFromSourceRef_TOMLFile(t *testing.T) {
+	tmpFile := createTempFile(t, `
+[db]
+password = "s3cret"
+port = 5432
+
+[[tokens]]
+value = "first"
+`)
+
+	ref := func(path string) *commoncfg.SourceRef {
+		return &commoncfg.SourceRef{
+			Source: commoncfg.FileSourceValue,
+			File: commoncfg.CredentialFile{
+				Path:     tmpFile,
+				Format:   commoncfg.TOMLFileFormat,
+				JSONPath: path,
+			},
+		}
+	}
+
+	t.Run("looks up nested keys", func(t *testing.T) {
+		val, err := commoncfg.ExtractValueFromSourceRef(ref("$.db.password"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("s3cret"), val)
+	})
+
+	t.Run("looks up array elements", func(t *testing.T) {
+		val, err := commoncfg.ExtractValueFromSourceRef(ref("$.tokens[0].value"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("first"), val)
+	})
+
+	t.Run("returns the file without path", func(t *testing.T) {
+		val, err := commoncfg.ExtractValueFromSourceRef(ref(""))
+		require.NoError(t, err)
+		assert.Contains(t, string(val), `password = "s3cret"`)
+	})
+
+	t.Run("errors on non string values", func(t *testing.T) {
+		_, err := commoncfg.ExtractValueFromSourceRef(ref("$.db.port"))
+		assert.Error(t, err)
+	})
+
+	t.Run("errors on invalid toml", func(t *testing.T) {
+		r := ref("$.db.password")
+		r.File.Path = createTempFile(t, "not = = toml")
+
+		_, err := commoncfg.ExtractValueFromSourceRef(r)
+		assert.Error(t, err)
+	})
+}
+
 func TestLoadMTLSClientCertificate(t *testing.T) {
 	certPEM, keyPEM := generateTestCert(t)
 
@@ -731,6 +825,47 @@ func TestLoadMTLSConfig(t *testing.T) {
 		assert.True(t, tlsCfg.SessionTicketsDisabled)
 	})
 
+	t.Run("valid config with TLS versions and cipher suites", func(t *testing.T) {
+		mtls := &commoncfg.MTLS{
+			Cert:    commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(certPEM)},
+			CertKey: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(keyPEM)},
+			Attributes: &commoncfg.TLSAttributes{
+				MinVersion:   commoncfg.TLS12Version,
+				MaxVersion:   commoncfg.TLS13Version,
+				CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"},
+			},
+		}
+		tlsCfg, err := commoncfg.LoadMTLSConfig(mtls)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS12), tlsCfg.MinVersion)
+		assert.Equal(t, uint16(tls.VersionTLS13), tlsCfg.MaxVersion)
+		assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}, tlsCfg.CipherSuites)
+	})
+
+	t.Run("insecure cipher suite returns error", func(t *testing.T) {
+		mtls := &commoncfg.MTLS{
+			Cert:    commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(certPEM)},
+			CertKey: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(keyPEM)},
+			Attributes: &commoncfg.TLSAttributes{
+				CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"},
+			},
+		}
+		_, err := commoncfg.LoadMTLSConfig(mtls)
+		assert.ErrorIs(t, err, commoncfg.ErrUnknownCipherSuite)
+	})
+
+	t.Run("unknown TLS version returns error", func(t *testing.T) {
+		mtls := &commoncfg.MTLS{
+			Cert:    commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(certPEM)},
+			CertKey: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(keyPEM)},
+			Attributes: &commoncfg.TLSAttributes{
+				MinVersion: "1.1",
+			},
+		}
+		_, err := commoncfg.LoadMTLSConfig(mtls)
+		assert.ErrorIs(t, err, commoncfg.ErrUnknownTLSVersion)
+	})
+
 	t.Run("valid config with CA", func(t *testing.T) {
 		caRef := commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(certPEM)}
 		mtls := &commoncfg.MTLS{
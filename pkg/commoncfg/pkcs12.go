@@ -0,0 +1,90 @@
+package commoncfg
+
+import (
+	"crypto"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	ErrPKCS12Invalid     = errors.New("invalid PKCS#12 bundle")
+	ErrPKCS12KeyMismatch = errors.New("private key of the PKCS#12 bundle does not match the certificate")
+)
+
+// usesPKCS12 reports whether the client certificate is provided as PKCS#12 bundle.
+func (m *MTLS) usesPKCS12() bool {
+	return m.PKCS12 != nil && m.PKCS12.IsSet()
+}
+
+// LoadPKCS12Certificate loads the client certificate, its private key and the certificate
+// chain from a PKCS#12 (.p12/.pfx) bundle. The bundle is either DER encoded, e.g. read from
+// a binary file, or base64 encoded, e.g. embedded or read from an environment variable.
+// The passphrase is optional, as bundles may be encrypted with an empty password.
+func LoadPKCS12Certificate(bundleRef, passphraseRef *SourceRef) (*tls.Certificate, error) {
+	if bundleRef == nil {
+		return nil, ErrCertificateIsNil
+	}
+
+	bundle, err := ExtractValueFromSourceRef(bundleRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var passphrase string
+
+	if passphraseRef != nil && passphraseRef.IsSet() {
+		value, err := ExtractValueFromSourceRef(passphraseRef)
+		if err != nil {
+			return nil, err
+		}
+
+		passphrase = strings.TrimRight(string(value), "\r\n")
+	}
+
+	key, leaf, chain, err := pkcs12.DecodeChain(pkcs12DER(bundle), passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPKCS12Invalid, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported private key type %T", ErrPKCS12Invalid, key)
+	}
+
+	pub, ok := signer.Public().(interface{ Equal(x crypto.PublicKey) bool })
+	if !ok || !pub.Equal(leaf.PublicKey) {
+		return nil, ErrPKCS12KeyMismatch
+	}
+
+	cert := &tls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+
+	for _, ca := range chain {
+		cert.Certificate = append(cert.Certificate, ca.Raw)
+	}
+
+	return cert, nil
+}
+
+// pkcs12DER returns the DER encoding of a bundle which may be base64 encoded.
+func pkcs12DER(bundle []byte) []byte {
+	// DER encoded bundles start with an ASN.1 SEQUENCE
+	if len(bundle) > 0 && bundle[0] == 0x30 {
+		return bundle
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(bundle)), ""))
+	if err != nil {
+		return bundle
+	}
+
+	return decoded
+}
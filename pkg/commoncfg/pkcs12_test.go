@@ -0,0 +1,119 @@
+package commoncfg_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// generateTestPKCS12 creates a PKCS#12 bundle of a self-signed certificate encrypted with the password.
+func generateTestPKCS12(t *testing.T, password string) ([]byte, *x509.Certificate) {
+	t.Helper()
+
+	certPEM, keyPEM := generateTestCert(t)
+
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	bundle, err := pkcs12.Modern.Encode(pair.PrivateKey, cert, []*x509.Certificate{cert}, password)
+	require.NoError(t, err)
+
+	return bundle, cert
+}
+
+func TestLoadMTLSClientCertificate_PKCS12(t *testing.T) {
+	bundle, leaf := generateTestPKCS12(t, "changeit")
+
+	bundlePath := filepath.Join(t.TempDir(), "client.p12")
+	require.NoError(t, os.WriteFile(bundlePath, bundle, 0o600))
+
+	passphrase := &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "changeit"}
+
+	t.Run("loads a binary bundle from file", func(t *testing.T) {
+		cert, err := commoncfg.LoadMTLSClientCertificate(&commoncfg.MTLS{
+			PKCS12: &commoncfg.SourceRef{
+				Source: commoncfg.FileSourceValue,
+				File:   commoncfg.CredentialFile{Path: bundlePath, Format: commoncfg.BinaryFileFormat},
+			},
+			PKCS12Passphrase: passphrase,
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, cert.Leaf)
+		assert.Equal(t, leaf.Raw, cert.Leaf.Raw)
+		assert.Len(t, cert.Certificate, 2, "leaf and chain")
+	})
+
+	t.Run("loads a base64 encoded bundle", func(t *testing.T) {
+		cert, err := commoncfg.LoadMTLSClientCertificate(&commoncfg.MTLS{
+			PKCS12:           &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: base64.StdEncoding.EncodeToString(bundle)},
+			PKCS12Passphrase: passphrase,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, leaf.Raw, cert.Leaf.Raw)
+	})
+
+	t.Run("is preferred over cert and key", func(t *testing.T) {
+		cfg := &commoncfg.MTLS{
+			Cert:             commoncfg.SourceRef{Source: "unknown"},
+			CertKey:          commoncfg.SourceRef{Source: "unknown"},
+			PKCS12:           &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: base64.StdEncoding.EncodeToString(bundle)},
+			PKCS12Passphrase: passphrase,
+		}
+
+		tlsConfig, err := commoncfg.LoadMTLSConfig(cfg)
+		require.NoError(t, err)
+		require.Len(t, tlsConfig.Certificates, 1)
+
+		expiry, err := commoncfg.LoadMTLSCertificateExpiry(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, leaf.NotAfter, expiry.NotAfter)
+	})
+
+	t.Run("errors on wrong passphrase", func(t *testing.T) {
+		_, err := commoncfg.LoadMTLSClientCertificate(&commoncfg.MTLS{
+			PKCS12:           &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: base64.StdEncoding.EncodeToString(bundle)},
+			PKCS12Passphrase: &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "wrong"},
+		})
+		assert.ErrorIs(t, err, commoncfg.ErrPKCS12Invalid)
+	})
+
+	t.Run("loads a bundle without passphrase", func(t *testing.T) {
+		unprotected, _ := generateTestPKCS12(t, "")
+
+		cert, err := commoncfg.LoadPKCS12Certificate(
+			&commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: base64.StdEncoding.EncodeToString(unprotected)}, nil)
+		require.NoError(t, err)
+		assert.NotNil(t, cert.Leaf)
+	})
+
+	t.Run("nil bundle returns error", func(t *testing.T) {
+		_, err := commoncfg.LoadPKCS12Certificate(nil, nil)
+		assert.ErrorIs(t, err, commoncfg.ErrCertificateIsNil)
+	})
+}
+
+func TestValidateMTLSWithPKCS12(t *testing.T) {
+	ref := commoncfg.SecretRef{
+		Type: commoncfg.MTLSSecretType,
+		MTLS: commoncfg.MTLS{
+			PKCS12: &commoncfg.SourceRef{Source: commoncfg.FileSourceValue, File: commoncfg.CredentialFile{Path: "client.p12"}},
+		},
+	}
+
+	assert.NoError(t, ref.ValidateConfig())
+}
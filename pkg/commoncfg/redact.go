@@ -0,0 +1,147 @@
+package commoncfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+var ErrDumpFormatUnsupported = errors.New("unsupported config dump format")
+
+// Redacted returns the configuration as nested maps and slices keyed by the yaml field
+// names, with the secrets masked, so the effective configuration can be logged or served
+// for debugging, e.g.
+//
+//	slog.Info("Loaded configuration", "config", commoncfg.Redacted(cfg))
+//
+// The same values as in Diff are secrets: the values of SourceRef and of fields and map
+// keys named like a secret, e.g. password or token. Secrets are replaced by RedactedValue
+// unless they are empty, so missing credentials remain visible. Nil pointers, slices and
+// maps are left out.
+func Redacted(cfg any) any {
+	if cfg == nil {
+		return nil
+	}
+
+	return redactValue(reflect.ValueOf(cfg), false)
+}
+
+// MarshalSafe renders the configuration with the secrets masked (see Redacted) as JSON,
+// YAML or TOML.
+func MarshalSafe(cfg any, format FileFormat) ([]byte, error) {
+	redacted := Redacted(cfg)
+
+	switch format {
+	case JSONFileFormat:
+		return json.MarshalIndent(redacted, "", "  ")
+	case YAMLFileFormat:
+		return yaml.Marshal(redacted)
+	case TOMLFileFormat:
+		return toml.Marshal(redacted)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrDumpFormatUnsupported, format)
+	}
+}
+
+func redactValue(val reflect.Value, secret bool) any {
+	switch val.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+
+		return redactValue(val.Elem(), secret)
+	case reflect.Struct:
+		if val.Type() == reflect.TypeFor[SourceRef]() {
+			// a source reference only locates the secret, only its value is one
+			return redactStruct(val, false)
+		}
+
+		if val.Type() != reflect.TypeFor[time.Time]() {
+			return redactStruct(val, secret)
+		}
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return nil
+		}
+
+		if val.Type().Elem().Kind() != reflect.Uint8 {
+			items := make([]any, 0, val.Len())
+			for i := range val.Len() {
+				items = append(items, redactValue(val.Index(i), secret))
+			}
+
+			return items
+		}
+	case reflect.Map:
+		if val.IsNil() {
+			return nil
+		}
+
+		entries := make(map[string]any, val.Len())
+
+		iter := val.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key())
+			putRedacted(entries, key, redactValue(iter.Value(), secret || isSensitiveKey(key)))
+		}
+
+		return entries
+	case reflect.Invalid, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	default:
+	}
+
+	if secret && !val.IsZero() {
+		return RedactedValue
+	}
+
+	return leafInterface(val)
+}
+
+func redactStruct(val reflect.Value, secret bool) map[string]any {
+	typ := val.Type()
+	isSourceRef := typ == reflect.TypeFor[SourceRef]()
+	fields := make(map[string]any, typ.NumField())
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, inline := fieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		fieldSecret := secret || isSensitiveKey(name) || (isSourceRef && field.Name == "Value")
+		value := redactValue(val.Field(i), fieldSecret)
+
+		if inline {
+			if embedded, ok := value.(map[string]any); ok {
+				for key, v := range embedded {
+					putRedacted(fields, key, v)
+				}
+
+				continue
+			}
+		}
+
+		putRedacted(fields, name, value)
+	}
+
+	return fields
+}
+
+// putRedacted adds the value unless it is nil.
+func putRedacted(m map[string]any, key string, value any) {
+	if value != nil {
+		m[key] = value
+	}
+}
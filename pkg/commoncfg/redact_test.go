@@ -0,0 +1,99 @@
+package commoncfg_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func newRedactConfig() *commoncfg.BaseConfig {
+	return &commoncfg.BaseConfig{
+		Application: commoncfg.Application{
+			Name:   "svc",
+			Labels: map[string]string{"team": "kms", "apiKey": "k3y"},
+		},
+		Audit: commoncfg.Audit{
+			Endpoint: "http://audit",
+			HTTPClient: commoncfg.HTTPClient{
+				Timeout: 5 * time.Second,
+				APIToken: &commoncfg.SourceRef{
+					Source: commoncfg.EmbeddedSourceValue,
+					Value:  "t0ken",
+				},
+				BasicAuth: &commoncfg.BasicAuth{
+					Username: commoncfg.SourceRef{Source: commoncfg.EnvSourceValue, Env: "USER"},
+					Password: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "hunter2"},
+				},
+			},
+		},
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	redacted, ok := commoncfg.Redacted(newRedactConfig()).(map[string]any)
+	require.True(t, ok)
+
+	app := redacted["application"].(map[string]any) //nolint:forcetypeassert
+	assert.Equal(t, "svc", app["name"])
+	assert.Equal(t, map[string]any{"team": "kms", "apiKey": commoncfg.RedactedValue}, app["labels"])
+
+	client := redacted["audit"].(map[string]any)["httpClient"].(map[string]any) //nolint:forcetypeassert
+	assert.Equal(t, "5s", client["timeout"])
+	assert.Equal(t, map[string]any{"source": commoncfg.EmbeddedSourceValue, "value": commoncfg.RedactedValue}, pick(client["apiToken"], "source", "value"))
+
+	basicAuth := client["basicAuth"].(map[string]any) //nolint:forcetypeassert
+	assert.Equal(t, map[string]any{"source": commoncfg.EnvSourceValue, "env": "USER", "value": ""}, pick(basicAuth["username"], "source", "env", "value"))
+	assert.Equal(t, commoncfg.RedactedValue, pick(basicAuth["password"], "value")["value"])
+
+	assert.NotContains(t, client, "oauth2Auth", "nil pointers are left out")
+	assert.Nil(t, commoncfg.Redacted(nil))
+}
+
+func TestMarshalSafe(t *testing.T) {
+	cfg := newRedactConfig()
+
+	for _, format := range []commoncfg.FileFormat{commoncfg.JSONFileFormat, commoncfg.YAMLFileFormat, commoncfg.TOMLFileFormat} {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := commoncfg.MarshalSafe(cfg, format)
+			require.NoError(t, err)
+
+			assert.Contains(t, string(data), "http://audit")
+			assert.Contains(t, string(data), commoncfg.RedactedValue)
+			assert.NotContains(t, string(data), "hunter2")
+			assert.NotContains(t, string(data), "t0ken")
+			assert.NotContains(t, string(data), "k3y")
+		})
+	}
+
+	data, err := commoncfg.MarshalSafe(cfg, commoncfg.YAMLFileFormat)
+	require.NoError(t, err)
+
+	var dump commoncfg.BaseConfig
+	require.NoError(t, yaml.Unmarshal(data, &dump), "the dump must be readable as configuration")
+	assert.Equal(t, "svc", dump.Application.Name)
+
+	data, err = commoncfg.MarshalSafe(cfg, commoncfg.JSONFileFormat)
+	require.NoError(t, err)
+	assert.True(t, json.Valid(data))
+
+	_, err = commoncfg.MarshalSafe(cfg, commoncfg.BinaryFileFormat)
+	require.ErrorIs(t, err, commoncfg.ErrDumpFormatUnsupported)
+}
+
+// pick returns the entries of a redacted struct with the keys.
+func pick(v any, keys ...string) map[string]any {
+	m, _ := v.(map[string]any)
+	picked := make(map[string]any, len(keys))
+
+	for _, key := range keys {
+		picked[key] = m[key]
+	}
+
+	return picked
+}
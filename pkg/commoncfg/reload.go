@@ -0,0 +1,136 @@
+package commoncfg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/commonfs/notifier"
+)
+
+// DefaultReloadInterval is the minimum time between two reloads of Loader.Watch.
+const DefaultReloadInterval = time.Second
+
+var ErrConfigNotLoaded = errors.New("the configuration must be loaded before it is watched")
+
+// ReloadFunc is called by Loader.Watch with the reloaded configuration and its changes
+// compared to the previous one, or with the error of a failed reload.
+type ReloadFunc func(cfg any, changes []Change, err error)
+
+// Watch reloads the configuration each time one of its files changes, e.g. a mounted
+// ConfigMap is updated, until ctx is done, and then returns the context error. The
+// configuration must have been loaded with LoadConfig before.
+//
+// Each reload loads a new value of the type of the configuration passed to NewLoader, with
+// the same options; the configuration passed to NewLoader is never modified. If the reload
+// changed any value, onReload is called with the new configuration and the changes (see
+// Diff), e.g. to update DynamicFeatureGates:
+//
+//	gates := commoncfg.NewDynamicFeatureGates(cfg.FeatureGates)
+//	go loader.Watch(ctx, gates.OnReload)
+//
+// A reload which fails, e.g. because the file is invalid, calls onReload with the error and
+// keeps the previous configuration, against which the next reload is compared.
+func (l *Loader) Watch(ctx context.Context, onReload ReloadFunc) error {
+	paths := l.watchPaths()
+	if len(paths) == 0 {
+		return ErrConfigNotLoaded
+	}
+
+	current := l.cfg
+	reload := make(chan struct{}, 1)
+
+	nt, err := notifier.Create(
+		notifier.OnPaths(paths...),
+		notifier.WithSimpleHandler(func() {
+			select {
+			case reload <- struct{}{}:
+			default:
+			}
+		}),
+		notifier.WithThrottleInterval(DefaultReloadInterval),
+		notifier.WithBurstNumber(0),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = nt.Start()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = nt.Close() }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-reload:
+		}
+
+		next, err := l.reload()
+		if err != nil {
+			onReload(nil, nil, err)
+			continue
+		}
+
+		changes := Diff(current, next)
+		current = next
+
+		if len(changes) > 0 {
+			onReload(next, changes, nil)
+		}
+	}
+}
+
+// reload loads a new value of the configuration type with the options of the loader.
+func (l *Loader) reload() (any, error) {
+	loader := *l
+	loader.cfg = reflect.New(reflect.TypeOf(l.cfg).Elem()).Interface()
+
+	err := loader.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.cfg, nil
+}
+
+// watchPaths returns the directories holding the config files: the directory of the file
+// found by LoadConfig, or the layers, which are watched themselves if they are directories.
+func (l *Loader) watchPaths() []string {
+	if len(l.layers) == 0 {
+		if l.configFile == "" {
+			return nil
+		}
+
+		return []string{filepath.Dir(l.configFile)}
+	}
+
+	paths := make([]string, 0, len(l.layers))
+	seen := make(map[string]struct{}, len(l.layers))
+
+	for _, layer := range l.layers {
+		path := filepath.Dir(layer)
+		if info, err := os.Stat(layer); err == nil && info.IsDir() {
+			path = layer
+		}
+
+		if _, ok := seen[path]; ok {
+			continue
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		seen[path] = struct{}{}
+		paths = append(paths, path)
+	}
+
+	return paths
+}
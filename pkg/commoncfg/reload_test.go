@@ -0,0 +1,90 @@
+package commoncfg_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+type reload struct {
+	cfg     any
+	changes []commoncfg.Change
+	err     error
+}
+
+func TestLoaderWatch(t *testing.T) {
+	tmpdir := t.TempDir()
+	file := filepath.Join(tmpdir, "config.yaml")
+
+	require.NoError(t, os.WriteFile(file, []byte("featureGates:\n  a: false\n"), 0o600))
+
+	cfg := &commoncfg.BaseConfig{}
+	loader := commoncfg.NewLoader(cfg, commoncfg.WithPaths(tmpdir))
+	require.NoError(t, loader.LoadConfig())
+
+	gates := commoncfg.NewDynamicFeatureGates(cfg.FeatureGates)
+	toggled := make(chan bool, 1)
+	gates.Subscribe("a", func(_ string, enabled bool) { toggled <- enabled })
+
+	reloads := make(chan reload, 10)
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- loader.Watch(ctx, func(cfg any, changes []commoncfg.Change, err error) {
+			gates.OnReload(cfg, changes, err)
+			reloads <- reload{cfg, changes, err}
+		})
+	}()
+
+	// give the watcher time to start
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, os.WriteFile(file, []byte("featureGates:\n  a: true\n"), 0o600))
+
+	select {
+	case r := <-reloads:
+		require.NoError(t, r.err)
+		assert.Equal(t, []commoncfg.Change{{
+			Path: "featureGates[a].enabled",
+			Type: commoncfg.ChangeModified,
+			Old:  false,
+			New:  true,
+		}}, r.changes)
+		assert.True(t, r.cfg.(*commoncfg.BaseConfig).FeatureGates.IsFeatureEnabled("a"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration was not reloaded")
+	}
+
+	assert.True(t, <-toggled)
+	assert.False(t, cfg.FeatureGates.IsFeatureEnabled("a"), "the loaded configuration must not be modified")
+
+	time.Sleep(commoncfg.DefaultReloadInterval)
+	require.NoError(t, os.WriteFile(file, []byte("featureGates: [\n"), 0o600))
+
+	select {
+	case r := <-reloads:
+		require.Error(t, r.err)
+		assert.Nil(t, r.cfg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("failed reload was not reported")
+	}
+
+	assert.True(t, gates.IsFeatureEnabled("a"))
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestLoaderWatchNotLoaded(t *testing.T) {
+	loader := commoncfg.NewLoader(&commoncfg.BaseConfig{}, commoncfg.WithPaths(t.TempDir()))
+
+	err := loader.Watch(t.Context(), func(any, []commoncfg.Change, error) {})
+	require.ErrorIs(t, err, commoncfg.ErrConfigNotLoaded)
+}
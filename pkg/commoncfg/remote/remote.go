@@ -0,0 +1,219 @@
+// Package remotecfg loads configurations from a central config service over HTTP(S).
+//
+// The document fetched from RemoteConfig.URL is merged over the local config file or
+// layers, so it can be a complete configuration or a fragment, e.g. the feature gates of a
+// fleet. The document is polled with ETag/If-None-Match requests and changes are reported
+// like the reloads of commoncfg.Loader.Watch:
+//
+//	source, err := remotecfg.NewSource(&cfg.Remote)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	err = source.Load(ctx, cfg, commoncfg.WithPaths("."))
+//	if err != nil {
+//	    return err
+//	}
+//
+//	gates := commoncfg.NewDynamicFeatureGates(cfg.FeatureGates)
+//	go source.Watch(ctx, cfg, gates.OnReload, commoncfg.WithPaths("."))
+package remotecfg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commonhttp"
+)
+
+const (
+	// MaxDocumentSize is the maximum size of a fetched config document.
+	MaxDocumentSize = 10 << 20
+	// DefaultPollInterval is used by Watch if the configuration has no poll interval.
+	DefaultPollInterval = 30 * time.Second
+)
+
+var (
+	ErrUnexpectedStatus  = errors.New("unexpected status of the remote config source")
+	ErrDocumentTooLarge  = errors.New("remote config document is too large")
+	ErrDocumentNotLoaded = errors.New("remote config document was not fetched")
+)
+
+// Source fetches a config document from an HTTP(S) endpoint and keeps the last version,
+// which is only transferred again if its ETag changed.
+//
+// Source is safe for concurrent use.
+type Source struct {
+	cfg    commoncfg.RemoteConfig
+	client *http.Client
+
+	mu   sync.RWMutex
+	etag string
+	data []byte
+}
+
+// NewSource creates a source of the remote configuration. The requests are authenticated
+// as configured by the HTTPClient of the configuration.
+func NewSource(cfg *commoncfg.RemoteConfig) (*Source, error) {
+	client, err := commonhttp.NewHTTPClient(&cfg.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{
+		cfg:    *cfg,
+		client: client,
+	}, nil
+}
+
+// Fetch requests the document and reports whether it changed since the last fetch.
+// The ETag of the last version is sent as If-None-Match, so an unchanged document is
+// answered with 304 Not Modified without transferring it again.
+func (s *Source) Fetch(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.URL, nil)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	etag := s.etag
+	s.mu.RUnlock()
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && etag != "":
+		return false, nil
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxDocumentSize+1))
+	if err != nil {
+		return false, err
+	}
+
+	if len(data) > MaxDocumentSize {
+		return false, ErrDocumentTooLarge
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := s.data == nil || !bytes.Equal(s.data, data)
+	s.etag = resp.Header.Get("ETag")
+	s.data = data
+
+	return changed, nil
+}
+
+// Data returns the last fetched document, or nil if it was not fetched yet.
+func (s *Source) Data() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.data
+}
+
+// ETag returns the ETag of the last fetched document.
+func (s *Source) ETag() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.etag
+}
+
+// Load fetches the document and loads the configuration with the loader options and the
+// document merged over the config file or layers (see commoncfg.WithOverlay).
+func (s *Source) Load(ctx context.Context, cfg any, options ...commoncfg.Option) error {
+	_, err := s.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.load(cfg, options)
+}
+
+// Watch polls the document every PollInterval until ctx is done, and then returns the
+// context error. The configuration must have been loaded with Load before.
+//
+// Each time the document changed, a new value of the type of cfg is loaded with the loader
+// options and the document; cfg itself is never modified. If the reload changed any value,
+// onReload is called with the new configuration and the changes (see commoncfg.Diff).
+// Failed requests and reloads call onReload with the error and keep the previous
+// configuration, against which the next reload is compared.
+func (s *Source) Watch(ctx context.Context, cfg any, onReload commoncfg.ReloadFunc, options ...commoncfg.Option) error {
+	if s.Data() == nil {
+		return ErrDocumentNotLoaded
+	}
+
+	interval := s.cfg.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	current := cfg
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		changed, err := s.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			onReload(nil, nil, err)
+
+			continue
+		}
+
+		if !changed {
+			continue
+		}
+
+		next := reflect.New(reflect.TypeOf(cfg).Elem()).Interface()
+
+		err = s.load(next, options)
+		if err != nil {
+			onReload(nil, nil, err)
+			continue
+		}
+
+		changes := commoncfg.Diff(current, next)
+		current = next
+
+		if len(changes) > 0 {
+			onReload(next, changes, nil)
+		}
+	}
+}
+
+func (s *Source) load(cfg any, options []commoncfg.Option) error {
+	options = append(options[:len(options):len(options)], commoncfg.WithOverlay(s.Data(), s.cfg.Format))
+
+	return commoncfg.NewLoader(cfg, options...).LoadConfig()
+}
@@ -0,0 +1,179 @@
+package remotecfg_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	remotecfg "github.com/openkcm/common-sdk/pkg/commoncfg/remote"
+)
+
+// configService serves a config document with an ETag derived from its version.
+type configService struct {
+	mu          sync.Mutex
+	doc         string
+	version     int
+	notModified int
+	auth        string
+}
+
+func (c *configService) set(doc string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.doc = doc
+	c.version++
+}
+
+func (c *configService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.auth = r.Header.Get("Authorization")
+
+	etag := `"v` + strconv.Itoa(c.version) + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		c.notModified++
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	_, _ = w.Write([]byte(c.doc))
+}
+
+func newSource(t *testing.T, svc http.Handler, interval time.Duration) *remotecfg.Source {
+	t.Helper()
+
+	server := httptest.NewServer(svc)
+	t.Cleanup(server.Close)
+
+	source, err := remotecfg.NewSource(&commoncfg.RemoteConfig{
+		URL:          server.URL,
+		Format:       commoncfg.YAMLFileFormat,
+		PollInterval: interval,
+		HTTPClient: commoncfg.HTTPClient{
+			APIToken: &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "secret"},
+		},
+	})
+	require.NoError(t, err)
+
+	return source
+}
+
+func TestSourceFetch(t *testing.T) {
+	svc := &configService{}
+	svc.set("application:\n  name: remote\n")
+	source := newSource(t, svc, time.Second)
+
+	changed, err := source.Fetch(t.Context())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, `"v1"`, source.ETag())
+	assert.Equal(t, "Api-Token secret", svc.auth)
+
+	changed, err = source.Fetch(t.Context())
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 1, svc.notModified)
+
+	svc.set("application:\n  name: changed\n")
+
+	changed, err = source.Fetch(t.Context())
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "application:\n  name: changed\n", string(source.Data()))
+}
+
+func TestSourceFetchUnexpectedStatus(t *testing.T) {
+	source := newSource(t, http.NotFoundHandler(), time.Second)
+
+	_, err := source.Fetch(t.Context())
+	require.ErrorIs(t, err, remotecfg.ErrUnexpectedStatus)
+	assert.Nil(t, source.Data())
+}
+
+func TestSourceLoad(t *testing.T) {
+	svc := &configService{}
+	svc.set("application:\n  name: remote\nfeatureGates:\n  a: true\n")
+	source := newSource(t, svc, time.Second)
+
+	t.Run("without local config", func(t *testing.T) {
+		cfg := &commoncfg.BaseConfig{}
+		require.NoError(t, source.Load(t.Context(), cfg, commoncfg.WithPaths(t.TempDir())))
+
+		assert.Equal(t, "remote", cfg.Application.Name)
+		assert.True(t, cfg.FeatureGates.IsFeatureEnabled("a"))
+	})
+
+	t.Run("fragment over local config", func(t *testing.T) {
+		tmpdir := t.TempDir()
+		local := "application:\n  name: local\n  environment: dev\n"
+		require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "config.yaml"), []byte(local), 0o600))
+
+		cfg := &commoncfg.BaseConfig{}
+		require.NoError(t, source.Load(t.Context(), cfg, commoncfg.WithPaths(tmpdir)))
+
+		assert.Equal(t, "remote", cfg.Application.Name)
+		assert.Equal(t, "dev", cfg.Application.Environment)
+	})
+}
+
+func TestSourceWatch(t *testing.T) {
+	svc := &configService{}
+	svc.set("featureGates:\n  a: false\n")
+	source := newSource(t, svc, 10*time.Millisecond)
+
+	options := []commoncfg.Option{commoncfg.WithPaths(t.TempDir())}
+
+	require.ErrorIs(t, source.Watch(t.Context(), &commoncfg.BaseConfig{}, nil, options...), remotecfg.ErrDocumentNotLoaded)
+
+	cfg := &commoncfg.BaseConfig{}
+	require.NoError(t, source.Load(t.Context(), cfg, options...))
+
+	gates := commoncfg.NewDynamicFeatureGates(cfg.FeatureGates)
+	changes := make(chan []commoncfg.Change, 1)
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- source.Watch(ctx, cfg, func(next any, c []commoncfg.Change, err error) {
+			gates.OnReload(next, c, err)
+
+			if err == nil {
+				changes <- c
+			}
+		}, options...)
+	}()
+
+	svc.set("featureGates:\n  a: true\n")
+
+	select {
+	case c := <-changes:
+		assert.Equal(t, []commoncfg.Change{{
+			Path: "featureGates[a].enabled",
+			Type: commoncfg.ChangeModified,
+			Old:  false,
+			New:  true,
+		}}, c)
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration was not reloaded")
+	}
+
+	assert.True(t, gates.IsFeatureEnabled("a"))
+	assert.False(t, cfg.FeatureGates.IsFeatureEnabled("a"))
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
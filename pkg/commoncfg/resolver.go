@@ -0,0 +1,153 @@
+package commoncfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+const DefaultResolverTTL = time.Minute
+
+// Resolver resolves SourceRef values and caches them per reference, so hot code
+// paths (e.g. loading a token per request) do not hit the filesystem or a remote
+// secret store on every call. Cached values expire after the configured TTL.
+// Failed lookups are not cached. A Resolver is safe for concurrent use.
+type Resolver struct {
+	ttl  time.Duration
+	now  func() time.Time
+	load func(*SourceRef) ([]byte, error)
+
+	mu      sync.Mutex
+	entries map[string]*resolverEntry
+}
+
+type resolverEntry struct {
+	mu        sync.Mutex
+	value     []byte
+	expiresAt time.Time
+	loaded    bool
+}
+
+type ResolverOption func(*Resolver)
+
+// WithResolverTTL sets how long resolved values are cached.
+// A TTL of zero or less caches values until they are refreshed explicitly.
+func WithResolverTTL(ttl time.Duration) ResolverOption {
+	return func(r *Resolver) {
+		r.ttl = ttl
+	}
+}
+
+// WithResolverClock sets the clock used to expire cached values.
+func WithResolverClock(now func() time.Time) ResolverOption {
+	return func(r *Resolver) {
+		if now != nil {
+			r.now = now
+		}
+	}
+}
+
+// NewResolver creates a Resolver caching values for DefaultResolverTTL unless configured otherwise.
+func NewResolver(opts ...ResolverOption) *Resolver {
+	r := &Resolver{
+		ttl:     DefaultResolverTTL,
+		now:     time.Now,
+		load:    ExtractValueFromSourceRef,
+		entries: make(map[string]*resolverEntry),
+	}
+
+	for _, o := range opts {
+		if o != nil {
+			o(r)
+		}
+	}
+
+	return r
+}
+
+// Resolve returns the value of the reference, from the cache if it is still valid.
+// Embedded values are returned directly without caching.
+func (r *Resolver) Resolve(ref *SourceRef) ([]byte, error) {
+	return r.resolve(ref, false)
+}
+
+// ForceRefresh reloads the value of the reference regardless of the cache state
+// and stores it in the cache. On error, the previously cached value is kept.
+func (r *Resolver) ForceRefresh(ref *SourceRef) ([]byte, error) {
+	return r.resolve(ref, true)
+}
+
+// Invalidate removes the cached value of the reference.
+func (r *Resolver) Invalidate(ref *SourceRef) {
+	key, err := resolverKey(ref)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	delete(r.entries, key)
+	r.mu.Unlock()
+}
+
+// Purge removes all cached values.
+func (r *Resolver) Purge() {
+	r.mu.Lock()
+	r.entries = make(map[string]*resolverEntry)
+	r.mu.Unlock()
+}
+
+func (r *Resolver) resolve(ref *SourceRef, force bool) ([]byte, error) {
+	if ref == nil {
+		return nil, errors.New("given credential is nil")
+	}
+
+	if ref.Source == EmbeddedSourceValue {
+		return r.load(ref)
+	}
+
+	key, err := resolverKey(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+
+	entry, ok := r.entries[key]
+	if !ok {
+		entry = &resolverEntry{}
+		r.entries[key] = entry
+	}
+
+	r.mu.Unlock()
+
+	// Locking the entry makes concurrent callers of the same reference wait for a single load.
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if !force && entry.loaded && (r.ttl <= 0 || r.now().Before(entry.expiresAt)) {
+		return bytes.Clone(entry.value), nil
+	}
+
+	value, err := r.load(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.value = value
+	entry.loaded = true
+	entry.expiresAt = r.now().Add(r.ttl)
+
+	return bytes.Clone(value), nil
+}
+
+// resolverKey identifies a reference by its complete content.
+func resolverKey(ref *SourceRef) (string, error) {
+	data, err := json.Marshal(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,144 @@
+package commoncfg_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestResolverCachesFileValues(t *testing.T) {
+	// Arrange
+	path := createTempFile(t, "first")
+	now := time.Now()
+	resolver := commoncfg.NewResolver(
+		commoncfg.WithResolverTTL(time.Minute),
+		commoncfg.WithResolverClock(func() time.Time { return now }),
+	)
+	ref := &commoncfg.SourceRef{
+		Source: commoncfg.FileSourceValue,
+		File:   commoncfg.CredentialFile{Path: path},
+	}
+
+	// Act + Assert
+	value, err := resolver.Resolve(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(value))
+
+	require.NoError(t, os.WriteFile(path, []byte("second"), 0o600))
+
+	value, err = resolver.Resolve(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(value), "value should be served from cache")
+
+	now = now.Add(time.Minute)
+
+	value, err = resolver.Resolve(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(value), "value should be reloaded after the TTL")
+
+	require.NoError(t, os.WriteFile(path, []byte("third"), 0o600))
+
+	value, err = resolver.ForceRefresh(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "third", string(value))
+
+	require.NoError(t, os.WriteFile(path, []byte("fourth"), 0o600))
+	resolver.Invalidate(ref)
+
+	value, err = resolver.Resolve(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "fourth", string(value))
+}
+
+func TestResolverReturnsCopies(t *testing.T) {
+	t.Setenv("RESOLVER_TEST_VALUE", "secret")
+
+	resolver := commoncfg.NewResolver()
+	ref := &commoncfg.SourceRef{Source: commoncfg.EnvSourceValue, Env: "RESOLVER_TEST_VALUE"}
+
+	value, err := resolver.Resolve(ref)
+	require.NoError(t, err)
+
+	value[0] = 'X'
+
+	value, err = resolver.Resolve(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(value))
+}
+
+func TestResolverDoesNotCacheErrors(t *testing.T) {
+	var calls atomic.Int32
+
+	resolver := commoncfg.NewResolver(commoncfg.WithResolverTTL(0))
+	commoncfg.SetResolverLoadFunc(resolver, func(*commoncfg.SourceRef) ([]byte, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("temporarily unavailable")
+		}
+
+		return []byte("value"), nil
+	})
+
+	ref := &commoncfg.SourceRef{Source: commoncfg.VaultSourceValue}
+
+	_, err := resolver.Resolve(ref)
+	require.Error(t, err)
+
+	for range 3 {
+		value, err := resolver.Resolve(ref)
+		require.NoError(t, err)
+		assert.Equal(t, "value", string(value))
+	}
+
+	assert.Equal(t, int32(2), calls.Load())
+
+	resolver.Purge()
+
+	_, err = resolver.Resolve(ref)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestResolverConcurrentCallersShareLoad(t *testing.T) {
+	var calls atomic.Int32
+
+	resolver := commoncfg.NewResolver()
+	commoncfg.SetResolverLoadFunc(resolver, func(*commoncfg.SourceRef) ([]byte, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+
+		return []byte("value"), nil
+	})
+
+	ref := &commoncfg.SourceRef{Source: commoncfg.FileSourceValue, File: commoncfg.CredentialFile{Path: "/secret"}}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			_, err := resolver.Resolve(ref)
+			assert.NoError(t, err)
+		})
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestResolverEmbeddedAndNil(t *testing.T) {
+	resolver := commoncfg.NewResolver()
+
+	value, err := resolver.Resolve(&commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "inline"})
+	require.NoError(t, err)
+	assert.Equal(t, "inline", string(value))
+
+	_, err = resolver.Resolve(nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,189 @@
+package commoncfg
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+var (
+	ErrCertificateRevoked      = errors.New("certificate is revoked")
+	ErrRevocationStatusUnknown = errors.New("certificate revocation status is unknown")
+	ErrInvalidCRL              = errors.New("invalid certificate revocation list")
+)
+
+// LoadRevocationLists loads and parses the certificate revocation lists, PEM or DER encoded.
+func LoadRevocationLists(refs []SourceRef) ([]*x509.RevocationList, error) {
+	crls := make([]*x509.RevocationList, 0, len(refs))
+
+	for _, ref := range refs {
+		data, err := ExtractValueFromSourceRef(&ref)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := parseRevocationLists(data)
+		if err != nil {
+			return nil, err
+		}
+
+		crls = append(crls, parsed...)
+	}
+
+	return crls, nil
+}
+
+func parseRevocationLists(data []byte) ([]*x509.RevocationList, error) {
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		crl, err := x509.ParseRevocationList(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCRL, err)
+		}
+
+		return []*x509.RevocationList{crl}, nil
+	}
+
+	var crls []*x509.RevocationList
+
+	for {
+		var block *pem.Block
+
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "X509 CRL" {
+			continue
+		}
+
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCRL, err)
+		}
+
+		crls = append(crls, crl)
+	}
+
+	if len(crls) == 0 {
+		return nil, fmt.Errorf("%w: no X509 CRL block found", ErrInvalidCRL)
+	}
+
+	return crls, nil
+}
+
+// revocationVerifier checks the peer certificate chain of a TLS connection.
+type revocationVerifier struct {
+	crls         []*x509.RevocationList
+	ocspStapling bool
+	softFail     bool
+}
+
+// newRevocationVerifier returns the tls.Config VerifyConnection callback checking the revocation status.
+func newRevocationVerifier(cfg *TLSRevocation) (func(tls.ConnectionState) error, error) {
+	crls, err := LoadRevocationLists(cfg.CRLs)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &revocationVerifier{
+		crls:         crls,
+		ocspStapling: cfg.OCSPStapling,
+		softFail:     cfg.SoftFail,
+	}
+
+	return v.verifyConnection, nil
+}
+
+func (v *revocationVerifier) verifyConnection(cs tls.ConnectionState) error {
+	chain := cs.PeerCertificates
+	if len(cs.VerifiedChains) > 0 {
+		chain = cs.VerifiedChains[0]
+	}
+
+	if len(chain) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	for i, cert := range chain {
+		if i+1 >= len(chain) {
+			// the last certificate is the root or its issuer is not part of the chain
+			break
+		}
+
+		err := v.checkCRLs(cert, chain[i+1], now)
+		if err != nil {
+			return err
+		}
+	}
+
+	if v.ocspStapling {
+		return v.checkOCSP(cs.OCSPResponse, chain)
+	}
+
+	return nil
+}
+
+func (v *revocationVerifier) checkCRLs(cert, issuer *x509.Certificate, now time.Time) error {
+	for _, crl := range v.crls {
+		if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) || crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+
+		for _, entry := range crl.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return fmt.Errorf("%w: %s (serial %s)", ErrCertificateRevoked, cert.Subject, cert.SerialNumber)
+			}
+		}
+
+		if !crl.NextUpdate.IsZero() && now.After(crl.NextUpdate) {
+			return v.unknown(fmt.Sprintf("the CRL of %s expired at %s", crl.Issuer, crl.NextUpdate.Format(time.RFC3339)))
+		}
+	}
+
+	return nil
+}
+
+func (v *revocationVerifier) checkOCSP(staple []byte, chain []*x509.Certificate) error {
+	if len(staple) == 0 {
+		return v.unknown("no OCSP response stapled")
+	}
+
+	if len(chain) < 2 {
+		return v.unknown("the issuer of the OCSP response is not part of the chain")
+	}
+
+	resp, err := ocsp.ParseResponseForCert(staple, chain[0], chain[1])
+	if err != nil {
+		return v.unknown("invalid OCSP response: " + err.Error())
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		if !resp.NextUpdate.IsZero() && time.Now().After(resp.NextUpdate) {
+			return v.unknown("the OCSP response expired at " + resp.NextUpdate.Format(time.RFC3339))
+		}
+
+		return nil
+	case ocsp.Revoked:
+		return fmt.Errorf("%w: %s (serial %s)", ErrCertificateRevoked, chain[0].Subject, chain[0].SerialNumber)
+	default:
+		return v.unknown("the OCSP responder does not know the certificate")
+	}
+}
+
+func (v *revocationVerifier) unknown(reason string) error {
+	if v.softFail {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrRevocationStatusUnknown, reason)
+}
@@ -0,0 +1,206 @@
+package commoncfg_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+type revocationFixture struct {
+	ca    *x509.Certificate
+	caKey crypto.Signer
+	leaf  *x509.Certificate
+}
+
+func newRevocationFixture(t *testing.T) *revocationFixture {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(leafDER)
+	require.NoError(t, err)
+
+	return &revocationFixture{ca: ca, caKey: caKey, leaf: leaf}
+}
+
+func (f *revocationFixture) crl(t *testing.T, nextUpdate time.Time, revoked ...*big.Int) string {
+	t.Helper()
+
+	entries := make([]x509.RevocationListEntry, 0, len(revoked))
+	for _, serial := range revoked {
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: serial, RevocationTime: time.Now()})
+	}
+
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now().Add(-time.Hour),
+		NextUpdate:                nextUpdate,
+		RevokedCertificateEntries: entries,
+	}, f.ca, f.caKey)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}))
+}
+
+func (f *revocationFixture) ocspResponse(t *testing.T, status int) []byte {
+	t.Helper()
+
+	resp, err := ocsp.CreateResponse(f.ca, f.ca, ocsp.Response{
+		Status:       status,
+		SerialNumber: f.leaf.SerialNumber,
+		ThisUpdate:   time.Now().Add(-time.Minute),
+		NextUpdate:   time.Now().Add(time.Hour),
+		RevokedAt:    time.Now().Add(-time.Minute),
+	}, f.caKey)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func (f *revocationFixture) verify(t *testing.T, revocation *commoncfg.TLSRevocation, staple []byte) error {
+	t.Helper()
+
+	certPEM, keyPEM := generateTestCert(t)
+
+	tlsConfig, err := commoncfg.LoadMTLSConfig(&commoncfg.MTLS{
+		Cert:       commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(certPEM)},
+		CertKey:    commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(keyPEM)},
+		Revocation: revocation,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig.VerifyConnection)
+
+	return tlsConfig.VerifyConnection(tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{f.leaf},
+		VerifiedChains:   [][]*x509.Certificate{{f.leaf, f.ca}},
+		OCSPResponse:     staple,
+	})
+}
+
+func TestLoadMTLSConfigRevocationCRL(t *testing.T) {
+	f := newRevocationFixture(t)
+
+	crlRef := func(crl string) []commoncfg.SourceRef {
+		return []commoncfg.SourceRef{{Source: commoncfg.EmbeddedSourceValue, Value: crl}}
+	}
+
+	t.Run("accepts certificates which are not revoked", func(t *testing.T) {
+		err := f.verify(t, &commoncfg.TLSRevocation{CRLs: crlRef(f.crl(t, time.Now().Add(time.Hour), big.NewInt(7)))}, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects revoked certificates", func(t *testing.T) {
+		err := f.verify(t, &commoncfg.TLSRevocation{CRLs: crlRef(f.crl(t, time.Now().Add(time.Hour), f.leaf.SerialNumber))}, nil)
+		assert.ErrorIs(t, err, commoncfg.ErrCertificateRevoked)
+	})
+
+	t.Run("rejects revoked certificates in soft fail mode", func(t *testing.T) {
+		err := f.verify(t, &commoncfg.TLSRevocation{
+			CRLs:     crlRef(f.crl(t, time.Now().Add(time.Hour), f.leaf.SerialNumber)),
+			SoftFail: true,
+		}, nil)
+		assert.ErrorIs(t, err, commoncfg.ErrCertificateRevoked)
+	})
+
+	t.Run("expired CRLs", func(t *testing.T) {
+		expired := crlRef(f.crl(t, time.Now().Add(-time.Minute)))
+
+		err := f.verify(t, &commoncfg.TLSRevocation{CRLs: expired}, nil)
+		require.ErrorIs(t, err, commoncfg.ErrRevocationStatusUnknown)
+
+		err = f.verify(t, &commoncfg.TLSRevocation{CRLs: expired, SoftFail: true}, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ignores CRLs of other issuers", func(t *testing.T) {
+		other := newRevocationFixture(t)
+
+		err := f.verify(t, &commoncfg.TLSRevocation{CRLs: crlRef(other.crl(t, time.Now().Add(time.Hour), f.leaf.SerialNumber))}, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("errors on invalid CRLs", func(t *testing.T) {
+		certPEM, keyPEM := generateTestCert(t)
+
+		_, err := commoncfg.LoadMTLSConfig(&commoncfg.MTLS{
+			Cert:       commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(certPEM)},
+			CertKey:    commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(keyPEM)},
+			Revocation: &commoncfg.TLSRevocation{CRLs: crlRef("not a crl")},
+		})
+		assert.ErrorIs(t, err, commoncfg.ErrInvalidCRL)
+	})
+}
+
+func TestLoadMTLSConfigRevocationOCSP(t *testing.T) {
+	f := newRevocationFixture(t)
+
+	t.Run("accepts a good stapled response", func(t *testing.T) {
+		err := f.verify(t, &commoncfg.TLSRevocation{OCSPStapling: true}, f.ocspResponse(t, ocsp.Good))
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects a revoked stapled response", func(t *testing.T) {
+		err := f.verify(t, &commoncfg.TLSRevocation{OCSPStapling: true, SoftFail: true}, f.ocspResponse(t, ocsp.Revoked))
+		assert.ErrorIs(t, err, commoncfg.ErrCertificateRevoked)
+	})
+
+	t.Run("missing stapled response", func(t *testing.T) {
+		err := f.verify(t, &commoncfg.TLSRevocation{OCSPStapling: true}, nil)
+		require.ErrorIs(t, err, commoncfg.ErrRevocationStatusUnknown)
+
+		err = f.verify(t, &commoncfg.TLSRevocation{OCSPStapling: true, SoftFail: true}, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects responses of other issuers", func(t *testing.T) {
+		other := newRevocationFixture(t)
+		other.leaf = f.leaf
+
+		err := f.verify(t, &commoncfg.TLSRevocation{OCSPStapling: true}, other.ocspResponse(t, ocsp.Good))
+		assert.ErrorIs(t, err, commoncfg.ErrRevocationStatusUnknown)
+	})
+}
@@ -0,0 +1,364 @@
+package commoncfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// JSONSchemaDialect is the JSON Schema version of the generated schemas.
+	JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+	// durationPattern matches the values accepted by ParseDuration.
+	durationPattern = `^[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h|d))+)$`
+	// byteSizePattern matches the values accepted by ParseByteSize.
+	byteSizePattern = `^[0-9]+(\.[0-9]+)?\s*([kKmMgGtT]([iI]?[bB])?|[bB])?$`
+	// hostPortPattern matches host:port addresses with an optional host.
+	hostPortPattern = `^(\[[^\]]*\]|[^:]*):[0-9]{1,5}$`
+)
+
+// JSONSchema is a JSON Schema document or sub schema.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*JSONSchema `json:"$defs,omitempty"`
+}
+
+// SchemaOption configures the JSON Schema generation.
+type SchemaOption func(*schemaGenerator)
+
+// WithSchemaID sets the $id of the generated schema.
+func WithSchemaID(id string) SchemaOption {
+	return func(g *schemaGenerator) {
+		g.id = id
+	}
+}
+
+// WithSchemaTitle sets the title of the generated schema.
+func WithSchemaTitle(title string) SchemaOption {
+	return func(g *schemaGenerator) {
+		g.title = title
+	}
+}
+
+// WithAdditionalProperties allows properties which are not part of the configuration model.
+// By default they are rejected, as the Loader does unless DisableViperErrorUnused is used.
+func WithAdditionalProperties() SchemaOption {
+	return func(g *schemaGenerator) {
+		g.additionalProperties = true
+	}
+}
+
+// GenerateJSONSchema generates the JSON Schema of a configuration model, which must be a struct
+// or a pointer to a struct, e.g. a service configuration embedding BaseConfig inline.
+//
+// The schema uses the yaml field names, the default struct tags and the validate
+// struct tags (see ValidateTag), so it can be used to validate Helm values or to
+// provide completion for YAML configuration files in IDEs. Durations are represented
+// as strings in the ParseDuration format. Named struct types are placed into $defs.
+//
+// It can be wired into a service with go:generate, e.g. with a small program writing
+// the output of GenerateJSONSchema(&Config{}) to config.schema.json.
+func GenerateJSONSchema(v any, opts ...SchemaOption) (*JSONSchema, error) {
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: expected a struct to generate the schema from", ErrInvalidConfig)
+	}
+
+	g := &schemaGenerator{defs: make(map[string]*JSONSchema)}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(g)
+		}
+	}
+
+	root := g.structSchema(typ)
+	root.Schema = JSONSchemaDialect
+	root.ID = g.id
+	root.Title = g.title
+
+	if len(g.defs) > 0 {
+		root.Defs = g.defs
+	}
+
+	return root, nil
+}
+
+// MarshalJSONSchema generates the JSON Schema of the configuration model as indented JSON.
+func MarshalJSONSchema(v any, opts ...SchemaOption) ([]byte, error) {
+	schema, err := GenerateJSONSchema(v, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// JSONSchema returns the JSON Schema of the base configuration.
+func (c *BaseConfig) JSONSchema() ([]byte, error) {
+	return MarshalJSONSchema(c, WithSchemaTitle("BaseConfig"))
+}
+
+type schemaGenerator struct {
+	id                   string
+	title                string
+	additionalProperties bool
+	defs                 map[string]*JSONSchema
+}
+
+// typeSchema returns the schema of a type, named struct types are referenced from $defs.
+func (g *schemaGenerator) typeSchema(typ reflect.Type) *JSONSchema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ == reflect.TypeFor[time.Duration]() || typ == reflect.TypeFor[Duration]() {
+		return &JSONSchema{Type: "string", Pattern: durationPattern}
+	}
+
+	if typ == reflect.TypeFor[ByteSize]() {
+		// a number of bytes or a size with a unit, e.g. 120MB
+		return &JSONSchema{AnyOf: []*JSONSchema{{Type: "integer"}, {Type: "string", Pattern: byteSizePattern}}}
+	}
+
+	if typ == reflect.TypeFor[FeatureGate]() {
+		// a boolean is the shorthand of a gate without tenant rules
+		return &JSONSchema{AnyOf: []*JSONSchema{{Type: "boolean"}, g.namedStructSchema(typ)}}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string"}
+		}
+
+		return &JSONSchema{Type: "array", Items: g.typeSchema(typ.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: g.typeSchema(typ.Elem())}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return g.structSchema(typ)
+		}
+
+		return g.namedStructSchema(typ)
+	default:
+		// interfaces and other kinds accept any value
+		return &JSONSchema{}
+	}
+}
+
+// namedStructSchema returns a reference to the schema of a named struct type in $defs.
+func (g *schemaGenerator) namedStructSchema(typ reflect.Type) *JSONSchema {
+	name := typ.Name()
+	if _, ok := g.defs[name]; !ok {
+		// register before generating the properties to support recursive types
+		g.defs[name] = &JSONSchema{}
+		*g.defs[name] = *g.structSchema(typ)
+	}
+
+	return &JSONSchema{Ref: "#/$defs/" + name}
+}
+
+func (g *schemaGenerator) structSchema(typ reflect.Type) *JSONSchema {
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: make(map[string]*JSONSchema),
+	}
+
+	if !g.additionalProperties {
+		schema.AdditionalProperties = false
+	}
+
+	g.addFields(schema, typ)
+
+	return schema
+}
+
+func (g *schemaGenerator) addFields(schema *JSONSchema, typ reflect.Type) {
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, inline := fieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		if inline {
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+
+			if fieldType.Kind() == reflect.Struct {
+				g.addFields(schema, fieldType)
+				continue
+			}
+
+			name = field.Name
+		}
+
+		prop := g.typeSchema(field.Type)
+		if prop.Ref != "" {
+			// keep the shared definition untouched by the field specific keywords
+			prop = &JSONSchema{Ref: prop.Ref}
+		}
+
+		setSchemaDefault(prop, field)
+
+		if applySchemaRules(prop, field) {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = prop
+	}
+}
+
+// setSchemaDefault converts the default struct tag into the JSON type of the field.
+func setSchemaDefault(prop *JSONSchema, field reflect.StructField) {
+	def, ok := field.Tag.Lookup("default")
+	if !ok || prop.Ref != "" {
+		return
+	}
+
+	if field.Type == reflect.TypeFor[ByteSize]() {
+		prop.Default = def
+		if i, err := strconv.ParseInt(def, 10, 64); err == nil {
+			prop.Default = i
+		}
+
+		return
+	}
+
+	switch prop.Type {
+	case "boolean":
+		if b, err := strconv.ParseBool(def); err == nil {
+			prop.Default = b
+		}
+	case "integer":
+		if i, err := strconv.ParseInt(def, 10, 64); err == nil {
+			prop.Default = i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(def, 64); err == nil {
+			prop.Default = f
+		}
+	case "string":
+		prop.Default = def
+	default:
+		var v any
+		if err := json.Unmarshal([]byte(def), &v); err == nil {
+			prop.Default = v
+		}
+	}
+}
+
+// applySchemaRules translates the validate struct tag into schema keywords
+// and reports whether the field is required.
+func applySchemaRules(prop *JSONSchema, field reflect.StructField) bool {
+	required := false
+
+	for rule := range strings.SplitSeq(field.Tag.Get(ValidateTag), ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			required = true
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				prop.Enum = append(prop.Enum, enumValue(prop.Type, v))
+			}
+		case "hostport":
+			prop.Pattern = hostPortPattern
+		case "url":
+			prop.Format = "uri"
+		case "min", "max":
+			applySchemaBound(prop, name, arg)
+		}
+	}
+
+	return required
+}
+
+func applySchemaBound(prop *JSONSchema, name, arg string) {
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		// bounds which are not numbers, e.g. durations, cannot be expressed in the schema
+		return
+	}
+
+	length := int(bound)
+
+	switch prop.Type {
+	case "integer", "number":
+		if name == "min" {
+			prop.Minimum = &bound
+		} else {
+			prop.Maximum = &bound
+		}
+	case "string":
+		if name == "min" {
+			prop.MinLength = &length
+		} else {
+			prop.MaxLength = &length
+		}
+	case "array":
+		if name == "min" {
+			prop.MinItems = &length
+		} else {
+			prop.MaxItems = &length
+		}
+	}
+}
+
+func enumValue(typ, v string) any {
+	switch typ {
+	case "integer":
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,147 @@
+package commoncfg_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestBaseConfigJSONSchema(t *testing.T) {
+	// Act
+	data, err := (&commoncfg.BaseConfig{}).JSONSchema()
+	require.NoError(t, err)
+
+	// Assert
+	var schema commoncfg.JSONSchema
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	assert.Equal(t, commoncfg.JSONSchemaDialect, schema.Schema)
+	assert.Equal(t, "BaseConfig", schema.Title)
+	assert.Equal(t, "#/$defs/Telemetry", schema.Properties["telemetry"].Ref)
+	assert.Equal(t, false, schema.AdditionalProperties)
+
+	status := schema.Defs["Status"]
+	require.NotNil(t, status)
+	assert.Equal(t, ":8888", status.Properties["address"].Default)
+	assert.NotEmpty(t, status.Properties["address"].Pattern)
+	assert.Equal(t, "10s", status.Properties["timeout"].Default)
+
+	trace := schema.Defs["Trace"]
+	require.NotNil(t, trace)
+	assert.Equal(t, []any{"grpc", "http", "stdout", "file"}, trace.Properties["protocol"].Enum)
+	assert.Equal(t, "#/$defs/SecretRef", trace.Properties["secretRef"].Ref)
+
+	// recursive types are referenced from $defs
+	vault := schema.Defs["VaultSource"]
+	require.NotNil(t, vault)
+	assert.Equal(t, "#/$defs/SourceRef", vault.Properties["serverCa"].Ref)
+	assert.Equal(t, "uri", vault.Properties["address"].Format)
+
+	assert.Contains(t, schema.Defs["Component"].Properties, "sha")
+
+	// feature gates are booleans or rules
+	gate := schema.Properties["featureGates"].AdditionalProperties.(map[string]any) //nolint:forcetypeassert
+	assert.Equal(t, []any{
+		map[string]any{"type": "boolean"},
+		map[string]any{"$ref": "#/$defs/FeatureGate"},
+	}, gate["anyOf"])
+	assert.Contains(t, schema.Defs["FeatureGate"].Properties, "percentage")
+}
+
+type schemaChild struct {
+	Name string `yaml:"name" validate:"required"`
+}
+
+type schemaConfig struct {
+	commoncfg.Status `yaml:",inline"`
+
+	Workers  int                `yaml:"workers" default:"4" validate:"min=1,max=16"`
+	Ratio    float64            `yaml:"ratio" default:"0.5"`
+	Mode     string             `yaml:"mode" validate:"required,oneof=a b,min=1"`
+	Levels   int                `yaml:"levels" validate:"oneof=1 2"`
+	Children []schemaChild      `yaml:"children" validate:"max=3"`
+	Labels   map[string]string  `yaml:"labels" default:"{}"`
+	Interval time.Duration      `yaml:"interval" default:"1m" validate:"min=1s"`
+	Size     commoncfg.ByteSize `yaml:"size" default:"4MB"`
+	Raw      []byte             `yaml:"raw"`
+	Any      any                `yaml:"any"`
+	Ignored  string             `yaml:"-"`
+	Nested   struct {
+		Enabled bool `yaml:"enabled" default:"true"`
+	} `yaml:"nested"`
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	// Act
+	schema, err := commoncfg.GenerateJSONSchema(&schemaConfig{},
+		commoncfg.WithSchemaID("https://example.com/config.schema.json"),
+		commoncfg.WithAdditionalProperties(),
+	)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "https://example.com/config.schema.json", schema.ID)
+	assert.Nil(t, schema.AdditionalProperties)
+	assert.Equal(t, []string{"mode"}, schema.Required)
+
+	// inlined fields are merged into the parent
+	assert.Contains(t, schema.Properties, "address")
+	assert.NotContains(t, schema.Properties, "Ignored")
+	assert.NotContains(t, schema.Properties, "-")
+
+	workers := schema.Properties["workers"]
+	assert.Equal(t, "integer", workers.Type)
+	assert.Equal(t, int64(4), workers.Default)
+	assert.InDelta(t, 1, *workers.Minimum, 0)
+	assert.InDelta(t, 16, *workers.Maximum, 0)
+
+	assert.Equal(t, "number", schema.Properties["ratio"].Type)
+	assert.InDelta(t, 0.5, schema.Properties["ratio"].Default, 0)
+
+	mode := schema.Properties["mode"]
+	assert.Equal(t, []any{"a", "b"}, mode.Enum)
+	assert.Equal(t, 1, *mode.MinLength)
+
+	assert.Equal(t, []any{int64(1), int64(2)}, schema.Properties["levels"].Enum)
+
+	children := schema.Properties["children"]
+	assert.Equal(t, "array", children.Type)
+	assert.Equal(t, 3, *children.MaxItems)
+	assert.Equal(t, "#/$defs/schemaChild", children.Items.Ref)
+	assert.Equal(t, []string{"name"}, schema.Defs["schemaChild"].Required)
+
+	labels := schema.Properties["labels"]
+	assert.Equal(t, "object", labels.Type)
+	assert.Equal(t, map[string]any{}, labels.Default)
+
+	interval := schema.Properties["interval"]
+	assert.Equal(t, "string", interval.Type)
+	assert.Equal(t, "1m", interval.Default)
+	assert.Nil(t, interval.Minimum)
+
+	size := schema.Properties["size"]
+	require.Len(t, size.AnyOf, 2)
+	assert.Equal(t, "integer", size.AnyOf[0].Type)
+	assert.Equal(t, "string", size.AnyOf[1].Type)
+	assert.Equal(t, "4MB", size.Default)
+
+	assert.Equal(t, "string", schema.Properties["raw"].Type)
+	assert.Empty(t, schema.Properties["any"].Type)
+
+	nested := schema.Properties["nested"]
+	assert.Equal(t, "object", nested.Type)
+	assert.Equal(t, true, nested.Properties["enabled"].Default)
+}
+
+func TestGenerateJSONSchemaInvalidInput(t *testing.T) {
+	_, err := commoncfg.GenerateJSONSchema(nil)
+	require.ErrorIs(t, err, commoncfg.ErrInvalidConfig)
+
+	_, err = commoncfg.MarshalJSONSchema("config")
+	require.ErrorIs(t, err, commoncfg.ErrInvalidConfig)
+}
@@ -0,0 +1,576 @@
+package commoncfg
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/goccy/go-yaml"
+)
+
+// FileEncryption defines how the content of a file source is encrypted.
+type FileEncryption string
+
+const (
+	// SOPSFileEncryption is a file encrypted with SOPS (https://getsops.io). Only the
+	// values are encrypted, the data key is encrypted with age or AWS KMS.
+	SOPSFileEncryption FileEncryption = "sops"
+	// AgeFileEncryption is a file encrypted as a whole with age (https://age-encryption.org),
+	// either binary or ASCII armored.
+	AgeFileEncryption FileEncryption = "age"
+
+	// SOPSAgeKeyEnv holds one or more age identities, one per line.
+	SOPSAgeKeyEnv = "SOPS_AGE_KEY"
+	// SOPSAgeKeyFileEnv holds the path of a file with one or more age identities.
+	SOPSAgeKeyFileEnv = "SOPS_AGE_KEY_FILE"
+
+	sopsMetadataKey = "sops"
+	// sopsBinaryKey holds the content of files encrypted by SOPS in binary mode.
+	sopsBinaryKey = "data"
+)
+
+var (
+	ErrFileEncryptionUnsupported = errors.New("unsupported file encryption")
+	ErrAgeIdentityMissing        = errors.New("no age identity found, set " + SOPSAgeKeyEnv + " or " + SOPSAgeKeyFileEnv)
+	ErrSOPSMetadataMissing       = errors.New("sops metadata not found")
+	ErrSOPSDataKey               = errors.New("failed to decrypt the sops data key")
+	ErrSOPSInvalidValue          = errors.New("invalid sops encrypted value")
+	ErrSOPSMACMismatch           = errors.New("sops message authentication code does not match, the file was modified")
+)
+
+// sopsValueRegex matches the values encrypted by SOPS.
+var sopsValueRegex = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// sopsMACOnlyEncryptedInit initializes the MAC of files with mac_only_encrypted,
+// it is the SHA-256 hash of "sops".
+var sopsMACOnlyEncryptedInit = []byte{
+	0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3, 0xd1, 0x47, 0xbe, 0x0b,
+	0x0b, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69,
+}
+
+type sopsFile struct {
+	SOPS *sopsMetadata `yaml:"sops"`
+}
+
+type sopsMetadata struct {
+	KMS                     []sopsKMSKey   `yaml:"kms"`
+	Age                     []sopsAgeKey   `yaml:"age"`
+	KeyGroups               []sopsKeyGroup `yaml:"key_groups"`
+	LastModified            string         `yaml:"lastmodified"`
+	MAC                     string         `yaml:"mac"`
+	UnencryptedSuffix       string         `yaml:"unencrypted_suffix"`
+	EncryptedSuffix         string         `yaml:"encrypted_suffix"`
+	UnencryptedRegex        string         `yaml:"unencrypted_regex"`
+	EncryptedRegex          string         `yaml:"encrypted_regex"`
+	UnencryptedCommentRegex string         `yaml:"unencrypted_comment_regex"`
+	EncryptedCommentRegex   string         `yaml:"encrypted_comment_regex"`
+	MACOnlyEncrypted        bool           `yaml:"mac_only_encrypted"`
+}
+
+type sopsKeyGroup struct {
+	KMS []sopsKMSKey `yaml:"kms"`
+	Age []sopsAgeKey `yaml:"age"`
+}
+
+type sopsKMSKey struct {
+	ARN              string            `yaml:"arn"`
+	Role             string            `yaml:"role"`
+	Context          map[string]string `yaml:"context"`
+	EncryptedDataKey string            `yaml:"enc"`
+}
+
+type sopsAgeKey struct {
+	Recipient        string `yaml:"recipient"`
+	EncryptedDataKey string `yaml:"enc"`
+}
+
+// decryptFile decrypts the content of a file source according to its encryption.
+func decryptFile(data []byte, file CredentialFile) ([]byte, error) {
+	switch file.Encryption {
+	case "":
+		return data, nil
+	case SOPSFileEncryption:
+		return decryptSOPS(data, file.Format)
+	case AgeFileEncryption:
+		return decryptAge(data)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrFileEncryptionUnsupported, file.Encryption)
+}
+
+// decryptAge decrypts a binary or ASCII armored age file with the identities of the environment.
+func decryptAge(data []byte) ([]byte, error) {
+	identities, err := ageIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age file: %w", err)
+	}
+
+	return io.ReadAll(r)
+}
+
+// ageIdentities loads the age identities from SOPS_AGE_KEY and SOPS_AGE_KEY_FILE.
+func ageIdentities() ([]age.Identity, error) {
+	var identities []age.Identity
+
+	if keys := strings.TrimSpace(os.Getenv(SOPSAgeKeyEnv)); keys != "" {
+		ids, err := age.ParseIdentities(strings.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", SOPSAgeKeyEnv, err)
+		}
+
+		identities = append(identities, ids...)
+	}
+
+	if path := strings.TrimSpace(os.Getenv(SOPSAgeKeyFileEnv)); path != "" {
+		keys, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", SOPSAgeKeyFileEnv, err)
+		}
+
+		ids, err := age.ParseIdentities(bytes.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		identities = append(identities, ids...)
+	}
+
+	if len(identities) == 0 {
+		return nil, ErrAgeIdentityMissing
+	}
+
+	return identities, nil
+}
+
+// decryptSOPS decrypts a SOPS file and returns it in the given format without the sops
+// metadata. The data key is decrypted with the age identities of the environment or with
+// AWS KMS, using the default AWS credential chain. The integrity of the file is verified
+// with its message authentication code.
+func decryptSOPS(data []byte, format FileFormat) ([]byte, error) {
+	var file sopsFile
+
+	err := yaml.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.SOPS == nil {
+		return nil, ErrSOPSMetadataMissing
+	}
+
+	meta := file.SOPS
+	if meta.UnencryptedCommentRegex != "" || meta.EncryptedCommentRegex != "" {
+		return nil, fmt.Errorf("%w: comment based encryption rules are not supported", ErrFileEncryptionUnsupported)
+	}
+
+	var doc yaml.MapSlice
+
+	err = yaml.UnmarshalWithOptions(data, &doc, yaml.UseOrderedMap())
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := sopsDataKey(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := newSOPSDecrypter(key, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := make(yaml.MapSlice, 0, len(doc))
+
+	for _, item := range doc {
+		if fmt.Sprint(item.Key) == sopsMetadataKey {
+			continue
+		}
+
+		value, err := d.decrypt(item.Value, []string{fmt.Sprint(item.Key)})
+		if err != nil {
+			return nil, err
+		}
+
+		tree = append(tree, yaml.MapItem{Key: item.Key, Value: value})
+	}
+
+	mac, _, err := decryptSOPSValue(meta.MAC, key, meta.LastModified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt sops message authentication code: %w", err)
+	}
+
+	if string(mac) != fmt.Sprintf("%X", d.mac.Sum(nil)) {
+		return nil, ErrSOPSMACMismatch
+	}
+
+	switch format {
+	case JSONFileFormat:
+		return json.Marshal(jsonValue(tree))
+	case BinaryFileFormat:
+		for _, item := range tree {
+			if fmt.Sprint(item.Key) == sopsBinaryKey {
+				return []byte(fmt.Sprint(item.Value)), nil
+			}
+		}
+
+		return nil, fmt.Errorf("%w: missing %q of a binary file", ErrSOPSInvalidValue, sopsBinaryKey)
+	default:
+		return yaml.Marshal(tree)
+	}
+}
+
+// sopsDataKey decrypts the data key with the first master key which succeeds.
+func sopsDataKey(meta *sopsMetadata) ([]byte, error) {
+	ageKeys, kmsKeys := meta.Age, meta.KMS
+
+	switch len(meta.KeyGroups) {
+	case 0:
+	case 1:
+		ageKeys = append(ageKeys, meta.KeyGroups[0].Age...)
+		kmsKeys = append(kmsKeys, meta.KeyGroups[0].KMS...)
+	default:
+		return nil, fmt.Errorf("%w: shamir secret sharing is not supported", ErrFileEncryptionUnsupported)
+	}
+
+	var errs []error
+
+	if len(ageKeys) > 0 {
+		identities, err := ageIdentities()
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		for _, k := range ageKeys {
+			if len(identities) == 0 {
+				break
+			}
+
+			r, err := age.Decrypt(armor.NewReader(strings.NewReader(strings.TrimSpace(k.EncryptedDataKey))), identities...)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("age recipient %s: %w", k.Recipient, err))
+				continue
+			}
+
+			key, err := io.ReadAll(r)
+			if err == nil {
+				return key, nil
+			}
+
+			errs = append(errs, fmt.Errorf("age recipient %s: %w", k.Recipient, err))
+		}
+	}
+
+	for _, k := range kmsKeys {
+		key, err := decryptKMSDataKey(k)
+		if err == nil {
+			return key, nil
+		}
+
+		errs = append(errs, fmt.Errorf("kms key %s: %w", k.ARN, err))
+	}
+
+	if len(errs) == 0 {
+		errs = append(errs, errors.New("no supported master key found, only age and kms are supported"))
+	}
+
+	return nil, fmt.Errorf("%w: %w", ErrSOPSDataKey, errors.Join(errs...))
+}
+
+// decryptKMSDataKey decrypts the data key with AWS KMS in the region of the key.
+func decryptKMSDataKey(k sopsKMSKey) ([]byte, error) {
+	blob, err := base64.StdEncoding.DecodeString(k.EncryptedDataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// arn:aws:kms:<region>:<account>:key/<id>
+	var region string
+	if parts := strings.Split(k.ARN, ":"); len(parts) > 3 {
+		region = parts[3]
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultAWSTimeout)
+	defer cancel()
+
+	awsCfg, err := newAWSConfig(ctx, &AWSSource{Region: region, RoleARN: k.Role})
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := kms.NewFromConfig(awsCfg).Decrypt(ctx, &kms.DecryptInput{
+		KeyId:             aws.String(k.ARN),
+		CiphertextBlob:    blob,
+		EncryptionContext: k.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Plaintext, nil
+}
+
+// sopsDecrypter decrypts the values of a SOPS tree and computes its message authentication code.
+type sopsDecrypter struct {
+	key              []byte
+	meta             *sopsMetadata
+	unencryptedRegex *regexp.Regexp
+	encryptedRegex   *regexp.Regexp
+	mac              hash.Hash
+}
+
+func newSOPSDecrypter(key []byte, meta *sopsMetadata) (*sopsDecrypter, error) {
+	d := &sopsDecrypter{key: key, meta: meta, mac: sha512.New()}
+	if meta.MACOnlyEncrypted {
+		d.mac.Write(sopsMACOnlyEncryptedInit)
+	}
+
+	var err error
+
+	if meta.UnencryptedRegex != "" {
+		d.unencryptedRegex, err = regexp.Compile(meta.UnencryptedRegex)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if meta.EncryptedRegex != "" {
+		d.encryptedRegex, err = regexp.Compile(meta.EncryptedRegex)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// decrypt walks the tree in document order, as the MAC depends on the order of the values.
+func (d *sopsDecrypter) decrypt(in any, path []string) (any, error) {
+	switch v := in.(type) {
+	case yaml.MapSlice:
+		out := make(yaml.MapSlice, 0, len(v))
+		for _, item := range v {
+			value, err := d.decrypt(item.Value, append(path[:len(path):len(path)], fmt.Sprint(item.Key)))
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, yaml.MapItem{Key: item.Key, Value: value})
+		}
+
+		return out, nil
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, item := range v {
+			value, err := d.decrypt(item, path)
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, value)
+		}
+
+		return out, nil
+	case nil:
+		return nil, nil
+	}
+
+	if !d.encrypted(path) {
+		if !d.meta.MACOnlyEncrypted {
+			d.mac.Write(sopsMACBytes(in))
+		}
+
+		return in, nil
+	}
+
+	s, ok := in.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not encrypted", ErrSOPSInvalidValue, strings.Join(path, "."))
+	}
+
+	plaintext, typ, err := decryptSOPSValue(s, d.key, strings.Join(path, ":")+":")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.Join(path, "."), err)
+	}
+
+	if typ == "comment" {
+		return string(plaintext), nil
+	}
+
+	d.mac.Write(plaintext)
+
+	return sopsTypedValue(plaintext, typ)
+}
+
+// encrypted applies the encryption rules of the metadata to the path of a value.
+func (d *sopsDecrypter) encrypted(path []string) bool {
+	encrypted := true
+
+	if suffix := d.meta.UnencryptedSuffix; suffix != "" {
+		for _, p := range path {
+			if strings.HasSuffix(p, suffix) {
+				encrypted = false
+				break
+			}
+		}
+	}
+
+	if suffix := d.meta.EncryptedSuffix; suffix != "" {
+		encrypted = false
+
+		for _, p := range path {
+			if strings.HasSuffix(p, suffix) {
+				encrypted = true
+				break
+			}
+		}
+	}
+
+	if d.unencryptedRegex != nil {
+		for _, p := range path {
+			if d.unencryptedRegex.MatchString(p) {
+				encrypted = false
+				break
+			}
+		}
+	}
+
+	if d.encryptedRegex != nil {
+		encrypted = false
+
+		for _, p := range path {
+			if d.encryptedRegex.MatchString(p) {
+				encrypted = true
+				break
+			}
+		}
+	}
+
+	return encrypted
+}
+
+// decryptSOPSValue decrypts a value in the ENC[AES256_GCM,data:...,iv:...,tag:...,type:...] format.
+func decryptSOPSValue(value string, key []byte, additionalData string) ([]byte, string, error) {
+	if value == "" {
+		return nil, "str", nil
+	}
+
+	m := sopsValueRegex.FindStringSubmatch(value)
+	if m == nil {
+		return nil, "", ErrSOPSInvalidValue
+	}
+
+	parts := make([][]byte, 0, 3)
+
+	for _, part := range m[1:4] {
+		b, err := base64.StdEncoding.DecodeString(part)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrSOPSInvalidValue, err)
+		}
+
+		parts = append(parts, b)
+	}
+
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, "", err
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrSOPSInvalidValue, err)
+	}
+
+	return plaintext, m[4], nil
+}
+
+// sopsTypedValue converts a decrypted value into the type it had before the encryption.
+func sopsTypedValue(plaintext []byte, typ string) (any, error) {
+	s := string(plaintext)
+
+	switch typ {
+	case "str", "bytes", "time":
+		return s, nil
+	case "int":
+		return strconv.Atoi(s)
+	case "float":
+		return strconv.ParseFloat(s, 64)
+	case "bool":
+		return strconv.ParseBool(s)
+	}
+
+	return nil, fmt.Errorf("%w: unknown type %s", ErrSOPSInvalidValue, typ)
+}
+
+// sopsMACBytes returns the representation of an unencrypted value SOPS uses for the MAC.
+func sopsMACBytes(v any) []byte {
+	switch v := v.(type) {
+	case string:
+		return []byte(v)
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		if v {
+			return []byte("True")
+		}
+
+		return []byte("False")
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+// jsonValue converts the ordered maps of the tree into maps which can be marshalled to JSON.
+func jsonValue(in any) any {
+	switch v := in.(type) {
+	case yaml.MapSlice:
+		out := make(map[string]any, len(v))
+		for _, item := range v {
+			out[fmt.Sprint(item.Key)] = jsonValue(item.Value)
+		}
+
+		return out
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, item := range v {
+			out = append(out, jsonValue(item))
+		}
+
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,199 @@
+package commoncfg_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// The fixtures in testdata/sops were encrypted with the sops CLI for the age identity in age-key.txt.
+const sopsTestdata = "testdata/sops"
+
+func sopsRef(path string, format commoncfg.FileFormat, jsonPath string) *commoncfg.SourceRef {
+	return &commoncfg.SourceRef{
+		Source: commoncfg.FileSourceValue,
+		File: commoncfg.CredentialFile{
+			Path:       path,
+			Format:     format,
+			JSONPath:   jsonPath,
+			Encryption: commoncfg.SOPSFileEncryption,
+		},
+	}
+}
+
+func TestExtractValueFromSourceRef_SOPS(t *testing.T) {
+	t.Setenv(commoncfg.SOPSAgeKeyFileEnv, filepath.Join(sopsTestdata, "age-key.txt"))
+
+	t.Run("decrypts yaml", func(t *testing.T) {
+		val, err := commoncfg.ExtractValueFromSourceRef(sopsRef(filepath.Join(sopsTestdata, "secrets.enc.yaml"), commoncfg.YAMLFileFormat, ""))
+		require.NoError(t, err)
+
+		assert.Equal(t, `db:
+  password: s3cret
+  port: 5432
+  ratio: 1.5
+  "on": true
+list:
+- a
+- b
+plain_unencrypted: visible
+`, string(val))
+	})
+
+	t.Run("decrypts json and applies the json path", func(t *testing.T) {
+		val, err := commoncfg.ExtractValueFromSourceRef(sopsRef(filepath.Join(sopsTestdata, "secrets.enc.json"), commoncfg.JSONFileFormat, "$.token"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("json-secret"), val)
+	})
+
+	t.Run("decrypts binary", func(t *testing.T) {
+		val, err := commoncfg.ExtractValueFromSourceRef(sopsRef(filepath.Join(sopsTestdata, "secret.enc.bin"), commoncfg.BinaryFileFormat, ""))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("rawbytes"), val)
+	})
+
+	t.Run("detects modified values", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(sopsTestdata, "secrets.enc.yaml"))
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "modified.yaml")
+		err = os.WriteFile(path, bytes.Replace(data, []byte("plain_unencrypted: visible"), []byte("plain_unencrypted: modified"), 1), 0o600)
+		require.NoError(t, err)
+
+		_, err = commoncfg.ExtractValueFromSourceRef(sopsRef(path, commoncfg.YAMLFileFormat, ""))
+		assert.ErrorIs(t, err, commoncfg.ErrSOPSMACMismatch)
+	})
+
+	t.Run("errors on files without sops metadata", func(t *testing.T) {
+		path := createTempFile(t, `{"token":"plain"}`)
+
+		_, err := commoncfg.ExtractValueFromSourceRef(sopsRef(path, commoncfg.JSONFileFormat, "$.token"))
+		assert.ErrorIs(t, err, commoncfg.ErrSOPSMetadataMissing)
+	})
+}
+
+func TestExtractValueFromSourceRef_SOPSIdentities(t *testing.T) {
+	key, err := os.ReadFile(filepath.Join(sopsTestdata, "age-key.txt"))
+	require.NoError(t, err)
+
+	ref := sopsRef(filepath.Join(sopsTestdata, "secrets.enc.json"), commoncfg.JSONFileFormat, "$.token")
+
+	t.Run("reads the identity from the environment", func(t *testing.T) {
+		t.Setenv(commoncfg.SOPSAgeKeyEnv, string(key))
+
+		val, err := commoncfg.ExtractValueFromSourceRef(ref)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("json-secret"), val)
+	})
+
+	t.Run("errors without identity", func(t *testing.T) {
+		t.Setenv(commoncfg.SOPSAgeKeyEnv, "")
+		t.Setenv(commoncfg.SOPSAgeKeyFileEnv, "")
+
+		_, err := commoncfg.ExtractValueFromSourceRef(ref)
+		assert.ErrorIs(t, err, commoncfg.ErrSOPSDataKey)
+		assert.ErrorIs(t, err, commoncfg.ErrAgeIdentityMissing)
+	})
+
+	t.Run("errors with another identity", func(t *testing.T) {
+		other, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+
+		t.Setenv(commoncfg.SOPSAgeKeyEnv, other.String())
+		t.Setenv(commoncfg.SOPSAgeKeyFileEnv, "")
+
+		_, err = commoncfg.ExtractValueFromSourceRef(ref)
+		assert.ErrorIs(t, err, commoncfg.ErrSOPSDataKey)
+	})
+}
+
+func TestExtractValueFromSourceRef_Age(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	t.Setenv(commoncfg.SOPSAgeKeyEnv, identity.String())
+
+	encrypt := func(t *testing.T, plaintext string, armored bool) string {
+		t.Helper()
+
+		var buf bytes.Buffer
+
+		var dst io.Writer = &buf
+
+		var armorWriter io.WriteCloser
+		if armored {
+			armorWriter = armor.NewWriter(&buf)
+			dst = armorWriter
+		}
+
+		w, err := age.Encrypt(dst, identity.Recipient())
+		require.NoError(t, err)
+
+		_, err = w.Write([]byte(plaintext))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		if armorWriter != nil {
+			require.NoError(t, armorWriter.Close())
+		}
+
+		path := filepath.Join(t.TempDir(), "secret.age")
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+		return path
+	}
+
+	for _, armored := range []bool{false, true} {
+		ref := &commoncfg.SourceRef{
+			Source: commoncfg.FileSourceValue,
+			File: commoncfg.CredentialFile{
+				Path:       encrypt(t, `{"password":"age-secret"}`, armored),
+				Format:     commoncfg.JSONFileFormat,
+				JSONPath:   "$.password",
+				Encryption: commoncfg.AgeFileEncryption,
+			},
+		}
+
+		val, err := commoncfg.ExtractValueFromSourceRef(ref)
+		require.NoError(t, err, "armored: %v", armored)
+		assert.Equal(t, []byte("age-secret"), val)
+	}
+
+	t.Run("errors on unencrypted files", func(t *testing.T) {
+		ref := &commoncfg.SourceRef{
+			Source: commoncfg.FileSourceValue,
+			File: commoncfg.CredentialFile{
+				Path:       createTempFile(t, "plain"),
+				Format:     commoncfg.BinaryFileFormat,
+				Encryption: commoncfg.AgeFileEncryption,
+			},
+		}
+
+		_, err := commoncfg.ExtractValueFromSourceRef(ref)
+		assert.Error(t, err)
+	})
+
+	t.Run("errors on unknown encryption", func(t *testing.T) {
+		ref := &commoncfg.SourceRef{
+			Source: commoncfg.FileSourceValue,
+			File: commoncfg.CredentialFile{
+				Path:       createTempFile(t, "plain"),
+				Format:     commoncfg.BinaryFileFormat,
+				Encryption: "gpg",
+			},
+		}
+
+		_, err := commoncfg.ExtractValueFromSourceRef(ref)
+		require.ErrorIs(t, err, commoncfg.ErrFileEncryptionUnsupported)
+		assert.Contains(t, err.Error(), "gpg")
+	})
+}
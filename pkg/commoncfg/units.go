@@ -0,0 +1,261 @@
+package commoncfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Byte sizes of the units accepted by ParseByteSize.
+const (
+	Byte     ByteSize = 1
+	KibiByte          = 1024 * Byte
+	MebiByte          = 1024 * KibiByte
+	GibiByte          = 1024 * MebiByte
+	TebiByte          = 1024 * GibiByte
+)
+
+var (
+	ErrInvalidByteSize = errors.New("invalid byte size")
+	ErrInvalidDuration = errors.New("invalid duration")
+)
+
+var (
+	byteSizeRegex = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z]*)$`)
+	daysRegex     = regexp.MustCompile(`([0-9]*\.?[0-9]+)d`)
+
+	byteSizeUnits = map[string]ByteSize{
+		"":    Byte,
+		"b":   Byte,
+		"k":   KibiByte,
+		"kb":  KibiByte,
+		"kib": KibiByte,
+		"m":   MebiByte,
+		"mb":  MebiByte,
+		"mib": MebiByte,
+		"g":   GibiByte,
+		"gb":  GibiByte,
+		"gib": GibiByte,
+		"t":   TebiByte,
+		"tb":  TebiByte,
+		"tib": TebiByte,
+	}
+)
+
+// ByteSize is a size in bytes, which can be configured as a number of bytes or with a
+// unit, e.g. 120MB or 1.5GiB. The units are case insensitive and binary, so 1KB and
+// 1KiB are both 1024 bytes, like the message sizes of gRPC.
+type ByteSize int
+
+// ParseByteSize parses a number of bytes with an optional unit, e.g. 512, 64KB or 120MiB.
+func ParseByteSize(s string) (ByteSize, error) {
+	match := byteSizeRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidByteSize, s)
+	}
+
+	unit, ok := byteSizeUnits[strings.ToLower(match[2])]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown unit in %q", ErrInvalidByteSize, s)
+	}
+
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidByteSize, s)
+	}
+
+	size := value * float64(unit)
+	if size > math.MaxInt {
+		return 0, fmt.Errorf("%w: %q is too large", ErrInvalidByteSize, s)
+	}
+
+	return ByteSize(size), nil
+}
+
+// String returns the size with the largest binary unit it is a multiple of, e.g. 120MiB.
+func (b ByteSize) String() string {
+	for _, u := range []struct {
+		size ByteSize
+		name string
+	}{
+		{TebiByte, "TiB"},
+		{GibiByte, "GiB"},
+		{MebiByte, "MiB"},
+		{KibiByte, "KiB"},
+	} {
+		if b != 0 && b%u.size == 0 {
+			return strconv.Itoa(int(b/u.size)) + u.name
+		}
+	}
+
+	return strconv.Itoa(int(b)) + "B"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = size
+
+	return nil
+}
+
+// UnmarshalJSON accepts a number of bytes or a string with a unit.
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	var size int
+	if json.Unmarshal(data, &size) == nil {
+		*b = ByteSize(size)
+		return nil
+	}
+
+	var s string
+
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidByteSize, data)
+	}
+
+	return b.UnmarshalText([]byte(s))
+}
+
+// UnmarshalYAML accepts a number of bytes or a string with a unit.
+func (b *ByteSize) UnmarshalYAML(unmarshal func(any) error) error {
+	var size int
+	if unmarshal(&size) == nil {
+		*b = ByteSize(size)
+		return nil
+	}
+
+	var s string
+
+	err := unmarshal(&s)
+	if err != nil {
+		return err
+	}
+
+	return b.UnmarshalText([]byte(s))
+}
+
+// Duration is a time.Duration which is decoded from and encoded to JSON and YAML as a
+// string, e.g. 1h30m or 7d, instead of a number of nanoseconds.
+//
+// The fields of type time.Duration accept the same strings when a configuration is loaded
+// with the Loader, so Duration is only needed by configurations decoded by other means.
+type Duration time.Duration
+
+// ParseDuration parses a duration in the format of time.ParseDuration, which additionally
+// accepts days of 24 hours as unit d, e.g. 7d or 1d12h.
+func ParseDuration(s string) (time.Duration, error) {
+	expanded := daysRegex.ReplaceAllStringFunc(strings.TrimSpace(s), func(days string) string {
+		n, err := strconv.ParseFloat(strings.TrimSuffix(days, "d"), 64)
+		if err != nil {
+			return days
+		}
+
+		return strconv.FormatFloat(n*24, 'f', -1, 64) + "h"
+	})
+
+	d, err := time.ParseDuration(expanded)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+	}
+
+	return d, nil
+}
+
+// Duration returns the duration as a time.Duration.
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// String returns the duration in the format of time.Duration.String, e.g. 1h30m0s.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// UnmarshalJSON accepts a string, or a number of nanoseconds like time.Duration.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var ns int64
+	if json.Unmarshal(data, &ns) == nil {
+		*d = Duration(ns)
+		return nil
+	}
+
+	var s string
+
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDuration, data)
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+// UnmarshalYAML accepts a string, or a number of nanoseconds like time.Duration.
+func (d *Duration) UnmarshalYAML(unmarshal func(any) error) error {
+	var ns int64
+	if unmarshal(&ns) == nil {
+		*d = Duration(ns)
+		return nil
+	}
+
+	var s string
+
+	err := unmarshal(&s)
+	if err != nil {
+		return err
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+// unitsDecodeHook decodes strings with units into byte sizes and durations when the
+// configuration is loaded, e.g. 120MB or 7d.
+func unitsDecodeHook(from, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+
+	switch to {
+	case reflect.TypeFor[ByteSize]():
+		return ParseByteSize(data.(string)) //nolint:forcetypeassert
+	case reflect.TypeFor[time.Duration]():
+		return ParseDuration(data.(string)) //nolint:forcetypeassert
+	case reflect.TypeFor[Duration]():
+		d, err := ParseDuration(data.(string)) //nolint:forcetypeassert
+		return Duration(d), err
+	default:
+		return data, nil
+	}
+}
@@ -0,0 +1,158 @@
+package commoncfg_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    commoncfg.ByteSize
+		wantErr bool
+	}{
+		{in: "512", want: 512},
+		{in: "512B", want: 512},
+		{in: "64KB", want: 64 * commoncfg.KibiByte},
+		{in: "64k", want: 64 * commoncfg.KibiByte},
+		{in: "120MB", want: 125829120},
+		{in: "120 MiB", want: 125829120},
+		{in: "1.5gb", want: 1536 * commoncfg.MebiByte},
+		{in: "2TiB", want: 2 * commoncfg.TebiByte},
+		{in: "", wantErr: true},
+		{in: "-1MB", wantErr: true},
+		{in: "12XB", wantErr: true},
+		{in: "MB", wantErr: true},
+		{in: "100000000000TB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := commoncfg.ParseByteSize(tt.in)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, commoncfg.ErrInvalidByteSize)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	assert.Equal(t, "120MiB", commoncfg.ByteSize(125829120).String())
+	assert.Equal(t, "1536MiB", (1536 * commoncfg.MebiByte).String())
+	assert.Equal(t, "1000B", commoncfg.ByteSize(1000).String())
+	assert.Equal(t, "0B", commoncfg.ByteSize(0).String())
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "250ms", want: 250 * time.Millisecond},
+		{in: "7d", want: 7 * 24 * time.Hour},
+		{in: "1d12h", want: 36 * time.Hour},
+		{in: "1.5d", want: 36 * time.Hour},
+		{in: "-1d", want: -24 * time.Hour},
+		{in: "", wantErr: true},
+		{in: "1 day", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := commoncfg.ParseDuration(tt.in)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, commoncfg.ErrInvalidDuration)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUnitsUnmarshal(t *testing.T) {
+	type limits struct {
+		Size    commoncfg.ByteSize `json:"size" yaml:"size"`
+		Timeout commoncfg.Duration `json:"timeout" yaml:"timeout"`
+	}
+
+	var fromJSON limits
+
+	err := json.Unmarshal([]byte(`{"size":"120MB","timeout":"1h30m"}`), &fromJSON)
+	require.NoError(t, err)
+	assert.Equal(t, limits{Size: 125829120, Timeout: commoncfg.Duration(90 * time.Minute)}, fromJSON)
+
+	err = json.Unmarshal([]byte(`{"size":1024,"timeout":1000000000}`), &fromJSON)
+	require.NoError(t, err)
+	assert.Equal(t, limits{Size: 1024, Timeout: commoncfg.Duration(time.Second)}, fromJSON)
+
+	var fromYAML limits
+
+	err = yaml.Unmarshal([]byte("size: 64KiB\ntimeout: 2d\n"), &fromYAML)
+	require.NoError(t, err)
+	assert.Equal(t, limits{Size: 64 * commoncfg.KibiByte, Timeout: commoncfg.Duration(48 * time.Hour)}, fromYAML)
+
+	err = yaml.Unmarshal([]byte("size: 2048\n"), &fromYAML)
+	require.NoError(t, err)
+	assert.Equal(t, commoncfg.ByteSize(2048), fromYAML.Size)
+
+	err = json.Unmarshal([]byte(`{"size":"lots"}`), &fromJSON)
+	require.ErrorIs(t, err, commoncfg.ErrInvalidByteSize)
+
+	data, err := json.Marshal(limits{Size: 120 * commoncfg.MebiByte, Timeout: commoncfg.Duration(90 * time.Minute)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"size":"120MiB","timeout":"1h30m0s"}`, string(data))
+}
+
+func TestLoadConfigUnits(t *testing.T) {
+	type config struct {
+		GRPC commoncfg.GRPCServer `yaml:"grpc"`
+	}
+
+	tmpdir := t.TempDir()
+	content := "grpc:\n  maxRecvMsgSize: 16MB\n  maxSendMsgSize: 4194304\n  attributes:\n    maxConnectionAge: 1d\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "config.yaml"), []byte(content), 0o600))
+	t.Setenv("GRPC_MAXSENDMSGSIZE", "1GiB")
+
+	cfg := &config{}
+	err := commoncfg.NewLoader(cfg, commoncfg.WithPaths(tmpdir), commoncfg.WithEnvOverride("")).LoadConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, 16*commoncfg.MebiByte, cfg.GRPC.MaxRecvMsgSize)
+	assert.Equal(t, commoncfg.GibiByte, cfg.GRPC.MaxSendMsgSize)
+	assert.Equal(t, 24*time.Hour, cfg.GRPC.Attributes.MaxConnectionAge)
+
+	t.Run("applies defaults with units", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "config.yaml"), []byte("grpc:\n  address: :9092\n"), 0o600))
+
+		cfg := &config{}
+		err := commoncfg.NewLoader(cfg, commoncfg.WithPaths(tmpdir)).LoadConfig()
+		require.NoError(t, err)
+
+		assert.Equal(t, 120*commoncfg.MebiByte, cfg.GRPC.MaxRecvMsgSize)
+	})
+
+	t.Run("errors on invalid sizes", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "config.yaml"), []byte("grpc:\n  maxRecvMsgSize: lots\n"), 0o600))
+
+		cfg := &config{}
+		err := commoncfg.NewLoader(cfg, commoncfg.WithPaths(tmpdir)).LoadConfig()
+		require.ErrorIs(t, err, commoncfg.ErrInvalidByteSize)
+	})
+}
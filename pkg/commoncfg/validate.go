@@ -0,0 +1,363 @@
+package commoncfg
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidateTag is the struct tag holding the validation rules of a field.
+//
+// Rules are separated by commas:
+//   - required: the value must not be the zero value (or empty for slices and maps)
+//   - oneof=a b c: the value must be one of the space separated values
+//   - hostport: the value must be a host:port address, the host may be empty (e.g. ":8080")
+//   - url: the value must be an absolute URL
+//   - min=x, max=x: bounds for numbers and durations, or for the length of strings, slices and maps
+//
+// All rules but required are skipped for zero values.
+const ValidateTag = "validate"
+
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// ConfigValidator can be implemented by configuration types to add rules which
+// cannot be expressed with tags, e.g. rules depending on several fields.
+// It is called by ValidateStruct for every value of the implementing type.
+type ConfigValidator interface {
+	ValidateConfig() error
+}
+
+// FieldError describes a single invalid configuration value.
+type FieldError struct {
+	// Path is the location of the value using the yaml field names, e.g. telemetry.traces.protocol.
+	Path    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError aggregates all errors found while validating a configuration.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+
+	return ErrInvalidConfig.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+// Is makes errors.Is(err, ErrInvalidConfig) hold for validation errors.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// Validate checks the complete configuration and returns a *ValidationError listing all invalid values.
+func (c *BaseConfig) Validate() error {
+	return ValidateStruct(c)
+}
+
+// ValidateStruct validates v, which must be a struct or a pointer to a struct, using the
+// validate struct tags (see ValidateTag) and the ConfigValidator implementations of all
+// nested values. All findings are aggregated into a single *ValidationError.
+func ValidateStruct(v any) error {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return &ValidationError{Errors: []*FieldError{{Message: "configuration is nil"}}}
+		}
+
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected a struct, got %s", ErrInvalidConfig, val.Kind())
+	}
+
+	w := &validationWalker{}
+	w.walk("", val)
+
+	if len(w.errs) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Errors: w.errs}
+}
+
+type validationWalker struct {
+	errs []*FieldError
+}
+
+func (w *validationWalker) add(path, format string, args ...any) {
+	w.errs = append(w.errs, &FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (w *validationWalker) walk(path string, val reflect.Value) {
+	switch val.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !val.IsNil() {
+			w.walk(path, val.Elem())
+		}
+	case reflect.Struct:
+		w.walkStruct(path, val)
+	case reflect.Slice, reflect.Array:
+		for i := range val.Len() {
+			w.walk(fmt.Sprintf("%s[%d]", path, i), val.Index(i))
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			w.walk(fmt.Sprintf("%s[%v]", path, iter.Key()), iter.Value())
+		}
+	default:
+	}
+}
+
+func (w *validationWalker) walkStruct(path string, val reflect.Value) {
+	typ := val.Type()
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, inline := fieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		fieldPath := joinPath(path, name)
+		if inline {
+			fieldPath = path
+		}
+
+		fieldVal := val.Field(i)
+
+		w.applyRules(fieldPath, fieldVal, field.Tag.Get(ValidateTag))
+		w.walk(fieldPath, fieldVal)
+	}
+
+	w.callValidator(path, val)
+}
+
+// callValidator runs the ConfigValidator of the value, if any.
+func (w *validationWalker) callValidator(path string, val reflect.Value) {
+	var validator ConfigValidator
+
+	switch {
+	case val.CanInterface() && val.Type().Implements(reflect.TypeFor[ConfigValidator]()):
+		validator, _ = val.Interface().(ConfigValidator)
+	case val.CanAddr() && val.Addr().Type().Implements(reflect.TypeFor[ConfigValidator]()):
+		validator, _ = val.Addr().Interface().(ConfigValidator)
+	default:
+		// Values which are not addressable can still implement the interface on the pointer type.
+		if reflect.PointerTo(val.Type()).Implements(reflect.TypeFor[ConfigValidator]()) {
+			ptr := reflect.New(val.Type())
+			ptr.Elem().Set(val)
+			validator, _ = ptr.Interface().(ConfigValidator)
+		}
+	}
+
+	if validator == nil {
+		return
+	}
+
+	err := validator.ValidateConfig()
+	if err == nil {
+		return
+	}
+
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		for _, fe := range verr.Errors {
+			w.errs = append(w.errs, &FieldError{Path: joinPath(path, fe.Path), Message: fe.Message})
+		}
+
+		return
+	}
+
+	w.add(path, "%s", err.Error())
+}
+
+func (w *validationWalker) applyRules(path string, val reflect.Value, tag string) {
+	if tag == "" {
+		return
+	}
+
+	for rule := range strings.SplitSeq(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if name == "required" {
+			if isEmptyValue(val) {
+				w.add(path, "is required")
+			}
+
+			continue
+		}
+
+		if isEmptyValue(val) {
+			continue
+		}
+
+		err := checkRule(name, arg, val)
+		if err != nil {
+			w.add(path, "%s", err.Error())
+		}
+	}
+}
+
+func checkRule(name, arg string, val reflect.Value) error {
+	switch name {
+	case "oneof":
+		allowed := strings.Fields(arg)
+
+		s := fmt.Sprint(val.Interface())
+		if !slices.Contains(allowed, s) {
+			return fmt.Errorf("%q must be one of [%s]", s, strings.Join(allowed, ", "))
+		}
+	case "hostport":
+		return checkHostPort(val.String())
+	case "url":
+		u, err := url.Parse(val.String())
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%q is not an absolute URL", val.String())
+		}
+	case "min", "max":
+		return checkBound(name, arg, val)
+	default:
+		return fmt.Errorf("unknown validation rule %q", name)
+	}
+
+	return nil
+}
+
+func checkHostPort(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid host:port address: %w", addr, err)
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 0 || p > 65535 {
+		return fmt.Errorf("%q has an invalid port", addr)
+	}
+
+	return nil
+}
+
+func checkBound(name, arg string, val reflect.Value) error {
+	var (
+		actual, bound float64
+		err           error
+		describe      = func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	)
+
+	switch {
+	case val.Type() == reflect.TypeFor[time.Duration]():
+		var d time.Duration
+
+		d, err = time.ParseDuration(arg)
+		actual, bound = float64(val.Int()), float64(d)
+		describe = func(f float64) string { return time.Duration(f).String() }
+	case val.CanInt():
+		actual = float64(val.Int())
+		bound, err = strconv.ParseFloat(arg, 64)
+	case val.CanUint():
+		actual = float64(val.Uint())
+		bound, err = strconv.ParseFloat(arg, 64)
+	case val.CanFloat():
+		actual = val.Float()
+		bound, err = strconv.ParseFloat(arg, 64)
+	case val.Kind() == reflect.String || val.Kind() == reflect.Slice || val.Kind() == reflect.Map:
+		actual = float64(val.Len())
+		bound, err = strconv.ParseFloat(arg, 64)
+
+		if err == nil {
+			if name == "min" && actual < bound {
+				return fmt.Errorf("length must be at least %s", describe(bound))
+			}
+
+			if name == "max" && actual > bound {
+				return fmt.Errorf("length must be at most %s", describe(bound))
+			}
+		}
+
+		return err
+	default:
+		return fmt.Errorf("rule %s is not supported for %s", name, val.Kind())
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s rule %q: %w", name, arg, err)
+	}
+
+	if name == "min" && actual < bound {
+		return fmt.Errorf("%s must be at least %s", describe(actual), describe(bound))
+	}
+
+	if name == "max" && actual > bound {
+		return fmt.Errorf("%s must be at most %s", describe(actual), describe(bound))
+	}
+
+	return nil
+}
+
+func isEmptyValue(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.Slice, reflect.Map:
+		return val.Len() == 0
+	case reflect.Invalid:
+		return true
+	default:
+		return val.IsZero()
+	}
+}
+
+// fieldName returns the yaml name of the field, falling back to the json name,
+// and whether it is inlined into its parent.
+func fieldName(field reflect.StructField) (string, bool) {
+	tag, ok := field.Tag.Lookup("yaml")
+	if !ok {
+		tag = field.Tag.Get("json")
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+
+	if strings.Contains(opts, "inline") || (field.Anonymous && name == "") {
+		return "", true
+	}
+
+	if name == "" {
+		name = strings.ToLower(field.Name[:1]) + field.Name[1:]
+	}
+
+	return name, false
+}
+
+func joinPath(parent, name string) string {
+	switch {
+	case parent == "":
+		return name
+	case name == "":
+		return parent
+	case strings.HasPrefix(name, "["):
+		return parent + name
+	}
+
+	return parent + "." + name
+}
@@ -0,0 +1,378 @@
+package commoncfg_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/creasty/defaults"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func validBaseConfig(t *testing.T) *commoncfg.BaseConfig {
+	t.Helper()
+
+	cfg := &commoncfg.BaseConfig{}
+	require.NoError(t, defaults.Set(cfg))
+
+	return cfg
+}
+
+func fieldErrors(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	var verr *commoncfg.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.ErrorIs(t, err, commoncfg.ErrInvalidConfig)
+
+	result := make(map[string]string, len(verr.Errors))
+	for _, fe := range verr.Errors {
+		result[fe.Path] = fe.Message
+	}
+
+	return result
+}
+
+func TestBaseConfigValidateDefaults(t *testing.T) {
+	require.NoError(t, validBaseConfig(t).Validate())
+}
+
+func TestBaseConfigValidateAggregatesErrors(t *testing.T) {
+	// Arrange
+	cfg := validBaseConfig(t)
+	cfg.Status.Address = "8888"
+	cfg.Status.Timeout = -time.Second
+	cfg.Logger.Format = "xml"
+	cfg.Telemetry.Traces.Enabled = true
+	cfg.Telemetry.Metrics.Protocol = "udp"
+	cfg.Telemetry.Logs.SecretRef = commoncfg.SecretRef{
+		Type: commoncfg.MTLSSecretType,
+		MTLS: commoncfg.MTLS{
+			Cert:    commoncfg.SourceRef{Source: commoncfg.FileSourceValue},
+			RootCAs: []commoncfg.SourceRef{{Source: "ftp"}},
+		},
+	}
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	errs := fieldErrors(t, err)
+	assert.Contains(t, errs["status.address"], "not a valid host:port")
+	assert.Equal(t, "-1s must be at least 0s", errs["status.timeout"])
+	assert.Equal(t, `"xml" must be one of [json, text]`, errs["logger.format"])
+	assert.Equal(t, "is required when enabled", errs["telemetry.traces.protocol"])
+	assert.Equal(t, `"udp" must be one of [grpc, http, stdout, file, prometheus]`, errs["telemetry.metrics.protocol"])
+	assert.Equal(t, "is required", errs["telemetry.logs.secretRef.mtls.cert.file.path"])
+	assert.Equal(t, "is required", errs["telemetry.logs.secretRef.mtls.cert"])
+	assert.Equal(t, "is required", errs["telemetry.logs.secretRef.mtls.certKey"])
+	assert.Contains(t, errs["telemetry.logs.secretRef.mtls.rootCAs[0].source"], `"ftp" must be one of`)
+	assert.Len(t, errs, 9)
+	assert.Contains(t, err.Error(), "invalid configuration: ")
+}
+
+func TestValidateTelemetryPropagators(t *testing.T) {
+	cfg := validBaseConfig(t)
+	cfg.Telemetry.Propagators = []commoncfg.Propagator{commoncfg.TraceContextPropagator, commoncfg.B3Propagator}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Telemetry.Propagators = append(cfg.Telemetry.Propagators, "xray")
+
+	errs := fieldErrors(t, cfg.Validate())
+	assert.Equal(t, `"xray" must be one of [tracecontext, baggage, b3, b3multi, jaeger]`, errs["telemetry.propagators[2]"])
+	assert.Len(t, errs, 1)
+}
+
+func TestValidateMetricViews(t *testing.T) {
+	cfg := validBaseConfig(t)
+	cfg.Telemetry.Metrics.Views = []commoncfg.MetricView{
+		{Instrument: "http.server.duration", Name: "request.duration", Buckets: []float64{0.1, 1}},
+		{Instrument: "http.*", Name: "renamed"},
+		{Instrument: "rpc.*", Drop: true, Buckets: []float64{1}},
+		{Name: "unnamed"},
+	}
+
+	errs := fieldErrors(t, cfg.Validate())
+	assert.Equal(t, "requires an instrument without wildcards", errs["telemetry.metrics.views[1].name"])
+	assert.Equal(t, "cannot be set on a dropped instrument", errs["telemetry.metrics.views[2].buckets"])
+	assert.Equal(t, "is required", errs["telemetry.metrics.views[3].instrument"])
+	assert.Len(t, errs, 3)
+}
+
+type nestedConfig struct {
+	Name     string        `yaml:"name" validate:"required,min=3,max=5"`
+	Endpoint string        `yaml:"endpoint" validate:"url"`
+	Interval time.Duration `yaml:"interval" validate:"min=1s,max=1m"`
+	Ratio    float64       `yaml:"ratio" validate:"max=1"`
+	Tags     []string      `yaml:"tags" validate:"required"`
+	Workers  uint          `yaml:"workers" validate:"max=8"`
+	Children []childConfig `yaml:"children"`
+	Extra    map[string]*childConfig
+	Ignored  string `yaml:"-" validate:"required"`
+	inner    string `validate:"required"` //nolint:unused
+}
+
+type childConfig struct {
+	Mode string `yaml:"mode" validate:"oneof=a b"`
+}
+
+func (c childConfig) ValidateConfig() error {
+	if c.Mode == "b" {
+		return errors.New("mode b is deprecated")
+	}
+
+	return nil
+}
+
+func TestValidateStructRules(t *testing.T) {
+	cfg := nestedConfig{
+		Name:     "ab",
+		Endpoint: "localhost:8080",
+		Interval: 2 * time.Minute,
+		Ratio:    1.5,
+		Workers:  9,
+		Children: []childConfig{{Mode: "a"}, {Mode: "c"}, {Mode: "b"}},
+		Extra:    map[string]*childConfig{"x": {Mode: "b"}, "y": nil},
+	}
+
+	errs := fieldErrors(t, commoncfg.ValidateStruct(&cfg))
+
+	assert.Equal(t, map[string]string{
+		"name":             "length must be at least 3",
+		"endpoint":         `"localhost:8080" is not an absolute URL`,
+		"interval":         "2m0s must be at most 1m0s",
+		"ratio":            "1.5 must be at most 1",
+		"tags":             "is required",
+		"workers":          "9 must be at most 8",
+		"children[1].mode": `"c" must be one of [a, b]`,
+		"children[2]":      "mode b is deprecated",
+		"extra[x]":         "mode b is deprecated",
+	}, errs)
+}
+
+func TestValidateStructValid(t *testing.T) {
+	cfg := nestedConfig{
+		Name:     "abcd",
+		Endpoint: "https://example.com",
+		Interval: time.Second,
+		Tags:     []string{"a"},
+	}
+
+	require.NoError(t, commoncfg.ValidateStruct(cfg))
+}
+
+func TestValidateStructInvalidInput(t *testing.T) {
+	require.ErrorIs(t, commoncfg.ValidateStruct("text"), commoncfg.ErrInvalidConfig)
+	require.ErrorIs(t, commoncfg.ValidateStruct((*commoncfg.BaseConfig)(nil)), commoncfg.ErrInvalidConfig)
+}
+
+func TestValidateSourceAndSecretRefs(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     commoncfg.SecretRef
+		wantErr []string
+	}{
+		{name: "insecure", ref: commoncfg.SecretRef{Type: commoncfg.InsecureSecretType}},
+		{
+			name:    "api token missing",
+			ref:     commoncfg.SecretRef{Type: commoncfg.ApiTokenSecretType},
+			wantErr: []string{"apiToken"},
+		},
+		{
+			name: "api token from env",
+			ref: commoncfg.SecretRef{
+				Type:     commoncfg.ApiTokenSecretType,
+				APIToken: commoncfg.SourceRef{Source: commoncfg.EnvSourceValue, Env: "TOKEN"},
+			},
+		},
+		{
+			name:    "basic missing password",
+			ref:     commoncfg.SecretRef{Type: commoncfg.BasicSecretType, Basic: commoncfg.BasicAuth{Username: commoncfg.SourceRef{Value: "user"}}},
+			wantErr: []string{"basic.password"},
+		},
+		{
+			name:    "oauth2 missing client id",
+			ref:     commoncfg.SecretRef{Type: commoncfg.OAuth2SecretType},
+			wantErr: []string{"oauth2.credentials.clientID"},
+		},
+		{
+			name: "vault source missing field",
+			ref: commoncfg.SecretRef{
+				Type:     commoncfg.ApiTokenSecretType,
+				APIToken: commoncfg.SourceRef{Source: commoncfg.VaultSourceValue, Vault: commoncfg.VaultSource{Path: "kv/app"}},
+			},
+			wantErr: []string{"apiToken.vault.field"},
+		},
+		{
+			name: "kubernetes source missing key",
+			ref: commoncfg.SecretRef{
+				Type:     commoncfg.ApiTokenSecretType,
+				APIToken: commoncfg.SourceRef{Source: commoncfg.KubernetesSourceValue, Kubernetes: commoncfg.KubernetesSource{Name: "s"}},
+			},
+			wantErr: []string{"apiToken.kubernetes.key"},
+		},
+		{
+			name: "aws source missing name",
+			ref: commoncfg.SecretRef{
+				Type:     commoncfg.ApiTokenSecretType,
+				APIToken: commoncfg.SourceRef{Source: commoncfg.AWSSourceValue},
+			},
+			wantErr: []string{"apiToken.aws.name", "apiToken"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := commoncfg.ValidateStruct(&tt.ref)
+			if len(tt.wantErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			errs := fieldErrors(t, err)
+			for _, path := range tt.wantErr {
+				assert.Contains(t, errs, path)
+			}
+
+			assert.Len(t, errs, len(tt.wantErr))
+		})
+	}
+}
+
+func TestValidateGRPCPool(t *testing.T) {
+	err := commoncfg.ValidateStruct(commoncfg.GRPCClient{Pool: commoncfg.GRPCPool{InitialCapacity: 3, MaxCapacity: 2}})
+	assert.Equal(t, map[string]string{"pool": "initialCapacity must not exceed maxCapacity"}, fieldErrors(t, err))
+}
+
+func TestValidateGRPCClientTargets(t *testing.T) {
+	err := commoncfg.ValidateStruct(commoncfg.GRPCClient{Targets: []commoncfg.GRPCTarget{
+		{Name: "stable", Address: "stable:443", Weight: 90},
+		{Name: "canary", Address: "canary:443", Weight: 10},
+	}})
+	require.NoError(t, err)
+
+	err = commoncfg.ValidateStruct(commoncfg.GRPCClient{Targets: []commoncfg.GRPCTarget{
+		{Name: "stable", Address: "stable:443", Weight: 101},
+		{Name: "stable"},
+	}})
+	assert.Equal(t, map[string]string{
+		"targets[0].weight":  "101 must be at most 100",
+		"targets[1].address": "is required",
+		"":                   `duplicate target name "stable"`,
+	}, fieldErrors(t, err))
+
+	err = commoncfg.ValidateStruct(commoncfg.GRPCClient{Targets: []commoncfg.GRPCTarget{{Name: "stable", Address: "stable:443"}}})
+	assert.Equal(t, map[string]string{"": "at least one target must have a weight"}, fieldErrors(t, err))
+}
+
+func TestValidateGRPCServerPreset(t *testing.T) {
+	tlsCfg := &commoncfg.MTLS{
+		Cert:    commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "cert"},
+		CertKey: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "key"},
+	}
+
+	require.NoError(t, commoncfg.ValidateStruct(commoncfg.GRPCServer{Preset: commoncfg.SidecarGRPCServerPreset}))
+	require.NoError(t, commoncfg.ValidateStruct(commoncfg.GRPCServer{Preset: commoncfg.EdgeGRPCServerPreset, TLS: tlsCfg}))
+
+	err := commoncfg.ValidateStruct(commoncfg.GRPCServer{Preset: "public"})
+	assert.Equal(t, map[string]string{"preset": `"public" must be one of [edge, internal, sidecar]`}, fieldErrors(t, err))
+
+	err = commoncfg.ValidateStruct(commoncfg.GRPCServer{Preset: commoncfg.EdgeGRPCServerPreset})
+	assert.Equal(t, map[string]string{"tls": "is required by the edge preset"}, fieldErrors(t, err))
+
+	err = commoncfg.ValidateStruct(commoncfg.GRPCServer{Preset: commoncfg.InternalGRPCServerPreset, TLS: &commoncfg.MTLS{}})
+	assert.Equal(t, map[string]string{
+		"tls.cert":     "is required",
+		"tls.certKey":  "is required",
+		"tls.serverCa": "is required",
+	}, fieldErrors(t, err))
+}
+
+func TestLoaderWithValidation(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("status:\n  address: invalid\n"), 0o600))
+
+	cfg := &commoncfg.BaseConfig{}
+	err := commoncfg.NewLoader(cfg, commoncfg.WithPaths(dir)).LoadConfig()
+	require.NoError(t, err)
+
+	cfg = &commoncfg.BaseConfig{}
+	err = commoncfg.NewLoader(cfg, commoncfg.WithPaths(dir), commoncfg.WithValidation()).LoadConfig()
+	require.ErrorIs(t, err, commoncfg.ErrInvalidConfig)
+	assert.Contains(t, err.Error(), "status.address")
+}
+
+func TestValidateTLSAttributes(t *testing.T) {
+	cfg := validBaseConfig(t)
+	cfg.Telemetry.Traces.SecretRef = commoncfg.SecretRef{
+		Type: commoncfg.MTLSSecretType,
+		MTLS: commoncfg.MTLS{
+			Cert:    commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "cert"},
+			CertKey: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "key"},
+			Attributes: &commoncfg.TLSAttributes{
+				MinVersion:   commoncfg.TLS13Version,
+				MaxVersion:   commoncfg.TLS12Version,
+				CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", "TLS_RSA_WITH_RC4_128_SHA"},
+			},
+		},
+	}
+
+	errs := fieldErrors(t, cfg.Validate())
+	assert.Equal(t, "must not be lower than minVersion", errs["telemetry.traces.secretRef.mtls.attributes.maxVersion"])
+	assert.Equal(t, `"TLS_RSA_WITH_RC4_128_SHA" is not a secure cipher suite`,
+		errs["telemetry.traces.secretRef.mtls.attributes.cipherSuites[1]"])
+	assert.Len(t, errs, 2)
+}
+
+func TestValidateAuditSender(t *testing.T) {
+	cfg := validBaseConfig(t)
+	cfg.Audit.Sender.Transport = commoncfg.AuditNATSTransport
+	cfg.Audit.Sender.NATS.URL = "nats://localhost:4222"
+
+	errs := fieldErrors(t, cfg.Validate())
+	assert.Equal(t, "is required by the nats transport", errs["audit.sender.nats.subject"])
+	assert.Len(t, errs, 1)
+
+	cfg.Audit.Sender.NATS.Subject = "audit.events"
+	require.NoError(t, cfg.Validate())
+
+	cfg.Audit.Sender.Transport = commoncfg.AuditKafkaTransport
+	cfg.Audit.Sender.Kafka.SecretRef = &commoncfg.SecretRef{
+		Type:     commoncfg.ApiTokenSecretType,
+		APIToken: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "token"},
+	}
+
+	errs = fieldErrors(t, cfg.Validate())
+	assert.Equal(t, "is required by the kafka transport", errs["audit.sender.kafka.brokers"])
+	assert.Equal(t, "is required by the kafka transport", errs["audit.sender.kafka.topic"])
+	assert.Equal(t, "must be one of [insecure, mtls, basic] for the kafka transport", errs["audit.sender.kafka.secretRef.type"])
+	assert.Len(t, errs, 3)
+
+	cfg.Audit.Sender.Kafka = commoncfg.AuditKafka{
+		Brokers:       []string{"kafka-0:9092"},
+		Topic:         "audit.events",
+		SASLMechanism: commoncfg.AuditKafkaSASLSCRAMSHA512,
+		SecretRef: &commoncfg.SecretRef{
+			Type: commoncfg.BasicSecretType,
+			Basic: commoncfg.BasicAuth{
+				Username: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "audit"},
+				Password: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "secret"},
+			},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+}
+
+func TestValidateAuditFilter(t *testing.T) {
+	cfg := validBaseConfig(t)
+	cfg.Audit.Sender.Filter.SampleRates = map[string]int{"keyRotate": 10, "groupRead": 150}
+
+	errs := fieldErrors(t, cfg.Validate())
+	assert.Equal(t, "150 must be between 0 and 100", errs["audit.sender.filter.sampleRates.groupRead"])
+	assert.Len(t, errs, 1)
+}
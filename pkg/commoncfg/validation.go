@@ -0,0 +1,320 @@
+package commoncfg
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ValidateConfig checks that the propagators are supported.
+func (t Telemetry) ValidateConfig() error {
+	supported := []string{
+		string(TraceContextPropagator), string(BaggagePropagator),
+		string(B3Propagator), string(B3MultiPropagator), string(JaegerPropagator),
+	}
+
+	var errs []*FieldError
+
+	for i, propagator := range t.Propagators {
+		if !slices.Contains(supported, string(propagator)) {
+			errs = append(errs, &FieldError{
+				Path:    fmt.Sprintf("propagators[%d]", i),
+				Message: fmt.Sprintf("%q must be one of [%s]", propagator, strings.Join(supported, ", ")),
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// ValidateConfig checks that the changes of the view can be combined.
+func (v MetricView) ValidateConfig() error {
+	if v.Name != "" && strings.ContainsAny(v.Instrument, "*?") {
+		return &ValidationError{Errors: []*FieldError{{Path: "name", Message: "requires an instrument without wildcards"}}}
+	}
+
+	if v.Drop && len(v.Buckets) > 0 {
+		return &ValidationError{Errors: []*FieldError{{Path: "buckets", Message: "cannot be set on a dropped instrument"}}}
+	}
+
+	return nil
+}
+
+// ValidateConfig checks that the TLS versions form a range and the cipher suites are known.
+func (a TLSAttributes) ValidateConfig() error {
+	var errs []*FieldError
+
+	if a.MinVersion == TLS13Version && a.MaxVersion == TLS12Version {
+		errs = append(errs, &FieldError{Path: "maxVersion", Message: "must not be lower than minVersion"})
+	}
+
+	for i, name := range a.CipherSuites {
+		_, err := CipherSuiteIDs([]string{name})
+		if err != nil {
+			errs = append(errs, &FieldError{
+				Path:    fmt.Sprintf("cipherSuites[%d]", i),
+				Message: fmt.Sprintf("%q is not a secure cipher suite", name),
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// ValidateConfig requires the protocol once trace export is enabled.
+func (t Trace) ValidateConfig() error {
+	return validateExporter(t.Enabled, t.Protocol)
+}
+
+// ValidateConfig requires the protocol once log export is enabled.
+func (l Log) ValidateConfig() error {
+	return validateExporter(l.Enabled, l.Protocol)
+}
+
+// ValidateConfig requires the protocol once metric export is enabled.
+func (m Metric) ValidateConfig() error {
+	return validateExporter(m.Enabled, m.Protocol)
+}
+
+func validateExporter(enabled bool, protocol Protocol) error {
+	if enabled && protocol == "" {
+		return &ValidationError{Errors: []*FieldError{{Path: "protocol", Message: "is required when enabled"}}}
+	}
+
+	return nil
+}
+
+// ValidateConfig checks that the credentials required by the secret type are configured.
+func (s SecretRef) ValidateConfig() error {
+	var missing []string
+
+	switch s.Type {
+	case MTLSSecretType:
+		if s.MTLS.usesPKCS12() {
+			break
+		}
+
+		missing = appendIfUnset(missing, "mtls.cert", &s.MTLS.Cert)
+		missing = appendIfUnset(missing, "mtls.certKey", &s.MTLS.CertKey)
+	case ApiTokenSecretType:
+		missing = appendIfUnset(missing, "apiToken", &s.APIToken)
+	case BasicSecretType:
+		missing = appendIfUnset(missing, "basic.username", &s.Basic.Username)
+		missing = appendIfUnset(missing, "basic.password", &s.Basic.Password)
+	case OAuth2SecretType:
+		missing = appendIfUnset(missing, "oauth2.credentials.clientID", &s.OAuth2.Credentials.ClientID)
+	case InsecureSecretType, "":
+	}
+
+	return requiredFieldsError(missing)
+}
+
+// ValidateConfig checks that the fields required by the source are set.
+func (s SourceRef) ValidateConfig() error {
+	var missing []string
+
+	switch s.Source {
+	case EnvSourceValue:
+		if s.Env == "" && s.Value == "" {
+			missing = append(missing, "env")
+		}
+	case FileSourceValue:
+		if s.File.Path == "" {
+			missing = append(missing, "file.path")
+		}
+	case VaultSourceValue:
+		if s.Vault.Path == "" {
+			missing = append(missing, "vault.path")
+		}
+
+		if s.Vault.Field == "" {
+			missing = append(missing, "vault.field")
+		}
+	case AWSSourceValue:
+		if s.AWS.Name == "" {
+			missing = append(missing, "aws.name")
+		}
+	case KubernetesSourceValue:
+		if s.Kubernetes.Name == "" {
+			missing = append(missing, "kubernetes.name")
+		}
+
+		if s.Kubernetes.Key == "" {
+			missing = append(missing, "kubernetes.key")
+		}
+	case ExecSourceValue:
+		if s.Exec.Command == "" {
+			missing = append(missing, "exec.command")
+		}
+	case EmbeddedSourceValue, "":
+	}
+
+	return requiredFieldsError(missing)
+}
+
+// ValidateConfig checks that the initial capacity does not exceed the maximum capacity.
+func (p GRPCPool) ValidateConfig() error {
+	if p.MaxCapacity > 0 && p.InitialCapacity > p.MaxCapacity {
+		return errors.New("initialCapacity must not exceed maxCapacity")
+	}
+
+	return nil
+}
+
+// ValidateConfig requires unique target names and at least one weighted target.
+func (c GRPCClient) ValidateConfig() error {
+	names := make(map[string]struct{}, len(c.Targets))
+	total := 0
+
+	for _, target := range c.Targets {
+		if _, ok := names[target.Name]; ok && target.Name != "" {
+			return fmt.Errorf("duplicate target name %q", target.Name)
+		}
+
+		names[target.Name] = struct{}{}
+		total += target.Weight
+	}
+
+	if len(c.Targets) > 0 && total == 0 {
+		return errors.New("at least one target must have a weight")
+	}
+
+	return nil
+}
+
+// ValidateConfig requires the TLS configuration of the presets serving over TLS, and the
+// CA certificates of the clients for the mutual TLS of the internal preset.
+func (s GRPCServer) ValidateConfig() error {
+	switch s.Preset {
+	case EdgeGRPCServerPreset, InternalGRPCServerPreset:
+		if s.TLS == nil {
+			return &ValidationError{Errors: []*FieldError{{Path: "tls", Message: "is required by the " + string(s.Preset) + " preset"}}}
+		}
+	case SidecarGRPCServerPreset, "":
+	}
+
+	if s.TLS == nil {
+		return nil
+	}
+
+	var missing []string
+
+	if !s.TLS.usesPKCS12() {
+		missing = appendIfUnset(missing, "tls.cert", &s.TLS.Cert)
+		missing = appendIfUnset(missing, "tls.certKey", &s.TLS.CertKey)
+	}
+
+	if s.Preset == InternalGRPCServerPreset && s.TLS.ServerCA == nil && len(s.TLS.RootCAs) == 0 {
+		missing = append(missing, "tls.serverCa")
+	}
+
+	return requiredFieldsError(missing)
+}
+
+// IsSet reports whether the reference points to a value, without resolving it.
+func (s *SourceRef) IsSet() bool {
+	switch s.Source {
+	case EmbeddedSourceValue, "":
+		return s.Value != ""
+	case EnvSourceValue:
+		return s.Env != "" || s.Value != ""
+	case FileSourceValue:
+		return s.File.Path != ""
+	case VaultSourceValue:
+		return s.Vault.Path != ""
+	case AWSSourceValue:
+		return s.AWS.Name != ""
+	case KubernetesSourceValue:
+		return s.Kubernetes.Name != ""
+	case ExecSourceValue:
+		return s.Exec.Command != ""
+	}
+
+	return false
+}
+
+func appendIfUnset(missing []string, path string, ref *SourceRef) []string {
+	if ref.IsSet() {
+		return missing
+	}
+
+	return append(missing, path)
+}
+
+func requiredFieldsError(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	errs := make([]*FieldError, 0, len(paths))
+	for _, p := range paths {
+		errs = append(errs, &FieldError{Path: p, Message: "is required"})
+	}
+
+	return &ValidationError{Errors: errs}
+}
+
+// ValidateConfig checks that the NATS server and subject are set for the nats transport,
+// and the Kafka brokers and topic for the kafka transport.
+func (s AuditSender) ValidateConfig() error {
+	var errs []*FieldError
+
+	switch s.Transport {
+	case AuditNATSTransport:
+		if s.NATS.URL == "" {
+			errs = append(errs, &FieldError{Path: "nats.url", Message: "is required by the nats transport"})
+		}
+
+		if s.NATS.Subject == "" {
+			errs = append(errs, &FieldError{Path: "nats.subject", Message: "is required by the nats transport"})
+		}
+	case AuditKafkaTransport:
+		if len(s.Kafka.Brokers) == 0 {
+			errs = append(errs, &FieldError{Path: "kafka.brokers", Message: "is required by the kafka transport"})
+		}
+
+		if s.Kafka.Topic == "" {
+			errs = append(errs, &FieldError{Path: "kafka.topic", Message: "is required by the kafka transport"})
+		}
+
+		if ref := s.Kafka.SecretRef; ref != nil && ref.Type != InsecureSecretType && ref.Type != MTLSSecretType && ref.Type != BasicSecretType {
+			errs = append(errs, &FieldError{Path: "kafka.secretRef.type", Message: "must be one of [insecure, mtls, basic] for the kafka transport"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// ValidateConfig checks that the sample rates are percentages.
+func (f AuditFilter) ValidateConfig() error {
+	var errs []*FieldError
+
+	for _, eventType := range slices.Sorted(maps.Keys(f.SampleRates)) {
+		if rate := f.SampleRates[eventType]; rate < 0 || rate > 100 {
+			errs = append(errs, &FieldError{
+				Path:    "sampleRates." + eventType,
+				Message: fmt.Sprintf("%d must be between 0 and 100", rate),
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
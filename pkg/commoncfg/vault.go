@@ -0,0 +1,307 @@
+package commoncfg
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultAuthMethod defines how the client authenticates against HashiCorp Vault.
+type VaultAuthMethod string
+
+const (
+	VaultTokenAuth      VaultAuthMethod = "token"
+	VaultKubernetesAuth VaultAuthMethod = "kubernetes"
+	VaultAppRoleAuth    VaultAuthMethod = "approle"
+
+	DefaultVaultTimeout                 = 10 * time.Second
+	DefaultVaultServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token" //nolint:gosec
+
+	vaultAddressEnv      = "VAULT_ADDR"
+	vaultTokenEnv        = "VAULT_TOKEN"
+	vaultTokenHeader     = "X-Vault-Token"
+	vaultNamespaceHeader = "X-Vault-Namespace"
+)
+
+var (
+	ErrVaultAddressMissing  = errors.New("vault address is missing")
+	ErrVaultPathMissing     = errors.New("vault path is missing")
+	ErrVaultFieldMissing    = errors.New("vault field is missing")
+	ErrVaultFieldNotFound   = errors.New("vault field not found in secret")
+	ErrVaultAuthUnsupported = errors.New("unsupported vault auth method")
+)
+
+// VaultSource describes a secret stored in HashiCorp Vault.
+type VaultSource struct {
+	// Address of the Vault server, e.g. https://vault:8200. Falls back to the VAULT_ADDR environment variable.
+	Address string `yaml:"address" json:"address" mapstructure:"address" validate:"url"`
+	// Namespace is the optional Vault Enterprise namespace.
+	Namespace string `yaml:"namespace" json:"namespace" mapstructure:"namespace"`
+	// Path is the API path of the secret without the /v1 prefix, e.g. secret/data/my-service for KV v2.
+	Path string `yaml:"path" json:"path" mapstructure:"path"`
+	// Field is the key inside the secret data holding the value.
+	Field string `yaml:"field" json:"field" mapstructure:"field"`
+	// Auth configures how to obtain a Vault token.
+	Auth VaultAuth `yaml:"auth" json:"auth" mapstructure:"auth"`
+	// ServerCA optionally references the CA bundle used to verify the Vault server certificate.
+	ServerCA *SourceRef `yaml:"serverCa,omitempty" json:"serverCa,omitempty" mapstructure:"serverCa"`
+	// Timeout bounds the login and read requests.
+	Timeout time.Duration `yaml:"timeout" json:"timeout" mapstructure:"timeout"`
+}
+
+// VaultAuth holds the Vault authentication configuration.
+type VaultAuth struct {
+	Method VaultAuthMethod `yaml:"method" json:"method" default:"token" mapstructure:"method" validate:"oneof=token kubernetes approle"`
+	// Mount is the path the auth method is mounted on; defaults to the method name.
+	Mount string `yaml:"mount" json:"mount" mapstructure:"mount"`
+
+	// Token is used by the token method. Falls back to the VAULT_TOKEN environment variable.
+	Token *SourceRef `yaml:"token,omitempty" json:"token,omitempty" mapstructure:"token"`
+
+	// Role is the Vault role used by the kubernetes method.
+	Role string `yaml:"role" json:"role" mapstructure:"role"`
+	// ServiceAccountTokenPath is the projected service account token used by the kubernetes method.
+	ServiceAccountTokenPath string `yaml:"serviceAccountTokenPath" json:"serviceAccountTokenPath" mapstructure:"serviceAccountTokenPath"`
+
+	// RoleID and SecretID are used by the approle method.
+	RoleID   *SourceRef `yaml:"roleId,omitempty" json:"roleId,omitempty" mapstructure:"roleId"`
+	SecretID *SourceRef `yaml:"secretId,omitempty" json:"secretId,omitempty" mapstructure:"secretId"`
+}
+
+type vaultResponse struct {
+	Data map[string]any `json:"data"`
+	Auth *struct {
+		ClientToken string `json:"client_token"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// loadFromVault reads the configured field of a Vault secret.
+// Both KV version 1 and version 2 responses are supported.
+func loadFromVault(src *VaultSource) ([]byte, error) {
+	address := strings.TrimRight(strings.TrimSpace(src.Address), "/")
+	if address == "" {
+		address = strings.TrimRight(os.Getenv(vaultAddressEnv), "/")
+	}
+
+	if address == "" {
+		return nil, ErrVaultAddressMissing
+	}
+
+	if strings.TrimSpace(src.Path) == "" {
+		return nil, ErrVaultPathMissing
+	}
+
+	if strings.TrimSpace(src.Field) == "" {
+		return nil, ErrVaultFieldMissing
+	}
+
+	client, err := newVaultHTTPClient(src)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := src.Timeout
+	if timeout <= 0 {
+		timeout = DefaultVaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	token, err := vaultLogin(ctx, client, address, src)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := vaultDo(ctx, client, http.MethodGet, address+"/v1/"+strings.TrimLeft(src.Path, "/"), token, src.Namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := resp.Data
+
+	// KV version 2 nests the secret data together with its metadata.
+	nested, isMap := data["data"].(map[string]any)
+	if _, hasMetadata := data["metadata"]; isMap && hasMetadata {
+		data = nested
+	}
+
+	value, ok := data[src.Field]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrVaultFieldNotFound, src.Field)
+	}
+
+	if s, ok := value.(string); ok {
+		return []byte(s), nil
+	}
+
+	return json.Marshal(value)
+}
+
+func newVaultHTTPClient(src *VaultSource) (*http.Client, error) {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return &http.Client{}, nil
+	}
+
+	transport = transport.Clone()
+
+	if src.ServerCA != nil {
+		pool, err := LoadCACertPool(src.ServerCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load vault server CA: %w", err)
+		}
+
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// vaultLogin returns a Vault token according to the configured auth method.
+func vaultLogin(ctx context.Context, client *http.Client, address string, src *VaultSource) (string, error) {
+	auth := src.Auth
+
+	method := auth.Method
+	if method == "" {
+		method = VaultTokenAuth
+	}
+
+	mount := strings.Trim(auth.Mount, "/")
+	if mount == "" {
+		mount = string(method)
+	}
+
+	var body map[string]string
+
+	switch method {
+	case VaultTokenAuth:
+		if auth.Token == nil {
+			token := os.Getenv(vaultTokenEnv)
+			if token == "" {
+				return "", errors.New("vault token is not configured")
+			}
+
+			return token, nil
+		}
+
+		token, err := ExtractValueFromSourceRef(auth.Token)
+		if err != nil {
+			return "", fmt.Errorf("failed to load vault token: %w", err)
+		}
+
+		return strings.TrimSpace(string(token)), nil
+	case VaultKubernetesAuth:
+		path := auth.ServiceAccountTokenPath
+		if path == "" {
+			path = DefaultVaultServiceAccountTokenPath
+		}
+
+		jwt, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read service account token: %w", err)
+		}
+
+		body = map[string]string{"role": auth.Role, "jwt": strings.TrimSpace(string(jwt))}
+	case VaultAppRoleAuth:
+		roleID, err := ExtractValueFromSourceRef(auth.RoleID)
+		if err != nil {
+			return "", fmt.Errorf("failed to load vault role id: %w", err)
+		}
+
+		secretID, err := ExtractValueFromSourceRef(auth.SecretID)
+		if err != nil {
+			return "", fmt.Errorf("failed to load vault secret id: %w", err)
+		}
+
+		body = map[string]string{"role_id": string(roleID), "secret_id": string(secretID)}
+	default:
+		return "", fmt.Errorf("%w: %s", ErrVaultAuthUnsupported, method)
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := vaultDo(ctx, client, http.MethodPost, address+"/v1/auth/"+mount+"/login", "", src.Namespace, payload)
+	if err != nil {
+		return "", fmt.Errorf("vault %s login failed: %w", method, err)
+	}
+
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault %s login returned no token", method)
+	}
+
+	return resp.Auth.ClientToken, nil
+}
+
+func vaultDo(ctx context.Context, client *http.Client, method, u, token, namespace string, payload []byte) (*vaultResponse, error) {
+	_, err := url.ParseRequestURI(u)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if token != "" {
+		req.Header.Set(vaultTokenHeader, token)
+	}
+
+	if namespace != "" {
+		req.Header.Set(vaultNamespaceHeader, namespace)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result vaultResponse
+
+	if resp.StatusCode != http.StatusOK {
+		// error pages of proxies are not JSON, so the errors of Vault are decoded on a best-effort basis
+		if json.Unmarshal(body, &result) != nil || len(result.Errors) == 0 {
+			return nil, fmt.Errorf("vault responded with status %d", resp.StatusCode)
+		}
+
+		return nil, fmt.Errorf("vault responded with status %d: %s", resp.StatusCode, strings.Join(result.Errors, "; "))
+	}
+
+	if len(body) > 0 {
+		err = json.Unmarshal(body, &result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode vault response: %w", err)
+		}
+	}
+
+	return &result, nil
+}
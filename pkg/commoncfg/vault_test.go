@@ -0,0 +1,252 @@
+package commoncfg_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+const vaultTestToken = "s.test-token"
+
+func newVaultServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("X-Vault-Token") != vaultTestToken {
+			w.WriteHeader(http.StatusForbidden)
+			writeJSON(w, map[string]any{"errors": []string{"permission denied"}})
+
+			return false
+		}
+
+		return true
+	}
+
+	mux.HandleFunc("GET /v1/secret/data/app", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+
+		writeJSON(w, map[string]any{
+			"data": map[string]any{
+				"data":     map[string]any{"password": "kv2-secret", "port": 8443},
+				"metadata": map[string]any{"version": 3},
+			},
+		})
+	})
+	mux.HandleFunc("GET /v1/kv/app", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+
+		writeJSON(w, map[string]any{"data": map[string]any{"password": "kv1-secret"}})
+	})
+	mux.HandleFunc("POST /v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "role" || body["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]any{"errors": []string{"invalid role or secret ID"}})
+
+			return
+		}
+
+		writeJSON(w, map[string]any{"auth": map[string]any{"client_token": vaultTestToken}})
+	})
+	mux.HandleFunc("POST /v1/auth/k8s/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role"] != "my-role" || body["jwt"] != "sa-jwt" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		writeJSON(w, map[string]any{"auth": map[string]any{"client_token": vaultTestToken}})
+	})
+
+	mux.HandleFunc("GET /v1/kv/unavailable", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("<html><body>502 Bad Gateway</body></html>"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestExtractValueFromSourceRef_Vault(t *testing.T) {
+	server := newVaultServer(t)
+
+	saTokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(saTokenPath, []byte("sa-jwt\n"), 0o600))
+
+	tokenRef := &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: vaultTestToken}
+
+	tests := []struct {
+		name    string
+		vault   commoncfg.VaultSource
+		want    string
+		wantErr error
+	}{
+		{
+			name: "kv v2 with token auth",
+			vault: commoncfg.VaultSource{
+				Address: server.URL,
+				Path:    "secret/data/app",
+				Field:   "password",
+				Auth:    commoncfg.VaultAuth{Method: commoncfg.VaultTokenAuth, Token: tokenRef},
+			},
+			want: "kv2-secret",
+		},
+		{
+			name: "kv v2 non string value",
+			vault: commoncfg.VaultSource{
+				Address: server.URL,
+				Path:    "/secret/data/app",
+				Field:   "port",
+				Auth:    commoncfg.VaultAuth{Token: tokenRef},
+			},
+			want: "8443",
+		},
+		{
+			name: "kv v1 with approle auth",
+			vault: commoncfg.VaultSource{
+				Address: server.URL,
+				Path:    "kv/app",
+				Field:   "password",
+				Auth: commoncfg.VaultAuth{
+					Method:   commoncfg.VaultAppRoleAuth,
+					RoleID:   &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "role"},
+					SecretID: &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "secret"},
+				},
+			},
+			want: "kv1-secret",
+		},
+		{
+			name: "kubernetes auth with custom mount",
+			vault: commoncfg.VaultSource{
+				Address: server.URL,
+				Path:    "kv/app",
+				Field:   "password",
+				Auth: commoncfg.VaultAuth{
+					Method:                  commoncfg.VaultKubernetesAuth,
+					Mount:                   "k8s",
+					Role:                    "my-role",
+					ServiceAccountTokenPath: saTokenPath,
+				},
+			},
+			want: "kv1-secret",
+		},
+		{
+			name: "missing field",
+			vault: commoncfg.VaultSource{
+				Address: server.URL,
+				Path:    "kv/app",
+				Field:   "username",
+				Auth:    commoncfg.VaultAuth{Token: tokenRef},
+			},
+			wantErr: commoncfg.ErrVaultFieldNotFound,
+		},
+		{
+			name:    "missing address",
+			vault:   commoncfg.VaultSource{Path: "kv/app", Field: "password"},
+			wantErr: commoncfg.ErrVaultAddressMissing,
+		},
+		{
+			name:    "missing path",
+			vault:   commoncfg.VaultSource{Address: server.URL, Field: "password"},
+			wantErr: commoncfg.ErrVaultPathMissing,
+		},
+		{
+			name:    "missing field name",
+			vault:   commoncfg.VaultSource{Address: server.URL, Path: "kv/app"},
+			wantErr: commoncfg.ErrVaultFieldMissing,
+		},
+		{
+			name: "unsupported auth method",
+			vault: commoncfg.VaultSource{
+				Address: server.URL,
+				Path:    "kv/app",
+				Field:   "password",
+				Auth:    commoncfg.VaultAuth{Method: "ldap"},
+			},
+			wantErr: commoncfg.ErrVaultAuthUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VAULT_ADDR", "")
+
+			value, err := commoncfg.ExtractValueFromSourceRef(&commoncfg.SourceRef{
+				Source: commoncfg.VaultSourceValue,
+				Vault:  tt.vault,
+			})
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(value))
+		})
+	}
+}
+
+func TestExtractValueFromSourceRef_VaultEnvFallback(t *testing.T) {
+	server := newVaultServer(t)
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", vaultTestToken)
+
+	value, err := commoncfg.ExtractValueFromSourceRef(&commoncfg.SourceRef{
+		Source: commoncfg.VaultSourceValue,
+		Vault:  commoncfg.VaultSource{Path: "secret/data/app", Field: "password"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "kv2-secret", string(value))
+
+	t.Setenv("VAULT_TOKEN", "invalid")
+
+	_, err = commoncfg.ExtractValueFromSourceRef(&commoncfg.SourceRef{
+		Source: commoncfg.VaultSourceValue,
+		Vault:  commoncfg.VaultSource{Path: "secret/data/app", Field: "password"},
+	})
+	require.ErrorContains(t, err, "permission denied")
+}
+
+func TestExtractValueFromSourceRef_VaultErrorPage(t *testing.T) {
+	server := newVaultServer(t)
+
+	_, err := commoncfg.ExtractValueFromSourceRef(&commoncfg.SourceRef{
+		Source: commoncfg.VaultSourceValue,
+		Vault: commoncfg.VaultSource{
+			Address: server.URL,
+			Path:    "kv/unavailable",
+			Field:   "password",
+			Auth:    commoncfg.VaultAuth{Token: &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: vaultTestToken}},
+		},
+	})
+	require.ErrorContains(t, err, "vault responded with status 502")
+	assert.NotContains(t, err.Error(), "decode")
+}
@@ -10,7 +10,8 @@
 //   - Location (path): a directory that the loader has been instructed to watch.
 //   - KeyID: the identifier under which a file's contents are stored in the
 //     storage backend. The KeyID is derived from the file path using a
-//     configurable strategy (see KeyIDType).
+//     configurable strategy (see KeyIDType) and optionally normalized, so it
+//     is the same on every platform (see KeyIDNormalization).
 //   - Storage: a pluggable key->[]byte storage implementing
 //     keyvalue.StringToBytesStorage. By default, an in-memory storage is used.
 //   - Watcher: a filesystem watcher (fsnotify based) that forwards events to
@@ -51,6 +52,7 @@ import (
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
+	"golang.org/x/text/unicode/norm"
 
 	"github.com/openkcm/common-sdk/pkg/commonfs/watcher"
 	"github.com/openkcm/common-sdk/pkg/storage/keyvalue"
@@ -97,6 +99,40 @@ const (
 	FileFullPath
 )
 
+// KeyIDNormalization defines how the loader normalizes Key IDs, so the same
+// files produce the same Key IDs on every platform, e.g. on case-insensitive
+// macOS dev machines and on Linux pods.
+//
+// Normalizations can be combined, e.g. LowerCase | UnicodeNFC.
+type KeyIDNormalization uint32
+
+const (
+	// LowerCase lower-cases the Key ID and matches the extension case-insensitively.
+	//
+	// Example:
+	//   Path:   /tmp/keys/Signing.PEM
+	//   KeyID:  signing
+	LowerCase KeyIDNormalization = 1 << iota
+
+	// ForwardSlashes replaces the path separators of the platform with "/".
+	//
+	// Example:
+	//   Path:   C:\keys\sub\key.pem
+	//   KeyID:  C:/keys/sub/key.pem
+	ForwardSlashes
+
+	// UnicodeNFC converts the Key ID to the Unicode normalization form C, as
+	// file names may be reported decomposed (NFD) on macOS but composed on Linux.
+	//
+	// Example:
+	//   Path:   /tmp/keys/cle\u0301.pem
+	//   KeyID:  cl\u00e9
+	UnicodeNFC
+
+	// PortableKeyIDs combines all normalizations.
+	PortableKeyIDs = LowerCase | ForwardSlashes | UnicodeNFC
+)
+
 var (
 	// ErrStorageNotSpecified is returned when a nil storage is passed to WithStorage.
 	ErrStorageNotSpecified = errors.New("storage not specified")
@@ -109,12 +145,18 @@ type Loader struct {
 	pathsToWatch   map[string]struct{}
 	extension      string
 	keyIDType      KeyIDType
+	normalization  KeyIDNormalization
 	recursiveWatch bool
 	operations     map[fsnotify.Op]struct{}
 
 	startMu sync.Mutex
 	watcher *watcher.Watcher
 	storage keyvalue.StringToBytesStorage
+
+	// sources tracks the files of every normalized Key ID, as several
+	// files may differ only by case on case-sensitive file systems.
+	sourcesMu sync.Mutex
+	sources   map[string]map[string]struct{}
 }
 
 // Option represents a configuration option for Loader.
@@ -184,6 +226,20 @@ func WithKeyIDType(value KeyIDType) Option {
 	}
 }
 
+// WithKeyIDNormalization configures the normalization applied to the Key IDs
+// after they have been extracted according to the KeyIDType.
+// By default, Key IDs are not normalized.
+//
+// If several files map to the same normalized Key ID, e.g. Key.pem and key.pem
+// on a case-sensitive file system, the file written last wins and the Key ID is
+// only removed from storage once all of these files have been removed.
+func WithKeyIDNormalization(value KeyIDNormalization) Option {
+	return func(w *Loader) error {
+		w.normalization = value
+		return nil
+	}
+}
+
 // WithStorage configures the storage backend used by Loader.
 //
 // If storage is nil, ErrStorageNotSpecified is returned.
@@ -259,6 +315,7 @@ func Create(opts ...Option) (*Loader, error) {
 
 		startMu: sync.Mutex{},
 		storage: keyvalue.NewMemoryStorage[string, []byte](),
+		sources: make(map[string]map[string]struct{}),
 	}
 
 	// Apply options
@@ -425,25 +482,94 @@ func (l *Loader) loadResource(event fsnotify.Event) {
 	}
 
 	keyID, _ = strings.CutSuffix(keyID, "~")
+	keyID = l.normalizeKeyID(keyID)
+	filePath, _ = strings.CutSuffix(filePath, "~")
+
 	if event.Op&(fsnotify.Rename|fsnotify.Remove) != 0 {
+		l.removeResource(keyID, filePath)
+		return
+	}
+
+	keyData, ok := readResource(filePath)
+	if !ok {
+		return
+	}
+
+	if l.normalization != 0 {
+		l.sourcesMu.Lock()
+		defer l.sourcesMu.Unlock()
+
+		if l.sources[keyID] == nil {
+			l.sources[keyID] = make(map[string]struct{})
+		}
+
+		l.sources[keyID][filePath] = struct{}{}
+	}
+
+	l.storage.Store(keyID, keyData)
+}
+
+// removeResource removes the Key ID from storage, unless another file
+// still maps to the same normalized Key ID, which is then loaded instead.
+func (l *Loader) removeResource(keyID, filePath string) {
+	if l.normalization == 0 {
 		l.storage.Remove(keyID)
 		return
 	}
 
-	filePath, _ = strings.CutSuffix(filePath, "~")
+	l.sourcesMu.Lock()
+	defer l.sourcesMu.Unlock()
+
+	files := l.sources[keyID]
+	delete(files, filePath)
+
+	for file := range files {
+		keyData, ok := readResource(file)
+		if !ok {
+			delete(files, file)
+			continue
+		}
+
+		l.storage.Store(keyID, keyData)
+
+		return
+	}
+
+	delete(l.sources, keyID)
+	l.storage.Remove(keyID)
+}
 
+// readResource reads a file, skipping directories, unreadable and empty files.
+func readResource(filePath string) ([]byte, bool) {
 	info, err := os.Stat(filePath)
 	if err != nil || info.IsDir() {
-		return
+		return nil, false
 	}
 
 	keyData, err := os.ReadFile(filePath)
 	if err != nil || len(keyData) == 0 {
 		// Skip unreadable files
-		return
+		return nil, false
 	}
 
-	l.storage.Store(keyID, keyData)
+	return keyData, true
+}
+
+// normalizeKeyID applies the configured KeyIDNormalization.
+func (l *Loader) normalizeKeyID(keyID string) string {
+	if l.normalization&UnicodeNFC != 0 {
+		keyID = norm.NFC.String(keyID)
+	}
+
+	if l.normalization&LowerCase != 0 {
+		keyID = strings.ToLower(keyID)
+	}
+
+	if l.normalization&ForwardSlashes != 0 {
+		keyID = filepath.ToSlash(keyID)
+	}
+
+	return keyID
 }
 
 // resolveKeyID determines the storage key based on Loader.keyIDType
@@ -457,6 +583,15 @@ func (l *Loader) resolveKeyID(filePath string) (string, bool) {
 	case FileNameWithoutExtension:
 		_, name := filepath.Split(filePath)
 
+		if l.normalization&LowerCase != 0 {
+			// case-insensitive file systems accept any case of the extension
+			if len(name) < len(l.extension) || !strings.EqualFold(name[len(name)-len(l.extension):], l.extension) {
+				return "", false
+			}
+
+			return name[:len(name)-len(l.extension)], true
+		}
+
 		keyID, found := strings.CutSuffix(name, l.extension)
 		if !found {
 			return "", false
@@ -341,3 +341,126 @@ func TestStartSigningKeysWatcherReloadOnTouch(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, []byte(PemKey1Data), val)
 }
+
+func TestLoaderKeyIDNormalization(t *testing.T) {
+	dir := t.TempDir()
+	subDir := filepath.Join(dir, "Sub")
+	require.NoError(t, os.Mkdir(subDir, 0700))
+
+	// decomposed "é" as reported by macOS
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Cle\u0301.PEM"), []byte("data1"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(subDir, "Key.pem"), []byte("data2"), 0600))
+
+	tests := []struct {
+		name          string
+		keyIDType     loader.KeyIDType
+		normalization loader.KeyIDNormalization
+		expected      map[string]string
+	}{
+		{
+			name:          "lower case matches the extension case-insensitively",
+			keyIDType:     loader.FileNameWithoutExtension,
+			normalization: loader.LowerCase,
+			expected:      map[string]string{"cle\u0301": "data1", "key": "data2"},
+		},
+		{
+			name:          "unicode NFC",
+			keyIDType:     loader.FileNameWithExtension,
+			normalization: loader.UnicodeNFC,
+			expected:      map[string]string{"Cl\u00e9.PEM": "data1", "Key.pem": "data2"},
+		},
+		{
+			name:          "portable key IDs",
+			keyIDType:     loader.FileFullPathRelativeToLocation,
+			normalization: loader.PortableKeyIDs,
+			expected:      map[string]string{"cl\u00e9.pem": "data1", "/sub/key.pem": "data2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := keyvalue.NewMemoryStorage[string, []byte]()
+			l, err := loader.Create(
+				loader.OnPath(dir),
+				loader.WithStorage(st),
+				loader.WithExtension(".pem"),
+				loader.WithKeyIDType(tt.keyIDType),
+				loader.WithKeyIDNormalization(tt.normalization),
+				loader.WatchSubfolders(true),
+			)
+			require.NoError(t, err)
+
+			startLoader(t, l)
+			defer stopLoader(t, l)
+
+			require.ElementsMatch(t, keysOf(tt.expected), st.List())
+
+			for key, data := range tt.expected {
+				val, ok := st.Get(key)
+				require.True(t, ok, "expected key %s to exist", key)
+				require.Equal(t, data, string(val))
+			}
+		})
+	}
+}
+
+func TestLoaderKeyIDNormalizationCollisions(t *testing.T) {
+	dir := t.TempDir()
+	upper := filepath.Join(dir, "Key.pem")
+	lower := filepath.Join(dir, "key.pem")
+
+	require.NoError(t, os.WriteFile(upper, []byte("upper"), 0600))
+
+	st := keyvalue.NewMemoryStorage[string, []byte]()
+	l, err := loader.Create(
+		loader.OnPath(dir),
+		loader.WithStorage(st),
+		loader.WithExtension(".pem"),
+		loader.WithKeyIDType(loader.FileNameWithoutExtension),
+		loader.WithKeyIDNormalization(loader.LowerCase),
+	)
+	require.NoError(t, err)
+
+	startLoader(t, l)
+	defer stopLoader(t, l)
+
+	err = os.WriteFile(lower, []byte("lower"), 0600)
+	require.NoError(t, err)
+
+	info, err := os.Stat(upper)
+	require.NoError(t, err)
+
+	lowerInfo, err := os.Stat(lower)
+	require.NoError(t, err)
+
+	if os.SameFile(info, lowerInfo) {
+		t.Skip("file system is case-insensitive")
+	}
+
+	require.Eventually(t, func() bool {
+		val, ok := st.Get("key")
+		return ok && string(val) == "lower"
+	}, time.Second, 50*time.Millisecond)
+
+	// the key is kept as long as one of the files exists
+	require.NoError(t, os.Remove(lower))
+	require.Eventually(t, func() bool {
+		val, ok := st.Get("key")
+		return ok && string(val) == "upper"
+	}, time.Second, 50*time.Millisecond)
+
+	require.NoError(t, os.Remove(upper))
+	require.Eventually(t, func() bool {
+		_, ok := st.Get("key")
+		return !ok
+	}, time.Second, 50*time.Millisecond)
+}
+
+func keysOf(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
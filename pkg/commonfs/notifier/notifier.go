@@ -350,12 +350,18 @@ func (n *Notifier) onEvent(event fsnotify.Event) {
 	if n.jobSendingEvents != nil {
 		n.jobSendingEvents.Reset(n.interval)
 	} else {
-		n.jobSendingEvents = time.AfterFunc(n.interval, n.sendCachedEvents)
+		n.jobSendingEvents = time.AfterFunc(n.interval, func() {
+			n.cacheMu.Lock()
+			defer n.cacheMu.Unlock()
+
+			n.sendCachedEvents()
+		})
 	}
 }
 
 // sendCachedEvents sends accumulated events to the configured callback
 // and resets the internal cache. Recovers from panics in user callbacks.
+// The caller must hold cacheMu.
 func (n *Notifier) sendCachedEvents() {
 	defer func() {
 		if err := recover(); err != nil {
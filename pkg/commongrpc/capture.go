@@ -0,0 +1,337 @@
+package commongrpc
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	DefCaptureSize       = 100
+	DefCaptureSampleRate = 0.01
+	DefCaptureRateLimit  = 1.0 // captures per second
+
+	redactedValue = "[REDACTED]"
+)
+
+// DefCaptureRedactedFields are field names whose values are always redacted in captured payloads.
+var DefCaptureRedactedFields = []string{
+	"password", "secret", "token", "access_token", "refresh_token", "id_token",
+	"client_secret", "private_key", "api_key", "authorization",
+}
+
+// CapturedCall is a sampled RPC recorded by DebugCapture.
+type CapturedCall struct {
+	Method    string          `json:"method"`
+	StartTime time.Time       `json:"startTime"`
+	Duration  time.Duration   `json:"duration"`
+	Code      string          `json:"code"`
+	Error     string          `json:"error,omitempty"`
+	Request   json.RawMessage `json:"request,omitempty"`
+	Response  json.RawMessage `json:"response,omitempty"`
+}
+
+// DebugCapture records a heavily rate-limited sample of RPCs into a ring buffer, including their
+// redacted payloads as JSON, to debug issues which cannot be reproduced elsewhere.
+// Capturing is disabled until Enable is called. The recorded calls can be retrieved
+// through Calls or served via Handler, e.g. on the status server.
+//
+// Fields annotated with the debug_redact option and fields whose name is in the redacted field
+// list are replaced before a payload is recorded; bytes fields are never recorded.
+type DebugCapture struct {
+	enabled    atomic.Bool
+	sampleRate float64
+	limiter    *rate.Limiter
+	redacted   map[string]struct{}
+
+	mu    sync.Mutex
+	calls []CapturedCall
+	next  int
+	full  bool
+}
+
+type CaptureOption func(*DebugCapture)
+
+// WithCaptureSize sets the number of calls kept in the ring buffer.
+func WithCaptureSize(size int) CaptureOption {
+	return func(c *DebugCapture) {
+		if size > 0 {
+			c.calls = make([]CapturedCall, size)
+		}
+	}
+}
+
+// WithCaptureSampleRate sets the fraction (0..1] of calls considered for capturing.
+func WithCaptureSampleRate(sampleRate float64) CaptureOption {
+	return func(c *DebugCapture) {
+		c.sampleRate = sampleRate
+	}
+}
+
+// WithCaptureRateLimit sets the maximum number of captures per second and the allowed burst.
+func WithCaptureRateLimit(perSecond float64, burst int) CaptureOption {
+	return func(c *DebugCapture) {
+		c.limiter = rate.NewLimiter(rate.Limit(perSecond), burst)
+	}
+}
+
+// WithCaptureRedactedFields adds field names whose values are redacted in captured payloads.
+func WithCaptureRedactedFields(fields ...string) CaptureOption {
+	return func(c *DebugCapture) {
+		for _, f := range fields {
+			c.redacted[strings.ToLower(f)] = struct{}{}
+		}
+	}
+}
+
+// NewDebugCapture creates a disabled DebugCapture.
+//
+// Parameters:
+//   - opts: Optional CaptureOption values (buffer size, sample rate, rate limit, redacted fields)
+//
+// Returns:
+//   - *DebugCapture: The capture; register its interceptors on the server and call Enable to start capturing
+func NewDebugCapture(opts ...CaptureOption) *DebugCapture {
+	c := &DebugCapture{
+		sampleRate: DefCaptureSampleRate,
+		limiter:    rate.NewLimiter(rate.Limit(DefCaptureRateLimit), 1),
+		redacted:   make(map[string]struct{}),
+		calls:      make([]CapturedCall, DefCaptureSize),
+	}
+
+	for _, f := range DefCaptureRedactedFields {
+		c.redacted[f] = struct{}{}
+	}
+
+	for _, o := range opts {
+		if o != nil {
+			o(c)
+		}
+	}
+
+	return c
+}
+
+// Enable starts capturing sampled calls.
+func (c *DebugCapture) Enable() { c.enabled.Store(true) }
+
+// Disable stops capturing calls. Already captured calls are kept.
+func (c *DebugCapture) Disable() { c.enabled.Store(false) }
+
+// Enabled reports whether calls are currently captured.
+func (c *DebugCapture) Enabled() bool { return c.enabled.Load() }
+
+// Calls returns the captured calls, oldest first.
+func (c *DebugCapture) Calls() []CapturedCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.full {
+		return slices.Clone(c.calls[:c.next])
+	}
+
+	return slices.Concat(c.calls[c.next:], c.calls[:c.next])
+}
+
+// Reset removes all captured calls.
+func (c *DebugCapture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.calls)
+	c.next = 0
+	c.full = false
+}
+
+// Handler serves the captured calls as JSON. A POST request with the query parameter
+// enabled=true|false toggles capturing, a DELETE request resets the buffer.
+func (c *DebugCapture) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			switch r.URL.Query().Get("enabled") {
+			case "true":
+				c.Enable()
+			case "false":
+				c.Disable()
+			default:
+				http.Error(w, "query parameter enabled must be true or false", http.StatusBadRequest)
+				return
+			}
+		case http.MethodDelete:
+			c.Reset()
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		_ = json.NewEncoder(w).Encode(struct {
+			Enabled bool           `json:"enabled"`
+			Calls   []CapturedCall `json:"calls"`
+		}{
+			Enabled: c.Enabled(),
+			Calls:   c.Calls(),
+		})
+	})
+}
+
+// UnaryServerInterceptor records sampled unary calls including their payloads.
+func (c *DebugCapture) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !c.sample() {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		c.record(CapturedCall{
+			Method:    info.FullMethod,
+			StartTime: start,
+			Duration:  time.Since(start),
+			Code:      status.Code(err).String(),
+			Error:     errorString(err),
+			Request:   c.marshal(req),
+			Response:  c.marshal(resp),
+		})
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor records sampled streaming calls. Only the method,
+// timings and status are recorded, stream messages are not captured.
+func (c *DebugCapture) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !c.sample() {
+			return handler(srv, ss)
+		}
+
+		start := time.Now()
+		err := handler(srv, ss)
+
+		c.record(CapturedCall{
+			Method:    info.FullMethod,
+			StartTime: start,
+			Duration:  time.Since(start),
+			Code:      status.Code(err).String(),
+			Error:     errorString(err),
+		})
+
+		return err
+	}
+}
+
+func (c *DebugCapture) sample() bool {
+	if !c.enabled.Load() {
+		return false
+	}
+
+	//nolint:gosec // sampling does not need a cryptographically secure random number
+	if c.sampleRate < 1 && rand.Float64() >= c.sampleRate {
+		return false
+	}
+
+	return c.limiter.Allow()
+}
+
+func (c *DebugCapture) record(call CapturedCall) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls[c.next] = call
+
+	c.next++
+	if c.next == len(c.calls) {
+		c.next = 0
+		c.full = true
+	}
+}
+
+func (c *DebugCapture) marshal(v any) json.RawMessage {
+	msg, ok := v.(proto.Message)
+	if !ok || msg == nil {
+		return nil
+	}
+
+	clone := proto.Clone(msg)
+	c.redact(clone.ProtoReflect())
+
+	data, err := protojson.Marshal(clone)
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+// redact replaces sensitive values of the message in place.
+func (c *DebugCapture) redact(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if c.isSensitive(fd) {
+			if fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap() {
+				m.Set(fd, protoreflect.ValueOfString(redactedValue))
+			} else {
+				m.Clear(fd)
+			}
+
+			return true
+		}
+
+		switch {
+		case fd.IsList() && fd.Kind() == protoreflect.MessageKind:
+			list := v.List()
+			for i := range list.Len() {
+				c.redact(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Kind() == protoreflect.MessageKind:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				c.redact(mv.Message())
+				return true
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Kind() == protoreflect.MessageKind:
+			c.redact(v.Message())
+		}
+
+		return true
+	})
+}
+
+func (c *DebugCapture) isSensitive(fd protoreflect.FieldDescriptor) bool {
+	if fd.Kind() == protoreflect.BytesKind {
+		return true
+	}
+
+	if _, ok := c.redacted[strings.ToLower(string(fd.Name()))]; ok {
+		return true
+	}
+
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+
+	return ok && opts.GetDebugRedact()
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,176 @@
+package commongrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/openkcm/common-sdk/pkg/commongrpc"
+)
+
+func newTestCapture(opts ...commongrpc.CaptureOption) *commongrpc.DebugCapture {
+	opts = append([]commongrpc.CaptureOption{
+		commongrpc.WithCaptureSampleRate(1),
+		commongrpc.WithCaptureRateLimit(1000, 1000),
+	}, opts...)
+
+	return commongrpc.NewDebugCapture(opts...)
+}
+
+func TestDebugCaptureDisabledByDefault(t *testing.T) {
+	capture := newTestCapture()
+	interceptor := capture.UnaryServerInterceptor()
+
+	_, err := interceptor(context.Background(), &healthpb.HealthCheckRequest{}, &grpc.UnaryServerInfo{FullMethod: "/svc/Method"},
+		func(ctx context.Context, req any) (any, error) { return &healthpb.HealthCheckResponse{}, nil })
+	require.NoError(t, err)
+
+	assert.False(t, capture.Enabled())
+	assert.Empty(t, capture.Calls())
+}
+
+func TestDebugCaptureRecordsRedactedCalls(t *testing.T) {
+	// Arrange
+	capture := newTestCapture(commongrpc.WithCaptureRedactedFields("Service"))
+	capture.Enable()
+
+	interceptor := capture.UnaryServerInterceptor()
+	req := &healthpb.HealthCheckRequest{Service: "secret-service"}
+
+	// Act
+	resp, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"},
+		func(ctx context.Context, req any) (any, error) {
+			return nil, status.Error(codes.PermissionDenied, "denied")
+		})
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, "secret-service", req.GetService(), "the original request must not be modified")
+
+	calls := capture.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "/grpc.health.v1.Health/Check", calls[0].Method)
+	assert.Equal(t, codes.PermissionDenied.String(), calls[0].Code)
+	assert.Contains(t, calls[0].Error, "denied")
+	assert.JSONEq(t, `{"service":"[REDACTED]"}`, string(calls[0].Request))
+	assert.Nil(t, calls[0].Response)
+}
+
+func TestDebugCaptureRedactsNestedAndBytesFields(t *testing.T) {
+	capture := newTestCapture(commongrpc.WithCaptureRedactedFields("name"))
+	capture.Enable()
+
+	req := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("file.proto"),
+		Package: proto.String("pkg"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Inner")},
+		},
+		Options: &descriptorpb.FileOptions{GoPackage: proto.String("go/pkg")},
+	}
+
+	_, err := capture.UnaryServerInterceptor()(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/svc/M"},
+		func(ctx context.Context, req any) (any, error) { return &healthpb.HealthCheckResponse{}, nil })
+	require.NoError(t, err)
+
+	calls := capture.Calls()
+	require.Len(t, calls, 1)
+	assert.JSONEq(t,
+		`{"name":"[REDACTED]","package":"pkg","messageType":[{"name":"[REDACTED]"}],"options":{"goPackage":"go/pkg"}}`,
+		string(calls[0].Request))
+	assert.Equal(t, codes.OK.String(), calls[0].Code)
+}
+
+func TestDebugCaptureRingBufferAndRateLimit(t *testing.T) {
+	capture := commongrpc.NewDebugCapture(
+		commongrpc.WithCaptureSize(3),
+		commongrpc.WithCaptureSampleRate(1),
+		commongrpc.WithCaptureRateLimit(0, 5),
+	)
+	capture.Enable()
+
+	interceptor := capture.UnaryServerInterceptor()
+	for _, method := range []string{"/a", "/b", "/c", "/d", "/e", "/f", "/g"} {
+		_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, req any) (any, error) { return nil, nil })
+	}
+
+	calls := capture.Calls()
+	require.Len(t, calls, 3)
+	// Only the burst of 5 calls is captured, the ring buffer keeps the last 3 of them.
+	assert.Equal(t, []string{"/c", "/d", "/e"}, []string{calls[0].Method, calls[1].Method, calls[2].Method})
+
+	capture.Reset()
+	assert.Empty(t, capture.Calls())
+}
+
+func TestDebugCaptureStreamInterceptor(t *testing.T) {
+	capture := newTestCapture()
+	capture.Enable()
+
+	err := capture.StreamServerInterceptor()(nil, nil, &grpc.StreamServerInfo{FullMethod: "/svc/Watch"},
+		func(srv any, stream grpc.ServerStream) error { return status.Error(codes.Canceled, "gone") })
+	require.Error(t, err)
+
+	calls := capture.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, codes.Canceled.String(), calls[0].Code)
+	assert.Nil(t, calls[0].Request)
+}
+
+func TestDebugCaptureHandler(t *testing.T) {
+	capture := newTestCapture()
+	handler := capture.Handler()
+
+	type body struct {
+		Enabled bool                      `json:"enabled"`
+		Calls   []commongrpc.CapturedCall `json:"calls"`
+	}
+
+	serve := func(method, target string) (int, body) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+
+		var b body
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &b))
+		}
+
+		return rec.Code, b
+	}
+
+	code, b := serve(http.MethodPost, "/debug/grpc?enabled=true")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, b.Enabled)
+
+	_, _ = capture.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/M"},
+		func(ctx context.Context, req any) (any, error) { return nil, nil })
+
+	_, b = serve(http.MethodGet, "/debug/grpc")
+	require.Len(t, b.Calls, 1)
+
+	_, b = serve(http.MethodDelete, "/debug/grpc")
+	assert.Empty(t, b.Calls)
+
+	code, _ = serve(http.MethodPost, "/debug/grpc?enabled=maybe")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = serve(http.MethodPut, "/debug/grpc")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+	_, b = serve(http.MethodPost, "/debug/grpc?enabled=false")
+	assert.False(t, b.Enabled)
+}
@@ -39,6 +39,8 @@ package commongrpc
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -75,39 +77,41 @@ type PooledClient interface {
 // created pool. The function returns an error if the configuration is invalid
 // or if the pool cannot be created.
 //
+// With the grpcpool.WithRegistry dial option, the clients of the same target and
+// credentials share one pool instead of each creating their own; the pool is created
+// with the other dial options of the first client. Release the pool of each client with grpcpool.Registry.Release
+// or close all pools with grpcpool.Registry.Close.
+//
 // Example:
 //
 //	err := commongrpc.NewPooledClient(myClient, cfg,
 //	    grpc.WithBlock(),
 //	)
 func NewPooledClient(client PooledClient, cfg *commoncfg.GRPCClient, dialOptions ...grpc.DialOption) error {
-	if cfg.Address == "" {
-		return ErrEmptyAddress
-	}
+	registry, dialOptions := grpcpool.RegistryFromDialOptions(dialOptions)
 
-	creds, err := computeTransportCredentials(cfg)
+	target, opts, err := clientDialOptions(cfg, dialOptions)
 	if err != nil {
 		return err
 	}
 
-	opts := make([]grpc.DialOption, 0, 3+len(dialOptions))
-	opts = append(opts,
-		grpc.WithKeepaliveParams(keepalive.ClientParameters{
-			Time:    cfg.Attributes.KeepaliveTime,
-			Timeout: cfg.Attributes.KeepaliveTimeout,
-		}),
-		grpc.WithStatsHandler(otlp.NewClientHandler()),
-		grpc.WithTransportCredentials(creds),
-	)
-	opts = append(opts, dialOptions...)
+	newPool := func() (*grpcpool.Pool, error) {
+		return grpcpool.New(
+			createFactory(target, opts...),
+			grpcpool.WithInitialCapacity(cfg.Pool.InitialCapacity),
+			grpcpool.WithMaxCapacity(cfg.Pool.MaxCapacity),
+			grpcpool.WithIdleTimeout(cfg.Pool.IdleTimeout),
+			grpcpool.WithMaxLifeDuration(cfg.Pool.MaxLifeDuration),
+		)
+	}
+
+	var clientPool *grpcpool.Pool
+	if registry != nil {
+		clientPool, _, err = registry.Acquire(registryKey(cfg), newPool)
+	} else {
+		clientPool, err = newPool()
+	}
 
-	clientPool, err := grpcpool.New(
-		createFactory(cfg.Address, opts...),
-		grpcpool.WithInitialCapacity(cfg.Pool.InitialCapacity),
-		grpcpool.WithMaxCapacity(cfg.Pool.MaxCapacity),
-		grpcpool.WithIdleTimeout(cfg.Pool.IdleTimeout),
-		grpcpool.WithMaxLifeDuration(cfg.Pool.MaxLifeDuration),
-	)
 	if err != nil {
 		return err
 	}
@@ -117,6 +121,21 @@ func NewPooledClient(client PooledClient, cfg *commoncfg.GRPCClient, dialOptions
 	return nil
 }
 
+// registryKey returns the key the pools of a client are shared by: the address, or the
+// addresses of the weighted targets, and the credentials.
+func registryKey(cfg *commoncfg.GRPCClient) string {
+	if len(cfg.Targets) == 0 {
+		return grpcpool.RegistryKey(cfg.Address, cfg.SecretRef)
+	}
+
+	addrs := make([]string, 0, len(cfg.Targets))
+	for _, target := range cfg.Targets {
+		addrs = append(addrs, fmt.Sprintf("%s=%s/%d", target.Name, target.Address, target.Weight))
+	}
+
+	return grpcpool.RegistryKey(weightedTargetScheme+":"+strings.Join(addrs, ","), cfg.SecretRef)
+}
+
 // createFactory returns a grpcpool.ClientFactory function that dials
 // a new gRPC ClientConn using the given address and dial options.
 //
@@ -146,16 +165,38 @@ func createFactory(address string, dialOptions ...grpc.DialOption) grpcpool.Clie
 //	}
 //	defer conn.Close()
 func NewClient(cfg *commoncfg.GRPCClient, dialOptions ...grpc.DialOption) (*grpc.ClientConn, error) {
-	if cfg.Address == "" {
-		return nil, ErrEmptyAddress
+	target, opts, err := clientDialOptions(cfg, dialOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.NewClient(target, opts...)
+}
+
+// clientDialOptions returns the dial target and the dial options shared by all clients.
+//
+// If the configuration defines weighted targets, the calls are routed by the
+// WeightedTargetBalancerName load balancing policy: each call is sent to one of
+// the ready targets according to the weights, unless the TargetOverrideHeader
+// metadata names a target. Targets which are not ready are skipped and their
+// share is distributed among the ready ones.
+//
+// If retries are enabled, the unary calls are retried by RetryUnaryClientInterceptor
+// within a RetryBudget shared by all methods and, for pooled clients, all connections.
+//
+// If fault injection is enabled, the faults are injected inside the retries, so the
+// retries see them like failures of the server.
+func clientDialOptions(cfg *commoncfg.GRPCClient, dialOptions []grpc.DialOption) (string, []grpc.DialOption, error) {
+	if cfg.Address == "" && len(cfg.Targets) == 0 {
+		return "", nil, ErrEmptyAddress
 	}
 
 	creds, err := computeTransportCredentials(cfg)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
-	opts := make([]grpc.DialOption, 0, 3+len(dialOptions))
+	opts := make([]grpc.DialOption, 0, 5+len(dialOptions))
 	opts = append(opts,
 		grpc.WithKeepaliveParams(keepalive.ClientParameters{
 			Time:    cfg.Attributes.KeepaliveTime,
@@ -164,9 +205,31 @@ func NewClient(cfg *commoncfg.GRPCClient, dialOptions ...grpc.DialOption) (*grpc
 		grpc.WithStatsHandler(otlp.NewClientHandler()),
 		grpc.WithTransportCredentials(creds),
 	)
+
+	target := cfg.Address
+
+	if len(cfg.Targets) > 0 {
+		var weightedOpts []grpc.DialOption
+
+		target, weightedOpts = weightedDialTarget(cfg)
+		opts = append(opts, weightedOpts...)
+	}
+
+	retryOpts, err := retryDialOptions(cfg.Retry)
+	if err != nil {
+		return "", nil, err
+	}
+
+	faultOpts, dialOptions, err := faultDialOptions(cfg.FaultInjection, dialOptions)
+	if err != nil {
+		return "", nil, err
+	}
+
+	opts = append(opts, retryOpts...)
+	opts = append(opts, faultOpts...)
 	opts = append(opts, dialOptions...)
 
-	return grpc.NewClient(cfg.Address, opts...)
+	return target, opts, nil
 }
 
 // computeTransportCredentials determines the appropriate gRPC
@@ -0,0 +1,87 @@
+package commongrpc_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commongrpc"
+	"github.com/openkcm/common-sdk/pkg/grpcpool"
+	"github.com/openkcm/common-sdk/pkg/health"
+)
+
+type testPooledClient struct {
+	pool *grpcpool.Pool
+}
+
+func (c *testPooledClient) SetPool(pool *grpcpool.Pool) {
+	c.pool = pool
+}
+
+func TestNewPooledClientWithRegistry(t *testing.T) {
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, grpchealth.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() { _ = srv.Serve(lis) }()
+
+	t.Cleanup(srv.Stop)
+
+	cfg := &commoncfg.GRPCClient{
+		Address: lis.Addr().String(),
+		Pool:    commoncfg.GRPCPool{InitialCapacity: 1, MaxCapacity: 2},
+	}
+
+	registry := grpcpool.NewRegistry()
+	t.Cleanup(func() { _ = registry.Close() })
+
+	business, metrics := &testPooledClient{}, &testPooledClient{}
+
+	require.NoError(t, commongrpc.NewPooledClient(business, cfg, grpcpool.WithRegistry(registry)))
+	require.NoError(t, commongrpc.NewPooledClient(metrics, cfg, grpcpool.WithRegistry(registry)))
+	assert.Same(t, business.pool, metrics.pool)
+
+	healthClient, err := health.NewGRPCHealthClient(cfg, grpcpool.WithRegistry(registry))
+	require.NoError(t, err)
+
+	key := grpcpool.RegistryKey(cfg.Address, cfg.SecretRef)
+	assert.Equal(t, 3, registry.Refs(key))
+
+	// clients with other credentials do not share the pool
+	insecureCfg := *cfg
+	insecureCfg.SecretRef = &commoncfg.SecretRef{Type: commoncfg.InsecureSecretType}
+
+	withCredentials := &testPooledClient{}
+	require.NoError(t, commongrpc.NewPooledClient(withCredentials, &insecureCfg, grpcpool.WithRegistry(registry)))
+	assert.NotSame(t, business.pool, withCredentials.pool)
+	assert.Equal(t, 3, registry.Refs(key))
+
+	resp, err := healthClient.Check(t.Context(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	require.NoError(t, registry.Release(business.pool))
+	require.NoError(t, registry.Release(metrics.pool))
+	assert.False(t, business.pool.IsClosed())
+
+	// the pool is closed once the health client releases it as well
+	require.NoError(t, healthClient.Close())
+	require.NoError(t, healthClient.Close())
+	assert.True(t, business.pool.IsClosed())
+	assert.Equal(t, 0, registry.Refs(key))
+
+	other := &testPooledClient{}
+	require.NoError(t, commongrpc.NewPooledClient(other, cfg))
+	assert.NotSame(t, business.pool, other.pool)
+
+	t.Cleanup(func() { _ = other.pool.Close() })
+}
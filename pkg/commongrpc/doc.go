@@ -60,12 +60,25 @@
 //   - Optional gRPC health service (server)
 //   - Connection pooling for clients
 //   - Secure (mTLS) and insecure transport credentials
+//   - Opt-in, rate-limited debug capture of sampled RPCs (server)
+//   - Weighted routing between several targets, e.g. stable and canary, with a
+//     metadata header forcing a target for test traffic (client)
+//   - Retries of failed unary calls limited by a retry budget shared by all
+//     methods, e.g. at most 20% of the calls over a sliding window (client)
+//   - Reference counted pools shared by the clients of a target, e.g. the business
+//     and health clients, via grpcpool.WithRegistry (client)
+//   - Fault injection of latency, error codes and connection resets into a percentage
+//     of the calls for chaos experiments, togglable at runtime via a feature gate
 //
 // # Functions
 //
 //   - NewServer: Creates and configures a new gRPC server instance.
 //   - NewClient: Creates a single gRPC client connection.
 //   - NewPooledClient: Initializes a pooled gRPC client.
+//   - NewDebugCapture: Creates a ring buffer of sampled, redacted RPCs served via status.WithEndpoint.
+//   - WithTargetOverride: Forces the calls of a context to a weighted target of the client.
+//   - RetryUnaryClientInterceptor: Retries failed unary calls within a RetryBudget.
+//   - NewFaultInjector: Creates the interceptors injecting faults into the calls.
 //
 // # Function Documentation
 //
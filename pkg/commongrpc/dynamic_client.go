@@ -26,6 +26,7 @@ package commongrpc
 
 import (
 	"log/slog"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -116,8 +117,9 @@ func NewDynamicClientConn(cfg *commoncfg.GRPCClient, throttleInterval time.Durat
 //   - error: non-nil if the notifier could not be created or started.
 //
 // Behavior:
-//  1. Collects the directories containing the client certificate, key, and server CA (if provided).
-//     Only the directories are monitored, not the individual files directly.
+//  1. Collects the directories containing the client certificate, key or PKCS#12 bundle, and server CA (if provided).
+//     Only the directories are monitored, not the individual files directly; a server CA
+//     directory of PEM files is monitored itself.
 //  2. Creates a new notifier instance with:
 //     - monitored paths as collected above
 //     - an event handler that triggers DynamicClientConn refresh
@@ -138,17 +140,33 @@ func (dcc *DynamicClientConn) initAndStartNotifierOnMTLSCredentials(throttleInte
 		pathMap[filepath.Dir(keyPath)] = struct{}{}
 	}
 
+	if mtls.PKCS12 != nil {
+		bundlePath := strings.TrimSpace(mtls.PKCS12.File.Path)
+		if bundlePath != "" {
+			pathMap[filepath.Dir(bundlePath)] = struct{}{}
+		}
+	}
+
 	if mtls.ServerCA != nil {
 		caPath := strings.TrimSpace(mtls.ServerCA.File.Path)
 		if caPath != "" {
-			pathMap[filepath.Dir(caPath)] = struct{}{}
+			pathMap[caWatchPath(caPath)] = struct{}{}
 		}
 	}
 
 	for _, ca := range mtls.RootCAs {
 		caPath := strings.TrimSpace(ca.File.Path)
 		if caPath != "" {
-			pathMap[filepath.Dir(caPath)] = struct{}{}
+			pathMap[caWatchPath(caPath)] = struct{}{}
+		}
+	}
+
+	if mtls.Revocation != nil {
+		for _, crl := range mtls.Revocation.CRLs {
+			crlPath := strings.TrimSpace(crl.File.Path)
+			if crlPath != "" {
+				pathMap[filepath.Dir(crlPath)] = struct{}{}
+			}
 		}
 	}
 
@@ -177,6 +195,17 @@ func (dcc *DynamicClientConn) initAndStartNotifierOnMTLSCredentials(throttleInte
 	return nil
 }
 
+// caWatchPath returns the directory to watch for a CA path, which is either a
+// bundle file or a directory of PEM files.
+func caWatchPath(caPath string) string {
+	info, err := os.Stat(caPath)
+	if err == nil && info.IsDir() {
+		return caPath
+	}
+
+	return filepath.Dir(caPath)
+}
+
 // Close stops the file watcher (if active) and closes the underlying
 // gRPC client connection. After calling Close, the DynamicClientConn
 // must not be reused.
@@ -0,0 +1,316 @@
+package commongrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// ErrInvalidFaultCode is returned when the error code of a fault injection is not a gRPC status code.
+var ErrInvalidFaultCode = errors.New("invalid fault injection grpc status code")
+
+// FeatureGateChecker reports whether a feature is enabled, e.g. commoncfg.FeatureGates or
+// *commoncfg.DynamicFeatureGates to toggle the fault injection at runtime.
+type FeatureGateChecker interface {
+	IsFeatureEnabled(feature string) bool
+}
+
+// FaultInjector injects latency, errors and connection resets into a percentage of the
+// calls of a client or server, to run chaos experiments against the shared middleware,
+// e.g. retries and timeouts, without modifying the service code.
+//
+// NewClient, NewPooledClient and NewServer install its interceptors if the fault injection
+// of their configuration is enabled; pass the feature gates toggling it with
+// WithFaultInjectionGates or WithServerFaultInjectionGates.
+//
+// FaultInjector is safe for concurrent use.
+type FaultInjector struct {
+	cfg   commoncfg.GRPCFaultInjection
+	code  codes.Code
+	gates FeatureGateChecker
+}
+
+// fault holds the faults drawn for a call.
+type fault struct {
+	delay time.Duration
+	err   bool
+	reset bool
+}
+
+// NewFaultInjector creates a fault injector. If the configuration names a feature gate,
+// faults are only injected while gates enables it; without gates no faults are injected.
+func NewFaultInjector(cfg commoncfg.GRPCFaultInjection, gates FeatureGateChecker) (*FaultInjector, error) {
+	code := codes.Unavailable
+	if cfg.ErrorCode != "" {
+		var err error
+
+		code, err = parseCode(cfg.ErrorCode)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFaultCode, cfg.ErrorCode)
+		}
+	}
+
+	return &FaultInjector{
+		cfg:   cfg,
+		code:  code,
+		gates: gates,
+	}, nil
+}
+
+// Active reports whether faults are currently injected into the calls of the method.
+func (f *FaultInjector) Active(method string) bool {
+	if !f.cfg.Enabled {
+		return false
+	}
+
+	if f.cfg.FeatureGate != "" && (f.gates == nil || !f.gates.IsFeatureEnabled(f.cfg.FeatureGate)) {
+		return false
+	}
+
+	if len(f.cfg.Methods) == 0 {
+		return true
+	}
+
+	for _, prefix := range f.cfg.Methods {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnaryClientInterceptor injects faults into unary calls of a client.
+func (f *FaultInjector) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		flt := f.draw(method)
+
+		err := f.inject(ctx, method, flt)
+		if err != nil {
+			return err
+		}
+
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil && flt.reset {
+			return resetError(ctx, method)
+		}
+
+		return err
+	}
+}
+
+// StreamClientInterceptor injects faults into streams of a client. The first message
+// received from a reset stream fails.
+func (f *FaultInjector) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		flt := f.draw(method)
+
+		err := f.inject(ctx, method, flt)
+		if err != nil {
+			return nil, err
+		}
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err == nil && flt.reset {
+			return &resetClientStream{ClientStream: stream, err: resetError(ctx, method)}, nil
+		}
+
+		return stream, err
+	}
+}
+
+// UnaryServerInterceptor injects faults into unary calls of a server.
+func (f *FaultInjector) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		flt := f.draw(info.FullMethod)
+
+		err := f.inject(ctx, info.FullMethod, flt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := handler(ctx, req)
+		if err == nil && flt.reset {
+			return nil, resetError(ctx, info.FullMethod)
+		}
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor injects faults into streams of a server.
+func (f *FaultInjector) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		flt := f.draw(info.FullMethod)
+
+		err := f.inject(ss.Context(), info.FullMethod, flt)
+		if err != nil {
+			return err
+		}
+
+		err = handler(srv, ss)
+		if err == nil && flt.reset {
+			return resetError(ss.Context(), info.FullMethod)
+		}
+
+		return err
+	}
+}
+
+// draw decides the faults of a call of the method.
+func (f *FaultInjector) draw(method string) fault {
+	if !f.Active(method) {
+		return fault{}
+	}
+
+	var flt fault
+
+	if hit(f.cfg.DelayPercentage) {
+		flt.delay = f.cfg.Delay
+	}
+
+	flt.err = hit(f.cfg.ErrorPercentage)
+	flt.reset = !flt.err && hit(f.cfg.ResetPercentage)
+
+	return flt
+}
+
+// inject applies the delay and the error of a fault before the call is processed.
+func (f *FaultInjector) inject(ctx context.Context, method string, flt fault) error {
+	if flt.delay > 0 {
+		slogctx.Debug(ctx, "Injecting grpc fault", "method", method, "delay", flt.delay)
+
+		timer := time.NewTimer(flt.delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return status.FromContextError(ctx.Err()).Err()
+		case <-timer.C:
+		}
+	}
+
+	if flt.err {
+		slogctx.Debug(ctx, "Injecting grpc fault", "method", method, "code", f.code)
+		return status.Errorf(f.code, "injected fault in %s", method)
+	}
+
+	return nil
+}
+
+func resetError(ctx context.Context, method string) error {
+	slogctx.Debug(ctx, "Injecting grpc fault", "method", method, "reset", true)
+	return status.Errorf(codes.Unavailable, "injected connection reset in %s", method)
+}
+
+// hit reports whether a call is part of the percentage.
+func hit(percentage float64) bool {
+	//nolint:gosec // fault injection does not need a cryptographically secure random number
+	return percentage > 0 && rand.Float64()*100 < percentage
+}
+
+type resetClientStream struct {
+	grpc.ClientStream
+
+	err error
+}
+
+func (s *resetClientStream) RecvMsg(any) error {
+	return s.err
+}
+
+type faultGatesDialOption struct {
+	grpc.EmptyDialOption
+
+	gates FeatureGateChecker
+}
+
+type faultGatesServerOption struct {
+	grpc.EmptyServerOption
+
+	gates FeatureGateChecker
+}
+
+// WithFaultInjectionGates returns a dial option passing the feature gates which toggle the
+// fault injection of the client configuration to NewClient and NewPooledClient.
+func WithFaultInjectionGates(gates FeatureGateChecker) grpc.DialOption {
+	return faultGatesDialOption{gates: gates}
+}
+
+// WithServerFaultInjectionGates returns a server option passing the feature gates which
+// toggle the fault injection of the server configuration to NewServer.
+func WithServerFaultInjectionGates(gates FeatureGateChecker) grpc.ServerOption {
+	return faultGatesServerOption{gates: gates}
+}
+
+// faultDialOptions returns the dial options injecting the faults of the configuration,
+// and the dial options without the feature gates option.
+func faultDialOptions(cfg commoncfg.GRPCFaultInjection, dialOptions []grpc.DialOption) ([]grpc.DialOption, []grpc.DialOption, error) {
+	var gates FeatureGateChecker
+
+	opts := make([]grpc.DialOption, 0, len(dialOptions))
+
+	for _, opt := range dialOptions {
+		if o, ok := opt.(faultGatesDialOption); ok {
+			gates = o.gates
+			continue
+		}
+
+		opts = append(opts, opt)
+	}
+
+	if !cfg.Enabled {
+		return nil, opts, nil
+	}
+
+	injector, err := NewFaultInjector(cfg, gates)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(injector.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(injector.StreamClientInterceptor()),
+	}, opts, nil
+}
+
+// faultServerOptions returns the server options injecting the faults of the configuration,
+// and the server options without the feature gates option.
+func faultServerOptions(cfg commoncfg.GRPCFaultInjection, serverOptions []grpc.ServerOption) ([]grpc.ServerOption, []grpc.ServerOption, error) {
+	var gates FeatureGateChecker
+
+	opts := make([]grpc.ServerOption, 0, len(serverOptions))
+
+	for _, opt := range serverOptions {
+		if o, ok := opt.(faultGatesServerOption); ok {
+			gates = o.gates
+			continue
+		}
+
+		opts = append(opts, opt)
+	}
+
+	if !cfg.Enabled {
+		return nil, opts, nil
+	}
+
+	injector, err := NewFaultInjector(cfg, gates)
+	if err != nil {
+		return nil, opts, err
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(injector.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(injector.StreamServerInterceptor()),
+	}, opts, nil
+}
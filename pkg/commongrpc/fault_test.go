@@ -0,0 +1,302 @@
+package commongrpc_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/status"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commongrpc"
+)
+
+func TestFaultInjectorActive(t *testing.T) {
+	gates := commoncfg.NewDynamicFeatureGates(commoncfg.FeatureGates{})
+
+	tests := []struct {
+		name   string
+		cfg    commoncfg.GRPCFaultInjection
+		gates  commongrpc.FeatureGateChecker
+		method string
+		want   bool
+	}{
+		{
+			name:   "disabled",
+			cfg:    commoncfg.GRPCFaultInjection{},
+			method: "/svc/Method",
+			want:   false,
+		},
+		{
+			name:   "all methods",
+			cfg:    commoncfg.GRPCFaultInjection{Enabled: true},
+			method: "/svc/Method",
+			want:   true,
+		},
+		{
+			name:   "matching method prefix",
+			cfg:    commoncfg.GRPCFaultInjection{Enabled: true, Methods: []string{"/other/", "/svc/"}},
+			method: "/svc/Method",
+			want:   true,
+		},
+		{
+			name:   "other method",
+			cfg:    commoncfg.GRPCFaultInjection{Enabled: true, Methods: []string{"/svc/Other"}},
+			method: "/svc/Method",
+			want:   false,
+		},
+		{
+			name:   "feature gate without gates",
+			cfg:    commoncfg.GRPCFaultInjection{Enabled: true, FeatureGate: "chaos"},
+			method: "/svc/Method",
+			want:   false,
+		},
+		{
+			name:   "disabled feature gate",
+			cfg:    commoncfg.GRPCFaultInjection{Enabled: true, FeatureGate: "chaos"},
+			gates:  gates,
+			method: "/svc/Method",
+			want:   false,
+		},
+		{
+			name:   "enabled feature gate",
+			cfg:    commoncfg.GRPCFaultInjection{Enabled: true, FeatureGate: "chaos"},
+			gates:  commoncfg.FeatureGates{"chaos": {Enabled: true}},
+			method: "/svc/Method",
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector, err := commongrpc.NewFaultInjector(tt.cfg, tt.gates)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, injector.Active(tt.method))
+		})
+	}
+
+	t.Run("toggles at runtime", func(t *testing.T) {
+		injector, err := commongrpc.NewFaultInjector(
+			commoncfg.GRPCFaultInjection{Enabled: true, FeatureGate: "chaos"}, gates)
+		require.NoError(t, err)
+
+		assert.False(t, injector.Active("/svc/Method"))
+
+		gates.Update(commoncfg.FeatureGates{"chaos": {Enabled: true}})
+		assert.True(t, injector.Active("/svc/Method"))
+	})
+
+	t.Run("errors on invalid codes", func(t *testing.T) {
+		_, err := commongrpc.NewFaultInjector(commoncfg.GRPCFaultInjection{ErrorCode: "SOMETIMES"}, nil)
+		assert.ErrorIs(t, err, commongrpc.ErrInvalidFaultCode)
+	})
+}
+
+func TestFaultInjectorUnaryClientInterceptor(t *testing.T) {
+	countingInvoker := func(calls *int) grpc.UnaryInvoker {
+		return func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			*calls++
+			return nil
+		}
+	}
+
+	t.Run("injects errors without invoking the call", func(t *testing.T) {
+		injector, err := commongrpc.NewFaultInjector(commoncfg.GRPCFaultInjection{
+			Enabled:         true,
+			ErrorPercentage: 100,
+			ErrorCode:       "resource_exhausted",
+		}, nil)
+		require.NoError(t, err)
+
+		calls := 0
+		err = injector.UnaryClientInterceptor()(t.Context(), "/svc/Method", nil, nil, nil, countingInvoker(&calls))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("injects resets after invoking the call", func(t *testing.T) {
+		injector, err := commongrpc.NewFaultInjector(commoncfg.GRPCFaultInjection{
+			Enabled:         true,
+			ResetPercentage: 100,
+		}, nil)
+		require.NoError(t, err)
+
+		calls := 0
+		err = injector.UnaryClientInterceptor()(t.Context(), "/svc/Method", nil, nil, nil, countingInvoker(&calls))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("injects delays", func(t *testing.T) {
+		injector, err := commongrpc.NewFaultInjector(commoncfg.GRPCFaultInjection{
+			Enabled:         true,
+			DelayPercentage: 100,
+			Delay:           20 * time.Millisecond,
+		}, nil)
+		require.NoError(t, err)
+
+		calls := 0
+		start := time.Now()
+		err = injector.UnaryClientInterceptor()(t.Context(), "/svc/Method", nil, nil, nil, countingInvoker(&calls))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("delays honour the deadline", func(t *testing.T) {
+		injector, err := commongrpc.NewFaultInjector(commoncfg.GRPCFaultInjection{
+			Enabled:         true,
+			DelayPercentage: 100,
+			Delay:           time.Minute,
+		}, nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+
+		calls := 0
+		err = injector.UnaryClientInterceptor()(ctx, "/svc/Method", nil, nil, nil, countingInvoker(&calls))
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("injects faults into a percentage of the calls", func(t *testing.T) {
+		injector, err := commongrpc.NewFaultInjector(commoncfg.GRPCFaultInjection{
+			Enabled:         true,
+			ErrorPercentage: 50,
+		}, nil)
+		require.NoError(t, err)
+
+		calls := 0
+		for range 1000 {
+			_ = injector.UnaryClientInterceptor()(t.Context(), "/svc/Method", nil, nil, nil, countingInvoker(&calls))
+		}
+
+		assert.InDelta(t, 500, calls, 100)
+	})
+}
+
+func TestFaultInjectorServerInterceptors(t *testing.T) {
+	injector, err := commongrpc.NewFaultInjector(commoncfg.GRPCFaultInjection{
+		Enabled:         true,
+		ResetPercentage: 100,
+	}, nil)
+	require.NoError(t, err)
+
+	t.Run("unary resets discard the response", func(t *testing.T) {
+		calls := 0
+		resp, err := injector.UnaryServerInterceptor()(t.Context(), nil,
+			&grpc.UnaryServerInfo{FullMethod: "/svc/Method"},
+			func(context.Context, any) (any, error) {
+				calls++
+				return "response", nil
+			})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Nil(t, resp)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("stream resets fail after the handler", func(t *testing.T) {
+		calls := 0
+		err := injector.StreamServerInterceptor()(nil, &fakeServerStream{ctx: t.Context()},
+			&grpc.StreamServerInfo{FullMethod: "/svc/Stream"},
+			func(any, grpc.ServerStream) error {
+				calls++
+				return nil
+			})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestNewClientFaultInjection(t *testing.T) {
+	calls := &atomic.Int64{}
+	srv := grpc.NewServer(grpc.UnaryInterceptor(
+		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			calls.Add(1)
+			return handler(ctx, req)
+		},
+	))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() { _ = srv.Serve(lis) }()
+
+	t.Cleanup(srv.Stop)
+
+	gates := commoncfg.NewDynamicFeatureGates(commoncfg.FeatureGates{"chaos": {Enabled: true}})
+
+	conn, err := commongrpc.NewClient(&commoncfg.GRPCClient{
+		Address: lis.Addr().String(),
+		FaultInjection: commoncfg.GRPCFaultInjection{
+			Enabled:         true,
+			FeatureGate:     "chaos",
+			ErrorPercentage: 100,
+			ErrorCode:       "UNAVAILABLE",
+		},
+	}, commongrpc.WithFaultInjectionGates(gates))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	client := healthpb.NewHealthClient(conn)
+
+	_, err = client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int64(0), calls.Load())
+
+	gates.Update(commoncfg.FeatureGates{"chaos": {Enabled: false}})
+
+	_, err = client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), calls.Load())
+}
+
+func TestNewServerFaultInjection(t *testing.T) {
+	srv := commongrpc.NewServer(t.Context(), &commoncfg.GRPCServer{
+		FaultInjection: commoncfg.GRPCFaultInjection{
+			Enabled:         true,
+			Methods:         []string{"/grpc.health.v1.Health/"},
+			ErrorPercentage: 100,
+			ErrorCode:       "ABORTED",
+		},
+	})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() { _ = srv.Serve(lis) }()
+
+	t.Cleanup(srv.Stop)
+
+	conn, err := commongrpc.NewClient(&commoncfg.GRPCClient{Address: lis.Addr().String()})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = healthpb.NewHealthClient(conn).Check(t.Context(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
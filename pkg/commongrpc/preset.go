@@ -0,0 +1,238 @@
+package commongrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/creasty/defaults"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// ErrPresetRequiresTLS is logged, and fails the handshakes of the clients, when the preset
+// of a server serves over TLS but the server has no TLS configuration.
+var ErrPresetRequiresTLS = errors.New("grpc server preset requires a tls configuration")
+
+// serverPreset holds the settings a commoncfg.GRPCServerPreset applies to a server.
+type serverPreset struct {
+	MaxSendMsgSize           commoncfg.ByteSize
+	MaxRecvMsgSize           commoncfg.ByteSize
+	EfPolMinTime             time.Duration
+	EfPolPermitWithoutStream bool
+	Attributes               commoncfg.GRPCServerAttributes
+
+	// Recovery converts panics of the handlers into codes.Internal errors.
+	Recovery bool
+	// RequireTLS rejects a configuration without TLS.
+	RequireTLS bool
+	// ClientAuth is the policy for the client certificates if TLS is configured.
+	ClientAuth tls.ClientAuthType
+	// MinTLSVersion is the minimum TLS version if TLS is configured.
+	MinTLSVersion uint16
+}
+
+var serverPresets = map[commoncfg.GRPCServerPreset]serverPreset{
+	commoncfg.EdgeGRPCServerPreset: {
+		MaxSendMsgSize: 4 * commoncfg.MebiByte,
+		MaxRecvMsgSize: 4 * commoncfg.MebiByte,
+		EfPolMinTime:   5 * time.Minute,
+		Attributes: commoncfg.GRPCServerAttributes{
+			MaxConnectionIdle:     5 * time.Minute,
+			MaxConnectionAge:      30 * time.Minute,
+			MaxConnectionAgeGrace: 30 * time.Second,
+			Time:                  30 * time.Minute,
+			Timeout:               20 * time.Second,
+		},
+		Recovery:      true,
+		RequireTLS:    true,
+		ClientAuth:    tls.VerifyClientCertIfGiven,
+		MinTLSVersion: tls.VersionTLS13,
+	},
+	commoncfg.InternalGRPCServerPreset: {
+		MaxSendMsgSize:           16 * commoncfg.MebiByte,
+		MaxRecvMsgSize:           16 * commoncfg.MebiByte,
+		EfPolMinTime:             30 * time.Second,
+		EfPolPermitWithoutStream: true,
+		Attributes: commoncfg.GRPCServerAttributes{
+			MaxConnectionIdle:     30 * time.Minute,
+			MaxConnectionAge:      30 * time.Minute,
+			MaxConnectionAgeGrace: 5 * time.Minute,
+			Time:                  2 * time.Minute,
+			Timeout:               20 * time.Second,
+		},
+		Recovery:      true,
+		RequireTLS:    true,
+		ClientAuth:    tls.RequireAndVerifyClientCert,
+		MinTLSVersion: tls.VersionTLS12,
+	},
+	commoncfg.SidecarGRPCServerPreset: {
+		MaxSendMsgSize:           2147483647,
+		MaxRecvMsgSize:           120 * commoncfg.MebiByte,
+		EfPolMinTime:             10 * time.Second,
+		EfPolPermitWithoutStream: true,
+		Attributes: commoncfg.GRPCServerAttributes{
+			// zero durations keep the connections forever
+			Time:    time.Minute,
+			Timeout: 10 * time.Second,
+		},
+		ClientAuth:    tls.VerifyClientCertIfGiven,
+		MinTLSVersion: tls.VersionTLS12,
+	},
+}
+
+// defaultServerPreset keeps the settings of the configuration of servers without preset.
+var defaultServerPreset = serverPreset{
+	ClientAuth:    tls.VerifyClientCertIfGiven,
+	MinTLSVersion: tls.VersionTLS12,
+}
+
+// ApplyServerPreset returns a copy of the configuration with the settings of its preset.
+//
+// The fields of the configuration which are neither zero nor their default value override
+// the preset, so setting a field to its default value does not override the preset.
+// A configuration without or with an unknown preset is returned unchanged.
+func ApplyServerPreset(cfg *commoncfg.GRPCServer) *commoncfg.GRPCServer {
+	resolved := *cfg
+
+	preset, ok := serverPresets[cfg.Preset]
+	if !ok {
+		return &resolved
+	}
+
+	var def commoncfg.GRPCServer
+	// the defaults of the struct tags are valid
+	_ = defaults.Set(&def)
+
+	resolved.MaxSendMsgSize = presetValue(cfg.MaxSendMsgSize, def.MaxSendMsgSize, preset.MaxSendMsgSize)
+	resolved.MaxRecvMsgSize = presetValue(cfg.MaxRecvMsgSize, def.MaxRecvMsgSize, preset.MaxRecvMsgSize)
+	resolved.EfPolMinTime = presetValue(cfg.EfPolMinTime, def.EfPolMinTime, preset.EfPolMinTime)
+	resolved.EfPolPermitWithoutStream = presetValue(cfg.EfPolPermitWithoutStream, def.EfPolPermitWithoutStream, preset.EfPolPermitWithoutStream)
+
+	attrs, defAttrs := cfg.Attributes, def.Attributes
+	resolved.Attributes = commoncfg.GRPCServerAttributes{
+		MaxConnectionIdle:     presetValue(attrs.MaxConnectionIdle, defAttrs.MaxConnectionIdle, preset.Attributes.MaxConnectionIdle),
+		MaxConnectionAge:      presetValue(attrs.MaxConnectionAge, defAttrs.MaxConnectionAge, preset.Attributes.MaxConnectionAge),
+		MaxConnectionAgeGrace: presetValue(attrs.MaxConnectionAgeGrace, defAttrs.MaxConnectionAgeGrace, preset.Attributes.MaxConnectionAgeGrace),
+		Time:                  presetValue(attrs.Time, defAttrs.Time, preset.Attributes.Time),
+		Timeout:               presetValue(attrs.Timeout, defAttrs.Timeout, preset.Attributes.Timeout),
+	}
+
+	return &resolved
+}
+
+// presetValue returns the value of the preset if the configured value is zero or its default.
+func presetValue[T comparable](configured, def, preset T) T {
+	var zero T
+	if configured == zero || configured == def {
+		return preset
+	}
+
+	return configured
+}
+
+// presetServerOptions returns the interceptors and transport credentials of the preset
+// of the configuration.
+//
+// If the TLS configuration cannot be loaded, or is missing for a preset requiring TLS,
+// the server rejects all connections instead of serving them without TLS.
+func presetServerOptions(ctx context.Context, cfg *commoncfg.GRPCServer) []grpc.ServerOption {
+	preset, ok := serverPresets[cfg.Preset]
+	if !ok {
+		preset = defaultServerPreset
+	}
+
+	var opts []grpc.ServerOption
+
+	if preset.Recovery {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(recoveryUnaryServerInterceptor),
+			grpc.ChainStreamInterceptor(recoveryStreamServerInterceptor),
+		)
+	}
+
+	if cfg.TLS == nil && !preset.RequireTLS {
+		return opts
+	}
+
+	tlsConfig, err := serverTLSConfig(cfg.TLS, preset)
+	if err != nil {
+		slogctx.Error(ctx, "grpc server rejects all connections", "preset", cfg.Preset, "error", err)
+
+		tlsConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+				return nil, err
+			},
+		}
+	}
+
+	return append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+}
+
+// serverTLSConfig returns the TLS configuration of the server certificate, verifying the
+// client certificates against the configured CA certificates with the policy of the preset.
+func serverTLSConfig(cfg *commoncfg.MTLS, preset serverPreset) (*tls.Config, error) {
+	if cfg == nil {
+		return nil, ErrPresetRequiresTLS
+	}
+
+	cert, err := commoncfg.LoadMTLSClientCertificate(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("loading server certificate: %w", err)
+	}
+
+	clientCAs, err := commoncfg.LoadMTLSCACertPool(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("loading client CA certificates: %w", err)
+	}
+
+	clientAuth := preset.ClientAuth
+	if clientCAs == nil && clientAuth == tls.VerifyClientCertIfGiven {
+		// without CA certificates the client certificates cannot be verified
+		clientAuth = tls.NoClientCert
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   clientAuth,
+		MinVersion:   preset.MinTLSVersion,
+	}, nil
+}
+
+func recoveryUnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(ctx, info.FullMethod, r)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func recoveryStreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(ss.Context(), info.FullMethod, r)
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+// recoveredError logs the panic of a handler and returns the error sent to the client,
+// without the details of the panic.
+func recoveredError(ctx context.Context, method string, r any) error {
+	slogctx.Error(ctx, "grpc handler panicked", "method", method, "panic", r, "stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,198 @@
+package commongrpc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/creasty/defaults"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/status"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commongrpc"
+)
+
+func TestApplyServerPreset(t *testing.T) {
+	cfg := &commoncfg.GRPCServer{}
+	require.NoError(t, defaults.Set(cfg))
+
+	t.Run("keeps the configuration without preset", func(t *testing.T) {
+		assert.Equal(t, cfg, commongrpc.ApplyServerPreset(cfg))
+	})
+
+	t.Run("applies the preset to the fields with default values", func(t *testing.T) {
+		internal := *cfg
+		internal.Preset = commoncfg.InternalGRPCServerPreset
+
+		result := commongrpc.ApplyServerPreset(&internal)
+
+		assert.Equal(t, 16*commoncfg.MebiByte, result.MaxRecvMsgSize)
+		assert.Equal(t, 16*commoncfg.MebiByte, result.MaxSendMsgSize)
+		assert.Equal(t, 30*time.Second, result.EfPolMinTime)
+		assert.True(t, result.EfPolPermitWithoutStream)
+		assert.Equal(t, 2*time.Minute, result.Attributes.Time)
+		assert.Equal(t, 30*time.Minute, result.Attributes.MaxConnectionAge)
+		assert.Equal(t, commoncfg.InternalGRPCServerPreset, result.Preset)
+
+		// the configuration is not modified
+		assert.Equal(t, cfg.MaxRecvMsgSize, internal.MaxRecvMsgSize)
+	})
+
+	t.Run("keeps the fields overriding the preset", func(t *testing.T) {
+		edge := *cfg
+		edge.Preset = commoncfg.EdgeGRPCServerPreset
+		edge.MaxRecvMsgSize = commoncfg.MebiByte
+		edge.Attributes.Time = 5 * time.Minute
+
+		result := commongrpc.ApplyServerPreset(&edge)
+
+		assert.Equal(t, commoncfg.MebiByte, result.MaxRecvMsgSize)
+		assert.Equal(t, 5*time.Minute, result.Attributes.Time)
+		assert.Equal(t, 4*commoncfg.MebiByte, result.MaxSendMsgSize)
+		assert.Equal(t, 5*time.Minute, result.Attributes.MaxConnectionIdle)
+	})
+}
+
+func TestNewServerPresets(t *testing.T) {
+	certPEM, keyPEM := generateServerCert(t)
+
+	certPool := x509.NewCertPool()
+	require.True(t, certPool.AppendCertsFromPEM(certPEM))
+
+	tlsCfg := &commoncfg.MTLS{
+		Cert:    commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(certPEM)},
+		CertKey: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(keyPEM)},
+	}
+	tlsCreds := credentials.NewTLS(&tls.Config{RootCAs: certPool, MinVersion: tls.VersionTLS13})
+
+	// serve starts a server with a health service panicking for the "panic" service
+	serve := func(t *testing.T, cfg *commoncfg.GRPCServer) string {
+		t.Helper()
+
+		srv := commongrpc.NewServer(t.Context(), cfg)
+		healthpb.RegisterHealthServer(srv, &panickingHealthServer{Server: health.NewServer()})
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		go func() { _ = srv.Serve(lis) }()
+
+		t.Cleanup(srv.Stop)
+
+		return lis.Addr().String()
+	}
+
+	check := func(t *testing.T, addr string, creds credentials.TransportCredentials, service string) error {
+		t.Helper()
+
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+
+		t.Cleanup(func() { _ = conn.Close() })
+
+		ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+		defer cancel()
+
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+
+		return err
+	}
+
+	t.Run("edge serves over TLS and recovers panics", func(t *testing.T) {
+		addr := serve(t, &commoncfg.GRPCServer{Preset: commoncfg.EdgeGRPCServerPreset, TLS: tlsCfg})
+
+		require.NoError(t, check(t, addr, tlsCreds, ""))
+		assert.Equal(t, codes.Internal, status.Code(check(t, addr, tlsCreds, "panic")))
+		assert.Equal(t, codes.Unavailable, status.Code(check(t, addr, insecure.NewCredentials(), "")))
+	})
+
+	t.Run("edge rejects all connections without TLS configuration", func(t *testing.T) {
+		addr := serve(t, &commoncfg.GRPCServer{Preset: commoncfg.EdgeGRPCServerPreset})
+
+		assert.Equal(t, codes.Unavailable, status.Code(check(t, addr, tlsCreds, "")))
+		assert.Equal(t, codes.Unavailable, status.Code(check(t, addr, insecure.NewCredentials(), "")))
+	})
+
+	t.Run("internal requires client certificates", func(t *testing.T) {
+		mtlsCfg := *tlsCfg
+		mtlsCfg.ServerCA = &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(certPEM)}
+
+		addr := serve(t, &commoncfg.GRPCServer{Preset: commoncfg.InternalGRPCServerPreset, TLS: &mtlsCfg})
+
+		assert.Equal(t, codes.Unavailable, status.Code(check(t, addr, tlsCreds, "")))
+
+		clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+		require.NoError(t, err)
+
+		mtlsCreds := credentials.NewTLS(&tls.Config{
+			RootCAs:      certPool,
+			Certificates: []tls.Certificate{clientCert},
+			MinVersion:   tls.VersionTLS12,
+		})
+		require.NoError(t, check(t, addr, mtlsCreds, ""))
+	})
+
+	t.Run("sidecar serves without TLS", func(t *testing.T) {
+		addr := serve(t, &commoncfg.GRPCServer{Preset: commoncfg.SidecarGRPCServerPreset})
+
+		require.NoError(t, check(t, addr, insecure.NewCredentials(), ""))
+	})
+}
+
+// panickingHealthServer panics on checks of the "panic" service.
+type panickingHealthServer struct {
+	*health.Server
+}
+
+func (s *panickingHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.GetService() == "panic" {
+		panic("check failed")
+	}
+
+	return s.Server.Check(ctx, req)
+}
+
+// generateServerCert creates a self-signed certificate for 127.0.0.1, usable by servers and clients.
+func generateServerCert(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	certTmpl := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, &certTmpl, &certTmpl, &priv.PublicKey, priv)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
+
+	return certPEM, keyPEM
+}
@@ -0,0 +1,226 @@
+package commongrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// retryBudgetBuckets is the number of buckets the sliding window of a RetryBudget is split into.
+const retryBudgetBuckets = 10
+
+var (
+	// ErrInvalidRetryCode is returned when a retryable code of the configuration is not a gRPC status code.
+	ErrInvalidRetryCode = errors.New("invalid retryable grpc status code")
+	// ErrInvalidRetryBudgetWindow is returned when the window of a retry budget is too short to be split into buckets.
+	ErrInvalidRetryBudgetWindow = errors.New("invalid retry budget window")
+)
+
+// RetryBudget limits the retries of a client to a share of its calls over a sliding
+// window, e.g. at most 20% of the calls of the last 10 seconds may be retried. It is
+// shared by all methods of a client, so retries cannot turn a partial backend outage
+// into a retry storm.
+//
+// RetryBudget is safe for concurrent use.
+type RetryBudget struct {
+	ratio      float64
+	minRetries int
+	bucketSize time.Duration
+
+	mu      sync.Mutex
+	buckets [retryBudgetBuckets]retryBudgetBucket
+}
+
+type retryBudgetBucket struct {
+	start    time.Time
+	calls    int
+	retries  int
+	occupied bool
+}
+
+// NewRetryBudget creates a retry budget allowing MinRetries plus Ratio times the number
+// of calls as retries within the sliding Window, which defaults to 10 seconds.
+//
+// It returns ErrInvalidRetryBudgetWindow if the window is shorter than 10ns, the number of
+// buckets the window is split into.
+func NewRetryBudget(cfg commoncfg.GRPCRetryBudget) (*RetryBudget, error) {
+	window := cfg.Window
+	if window <= 0 {
+		window = 10 * time.Second
+	}
+
+	if window < retryBudgetBuckets {
+		return nil, fmt.Errorf("%w: %s is shorter than %dns", ErrInvalidRetryBudgetWindow, window, retryBudgetBuckets)
+	}
+
+	return &RetryBudget{
+		ratio:      cfg.Ratio,
+		minRetries: cfg.MinRetries,
+		bucketSize: window / retryBudgetBuckets,
+	}, nil
+}
+
+// RecordCall records a call, which increases the number of allowed retries.
+func (b *RetryBudget) RecordCall() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bucket().calls++
+}
+
+// TryRetry reports whether a retry is within the budget and records it if so.
+func (b *RetryBudget) TryRetry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current := b.bucket()
+
+	calls, retries := 0, 0
+
+	for _, bucket := range b.buckets {
+		if bucket.occupied {
+			calls += bucket.calls
+			retries += bucket.retries
+		}
+	}
+
+	if float64(retries) >= float64(b.minRetries)+b.ratio*float64(calls) {
+		return false
+	}
+
+	current.retries++
+
+	return true
+}
+
+// bucket returns the bucket of the current time, resetting buckets which left the window.
+func (b *RetryBudget) bucket() *retryBudgetBucket {
+	start := time.Now().Truncate(b.bucketSize)
+	current := &b.buckets[int(start.UnixNano()/int64(b.bucketSize))%retryBudgetBuckets]
+
+	if !current.occupied || !current.start.Equal(start) {
+		*current = retryBudgetBucket{start: start, occupied: true}
+	}
+
+	windowStart := start.Add(-b.bucketSize * (retryBudgetBuckets - 1))
+	for i := range b.buckets {
+		if b.buckets[i].occupied && b.buckets[i].start.Before(windowStart) {
+			b.buckets[i] = retryBudgetBucket{}
+		}
+	}
+
+	return current
+}
+
+// RetryUnaryClientInterceptor returns an interceptor retrying unary calls failing with one of
+// the retryable status codes, with an exponential backoff with jitter. A call is only retried
+// while the budget allows it; otherwise the error of the last attempt is returned. The
+// interceptor does not retry calls whose context is done.
+func RetryUnaryClientInterceptor(cfg commoncfg.GRPCRetry, budget *RetryBudget) (grpc.UnaryClientInterceptor, error) {
+	retryable, err := parseRetryableCodes(cfg.RetryableCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	if budget == nil {
+		budget, err = NewRetryBudget(cfg.Budget)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		budget.RecordCall()
+
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= cfg.MaxAttempts || ctx.Err() != nil {
+				return err
+			}
+
+			if _, ok := retryable[status.Code(err)]; !ok || !budget.TryRetry() {
+				return err
+			}
+
+			timer := time.NewTimer(retryBackoff(cfg, attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}, nil
+}
+
+// retryBackoff returns a random backoff of up to InitialBackoff * 2^(attempt-1), capped by MaxBackoff.
+func retryBackoff(cfg commoncfg.GRPCRetry, attempt int) time.Duration {
+	backoff := cfg.InitialBackoff
+	for i := 1; i < attempt && (cfg.MaxBackoff <= 0 || backoff < cfg.MaxBackoff); i++ {
+		backoff *= 2
+	}
+
+	if cfg.MaxBackoff > 0 && backoff > cfg.MaxBackoff {
+		backoff = cfg.MaxBackoff
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(backoff))) //nolint:gosec
+}
+
+// parseRetryableCodes parses the names of status codes, e.g. UNAVAILABLE or unavailable.
+func parseRetryableCodes(names []string) (map[codes.Code]struct{}, error) {
+	if len(names) == 0 {
+		names = []string{"UNAVAILABLE"}
+	}
+
+	retryable := make(map[codes.Code]struct{}, len(names))
+
+	for _, name := range names {
+		code, err := parseCode(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRetryCode, name)
+		}
+
+		retryable[code] = struct{}{}
+	}
+
+	return retryable, nil
+}
+
+// parseCode parses the name of a status code, e.g. UNAVAILABLE or unavailable.
+func parseCode(name string) (codes.Code, error) {
+	var code codes.Code
+
+	err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(strings.TrimSpace(name)))))
+
+	return code, err
+}
+
+// retryDialOptions returns the dial options retrying the unary calls of the client.
+func retryDialOptions(cfg commoncfg.GRPCRetry) ([]grpc.DialOption, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	interceptor, err := RetryUnaryClientInterceptor(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return []grpc.DialOption{grpc.WithChainUnaryInterceptor(interceptor)}, nil
+}
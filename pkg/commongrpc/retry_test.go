@@ -0,0 +1,167 @@
+package commongrpc_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/status"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commongrpc"
+)
+
+func TestRetryBudget(t *testing.T) {
+	t.Run("allows retries relative to the calls", func(t *testing.T) {
+		budget, err := commongrpc.NewRetryBudget(commoncfg.GRPCRetryBudget{Ratio: 0.2, Window: time.Minute})
+		require.NoError(t, err)
+
+		for range 10 {
+			budget.RecordCall()
+		}
+
+		assert.True(t, budget.TryRetry())
+		assert.True(t, budget.TryRetry())
+		assert.False(t, budget.TryRetry())
+	})
+
+	t.Run("allows the minimum retries without calls", func(t *testing.T) {
+		budget, err := commongrpc.NewRetryBudget(commoncfg.GRPCRetryBudget{MinRetries: 2, Window: time.Minute})
+		require.NoError(t, err)
+
+		assert.True(t, budget.TryRetry())
+		assert.True(t, budget.TryRetry())
+		assert.False(t, budget.TryRetry())
+	})
+
+	t.Run("rejects windows too short to be split into buckets", func(t *testing.T) {
+		budget, err := commongrpc.NewRetryBudget(commoncfg.GRPCRetryBudget{MinRetries: 1, Window: 9 * time.Nanosecond})
+		require.ErrorIs(t, err, commongrpc.ErrInvalidRetryBudgetWindow)
+		assert.Nil(t, budget)
+
+		_, err = commongrpc.RetryUnaryClientInterceptor(commoncfg.GRPCRetry{Budget: commoncfg.GRPCRetryBudget{Window: time.Nanosecond}}, nil)
+		require.ErrorIs(t, err, commongrpc.ErrInvalidRetryBudgetWindow)
+	})
+
+	t.Run("forgets retries which left the window", func(t *testing.T) {
+		budget, err := commongrpc.NewRetryBudget(commoncfg.GRPCRetryBudget{MinRetries: 1, Window: 50 * time.Millisecond})
+		require.NoError(t, err)
+
+		assert.True(t, budget.TryRetry())
+		assert.False(t, budget.TryRetry())
+
+		time.Sleep(60 * time.Millisecond)
+
+		assert.True(t, budget.TryRetry())
+	})
+}
+
+func TestRetryUnaryClientInterceptor(t *testing.T) {
+	cfg := commoncfg.GRPCRetry{
+		Enabled:        true,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		RetryableCodes: []string{"UNAVAILABLE", "resource_exhausted"},
+	}
+
+	failingInvoker := func(code codes.Code, attempts *int) grpc.UnaryInvoker {
+		return func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			*attempts++
+			return status.Error(code, "failed")
+		}
+	}
+
+	t.Run("retries retryable codes up to the max attempts", func(t *testing.T) {
+		budget, err := commongrpc.NewRetryBudget(commoncfg.GRPCRetryBudget{MinRetries: 10, Window: time.Minute})
+		require.NoError(t, err)
+
+		interceptor, err := commongrpc.RetryUnaryClientInterceptor(cfg, budget)
+		require.NoError(t, err)
+
+		attempts := 0
+		err = interceptor(t.Context(), "/svc/Method", nil, nil, nil, failingInvoker(codes.ResourceExhausted, &attempts))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("does not retry other codes", func(t *testing.T) {
+		interceptor, err := commongrpc.RetryUnaryClientInterceptor(cfg, nil)
+		require.NoError(t, err)
+
+		attempts := 0
+		err = interceptor(t.Context(), "/svc/Method", nil, nil, nil, failingInvoker(codes.InvalidArgument, &attempts))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("stops retrying when the budget is exhausted", func(t *testing.T) {
+		budget, err := commongrpc.NewRetryBudget(commoncfg.GRPCRetryBudget{MinRetries: 1, Window: time.Minute})
+		require.NoError(t, err)
+
+		interceptor, err := commongrpc.RetryUnaryClientInterceptor(cfg, budget)
+		require.NoError(t, err)
+
+		attempts := 0
+		err = interceptor(t.Context(), "/svc/Method", nil, nil, nil, failingInvoker(codes.Unavailable, &attempts))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 2, attempts, "one retry within the budget")
+
+		// the budget is shared by all methods
+		attempts = 0
+		_ = interceptor(t.Context(), "/svc/Other", nil, nil, nil, failingInvoker(codes.Unavailable, &attempts))
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("errors on invalid codes", func(t *testing.T) {
+		_, err := commongrpc.RetryUnaryClientInterceptor(commoncfg.GRPCRetry{RetryableCodes: []string{"SOMETIMES"}}, nil)
+		assert.ErrorIs(t, err, commongrpc.ErrInvalidRetryCode)
+	})
+}
+
+func TestNewClientRetries(t *testing.T) {
+	calls := &atomic.Int64{}
+	srv := grpc.NewServer(grpc.UnaryInterceptor(
+		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if calls.Add(1) == 1 {
+				return nil, status.Error(codes.Unavailable, "warming up")
+			}
+
+			return handler(ctx, req)
+		},
+	))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() { _ = srv.Serve(lis) }()
+
+	t.Cleanup(srv.Stop)
+
+	conn, err := commongrpc.NewClient(&commoncfg.GRPCClient{
+		Address: lis.Addr().String(),
+		Retry: commoncfg.GRPCRetry{
+			Enabled:        true,
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+			Budget:         commoncfg.GRPCRetryBudget{Ratio: 0.2, MinRetries: 1, Window: time.Minute},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = healthpb.NewHealthClient(conn).Check(t.Context(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), calls.Load())
+}
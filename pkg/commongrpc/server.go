@@ -17,11 +17,17 @@ import (
 
 // NewServer creates and configures a new gRPC server instance.
 //
-// It applies keepalive enforcement and server parameters, maximum receive message size,
+// It applies keepalive enforcement and server parameters, maximum receive and send message sizes,
 // and OpenTelemetry stats handlers. Additional grpc.ServerOption values can be provided.
 //
 // If reflection is enabled in the config, the server will register the reflection service.
 // If health checks are enabled, the server will register the gRPC health service.
+// If fault injection is enabled, the server will inject the configured faults (see FaultInjector).
+//
+// The preset of the config bundles the keepalive, message size, interceptor and TLS settings of
+// a deployment topology, and the fields set to another value than their default override it
+// (see ApplyServerPreset). If TLS is configured, the server serves over TLS. A server whose TLS
+// configuration cannot be loaded rejects all connections.
 //
 // Parameters:
 //   - ctx: Context for logging and server setup
@@ -31,7 +37,9 @@ import (
 // Returns:
 //   - *grpc.Server: The configured gRPC server instance
 func NewServer(ctx context.Context, cfg *commoncfg.GRPCServer, serverOptions ...grpc.ServerOption) *grpc.Server {
-	opts := make([]grpc.ServerOption, 0, 4+len(serverOptions))
+	cfg = ApplyServerPreset(cfg)
+
+	opts := make([]grpc.ServerOption, 0, 6+len(serverOptions))
 
 	opts = append(opts,
 		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
@@ -45,10 +53,22 @@ func NewServer(ctx context.Context, cfg *commoncfg.GRPCServer, serverOptions ...
 			Time:                  cfg.Attributes.Time,
 			Timeout:               cfg.Attributes.Timeout,
 		}),
-		grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize),
+		grpc.MaxRecvMsgSize(int(cfg.MaxRecvMsgSize)),
 		grpc.StatsHandler(otlp.NewServerHandler()),
 	)
 
+	if cfg.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(int(cfg.MaxSendMsgSize)))
+	}
+
+	opts = append(opts, presetServerOptions(ctx, cfg)...)
+
+	faultOpts, serverOptions, err := faultServerOptions(cfg.FaultInjection, serverOptions)
+	if err != nil {
+		slogctx.Error(ctx, "grpc server fault injection disabled", "error", err)
+	}
+
+	opts = append(opts, faultOpts...)
 	opts = append(opts, serverOptions...)
 
 	grpcServer := grpc.NewServer(opts...)
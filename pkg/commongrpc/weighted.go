@@ -0,0 +1,195 @@
+package commongrpc
+
+import (
+	"context"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+const (
+	// WeightedTargetBalancerName is the name of the load balancing policy routing
+	// the calls to the weighted targets of a gRPC client (see commoncfg.GRPCTarget).
+	WeightedTargetBalancerName = "commongrpc_weighted_target"
+
+	// DefTargetOverrideHeader is the default metadata key forcing calls to a target.
+	DefTargetOverrideHeader = "x-grpc-target"
+
+	weightedTargetScheme = "commongrpc-weighted"
+)
+
+// weightedTargetKey is the key of the target in the balancer attributes of an address.
+type weightedTargetKey struct{}
+
+// weightedTarget is stored in the balancer attributes of every resolved address.
+type weightedTarget struct {
+	name           string
+	weight         int
+	overrideHeader string
+}
+
+// Equal is used by the attributes package to compare the values.
+func (t weightedTarget) Equal(o any) bool {
+	other, ok := o.(weightedTarget)
+	return ok && t == other
+}
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(WeightedTargetBalancerName, weightedPickerBuilder{}, base.Config{}))
+}
+
+// WithTargetOverride returns a context forcing the outgoing calls to the target with the
+// given name, regardless of its weight. It uses the DefTargetOverrideHeader metadata key,
+// so it only applies to clients which do not configure another TargetOverrideHeader.
+func WithTargetOverride(ctx context.Context, target string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, DefTargetOverrideHeader, target)
+}
+
+// weightedDialTarget returns the dial target and options routing the calls to the weighted targets.
+func weightedDialTarget(cfg *commoncfg.GRPCClient) (string, []grpc.DialOption) {
+	header := strings.ToLower(cfg.TargetOverrideHeader)
+	if header == "" {
+		header = DefTargetOverrideHeader
+	}
+
+	addrs := make([]resolver.Address, 0, len(cfg.Targets))
+	for _, target := range cfg.Targets {
+		// the certificate of a target is verified against its own host,
+		// not the authority of the channel
+		serverName, _, err := net.SplitHostPort(target.Address)
+		if err != nil {
+			serverName = target.Address
+		}
+
+		addrs = append(addrs, resolver.Address{
+			Addr:       target.Address,
+			ServerName: serverName,
+			BalancerAttributes: attributes.New(weightedTargetKey{}, weightedTarget{
+				name:           target.Name,
+				weight:         target.Weight,
+				overrideHeader: header,
+			}),
+		})
+	}
+
+	return weightedTargetScheme + ":///targets", []grpc.DialOption{
+		grpc.WithResolvers(&staticResolverBuilder{state: resolver.State{Addresses: addrs}}),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"` + WeightedTargetBalancerName + `":{}}]}`),
+	}
+}
+
+// staticResolverBuilder resolves to a fixed list of addresses. Unlike the manual
+// resolver it can be shared by several connections, e.g. of a connection pool.
+type staticResolverBuilder struct {
+	state resolver.State
+}
+
+func (b *staticResolverBuilder) Build(_ resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	err := cc.UpdateState(b.state)
+	if err != nil {
+		return nil, err
+	}
+
+	return staticResolver{}, nil
+}
+
+func (b *staticResolverBuilder) Scheme() string {
+	return weightedTargetScheme
+}
+
+type staticResolver struct{}
+
+func (staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (staticResolver) Close() {}
+
+type weightedPickerBuilder struct{}
+
+// Build groups the ready sub connections by target.
+func (weightedPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	p := &weightedPicker{targets: make(map[string]*pickerTarget)}
+
+	for sc, scInfo := range info.ReadySCs {
+		target, _ := scInfo.Address.BalancerAttributes.Value(weightedTargetKey{}).(weightedTarget)
+		p.overrideHeader = target.overrideHeader
+
+		t, ok := p.targets[target.name]
+		if !ok {
+			t = &pickerTarget{weight: target.weight}
+			p.targets[target.name] = t
+			p.order = append(p.order, t)
+			p.totalWeight += target.weight
+		}
+
+		t.subConns = append(t.subConns, sc)
+	}
+
+	return p
+}
+
+// weightedPicker picks a target by weight and the sub connections of a target round robin.
+type weightedPicker struct {
+	targets        map[string]*pickerTarget
+	order          []*pickerTarget
+	totalWeight    int
+	overrideHeader string
+}
+
+type pickerTarget struct {
+	weight   int
+	subConns []balancer.SubConn
+	next     atomic.Uint32
+}
+
+func (p *weightedPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	if p.overrideHeader != "" {
+		if md, ok := metadata.FromOutgoingContext(info.Ctx); ok {
+			if values := md.Get(p.overrideHeader); len(values) > 0 {
+				target, ok := p.targets[values[len(values)-1]]
+				if !ok {
+					return balancer.PickResult{}, status.Errorf(codes.Unavailable,
+						"target %q requested by %s is not available", values[len(values)-1], p.overrideHeader)
+				}
+
+				return target.pick(), nil
+			}
+		}
+	}
+
+	if p.totalWeight == 0 {
+		// only targets without weight are ready, distribute the calls evenly
+		return p.order[rand.IntN(len(p.order))].pick(), nil //nolint:gosec
+	}
+
+	n := rand.IntN(p.totalWeight) //nolint:gosec
+	for _, target := range p.order {
+		if n < target.weight {
+			return target.pick(), nil
+		}
+
+		n -= target.weight
+	}
+
+	return p.order[len(p.order)-1].pick(), nil
+}
+
+func (t *pickerTarget) pick() balancer.PickResult {
+	i := t.next.Add(1) - 1
+	return balancer.PickResult{SubConn: t.subConns[int(i)%len(t.subConns)]}
+}
@@ -0,0 +1,231 @@
+package commongrpc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commongrpc"
+)
+
+// startCountingServer starts a gRPC health server counting the served calls.
+func startCountingServer(t *testing.T) (string, *atomic.Int64) {
+	t.Helper()
+
+	calls := &atomic.Int64{}
+	srv := grpc.NewServer(grpc.UnaryInterceptor(
+		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			calls.Add(1)
+			return handler(ctx, req)
+		},
+	))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() { _ = srv.Serve(lis) }()
+
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String(), calls
+}
+
+func newWeightedClient(t *testing.T, cfg *commoncfg.GRPCClient) healthpb.HealthClient {
+	t.Helper()
+
+	conn, err := commongrpc.NewClient(cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+// waitForTarget waits until the target is ready to serve calls forced to it.
+func waitForTarget(t *testing.T, client healthpb.HealthClient, header, target string) {
+	t.Helper()
+
+	ctx := metadata.AppendToOutgoingContext(t.Context(), header, target)
+	require.Eventually(t, func() bool {
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNewClientWeightedTargets(t *testing.T) {
+	stableAddr, stableCalls := startCountingServer(t)
+	canaryAddr, canaryCalls := startCountingServer(t)
+
+	client := newWeightedClient(t, &commoncfg.GRPCClient{
+		Targets: []commoncfg.GRPCTarget{
+			{Name: "stable", Address: stableAddr, Weight: 100},
+			{Name: "canary", Address: canaryAddr, Weight: 0},
+		},
+	})
+
+	waitForTarget(t, client, commongrpc.DefTargetOverrideHeader, "stable")
+	waitForTarget(t, client, commongrpc.DefTargetOverrideHeader, "canary")
+	stableCalls.Store(0)
+	canaryCalls.Store(0)
+
+	t.Run("weights are honored", func(t *testing.T) {
+		for range 50 {
+			_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int64(50), stableCalls.Load())
+		assert.Equal(t, int64(0), canaryCalls.Load())
+	})
+
+	t.Run("override header forces the target", func(t *testing.T) {
+		ctx := commongrpc.WithTargetOverride(t.Context(), "canary")
+
+		for range 10 {
+			_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int64(10), canaryCalls.Load())
+	})
+
+	t.Run("unknown override target is unavailable", func(t *testing.T) {
+		ctx := commongrpc.WithTargetOverride(t.Context(), "unknown")
+
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestNewClientWeightedTargetsSplit(t *testing.T) {
+	stableAddr, stableCalls := startCountingServer(t)
+	canaryAddr, canaryCalls := startCountingServer(t)
+
+	client := newWeightedClient(t, &commoncfg.GRPCClient{
+		Targets: []commoncfg.GRPCTarget{
+			{Name: "stable", Address: stableAddr, Weight: 50},
+			{Name: "canary", Address: canaryAddr, Weight: 50},
+		},
+		TargetOverrideHeader: "X-Route",
+	})
+
+	waitForTarget(t, client, "x-route", "stable")
+	waitForTarget(t, client, "x-route", "canary")
+
+	for range 400 {
+		_, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+	}
+
+	assert.Greater(t, stableCalls.Load(), int64(100))
+	assert.Greater(t, canaryCalls.Load(), int64(100))
+}
+
+func TestNewClientWithoutAddressOrTargets(t *testing.T) {
+	_, err := commongrpc.NewClient(&commoncfg.GRPCClient{})
+	require.ErrorIs(t, err, commongrpc.ErrEmptyAddress)
+}
+
+func TestNewClientWeightedTargetsTLS(t *testing.T) {
+	// every target presents a certificate valid only for the host of its own address
+	localhostCert, localhostKey := generateTargetCert(t, &x509.Certificate{DNSNames: []string{"localhost"}})
+	ipCert, ipKey := generateTargetCert(t, &x509.Certificate{IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)}})
+
+	startTLSServer := func(t *testing.T, certPEM, keyPEM []byte) string {
+		t.Helper()
+
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		require.NoError(t, err)
+
+		srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		})))
+		healthpb.RegisterHealthServer(srv, health.NewServer())
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		go func() { _ = srv.Serve(lis) }()
+
+		t.Cleanup(srv.Stop)
+
+		return lis.Addr().String()
+	}
+
+	embedded := func(value []byte) commoncfg.SourceRef {
+		return commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(value)}
+	}
+
+	_, localhostPort, err := net.SplitHostPort(startTLSServer(t, localhostCert, localhostKey))
+	require.NoError(t, err)
+
+	ipAddr := startTLSServer(t, ipCert, ipKey)
+
+	client := newWeightedClient(t, &commoncfg.GRPCClient{
+		Targets: []commoncfg.GRPCTarget{
+			{Name: "stable", Address: net.JoinHostPort("localhost", localhostPort), Weight: 50},
+			{Name: "canary", Address: ipAddr, Weight: 50},
+		},
+		SecretRef: &commoncfg.SecretRef{
+			Type: commoncfg.MTLSSecretType,
+			MTLS: commoncfg.MTLS{
+				Cert:    embedded(localhostCert),
+				CertKey: embedded(localhostKey),
+				RootCAs: []commoncfg.SourceRef{embedded(localhostCert), embedded(ipCert)},
+			},
+		},
+	})
+
+	waitForTarget(t, client, commongrpc.DefTargetOverrideHeader, "stable")
+	waitForTarget(t, client, commongrpc.DefTargetOverrideHeader, "canary")
+}
+
+// generateTargetCert creates a self-signed certificate for the names of the template.
+func generateTargetCert(t *testing.T, tmpl *x509.Certificate) ([]byte, []byte) {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.Subject = pkix.Name{CommonName: "weighted target"}
+	tmpl.NotBefore = time.Now().Add(-time.Minute)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	tmpl.BasicConstraintsValid = true
+	tmpl.IsCA = true
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	require.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(priv)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
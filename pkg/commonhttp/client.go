@@ -112,3 +112,19 @@ func NewHTTPClient(cfg *commoncfg.HTTPClient) (*http.Client, error) {
 
 	return client, nil
 }
+
+// SetBaseTransport replaces the transport which sends the requests of a client created by
+// NewHTTPClient or the NewClientFrom functions, keeping the authentication round trippers,
+// e.g. to send the authenticated requests to a mock transport in tests (see httptestkit).
+func SetBaseTransport(client *http.Client, base http.RoundTripper) {
+	switch t := client.Transport.(type) {
+	case *clientOAuth2RoundTripper:
+		t.Next = base
+	case *clientAPITokenRoundTripper:
+		t.Next = base
+	case *clientBasicRoundTripper:
+		t.Next = base
+	default:
+		client.Transport = base
+	}
+}
@@ -404,3 +404,36 @@ func checkClient(t *testing.T, client *http.Client, cfg commoncfg.HTTPClient) {
 		}
 	}
 }
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestSetBaseTransport(t *testing.T) {
+	client, err := commonhttp.NewHTTPClient(&commoncfg.HTTPClient{
+		APIToken: &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "token"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var authorization string
+
+	commonhttp.SetBaseTransport(client, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		authorization = req.Header.Get("Authorization")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	}))
+
+	resp, err := client.Get("https://example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if authorization != "Api-Token token" {
+		t.Errorf("expected the authentication to be kept, got %q", authorization)
+	}
+}
@@ -1,3 +1,4 @@
 // Package commonhttp provides utilities to create HTTP clients
-// configured with OAuth2 credentials and optional mutual TLS (mTLS).
+// configured with OAuth2 credentials and optional mutual TLS (mTLS),
+// and HTTP servers supporting graceful shutdown with request draining.
 package commonhttp
@@ -0,0 +1,404 @@
+// Package httptestkit provides a programmable http.RoundTripper to unit test code sending
+// HTTP requests without starting an httptest server for every case.
+//
+// A Transport answers the requests matching its expectations with canned responses,
+// optionally after a delay or with an injected error, and records all requests so the
+// headers, credentials and bodies sent can be asserted:
+//
+//	transport := httptestkit.NewTransport()
+//	transport.Expect(http.MethodGet, "/v1/keys").ReplyJSON(http.StatusOK, keys)
+//
+//	client, _ := commonhttp.NewHTTPClient(cfg)
+//	transport.Install(client) // keeps the authentication of the client
+//
+//	// ... run the code under test
+//
+//	transport.AssertExpectations(t)
+//	req, _ := transport.LastRequest()
+//	assert.Equal(t, "Api-Token secret", req.Header.Get("Authorization"))
+package httptestkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/commonhttp"
+)
+
+// ErrUnexpectedRequest is returned for requests which match no expectation.
+var ErrUnexpectedRequest = errors.New("unexpected request")
+
+// Transport is a programmable http.RoundTripper. Requests are answered by the first
+// expectation which matches them and is not exhausted, in the order of registration.
+//
+// Transport is safe for concurrent use.
+type Transport struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	requests     []RecordedRequest
+	unexpected   []RecordedRequest
+}
+
+// NewTransport creates a transport without expectations.
+func NewTransport() *Transport {
+	return &Transport{}
+}
+
+// Client returns an HTTP client sending its requests to the transport.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Install sends the requests of a client created by the commonhttp client factories to the
+// transport; the requests are recorded with the credentials added by the client.
+func (t *Transport) Install(client *http.Client) {
+	commonhttp.SetBaseTransport(client, t)
+}
+
+// Expect registers an expectation for requests with the method, or any method if it is
+// empty, and the URL. A URL starting with a slash is matched against the path of the
+// requests, any other URL against the complete URL. The expectation matches once and
+// replies 200 OK with an empty body, unless configured otherwise.
+func (t *Transport) Expect(method, url string) *Expectation {
+	e := &Expectation{
+		method: method,
+		url:    url,
+		times:  1,
+		status: http.StatusOK,
+		header: make(http.Header),
+	}
+
+	t.mu.Lock()
+	t.expectations = append(t.expectations, e)
+	t.mu.Unlock()
+
+	return e
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := record(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+
+	t.requests = append(t.requests, recorded)
+
+	var (
+		match *Expectation
+		call  int
+	)
+
+	for _, e := range t.expectations {
+		if e.exhausted() || !e.matches(recorded) {
+			continue
+		}
+
+		e.calls++
+		match, call = e, e.calls
+
+		break
+	}
+
+	if match == nil {
+		t.unexpected = append(t.unexpected, recorded)
+	}
+
+	t.mu.Unlock()
+
+	if match == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrUnexpectedRequest, req.Method, req.URL)
+	}
+
+	return match.reply(req, call)
+}
+
+// Requests returns all requests sent to the transport, in the order they were sent.
+func (t *Transport) Requests() []RecordedRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.requests)
+}
+
+// LastRequest returns the last request sent to the transport.
+func (t *Transport) LastRequest() (RecordedRequest, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.requests) == 0 {
+		return RecordedRequest{}, false
+	}
+
+	return t.requests[len(t.requests)-1], true
+}
+
+// Reset removes all expectations and recorded requests.
+func (t *Transport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expectations = nil
+	t.requests = nil
+	t.unexpected = nil
+}
+
+// AssertExpectations reports an error for each expectation which was not met and for each
+// request which matched no expectation. It returns whether all expectations were met.
+func (t *Transport) AssertExpectations(tb testing.TB) bool {
+	tb.Helper()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ok := true
+
+	for _, e := range t.expectations {
+		if e.times > 0 && e.calls < e.times {
+			tb.Errorf("httptestkit: expected %s %s %d times, got %d calls", e.methodName(), e.url, e.times, e.calls)
+
+			ok = false
+		}
+	}
+
+	for _, r := range t.unexpected {
+		tb.Errorf("httptestkit: unexpected request %s %s", r.Method, r.URL)
+
+		ok = false
+	}
+
+	return ok
+}
+
+// RecordedRequest is a request sent to a Transport.
+type RecordedRequest struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+}
+
+// BasicAuth returns the credentials of the basic Authorization header.
+func (r RecordedRequest) BasicAuth() (string, string, bool) {
+	return (&http.Request{Header: r.Header}).BasicAuth()
+}
+
+// BearerToken returns the token of the bearer Authorization header.
+func (r RecordedRequest) BearerToken() (string, bool) {
+	return strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// record reads the body of the request, which is replaced so it can still be read.
+func record(req *http.Request) (RecordedRequest, error) {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL,
+		Header: req.Header.Clone(),
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return recorded, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+
+	if err != nil {
+		return RecordedRequest{}, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	recorded.Body = body
+
+	return recorded, nil
+}
+
+// Expectation describes the requests it matches and the response they get.
+// The methods configuring it must be called before the requests are sent.
+type Expectation struct {
+	method   string
+	url      string
+	matchers []func(RecordedRequest) bool
+	times    int
+	calls    int
+
+	delay     time.Duration
+	err       error
+	failFirst int
+	replyFunc func(*http.Request) (*http.Response, error)
+	status    int
+	header    http.Header
+	body      []byte
+}
+
+// MatchHeader restricts the expectation to requests with the header value.
+func (e *Expectation) MatchHeader(key, value string) *Expectation {
+	return e.Match(func(r RecordedRequest) bool {
+		return slices.Contains(r.Header.Values(key), value)
+	})
+}
+
+// MatchQuery restricts the expectation to requests with the query parameter value.
+func (e *Expectation) MatchQuery(key, value string) *Expectation {
+	return e.Match(func(r RecordedRequest) bool {
+		return slices.Contains(r.URL.Query()[key], value)
+	})
+}
+
+// MatchBody restricts the expectation to requests with the body.
+func (e *Expectation) MatchBody(body string) *Expectation {
+	return e.Match(func(r RecordedRequest) bool {
+		return string(r.Body) == body
+	})
+}
+
+// Match restricts the expectation to requests accepted by the matcher.
+func (e *Expectation) Match(matcher func(RecordedRequest) bool) *Expectation {
+	e.matchers = append(e.matchers, matcher)
+	return e
+}
+
+// Times sets how often the expectation matches; AnyTimes lifts the limit.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// AnyTimes lets the expectation match any number of requests, including none.
+func (e *Expectation) AnyTimes() *Expectation {
+	e.times = 0
+	return e
+}
+
+// Reply sets the status and body of the response.
+func (e *Expectation) Reply(status int, body string) *Expectation {
+	e.status = status
+	e.body = []byte(body)
+
+	return e
+}
+
+// ReplyJSON sets the status of the response and its body to the JSON encoding of v.
+func (e *Expectation) ReplyJSON(status int, v any) *Expectation {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("httptestkit: encoding the response body: %v", err))
+	}
+
+	e.header.Set("Content-Type", "application/json")
+
+	return e.Reply(status, string(body))
+}
+
+// ReplyHeader adds a header to the response.
+func (e *Expectation) ReplyHeader(key, value string) *Expectation {
+	e.header.Add(key, value)
+	return e
+}
+
+// ReplyFunc lets fn create the responses, e.g. depending on the request.
+func (e *Expectation) ReplyFunc(fn func(*http.Request) (*http.Response, error)) *Expectation {
+	e.replyFunc = fn
+	return e
+}
+
+// Delay delays the responses, e.g. to test timeouts. A request whose context is done
+// during the delay fails with the context error.
+func (e *Expectation) Delay(d time.Duration) *Expectation {
+	e.delay = d
+	return e
+}
+
+// Fail lets all matching requests fail with the error, e.g. a connection error.
+func (e *Expectation) Fail(err error) *Expectation {
+	return e.FailFirst(-1, err)
+}
+
+// FailFirst lets the first n matching requests fail with the error and replies to the
+// following ones, e.g. to test retries.
+func (e *Expectation) FailFirst(n int, err error) *Expectation {
+	e.failFirst = n
+	e.err = err
+
+	return e
+}
+
+func (e *Expectation) exhausted() bool {
+	return e.times > 0 && e.calls >= e.times
+}
+
+func (e *Expectation) matches(r RecordedRequest) bool {
+	if e.method != "" && e.method != r.Method {
+		return false
+	}
+
+	if strings.HasPrefix(e.url, "/") {
+		if e.url != r.URL.Path {
+			return false
+		}
+	} else if e.url != r.URL.String() {
+		return false
+	}
+
+	for _, matcher := range e.matchers {
+		if !matcher(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *Expectation) methodName() string {
+	if e.method == "" {
+		return "*"
+	}
+
+	return e.method
+}
+
+// reply creates the response of the call-th matching request.
+func (e *Expectation) reply(req *http.Request, call int) (*http.Response, error) {
+	if e.delay > 0 {
+		timer := time.NewTimer(e.delay)
+		defer timer.Stop()
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	if e.err != nil && (e.failFirst < 0 || call <= e.failFirst) {
+		return nil, e.err
+	}
+
+	if e.replyFunc != nil {
+		return e.replyFunc(req)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.status, http.StatusText(e.status)),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}, nil
+}
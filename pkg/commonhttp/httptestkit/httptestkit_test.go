@@ -0,0 +1,192 @@
+package httptestkit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commonhttp"
+	"github.com/openkcm/common-sdk/pkg/commonhttp/httptestkit"
+)
+
+// recordingTB records the errors reported by AssertExpectations.
+type recordingTB struct {
+	testing.TB
+
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestTransportReplies(t *testing.T) {
+	transport := httptestkit.NewTransport()
+	transport.Expect(http.MethodGet, "/keys").
+		MatchQuery("tenant", "t1").
+		ReplyHeader("ETag", `"v1"`).
+		ReplyJSON(http.StatusOK, map[string]string{"id": "k1"})
+	transport.Expect(http.MethodPost, "https://kms.example.com/keys").
+		MatchHeader("Content-Type", "application/json").
+		MatchBody(`{"name":"k2"}`).
+		Reply(http.StatusCreated, "created")
+
+	client := transport.Client()
+
+	resp, err := client.Get("https://kms.example.com/keys?tenant=t1")
+	require.NoError(t, err)
+
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"id":"k1"}`, string(body))
+	assert.Equal(t, `"v1"`, resp.Header.Get("ETag"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	resp, err = client.Post("https://kms.example.com/keys", "application/json", strings.NewReader(`{"name":"k2"}`))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	assert.True(t, transport.AssertExpectations(t))
+
+	last, ok := transport.LastRequest()
+	require.True(t, ok)
+	assert.Equal(t, `{"name":"k2"}`, string(last.Body))
+	assert.Len(t, transport.Requests(), 2)
+}
+
+func TestTransportUnexpectedAndUnmetExpectations(t *testing.T) {
+	transport := httptestkit.NewTransport()
+	transport.Expect(http.MethodGet, "/a").Times(2)
+	transport.Expect("", "/b").AnyTimes()
+
+	client := transport.Client()
+
+	resp, err := client.Get("https://example.com/a")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	_, err = client.Get("https://example.com/c")
+	require.ErrorIs(t, err, httptestkit.ErrUnexpectedRequest)
+
+	tb := &recordingTB{TB: t}
+	assert.False(t, transport.AssertExpectations(tb))
+	assert.Equal(t, []string{
+		"httptestkit: expected GET /a 2 times, got 1 calls",
+		"httptestkit: unexpected request GET https://example.com/c",
+	}, tb.errors)
+
+	transport.Reset()
+	assert.True(t, transport.AssertExpectations(t))
+	assert.Empty(t, transport.Requests())
+}
+
+func TestTransportFaults(t *testing.T) {
+	errConn := errors.New("connection reset")
+
+	transport := httptestkit.NewTransport()
+	transport.Expect(http.MethodGet, "/flaky").FailFirst(2, errConn).Times(3)
+	transport.Expect(http.MethodGet, "/down").Fail(errConn)
+	transport.Expect(http.MethodGet, "/slow").Delay(time.Second)
+
+	client := transport.Client()
+
+	for range 2 {
+		_, err := client.Get("https://example.com/flaky")
+		require.ErrorIs(t, err, errConn)
+	}
+
+	resp, err := client.Get("https://example.com/flaky")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	_, err = client.Get("https://example.com/down")
+	require.ErrorIs(t, err, errConn)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/slow", nil)
+	require.NoError(t, err)
+
+	_, err = client.Do(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestTransportInstall(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    commoncfg.HTTPClient
+		assert func(t *testing.T, req httptestkit.RecordedRequest)
+	}{
+		{
+			name: "api token",
+			cfg: commoncfg.HTTPClient{
+				APIToken: &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "secret"},
+			},
+			assert: func(t *testing.T, req httptestkit.RecordedRequest) {
+				t.Helper()
+				assert.Equal(t, "Api-Token secret", req.Header.Get("Authorization"))
+			},
+		},
+		{
+			name: "basic auth",
+			cfg: commoncfg.HTTPClient{
+				BasicAuth: &commoncfg.BasicAuth{
+					Username: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "user"},
+					Password: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "pass"},
+				},
+			},
+			assert: func(t *testing.T, req httptestkit.RecordedRequest) {
+				t.Helper()
+
+				user, pass, ok := req.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "user", user)
+				assert.Equal(t, "pass", pass)
+			},
+		},
+		{
+			name: "no auth",
+			assert: func(t *testing.T, req httptestkit.RecordedRequest) {
+				t.Helper()
+
+				_, ok := req.BearerToken()
+				assert.False(t, ok)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := commonhttp.NewHTTPClient(&tt.cfg)
+			require.NoError(t, err)
+
+			transport := httptestkit.NewTransport()
+			transport.Expect(http.MethodGet, "/").Reply(http.StatusNoContent, "")
+			transport.Install(client)
+
+			resp, err := client.Get("https://example.com/")
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			req, ok := transport.LastRequest()
+			require.True(t, ok)
+			tt.assert(t, req)
+			transport.AssertExpectations(t)
+		})
+	}
+}
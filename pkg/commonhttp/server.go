@@ -0,0 +1,130 @@
+package commonhttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// Server wraps an *http.Server created from an HTTPServer configuration and
+// tracks in-flight requests so that it can be drained gracefully.
+type Server struct {
+	server       *http.Server
+	drainTimeout time.Duration
+	inFlight     atomic.Int64
+}
+
+// ShutdownReport describes the outcome of a graceful shutdown.
+type ShutdownReport struct {
+	// Drained is true if all in-flight requests completed before the drain deadline.
+	Drained bool
+	// Aborted is the number of requests still in flight when the remaining
+	// connections were closed forcibly.
+	Aborted int64
+	// Duration is the time spent shutting down.
+	Duration time.Duration
+}
+
+// NewServer creates a *Server serving the given handler.
+//
+// Parameters:
+//   - cfg: HTTPServer configuration (address, timeouts and drain deadline)
+//   - handler: The handler invoked for each request
+//
+// Returns:
+//   - *Server: The configured server; call ListenAndServe or Serve to start it
+//   - error: If the configuration or handler is nil
+func NewServer(cfg *commoncfg.HTTPServer, handler http.Handler) (*Server, error) {
+	if cfg == nil {
+		return nil, errors.New("HTTPServer config is nil")
+	}
+
+	if handler == nil {
+		return nil, errors.New("HTTP handler is nil")
+	}
+
+	s := &Server{
+		drainTimeout: cfg.DrainTimeout,
+	}
+
+	s.server = &http.Server{
+		Addr:              cfg.Address,
+		Handler:           s.track(handler),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	return s, nil
+}
+
+// HTTPServer returns the underlying *http.Server, e.g. to set TLSConfig or ErrorLog.
+func (s *Server) HTTPServer() *http.Server {
+	return s.server
+}
+
+// InFlight returns the number of requests currently being served.
+func (s *Server) InFlight() int64 {
+	return s.inFlight.Load()
+}
+
+// ListenAndServe listens on the configured address and serves requests.
+// It returns http.ErrServerClosed after Shutdown.
+func (s *Server) ListenAndServe() error {
+	return s.server.ListenAndServe()
+}
+
+// Serve accepts incoming connections on the listener l.
+// It returns http.ErrServerClosed after Shutdown.
+func (s *Server) Serve(l net.Listener) error {
+	return s.server.Serve(l)
+}
+
+// Shutdown gracefully stops the server.
+//
+// It stops accepting new connections, closes idle connections and waits for
+// in-flight requests to complete until the drain deadline elapses. The drain
+// deadline is the earlier of the configured DrainTimeout and the deadline of ctx.
+// Once the deadline is reached, all remaining connections are closed and the
+// number of aborted requests is reported.
+//
+// Returns:
+//   - ShutdownReport: Whether the server drained and how many requests were aborted
+//   - error: The context error if the deadline was reached, or any error closing the server
+func (s *Server) Shutdown(ctx context.Context) (ShutdownReport, error) {
+	start := time.Now()
+
+	if s.drainTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, s.drainTimeout)
+		defer cancel()
+	}
+
+	err := s.server.Shutdown(ctx)
+	if err == nil {
+		return ShutdownReport{Drained: true, Duration: time.Since(start)}, nil
+	}
+
+	report := ShutdownReport{Aborted: s.inFlight.Load()}
+
+	closeErr := s.server.Close()
+	report.Duration = time.Since(start)
+
+	return report, errors.Join(err, closeErr)
+}
+
+func (s *Server) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,125 @@
+package commonhttp_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commonhttp"
+)
+
+func startServer(t *testing.T, cfg *commoncfg.HTTPServer, handler http.Handler) (*commonhttp.Server, string) {
+	t.Helper()
+
+	srv, err := commonhttp.NewServer(cfg, handler)
+	require.NoError(t, err)
+
+	listener, err := (&net.ListenConfig{}).Listen(t.Context(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+
+	return srv, "http://" + listener.Addr().String()
+}
+
+func TestNewServerInvalidArgs(t *testing.T) {
+	_, err := commonhttp.NewServer(nil, http.NotFoundHandler())
+	require.Error(t, err)
+
+	_, err = commonhttp.NewServer(&commoncfg.HTTPServer{}, nil)
+	require.Error(t, err)
+}
+
+func TestServerShutdownDrainsInFlightRequests(t *testing.T) {
+	// Arrange
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	srv, url := startServer(t, &commoncfg.HTTPServer{DrainTimeout: 5 * time.Second},
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	)
+
+	result := make(chan int, 1)
+
+	go func() {
+		resp, err := http.Get(url) //nolint:noctx
+		if err != nil {
+			result <- 0
+			return
+		}
+		defer resp.Body.Close()
+
+		result <- resp.StatusCode
+	}()
+
+	<-started
+	assert.Equal(t, int64(1), srv.InFlight())
+
+	time.AfterFunc(100*time.Millisecond, func() { close(release) })
+
+	// Act
+	report, err := srv.Shutdown(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, report.Drained)
+	assert.Zero(t, report.Aborted)
+	assert.Equal(t, http.StatusNoContent, <-result)
+	assert.Zero(t, srv.InFlight())
+}
+
+func TestServerShutdownAbortsAfterDrainTimeout(t *testing.T) {
+	// Arrange
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	srv, url := startServer(t, &commoncfg.HTTPServer{DrainTimeout: 100 * time.Millisecond},
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		}),
+	)
+
+	go func() {
+		resp, err := http.Get(url) //nolint:noctx
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+
+	// Act
+	report, err := srv.Shutdown(context.Background())
+
+	// Assert
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, report.Drained)
+	assert.Equal(t, int64(1), report.Aborted)
+	assert.GreaterOrEqual(t, report.Duration, 100*time.Millisecond)
+}
+
+func TestServerShutdownWithoutRequests(t *testing.T) {
+	srv, _ := startServer(t, &commoncfg.HTTPServer{}, http.NotFoundHandler())
+
+	report, err := srv.Shutdown(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.Drained)
+
+	err = srv.ListenAndServe()
+	assert.True(t, errors.Is(err, http.ErrServerClosed))
+}
@@ -0,0 +1,169 @@
+package grpcpool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// ErrNotRegistered is the error when a pool released to a registry was not acquired from it
+var ErrNotRegistered = errors.New("grpc pool: the pool is not registered")
+
+// Registry shares pools between the clients of the same target and credentials, e.g.
+// the business, health and metrics clients of a backend, so they do not each open their
+// own connections. The pools are reference counted: every Acquire must be matched by a
+// Release, and a pool is closed once its last reference is released.
+//
+// Registry is safe for concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	entries map[string]*registryEntry
+	keys    map[*Pool]string
+}
+
+type registryEntry struct {
+	pool *Pool
+	refs int
+}
+
+// RegistryKey returns the key of the pools of a target dialed with the given credentials
+// configuration, e.g. a *commoncfg.SecretRef, so that clients of the same target with
+// different credentials do not share a pool. Only a digest of the credentials is part of the key.
+func RegistryKey(target string, credentials any) string {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		// credentials which cannot be compared are never shared
+		return target + "#" + err.Error()
+	}
+
+	sum := sha256.Sum256(data)
+
+	return target + "#" + hex.EncodeToString(sum[:8])
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		entries: make(map[string]*registryEntry),
+		keys:    make(map[*Pool]string),
+	}
+}
+
+// Acquire returns the pool registered for the key, e.g. the target address, and adds a
+// reference to it. If there is none, the pool is created by newPool and registered, so
+// the first client of a target determines the dial options of all clients sharing it.
+// If newPool is nil, only an existing pool is returned and ok reports whether there is one.
+func (r *Registry) Acquire(key string, newPool func() (*Pool, error)) (pool *Pool, ok bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, found := r.entries[key]; found {
+		entry.refs++
+		return entry.pool, true, nil
+	}
+
+	if newPool == nil {
+		return nil, false, nil
+	}
+
+	pool, err = newPool()
+	if err != nil {
+		return nil, false, err
+	}
+
+	r.entries[key] = &registryEntry{pool: pool, refs: 1}
+	r.keys[pool] = key
+
+	return pool, true, nil
+}
+
+// Release drops a reference to the pool and closes it once the last reference is released.
+func (r *Registry) Release(pool *Pool) error {
+	r.mu.Lock()
+
+	key, ok := r.keys[pool]
+	if !ok {
+		r.mu.Unlock()
+		return ErrNotRegistered
+	}
+
+	entry := r.entries[key]
+	entry.refs--
+
+	if entry.refs > 0 {
+		r.mu.Unlock()
+		return nil
+	}
+
+	delete(r.entries, key)
+	delete(r.keys, pool)
+	r.mu.Unlock()
+
+	// close outside the lock, Close waits for the outstanding connections
+	return pool.Close()
+}
+
+// Refs returns the number of references to the pool registered for the key.
+func (r *Registry) Refs(key string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry, ok := r.entries[key]; ok {
+		return entry.refs
+	}
+
+	return 0
+}
+
+// Close closes all pools regardless of their references, e.g. on shutdown.
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	entries := r.entries
+	r.entries = make(map[string]*registryEntry)
+	r.keys = make(map[*Pool]string)
+	r.mu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		errs = append(errs, entry.pool.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// registryDialOption carries a registry through the dial options of client constructors.
+type registryDialOption struct {
+	grpc.EmptyDialOption
+
+	registry *Registry
+}
+
+// WithRegistry returns a dial option making the client constructors accepting it, e.g.
+// commongrpc.NewPooledClient and health.NewGRPCHealthClient, share their pools through
+// the registry. It does not change the connections otherwise.
+func WithRegistry(registry *Registry) grpc.DialOption {
+	return registryDialOption{registry: registry}
+}
+
+// RegistryFromDialOptions returns the registry set by WithRegistry, if any, and the
+// dial options without it.
+func RegistryFromDialOptions(dialOptions []grpc.DialOption) (*Registry, []grpc.DialOption) {
+	var registry *Registry
+
+	opts := make([]grpc.DialOption, 0, len(dialOptions))
+
+	for _, opt := range dialOptions {
+		if o, ok := opt.(registryDialOption); ok {
+			registry = o.registry
+			continue
+		}
+
+		opts = append(opts, opt)
+	}
+
+	return registry, opts
+}
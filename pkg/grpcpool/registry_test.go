@@ -0,0 +1,129 @@
+package grpcpool_test
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/openkcm/common-sdk/pkg/grpcpool"
+)
+
+func newTestPool() (*grpcpool.Pool, error) {
+	return grpcpool.New(func() (*grpc.ClientConn, error) {
+		return grpc.NewClient("example.com", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	registry := grpcpool.NewRegistry()
+
+	created := 0
+	newPool := func() (*grpcpool.Pool, error) {
+		created++
+		return newTestPool()
+	}
+
+	first, _, err := registry.Acquire("backend:443", newPool)
+	if err != nil {
+		t.Fatalf("Acquire returned an error: %s", err)
+	}
+
+	second, _, err := registry.Acquire("backend:443", newPool)
+	if err != nil {
+		t.Fatalf("Acquire returned an error: %s", err)
+	}
+
+	existing, ok, _ := registry.Acquire("backend:443", nil)
+
+	if first != second || first != existing || !ok {
+		t.Fatalf("expected the pool to be shared")
+	}
+
+	if created != 1 || registry.Refs("backend:443") != 3 {
+		t.Fatalf("expected 1 pool with 3 references, got %d pools with %d references", created, registry.Refs("backend:443"))
+	}
+
+	for range 2 {
+		err = registry.Release(first)
+		if err != nil {
+			t.Fatalf("Release returned an error: %s", err)
+		}
+
+		if first.IsClosed() {
+			t.Fatalf("the pool was closed while still referenced")
+		}
+	}
+
+	err = registry.Release(first)
+	if err != nil {
+		t.Fatalf("Release returned an error: %s", err)
+	}
+
+	if !first.IsClosed() {
+		t.Errorf("expected the pool to be closed after the last release")
+	}
+
+	err = registry.Release(first)
+	if !errors.Is(err, grpcpool.ErrNotRegistered) {
+		t.Errorf("expected ErrNotRegistered, got %v", err)
+	}
+
+	_, ok, _ = registry.Acquire("backend:443", nil)
+	if ok {
+		t.Errorf("expected no pool after the last release")
+	}
+}
+
+func TestRegistryKey(t *testing.T) {
+	type creds struct{ Token string }
+
+	key := grpcpool.RegistryKey("backend:443", &creds{Token: "a"})
+
+	if got := grpcpool.RegistryKey("backend:443", &creds{Token: "a"}); got != key {
+		t.Errorf("the keys of the same credentials differ: %s and %s", key, got)
+	}
+
+	for _, other := range []string{
+		grpcpool.RegistryKey("backend:443", &creds{Token: "b"}),
+		grpcpool.RegistryKey("backend:443", nil),
+		grpcpool.RegistryKey("other:443", &creds{Token: "a"}),
+	} {
+		if other == key {
+			t.Errorf("the key %s is shared by other credentials or targets", key)
+		}
+	}
+}
+
+func TestRegistryClose(t *testing.T) {
+	registry := grpcpool.NewRegistry()
+
+	a, _, _ := registry.Acquire("a:443", newTestPool)
+	b, _, _ := registry.Acquire("b:443", newTestPool)
+	_, _, _ = registry.Acquire("b:443", newTestPool)
+
+	err := registry.Close()
+	if err != nil {
+		t.Fatalf("Close returned an error: %s", err)
+	}
+
+	if !a.IsClosed() || !b.IsClosed() {
+		t.Errorf("expected all pools to be closed")
+	}
+}
+
+func TestRegistryFromDialOptions(t *testing.T) {
+	registry := grpcpool.NewRegistry()
+	creds := grpc.WithTransportCredentials(insecure.NewCredentials())
+
+	got, opts := grpcpool.RegistryFromDialOptions([]grpc.DialOption{creds, grpcpool.WithRegistry(registry)})
+	if got != registry || len(opts) != 1 {
+		t.Errorf("expected the registry and the remaining option, got %v and %d options", got, len(opts))
+	}
+
+	got, opts = grpcpool.RegistryFromDialOptions([]grpc.DialOption{creds})
+	if got != nil || len(opts) != 1 {
+		t.Errorf("expected no registry")
+	}
+}
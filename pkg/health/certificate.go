@@ -0,0 +1,159 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+const (
+	// InfoCertificateExpiry is the key of the certificate expiry dates in Result.Info.
+	InfoCertificateExpiry = "certificateExpiry"
+
+	// CertificateDaysRemainingMetric is the gauge reporting the remaining validity of checked certificates.
+	CertificateDaysRemainingMetric = "certificate.expiry.days_remaining"
+
+	DefCertificateCheckInterval   = time.Hour
+	DefCertificateExpiryWarning   = 30 * 24 * time.Hour
+	DefCertificateExpiryCritical  = 7 * 24 * time.Hour
+	certificateExpiryInstrumentID = "github.com/openkcm/common-sdk/pkg/health"
+)
+
+// CertificateExpiryThresholds define when warnings about an approaching certificate expiry are logged.
+// Below Warning a warning is logged, below Critical an error is logged on every check.
+type CertificateExpiryThresholds struct {
+	Warning  time.Duration
+	Critical time.Duration
+}
+
+// certificateExpiryChecker keeps the last known expiry of a certificate,
+// shared between the periodic check and the info function.
+type certificateExpiryChecker struct {
+	name       string
+	mtls       *commoncfg.MTLS
+	thresholds CertificateExpiryThresholds
+	now        func() time.Time
+
+	mu     sync.RWMutex
+	expiry *commoncfg.CertificateExpiry
+}
+
+// WithCertificateExpiryChecker adds a periodic health check for the client certificate of the given
+// mTLS configuration. The certificate is reloaded on every run so rotated certificates are picked up.
+//
+// On every run the check
+//   - reports the remaining validity in days via the certificate.expiry.days_remaining gauge,
+//   - logs a warning once the expiry is within thresholds.Warning and an error once it is within
+//     thresholds.Critical (zero values fall back to 30 and 7 days),
+//   - publishes the expiry date below "certificateExpiry" in Result.Info,
+//   - fails once the certificate is expired or cannot be loaded.
+func WithCertificateExpiryChecker(name string, mtls *commoncfg.MTLS, thresholds CertificateExpiryThresholds) Option {
+	c := newCertificateExpiryChecker(name, mtls, thresholds)
+
+	return func(cfg *checkerConfig) {
+		WithPeriodicCheck(DefCertificateCheckInterval, 0, Check{
+			Name:  name,
+			Check: c.check,
+		})(cfg)
+		WithInfoFunc(c.info)(cfg)
+	}
+}
+
+func newCertificateExpiryChecker(name string, mtls *commoncfg.MTLS, thresholds CertificateExpiryThresholds) *certificateExpiryChecker {
+	if thresholds.Warning <= 0 {
+		thresholds.Warning = DefCertificateExpiryWarning
+	}
+
+	if thresholds.Critical <= 0 {
+		thresholds.Critical = DefCertificateExpiryCritical
+	}
+
+	return &certificateExpiryChecker{
+		name:       name,
+		mtls:       mtls,
+		thresholds: thresholds,
+		now:        time.Now,
+	}
+}
+
+func (c *certificateExpiryChecker) check(ctx context.Context) error {
+	expiry, err := commoncfg.LoadMTLSCertificateExpiry(c.mtls)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate %s: %w", c.name, err)
+	}
+
+	c.mu.Lock()
+	c.expiry = &expiry
+	c.mu.Unlock()
+
+	now := c.now()
+	daysRemaining := expiry.DaysRemaining(now)
+
+	recordCertificateDaysRemaining(ctx, c.name, expiry, daysRemaining)
+
+	logCtx := slogctx.With(ctx,
+		"certificate", c.name,
+		"subject", expiry.Subject,
+		"notAfter", expiry.NotAfter,
+		"daysRemaining", daysRemaining,
+	)
+
+	remaining := expiry.Remaining(now)
+
+	switch {
+	case expiry.IsExpired(now):
+		slogctx.Error(logCtx, "Certificate is expired")
+		return fmt.Errorf("certificate %s expired at %s", c.name, expiry.NotAfter.Format(time.RFC3339))
+	case remaining <= c.thresholds.Critical:
+		slogctx.Error(logCtx, "Certificate expires soon, renew it immediately")
+	case remaining <= c.thresholds.Warning:
+		slogctx.Warn(logCtx, "Certificate expires soon")
+	}
+
+	return nil
+}
+
+func (c *certificateExpiryChecker) info(info map[string]any) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.expiry == nil {
+		return
+	}
+
+	// Several checkers share the same info entry.
+	expiries := map[string]any{}
+	if existing, ok := info[InfoCertificateExpiry].(map[string]any); ok {
+		maps.Copy(expiries, existing)
+	}
+
+	expiries[c.name] = c.expiry.NotAfter.UTC().Format(time.RFC3339)
+	info[InfoCertificateExpiry] = expiries
+}
+
+func recordCertificateDaysRemaining(ctx context.Context, name string, expiry commoncfg.CertificateExpiry, days float64) {
+	gauge, err := otel.GetMeterProvider().Meter(certificateExpiryInstrumentID).Float64Gauge(
+		CertificateDaysRemainingMetric,
+		metric.WithDescription("Remaining validity of the certificate in days"),
+		metric.WithUnit("d"),
+	)
+	if err != nil {
+		slogctx.Error(ctx, "Failed to create certificate expiry gauge", "error", err)
+		return
+	}
+
+	gauge.Record(ctx, days, metric.WithAttributes(
+		attribute.String("certificate.name", name),
+		attribute.String("certificate.subject", expiry.Subject),
+	))
+}
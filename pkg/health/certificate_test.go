@@ -0,0 +1,147 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func newTestMTLS(t *testing.T, notAfter time.Time) *commoncfg.MTLS {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	return &commoncfg.MTLS{
+		Cert: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: string(certPEM)},
+	}
+}
+
+func TestCertificateExpiryCheck(t *testing.T) {
+	now := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		wantErr  bool
+		wantLog  string
+		wantDays float64
+	}{
+		{name: "valid", notAfter: now.Add(90 * 24 * time.Hour), wantDays: 90},
+		{name: "warning", notAfter: now.Add(20 * 24 * time.Hour), wantLog: "level=WARN", wantDays: 20},
+		{name: "critical", notAfter: now.Add(3 * 24 * time.Hour), wantLog: "level=ERROR", wantDays: 3},
+		{name: "expired", notAfter: now.Add(-24 * time.Hour), wantErr: true, wantLog: "Certificate is expired", wantDays: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			reader := metric.NewManualReader()
+			prev := otel.GetMeterProvider()
+			otel.SetMeterProvider(metric.NewMeterProvider(metric.WithReader(reader)))
+			t.Cleanup(func() { otel.SetMeterProvider(prev) })
+
+			var logs bytes.Buffer
+
+			ctx := slogctx.NewCtx(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)))
+
+			c := newCertificateExpiryChecker("client", newTestMTLS(t, tt.notAfter), CertificateExpiryThresholds{})
+			c.now = func() time.Time { return now }
+
+			// Act
+			err := c.check(ctx)
+
+			// Assert
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tt.wantLog == "" {
+				assert.Empty(t, logs.String())
+			} else {
+				assert.Contains(t, logs.String(), tt.wantLog)
+			}
+
+			var rm metricdata.ResourceMetrics
+			require.NoError(t, reader.Collect(ctx, &rm))
+			require.Len(t, rm.ScopeMetrics, 1)
+			require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+
+			gauge, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Gauge[float64])
+			require.True(t, ok)
+			require.Len(t, gauge.DataPoints, 1)
+			assert.InDelta(t, tt.wantDays, gauge.DataPoints[0].Value, 0.001)
+		})
+	}
+}
+
+func TestCertificateExpiryCheckLoadError(t *testing.T) {
+	c := newCertificateExpiryChecker("client", &commoncfg.MTLS{Cert: commoncfg.SourceRef{Source: "unknown"}}, CertificateExpiryThresholds{})
+
+	require.Error(t, c.check(context.Background()))
+
+	info := map[string]any{}
+	c.info(info)
+	assert.Empty(t, info)
+}
+
+func TestWithCertificateExpiryChecker(t *testing.T) {
+	// Arrange
+	notAfter := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	checker := NewChecker(
+		WithDisabledAutostart(),
+		WithCertificateExpiryChecker("client", newTestMTLS(t, notAfter), CertificateExpiryThresholds{}),
+		WithCertificateExpiryChecker("server", newTestMTLS(t, notAfter), CertificateExpiryThresholds{}),
+	)
+
+	ckr, _ := checker.(*defaultChecker)
+	require.Len(t, ckr.cfg.checks, 2)
+	assert.Equal(t, DefCertificateCheckInterval, ckr.cfg.checks["client"].updateInterval)
+
+	// Act
+	checker.Start()
+	defer checker.Stop()
+
+	require.Eventually(t, func() bool {
+		return checker.Check(context.Background()).Status == StatusUp
+	}, 5*time.Second, 10*time.Millisecond)
+
+	result := checker.Check(context.Background())
+
+	// Assert
+	want := notAfter.UTC().Format(time.RFC3339)
+	assert.Equal(t, map[string]any{"client": want, "server": want}, result.Info[InfoCertificateExpiry])
+}
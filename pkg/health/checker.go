@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"sync"
 	"time"
@@ -408,9 +409,7 @@ func (ck *defaultChecker) mapStateToCheckerResult() Result {
 		}
 	}
 
-	refreshInfoMap(ck.cfg.info, ck.cfg.infoFuncs)
-
-	return Result{Status: status, Details: checkResults, Info: ck.cfg.info}
+	return Result{Status: status, Details: checkResults, Info: computeInfo(ck.cfg.info, ck.cfg.infoFuncs)}
 }
 
 func isCacheExpired(cacheDuration time.Duration, state *CheckState) bool {
@@ -566,23 +565,19 @@ func withInterceptors(interceptors []Interceptor, target InterceptorFunc) Interc
 	return chain
 }
 
-func refreshInfoMap(infoMap map[string]any, infoFuncs []func(map[string]any)) {
+// computeInfo builds the info map of a health check result. Values computed by the info
+// functions are added first, static values (see WithInfo) override them afterwards.
+func computeInfo(staticInfo map[string]any, infoFuncs []func(map[string]any)) map[string]any {
 	if len(infoFuncs) == 0 {
-		return
-	}
-
-	if len(infoMap) == 0 {
-		return
+		return staticInfo
 	}
 
-	target := make(map[string]any)
+	info := make(map[string]any, len(staticInfo))
 	for _, infoFunc := range infoFuncs {
-		infoFunc(target)
+		infoFunc(info)
 	}
 
-	for k, v := range target {
-		if _, ok := infoMap[k]; !ok {
-			infoMap[k] = v
-		}
-	}
+	maps.Copy(info, staticInfo)
+
+	return info
 }
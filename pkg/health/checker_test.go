@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
 	"github.com/openkcm/common-sdk/pkg/health"
 )
 
@@ -226,3 +227,75 @@ func TestPanicRecovery(t *testing.T) {
 	assert.Error(t, checkRes.Error)
 	assert.Equal(t, expectedPanicMsg, (checkRes.Error).Error())
 }
+
+func TestCheckerCheckWithBuildInfo(t *testing.T) {
+	// Arrange
+	cfg := &commoncfg.BaseConfig{
+		Application: commoncfg.Application{
+			Name:        "my-service",
+			Environment: "dev",
+			BuildInfo: commoncfg.BuildInfo{
+				Component: commoncfg.Component{
+					Version:   "1.2.3",
+					SHA:       "abc123",
+					BuildTime: "2024-01-01T12:00:00Z",
+				},
+			},
+		},
+		FeatureGates: commoncfg.FeatureGates{"b-feature": {Enabled: true}, "a-feature": {Enabled: true}, "off": {Enabled: false}},
+	}
+	fingerprint, err := commoncfg.Fingerprint(cfg)
+	require.NoError(t, err)
+
+	ckr := health.NewChecker(
+		health.WithDisabledAutostart(),
+		health.WithBuildInfo(cfg),
+		health.WithInfo(map[string]any{health.InfoVersion: "overridden"}),
+	)
+
+	// Act
+	res := ckr.Check(t.Context())
+
+	// Assert
+	assert.Equal(t, "my-service", res.Info[health.InfoApplication])
+	assert.Equal(t, "dev", res.Info[health.InfoEnvironment])
+	assert.Equal(t, "overridden", res.Info[health.InfoVersion])
+	assert.Equal(t, "abc123", res.Info[health.InfoGitSHA])
+	assert.Equal(t, "2024-01-01T12:00:00Z", res.Info[health.InfoBuildTime])
+	assert.Equal(t, fingerprint, res.Info[health.InfoConfigFingerprint])
+	assert.Equal(t, []string{"a-feature", "b-feature"}, res.Info[health.InfoFeatureGates])
+}
+
+func TestCheckerCheckWithBuildInfoInstance(t *testing.T) {
+	// Arrange
+	cfg := &commoncfg.BaseConfig{}
+	ckr := health.NewChecker(health.WithDisabledAutostart(), health.WithBuildInfo(cfg))
+
+	// Act
+	res := ckr.Check(t.Context())
+
+	// Assert
+	assert.NotContains(t, res.Info, health.InfoInstanceID)
+
+	// the instance is loaded after the checker was created, e.g. by otlp.Init
+	cfg.Application.Instance = commoncfg.Instance{ID: "instance-id", RestartCount: 2}
+	res = ckr.Check(t.Context())
+
+	assert.Equal(t, "instance-id", res.Info[health.InfoInstanceID])
+	assert.Equal(t, int64(2), res.Info[health.InfoRestartCount])
+}
+
+func TestCheckerCheckInfoFuncWithoutStaticInfo(t *testing.T) {
+	// Arrange
+	ckr := health.NewChecker(
+		health.WithDisabledAutostart(),
+		health.WithInfoFunc(func(info map[string]any) { info["first"] = 1 }),
+		health.WithInfoFunc(func(info map[string]any) { info["second"] = 2 }),
+	)
+
+	// Act
+	res := ckr.Check(t.Context())
+
+	// Assert
+	assert.Equal(t, map[string]any{"first": 1, "second": 2}, res.Info)
+}
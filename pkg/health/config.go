@@ -14,6 +14,19 @@ import (
 	"github.com/openkcm/common-sdk/pkg/commoncfg"
 )
 
+// Keys of the values published by BuildInfoFunc in Result.Info.
+const (
+	InfoApplication       = "application"
+	InfoEnvironment       = "environment"
+	InfoVersion           = "version"
+	InfoGitSHA            = "gitSha"
+	InfoBuildTime         = "buildTime"
+	InfoConfigFingerprint = "configFingerprint"
+	InfoFeatureGates      = "featureGates"
+	InfoInstanceID        = "instanceId"
+	InfoRestartCount      = "restartCount"
+)
+
 type (
 	// Check allows to configure health checks.
 	Check struct {
@@ -231,10 +244,55 @@ func WithInfo(values map[string]any) Option {
 // from WithInfo will take precedence over those dynamically computed by WithInfoFunc.
 // Values added by these functions will still be available in Result.Info and reflected in the
 // "info" field if you are using the default HTTP handler (see NewHandler) or converting Result to JSON.
-// The functions will be executed in order.
+// The functions will be executed in order. Calling WithInfoFunc multiple times adds the functions
+// to the ones registered before.
 func WithInfoFunc(infoFuncs ...func(info map[string]any)) Option {
 	return func(cfg *checkerConfig) {
-		cfg.infoFuncs = infoFuncs
+		cfg.infoFuncs = append(cfg.infoFuncs, infoFuncs...)
+	}
+}
+
+// WithBuildInfo adds the application name, version, Git SHA, build time, configuration fingerprint
+// and the enabled feature gates of the given configuration to every health check result
+// (see BuildInfoFunc). It is registered like any other WithInfoFunc function, so static values
+// set by WithInfo still take precedence.
+func WithBuildInfo(cfg *commoncfg.BaseConfig) Option {
+	return WithInfoFunc(BuildInfoFunc(cfg))
+}
+
+// BuildInfoFunc returns an info function publishing what exactly is running: the application
+// name and environment, the build version, Git SHA and build time, a fingerprint of the
+// configuration, the names of all enabled feature gates and, once loaded (see commoncfg.LoadInstance),
+// the instance ID and restart counter. Empty values are omitted.
+func BuildInfoFunc(cfg *commoncfg.BaseConfig) func(info map[string]any) {
+	return func(info map[string]any) {
+		if cfg == nil {
+			return
+		}
+
+		putIfNotEmpty(info, InfoApplication, cfg.Application.Name)
+		putIfNotEmpty(info, InfoEnvironment, cfg.Application.Environment)
+		putIfNotEmpty(info, InfoVersion, cfg.Application.BuildInfo.Version)
+		putIfNotEmpty(info, InfoGitSHA, cfg.Application.BuildInfo.SHA)
+		putIfNotEmpty(info, InfoBuildTime, cfg.Application.BuildInfo.BuildTime)
+
+		fingerprint, err := commoncfg.Fingerprint(cfg)
+		if err == nil {
+			info[InfoConfigFingerprint] = fingerprint
+		}
+
+		info[InfoFeatureGates] = cfg.FeatureGates.EnabledFeatures()
+
+		if cfg.Application.Instance.ID != "" {
+			info[InfoInstanceID] = cfg.Application.Instance.ID
+			info[InfoRestartCount] = cfg.Application.Instance.RestartCount
+		}
+	}
+}
+
+func putIfNotEmpty(info map[string]any, key, value string) {
+	if value != "" {
+		info[key] = value
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 
 	"google.golang.org/grpc"
@@ -23,6 +24,7 @@ var (
 
 type GRPCHealthClientService interface {
 	healthgrpc.HealthClient
+	io.Closer
 }
 
 var globalGRPCClientPool = sync.Map{}
@@ -37,6 +39,7 @@ func CheckGRPCServerHealth(ctx context.Context, grpcCfg *commoncfg.GRPCClient) e
 	if err != nil {
 		return err
 	}
+	defer client.Close()
 
 	grpcServerHealthResp, err := client.Check(ctx, &healthgrpc.HealthCheckRequest{Service: "self"})
 	if err != nil {
@@ -51,7 +54,26 @@ func CheckGRPCServerHealth(ctx context.Context, grpcCfg *commoncfg.GRPCClient) e
 }
 
 // NewGRPCHealthClient create a grpc client connect to health check server
+//
+// With the grpcpool.WithRegistry dial option, the client shares the pool of the business
+// client of the same address and credentials (SecretRef), if the registry holds one, instead
+// of opening its own connections. Close releases the reference it acquires, so the pool is
+// closed once the business client releases it as well.
 func NewGRPCHealthClient(grpcClientCfg *commoncfg.GRPCClient, dialOptions ...grpc.DialOption) (GRPCHealthClientService, error) {
+	registry, dialOptions := grpcpool.RegistryFromDialOptions(dialOptions)
+	if registry != nil {
+		key := grpcpool.RegistryKey(grpcClientCfg.Address, grpcClientCfg.SecretRef)
+
+		pool, ok, _ := registry.Acquire(key, nil)
+		if ok {
+			return &grpcClient{
+				serverAddr: grpcClientCfg.Address,
+				clientPool: pool,
+				registry:   registry,
+			}, nil
+		}
+	}
+
 	dialOptions = append(dialOptions,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithKeepaliveParams(keepalive.ClientParameters{
@@ -95,6 +117,22 @@ type grpcClient struct {
 	serverAddr string
 
 	clientPool *grpcpool.Pool
+	registry   *grpcpool.Registry // set if the pool was acquired from the registry
+	closeOnce  sync.Once
+}
+
+// Close releases the pool acquired from a registry. The pools which are not shared
+// through a registry are kept for the other health clients of the address.
+func (client *grpcClient) Close() error {
+	var err error
+
+	client.closeOnce.Do(func() {
+		if client.registry != nil {
+			err = client.registry.Release(client.clientPool)
+		}
+	})
+
+	return err
 }
 
 func createGRPCFactory(client *grpcClient, dialOptions ...grpc.DialOption) grpcpool.ClientFactory {
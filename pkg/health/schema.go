@@ -0,0 +1,128 @@
+package health
+
+import (
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+const (
+	// ResultSchemaName is the name of the Result schema in the $defs of ResultJSONSchema
+	// and in the components of ResultOpenAPISchemas.
+	ResultSchemaName = "HealthResult"
+	// CheckResultSchemaName is the name of the CheckResult schema in the $defs of
+	// ResultJSONSchema and in the components of ResultOpenAPISchemas.
+	CheckResultSchemaName = "HealthCheckResult"
+
+	openAPISchemaRef = "#/components/schemas/"
+	jsonSchemaRef    = "#/$defs/"
+)
+
+// ResultJSONSchema returns the JSON Schema of the Result written by the JSONResultWriter,
+// including the Info values published by WithBuildInfo and WithCertificateExpiryChecker.
+// Other Info values are allowed, as they can be added by WithInfo and WithInfoFunc.
+//
+// The schema can be served next to the health endpoint or be used by API gateways and
+// monitors to validate its responses.
+func ResultJSONSchema() *commoncfg.JSONSchema {
+	schema := resultSchema(jsonSchemaRef)
+	schema.Schema = commoncfg.JSONSchemaDialect
+	schema.Defs = map[string]*commoncfg.JSONSchema{
+		CheckResultSchemaName: checkResultSchema(),
+	}
+
+	return schema
+}
+
+// ResultOpenAPISchemas returns the schemas of the Result and CheckResult to be added to
+// the components/schemas of an OpenAPI 3.1 document, which references them by
+// ResultSchemaName and CheckResultSchemaName, e.g.
+//
+//	$ref: '#/components/schemas/HealthResult'
+func ResultOpenAPISchemas() map[string]*commoncfg.JSONSchema {
+	return map[string]*commoncfg.JSONSchema{
+		ResultSchemaName:      resultSchema(openAPISchemaRef),
+		CheckResultSchemaName: checkResultSchema(),
+	}
+}
+
+func resultSchema(refPrefix string) *commoncfg.JSONSchema {
+	return &commoncfg.JSONSchema{
+		Title:       ResultSchemaName,
+		Description: "Aggregated availability status of the system and its checked components.",
+		Type:        "object",
+		Properties: map[string]*commoncfg.JSONSchema{
+			"status": statusSchema("Aggregated availability status of the system."),
+			"info": {
+				Description: "Additional information about the system.",
+				Type:        "object",
+				Properties: map[string]*commoncfg.JSONSchema{
+					InfoApplication:       stringSchema("Name of the application."),
+					InfoEnvironment:       stringSchema("Environment the application runs in."),
+					InfoVersion:           stringSchema("Version of the application."),
+					InfoGitSHA:            stringSchema("Git SHA the application was built from."),
+					InfoBuildTime:         stringSchema("Time the application was built at."),
+					InfoConfigFingerprint: stringSchema("Fingerprint of the loaded configuration."),
+					InfoInstanceID:        stringSchema("ID of the application instance."),
+					InfoRestartCount: {
+						Description: "Number of restarts of the application instance.",
+						Type:        "integer",
+					},
+					InfoFeatureGates: {
+						Description: "Names of the enabled feature gates.",
+						Type:        "array",
+						Items:       &commoncfg.JSONSchema{Type: "string"},
+					},
+					InfoCertificateExpiry: {
+						Description: "Expiry dates of the checked certificates by check name.",
+						Type:        "object",
+						AdditionalProperties: &commoncfg.JSONSchema{
+							Type:   "string",
+							Format: "date-time",
+						},
+					},
+				},
+				AdditionalProperties: true,
+			},
+			"details": {
+				Description:          "Health information of the checked components by check name.",
+				Type:                 "object",
+				AdditionalProperties: &commoncfg.JSONSchema{Ref: refPrefix + CheckResultSchemaName},
+			},
+		},
+		Required:             []string{"status"},
+		AdditionalProperties: false,
+	}
+}
+
+func checkResultSchema() *commoncfg.JSONSchema {
+	return &commoncfg.JSONSchema{
+		Title:       CheckResultSchemaName,
+		Description: "Health information of a component.",
+		Type:        "object",
+		Properties: map[string]*commoncfg.JSONSchema{
+			"status": statusSchema("Availability status of the component."),
+			"timestamp": {
+				Description: "Time the check was executed at.",
+				Type:        "string",
+				Format:      "date-time",
+			},
+			"error": stringSchema("Error message of the check, if it failed."),
+		},
+		Required:             []string{"status"},
+		AdditionalProperties: false,
+	}
+}
+
+func statusSchema(description string) *commoncfg.JSONSchema {
+	return &commoncfg.JSONSchema{
+		Description: description,
+		Type:        "string",
+		Enum:        []any{StatusUp, StatusDown, StatusUnknown},
+	}
+}
+
+func stringSchema(description string) *commoncfg.JSONSchema {
+	return &commoncfg.JSONSchema{
+		Description: description,
+		Type:        "string",
+	}
+}
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestResultJSONSchemaDescribesResult(t *testing.T) {
+	result := Result{
+		Status: StatusDown,
+		Info: map[string]any{
+			InfoApplication:       "svc",
+			InfoEnvironment:       "dev",
+			InfoVersion:           "1.0.0",
+			InfoGitSHA:            "abc",
+			InfoBuildTime:         "today",
+			InfoConfigFingerprint: "fp",
+			InfoInstanceID:        "pod-1",
+			InfoRestartCount:      2,
+			InfoFeatureGates:      []string{"a"},
+			InfoCertificateExpiry: map[string]any{"mtls": "2030-01-01T00:00:00Z"},
+		},
+		Details: map[string]CheckResult{
+			"db": {Status: StatusDown, Timestamp: time.Now(), Error: errors.New("unreachable")},
+		},
+	}
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(data, &payload))
+
+	schema := ResultJSONSchema()
+	assert.Equal(t, commoncfg.JSONSchemaDialect, schema.Schema)
+	assertDescribes(t, schema.Properties, payload)
+	assertDescribes(t, schema.Properties["info"].Properties, payload["info"])
+
+	ref := schema.Properties["details"].AdditionalProperties.(*commoncfg.JSONSchema).Ref //nolint:forcetypeassert
+	checkResult := schema.Defs[strings.TrimPrefix(ref, "#/$defs/")]
+	require.NotNil(t, checkResult)
+	assertDescribes(t, checkResult.Properties, payload["details"].(map[string]any)["db"]) //nolint:forcetypeassert
+
+	assert.Contains(t, schema.Properties["status"].Enum, StatusDown)
+}
+
+func TestResultOpenAPISchemas(t *testing.T) {
+	schemas := ResultOpenAPISchemas()
+	require.Len(t, schemas, 2)
+
+	details := schemas[ResultSchemaName].Properties["details"]
+	assert.Equal(t, &commoncfg.JSONSchema{Ref: "#/components/schemas/" + CheckResultSchemaName}, details.AdditionalProperties)
+	assert.Empty(t, schemas[ResultSchemaName].Schema)
+	assert.Empty(t, schemas[ResultSchemaName].Defs)
+
+	data, err := json.Marshal(schemas)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"additionalProperties":false`)
+}
+
+func assertDescribes(t *testing.T, properties map[string]*commoncfg.JSONSchema, payload any) {
+	t.Helper()
+
+	values, ok := payload.(map[string]any)
+	require.True(t, ok)
+
+	for key := range values {
+		assert.Contains(t, properties, key)
+	}
+}
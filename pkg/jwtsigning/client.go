@@ -9,6 +9,9 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commonhttp"
 )
 
 // Client represents a JWKS (JSON Web Key Set) client that fetches keys from a remote endpoint.
@@ -18,9 +21,18 @@ type Client struct {
 	endpoint string       // URL of the JWKS endpoint
 }
 
+const (
+	defaultClientTimeout = 10 * time.Second
+	defaultClientConns   = 20
+	defaultIdleTimeout   = 30 * time.Second
+)
+
 var (
-	ErrInvalidURL      = errors.New("invalid url")
-	ErrHTTPStatusNotOK = errors.New("http status not ok")
+	ErrInvalidURL            = errors.New("invalid url")
+	ErrHTTPStatusNotOK       = errors.New("http status not ok")
+	ErrNilHTTPClient         = errors.New("http client cannot be nil")
+	ErrNilSecretRef          = errors.New("secret ref cannot be nil")
+	ErrSecretTypeUnsupported = errors.New("secret type not supported")
 )
 
 // HTTPClientOpts defines a function type that modifies an http.Client.
@@ -32,26 +44,86 @@ type HTTPClientOpts func(*http.Client)
 // Optional HTTPClientOpts can be provided to customize the underlying http.Client.
 // Returns an error if the endpoint URL is invalid.
 func NewClient(endpoint string, opts ...HTTPClientOpts) (*Client, error) {
+	cli := &http.Client{
+		Transport: &http.Transport{
+			MaxIdleConns:        defaultClientConns,
+			MaxConnsPerHost:     defaultClientConns,
+			MaxIdleConnsPerHost: defaultClientConns,
+			IdleConnTimeout:     defaultIdleTimeout,
+		},
+		Timeout: defaultClientTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(cli)
+	}
+
+	return NewClientWithHTTPClient(endpoint, cli)
+}
+
+// NewClientWithHTTPClient creates a Client for the given JWKS endpoint which sends its
+// requests with the given http.Client, e.g. a client authenticating with a token or a
+// client certificate against endpoints of machine-to-machine issuers.
+// Returns an error if the endpoint URL is invalid or the client is nil.
+func NewClientWithHTTPClient(endpoint string, cli *http.Client) (*Client, error) {
 	u, err := parseURL(endpoint)
 	if err != nil {
 		return nil, ErrInvalidURL
 	}
 
-	cli := &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConns:        20,
-			MaxConnsPerHost:     20,
-			MaxIdleConnsPerHost: 20,
-			IdleConnTimeout:     30 * time.Second,
+	if cli == nil {
+		return nil, ErrNilHTTPClient
+	}
+
+	return &Client{cli: cli, endpoint: u.String()}, nil
+}
+
+// NewClientFromSecretRef creates a Client for the given JWKS endpoint which authenticates
+// its requests with the secret ref, i.e. with a client certificate (mtls), an API token
+// (api-token), basic credentials (basic) or OAuth2 client credentials (oauth2), see
+// commonhttp. The insecure type sends unauthenticated requests.
+// Optional HTTPClientOpts can be provided to customize the underlying http.Client.
+// Returns an error if the endpoint URL is invalid or the secrets cannot be loaded.
+func NewClientFromSecretRef(endpoint string, secretRef *commoncfg.SecretRef, opts ...HTTPClientOpts) (*Client, error) {
+	if secretRef == nil {
+		return nil, ErrNilSecretRef
+	}
+
+	cfg := &commoncfg.HTTPClient{
+		Timeout: defaultClientTimeout,
+		TransportAttributes: &commoncfg.HTTPTransportAttributes{
+			MaxIdleConns:        defaultClientConns,
+			MaxConnsPerHost:     defaultClientConns,
+			MaxIdleConnsPerHost: defaultClientConns,
+			IdleConnTimeout:     defaultIdleTimeout,
 		},
-		Timeout: 10 * time.Second,
+	}
+
+	switch secretRef.Type {
+	case commoncfg.InsecureSecretType:
+	case commoncfg.MTLSSecretType:
+		cfg.MTLS = &secretRef.MTLS
+	case commoncfg.ApiTokenSecretType:
+		cfg.APIToken = &secretRef.APIToken
+	case commoncfg.BasicSecretType:
+		cfg.BasicAuth = &secretRef.Basic
+	case commoncfg.OAuth2SecretType:
+		cfg.OAuth2Auth = &secretRef.OAuth2
+		cfg.MTLS = secretRef.OAuth2.MTLS
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrSecretTypeUnsupported, secretRef.Type)
+	}
+
+	cli, err := commonhttp.NewHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
 	}
 
 	for _, opt := range opts {
 		opt(cli)
 	}
 
-	return &Client{cli: cli, endpoint: u.String()}, nil
+	return NewClientWithHTTPClient(endpoint, cli)
 }
 
 // Get retrieves the JSON Web Key Set (JWKS) from the configured endpoint.
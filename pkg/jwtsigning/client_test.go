@@ -1,6 +1,10 @@
 package jwtsigning_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -9,7 +13,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
 	"github.com/openkcm/common-sdk/pkg/jwtsigning"
 )
 
@@ -178,3 +184,189 @@ func TestClient(t *testing.T) {
 		})
 	})
 }
+
+func TestClientAuthentication(t *testing.T) {
+	expJWKS := jwtsigning.JWKS{Keys: []jwtsigning.Key{{Kty: "kty", Kid: "kid"}}}
+
+	serveJWKS := func(t *testing.T, authorize func(r *http.Request) bool) http.HandlerFunc {
+		t.Helper()
+
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !authorize(r) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			assert.NoError(t, json.NewEncoder(w).Encode(expJWKS))
+		}
+	}
+
+	embedded := func(value string) commoncfg.SourceRef {
+		return commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: value}
+	}
+
+	t.Run("with an injected http client", func(t *testing.T) {
+		// given
+		srv := httptest.NewTLSServer(serveJWKS(t, func(*http.Request) bool { return true }))
+		defer srv.Close()
+
+		// when
+		subj, err := jwtsigning.NewClientWithHTTPClient(srv.URL, srv.Client())
+		require.NoError(t, err)
+
+		res, err := subj.Get(t.Context())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, &expJWKS, res)
+	})
+
+	t.Run("with an api token", func(t *testing.T) {
+		// given
+		srv := httptest.NewServer(serveJWKS(t, func(r *http.Request) bool {
+			return r.Header.Get("Authorization") == "Api-Token secret-token"
+		}))
+		defer srv.Close()
+
+		// when
+		subj, err := jwtsigning.NewClientFromSecretRef(srv.URL, &commoncfg.SecretRef{
+			Type:     commoncfg.ApiTokenSecretType,
+			APIToken: embedded("secret-token"),
+		})
+		require.NoError(t, err)
+
+		res, err := subj.Get(t.Context())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, &expJWKS, res)
+	})
+
+	t.Run("with basic credentials", func(t *testing.T) {
+		// given
+		srv := httptest.NewServer(serveJWKS(t, func(r *http.Request) bool {
+			username, password, ok := r.BasicAuth()
+			return ok && username == "user" && password == "pass"
+		}))
+		defer srv.Close()
+
+		// when
+		subj, err := jwtsigning.NewClientFromSecretRef(srv.URL, &commoncfg.SecretRef{
+			Type: commoncfg.BasicSecretType,
+			Basic: commoncfg.BasicAuth{
+				Username: embedded("user"),
+				Password: embedded("pass"),
+			},
+		})
+		require.NoError(t, err)
+
+		res, err := subj.Get(t.Context())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, &expJWKS, res)
+	})
+
+	t.Run("with a client certificate", func(t *testing.T) {
+		// given
+		clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		clientCert := selfSignedCert(t, clientKey)
+
+		srv := httptest.NewUnstartedServer(serveJWKS(t, func(r *http.Request) bool {
+			return len(r.TLS.PeerCertificates) == 1 && r.TLS.PeerCertificates[0].Equal(clientCert)
+		}))
+		srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MinVersion: tls.VersionTLS12}
+		srv.StartTLS()
+
+		defer srv.Close()
+
+		serverCA := embedded(string(certPEM(srv.Certificate())))
+
+		// when
+		subj, err := jwtsigning.NewClientFromSecretRef(srv.URL, &commoncfg.SecretRef{
+			Type: commoncfg.MTLSSecretType,
+			MTLS: commoncfg.MTLS{
+				Cert:     embedded(string(certPEM(clientCert))),
+				CertKey:  embedded(string(ecPEM(t, clientKey))),
+				ServerCA: &serverCA,
+			},
+		})
+		require.NoError(t, err)
+
+		res, err := subj.Get(t.Context())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, &expJWKS, res)
+	})
+
+	t.Run("unauthenticated requests are rejected", func(t *testing.T) {
+		// given
+		srv := httptest.NewServer(serveJWKS(t, func(r *http.Request) bool {
+			return r.Header.Get("Authorization") != ""
+		}))
+		defer srv.Close()
+
+		// when
+		subj, err := jwtsigning.NewClientFromSecretRef(srv.URL, &commoncfg.SecretRef{Type: commoncfg.InsecureSecretType})
+		require.NoError(t, err)
+
+		_, err = subj.Get(t.Context())
+
+		// then
+		assert.ErrorIs(t, err, jwtsigning.ErrHTTPStatusNotOK)
+	})
+
+	t.Run("should return error", func(t *testing.T) {
+		tts := []struct {
+			name      string
+			endpoint  string
+			secretRef *commoncfg.SecretRef
+			expErr    error
+		}{
+			{
+				name:     "if the secret ref is nil",
+				endpoint: "https://issuer.example.com/jwks",
+				expErr:   jwtsigning.ErrNilSecretRef,
+			},
+			{
+				name:      "if the secret type is unsupported",
+				endpoint:  "https://issuer.example.com/jwks",
+				secretRef: &commoncfg.SecretRef{Type: "unknown"},
+				expErr:    jwtsigning.ErrSecretTypeUnsupported,
+			},
+			{
+				name:      "if the endpoint is invalid",
+				endpoint:  "not valid endpoint url",
+				secretRef: &commoncfg.SecretRef{Type: commoncfg.InsecureSecretType},
+				expErr:    jwtsigning.ErrInvalidURL,
+			},
+		}
+		for _, tt := range tts {
+			t.Run(tt.name, func(t *testing.T) {
+				// when
+				res, err := jwtsigning.NewClientFromSecretRef(tt.endpoint, tt.secretRef)
+
+				// then
+				assert.ErrorIs(t, err, tt.expErr)
+				assert.Nil(t, res)
+			})
+		}
+
+		t.Run("if the api token cannot be loaded", func(t *testing.T) {
+			res, err := jwtsigning.NewClientFromSecretRef("https://issuer.example.com/jwks", &commoncfg.SecretRef{
+				Type: commoncfg.ApiTokenSecretType,
+			})
+			assert.Error(t, err)
+			assert.Nil(t, res)
+		})
+
+		t.Run("if the http client is nil", func(t *testing.T) {
+			res, err := jwtsigning.NewClientWithHTTPClient("https://issuer.example.com/jwks", nil)
+			assert.ErrorIs(t, err, jwtsigning.ErrNilHTTPClient)
+			assert.Nil(t, res)
+		})
+	})
+}
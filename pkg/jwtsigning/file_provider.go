@@ -0,0 +1,279 @@
+package jwtsigning
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/openkcm/common-sdk/pkg/storage/keyvalue"
+)
+
+var (
+	_ PublicKeyProvider = &FileJWKSProvider{}
+	_ PublicKeyResolver = &FileJWKSProvider{}
+
+	// ErrNoStorageFound indicates no key storage configured for the given issuer.
+	ErrNoStorageFound = errors.New("no key storage found for issuer")
+
+	// ErrInvalidKeyFile is returned when a key file holds neither a JWKS nor PEM encoded
+	// certificates or a public key.
+	ErrInvalidKeyFile = errors.New("invalid key file")
+)
+
+// FileJWKSProvider provides the RSA, EC and Ed25519 public keys of issuers from files,
+// e.g. from a directory of mounted secrets watched by a commonfs loader, so air-gapped
+// deployments can distribute verification keys without HTTP. A file holds either:
+//   - a JWKS document, whose keys are looked up by their kid, or
+//   - PEM encoded certificates, the first one being the leaf, or a PEM encoded public
+//     key, which are looked up by the key ID of the file in the storage.
+//
+// The files are read from the storage on every lookup, so changed files are picked up
+// right away, and are parsed again only if their content changed.
+type FileJWKSProvider struct {
+	stores map[string]*fileKeyStore
+}
+
+// fileKeyStore groups the key storage of a single issuer, its optional validator and the
+// parsed keys of its files. The lock protects access to the parsed files.
+type fileKeyStore struct {
+	storage   keyvalue.ReadOnlyStringToBytesStorage
+	validator *Validator
+	files     map[string]keyFile
+	lock      sync.Mutex
+}
+
+// keyFile holds the public keys parsed from the content of a key file.
+type keyFile struct {
+	data []byte
+	keys map[string]crypto.PublicKey
+}
+
+// FileProviderOption configures the keys of an issuer added with AddStorage.
+type FileProviderOption func(*fileKeyStore)
+
+// WithCertificateValidator validates the certificate chains of the keys of the issuer
+// with the validator: the x5c of the keys of JWKS documents, and the certificates of PEM
+// files. Keys without certificates, e.g. PEM encoded public keys, are then rejected.
+func WithCertificateValidator(validator *Validator) FileProviderOption {
+	return func(s *fileKeyStore) {
+		s.validator = validator
+	}
+}
+
+// NewFileJWKSProvider creates and returns a new FileJWKSProvider instance
+// with initialized in-memory storage for issuer key stores.
+func NewFileJWKSProvider() *FileJWKSProvider {
+	return &FileJWKSProvider{
+		stores: make(map[string]*fileKeyStore),
+	}
+}
+
+// AddStorage registers the storage holding the key files of a given issuer, e.g. the
+// storage of a commonfs loader. Returns an error if the issuer is empty or the storage
+// is nil.
+func (p *FileJWKSProvider) AddStorage(issuer string, storage keyvalue.ReadOnlyStringToBytesStorage, opts ...FileProviderOption) error {
+	if issuer == "" {
+		return ErrIssuerEmpty
+	}
+
+	if storage == nil {
+		return fmt.Errorf("%w: %s", ErrNoStorageFound, issuer)
+	}
+
+	store := &fileKeyStore{
+		storage: storage,
+		files:   make(map[string]keyFile),
+	}
+
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	p.stores[issuer] = store
+
+	return nil
+}
+
+// VerificationKey returns the RSA public key for the given issuer (iss) and key ID (kid).
+// It returns ErrRSAPublicKeyNotFound if the key is not an RSA key, see PublicKey for the
+// other key types.
+func (p *FileJWKSProvider) VerificationKey(ctx context.Context, iss string, kid string) (*rsa.PublicKey, error) {
+	key, err := p.PublicKey(ctx, iss, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRSAPublicKeyNotFound, kid)
+	}
+
+	return rsaKey, nil
+}
+
+// PublicKey returns the public key for the given issuer (iss) and key ID (kid), which is
+// a *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey. Returns an error if the issuer
+// is not configured or none of its files holds a valid key with the kid.
+func (p *FileJWKSProvider) PublicKey(ctx context.Context, iss string, kid string) (crypto.PublicKey, error) {
+	ctx = slogctx.With(ctx, "issuer", iss, "kid", kid)
+
+	store, ok := p.stores[iss]
+	if !ok {
+		slogctx.Error(ctx, "no key storage configured")
+		return nil, fmt.Errorf("%w: %s", ErrNoStorageFound, iss)
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.sync(ctx)
+
+	// the files are searched in the order of their key IDs, so duplicate kids resolve
+	// to the same key on every lookup
+	for _, id := range slices.Sorted(maps.Keys(store.files)) {
+		key, ok := store.files[id].keys[kid]
+		if ok {
+			return key, nil
+		}
+	}
+
+	slogctx.Info(ctx, "no public key found in key files")
+
+	return nil, fmt.Errorf("%w: %s", ErrKidNoPublicKeyFound, kid)
+}
+
+// sync parses the files of the storage whose content changed since the last lookup, and
+// drops the files removed from the storage. Invalid files are logged once and skipped.
+func (s *fileKeyStore) sync(ctx context.Context) {
+	ids := s.storage.List()
+
+	for id := range s.files {
+		if !slices.Contains(ids, id) {
+			delete(s.files, id)
+		}
+	}
+
+	for _, id := range ids {
+		data, ok := s.storage.Get(id)
+		if !ok {
+			delete(s.files, id)
+			continue
+		}
+
+		if file, ok := s.files[id]; ok && bytes.Equal(file.data, data) {
+			continue
+		}
+
+		keys, err := s.parseKeyFile(ctx, id, data)
+		if err != nil {
+			slogctx.Error(ctx, "failed while parsing key file", "file", id, "error", err)
+		}
+
+		s.files[id] = keyFile{data: data, keys: keys}
+	}
+}
+
+// parseKeyFile returns the valid public keys of a JWKS document by their kid, or the
+// public key of PEM encoded certificates or a PEM encoded public key by the key ID of
+// the file.
+func (s *fileKeyStore) parseKeyFile(ctx context.Context, id string, data []byte) (map[string]crypto.PublicKey, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return s.parseJWKSFile(ctx, data)
+	}
+
+	var (
+		certs  []*x509.Certificate
+		pubKey crypto.PublicKey
+	)
+
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrParseCertificate, err)
+			}
+
+			certs = append(certs, cert)
+		case "PUBLIC KEY":
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidKeyFile, err)
+			}
+
+			pubKey = key
+		}
+	}
+
+	if len(certs) > 0 {
+		pubKey = certs[0].PublicKey
+	}
+
+	if pubKey == nil {
+		return nil, ErrInvalidKeyFile
+	}
+
+	if s.validator != nil {
+		key := Key{X5c: make([]string, 0, len(certs))}
+		for _, cert := range certs {
+			key.X5c = append(key.X5c, base64.StdEncoding.EncodeToString(cert.Raw))
+		}
+
+		err := s.validator.ValidateContext(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err := keyTypeOf(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]crypto.PublicKey{id: pubKey}, nil
+}
+
+// parseJWKSFile returns the valid public keys of a JWKS document by their kid. Like the
+// keys fetched by the JWKSProvider, the keys must carry their certificate.
+func (s *fileKeyStore) parseJWKSFile(ctx context.Context, data []byte) (map[string]crypto.PublicKey, error) {
+	var jwks JWKS
+
+	err := json.Unmarshal(data, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKeyFile, err)
+	}
+
+	pubKeys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+
+	for _, jwk := range jwks.Keys {
+		if s.validator != nil {
+			err := s.validator.ValidateContext(ctx, jwk)
+			if err != nil {
+				slogctx.Error(ctx, "failed certificate validation", "for kid", jwk.Kid, "error", err)
+				continue
+			}
+		}
+
+		pubKey, err := parsePublicKey(ctx, jwk, false)
+		if err != nil {
+			slogctx.Error(ctx, "failed while parsing public keys", "for kid", jwk.Kid, "error", err)
+			continue
+		}
+
+		pubKeys[jwk.Kid] = pubKey
+	}
+
+	return pubKeys, nil
+}
@@ -0,0 +1,150 @@
+package jwtsigning_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commonfs/loader"
+	"github.com/openkcm/common-sdk/pkg/jwtsigning"
+	"github.com/openkcm/common-sdk/pkg/storage/keyvalue"
+)
+
+func TestFileJWKSProvider(t *testing.T) {
+	notBefore := time.Now()
+	notAfter := notBefore.Add(24 * time.Hour)
+
+	rootKey, rootDer := rootCertificate(t, notBefore, notAfter)
+	rootCa, err := x509.ParseCertificate(rootDer)
+	require.NoError(t, err)
+
+	intKey, intDer := intermediateCertificate(t, rootDer, notBefore, notAfter, rootKey)
+	intCert, err := x509.ParseCertificate(intDer)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, _ := generateKeysAndCert(t)
+
+	ecLeaf, err := x509.ParseCertificate(leafCertWithPublicKey(t, intDer, notBefore, notAfter, intKey, validSubject, &ecKey.PublicKey))
+	require.NoError(t, err)
+	edLeaf, err := x509.ParseCertificate(leafCertWithPublicKey(t, intDer, notBefore, notAfter, intKey, validSubject, edPub))
+	require.NoError(t, err)
+
+	jwks, err := jwtsigning.NewJWKS(jwtsigning.Input{
+		Kty:       jwtsigning.KeyTypeOKP,
+		Kid:       "kid-okp",
+		X509Certs: []x509.Certificate{*edLeaf, *intCert},
+	})
+	require.NoError(t, err)
+
+	jwksData, err := json.Marshal(jwks)
+	require.NoError(t, err)
+
+	pubDer, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	storage := keyvalue.NewMemoryStorage[string, []byte]()
+	storage.Store("issuer-keys", jwksData)
+	storage.Store("kid-cert", append(certPEM(ecLeaf), certPEM(intCert)...))
+	storage.Store("kid-pub", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDer}))
+	storage.Store("broken", []byte("not a key"))
+
+	validator, err := jwtsigning.NewValidator(rootCa, validSubjString)
+	require.NoError(t, err)
+
+	t.Run("AddStorage fails", func(t *testing.T) {
+		subj := jwtsigning.NewFileJWKSProvider()
+
+		assert.ErrorIs(t, subj.AddStorage("", storage), jwtsigning.ErrIssuerEmpty)
+		assert.ErrorIs(t, subj.AddStorage("issuer-1", nil), jwtsigning.ErrNoStorageFound)
+	})
+
+	t.Run("returns the keys of the files", func(t *testing.T) {
+		subj := jwtsigning.NewFileJWKSProvider()
+		require.NoError(t, subj.AddStorage("issuer-1", storage))
+
+		result, err := subj.PublicKey(t.Context(), "issuer-1", "kid-okp")
+		require.NoError(t, err)
+		assert.Equal(t, edPub, result)
+
+		result, err = subj.PublicKey(t.Context(), "issuer-1", "kid-cert")
+		require.NoError(t, err)
+		assert.True(t, ecKey.PublicKey.Equal(result))
+
+		rsaResult, err := subj.VerificationKey(t.Context(), "issuer-1", "kid-pub")
+		require.NoError(t, err)
+		assert.True(t, rsaKey.PublicKey.Equal(rsaResult))
+
+		_, err = subj.VerificationKey(t.Context(), "issuer-1", "kid-cert")
+		assert.ErrorIs(t, err, jwtsigning.ErrRSAPublicKeyNotFound)
+
+		_, err = subj.PublicKey(t.Context(), "issuer-1", "broken")
+		assert.ErrorIs(t, err, jwtsigning.ErrKidNoPublicKeyFound)
+
+		_, err = subj.PublicKey(t.Context(), "issuer-2", "kid-okp")
+		assert.ErrorIs(t, err, jwtsigning.ErrNoStorageFound)
+	})
+
+	t.Run("rejects keys without a valid certificate chain with a validator", func(t *testing.T) {
+		subj := jwtsigning.NewFileJWKSProvider()
+		require.NoError(t, subj.AddStorage("issuer-1", storage, jwtsigning.WithCertificateValidator(validator)))
+
+		_, err := subj.PublicKey(t.Context(), "issuer-1", "kid-okp")
+		require.NoError(t, err)
+
+		_, err = subj.PublicKey(t.Context(), "issuer-1", "kid-cert")
+		require.NoError(t, err)
+
+		_, err = subj.PublicKey(t.Context(), "issuer-1", "kid-pub")
+		assert.ErrorIs(t, err, jwtsigning.ErrKidNoPublicKeyFound)
+	})
+
+	t.Run("picks up changed files of a commonfs loader", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "kid-1.pem"), certPEM(ecLeaf), 0o600))
+
+		ldr, err := loader.Create(
+			loader.OnPath(dir),
+			loader.WithExtension(".pem"),
+			loader.WithKeyIDType(loader.FileNameWithoutExtension),
+		)
+		require.NoError(t, err)
+		require.NoError(t, ldr.Start())
+
+		defer ldr.Close()
+
+		subj := jwtsigning.NewFileJWKSProvider()
+		require.NoError(t, subj.AddStorage("issuer-1", ldr.Storage()))
+
+		result, err := subj.PublicKey(t.Context(), "issuer-1", "kid-1")
+		require.NoError(t, err)
+		assert.True(t, ecKey.PublicKey.Equal(result))
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "kid-2.pem"), certPEM(edLeaf), 0o600))
+		require.NoError(t, os.Remove(filepath.Join(dir, "kid-1.pem")))
+
+		assert.Eventually(t, func() bool {
+			result, err := subj.PublicKey(t.Context(), "issuer-1", "kid-2")
+			return err == nil && edPub.Equal(result)
+		}, 5*time.Second, 20*time.Millisecond)
+
+		assert.Eventually(t, func() bool {
+			_, err := subj.PublicKey(t.Context(), "issuer-1", "kid-1")
+			return err != nil
+		}, 5*time.Second, 20*time.Millisecond)
+	})
+}
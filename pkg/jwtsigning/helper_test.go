@@ -2,6 +2,7 @@ package jwtsigning_test
 
 import (
 	"context"
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -44,6 +45,19 @@ func (s *stubPublicKeyProvider) VerificationKey(ctx context.Context, iss, kid st
 	return s.key, nil
 }
 
+type stubPublicKeyResolver struct {
+	stubPublicKeyProvider
+
+	publicKey crypto.PublicKey
+}
+
+func (s *stubPublicKeyResolver) PublicKey(ctx context.Context, iss, kid string) (crypto.PublicKey, error) {
+	s.lastIss = iss
+	s.lastKid = kid
+
+	return s.publicKey, s.err
+}
+
 type hasherStub struct {
 	hash string
 	alg  string
@@ -136,10 +150,16 @@ func intermediateCertificate(t *testing.T, rootDer []byte, notBefore time.Time,
 func leafCertWithParams(t *testing.T, intDer []byte, notBefore time.Time, notAfter time.Time, intKey *rsa.PrivateKey, subject pkix.Name) []byte {
 	t.Helper()
 
-	intCert, err := x509.ParseCertificate(intDer)
+	leafKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	require.NoError(t, err)
 
-	leafKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	return leafCertWithPublicKey(t, intDer, notBefore, notAfter, intKey, subject, leafKey.Public())
+}
+
+func leafCertWithPublicKey(t *testing.T, intDer []byte, notBefore time.Time, notAfter time.Time, intKey *rsa.PrivateKey, subject pkix.Name, leafPub any) []byte {
+	t.Helper()
+
+	intCert, err := x509.ParseCertificate(intDer)
 	require.NoError(t, err)
 
 	leafTmpl := &x509.Certificate{
@@ -154,7 +174,7 @@ func leafCertWithParams(t *testing.T, intDer []byte, notBefore time.Time, notAft
 	leafDer, err := x509.CreateCertificate(rand.Reader,
 		leafTmpl,
 		intCert,
-		leafKey.Public(),
+		leafPub,
 		intKey)
 	require.NoError(t, err)
 
@@ -1,6 +1,9 @@
 package jwtsigning
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
@@ -10,6 +13,7 @@ import (
 	"io"
 	"math/big"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -18,19 +22,24 @@ type JWKS struct {
 	Keys []Key `json:"keys"`
 }
 
-// KeyType specifies the type of cryptographic key (e.g., "RSA").
+// KeyType specifies the type of cryptographic key (e.g., "RSA", "EC" or "OKP").
 type KeyType string
 
 // Key defines the structure of a single JSON Web Key.
 type Key struct {
-	Kty    KeyType  `json:"kty"`     // Key type (e.g., "RSA")
-	Alg    string   `json:"alg"`     // Algorithm intended for use with the key
-	Use    string   `json:"use"`     // Intended use of the public key
-	KeyOps []string `json:"key_ops"` // Permitted operations for the key
-	Kid    string   `json:"kid"`     // Key ID
-	X5c    []string `json:"x5c"`     // X.509 certificate chain
-	N      string   `json:"n"`       // RSA modulus
-	E      string   `json:"e"`       // RSA public exponent
+	Kty    KeyType  `json:"kty"`           // Key type (e.g., "RSA")
+	Alg    string   `json:"alg"`           // Algorithm intended for use with the key
+	Use    string   `json:"use"`           // Intended use of the public key
+	KeyOps []string `json:"key_ops"`       // Permitted operations for the key
+	Kid    string   `json:"kid"`           // Key ID
+	X5c    []string `json:"x5c,omitempty"` // X.509 certificate chain
+	N      string   `json:"n,omitempty"`   // RSA modulus
+	E      string   `json:"e,omitempty"`   // RSA public exponent
+
+	// EC and OKP keys, see RFC 7518 section 6.2 and RFC 8037
+	Crv string `json:"crv,omitempty"` // Curve, e.g. P-256 or Ed25519
+	X   string `json:"x,omitempty"`   // x coordinate of EC keys, public key of OKP keys
+	Y   string `json:"y,omitempty"`   // y coordinate of EC keys
 }
 
 // Input is used to build JWKS from a set of keys and certificates.
@@ -51,12 +60,46 @@ type thumbprint struct {
 	N   string `json:"n"`
 }
 
+// ecThumbprint is used for computing the thumbprint of an EC JWK.
+// DO NOT change field order: required for RFC 7638 thumbprint calculation.
+type ecThumbprint struct {
+	Crv string `json:"crv"`
+	Kty string `json:"kty"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// okpThumbprint is used for computing the thumbprint of an OKP JWK, see RFC 8037 section 2.
+// DO NOT change field order: required for RFC 7638 thumbprint calculation.
+type okpThumbprint struct {
+	Crv string `json:"crv"`
+	Kty string `json:"kty"`
+	X   string `json:"x"`
+}
+
 // KeyTypeRSA is the constant for RSA key type.
 var KeyTypeRSA KeyType = "RSA"
 
+// KeyTypeEC is the constant for elliptic curve key type, used with the P-256 and P-384 curves.
+var KeyTypeEC KeyType = "EC"
+
+// KeyTypeOKP is the constant for octet key pair key type, used with the Ed25519 curve.
+var KeyTypeOKP KeyType = "OKP"
+
+// The curves of the EC and OKP keys.
+const (
+	CurveP256    = "P-256"
+	CurveP384    = "P-384"
+	CurveEd25519 = "Ed25519"
+)
+
 var (
 	// ErrRSAPublicKeyNotFound is returned when a certificate does not contain an RSA public key.
 	ErrRSAPublicKeyNotFound = errors.New("not a RSA public key")
+	// ErrPublicKeyTypeMismatch is returned when a certificate does not contain a public key of the key type.
+	ErrPublicKeyTypeMismatch = errors.New("public key does not match the key type")
+	// ErrCurveUnsupported is returned when an unsupported curve is encountered.
+	ErrCurveUnsupported = errors.New("curve unsupported")
 	// ErrCertificateNotFound is returned when no certificate is provided.
 	ErrCertificateNotFound = errors.New("certificate not found")
 	// ErrNoKeysFound is returned when no keys are found in the JWKS (JSON Web Key Set).
@@ -138,7 +181,7 @@ func (j *JWKS) Decode(r io.Reader) error {
 }
 
 func (k Key) Validate() error {
-	if k.Kty != KeyTypeRSA {
+	if k.Kty != KeyTypeRSA && k.Kty != KeyTypeEC && k.Kty != KeyTypeOKP {
 		return fmt.Errorf("%w %s", ErrInvalidKey, "type is invalid")
 	}
 
@@ -162,19 +205,47 @@ func (k Key) Validate() error {
 		return fmt.Errorf("%w %s", ErrInvalidKey, "x5c is empty")
 	}
 
-	if isEmpty(k.N) {
-		return fmt.Errorf("%w %s", ErrInvalidKey, "n is invalid")
-	}
+	return k.validatePublicKey()
+}
+
+// validatePublicKey checks the public key parameters of the key type.
+func (k Key) validatePublicKey() error {
+	switch k.Kty {
+	case KeyTypeEC:
+		if k.Crv != CurveP256 && k.Crv != CurveP384 {
+			return fmt.Errorf("%w %s", ErrInvalidKey, "crv is invalid")
+		}
+
+		if isEmpty(k.X) {
+			return fmt.Errorf("%w %s", ErrInvalidKey, "x is invalid")
+		}
+
+		if isEmpty(k.Y) {
+			return fmt.Errorf("%w %s", ErrInvalidKey, "y is invalid")
+		}
+	case KeyTypeOKP:
+		if k.Crv != CurveEd25519 {
+			return fmt.Errorf("%w %s", ErrInvalidKey, "crv is invalid")
+		}
+
+		if isEmpty(k.X) {
+			return fmt.Errorf("%w %s", ErrInvalidKey, "x is invalid")
+		}
+	default:
+		if isEmpty(k.N) {
+			return fmt.Errorf("%w %s", ErrInvalidKey, "n is invalid")
+		}
 
-	if isEmpty(k.E) {
-		return fmt.Errorf("%w %s", ErrInvalidKey, "e is invalid")
+		if isEmpty(k.E) {
+			return fmt.Errorf("%w %s", ErrInvalidKey, "e is invalid")
+		}
 	}
 
 	return nil
 }
 
 // Thumbprint computes the thumbprint of the Key according to RFC 7638.
-// It currently supports only RSA keys.
+// It supports RSA, EC and OKP keys.
 // Returns the thumbprint as a base64url-encoded string or an error if the key type is unsupported or invalid.
 // please refer to the example in RFC 7638 section 3.1
 // https://www.rfc-editor.org/rfc/rfc7638#section-3.1
@@ -200,6 +271,24 @@ func (k Key) Thumbprint() (string, error) {
 
 		return (&SHA256Hasher{}).HashMessage(b), nil
 
+	case KeyTypeEC, KeyTypeOKP:
+		err := k.validatePublicKey()
+		if err != nil {
+			return "", err
+		}
+
+		var members any = ecThumbprint{Crv: k.Crv, Kty: string(k.Kty), X: k.X, Y: k.Y}
+		if k.Kty == KeyTypeOKP {
+			members = okpThumbprint{Crv: k.Crv, Kty: string(k.Kty), X: k.X}
+		}
+
+		b, err := json.Marshal(members)
+		if err != nil {
+			return "", err
+		}
+
+		return (&SHA256Hasher{}).HashMessage(b), nil
+
 	default:
 		return "", fmt.Errorf("%w %s", ErrKeyTypeUnsupported, k.Kty)
 	}
@@ -207,8 +296,9 @@ func (k Key) Thumbprint() (string, error) {
 
 // build constructs a Key from the KeyInput.
 // It encodes the provided X.509 certificates to base64 and sets them in the Key's X5c field.
-// For RSA keys, it extracts the modulus (N) and exponent (E) from the first certificate's public key.
-// Returns an error if the key type is unsupported or if the RSA public key cannot be found.
+// For RSA keys, it extracts the modulus (N) and exponent (E) from the first certificate's public key,
+// for EC keys the curve and coordinates, and for OKP keys the curve and the Ed25519 public key.
+// Returns an error if the key type is unsupported or does not match the certificate's public key.
 func (i Input) build() (Key, error) {
 	x5cs := make([]string, 0, len(i.X509Certs))
 
@@ -232,18 +322,9 @@ func (i Input) build() (Key, error) {
 		X5c:    x5cs,
 	}
 
-	switch i.Kty {
-	case KeyTypeRSA:
-		publicKey, ok := firstCert.PublicKey.(*rsa.PublicKey)
-		if !ok {
-			return Key{}, fmt.Errorf("%w kid %s", ErrRSAPublicKeyNotFound, i.Kid)
-		}
-
-		b64RawURLEncoder := base64.RawURLEncoding
-		key.N = b64RawURLEncoder.EncodeToString(publicKey.N.Bytes())
-		key.E = b64RawURLEncoder.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
-	default:
-		return Key{}, fmt.Errorf("%w [%s]", ErrKeyTypeUnsupported, i.Kty)
+	err := key.setPublicKey(firstCert.PublicKey)
+	if err != nil {
+		return Key{}, err
 	}
 
 	if isEmpty(key.Kid) {
@@ -258,6 +339,120 @@ func (i Input) build() (Key, error) {
 	return key, nil
 }
 
+// setPublicKey sets the public key parameters of the key type from the public key.
+// Returns an error if the key type is unsupported or does not match the public key.
+func (k *Key) setPublicKey(pub crypto.PublicKey) error {
+	switch k.Kty {
+	case KeyTypeRSA:
+		publicKey, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w kid %s", ErrRSAPublicKeyNotFound, k.Kid)
+		}
+
+		b64RawURLEncoder := base64.RawURLEncoding
+		k.N = b64RawURLEncoder.EncodeToString(publicKey.N.Bytes())
+		k.E = b64RawURLEncoder.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case KeyTypeEC:
+		publicKey, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w kid %s", ErrPublicKeyTypeMismatch, k.Kid)
+		}
+
+		crv, x, y, err := ecCoordinates(publicKey)
+		if err != nil {
+			return fmt.Errorf("%w kid %s", err, k.Kid)
+		}
+
+		k.Crv, k.X, k.Y = crv, x, y
+	case KeyTypeOKP:
+		publicKey, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w kid %s", ErrPublicKeyTypeMismatch, k.Kid)
+		}
+
+		k.Crv = CurveEd25519
+		k.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	default:
+		return fmt.Errorf("%w [%s]", ErrKeyTypeUnsupported, k.Kty)
+	}
+
+	return nil
+}
+
+// keyTypeOf returns the key type of the public key.
+func keyTypeOf(pub crypto.PublicKey) (KeyType, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return KeyTypeRSA, nil
+	case *ecdsa.PublicKey:
+		return KeyTypeEC, nil
+	case ed25519.PublicKey:
+		return KeyTypeOKP, nil
+	default:
+		return "", fmt.Errorf("%w [%T]", ErrKeyTypeUnsupported, pub)
+	}
+}
+
+// RSAPublicKey decodes the RSA public key of the modulus (N) and exponent (E), which are
+// base64url encoded big-endian integers as of RFC 7518 section 6.3.1.
+func (k Key) RSAPublicKey() (*rsa.PublicKey, error) {
+	if k.Kty != KeyTypeRSA {
+		return nil, fmt.Errorf("%w [%s]", ErrKeyTypeUnsupported, k.Kty)
+	}
+
+	b64RawURLEncoder := base64.RawURLEncoding
+
+	n, err := b64RawURLEncoder.DecodeString(k.N)
+	if err != nil || len(n) == 0 {
+		return nil, fmt.Errorf("%w %s", ErrInvalidKey, "n is invalid")
+	}
+
+	e, err := b64RawURLEncoder.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("%w %s", ErrInvalidKey, "e is invalid")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+// legacyRSAPublicKey decodes the RSA public key of the modulus (N) and exponent (E) in
+// the legacy format of decimal strings.
+func (k Key) legacyRSAPublicKey() (*rsa.PublicKey, error) {
+	n, ok := new(big.Int).SetString(k.N, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrInvalidKey, "n is invalid")
+	}
+
+	e, err := strconv.Atoi(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s", ErrInvalidKey, "e is invalid")
+	}
+
+	return &rsa.PublicKey{N: n, E: e}, nil
+}
+
+// ecCoordinates returns the curve name and the base64url encoded coordinates of the key.
+func ecCoordinates(publicKey *ecdsa.PublicKey) (string, string, string, error) {
+	crv := publicKey.Curve.Params().Name
+	if crv != CurveP256 && crv != CurveP384 {
+		return "", "", "", fmt.Errorf("%w [%s]", ErrCurveUnsupported, crv)
+	}
+
+	// the uncompressed point is 0x04 followed by the x and y coordinates of equal size
+	point, err := publicKey.Bytes()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	size := (len(point) - 1) / 2
+	b64RawURLEncoder := base64.RawURLEncoding
+
+	return crv, b64RawURLEncoder.EncodeToString(point[1 : 1+size]), b64RawURLEncoder.EncodeToString(point[1+size:]), nil
+}
+
 // hasEmptyValues checks if the provided slice of strings contains any empty values.
 func hasEmptyValues(values []string) bool {
 	if len(values) == 0 {
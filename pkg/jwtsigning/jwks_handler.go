@@ -0,0 +1,177 @@
+package jwtsigning
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// WellKnownJWKSPath is the path the JWKS of an issuer is conventionally served at.
+	WellKnownJWKSPath = "/.well-known/jwks.json"
+
+	// JWKSContentType is the media type of a JWKS, see RFC 7517 section 8.5.
+	JWKSContentType = "application/jwk-set+json"
+
+	// defaultJWKSMaxAge is the default time clients may cache the served JWKS.
+	defaultJWKSMaxAge = 5 * time.Minute
+)
+
+var (
+	_ JWKSSource   = &JWKS{}
+	_ JWKSSource   = &KeyRing{}
+	_ http.Handler = &JWKSHandler{}
+
+	// ErrNilJWKSSource is returned when a JWKS handler is created without a source.
+	ErrNilJWKSSource = errors.New("jwks source cannot be nil")
+)
+
+// JWKSSource supplies the public JWKS served by a JWKSHandler, e.g. a KeyRing or a
+// JWKS created by NewJWKS.
+type JWKSSource interface {
+	// PublicJWKS returns the JWKS to serve.
+	PublicJWKS() (*JWKS, error)
+}
+
+// JWKSSourceFunc is an adapter to use a function as JWKSSource, e.g. to build the
+// JWKS from the certificates of a commonfs loader.
+type JWKSSourceFunc func() (*JWKS, error)
+
+// PublicJWKS calls f().
+func (f JWKSSourceFunc) PublicJWKS() (*JWKS, error) {
+	return f()
+}
+
+// PublicJWKS returns the JWKS itself, so a static JWKS can be served.
+func (j *JWKS) PublicJWKS() (*JWKS, error) {
+	return j, nil
+}
+
+// JWKSHandler serves the JWKS of a source, typically at WellKnownJWKSPath. The JWKS is
+// read from the source on every request, so rotated keys are served right away, and
+// clients are allowed to cache it for the max age.
+type JWKSHandler struct {
+	source JWKSSource
+	maxAge time.Duration
+}
+
+// JWKSHandlerOption configures a JWKSHandler.
+type JWKSHandlerOption func(*JWKSHandler)
+
+// WithMaxAge sets the time clients may cache the JWKS, five minutes by default. It
+// should be well below the overlap window of rotated keys, so clients pick up new keys
+// before they are used. Zero disables caching.
+func WithMaxAge(maxAge time.Duration) JWKSHandlerOption {
+	return func(h *JWKSHandler) {
+		h.maxAge = maxAge
+	}
+}
+
+// NewJWKSHandler creates a handler serving the JWKS of the source.
+func NewJWKSHandler(source JWKSSource, opts ...JWKSHandlerOption) (*JWKSHandler, error) {
+	if source == nil {
+		return nil, ErrNilJWKSSource
+	}
+
+	h := &JWKSHandler{
+		source: source,
+		maxAge: defaultJWKSMaxAge,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h, nil
+}
+
+// ServeHTTP writes the JWKS with caching headers. It answers conditional requests
+// with the ETag of the JWKS with 304 Not Modified.
+func (h *JWKSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	body, err := h.marshal()
+	if err != nil {
+		slogctx.Error(r.Context(), "failed to build jwks", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+
+	w.Header().Set("ETag", etag)
+
+	if h.maxAge > 0 {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.maxAge.Seconds())))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", JWKSContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(body)
+	}
+}
+
+// HTTPBody returns the JWKS as body of a gRPC method, e.g. of a method mapped to
+// WellKnownJWKSPath by the gRPC gateway. It returns an Internal status error if the
+// JWKS cannot be built.
+func (h *JWKSHandler) HTTPBody(ctx context.Context) (*httpbody.HttpBody, error) {
+	body, err := h.marshal()
+	if err != nil {
+		slogctx.Error(ctx, "failed to build jwks", "error", err)
+		return nil, status.Error(codes.Internal, "failed to build jwks")
+	}
+
+	return &httpbody.HttpBody{
+		ContentType: JWKSContentType,
+		Data:        body,
+	}, nil
+}
+
+func (h *JWKSHandler) marshal() ([]byte, error) {
+	jwks, err := h.source.PublicJWKS()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jwks)
+}
+
+// etagMatches reports whether the If-None-Match header matches the ETag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,168 @@
+package jwtsigning_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openkcm/common-sdk/pkg/jwtsigning"
+)
+
+func TestJWKSHandler(t *testing.T) {
+	ecKey1, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecKey2, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ring, err := jwtsigning.NewKeyRing("issuer", time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, ring.Rotate("kid-1", jwtsigning.SigningKey{Key: ecKey1}))
+
+	handler, err := jwtsigning.NewJWKSHandler(ring, jwtsigning.WithMaxAge(time.Minute))
+	require.NoError(t, err)
+
+	get := func(t *testing.T, etag string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, jwtsigning.WellKnownJWKSPath, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	kids := func(t *testing.T, rec *httptest.ResponseRecorder) []string {
+		t.Helper()
+
+		var jwks jwtsigning.JWKS
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &jwks))
+
+		result := make([]string, 0, len(jwks.Keys))
+		for _, key := range jwks.Keys {
+			result = append(result, key.Kid)
+		}
+
+		return result
+	}
+
+	rec := get(t, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, jwtsigning.JWKSContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, []string{"kid-1"}, kids(t, rec))
+
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	t.Run("answers conditional requests with not modified", func(t *testing.T) {
+		rec := get(t, etag)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.Bytes())
+
+		rec = get(t, `"other", W/`+etag)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	t.Run("serves rotated keys right away", func(t *testing.T) {
+		require.NoError(t, ring.Rotate("kid-2", jwtsigning.SigningKey{Key: ecKey2}))
+
+		rec := get(t, etag)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []string{"kid-2", "kid-1"}, kids(t, rec))
+		assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	})
+
+	t.Run("HEAD requests have no body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, jwtsigning.WellKnownJWKSPath, nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Content-Length"))
+		assert.Empty(t, rec.Body.Bytes())
+	})
+
+	t.Run("rejects other methods", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, jwtsigning.WellKnownJWKSPath, nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+	})
+
+	t.Run("HTTPBody returns the JWKS", func(t *testing.T) {
+		body, err := handler.HTTPBody(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, jwtsigning.JWKSContentType, body.GetContentType())
+
+		var jwks jwtsigning.JWKS
+		require.NoError(t, json.Unmarshal(body.GetData(), &jwks))
+		assert.Len(t, jwks.Keys, 2)
+	})
+}
+
+func TestJWKSHandlerSources(t *testing.T) {
+	t.Run("NewJWKSHandler fails without source", func(t *testing.T) {
+		handler, err := jwtsigning.NewJWKSHandler(nil)
+		assert.ErrorIs(t, err, jwtsigning.ErrNilJWKSSource)
+		assert.Nil(t, handler)
+	})
+
+	t.Run("serves a static JWKS", func(t *testing.T) {
+		_, cert := generateKeysAndCert(t)
+
+		jwks, err := jwtsigning.NewJWKS(jwtsigning.Input{
+			Kty:    jwtsigning.KeyTypeRSA,
+			Alg:    "PS256",
+			Use:    "sig",
+			KeyOps: []string{"verify"},
+			Kid:    "kid-1",
+			X509Certs: []x509.Certificate{
+				*cert,
+			},
+		})
+		require.NoError(t, err)
+
+		handler, err := jwtsigning.NewJWKSHandler(jwks, jwtsigning.WithMaxAge(0))
+		require.NoError(t, err)
+
+		srv := httptest.NewServer(handler)
+		defer srv.Close()
+
+		cli, err := jwtsigning.NewClient(srv.URL)
+		require.NoError(t, err)
+
+		result, err := cli.Get(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, jwks, result)
+	})
+
+	t.Run("fails if the source fails", func(t *testing.T) {
+		handler, err := jwtsigning.NewJWKSHandler(jwtsigning.JWKSSourceFunc(func() (*jwtsigning.JWKS, error) {
+			return nil, errors.New("source failed")
+		}))
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, jwtsigning.WellKnownJWKSPath, nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		_, err = handler.HTTPBody(t.Context())
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
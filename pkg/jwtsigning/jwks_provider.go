@@ -2,18 +2,32 @@ package jwtsigning
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"sync"
+	"time"
 
 	slogctx "github.com/veqryn/slog-context"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// defaultNegativeCacheTTL is the default time kids not found in the JWKS are cached.
+	defaultNegativeCacheTTL = 5 * time.Second
+	// defaultMaxKeys is the default maximum number of cached keys and missing kids of an issuer.
+	defaultMaxKeys = 1000
 )
 
 var (
 	_ PublicKeyProvider = &JWKSProvider{}
+	_ PublicKeyResolver = &JWKSProvider{}
 
 	// ErrNoClientFound indicates no JWKS client configured for the given issuer.
 	ErrNoClientFound = errors.New("no client found for issuer")
@@ -28,7 +42,7 @@ var (
 	ErrIssuerEmpty = errors.New("issuer is empty")
 )
 
-// JWKSProvider fetches JWKS for issuers and provides RSA public keys.
+// JWKSProvider fetches JWKS for issuers and provides RSA, EC and Ed25519 public keys.
 // It maintains a map of issuer to JWKSClientStore which holds a client,
 // validator and an in-memory cache of public keys.
 type JWKSProvider struct {
@@ -36,12 +50,81 @@ type JWKSProvider struct {
 }
 
 // jwksClientStore groups a JWKS client, its validator and an in-memory cache
-// of parsed RSA public keys for a single issuer. The lock protects access to the cache.
+// of parsed public keys for a single issuer. The lock protects access to the cache
+// and the missing kids.
 type jwksClientStore struct {
 	client    *Client
 	validator *Validator
-	cache     map[string]*rsa.PublicKey
+	cache     map[string]cachedKey
 	lock      sync.RWMutex
+
+	refreshInterval time.Duration // interval of the background refresh, zero to refresh on cache misses only
+	refreshJitter   time.Duration // maximum random deviation from the refresh interval
+
+	legacyKeyEncoding bool // accept RSA moduli and exponents encoded as decimal strings
+
+	fetches          singleflight.Group   // deduplicates concurrent fetches of the JWKS
+	missingKids      map[string]time.Time // kids not found in the JWKS, until when they are not fetched again
+	negativeCacheTTL time.Duration        // how long kids not found in the JWKS are cached
+
+	maxKeys int           // maximum number of cached keys and missing kids
+	keyTTL  time.Duration // how long fetched keys are cached, zero to cache them until the next fetch
+}
+
+// cachedKey is a cached public key with its expiry.
+type cachedKey struct {
+	key       crypto.PublicKey
+	expiresAt time.Time // zero if the key does not expire
+}
+
+// ClientOption configures the keys of an issuer added with AddClient.
+type ClientOption func(*jwksClientStore)
+
+// WithRefreshInterval refreshes the keys of the issuer every interval, plus or minus a
+// random jitter, while the provider runs (see JWKSProvider.Run), so rotated keys are
+// picked up before they are used. The jitter is capped by the interval.
+func WithRefreshInterval(interval, jitter time.Duration) ClientOption {
+	return func(s *jwksClientStore) {
+		s.refreshInterval = interval
+		s.refreshJitter = min(jitter, interval)
+	}
+}
+
+// WithNegativeCacheTTL sets how long a kid which is not found in the JWKS of the issuer is
+// remembered, so requests for it do not fetch the JWKS again meanwhile, e.g. while tokens
+// signed with a key which is not yet published arrive during a rollover. It defaults to
+// five seconds, zero disables the negative caching.
+func WithNegativeCacheTTL(ttl time.Duration) ClientOption {
+	return func(s *jwksClientStore) {
+		s.negativeCacheTTL = ttl
+	}
+}
+
+// WithMaxKeys limits the number of cached keys of the issuer, and of the cached kids which
+// were not found, to maxKeys, 1000 by default. Keys beyond the limit are skipped in the
+// order of the JWKS.
+func WithMaxKeys(maxKeys int) ClientOption {
+	return func(s *jwksClientStore) {
+		s.maxKeys = maxKeys
+	}
+}
+
+// WithKeyTTL evicts the cached keys of the issuer once the TTL has passed since they were
+// fetched, so the next request for them fetches the JWKS again. By default, keys are
+// cached until the next fetch.
+func WithKeyTTL(ttl time.Duration) ClientOption {
+	return func(s *jwksClientStore) {
+		s.keyTTL = ttl
+	}
+}
+
+// WithLegacyKeyEncoding accepts RSA keys of the issuer whose modulus (n) and exponent (e)
+// are decimal strings, as published by older issuers, in addition to the base64url
+// encoding of RFC 7518. It is meant for the migration of issuers to the standard encoding.
+func WithLegacyKeyEncoding() ClientOption {
+	return func(s *jwksClientStore) {
+		s.legacyKeyEncoding = true
+	}
 }
 
 // NewJWKSProvider creates and returns a new JWKSProvider instance
@@ -55,7 +138,7 @@ func NewJWKSProvider() *JWKSProvider {
 // AddClient registers a client and validator for a given issuer.
 // It initializes the in-memory cache for the issuer. Returns an error if the
 // issuer, client or validator is nil.
-func (j *JWKSProvider) AddClient(issuer string, client *Client, validator *Validator) error {
+func (j *JWKSProvider) AddClient(issuer string, client *Client, validator *Validator, opts ...ClientOption) error {
 	if issuer == "" {
 		return ErrIssuerEmpty
 	}
@@ -68,20 +151,48 @@ func (j *JWKSProvider) AddClient(issuer string, client *Client, validator *Valid
 		return fmt.Errorf("%w: %s", ErrNoValidatorFound, issuer)
 	}
 
-	j.stores[issuer] = &jwksClientStore{
-		client:    client,
-		validator: validator,
-		cache:     make(map[string]*rsa.PublicKey),
+	store := &jwksClientStore{
+		client:           client,
+		validator:        validator,
+		cache:            make(map[string]cachedKey),
+		missingKids:      make(map[string]time.Time),
+		negativeCacheTTL: defaultNegativeCacheTTL,
+		maxKeys:          defaultMaxKeys,
+	}
+
+	for _, opt := range opts {
+		opt(store)
 	}
 
+	j.stores[issuer] = store
+
 	return nil
 }
 
 // VerificationKey returns the RSA public key for the given issuer (iss) and key ID (kid).
-// It first attempts to retrieve the key from the in-memory cache. If the key is not found,
-// it refreshes the JWKS cache for the issuer and tries again. Returns an error if the
+// It returns ErrRSAPublicKeyNotFound if the key is not an RSA key, see PublicKey for the
+// other key types. It first attempts to retrieve the key from the in-memory cache. If the key is not found,
+// it refreshes the JWKS cache for the issuer and tries again, unless the kid was not found
+// within the negative cache TTL (see WithNegativeCacheTTL). Returns an error if the
 // issuer is not configured or if the key cannot be found or validated.
 func (j *JWKSProvider) VerificationKey(ctx context.Context, iss string, kid string) (*rsa.PublicKey, error) {
+	key, err := j.PublicKey(ctx, iss, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRSAPublicKeyNotFound, kid)
+	}
+
+	return rsaKey, nil
+}
+
+// PublicKey returns the public key for the given issuer (iss) and key ID (kid), which is
+// a *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey. Like VerificationKey, it
+// refreshes the JWKS cache of the issuer if the key is not cached.
+func (j *JWKSProvider) PublicKey(ctx context.Context, iss string, kid string) (crypto.PublicKey, error) {
 	ctx = slogctx.With(ctx, "issuer", iss, "kid", kid)
 
 	store, ok := j.stores[iss]
@@ -98,51 +209,105 @@ func (j *JWKSProvider) VerificationKey(ctx context.Context, iss string, kid stri
 	return key, nil
 }
 
-func (j *JWKSProvider) readKeyWithLock(ctx context.Context, store *jwksClientStore, kid string) (*rsa.PublicKey, error) {
+func (j *JWKSProvider) readKeyWithLock(ctx context.Context, store *jwksClientStore, kid string) (crypto.PublicKey, error) {
 	store.lock.RLock()
 	defer store.lock.RUnlock()
 
 	return j.readKey(ctx, store, kid)
 }
 
-func (j *JWKSProvider) readKey(ctx context.Context, store *jwksClientStore, kid string) (*rsa.PublicKey, error) {
-	key, ok := store.cache[kid]
-	if !ok {
+func (j *JWKSProvider) readKey(ctx context.Context, store *jwksClientStore, kid string) (crypto.PublicKey, error) {
+	cached, ok := store.cache[kid]
+	if !ok || (!cached.expiresAt.IsZero() && !time.Now().Before(cached.expiresAt)) {
 		slogctx.Info(ctx, "no public key found in cache")
 		return nil, fmt.Errorf("%w: %s", ErrKidNoPublicKeyFound, kid)
 	}
 
-	return key, nil
+	return cached.key, nil
 }
 
-func (j *JWKSProvider) rebuildAndReadKey(ctx context.Context, store *jwksClientStore, kid string) (*rsa.PublicKey, error) {
+// rebuildAndReadKey fetches the JWKS of the store and reads the key again, unless the
+// kid was recently not found. Concurrent requests share a single fetch.
+func (j *JWKSProvider) rebuildAndReadKey(ctx context.Context, store *jwksClientStore, kid string) (crypto.PublicKey, error) {
+	if store.isMissing(kid) {
+		slogctx.Info(ctx, "key recently not found, skipping refresh")
+		return nil, fmt.Errorf("%w: %s", ErrKidNoPublicKeyFound, kid)
+	}
+
 	slogctx.Info(ctx, "key not found in cache, refreshing")
 
-	store.lock.Lock()
-	defer store.lock.Unlock()
+	err := j.refresh(ctx, store)
+	if err != nil {
+		return nil, err
+	}
 
-	// Double-check if another goroutine has already refreshed the cache
-	key, err := j.readKey(ctx, store, kid)
-	if err == nil {
-		return key, nil
+	key, err := j.readKeyWithLock(ctx, store, kid)
+	if err != nil {
+		store.markMissing(kid)
+		return nil, err
 	}
 
+	return key, nil
+}
+
+// refresh fetches the JWKS of the store and replaces the cached public keys, unless none
+// of the fetched keys is valid. Concurrent refreshes of the store share a single fetch,
+// which is not canceled with the context of the request that started it.
+func (j *JWKSProvider) refresh(ctx context.Context, store *jwksClientStore) error {
+	ctx = context.WithoutCancel(ctx)
+
+	_, err, _ := store.fetches.Do("", func() (any, error) {
+		// the keys are fetched without the lock, so the cached keys can be read meanwhile
+		pubKeys, err := j.fetchKeys(ctx, store)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(pubKeys) > 0 {
+			var expiresAt time.Time
+			if store.keyTTL > 0 {
+				expiresAt = time.Now().Add(store.keyTTL)
+			}
+
+			cache := make(map[string]cachedKey, len(pubKeys))
+			for kid, key := range pubKeys {
+				cache[kid] = cachedKey{key: key, expiresAt: expiresAt}
+			}
+
+			store.lock.Lock()
+			store.cache = cache
+			store.lock.Unlock()
+		}
+
+		return nil, nil
+	})
+
+	return err
+}
+
+// fetchKeys fetches the JWKS of the store and returns its valid public keys.
+func (j *JWKSProvider) fetchKeys(ctx context.Context, store *jwksClientStore) (map[string]crypto.PublicKey, error) {
 	result, err := store.client.Get(ctx)
 	if err != nil {
 		slogctx.Error(ctx, "failed while fetching jwks url", "error", err)
 		return nil, err
 	}
 
-	pubKeys := make(map[string]*rsa.PublicKey, len(result.Keys))
+	pubKeys := make(map[string]crypto.PublicKey, len(result.Keys))
 
 	for _, jwk := range result.Keys {
-		err := store.validator.Validate(jwk)
+		if store.maxKeys > 0 && len(pubKeys) >= store.maxKeys {
+			slogctx.Warn(ctx, "jwks exceeds the maximum number of cached keys; skipping remaining keys", "max_keys", store.maxKeys)
+			break
+		}
+
+		err := store.validator.ValidateContext(ctx, jwk)
 		if err != nil {
 			slogctx.Error(ctx, "failed certificate validation", "for kid", jwk.Kid, "error", err)
 			continue
 		}
 
-		pubKey, err := parsePublicKey(ctx, jwk)
+		pubKey, err := parsePublicKey(ctx, jwk, store.legacyKeyEncoding)
 		if err != nil {
 			slogctx.Error(ctx, "failed while parsing public keys", "for kid", jwk.Kid, "error", err)
 			continue
@@ -155,14 +320,127 @@ func (j *JWKSProvider) rebuildAndReadKey(ctx context.Context, store *jwksClientS
 		slogctx.Warn(ctx, "jwks returned keys but none validated/parsed; retaining previous cache", "total_keys", len(result.Keys))
 	}
 
-	if len(pubKeys) > 0 {
-		store.cache = pubKeys
+	return pubKeys, nil
+}
+
+// Refresh fetches the JWKS of the issuer and replaces the cached public keys, unless
+// none of the fetched keys is valid.
+func (j *JWKSProvider) Refresh(ctx context.Context, iss string) error {
+	store, ok := j.stores[iss]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoClientFound, iss)
 	}
 
-	return j.readKey(ctx, store, kid)
+	ctx = slogctx.With(ctx, "issuer", iss)
+
+	return j.refresh(ctx, store)
 }
 
-func parsePublicKey(ctx context.Context, key Key) (*rsa.PublicKey, error) {
+// PurgeIssuer drops the cached keys and missing kids of the issuer, so they are fetched
+// again on their next use, e.g. after the issuer revoked a key.
+func (j *JWKSProvider) PurgeIssuer(iss string) error {
+	store, ok := j.stores[iss]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoClientFound, iss)
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.cache = make(map[string]cachedKey)
+	store.missingKids = make(map[string]time.Time)
+
+	return nil
+}
+
+// Run refreshes the keys of the issuers added with a refresh interval (see
+// WithRefreshInterval) in the background until ctx is done, and then returns the context
+// error. The keys are refreshed once on start, so the first requests do not wait for them,
+// and then after every refresh interval. Failed refreshes keep the cached keys.
+// All clients must be added before Run is called.
+func (j *JWKSProvider) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	for iss, store := range j.stores {
+		if store.refreshInterval <= 0 {
+			continue
+		}
+
+		wg.Go(func() {
+			j.refreshPeriodically(ctx, iss, store)
+		})
+	}
+
+	wg.Wait()
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+// refreshPeriodically refreshes the keys of the issuer until ctx is done.
+func (j *JWKSProvider) refreshPeriodically(ctx context.Context, iss string, store *jwksClientStore) {
+	for {
+		err := j.Refresh(ctx, iss)
+		if err != nil && ctx.Err() == nil {
+			slogctx.Warn(ctx, "failed to refresh jwks in the background", "issuer", iss, "error", err)
+		}
+
+		timer := time.NewTimer(store.nextRefresh())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// isMissing reports whether the kid was not found in the JWKS within the negative cache TTL.
+func (s *jwksClientStore) isMissing(kid string) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	until, ok := s.missingKids[kid]
+
+	return ok && time.Now().Before(until)
+}
+
+// markMissing remembers that the kid was not found in the JWKS, and drops expired entries.
+func (s *jwksClientStore) markMissing(kid string) {
+	if s.negativeCacheTTL <= 0 {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	for missing, until := range s.missingKids {
+		if !now.Before(until) {
+			delete(s.missingKids, missing)
+		}
+	}
+
+	if s.maxKeys > 0 && len(s.missingKids) >= s.maxKeys {
+		return
+	}
+
+	s.missingKids[kid] = now.Add(s.negativeCacheTTL)
+}
+
+// nextRefresh returns the refresh interval with a random jitter, so the refreshes of
+// many instances are spread out.
+func (s *jwksClientStore) nextRefresh() time.Duration {
+	if s.refreshJitter <= 0 {
+		return s.refreshInterval
+	}
+
+	return s.refreshInterval - s.refreshJitter + time.Duration(rand.Int64N(int64(2*s.refreshJitter)+1)) //nolint:gosec
+}
+
+// parsePublicKey returns the public key of the certificate of the key, after checking
+// that the key parameters describe it.
+func parsePublicKey(ctx context.Context, key Key, legacyKeyEncoding bool) (crypto.PublicKey, error) {
 	if len(key.X5c) == 0 {
 		return nil, ErrX5cEmpty
 	}
@@ -189,6 +467,42 @@ func parsePublicKey(ctx context.Context, key Key) (*rsa.PublicKey, error) {
 			return nil, ErrRSAPublicKeyNotFound
 		}
 
+		if !rsaParametersMatch(key, pubKey, legacyKeyEncoding) {
+			return nil, fmt.Errorf("%w: [%s] parameters do not match the certificate", ErrPublicKeyTypeMismatch, key.Kty)
+		}
+
+		return pubKey, nil
+
+	case KeyTypeEC:
+		pubKey, ok := cert.PublicKey.(*ecdsa.PublicKey)
+		if !ok {
+			slogctx.Error(ctx, "getting public key", "kid", key.Kid)
+			return nil, fmt.Errorf("%w: [%s]", ErrPublicKeyTypeMismatch, key.Kty)
+		}
+
+		crv, x, y, err := ecCoordinates(pubKey)
+		if err != nil {
+			return nil, err
+		}
+
+		// the key parameters of the JWK must describe the key of the certificate
+		if crv != key.Crv || x != key.X || y != key.Y {
+			return nil, fmt.Errorf("%w: [%s] parameters do not match the certificate", ErrPublicKeyTypeMismatch, key.Kty)
+		}
+
+		return pubKey, nil
+
+	case KeyTypeOKP:
+		pubKey, ok := cert.PublicKey.(ed25519.PublicKey)
+		if !ok || key.Crv != CurveEd25519 {
+			slogctx.Error(ctx, "getting public key", "kid", key.Kid)
+			return nil, fmt.Errorf("%w: [%s]", ErrPublicKeyTypeMismatch, key.Kty)
+		}
+
+		if base64.RawURLEncoding.EncodeToString(pubKey) != key.X {
+			return nil, fmt.Errorf("%w: [%s] parameters do not match the certificate", ErrPublicKeyTypeMismatch, key.Kty)
+		}
+
 		return pubKey, nil
 
 	default:
@@ -196,3 +510,20 @@ func parsePublicKey(ctx context.Context, key Key) (*rsa.PublicKey, error) {
 		return nil, fmt.Errorf("%w: [%s]", ErrKeyTypeUnsupported, key.Kty)
 	}
 }
+
+// rsaParametersMatch reports whether the modulus and exponent of the key are those of
+// the public key, in the base64url encoding or, if enabled, the legacy decimal encoding.
+func rsaParametersMatch(key Key, pubKey *rsa.PublicKey, legacyKeyEncoding bool) bool {
+	decoded, err := key.RSAPublicKey()
+	if err == nil && decoded.Equal(pubKey) {
+		return true
+	}
+
+	if !legacyKeyEncoding {
+		return false
+	}
+
+	decoded, err = key.legacyRSAPublicKey()
+
+	return err == nil && decoded.Equal(pubKey)
+}
@@ -1,11 +1,18 @@
 package jwtsigning_test
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -15,6 +22,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/openkcm/common-sdk/pkg/jwtsigning"
 )
@@ -513,8 +521,8 @@ func generateJWKSResources(t *testing.T) (jwtsigning.JWKS, *x509.Certificate, ma
 				KeyOps: []string{"encryption"},
 				Kid:    "kid-1",
 				X5c:    x5c1,
-				N:      pubKey1.N.String(),
-				E:      strconv.Itoa(pubKey1.E),
+				N:      base64.RawURLEncoding.EncodeToString(pubKey1.N.Bytes()),
+				E:      base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pubKey1.E)).Bytes()),
 			},
 			{
 				Kty:    jwtsigning.KeyTypeRSA,
@@ -523,11 +531,379 @@ func generateJWKSResources(t *testing.T) (jwtsigning.JWKS, *x509.Certificate, ma
 				KeyOps: []string{"encryption"},
 				Kid:    "kid-2",
 				X5c:    x5c2,
-				N:      pubKey2.N.String(),
-				E:      strconv.Itoa(pubKey2.E),
+				N:      base64.RawURLEncoding.EncodeToString(pubKey2.N.Bytes()),
+				E:      base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pubKey2.E)).Bytes()),
 			},
 		},
 	}
 
 	return j, rootCa, result
 }
+
+func TestJWKSProviderBackgroundRefresh(t *testing.T) {
+	jwk, rootCa, expPubKeys := generateJWKSResources(t)
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		assert.NoError(t, json.NewEncoder(w).Encode(jwk))
+	}))
+	defer srv.Close()
+
+	cli, err := jwtsigning.NewClient(srv.URL)
+	require.NoError(t, err)
+
+	validator, err := jwtsigning.NewValidator(rootCa, validSubjString)
+	require.NoError(t, err)
+
+	subj := jwtsigning.NewJWKSProvider()
+	err = subj.AddClient("issuer-1", cli, validator, jwtsigning.WithRefreshInterval(20*time.Millisecond, 5*time.Millisecond))
+	require.NoError(t, err)
+
+	err = subj.AddClient("issuer-2", cli, validator)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	go func() {
+		done <- subj.Run(ctx)
+	}()
+
+	// the keys are fetched on start and then refreshed periodically
+	assert.Eventually(t, func() bool { return calls.Load() >= 3 }, 5*time.Second, 10*time.Millisecond)
+
+	for kid, key := range expPubKeys {
+		fetches := calls.Load()
+
+		result, err := subj.VerificationKey(t.Context(), "issuer-1", kid)
+		require.NoError(t, err)
+		assert.Equal(t, key, result)
+		assert.LessOrEqual(t, calls.Load(), fetches+1, "expected the key to be cached")
+	}
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	stopped := calls.Load()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stopped, calls.Load(), "expected no refreshes after the provider stopped")
+
+	t.Run("Refresh fails for unknown issuers", func(t *testing.T) {
+		err := subj.Refresh(t.Context(), "unknown")
+		assert.ErrorIs(t, err, jwtsigning.ErrNoClientFound)
+	})
+}
+
+func TestJWKSProviderECAndOKPKeys(t *testing.T) {
+	notBefore := time.Now()
+	notAfter := notBefore.Add(24 * time.Hour)
+
+	rootKey, rootDer := rootCertificate(t, notBefore, notAfter)
+	rootCa, err := x509.ParseCertificate(rootDer)
+	require.NoError(t, err)
+
+	intKey, intDer := intermediateCertificate(t, rootDer, notBefore, notAfter, rootKey)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	inputs := make([]jwtsigning.Input, 0, 2)
+
+	for kid, in := range map[string]struct {
+		kty jwtsigning.KeyType
+		pub any
+	}{
+		"kid-ec":  {kty: jwtsigning.KeyTypeEC, pub: &ecKey.PublicKey},
+		"kid-okp": {kty: jwtsigning.KeyTypeOKP, pub: edPub},
+	} {
+		leaf, err := x509.ParseCertificate(leafCertWithPublicKey(t, intDer, notBefore, notAfter, intKey, validSubject, in.pub))
+		require.NoError(t, err)
+
+		intCert, err := x509.ParseCertificate(intDer)
+		require.NoError(t, err)
+
+		inputs = append(inputs, jwtsigning.Input{
+			Kty:       in.kty,
+			Alg:       "alg",
+			Use:       "use",
+			KeyOps:    []string{"verify"},
+			Kid:       kid,
+			X509Certs: []x509.Certificate{*leaf, *intCert},
+		})
+	}
+
+	jwks, err := jwtsigning.NewJWKS(inputs...)
+	require.NoError(t, err)
+
+	// a key whose parameters do not describe the key of its certificate is skipped
+	tampered := jwks.Keys[0]
+	tampered.Kid = "kid-tampered"
+	tampered.X = base64.RawURLEncoding.EncodeToString([]byte("tampered"))
+	jwks.Keys = append(jwks.Keys, tampered)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewEncoder(w).Encode(jwks))
+	}))
+	defer srv.Close()
+
+	cli, err := jwtsigning.NewClient(srv.URL)
+	require.NoError(t, err)
+
+	validator, err := jwtsigning.NewValidator(rootCa, validSubjString)
+	require.NoError(t, err)
+
+	subj := jwtsigning.NewJWKSProvider()
+	err = subj.AddClient("issuer-1", cli, validator)
+	require.NoError(t, err)
+
+	t.Run("PublicKey returns the EC key", func(t *testing.T) {
+		result, err := subj.PublicKey(t.Context(), "issuer-1", "kid-ec")
+		require.NoError(t, err)
+		assert.True(t, ecKey.PublicKey.Equal(result))
+	})
+
+	t.Run("PublicKey returns the Ed25519 key", func(t *testing.T) {
+		result, err := subj.PublicKey(t.Context(), "issuer-1", "kid-okp")
+		require.NoError(t, err)
+		assert.Equal(t, edPub, result)
+	})
+
+	t.Run("PublicKey skips keys not matching their certificate", func(t *testing.T) {
+		result, err := subj.PublicKey(t.Context(), "issuer-1", "kid-tampered")
+		assert.ErrorIs(t, err, jwtsigning.ErrKidNoPublicKeyFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("VerificationKey rejects non RSA keys", func(t *testing.T) {
+		result, err := subj.VerificationKey(t.Context(), "issuer-1", "kid-ec")
+		assert.ErrorIs(t, err, jwtsigning.ErrRSAPublicKeyNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func TestJWKSProviderKeyEncoding(t *testing.T) {
+	jwk, rootCa, expPubKeys := generateJWKSResources(t)
+
+	// the first key is published in the legacy format of decimal strings
+	legacy := jwk.Keys[0]
+	legacy.N = expPubKeys[legacy.Kid].N.String()
+	legacy.E = strconv.Itoa(expPubKeys[legacy.Kid].E)
+	jwk.Keys[0] = legacy
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewEncoder(w).Encode(jwk))
+	}))
+	defer srv.Close()
+
+	cli, err := jwtsigning.NewClient(srv.URL)
+	require.NoError(t, err)
+
+	validator, err := jwtsigning.NewValidator(rootCa, validSubjString)
+	require.NoError(t, err)
+
+	subj := jwtsigning.NewJWKSProvider()
+	require.NoError(t, subj.AddClient("standard", cli, validator))
+	require.NoError(t, subj.AddClient("legacy", cli, validator, jwtsigning.WithLegacyKeyEncoding()))
+
+	t.Run("base64url encoded keys are accepted", func(t *testing.T) {
+		for _, iss := range []string{"standard", "legacy"} {
+			result, err := subj.VerificationKey(t.Context(), iss, "kid-2")
+			require.NoError(t, err)
+			assert.Equal(t, expPubKeys["kid-2"], result)
+		}
+	})
+
+	t.Run("decimal encoded keys are rejected by default", func(t *testing.T) {
+		result, err := subj.VerificationKey(t.Context(), "standard", legacy.Kid)
+		assert.ErrorIs(t, err, jwtsigning.ErrKidNoPublicKeyFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("decimal encoded keys are accepted with the legacy encoding", func(t *testing.T) {
+		result, err := subj.VerificationKey(t.Context(), "legacy", legacy.Kid)
+		require.NoError(t, err)
+		assert.Equal(t, expPubKeys[legacy.Kid], result)
+	})
+}
+
+func TestJWKSProviderFetchDeduplication(t *testing.T) {
+	jwk, rootCa, expPubKeys := generateJWKSResources(t)
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+
+		assert.NoError(t, json.NewEncoder(w).Encode(jwk))
+	}))
+	defer srv.Close()
+
+	newProvider := func(t *testing.T, opts ...jwtsigning.ClientOption) *jwtsigning.JWKSProvider {
+		t.Helper()
+
+		cli, err := jwtsigning.NewClient(srv.URL)
+		require.NoError(t, err)
+
+		validator, err := jwtsigning.NewValidator(rootCa, validSubjString)
+		require.NoError(t, err)
+
+		subj := jwtsigning.NewJWKSProvider()
+		require.NoError(t, subj.AddClient("issuer-1", cli, validator, opts...))
+
+		return subj
+	}
+
+	t.Run("concurrent requests share a single fetch", func(t *testing.T) {
+		calls.Store(0)
+
+		subj := newProvider(t)
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				result, err := subj.VerificationKey(t.Context(), "issuer-1", "kid-1")
+				assert.NoError(t, err)
+				assert.Equal(t, expPubKeys["kid-1"], result)
+			})
+		}
+
+		assert.Eventually(t, func() bool { return calls.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("unknown kids are cached for the negative cache TTL", func(t *testing.T) {
+		calls.Store(0)
+
+		subj := newProvider(t, jwtsigning.WithNegativeCacheTTL(100*time.Millisecond))
+
+		for range 3 {
+			_, err := subj.VerificationKey(t.Context(), "issuer-1", "unknown")
+			assert.ErrorIs(t, err, jwtsigning.ErrKidNoPublicKeyFound)
+		}
+
+		assert.Equal(t, int32(1), calls.Load())
+
+		// other kids are still fetched
+		_, err := subj.VerificationKey(t.Context(), "issuer-1", "other")
+		assert.ErrorIs(t, err, jwtsigning.ErrKidNoPublicKeyFound)
+		assert.Equal(t, int32(2), calls.Load())
+
+		assert.Eventually(t, func() bool {
+			_, err := subj.VerificationKey(t.Context(), "issuer-1", "unknown")
+			return errors.Is(err, jwtsigning.ErrKidNoPublicKeyFound) && calls.Load() == 3
+		}, 5*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("negative caching can be disabled", func(t *testing.T) {
+		calls.Store(0)
+
+		subj := newProvider(t, jwtsigning.WithNegativeCacheTTL(0))
+
+		for range 3 {
+			_, err := subj.VerificationKey(t.Context(), "issuer-1", "unknown")
+			assert.ErrorIs(t, err, jwtsigning.ErrKidNoPublicKeyFound)
+		}
+
+		assert.Equal(t, int32(3), calls.Load())
+	})
+}
+
+func TestJWKSProviderCacheEviction(t *testing.T) {
+	jwk, rootCa, expPubKeys := generateJWKSResources(t)
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		assert.NoError(t, json.NewEncoder(w).Encode(jwk))
+	}))
+	defer srv.Close()
+
+	newProvider := func(t *testing.T, opts ...jwtsigning.ClientOption) *jwtsigning.JWKSProvider {
+		t.Helper()
+
+		cli, err := jwtsigning.NewClient(srv.URL)
+		require.NoError(t, err)
+
+		validator, err := jwtsigning.NewValidator(rootCa, validSubjString)
+		require.NoError(t, err)
+
+		subj := jwtsigning.NewJWKSProvider()
+		require.NoError(t, subj.AddClient("issuer-1", cli, validator, opts...))
+
+		return subj
+	}
+
+	t.Run("keys beyond the maximum are not cached", func(t *testing.T) {
+		calls.Store(0)
+
+		subj := newProvider(t, jwtsigning.WithMaxKeys(1), jwtsigning.WithNegativeCacheTTL(0))
+
+		result, err := subj.VerificationKey(t.Context(), "issuer-1", "kid-1")
+		require.NoError(t, err)
+		assert.Equal(t, expPubKeys["kid-1"], result)
+
+		_, err = subj.VerificationKey(t.Context(), "issuer-1", "kid-2")
+		assert.ErrorIs(t, err, jwtsigning.ErrKidNoPublicKeyFound)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("expired keys are fetched again", func(t *testing.T) {
+		calls.Store(0)
+
+		subj := newProvider(t, jwtsigning.WithKeyTTL(50*time.Millisecond))
+
+		for range 3 {
+			_, err := subj.VerificationKey(t.Context(), "issuer-1", "kid-1")
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, int32(1), calls.Load())
+
+		time.Sleep(60 * time.Millisecond)
+
+		result, err := subj.VerificationKey(t.Context(), "issuer-1", "kid-1")
+		require.NoError(t, err)
+		assert.Equal(t, expPubKeys["kid-1"], result)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("PurgeIssuer drops the cached keys and missing kids", func(t *testing.T) {
+		calls.Store(0)
+
+		subj := newProvider(t, jwtsigning.WithNegativeCacheTTL(time.Hour))
+
+		_, err := subj.VerificationKey(t.Context(), "issuer-1", "kid-1")
+		require.NoError(t, err)
+		_, err = subj.VerificationKey(t.Context(), "issuer-1", "unknown")
+		assert.ErrorIs(t, err, jwtsigning.ErrKidNoPublicKeyFound)
+		assert.Equal(t, int32(2), calls.Load())
+
+		require.NoError(t, subj.PurgeIssuer("issuer-1"))
+
+		_, err = subj.VerificationKey(t.Context(), "issuer-1", "kid-1")
+		require.NoError(t, err)
+		_, err = subj.VerificationKey(t.Context(), "issuer-1", "unknown")
+		assert.ErrorIs(t, err, jwtsigning.ErrKidNoPublicKeyFound)
+		assert.Equal(t, int32(4), calls.Load())
+	})
+
+	t.Run("PurgeIssuer fails for unknown issuers", func(t *testing.T) {
+		subj := newProvider(t)
+
+		err := subj.PurgeIssuer("unknown")
+		assert.ErrorIs(t, err, jwtsigning.ErrNoClientFound)
+	})
+}
@@ -1,6 +1,10 @@
 package jwtsigning_test
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -222,6 +226,105 @@ func TestNew(t *testing.T) {
 	})
 }
 
+func TestNewECAndOKP(t *testing.T) {
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, rsaCert := generateKeysAndCert(t)
+
+	t.Run("should be successful", func(t *testing.T) {
+		tts := []struct {
+			name   string
+			kty    jwtsigning.KeyType
+			alg    string
+			key    crypto.Signer
+			expCrv string
+		}{
+			{name: "P-256", kty: jwtsigning.KeyTypeEC, alg: "ES256", key: p256Key, expCrv: jwtsigning.CurveP256},
+			{name: "P-384", kty: jwtsigning.KeyTypeEC, alg: "ES384", key: p384Key, expCrv: jwtsigning.CurveP384},
+			{name: "Ed25519", kty: jwtsigning.KeyTypeOKP, alg: "EdDSA", key: edKey, expCrv: jwtsigning.CurveEd25519},
+		}
+		for _, tt := range tts {
+			t.Run(tt.name, func(t *testing.T) {
+				// given
+				cert := selfSignedCert(t, tt.key)
+
+				// when
+				result, err := jwtsigning.NewJWKS(jwtsigning.Input{
+					Kty:       tt.kty,
+					Alg:       tt.alg,
+					Use:       "sig",
+					KeyOps:    []string{"verify"},
+					X509Certs: []x509.Certificate{*cert},
+				})
+
+				// then
+				require.NoError(t, err)
+				require.Len(t, result.Keys, 1)
+
+				key := result.Keys[0]
+				assert.Equal(t, tt.kty, key.Kty)
+				assert.Equal(t, tt.expCrv, key.Crv)
+				assert.Empty(t, key.N)
+				assert.Empty(t, key.E)
+				assert.NoError(t, key.Validate())
+
+				expKid, err := key.Thumbprint()
+				require.NoError(t, err)
+				assert.Equal(t, expKid, key.Kid)
+
+				switch pub := tt.key.Public().(type) {
+				case *ecdsa.PublicKey:
+					point, err := pub.Bytes()
+					require.NoError(t, err)
+
+					x, err := base64.RawURLEncoding.DecodeString(key.X)
+					require.NoError(t, err)
+					y, err := base64.RawURLEncoding.DecodeString(key.Y)
+					require.NoError(t, err)
+					assert.Equal(t, point, append(append([]byte{4}, x...), y...))
+				case ed25519.PublicKey:
+					assert.Equal(t, base64.RawURLEncoding.EncodeToString(pub), key.X)
+					assert.Empty(t, key.Y)
+				}
+			})
+		}
+	})
+
+	t.Run("should fail", func(t *testing.T) {
+		tts := []struct {
+			name   string
+			kty    jwtsigning.KeyType
+			cert   *x509.Certificate
+			expErr error
+		}{
+			{name: "if the curve is unsupported", kty: jwtsigning.KeyTypeEC, cert: selfSignedCert(t, p521Key), expErr: jwtsigning.ErrCurveUnsupported},
+			{name: "if EC is used with an RSA certificate", kty: jwtsigning.KeyTypeEC, cert: rsaCert, expErr: jwtsigning.ErrPublicKeyTypeMismatch},
+			{name: "if OKP is used with an EC certificate", kty: jwtsigning.KeyTypeOKP, cert: selfSignedCert(t, p256Key), expErr: jwtsigning.ErrPublicKeyTypeMismatch},
+			{name: "if RSA is used with an Ed25519 certificate", kty: jwtsigning.KeyTypeRSA, cert: selfSignedCert(t, edKey), expErr: jwtsigning.ErrRSAPublicKeyNotFound},
+		}
+		for _, tt := range tts {
+			t.Run(tt.name, func(t *testing.T) {
+				// when
+				result, err := jwtsigning.NewJWKS(jwtsigning.Input{
+					Kty:       tt.kty,
+					Kid:       "kid1",
+					X509Certs: []x509.Certificate{*tt.cert},
+				})
+
+				// then
+				assert.Nil(t, result)
+				assert.ErrorIs(t, err, tt.expErr)
+			})
+		}
+	})
+}
+
 func TestEncodeAndDecode(t *testing.T) {
 	subj := &jwtsigning.JWKS{
 		Keys: []jwtsigning.Key{
@@ -606,6 +709,38 @@ func TestThumbprint(t *testing.T) {
 			expResult: "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
 			expErr:    nil,
 		},
+		// please refer to the example in RFC 8037 appendix A.3
+		// https://www.rfc-editor.org/rfc/rfc8037#appendix-A.3
+		{
+			name: "should successfully create a thumbprint of an OKP key",
+			subj: jwtsigning.Key{
+				Kty: jwtsigning.KeyTypeOKP,
+				Crv: jwtsigning.CurveEd25519,
+				X:   "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",
+			},
+			expResult: "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k",
+			expErr:    nil,
+		},
+		{
+			name: "should return error if Y of an EC key is empty",
+			subj: jwtsigning.Key{
+				Kty: jwtsigning.KeyTypeEC,
+				Crv: jwtsigning.CurveP256,
+				X:   "x",
+			},
+			expResult: "",
+			expErr:    jwtsigning.ErrInvalidKey,
+		},
+		{
+			name: "should return error if the curve of an OKP key is not Ed25519",
+			subj: jwtsigning.Key{
+				Kty: jwtsigning.KeyTypeOKP,
+				Crv: jwtsigning.CurveP256,
+				X:   "x",
+			},
+			expResult: "",
+			expErr:    jwtsigning.ErrInvalidKey,
+		},
 		{
 			name: "should return error if key type is not RSA",
 			subj: jwtsigning.Key{
@@ -649,6 +784,82 @@ func TestThumbprint(t *testing.T) {
 	}
 }
 
+func selfSignedCert(t *testing.T, key crypto.Signer) *x509.Certificate {
+	t.Helper()
+
+	tmpl := x509.Certificate{
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().AddDate(5, 0, 0),
+		SerialNumber: big.NewInt(123123),
+		Subject: pkix.Name{
+			CommonName:   "CommonName",
+			Organization: []string{"Organization"},
+		},
+		BasicConstraintsValid: true,
+	}
+
+	certByte, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(certByte)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func TestRSAPublicKey(t *testing.T) {
+	prvKey, cert := generateKeysAndCert(t)
+
+	t.Run("decodes the key of NewJWKS", func(t *testing.T) {
+		// given
+		jwks, err := jwtsigning.NewJWKS(jwtsigning.Input{
+			Kty:       jwtsigning.KeyTypeRSA,
+			Kid:       "kid1",
+			X509Certs: []x509.Certificate{*cert},
+		})
+		require.NoError(t, err)
+
+		// when
+		result, err := jwks.Keys[0].RSAPublicKey()
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, prvKey.PublicKey.Equal(result))
+	})
+
+	tts := []struct {
+		name   string
+		subj   jwtsigning.Key
+		expErr error
+	}{
+		{
+			name:   "should return error if key type is not RSA",
+			subj:   jwtsigning.Key{Kty: jwtsigning.KeyTypeEC, N: "AQAB", E: "AQAB"},
+			expErr: jwtsigning.ErrKeyTypeUnsupported,
+		},
+		{
+			name:   "should return error if N is not base64url encoded",
+			subj:   jwtsigning.Key{Kty: jwtsigning.KeyTypeRSA, N: "n+/=", E: "AQAB"},
+			expErr: jwtsigning.ErrInvalidKey,
+		},
+		{
+			name:   "should return error if E is empty",
+			subj:   jwtsigning.Key{Kty: jwtsigning.KeyTypeRSA, N: "AQAB", E: ""},
+			expErr: jwtsigning.ErrInvalidKey,
+		},
+	}
+	for _, tt := range tts {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			result, err := tt.subj.RSAPublicKey()
+
+			// then
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, tt.expErr)
+		})
+	}
+}
+
 func generateKeysAndCert(t *testing.T) (*rsa.PrivateKey, *x509.Certificate) {
 	t.Helper()
 
@@ -0,0 +1,156 @@
+package jwtsigning
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	_ PrivateKeyProvider = &KeyRing{}
+	_ SigningKeyProvider = &KeyRing{}
+
+	// ErrNoSigningKey is returned when no signing key was rotated into the key ring yet.
+	ErrNoSigningKey = errors.New("no signing key")
+	// ErrRSAPrivateKeyNotFound is returned when the current signing key is not an RSA key.
+	ErrRSAPrivateKeyNotFound = errors.New("not a RSA private key")
+)
+
+// KeyRing holds the signing keys of an issuer. The current key signs new tokens, while
+// keys rotated out remain published in the JWKS for the overlap window, so tokens signed
+// with them can still be verified until they expire.
+type KeyRing struct {
+	iss     string
+	overlap time.Duration
+
+	mu      sync.RWMutex
+	current *ringKey
+	retired []ringKey
+}
+
+// ringKey is a signing key of the key ring with its key ID.
+type ringKey struct {
+	kid      string
+	key      SigningKey
+	retireAt time.Time // end of the overlap window of retired keys
+}
+
+// NewKeyRing creates an empty key ring for the issuer. The overlap window should be at
+// least the lifetime of the signed tokens.
+func NewKeyRing(iss string, overlap time.Duration) (*KeyRing, error) {
+	if iss == "" {
+		return nil, ErrIssuerEmpty
+	}
+
+	return &KeyRing{
+		iss:     iss,
+		overlap: overlap,
+	}, nil
+}
+
+// Rotate makes the key the current signing key, the previous one is retired after the
+// overlap window. The thumbprint of the key is used as key ID if kid is empty.
+// Returns an error if the key cannot sign tokens, or if the key ID is already used.
+func (r *KeyRing) Rotate(kid string, key SigningKey) error {
+	jwk, err := key.publicKey(kid)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.retired = r.activeRetired(now)
+
+	if r.current != nil && r.current.kid == jwk.Kid {
+		return fmt.Errorf("%w %s", ErrDuplicateKID, jwk.Kid)
+	}
+
+	for _, retired := range r.retired {
+		if retired.kid == jwk.Kid {
+			return fmt.Errorf("%w %s", ErrDuplicateKID, jwk.Kid)
+		}
+	}
+
+	if r.current != nil {
+		previous := *r.current
+		previous.retireAt = now.Add(r.overlap)
+		r.retired = append(r.retired, previous)
+	}
+
+	r.current = &ringKey{kid: jwk.Kid, key: key}
+
+	return nil
+}
+
+// CurrentKey returns the current signing key and its metadata.
+func (r *KeyRing) CurrentKey(_ context.Context) (SigningKey, KeyMetadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.current == nil {
+		return SigningKey{}, KeyMetadata{}, ErrNoSigningKey
+	}
+
+	return r.current.key, KeyMetadata{Iss: r.iss, Kid: r.current.kid}, nil
+}
+
+// CurrentSigningKey returns the current signing key if it is an RSA key, so the key ring
+// can be used to sign messages with Signer.Sign.
+func (r *KeyRing) CurrentSigningKey(ctx context.Context) (*rsa.PrivateKey, KeyMetadata, error) {
+	key, meta, err := r.CurrentKey(ctx)
+	if err != nil {
+		return nil, KeyMetadata{}, err
+	}
+
+	rsaKey, ok := key.Key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, KeyMetadata{}, fmt.Errorf("%w kid %s", ErrRSAPrivateKeyNotFound, meta.Kid)
+	}
+
+	return rsaKey, meta, nil
+}
+
+// PublicJWKS returns the public keys of the current key and of the keys within their
+// overlap window, to be published at the JWKS URI of the issuer.
+func (r *KeyRing) PublicJWKS() (*JWKS, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := r.activeRetired(time.Now())
+	if r.current != nil {
+		keys = append([]ringKey{*r.current}, keys...)
+	}
+
+	result := &JWKS{
+		Keys: make([]Key, 0, len(keys)),
+	}
+
+	for _, key := range keys {
+		jwk, err := key.key.publicKey(key.kid)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Keys = append(result.Keys, jwk)
+	}
+
+	return result, nil
+}
+
+// activeRetired returns the retired keys whose overlap window has not ended yet.
+func (r *KeyRing) activeRetired(now time.Time) []ringKey {
+	active := make([]ringKey, 0, len(r.retired))
+
+	for _, key := range r.retired {
+		if now.Before(key.retireAt) {
+			active = append(active, key)
+		}
+	}
+
+	return active
+}
@@ -0,0 +1,136 @@
+package jwtsigning_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/jwtsigning"
+)
+
+func TestKeyRing(t *testing.T) {
+	rsaKey, rsaCert := generateKeysAndCert(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("NewKeyRing fails without issuer", func(t *testing.T) {
+		ring, err := jwtsigning.NewKeyRing("", time.Hour)
+		assert.ErrorIs(t, err, jwtsigning.ErrIssuerEmpty)
+		assert.Nil(t, ring)
+	})
+
+	t.Run("has no signing key before the first rotation", func(t *testing.T) {
+		ring, err := jwtsigning.NewKeyRing("issuer", time.Hour)
+		require.NoError(t, err)
+
+		_, _, err = ring.CurrentKey(t.Context())
+		assert.ErrorIs(t, err, jwtsigning.ErrNoSigningKey)
+
+		jwks, err := ring.PublicJWKS()
+		require.NoError(t, err)
+		assert.Empty(t, jwks.Keys)
+	})
+
+	t.Run("rotates keys with an overlap window", func(t *testing.T) {
+		overlap := 200 * time.Millisecond
+
+		ring, err := jwtsigning.NewKeyRing("issuer", overlap)
+		require.NoError(t, err)
+
+		require.NoError(t, ring.Rotate("kid-1", jwtsigning.SigningKey{Key: rsaKey, Certificates: []*x509.Certificate{rsaCert}}))
+
+		priv, meta, err := ring.CurrentSigningKey(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, rsaKey, priv)
+		assert.Equal(t, jwtsigning.KeyMetadata{Iss: "issuer", Kid: "kid-1"}, meta)
+
+		require.NoError(t, ring.Rotate("kid-2", jwtsigning.SigningKey{Key: ecKey}))
+
+		key, meta, err := ring.CurrentKey(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, ecKey, key.Key)
+		assert.Equal(t, "kid-2", meta.Kid)
+
+		_, _, err = ring.CurrentSigningKey(t.Context())
+		assert.ErrorIs(t, err, jwtsigning.ErrRSAPrivateKeyNotFound)
+
+		// the retired key is published during the overlap window
+		jwks, err := ring.PublicJWKS()
+		require.NoError(t, err)
+		require.Len(t, jwks.Keys, 2)
+
+		assert.Equal(t, "kid-2", jwks.Keys[0].Kid)
+		assert.Equal(t, jwtsigning.KeyTypeEC, jwks.Keys[0].Kty)
+		assert.Equal(t, "ES256", jwks.Keys[0].Alg)
+		assert.Empty(t, jwks.Keys[0].X5c)
+
+		assert.Equal(t, "kid-1", jwks.Keys[1].Kid)
+		assert.Equal(t, jwtsigning.KeyTypeRSA, jwks.Keys[1].Kty)
+		assert.Equal(t, "PS256", jwks.Keys[1].Alg)
+		assert.Len(t, jwks.Keys[1].X5c, 1)
+
+		// keys without certificates are published without x5c
+		assert.NoError(t, jwks.Keys[1].Validate())
+		assert.ErrorIs(t, jwks.Keys[0].Validate(), jwtsigning.ErrInvalidKey)
+
+		assert.Eventually(t, func() bool {
+			jwks, err := ring.PublicJWKS()
+			return err == nil && len(jwks.Keys) == 1 && jwks.Keys[0].Kid == "kid-2"
+		}, 5*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("uses the thumbprint as key ID if none is given", func(t *testing.T) {
+		ring, err := jwtsigning.NewKeyRing("issuer", time.Hour)
+		require.NoError(t, err)
+
+		require.NoError(t, ring.Rotate("", jwtsigning.SigningKey{Key: edKey}))
+
+		jwks, err := ring.PublicJWKS()
+		require.NoError(t, err)
+		require.Len(t, jwks.Keys, 1)
+
+		thumbprint, err := jwks.Keys[0].Thumbprint()
+		require.NoError(t, err)
+		assert.Equal(t, thumbprint, jwks.Keys[0].Kid)
+		assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+	})
+
+	t.Run("Rotate fails", func(t *testing.T) {
+		ring, err := jwtsigning.NewKeyRing("issuer", time.Hour)
+		require.NoError(t, err)
+
+		require.NoError(t, ring.Rotate("kid-1", jwtsigning.SigningKey{Key: ecKey}))
+		require.NoError(t, ring.Rotate("kid-2", jwtsigning.SigningKey{Key: edKey}))
+
+		tts := []struct {
+			name   string
+			kid    string
+			key    jwtsigning.SigningKey
+			expErr error
+		}{
+			{name: "for the key ID of the current key", kid: "kid-2", key: jwtsigning.SigningKey{Key: rsaKey}, expErr: jwtsigning.ErrDuplicateKID},
+			{name: "for the key ID of a retired key", kid: "kid-1", key: jwtsigning.SigningKey{Key: rsaKey}, expErr: jwtsigning.ErrDuplicateKID},
+			{name: "for an algorithm not matching the key", kid: "kid-3", key: jwtsigning.SigningKey{Key: ecKey, Algorithm: "ES384"}, expErr: jwtsigning.ErrKeyAlgorithmMismatch},
+			{name: "without a key", kid: "kid-3", key: jwtsigning.SigningKey{}, expErr: jwtsigning.ErrPrivateKeyNotFound},
+		}
+		for _, tt := range tts {
+			t.Run(tt.name, func(t *testing.T) {
+				err := ring.Rotate(tt.kid, tt.key)
+				assert.ErrorIs(t, err, tt.expErr)
+
+				_, meta, err := ring.CurrentKey(t.Context())
+				require.NoError(t, err)
+				assert.Equal(t, "kid-2", meta.Kid)
+			})
+		}
+	})
+}
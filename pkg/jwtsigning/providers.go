@@ -2,6 +2,7 @@ package jwtsigning
 
 import (
 	"context"
+	"crypto"
 	"crypto/rsa"
 )
 
@@ -19,12 +20,26 @@ type PrivateKeyProvider interface {
 	CurrentSigningKey(ctx context.Context) (*rsa.PrivateKey, KeyMetadata, error)
 }
 
+// SigningKeyProvider supplies the current signing key of any supported key type and its
+// metadata for signing tokens, e.g. a KeyRing.
+type SigningKeyProvider interface {
+	// CurrentKey returns the key+metadata to use for signing.
+	CurrentKey(ctx context.Context) (SigningKey, KeyMetadata, error)
+}
+
 // PublicKeyProvider resolves RSA public keys for verification of incoming message signatures.
 type PublicKeyProvider interface {
 	// VerificationKey returns the public key for given issuer and kid.
 	VerificationKey(ctx context.Context, iss, kid string) (*rsa.PublicKey, error)
 }
 
+// PublicKeyResolver resolves public keys of any supported key type for verification of
+// incoming signatures, i.e. *rsa.PublicKey, *ecdsa.PublicKey and ed25519.PublicKey.
+type PublicKeyResolver interface {
+	// PublicKey returns the public key for given issuer and kid.
+	PublicKey(ctx context.Context, iss, kid string) (crypto.PublicKey, error)
+}
+
 type Hasher interface {
 	HashMessage(body []byte) string
 	ToString() string
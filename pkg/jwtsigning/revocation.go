@@ -0,0 +1,391 @@
+package jwtsigning
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+const (
+	// defaultRevocationCacheTTL is the default maximum time fetched CRLs and OCSP responses are cached.
+	defaultRevocationCacheTTL = time.Hour
+	// defaultRevocationTimeout is the default timeout of the requests to CRL distribution points and OCSP responders.
+	defaultRevocationTimeout = 5 * time.Second
+	// maxRevocationResponseSize limits the size of fetched CRLs and OCSP responses.
+	maxRevocationResponseSize = 10 << 20
+)
+
+// ErrRevocationResponse is returned when a CRL or OCSP response cannot be fetched or is invalid.
+var ErrRevocationResponse = errors.New("invalid revocation response")
+
+// RevocationChecker checks the revocation status of the certificates of x5c chains
+// validated by a Validator, see WithRevocationChecker. It queries the OCSP responders
+// and CRL distribution points named in the certificates, and caches their responses
+// until their next update, capped by the cache TTL.
+//
+// Revoked certificates are rejected with commoncfg.ErrCertificateRevoked. Certificates
+// whose status cannot be determined, e.g. because the responder is not reachable, are
+// rejected with commoncfg.ErrRevocationStatusUnknown, unless soft-fail is enabled.
+// Certificates that name neither an OCSP responder nor a CRL distribution point, and
+// are not covered by a configured CRL, are not checked.
+type RevocationChecker struct {
+	client   *http.Client
+	ocsp     bool
+	crlDPs   bool
+	crls     []*x509.RevocationList
+	softFail bool
+	cacheTTL time.Duration
+
+	fetches     singleflight.Group // deduplicates concurrent fetches of the same response
+	lock        sync.RWMutex       // protects the cached responses
+	cachedCRLs  map[string]cachedCRL
+	cachedOCSPs map[string]cachedOCSP
+}
+
+type cachedCRL struct {
+	crl       *x509.RevocationList
+	expiresAt time.Time
+}
+
+type cachedOCSP struct {
+	resp      *ocsp.Response
+	expiresAt time.Time
+}
+
+// RevocationOption configures a RevocationChecker.
+type RevocationOption func(*RevocationChecker)
+
+// WithOCSP queries the OCSP responders named in the certificates.
+func WithOCSP() RevocationOption {
+	return func(c *RevocationChecker) {
+		c.ocsp = true
+	}
+}
+
+// WithCRLDistributionPoints fetches the CRLs of the distribution points named in the
+// certificates. If OCSP is enabled as well, the CRLs are only fetched if the OCSP
+// responder does not determine the status.
+func WithCRLDistributionPoints() RevocationOption {
+	return func(c *RevocationChecker) {
+		c.crlDPs = true
+	}
+}
+
+// WithRevocationLists checks the certificates against the given CRLs, e.g. loaded with
+// commoncfg.LoadRevocationLists in environments without access to the distribution
+// points. The CRLs are only used for the certificates of their issuer.
+func WithRevocationLists(crls []*x509.RevocationList) RevocationOption {
+	return func(c *RevocationChecker) {
+		c.crls = append(c.crls, crls...)
+	}
+}
+
+// WithSoftFail accepts certificates whose revocation status cannot be determined.
+// Revoked certificates are always rejected.
+func WithSoftFail() RevocationOption {
+	return func(c *RevocationChecker) {
+		c.softFail = true
+	}
+}
+
+// WithRevocationCacheTTL sets the maximum time fetched CRLs and OCSP responses are
+// cached, one hour by default. They are fetched again earlier once they are due to be
+// updated.
+func WithRevocationCacheTTL(ttl time.Duration) RevocationOption {
+	return func(c *RevocationChecker) {
+		c.cacheTTL = ttl
+	}
+}
+
+// WithRevocationHTTPClient sets the client sending the requests to the OCSP responders
+// and CRL distribution points, e.g. to use a proxy.
+func WithRevocationHTTPClient(client *http.Client) RevocationOption {
+	return func(c *RevocationChecker) {
+		c.client = client
+	}
+}
+
+// NewRevocationChecker creates a RevocationChecker. Without options, it only checks the
+// certificates against the configured CRLs, see WithOCSP, WithCRLDistributionPoints and
+// WithRevocationLists.
+func NewRevocationChecker(opts ...RevocationOption) *RevocationChecker {
+	c := &RevocationChecker{
+		client:      &http.Client{Timeout: defaultRevocationTimeout},
+		cacheTTL:    defaultRevocationCacheTTL,
+		cachedCRLs:  make(map[string]cachedCRL),
+		cachedOCSPs: make(map[string]cachedOCSP),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Check checks the revocation status of the certificates of the verified chain, which
+// starts with the leaf and ends with the root. The root is not checked. It returns the
+// error of the context once it is done, even if soft-fail is enabled.
+func (c *RevocationChecker) Check(ctx context.Context, chain []*x509.Certificate) error {
+	for i := 0; i+1 < len(chain); i++ {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		err = c.checkCertificate(ctx, chain[i], chain[i+1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkCertificate checks the certificate against the configured CRLs, then its OCSP
+// responders and then its CRL distribution points, until one determines its status.
+func (c *RevocationChecker) checkCertificate(ctx context.Context, cert, issuer *x509.Certificate) error {
+	determined, err := c.checkCRLs(cert, issuer, c.crls)
+	if determined {
+		return err
+	}
+
+	var reasons []error
+	if err != nil {
+		reasons = append(reasons, err)
+	}
+
+	if c.ocsp {
+		for _, server := range cert.OCSPServer {
+			determined, err := c.checkOCSP(ctx, server, cert, issuer)
+			if determined {
+				return err
+			}
+
+			reasons = append(reasons, err)
+		}
+	}
+
+	if c.crlDPs {
+		for _, url := range cert.CRLDistributionPoints {
+			crl, err := c.fetchCRL(ctx, url, issuer)
+			if err != nil {
+				reasons = append(reasons, err)
+				continue
+			}
+
+			determined, err := c.checkCRLs(cert, issuer, []*x509.RevocationList{crl})
+			if determined {
+				return err
+			}
+
+			if err == nil {
+				err = fmt.Errorf("%w: the CRL of %s does not cover the certificate", ErrRevocationResponse, url)
+			}
+
+			reasons = append(reasons, err)
+		}
+	}
+
+	err = ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	if len(reasons) == 0 || c.softFail {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s (serial %s): %w", commoncfg.ErrRevocationStatusUnknown, cert.Subject, cert.SerialNumber, errors.Join(reasons...))
+}
+
+// checkCRLs checks the certificate against the current CRLs signed by its issuer, and
+// reports whether one of them determined its status. If not, it returns an error if a
+// CRL of the issuer expired.
+func (c *RevocationChecker) checkCRLs(cert, issuer *x509.Certificate, crls []*x509.RevocationList) (bool, error) {
+	var expired error
+
+	now := time.Now()
+
+	for _, crl := range crls {
+		if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) || crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+
+		for _, entry := range crl.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return true, fmt.Errorf("%w: %s (serial %s)", commoncfg.ErrCertificateRevoked, cert.Subject, cert.SerialNumber)
+			}
+		}
+
+		if crl.NextUpdate.IsZero() || now.Before(crl.NextUpdate) {
+			return true, nil
+		}
+
+		expired = fmt.Errorf("%w: the CRL of %s expired at %s", ErrRevocationResponse, crl.Issuer, crl.NextUpdate.Format(time.RFC3339))
+	}
+
+	return false, expired
+}
+
+// checkOCSP queries the OCSP responder for the status of the certificate, and reports
+// whether the responder determined it.
+func (c *RevocationChecker) checkOCSP(ctx context.Context, server string, cert, issuer *x509.Certificate) (bool, error) {
+	resp, err := c.fetchOCSP(ctx, server, cert, issuer)
+	if err != nil {
+		return false, err
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		return true, nil
+	case ocsp.Revoked:
+		return true, fmt.Errorf("%w: %s (serial %s)", commoncfg.ErrCertificateRevoked, cert.Subject, cert.SerialNumber)
+	default:
+		return false, fmt.Errorf("%w: the OCSP responder %s does not know the certificate", ErrRevocationResponse, server)
+	}
+}
+
+func (c *RevocationChecker) fetchOCSP(ctx context.Context, server string, cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	cacheKey := server + "|" + string(issuer.RawSubject) + "|" + cert.SerialNumber.String()
+
+	c.lock.RLock()
+	cached, ok := c.cachedOCSPs[cacheKey]
+	c.lock.RUnlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.resp, nil
+	}
+
+	result, err := c.shared(ctx, "ocsp|"+cacheKey, func() (any, error) {
+		req, err := ocsp.CreateRequest(cert, issuer, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRevocationResponse, err)
+		}
+
+		body, err := c.fetch(ctx, http.MethodPost, server, req)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := ocsp.ParseResponseForCert(body, cert, issuer)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRevocationResponse, err)
+		}
+
+		c.lock.Lock()
+		c.cachedOCSPs[cacheKey] = cachedOCSP{resp: resp, expiresAt: c.expiry(resp.NextUpdate)}
+		c.lock.Unlock()
+
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*ocsp.Response), nil //nolint:forcetypeassert
+}
+
+func (c *RevocationChecker) fetchCRL(ctx context.Context, url string, issuer *x509.Certificate) (*x509.RevocationList, error) {
+	c.lock.RLock()
+	cached, ok := c.cachedCRLs[url]
+	c.lock.RUnlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.crl, nil
+	}
+
+	result, err := c.shared(ctx, "crl|"+url, func() (any, error) {
+		body, err := c.fetch(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		crl, err := x509.ParseRevocationList(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRevocationResponse, err)
+		}
+
+		err = crl.CheckSignatureFrom(issuer)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRevocationResponse, err)
+		}
+
+		c.lock.Lock()
+		c.cachedCRLs[url] = cachedCRL{crl: crl, expiresAt: c.expiry(crl.NextUpdate)}
+		c.lock.Unlock()
+
+		return crl, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*x509.RevocationList), nil //nolint:forcetypeassert
+}
+
+// shared runs the fetch once for concurrent checks with the same key. The fetch is not
+// canceled with the context, but the check stops waiting for it once the context is done.
+func (c *RevocationChecker) shared(ctx context.Context, key string, fetch func() (any, error)) (any, error) {
+	select {
+	case res := <-c.fetches.DoChan(key, fetch):
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch sends a request to a CRL distribution point or OCSP responder and returns the
+// response body. The request is not canceled with the context, as it is shared by
+// concurrent checks, but is bounded by the timeout.
+func (c *RevocationChecker) fetch(ctx context.Context, method, url string, body []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultRevocationTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRevocationResponse, err)
+	}
+
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/ocsp-request")
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRevocationResponse, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s returned status %d", ErrRevocationResponse, url, res.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxRevocationResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRevocationResponse, err)
+	}
+
+	return data, nil
+}
+
+// expiry returns until when a response due to be updated at nextUpdate is cached.
+func (c *RevocationChecker) expiry(nextUpdate time.Time) time.Time {
+	expiresAt := time.Now().Add(c.cacheTTL)
+	if !nextUpdate.IsZero() && nextUpdate.Before(expiresAt) {
+		return nextUpdate
+	}
+
+	return expiresAt
+}
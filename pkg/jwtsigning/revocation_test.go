@@ -0,0 +1,222 @@
+package jwtsigning_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/jwtsigning"
+)
+
+func TestRevocationChecker(t *testing.T) {
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := notBefore.Add(24 * time.Hour)
+
+	rootKey, rootDer := rootCertificate(t, notBefore, notAfter)
+	rootCa, err := x509.ParseCertificate(rootDer)
+	require.NoError(t, err)
+
+	intKey, intDer := intermediateCertificate(t, rootDer, notBefore, notAfter, rootKey)
+	intCert, err := x509.ParseCertificate(intDer)
+	require.NoError(t, err)
+
+	leafSerial := big.NewInt(42)
+
+	// newKey returns a key whose leaf certificate names the OCSP responder and CRL
+	// distribution point, if not empty
+	newKey := func(t *testing.T, ocspURL, crlURL string) jwtsigning.Key {
+		t.Helper()
+
+		leafKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		tmpl := &x509.Certificate{
+			SerialNumber: leafSerial,
+			Subject:      validSubject,
+			NotBefore:    notBefore,
+			NotAfter:     notAfter,
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}
+
+		if ocspURL != "" {
+			tmpl.OCSPServer = []string{ocspURL}
+		}
+
+		if crlURL != "" {
+			tmpl.CRLDistributionPoints = []string{crlURL}
+		}
+
+		leafDer, err := x509.CreateCertificate(rand.Reader, tmpl, intCert, leafKey.Public(), intKey)
+		require.NoError(t, err)
+
+		return jwtsigning.Key{X5c: []string{
+			base64.StdEncoding.EncodeToString(leafDer),
+			base64.StdEncoding.EncodeToString(intDer),
+		}}
+	}
+
+	newCRL := func(t *testing.T, revoked ...*big.Int) []byte {
+		t.Helper()
+
+		tmpl := &x509.RevocationList{
+			Number:     big.NewInt(1),
+			ThisUpdate: time.Now().Add(-time.Minute),
+			NextUpdate: time.Now().Add(time.Hour),
+		}
+		for _, serial := range revoked {
+			tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries, x509.RevocationListEntry{
+				SerialNumber:   serial,
+				RevocationTime: time.Now().Add(-time.Minute),
+			})
+		}
+
+		crl, err := x509.CreateRevocationList(rand.Reader, tmpl, intCert, intKey)
+		require.NoError(t, err)
+
+		return crl
+	}
+
+	// newServer serves the response and counts the requests
+	newServer := func(t *testing.T, status int, response []byte) (*httptest.Server, *atomic.Int32) {
+		t.Helper()
+
+		var calls atomic.Int32
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+
+			_, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+
+			w.WriteHeader(status)
+			_, _ = w.Write(response)
+		}))
+		t.Cleanup(srv.Close)
+
+		return srv, &calls
+	}
+
+	newOCSPResponse := func(t *testing.T, status int) []byte {
+		t.Helper()
+
+		resp, err := ocsp.CreateResponse(intCert, intCert, ocsp.Response{
+			Status:       status,
+			SerialNumber: leafSerial,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    time.Now().Add(-time.Minute),
+		}, intKey)
+		require.NoError(t, err)
+
+		return resp
+	}
+
+	newValidator := func(t *testing.T, opts ...jwtsigning.RevocationOption) *jwtsigning.Validator {
+		t.Helper()
+
+		validator, err := jwtsigning.NewValidator(rootCa, validSubjString,
+			jwtsigning.WithRevocationChecker(jwtsigning.NewRevocationChecker(opts...)))
+		require.NoError(t, err)
+
+		return validator
+	}
+
+	t.Run("accepts certificates the OCSP responder knows as good and caches the response", func(t *testing.T) {
+		srv, calls := newServer(t, http.StatusOK, newOCSPResponse(t, ocsp.Good))
+		validator := newValidator(t, jwtsigning.WithOCSP())
+		key := newKey(t, srv.URL, "")
+
+		assert.NoError(t, validator.Validate(key))
+		assert.NoError(t, validator.Validate(key))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("bounds the revocation checks by the context", func(t *testing.T) {
+		srv, calls := newServer(t, http.StatusOK, newOCSPResponse(t, ocsp.Good))
+		validator := newValidator(t, jwtsigning.WithOCSP())
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		err := validator.ValidateContext(ctx, newKey(t, srv.URL, ""))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int32(0), calls.Load())
+	})
+
+	t.Run("rejects certificates the OCSP responder knows as revoked", func(t *testing.T) {
+		srv, _ := newServer(t, http.StatusOK, newOCSPResponse(t, ocsp.Revoked))
+		validator := newValidator(t, jwtsigning.WithOCSP())
+
+		err := validator.Validate(newKey(t, srv.URL, ""))
+		assert.ErrorIs(t, err, commoncfg.ErrCertificateRevoked)
+	})
+
+	t.Run("rejects certificates revoked by the CRL of their distribution point", func(t *testing.T) {
+		srv, calls := newServer(t, http.StatusOK, newCRL(t, leafSerial))
+		validator := newValidator(t, jwtsigning.WithCRLDistributionPoints())
+		key := newKey(t, "", srv.URL)
+
+		assert.ErrorIs(t, validator.Validate(key), commoncfg.ErrCertificateRevoked)
+		assert.ErrorIs(t, validator.Validate(key), commoncfg.ErrCertificateRevoked)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("accepts certificates not revoked by the CRL of their distribution point", func(t *testing.T) {
+		srv, _ := newServer(t, http.StatusOK, newCRL(t, big.NewInt(7)))
+		validator := newValidator(t, jwtsigning.WithCRLDistributionPoints())
+
+		assert.NoError(t, validator.Validate(newKey(t, "", srv.URL)))
+	})
+
+	t.Run("falls back to the CRL if the OCSP responder fails", func(t *testing.T) {
+		ocspSrv, _ := newServer(t, http.StatusInternalServerError, nil)
+		crlSrv, _ := newServer(t, http.StatusOK, newCRL(t, leafSerial))
+		validator := newValidator(t, jwtsigning.WithOCSP(), jwtsigning.WithCRLDistributionPoints())
+
+		err := validator.Validate(newKey(t, ocspSrv.URL, crlSrv.URL))
+		assert.ErrorIs(t, err, commoncfg.ErrCertificateRevoked)
+	})
+
+	t.Run("rejects certificates of unknown status unless soft-fail is enabled", func(t *testing.T) {
+		srv, _ := newServer(t, http.StatusInternalServerError, nil)
+		key := newKey(t, srv.URL, srv.URL)
+
+		err := newValidator(t, jwtsigning.WithOCSP(), jwtsigning.WithCRLDistributionPoints()).Validate(key)
+		assert.ErrorIs(t, err, commoncfg.ErrRevocationStatusUnknown)
+		assert.ErrorIs(t, err, jwtsigning.ErrRevocationResponse)
+
+		err = newValidator(t, jwtsigning.WithOCSP(), jwtsigning.WithCRLDistributionPoints(), jwtsigning.WithSoftFail()).Validate(key)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects certificates revoked by a configured CRL", func(t *testing.T) {
+		crl, err := x509.ParseRevocationList(newCRL(t, leafSerial))
+		require.NoError(t, err)
+
+		validator := newValidator(t, jwtsigning.WithRevocationLists([]*x509.RevocationList{crl}))
+
+		err = validator.Validate(newKey(t, "", ""))
+		assert.ErrorIs(t, err, commoncfg.ErrCertificateRevoked)
+	})
+
+	t.Run("does not check certificates without revocation endpoints", func(t *testing.T) {
+		validator := newValidator(t, jwtsigning.WithOCSP(), jwtsigning.WithCRLDistributionPoints())
+
+		assert.NoError(t, validator.Validate(newKey(t, "", "")))
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -20,20 +22,49 @@ const (
 	jwtMapClaimKid           = "kid"
 	jwtMapClaimHash          = "hash"
 	jwtMapClaimHashAlgorithm = "hash-alg"
+	jwtMapClaimIssuedAt      = "iat"
+	jwtMapClaimExpiresAt     = "exp"
 
 	tokenHeaderType      = "typ"
 	tokenType            = "JWT"
 	tokenHeaderAlgorithm = "alg"
+	tokenHeaderKid       = "kid"
 )
 
-// Signer signs message bodies into JWS (JWT) tokens.
+// Signer signs message bodies and claims into JWS (JWT) tokens.
 type Signer struct {
 	Hasher
 
 	keys PrivateKeyProvider
+
+	// claims are added to the tokens of SignClaims unless they are set by the caller.
+	claims jwt.MapClaims
+	// tokenTTL sets the expiry of the tokens of SignClaims, zero for tokens without expiry.
+	tokenTTL time.Duration
+}
+
+// SignerOption configures the tokens signed by SignClaims.
+type SignerOption func(*Signer)
+
+// WithClaims adds the claims to every token signed by SignClaims, e.g. the audience.
+func WithClaims(claims map[string]any) SignerOption {
+	return func(s *Signer) {
+		maps.Copy(s.claims, claims)
+	}
 }
 
-func NewSigner(keyProvider PrivateKeyProvider, hasher Hasher) (*Signer, error) {
+// WithTokenTTL sets the "exp" claim of the tokens signed by SignClaims to the time of
+// signing plus the TTL.
+func WithTokenTTL(ttl time.Duration) SignerOption {
+	return func(s *Signer) {
+		s.tokenTTL = ttl
+	}
+}
+
+// NewSigner creates a signer of the keys of the key provider. If the key provider is
+// also a SigningKeyProvider, e.g. a KeyRing, SignClaims signs with keys of any supported
+// type and algorithm.
+func NewSigner(keyProvider PrivateKeyProvider, hasher Hasher, opts ...SignerOption) (*Signer, error) {
 	if keyProvider == nil {
 		return nil, ErrNilKeyProvider
 	}
@@ -42,10 +73,17 @@ func NewSigner(keyProvider PrivateKeyProvider, hasher Hasher) (*Signer, error) {
 		hasher = &SHA256Hasher{}
 	}
 
-	return &Signer{
+	signer := &Signer{
 		Hasher: hasher,
 		keys:   keyProvider,
-	}, nil
+		claims: jwt.MapClaims{},
+	}
+
+	for _, opt := range opts {
+		opt(signer)
+	}
+
+	return signer, nil
 }
 
 // Sign creates a compact JWS (JWT) for the given message body using PS256.
@@ -61,6 +99,7 @@ func NewSigner(keyProvider PrivateKeyProvider, hasher Hasher) (*Signer, error) {
 //   - headers:
 //     typ: "JWT"
 //     alg: "PS256"
+//     kid: key ID from KeyMetadata
 //
 // Sign obtains the private key and metadata from the configured
 // PrivateKeyProvider and enforces the minimum RSA key size before signing.
@@ -86,6 +125,65 @@ func (s *Signer) Sign(ctx context.Context, body []byte) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodPS256, claims)
 	token.Header[tokenHeaderType] = tokenType
 	token.Header[tokenHeaderAlgorithm] = jwt.SigningMethodPS256.Alg()
+	token.Header[tokenHeaderKid] = meta.Kid
 
 	return token.SignedString(priv)
 }
+
+// SignClaims creates a compact JWS (JWT) of the claims, signed with the current key of
+// the key provider and the algorithm of the key.
+//
+// The claims of WithClaims are added, as well as the following claims unless they are
+// set by the caller:
+//
+//	iss: issuer from KeyMetadata
+//	iat: time of signing
+//	exp: time of signing plus the TTL of WithTokenTTL, if configured
+//
+// The "kid" header is set to the key ID from KeyMetadata, so verifiers can select the
+// key from the JWKS of the issuer.
+func (s *Signer) SignClaims(ctx context.Context, claims map[string]any) (string, error) {
+	key, meta, err := s.currentKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	method, err := key.signingMethod()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	tokenClaims := jwt.MapClaims{
+		jwtMapClaimIss:      meta.Iss,
+		jwtMapClaimIssuedAt: jwt.NewNumericDate(now),
+	}
+	if s.tokenTTL > 0 {
+		tokenClaims[jwtMapClaimExpiresAt] = jwt.NewNumericDate(now.Add(s.tokenTTL))
+	}
+
+	maps.Copy(tokenClaims, s.claims)
+	maps.Copy(tokenClaims, claims)
+
+	token := jwt.NewWithClaims(method, tokenClaims)
+	token.Header[tokenHeaderType] = tokenType
+	token.Header[tokenHeaderKid] = meta.Kid
+
+	return token.SignedString(key.Key)
+}
+
+// currentKey returns the current key of the key provider, which signs with PS256 unless
+// the key provider is a SigningKeyProvider.
+func (s *Signer) currentKey(ctx context.Context) (SigningKey, KeyMetadata, error) {
+	if keys, ok := s.keys.(SigningKeyProvider); ok {
+		return keys.CurrentKey(ctx)
+	}
+
+	priv, meta, err := s.keys.CurrentSigningKey(ctx)
+	if err != nil {
+		return SigningKey{}, KeyMetadata{}, err
+	}
+
+	return SigningKey{Key: priv, Algorithm: jwt.SigningMethodPS256.Alg()}, meta, nil
+}
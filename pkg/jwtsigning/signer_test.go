@@ -1,13 +1,18 @@
 package jwtsigning_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/openkcm/common-sdk/pkg/jwtsigning"
 )
@@ -99,6 +104,7 @@ func TestSignerSign_SuccessClaimsAndHeaders(t *testing.T) {
 
 	assert.Equal(t, "JWT", parsed.Header["typ"])
 	assert.Equal(t, jwt.SigningMethodPS256.Alg(), parsed.Header["alg"])
+	assert.Equal(t, meta.Kid, parsed.Header["kid"])
 
 	claims, ok := parsed.Claims.(jwt.MapClaims)
 	if assert.True(t, ok) {
@@ -179,3 +185,79 @@ func TestSignerSign_UsesDefaultSHA256Hasher(t *testing.T) {
 		assert.Equal(t, signer.ToString(), claims["hash-alg"])
 	}
 }
+
+func TestSignerSignClaims(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	ring, err := jwtsigning.NewKeyRing("https://issuer.example", time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, ring.Rotate("key-id-1", jwtsigning.SigningKey{Key: ecKey}))
+
+	signer, err := jwtsigning.NewSigner(ring, nil,
+		jwtsigning.WithClaims(map[string]any{"aud": "audience", "scope": "default"}),
+		jwtsigning.WithTokenTTL(time.Minute),
+	)
+	require.NoError(t, err)
+
+	before := time.Now().Truncate(time.Second)
+
+	tokenStr, err := signer.SignClaims(t.Context(), map[string]any{"sub": "subject", "scope": "custom"})
+	require.NoError(t, err)
+
+	parsed, err := jwt.Parse(tokenStr, func(tkn *jwt.Token) (any, error) {
+		return &ecKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES384"}), jwt.WithAudience("audience"), jwt.WithIssuer("https://issuer.example"))
+	require.NoError(t, err)
+	assert.True(t, parsed.Valid)
+
+	assert.Equal(t, "JWT", parsed.Header["typ"])
+	assert.Equal(t, "key-id-1", parsed.Header["kid"])
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	require.True(t, ok)
+	assert.Equal(t, "subject", claims["sub"])
+	assert.Equal(t, "custom", claims["scope"])
+
+	iat, err := claims.GetIssuedAt()
+	require.NoError(t, err)
+	assert.False(t, iat.Before(before))
+
+	exp, err := claims.GetExpirationTime()
+	require.NoError(t, err)
+	assert.Equal(t, iat.Add(time.Minute), exp.Time)
+
+	t.Run("signs with PS256 keys of a PrivateKeyProvider", func(t *testing.T) {
+		key := generateRSAKey(t, 3072)
+
+		signer, err := jwtsigning.NewSigner(&stubPrivateKeyProvider{
+			key:  key,
+			meta: jwtsigning.KeyMetadata{Iss: "issuer", Kid: "kid"},
+		}, nil)
+		require.NoError(t, err)
+
+		tokenStr, err := signer.SignClaims(t.Context(), nil)
+		require.NoError(t, err)
+
+		parsed, err := jwt.Parse(tokenStr, func(tkn *jwt.Token) (any, error) {
+			return &key.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"PS256"}))
+		require.NoError(t, err)
+		assert.Equal(t, "kid", parsed.Header["kid"])
+
+		_, hasExp := parsed.Claims.(jwt.MapClaims)["exp"]
+		assert.False(t, hasExp)
+	})
+
+	t.Run("fails without signing key", func(t *testing.T) {
+		ring, err := jwtsigning.NewKeyRing("issuer", time.Hour)
+		require.NoError(t, err)
+
+		signer, err := jwtsigning.NewSigner(ring, nil)
+		require.NoError(t, err)
+
+		tokenStr, err := signer.SignClaims(t.Context(), nil)
+		assert.ErrorIs(t, err, jwtsigning.ErrNoSigningKey)
+		assert.Empty(t, tokenStr)
+	})
+}
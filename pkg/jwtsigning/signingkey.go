@@ -0,0 +1,249 @@
+package jwtsigning
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/storage/keyvalue"
+)
+
+var (
+	// ErrPrivateKeyNotFound is returned when PEM data does not contain a private key.
+	ErrPrivateKeyNotFound = errors.New("no private key found")
+	// ErrCertificateKeyMismatch is returned when the certificate is not issued for the private key.
+	ErrCertificateKeyMismatch = errors.New("certificate does not match the private key")
+)
+
+const (
+	keyUseSignature = "sig"
+	keyOpVerify     = "verify"
+)
+
+// SigningKey is a private key used to sign tokens.
+type SigningKey struct {
+	// Key is the private key, a *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey.
+	Key crypto.Signer
+	// Algorithm is the JWS algorithm, e.g. PS256 or ES256. It defaults to PS256 for RSA
+	// keys, ES256 or ES384 for EC keys depending on the curve, and EdDSA for Ed25519 keys.
+	Algorithm string
+	// Certificates is the optional certificate chain of the key, starting with the
+	// certificate of the key. It is published as x5c in the JWKS.
+	Certificates []*x509.Certificate
+}
+
+// ParseSigningKey parses a PEM encoded private key in PKCS #8, PKCS #1 or SEC 1 form,
+// optionally followed by its certificate chain.
+func ParseSigningKey(data []byte) (SigningKey, error) {
+	var key SigningKey
+
+	for {
+		var block *pem.Block
+
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return SigningKey{}, err
+			}
+
+			key.Certificates = append(key.Certificates, cert)
+		case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY":
+			privateKey, err := parsePrivateKey(block)
+			if err != nil {
+				return SigningKey{}, err
+			}
+
+			key.Key = privateKey
+		}
+	}
+
+	if key.Key == nil {
+		return SigningKey{}, ErrPrivateKeyNotFound
+	}
+
+	_, err := key.signingMethod()
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return key, nil
+}
+
+// LoadSigningKeyFromSourceRef loads the PEM encoded signing key of the source, see ParseSigningKey.
+func LoadSigningKeyFromSourceRef(ref commoncfg.SourceRef) (SigningKey, error) {
+	data, err := commoncfg.LoadValueFromSourceRef(ref)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return ParseSigningKey(data)
+}
+
+// LoadSigningKeys parses the PEM encoded signing keys of the storage by their key IDs,
+// e.g. the storage of a commonfs loader watching a directory of key files.
+func LoadSigningKeys(storage keyvalue.ReadOnlyStringToBytesStorage) (map[string]SigningKey, error) {
+	kids := storage.List()
+	keys := make(map[string]SigningKey, len(kids))
+
+	for _, kid := range kids {
+		data, ok := storage.Get(kid)
+		if !ok {
+			continue
+		}
+
+		key, err := ParseSigningKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w kid %s", err, kid)
+		}
+
+		keys[kid] = key
+	}
+
+	return keys, nil
+}
+
+// signingMethod returns the signing method of the algorithm of the key. It returns an
+// error if the algorithm cannot be used with the key, or if an RSA key is too small.
+func (k SigningKey) signingMethod() (jwt.SigningMethod, error) {
+	if k.Key == nil {
+		return nil, ErrPrivateKeyNotFound
+	}
+
+	alg := k.Algorithm
+
+	switch key := k.Key.(type) {
+	case *rsa.PrivateKey:
+		if alg == "" {
+			alg = jwt.SigningMethodPS256.Alg()
+		}
+
+		if !slices.Contains([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}, alg) {
+			return nil, fmt.Errorf("%w: %s", ErrKeyAlgorithmMismatch, alg)
+		}
+
+		if key.N.BitLen() < 3072 {
+			return nil, fmt.Errorf("%w: %d bits", ErrRSAKeyLength, key.N.BitLen())
+		}
+	case *ecdsa.PrivateKey:
+		expected := map[string]string{
+			CurveP256: jwt.SigningMethodES256.Alg(),
+			CurveP384: jwt.SigningMethodES384.Alg(),
+		}[key.Curve.Params().Name]
+		if expected == "" {
+			return nil, fmt.Errorf("%w [%s]", ErrCurveUnsupported, key.Curve.Params().Name)
+		}
+
+		if alg == "" {
+			alg = expected
+		}
+
+		if alg != expected {
+			return nil, fmt.Errorf("%w: %s", ErrKeyAlgorithmMismatch, alg)
+		}
+	case ed25519.PrivateKey:
+		if alg == "" {
+			alg = jwt.SigningMethodEdDSA.Alg()
+		}
+
+		if alg != jwt.SigningMethodEdDSA.Alg() {
+			return nil, fmt.Errorf("%w: %s", ErrKeyAlgorithmMismatch, alg)
+		}
+	default:
+		return nil, fmt.Errorf("%w [%T]", ErrKeyTypeUnsupported, k.Key)
+	}
+
+	if len(k.Certificates) > 0 && !publicKeysEqual(k.Certificates[0].PublicKey, k.Key.Public()) {
+		return nil, ErrCertificateKeyMismatch
+	}
+
+	return jwt.GetSigningMethod(alg), nil
+}
+
+// publicKey returns the public JWK of the signing key. The thumbprint of the key is
+// used as key ID if kid is empty.
+func (k SigningKey) publicKey(kid string) (Key, error) {
+	method, err := k.signingMethod()
+	if err != nil {
+		return Key{}, err
+	}
+
+	kty, err := keyTypeOf(k.Key.Public())
+	if err != nil {
+		return Key{}, err
+	}
+
+	key := Key{
+		Kty:    kty,
+		Alg:    method.Alg(),
+		Use:    keyUseSignature,
+		KeyOps: []string{keyOpVerify},
+		Kid:    kid,
+	}
+
+	for _, cert := range k.Certificates {
+		key.X5c = append(key.X5c, base64.StdEncoding.EncodeToString(cert.Raw))
+	}
+
+	err = key.setPublicKey(k.Key.Public())
+	if err != nil {
+		return Key{}, err
+	}
+
+	if isEmpty(key.Kid) {
+		key.Kid, err = key.Thumbprint()
+		if err != nil {
+			return Key{}, err
+		}
+	}
+
+	return key, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	var (
+		key any
+		err error
+	)
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w [%T]", ErrKeyTypeUnsupported, key)
+	}
+
+	return signer, nil
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	key, ok := a.(interface{ Equal(x crypto.PublicKey) bool })
+
+	return ok && key.Equal(b)
+}
@@ -0,0 +1,199 @@
+package jwtsigning_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commonfs/loader"
+	"github.com/openkcm/common-sdk/pkg/jwtsigning"
+)
+
+func TestParseSigningKey(t *testing.T) {
+	rsaKey, rsaCert := generateKeysAndCert(t)
+	_, otherCert := generateKeysAndCert(t)
+	smallKey := generateRSAKey(t, 2048)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("should be successful", func(t *testing.T) {
+		tts := []struct {
+			name     string
+			data     []byte
+			expKey   crypto.Signer
+			expCerts int
+		}{
+			{
+				name:     "for a PKCS #8 RSA key with its certificate",
+				data:     append(pkcs8PEM(t, rsaKey), certPEM(rsaCert)...),
+				expKey:   rsaKey,
+				expCerts: 1,
+			},
+			{
+				name:   "for a PKCS #1 RSA key",
+				data:   pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+				expKey: rsaKey,
+			},
+			{
+				name:   "for a SEC 1 EC key",
+				data:   ecPEM(t, ecKey),
+				expKey: ecKey,
+			},
+			{
+				name:   "for a PKCS #8 Ed25519 key",
+				data:   pkcs8PEM(t, edKey),
+				expKey: edKey,
+			},
+		}
+		for _, tt := range tts {
+			t.Run(tt.name, func(t *testing.T) {
+				// when
+				result, err := jwtsigning.ParseSigningKey(tt.data)
+
+				// then
+				require.NoError(t, err)
+				assert.True(t, tt.expKey.Public().(interface{ Equal(x crypto.PublicKey) bool }).Equal(result.Key.Public()))
+				assert.Len(t, result.Certificates, tt.expCerts)
+			})
+		}
+	})
+
+	t.Run("should fail", func(t *testing.T) {
+		tts := []struct {
+			name   string
+			data   []byte
+			expErr error
+		}{
+			{
+				name:   "if there is no private key",
+				data:   certPEM(rsaCert),
+				expErr: jwtsigning.ErrPrivateKeyNotFound,
+			},
+			{
+				name:   "if the RSA key is too small",
+				data:   pkcs8PEM(t, smallKey),
+				expErr: jwtsigning.ErrRSAKeyLength,
+			},
+			{
+				name:   "if the curve is unsupported",
+				data:   ecPEM(t, p521Key),
+				expErr: jwtsigning.ErrCurveUnsupported,
+			},
+			{
+				name:   "if the certificate is not issued for the key",
+				data:   append(pkcs8PEM(t, rsaKey), certPEM(otherCert)...),
+				expErr: jwtsigning.ErrCertificateKeyMismatch,
+			},
+		}
+		for _, tt := range tts {
+			t.Run(tt.name, func(t *testing.T) {
+				// when
+				_, err := jwtsigning.ParseSigningKey(tt.data)
+
+				// then
+				assert.ErrorIs(t, err, tt.expErr)
+			})
+		}
+	})
+}
+
+func TestLoadSigningKeys(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("from a commonfs loader", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "key-1.pem"), ecPEM(t, ecKey), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "key-2.pem"), pkcs8PEM(t, edKey), 0o600))
+
+		ldr, err := loader.Create(
+			loader.OnPath(dir),
+			loader.WithExtension(".pem"),
+			loader.WithKeyIDType(loader.FileNameWithoutExtension),
+		)
+		require.NoError(t, err)
+		require.NoError(t, ldr.Start())
+
+		defer ldr.Close()
+
+		// when
+		result, err := jwtsigning.LoadSigningKeys(ldr.Storage())
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, result, 2)
+		assert.True(t, ecKey.Equal(result["key-1"].Key))
+		assert.True(t, edKey.Equal(result["key-2"].Key))
+	})
+
+	t.Run("fails for an invalid key file", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("not a key"), 0o600))
+
+		ldr, err := loader.Create(loader.OnPath(dir), loader.WithExtension(".pem"))
+		require.NoError(t, err)
+		require.NoError(t, ldr.Start())
+
+		defer ldr.Close()
+
+		// when
+		result, err := jwtsigning.LoadSigningKeys(ldr.Storage())
+
+		// then
+		assert.ErrorIs(t, err, jwtsigning.ErrPrivateKeyNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("from a source ref", func(t *testing.T) {
+		// when
+		result, err := jwtsigning.LoadSigningKeyFromSourceRef(commoncfg.SourceRef{
+			Source: commoncfg.EmbeddedSourceValue,
+			Value:  string(ecPEM(t, ecKey)),
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, ecKey.Equal(result.Key))
+	})
+}
+
+func pkcs8PEM(t *testing.T, key any) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func ecPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func certPEM(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
@@ -0,0 +1,204 @@
+package jwtsigning
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// defaultTokenAlgorithms are the signing algorithms of the keys supported by the Signer.
+var defaultTokenAlgorithms = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384",
+	"EdDSA",
+}
+
+// ValidateOptions configures the validation of tokens by ValidateToken.
+type ValidateOptions struct {
+	// Keys resolves the verification keys by the issuer and key ID of the tokens, e.g. a
+	// JWKSProvider. It must be non-nil.
+	Keys PublicKeyResolver
+
+	// TrustedIssuers are the issuers whose tokens are accepted. It must not be empty.
+	TrustedIssuers []string
+
+	// Audience is the audience the tokens must be issued for. If it is empty, the
+	// audience is not checked.
+	Audience string
+
+	// Algorithms are the accepted signing algorithms. By default, the algorithms of the
+	// keys supported by the Signer are accepted: RS256 to RS512, PS256 to PS512, ES256,
+	// ES384 and EdDSA.
+	Algorithms []string
+
+	// ClockSkew is the time the exp, nbf and iat claims may be off, to tolerate clocks
+	// that are not in sync.
+	ClockSkew time.Duration
+
+	// RequireExpiry rejects tokens without an exp claim.
+	RequireExpiry bool
+}
+
+// TokenClaims are the claims of a token validated by ValidateToken. Times of claims
+// missing in the token are zero.
+type TokenClaims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	ID        string
+
+	// KeyID is the ID of the key the token was signed with.
+	KeyID string
+
+	// Claims are all claims of the token, including the registered claims above.
+	Claims map[string]any
+}
+
+// ValidateToken validates the compact JWS (JWT) token and returns its claims.
+//
+// ValidateToken performs the following steps:
+//  1. Parses the token and enforces one of the accepted signing algorithms.
+//  2. Extracts the issuer from the "iss" claim and the key ID from the "kid" header,
+//     or the "kid" claim of the tokens of Signer.Sign, and
+//     validates the issuer against the trusted issuers.
+//  3. Resolves the corresponding public key via the PublicKeyResolver.
+//  4. Enforces that the key matches the signing method, and the minimum RSA key size.
+//  5. Verifies the signature using the resolved public key.
+//  6. Validates the "exp", "nbf" and "iat" claims with the clock skew, and the "aud"
+//     claim if an audience is configured.
+//
+// The errors of the claim validation wrap the errors of golang-jwt, e.g.
+// jwt.ErrTokenExpired.
+func ValidateToken(ctx context.Context, rawJWT string, opts ValidateOptions) (*TokenClaims, error) {
+	if opts.Keys == nil {
+		return nil, ErrNilPublicKeyProvider
+	}
+
+	if len(opts.TrustedIssuers) == 0 {
+		return nil, ErrNoTrustedIssuers
+	}
+
+	algorithms := opts.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = defaultTokenAlgorithms
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(algorithms),
+		jwt.WithLeeway(opts.ClockSkew),
+		jwt.WithIssuedAt(),
+	}
+
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	if opts.RequireExpiry {
+		parserOpts = append(parserOpts, jwt.WithExpirationRequired())
+	}
+
+	var kid string
+
+	keyFunc := func(t *jwt.Token) (any, error) {
+		claims, ok := t.Claims.(jwt.MapClaims)
+		if !ok {
+			return nil, ErrUnexpectedClaimsType
+		}
+
+		iss, _ := claims[jwtMapClaimIss].(string)
+
+		kid, _ = t.Header[tokenHeaderKid].(string)
+		if kid == "" {
+			kid, _ = claims[jwtMapClaimKid].(string)
+		}
+
+		if iss == "" || kid == "" {
+			return nil, ErrMissingIssOrKid
+		}
+
+		if !slices.Contains(opts.TrustedIssuers, iss) {
+			return nil, ErrUntrustedIssuer
+		}
+
+		pub, err := opts.Keys.PublicKey(ctx, iss, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		return pub, checkTokenKey(t.Method, pub)
+	}
+
+	parsed, err := jwt.Parse(rawJWT, keyFunc, parserOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJWTParseFailed, err)
+	}
+
+	if !parsed.Valid {
+		return nil, ErrSignatureInvalid
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrUnexpectedClaimsType
+	}
+
+	return newTokenClaims(claims, kid), nil
+}
+
+// checkTokenKey checks that the public key can be used with the signing method, and that
+// RSA keys are not too small.
+func checkTokenKey(method jwt.SigningMethod, pub any) error {
+	if !strings.HasPrefix(method.Alg(), "RS") && !strings.HasPrefix(method.Alg(), "PS") {
+		return checkKeyAlgorithm(method, pub)
+	}
+
+	rsaKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrKeyAlgorithmMismatch, method.Alg())
+	}
+
+	if rsaKey.N.BitLen() < 3072 {
+		return fmt.Errorf("%w: %d bits", ErrRSAKeyLength, rsaKey.N.BitLen())
+	}
+
+	return nil
+}
+
+// newTokenClaims returns the typed claims of the validated claims.
+func newTokenClaims(claims jwt.MapClaims, kid string) *TokenClaims {
+	result := &TokenClaims{
+		KeyID:  kid,
+		Claims: claims,
+	}
+
+	// the claims were validated, so their types are valid
+	result.Issuer, _ = claims.GetIssuer()
+	result.Subject, _ = claims.GetSubject()
+	result.Audience, _ = claims.GetAudience()
+	result.ID, _ = claims["jti"].(string)
+
+	result.ExpiresAt = numericDate(claims.GetExpirationTime)
+	result.NotBefore = numericDate(claims.GetNotBefore)
+	result.IssuedAt = numericDate(claims.GetIssuedAt)
+
+	return result
+}
+
+// numericDate returns the time of a date claim, or the zero time if it is missing.
+func numericDate(get func() (*jwt.NumericDate, error)) time.Time {
+	date, err := get()
+	if err != nil || date == nil {
+		return time.Time{}
+	}
+
+	return date.Time
+}
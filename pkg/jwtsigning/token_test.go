@@ -0,0 +1,283 @@
+package jwtsigning_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/jwtsigning"
+)
+
+func TestValidateToken(t *testing.T) {
+	const iss = "https://issuer.example"
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	sign := func(t *testing.T, method jwt.SigningMethod, key any, header map[string]any, claims jwt.MapClaims) string {
+		t.Helper()
+
+		token := jwt.NewWithClaims(method, claims)
+		for name, value := range header {
+			token.Header[name] = value
+		}
+
+		tokenStr, err := token.SignedString(key)
+		require.NoError(t, err)
+
+		return tokenStr
+	}
+
+	now := time.Now()
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": iss,
+			"sub": "subject",
+			"aud": "audience",
+			"jti": "token-id",
+			"iat": now.Unix(),
+			"nbf": now.Unix(),
+			"exp": now.Add(time.Minute).Unix(),
+		}
+	}
+
+	keys := &stubPublicKeyResolver{publicKey: &ecKey.PublicKey}
+	opts := jwtsigning.ValidateOptions{
+		Keys:           keys,
+		TrustedIssuers: []string{iss},
+		Audience:       "audience",
+		ClockSkew:      5 * time.Second,
+		RequireExpiry:  true,
+	}
+
+	t.Run("returns the claims of a valid token", func(t *testing.T) {
+		// given
+		tokenStr := sign(t, jwt.SigningMethodES256, ecKey, map[string]any{"kid": "kid-1"}, validClaims())
+
+		// when
+		result, err := jwtsigning.ValidateToken(t.Context(), tokenStr, opts)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, iss, result.Issuer)
+		assert.Equal(t, "subject", result.Subject)
+		assert.Equal(t, []string{"audience"}, result.Audience)
+		assert.Equal(t, "token-id", result.ID)
+		assert.Equal(t, "kid-1", result.KeyID)
+		assert.Equal(t, now.Truncate(time.Second), result.IssuedAt)
+		assert.Equal(t, now.Truncate(time.Second), result.NotBefore)
+		assert.Equal(t, now.Add(time.Minute).Truncate(time.Second), result.ExpiresAt)
+		assert.Equal(t, "subject", result.Claims["sub"])
+
+		assert.Equal(t, iss, keys.lastIss)
+		assert.Equal(t, "kid-1", keys.lastKid)
+	})
+
+	t.Run("validates the tokens of a Signer", func(t *testing.T) {
+		// given
+		ring, err := jwtsigning.NewKeyRing(iss, time.Hour)
+		require.NoError(t, err)
+		require.NoError(t, ring.Rotate("kid-ed", jwtsigning.SigningKey{Key: edKey}))
+
+		signer, err := jwtsigning.NewSigner(ring, nil,
+			jwtsigning.WithClaims(map[string]any{"aud": "audience"}),
+			jwtsigning.WithTokenTTL(time.Minute),
+		)
+		require.NoError(t, err)
+
+		tokenStr, err := signer.SignClaims(t.Context(), map[string]any{"scope": "read"})
+		require.NoError(t, err)
+
+		// when
+		result, err := jwtsigning.ValidateToken(t.Context(), tokenStr, jwtsigning.ValidateOptions{
+			Keys:           &stubPublicKeyResolver{publicKey: edPub},
+			TrustedIssuers: []string{iss},
+			Audience:       "audience",
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "kid-ed", result.KeyID)
+		assert.Equal(t, "read", result.Claims["scope"])
+	})
+
+	t.Run("tolerates the clock skew", func(t *testing.T) {
+		// given
+		claims := validClaims()
+		claims["exp"] = now.Add(-2 * time.Second).Unix()
+		claims["iat"] = now.Add(2 * time.Second).Unix()
+		claims["nbf"] = now.Add(2 * time.Second).Unix()
+
+		tokenStr := sign(t, jwt.SigningMethodES256, ecKey, map[string]any{"kid": "kid-1"}, claims)
+
+		// when
+		_, err := jwtsigning.ValidateToken(t.Context(), tokenStr, opts)
+
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("should fail", func(t *testing.T) {
+		tts := []struct {
+			name    string
+			token   func(t *testing.T) string
+			opts    func(opts jwtsigning.ValidateOptions) jwtsigning.ValidateOptions
+			expErrs []error
+		}{
+			{
+				name: "without key resolver",
+				opts: func(opts jwtsigning.ValidateOptions) jwtsigning.ValidateOptions {
+					opts.Keys = nil
+					return opts
+				},
+				expErrs: []error{jwtsigning.ErrNilPublicKeyProvider},
+			},
+			{
+				name: "without trusted issuers",
+				opts: func(opts jwtsigning.ValidateOptions) jwtsigning.ValidateOptions {
+					opts.TrustedIssuers = nil
+					return opts
+				},
+				expErrs: []error{jwtsigning.ErrNoTrustedIssuers},
+			},
+			{
+				name: "for an expired token",
+				token: func(t *testing.T) string {
+					claims := validClaims()
+					claims["exp"] = now.Add(-time.Minute).Unix()
+
+					return sign(t, jwt.SigningMethodES256, ecKey, map[string]any{"kid": "kid-1"}, claims)
+				},
+				expErrs: []error{jwtsigning.ErrJWTParseFailed, jwt.ErrTokenExpired},
+			},
+			{
+				name: "for a token without expiry",
+				token: func(t *testing.T) string {
+					claims := validClaims()
+					delete(claims, "exp")
+
+					return sign(t, jwt.SigningMethodES256, ecKey, map[string]any{"kid": "kid-1"}, claims)
+				},
+				expErrs: []error{jwt.ErrTokenRequiredClaimMissing},
+			},
+			{
+				name: "for a token not yet valid",
+				token: func(t *testing.T) string {
+					claims := validClaims()
+					claims["nbf"] = now.Add(time.Minute).Unix()
+
+					return sign(t, jwt.SigningMethodES256, ecKey, map[string]any{"kid": "kid-1"}, claims)
+				},
+				expErrs: []error{jwt.ErrTokenNotValidYet},
+			},
+			{
+				name: "for a token issued in the future",
+				token: func(t *testing.T) string {
+					claims := validClaims()
+					claims["iat"] = now.Add(time.Minute).Unix()
+
+					return sign(t, jwt.SigningMethodES256, ecKey, map[string]any{"kid": "kid-1"}, claims)
+				},
+				expErrs: []error{jwt.ErrTokenUsedBeforeIssued},
+			},
+			{
+				name: "for another audience",
+				token: func(t *testing.T) string {
+					claims := validClaims()
+					claims["aud"] = "other"
+
+					return sign(t, jwt.SigningMethodES256, ecKey, map[string]any{"kid": "kid-1"}, claims)
+				},
+				expErrs: []error{jwt.ErrTokenInvalidAudience},
+			},
+			{
+				name: "for an untrusted issuer",
+				token: func(t *testing.T) string {
+					claims := validClaims()
+					claims["iss"] = "https://other.example"
+
+					return sign(t, jwt.SigningMethodES256, ecKey, map[string]any{"kid": "kid-1"}, claims)
+				},
+				expErrs: []error{jwtsigning.ErrUntrustedIssuer},
+			},
+			{
+				name: "for a token without kid",
+				token: func(t *testing.T) string {
+					return sign(t, jwt.SigningMethodES256, ecKey, nil, validClaims())
+				},
+				expErrs: []error{jwtsigning.ErrMissingIssOrKid},
+			},
+			{
+				name: "for an invalid signature",
+				token: func(t *testing.T) string {
+					return sign(t, jwt.SigningMethodES256, otherKey, map[string]any{"kid": "kid-1"}, validClaims())
+				},
+				expErrs: []error{jwt.ErrTokenSignatureInvalid},
+			},
+			{
+				name: "for a signing method not matching the key",
+				token: func(t *testing.T) string {
+					return sign(t, jwt.SigningMethodEdDSA, edKey, map[string]any{"kid": "kid-1"}, validClaims())
+				},
+				expErrs: []error{jwtsigning.ErrKeyAlgorithmMismatch},
+			},
+			{
+				name: "for a signing method not accepted",
+				token: func(t *testing.T) string {
+					return sign(t, jwt.SigningMethodES256, ecKey, map[string]any{"kid": "kid-1"}, validClaims())
+				},
+				opts: func(opts jwtsigning.ValidateOptions) jwtsigning.ValidateOptions {
+					opts.Algorithms = []string{"EdDSA"}
+					return opts
+				},
+				expErrs: []error{jwt.ErrTokenSignatureInvalid},
+			},
+			{
+				name: "for a key that cannot be resolved",
+				token: func(t *testing.T) string {
+					return sign(t, jwt.SigningMethodES256, ecKey, map[string]any{"kid": "kid-1"}, validClaims())
+				},
+				opts: func(opts jwtsigning.ValidateOptions) jwtsigning.ValidateOptions {
+					opts.Keys = &stubPublicKeyResolver{stubPublicKeyProvider: stubPublicKeyProvider{err: jwtsigning.ErrKidNoPublicKeyFound}}
+					return opts
+				},
+				expErrs: []error{jwtsigning.ErrKidNoPublicKeyFound},
+			},
+		}
+		for _, tt := range tts {
+			t.Run(tt.name, func(t *testing.T) {
+				// given
+				tokenStr := "token"
+				if tt.token != nil {
+					tokenStr = tt.token(t)
+				}
+
+				validateOpts := opts
+				if tt.opts != nil {
+					validateOpts = tt.opts(opts)
+				}
+
+				// when
+				result, err := jwtsigning.ValidateToken(t.Context(), tokenStr, validateOpts)
+
+				// then
+				assert.Nil(t, result)
+
+				for _, expErr := range tt.expErrs {
+					assert.ErrorIs(t, err, expErr)
+				}
+			})
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package jwtsigning
 
 import (
+	"context"
 	"crypto/x509"
 	"encoding/base64"
 	"errors"
@@ -14,6 +15,19 @@ type Validator struct {
 	caCertPool *x509.CertPool
 	// subject is the exact subject string expected on the leaf certificate.
 	subject string
+	// revocation optionally checks the revocation status of the verified chain.
+	revocation *RevocationChecker
+}
+
+// ValidatorOption configures a Validator.
+type ValidatorOption func(*Validator)
+
+// WithRevocationChecker checks the revocation status of the certificates of the verified
+// chains with the checker, e.g. for environments that revoke leaf certificates.
+func WithRevocationChecker(checker *RevocationChecker) ValidatorOption {
+	return func(v *Validator) {
+		v.revocation = checker
+	}
 }
 
 var (
@@ -32,7 +46,7 @@ var (
 // NewValidator returns a Validator initialized with the given CA certificate and subject.
 // The CA certificate is added to a new x509.CertPool used for validation.
 // Returns an error if the CA certificate is nil or the subject is empty.
-func NewValidator(ca *x509.Certificate, subject string) (*Validator, error) {
+func NewValidator(ca *x509.Certificate, subject string, opts ...ValidatorOption) (*Validator, error) {
 	if ca == nil {
 		return nil, ErrCACertNotLoaded
 	}
@@ -49,6 +63,10 @@ func NewValidator(ca *x509.Certificate, subject string) (*Validator, error) {
 
 	result.subject = subject
 
+	for _, opt := range opts {
+		opt(result)
+	}
+
 	return result, nil
 }
 
@@ -58,8 +76,15 @@ func NewValidator(ca *x509.Certificate, subject string) (*Validator, error) {
 // base64-encoded DER certificate. The chain is verified against the Validator's
 // CA pool and the leaf subject must match the Validator's Subject.
 // Returns an error if the chain cannot be decoded, parsed, verified or if the
-// leaf subject does not match.
+// leaf subject does not match. If a revocation checker is configured, the certificates
+// of the verified chain must not be revoked.
 func (v *Validator) Validate(key Key) error {
+	return v.ValidateContext(context.Background(), key)
+}
+
+// ValidateContext is like Validate, but the revocation checks are bound to the context,
+// so they are cancelled with the fetch or the reload of the keys.
+func (v *Validator) ValidateContext(ctx context.Context, key Key) error {
 	x5c := key.X5c
 	if len(x5c) == 0 {
 		return ErrX5cEmpty
@@ -93,12 +118,21 @@ func (v *Validator) Validate(key Key) error {
 		Intermediates: intermediates,
 	}
 
-	_, err := leaf.Verify(opts)
+	chains, err := leaf.Verify(opts)
 	if err != nil {
 		return err
 	}
 
-	return v.checkFullSubject(leaf)
+	err = v.checkFullSubject(leaf)
+	if err != nil {
+		return err
+	}
+
+	if v.revocation != nil {
+		return v.revocation.Check(ctx, chains[0])
+	}
+
+	return nil
 }
 
 // checkFullSubject verifies that the subject of the provided leaf certificate
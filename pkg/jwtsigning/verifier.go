@@ -2,6 +2,8 @@ package jwtsigning
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/subtle"
 	"errors"
 	"fmt"
@@ -21,6 +23,7 @@ var (
 	ErrMessageHashMismatch      = errors.New("message hash mismatch")
 	ErrNilPublicKeyProvider     = errors.New("publicKeyProvider cannot be nil")
 	ErrNoTrustedIssuers         = errors.New("trusted issuers cannot be nil")
+	ErrKeyAlgorithmMismatch     = errors.New("public key does not match the signing method")
 )
 
 // Verifier verifies signed messages represented as compact JWS (JWT) tokens.
@@ -61,11 +64,12 @@ func NewVerifier(publicKeyProvider PublicKeyProvider, hasher Hasher, trustedIssu
 // for the provided message body.
 //
 // Verify performs the following steps:
-//  1. Parses the token and enforces the PS256 signing method.
+//  1. Parses the token and enforces the PS256 signing method, or ES256, ES384
+//     and EdDSA if the PublicKeyProvider is also a PublicKeyResolver.
 //  2. Extracts "iss" and "kid" claims and validates "iss" against
 //     TrustedIssuers if configured.
-//  3. Resolves the corresponding RSA public key via the PublicKeyProvider.
-//  4. Enforces the minimum RSA key size.
+//  3. Resolves the corresponding public key via the PublicKeyProvider.
+//  4. Enforces the minimum RSA key size, or the curve of the signing method.
 //  5. Verifies the JWS signature using the resolved public key.
 //  6. Validates that the "hash-alg" claim is "SHA256".
 //  7. Recomputes base64url(SHA-256(body)) and compares it to the "hash"
@@ -75,7 +79,8 @@ func NewVerifier(publicKeyProvider PublicKeyProvider, hasher Hasher, trustedIssu
 // treat the message as untrusted.
 func (v *Verifier) Verify(ctx context.Context, tokenStr string, body []byte) error {
 	keyFunc := func(t *jwt.Token) (any, error) {
-		if t.Method != jwt.SigningMethodPS256 {
+		resolver, isResolver := v.keys.(PublicKeyResolver)
+		if t.Method != jwt.SigningMethodPS256 && (!isResolver || !isECOrEdDSA(t.Method)) {
 			return nil, fmt.Errorf("%w: %s", ErrUnexpectedSigningMethod, t.Method.Alg())
 		}
 
@@ -99,6 +104,15 @@ func (v *Verifier) Verify(ctx context.Context, tokenStr string, body []byte) err
 			return nil, ErrUntrustedIssuer
 		}
 
+		if t.Method != jwt.SigningMethodPS256 {
+			pub, err := resolver.PublicKey(ctx, iss, kid)
+			if err != nil {
+				return nil, err
+			}
+
+			return pub, checkKeyAlgorithm(t.Method, pub)
+		}
+
 		pub, err := v.keys.VerificationKey(ctx, iss, kid)
 		if err != nil {
 			return nil, err
@@ -142,3 +156,32 @@ func (v *Verifier) Verify(ctx context.Context, tokenStr string, body []byte) err
 
 	return nil
 }
+
+// isECOrEdDSA reports whether the signing method is ES256, ES384 or EdDSA.
+func isECOrEdDSA(method jwt.SigningMethod) bool {
+	return method == jwt.SigningMethodES256 || method == jwt.SigningMethodES384 || method == jwt.SigningMethodEdDSA
+}
+
+// checkKeyAlgorithm checks that the public key has the type and curve of the signing method,
+// so e.g. a P-256 key cannot be used with ES384.
+func checkKeyAlgorithm(method jwt.SigningMethod, pub any) error {
+	var crv string
+
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		crv = key.Curve.Params().Name
+	case ed25519.PublicKey:
+		crv = CurveEd25519
+	}
+
+	expected := map[jwt.SigningMethod]string{
+		jwt.SigningMethodES256: CurveP256,
+		jwt.SigningMethodES384: CurveP384,
+		jwt.SigningMethodEdDSA: CurveEd25519,
+	}[method]
+	if crv == "" || crv != expected {
+		return fmt.Errorf("%w: %s", ErrKeyAlgorithmMismatch, method.Alg())
+	}
+
+	return nil
+}
@@ -1,11 +1,17 @@
 package jwtsigning_test
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
 	"errors"
 	"testing"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/openkcm/common-sdk/pkg/jwtsigning"
 )
@@ -367,4 +373,99 @@ func TestVerifier(t *testing.T) {
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, jwtsigning.ErrMessageHashMismatch)
 	})
+
+	t.Run("Verify_ECAndEdDSA", func(t *testing.T) {
+		p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+		edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		body := []byte("body")
+		claims := jwt.MapClaims{
+			"iss":      "issuer",
+			"kid":      "kid",
+			"hash":     (&jwtsigning.SHA256Hasher{}).HashMessage(body),
+			"hash-alg": "SHA256",
+		}
+
+		tests := []struct {
+			name      string
+			method    jwt.SigningMethod
+			signKey   crypto.Signer
+			publicKey crypto.PublicKey
+			resolver  bool
+			expectErr error
+		}{
+			{
+				name:      "ES256 with a P-256 key is accepted",
+				method:    jwt.SigningMethodES256,
+				signKey:   p256Key,
+				publicKey: &p256Key.PublicKey,
+				resolver:  true,
+			},
+			{
+				name:      "ES384 with a P-384 key is accepted",
+				method:    jwt.SigningMethodES384,
+				signKey:   p384Key,
+				publicKey: &p384Key.PublicKey,
+				resolver:  true,
+			},
+			{
+				name:      "EdDSA with an Ed25519 key is accepted",
+				method:    jwt.SigningMethodEdDSA,
+				signKey:   edKey,
+				publicKey: edPub,
+				resolver:  true,
+			},
+			{
+				name:      "ES256 with a P-384 key is rejected",
+				method:    jwt.SigningMethodES256,
+				signKey:   p256Key,
+				publicKey: &p384Key.PublicKey,
+				resolver:  true,
+				expectErr: jwtsigning.ErrKeyAlgorithmMismatch,
+			},
+			{
+				name:      "EdDSA with an EC key is rejected",
+				method:    jwt.SigningMethodEdDSA,
+				signKey:   edKey,
+				publicKey: &p256Key.PublicKey,
+				resolver:  true,
+				expectErr: jwtsigning.ErrKeyAlgorithmMismatch,
+			},
+			{
+				name:      "ES256 is rejected without a public key resolver",
+				method:    jwt.SigningMethodES256,
+				signKey:   p256Key,
+				publicKey: &p256Key.PublicKey,
+				resolver:  false,
+				expectErr: jwtsigning.ErrUnexpectedSigningMethod,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tokenStr, err := jwt.NewWithClaims(tt.method, claims).SignedString(tt.signKey)
+				require.NoError(t, err)
+
+				var pubProvider jwtsigning.PublicKeyProvider = &stubPublicKeyProvider{}
+				if tt.resolver {
+					pubProvider = &stubPublicKeyResolver{publicKey: tt.publicKey}
+				}
+
+				verifier, err := jwtsigning.NewVerifier(pubProvider, nil, defaultTrustMap)
+				require.NoError(t, err)
+
+				err = verifier.Verify(t.Context(), tokenStr, body)
+				if tt.expectErr == nil {
+					assert.NoError(t, err)
+				} else {
+					assert.ErrorIs(t, err, jwtsigning.ErrJWTParseFailed)
+					assert.ErrorIs(t, err, tt.expectErr)
+				}
+			})
+		}
+	})
 }
@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// ElevationAttribute is the key of the attribute holding the header value of elevated requests.
+const ElevationAttribute = "logElevation"
+
+type elevationKey struct{}
+
+// LevelElevator elevates the log level of single requests, e.g. to debug the requests
+// carrying a support ticket header, while the other requests are logged with the
+// configured level. The number of requests elevated at the same time is bounded by
+// MaxConcurrent to limit the log volume.
+//
+// The elevated level is part of the request context, so it ends with the request. It is
+// applied by the handlers created by InitHandler or wrapped by NewElevationHandler.
+//
+// LevelElevator is safe for concurrent use.
+type LevelElevator struct {
+	enabled       bool
+	header        string
+	level         slog.Level
+	maxConcurrent int64
+	active        atomic.Int64
+}
+
+// NewLevelElevator creates a LevelElevator from the logger elevation configuration.
+func NewLevelElevator(cfg commoncfg.LoggerElevation) *LevelElevator {
+	return &LevelElevator{
+		enabled:       cfg.Enabled,
+		header:        cfg.Header,
+		level:         parseLevel(cfg.Level),
+		maxConcurrent: int64(cfg.MaxConcurrent),
+	}
+}
+
+// Elevate returns a context with the elevated log level and the reason, e.g. the support
+// ticket, as ElevationAttribute. The returned function ends the elevation; it must be called
+// when the request is done to free its slot. If the elevation is disabled, the reason is empty
+// or MaxConcurrent requests are already elevated, the context is returned unchanged.
+func (e *LevelElevator) Elevate(ctx context.Context, reason string) (context.Context, func()) {
+	if !e.enabled || reason == "" {
+		return ctx, func() {}
+	}
+
+	if e.active.Add(1) > e.maxConcurrent {
+		e.active.Add(-1)
+		return ctx, func() {}
+	}
+
+	ctx = context.WithValue(ctx, elevationKey{}, e.level)
+	ctx = slogctx.Append(ctx, slog.String(ElevationAttribute, reason))
+
+	var once sync.Once
+
+	return ctx, func() {
+		once.Do(func() { e.active.Add(-1) })
+	}
+}
+
+// Active returns the number of currently elevated requests.
+func (e *LevelElevator) Active() int {
+	return int(e.active.Load())
+}
+
+// HTTPMiddleware elevates the log level of requests carrying the configured header.
+func (e *LevelElevator) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, done := e.Elevate(r.Context(), r.Header.Get(e.header))
+		defer done()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// UnaryServerInterceptor elevates the log level of calls carrying the configured metadata key.
+func (e *LevelElevator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, done := e.Elevate(ctx, e.metadataValue(ctx))
+		defer done()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor elevates the log level of streams carrying the configured metadata key.
+func (e *LevelElevator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, done := e.Elevate(ss.Context(), e.metadataValue(ss.Context()))
+		defer done()
+
+		return handler(srv, &elevatedServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (e *LevelElevator) metadataValue(ctx context.Context) string {
+	values := metadata.ValueFromIncomingContext(ctx, e.header)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+type elevatedServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context //nolint:containedctx
+}
+
+func (s *elevatedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// ElevatedLevel returns the log level the context was elevated to by a LevelElevator.
+func ElevatedLevel(ctx context.Context) (slog.Level, bool) {
+	level, ok := ctx.Value(elevationKey{}).(slog.Level)
+	return level, ok
+}
+
+// NewElevationHandler wraps a handler so records of contexts elevated by a LevelElevator
+// are handled if their level is at least the elevated level, regardless of the level of
+// the handler. The wrapped handler must not filter levels in Handle.
+func NewElevationHandler(next slog.Handler) slog.Handler {
+	return &elevationHandler{next: next}
+}
+
+type elevationHandler struct {
+	next slog.Handler
+}
+
+// Enabled reports whether the level is enabled by the elevation of the context or by the wrapped handler.
+func (h *elevationHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if elevated, ok := ElevatedLevel(ctx); ok && level >= elevated {
+		return true
+	}
+
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle delegates the record to the wrapped handler.
+func (h *elevationHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs applies the attributes to the wrapped handler.
+func (h *elevationHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &elevationHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup applies a group name to the wrapped handler.
+func (h *elevationHandler) WithGroup(name string) slog.Handler {
+	return &elevationHandler{next: h.next.WithGroup(name)}
+}
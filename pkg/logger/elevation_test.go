@@ -0,0 +1,186 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/logger"
+)
+
+func newElevationConfig(maxConcurrent int) commoncfg.LoggerElevation {
+	return commoncfg.LoggerElevation{
+		Enabled:       true,
+		Header:        "X-Support-Ticket",
+		Level:         "debug",
+		MaxConcurrent: maxConcurrent,
+	}
+}
+
+func TestElevatedRequestLogsDebug(t *testing.T) {
+	var buf bytes.Buffer
+
+	handler, err := logger.InitHandlerWithWriter(&buf, commoncfg.Logger{
+		Format: commoncfg.JSONLoggerFormat,
+		Level:  "info",
+		Formatter: commoncfg.LoggerFormatter{
+			Time: commoncfg.LoggerTime{Type: commoncfg.UnixTimeLogger, Precision: "1us"},
+		},
+	}, commoncfg.Application{Name: "svc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	log := slog.New(handler)
+	elevator := logger.NewLevelElevator(newElevationConfig(1))
+
+	ctx, done := elevator.Elevate(context.Background(), "TICKET-1")
+	log.DebugContext(context.Background(), "not elevated")
+	log.DebugContext(ctx, "elevated")
+	log.Log(ctx, logger.LevelTrace, "below the elevated level")
+	done()
+
+	records := decodeRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d: %v", len(records), records)
+	}
+
+	if records[0]["msg"] != "elevated" || records[0][logger.ElevationAttribute] != "TICKET-1" {
+		t.Errorf("unexpected record: %v", records[0])
+	}
+}
+
+func TestLevelElevatorMaxConcurrent(t *testing.T) {
+	elevator := logger.NewLevelElevator(newElevationConfig(1))
+
+	first, doneFirst := elevator.Elevate(context.Background(), "a")
+	if _, ok := logger.ElevatedLevel(first); !ok {
+		t.Fatal("expected the first request to be elevated")
+	}
+
+	second, doneSecond := elevator.Elevate(context.Background(), "b")
+	if _, ok := logger.ElevatedLevel(second); ok {
+		t.Error("expected the second request not to be elevated")
+	}
+
+	doneSecond()
+	doneFirst()
+	doneFirst()
+
+	if elevator.Active() != 0 {
+		t.Errorf("expected no active elevations, got %d", elevator.Active())
+	}
+
+	third, doneThird := elevator.Elevate(context.Background(), "c")
+	defer doneThird()
+
+	if level, ok := logger.ElevatedLevel(third); !ok || level != slog.LevelDebug {
+		t.Errorf("expected the third request to be elevated to debug, got %v %v", level, ok)
+	}
+}
+
+func TestLevelElevatorDisabled(t *testing.T) {
+	cfg := newElevationConfig(1)
+	cfg.Enabled = false
+
+	ctx, done := logger.NewLevelElevator(cfg).Elevate(context.Background(), "a")
+	defer done()
+
+	if _, ok := logger.ElevatedLevel(ctx); ok {
+		t.Error("expected the request not to be elevated")
+	}
+
+	ctx, done = logger.NewLevelElevator(newElevationConfig(1)).Elevate(context.Background(), "")
+	defer done()
+
+	if _, ok := logger.ElevatedLevel(ctx); ok {
+		t.Error("expected a request without reason not to be elevated")
+	}
+}
+
+func TestLevelElevatorHTTPMiddleware(t *testing.T) {
+	elevator := logger.NewLevelElevator(newElevationConfig(1))
+
+	var (
+		elevated bool
+		active   int
+		attrs    []slog.Attr
+	)
+
+	handler := elevator.HTTPMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		_, elevated = logger.ElevatedLevel(r.Context())
+		active = elevator.Active()
+		attrs = slogctx.ExtractAppended(r.Context(), time.Time{}, slog.LevelInfo, "")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Support-Ticket", "TICKET-2")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !elevated || active != 1 {
+		t.Errorf("expected an elevated request, got elevated=%v active=%d", elevated, active)
+	}
+
+	if len(attrs) != 1 || attrs[0].Value.String() != "TICKET-2" {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+
+	if elevator.Active() != 0 {
+		t.Errorf("expected the elevation to end with the request, got %d", elevator.Active())
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if elevated {
+		t.Error("expected a request without header not to be elevated")
+	}
+}
+
+func TestLevelElevatorServerInterceptors(t *testing.T) {
+	elevator := logger.NewLevelElevator(newElevationConfig(1))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-support-ticket", "TICKET-3"))
+
+	var elevated bool
+
+	_, err := elevator.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		_, elevated = logger.ElevatedLevel(ctx)
+		return nil, nil
+	})
+	if err != nil || !elevated {
+		t.Errorf("expected an elevated unary call, got elevated=%v err=%v", elevated, err)
+	}
+
+	elevated = false
+
+	err = elevator.StreamServerInterceptor()(nil, &testServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, func(_ any, ss grpc.ServerStream) error {
+		_, elevated = logger.ElevatedLevel(ss.Context())
+		return nil
+	})
+	if err != nil || !elevated {
+		t.Errorf("expected an elevated stream, got elevated=%v err=%v", elevated, err)
+	}
+
+	if elevator.Active() != 0 {
+		t.Errorf("expected no active elevations, got %d", elevator.Active())
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context //nolint:containedctx
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
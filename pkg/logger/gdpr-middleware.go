@@ -7,11 +7,6 @@ import (
 	"github.com/openkcm/common-sdk/pkg/commoncfg"
 )
 
-type masking struct {
-	pii  bool
-	mask string
-}
-
 type Middleware func(slog.Handler) slog.Handler
 
 // NewGDPRMiddleware creates a new gdprMiddleware with masking and renaming rules.
@@ -22,35 +17,22 @@ func NewGDPRMiddleware(logger *commoncfg.Logger) Middleware {
 		LevelAttribute:   logger.Formatter.Fields.Level,
 	}
 
-	maskingFields := map[string]masking{}
-	for _, pii := range logger.Formatter.Fields.Masking.PII {
-		maskingFields[pii] = masking{
-			pii:  true,
-			mask: "*******",
-		}
-	}
-
-	for key, value := range logger.Formatter.Fields.Masking.Other {
-		maskingFields[key] = masking{
-			pii:  false,
-			mask: value,
-		}
-	}
+	masker := NewFieldMasker(logger.Formatter.Fields.Masking)
 
 	return func(next slog.Handler) slog.Handler {
 		return &gdprMiddleware{
-			next:          next,
-			maskingFields: maskingFields,
-			replaceAttr:   replaceAttr,
+			next:        next,
+			masker:      masker,
+			replaceAttr: replaceAttr,
 		}
 	}
 }
 
 // gdprMiddleware is a slog.Handler that masks and renames sensitive log attributes.
 type gdprMiddleware struct {
-	next          slog.Handler
-	maskingFields map[string]masking
-	replaceAttr   map[string]string
+	next        slog.Handler
+	masker      *FieldMasker
+	replaceAttr map[string]string
 }
 
 // Enabled delegates the check to the wrapped handler.
@@ -82,18 +64,18 @@ func (h *gdprMiddleware) WithAttrs(attrs []slog.Attr) slog.Handler {
 	}
 
 	return &gdprMiddleware{
-		next:          h.next.WithAttrs(attrs),
-		maskingFields: h.maskingFields,
-		replaceAttr:   h.replaceAttr,
+		next:        h.next.WithAttrs(attrs),
+		masker:      h.masker,
+		replaceAttr: h.replaceAttr,
 	}
 }
 
 // WithGroup applies a group name to the wrapped handler.
 func (h *gdprMiddleware) WithGroup(name string) slog.Handler {
 	return &gdprMiddleware{
-		next:          h.next.WithGroup(name),
-		maskingFields: h.maskingFields,
-		replaceAttr:   h.replaceAttr,
+		next:        h.next.WithGroup(name),
+		masker:      h.masker,
+		replaceAttr: h.replaceAttr,
 	}
 }
 
@@ -112,18 +94,14 @@ func (h *gdprMiddleware) anonymizeAndRename(attr slog.Attr) slog.Attr {
 
 		return slog.Group(k, toAnySlice(attrs)...)
 	default:
-		mask, ok := h.maskingFields[k]
+		masked, ok := h.masker.Mask(k, v.String())
 		if ok {
 			newKey, found := h.replaceAttr[k]
 			if !found {
 				newKey = k
 			}
 
-			if mask.pii {
-				return slog.String(newKey, v.String()[0:4]+mask.mask)
-			}
-
-			return slog.String(newKey, mask.mask)
+			return slog.String(newKey, masked)
 		}
 
 		return attr
@@ -30,19 +30,25 @@ const (
 // setLogLevel converts the level string used in the config to a slog.LevelVar
 // and sets the levelVar to the corresponding level.
 func setLogLevel(levelVar *slog.LevelVar, level string) {
+	levelVar.Set(parseLevel(level))
+}
+
+// parseLevel converts the level string used in the config to a slog.Level,
+// unknown levels default to info.
+func parseLevel(level string) slog.Level {
 	switch strings.ToLower(level) {
 	case "trace":
-		levelVar.Set(LevelTrace)
+		return LevelTrace
 	case "debug":
-		levelVar.Set(slog.LevelDebug)
+		return slog.LevelDebug
 	case "info":
-		levelVar.Set(slog.LevelInfo)
+		return slog.LevelInfo
 	case "warn":
-		levelVar.Set(slog.LevelWarn)
+		return slog.LevelWarn
 	case "error":
-		levelVar.Set(slog.LevelError)
+		return slog.LevelError
 	default:
-		levelVar.Set(slog.LevelInfo)
+		return slog.LevelInfo
 	}
 }
 
@@ -154,6 +160,9 @@ func InitHandlerWithWriter(w io.Writer, cfgLogger commoncfg.Logger, app commoncf
 		}).WithAttrs(attrs)
 	}
 
+	// requests elevated by a LevelElevator pass the level check of the handler
+	handler = NewElevationHandler(handler)
+
 	for _, pii := range cfgLogger.Formatter.Fields.Masking.PII {
 		formatters = append(formatters, slogformatter.PIIFormatter(pii))
 	}
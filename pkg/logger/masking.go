@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+const (
+	piiMask          = "*******"
+	piiVisiblePrefix = 4
+)
+
+type masking struct {
+	pii  bool
+	mask string
+}
+
+// apply returns the masked value: PII values keep their first characters, unless they are
+// too short to hide anything, the other values are replaced by the mask.
+func (m masking) apply(value string) string {
+	if !m.pii {
+		return m.mask
+	}
+
+	if len(value) <= piiVisiblePrefix {
+		return m.mask
+	}
+
+	return value[:piiVisiblePrefix] + m.mask
+}
+
+// FieldMasker masks the values of sensitive fields with the masking rules of the logger
+// configuration, so the same rules can be applied outside of the logs, e.g. to audit events.
+type FieldMasker struct {
+	fields map[string]masking
+}
+
+// NewFieldMasker creates a FieldMasker with the masking rules. The PII fields keep their first
+// four characters followed by a fixed mask, the other fields are replaced by their mask.
+func NewFieldMasker(cfg commoncfg.LoggerFieldsMasking) *FieldMasker {
+	fields := make(map[string]masking, len(cfg.PII)+len(cfg.Other))
+	for _, pii := range cfg.PII {
+		fields[pii] = masking{
+			pii:  true,
+			mask: piiMask,
+		}
+	}
+
+	for key, value := range cfg.Other {
+		fields[key] = masking{
+			pii:  false,
+			mask: value,
+		}
+	}
+
+	return &FieldMasker{fields: fields}
+}
+
+// Mask returns the masked value of the field and true, or the value and false if the field is not masked.
+func (m *FieldMasker) Mask(key, value string) (string, bool) {
+	mask, ok := m.fields[key]
+	if !ok {
+		return value, false
+	}
+
+	return mask.apply(value), true
+}
@@ -0,0 +1,39 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/logger"
+)
+
+func TestFieldMasker(t *testing.T) {
+	masker := logger.NewFieldMasker(commoncfg.LoggerFieldsMasking{
+		PII:   []string{"email", "name"},
+		Other: map[string]string{"credit_card": "****"},
+	})
+
+	tests := []struct {
+		name       string
+		key        string
+		value      string
+		want       string
+		wantMasked bool
+	}{
+		{name: "PII field", key: "email", value: "john@example.com", want: "john*******", wantMasked: true},
+		{name: "short PII field", key: "name", value: "Jo", want: "*******", wantMasked: true},
+		{name: "other field", key: "credit_card", value: "1111-2222-3333-4444", want: "****", wantMasked: true},
+		{name: "unmasked field", key: "status", value: "active", want: "active", wantMasked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, masked := masker.Mask(tt.key, tt.value)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantMasked, masked)
+		})
+	}
+}
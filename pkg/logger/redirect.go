@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/grpclog"
+)
+
+// OriginAttribute is the attribute holding the origin of redirected log lines, e.g. grpc.
+const OriginAttribute = "logger"
+
+const (
+	originStdLog = "stdlib"
+	originGRPC   = "grpc"
+	originHTTP   = "http"
+)
+
+// levelPrefixes are the level prefixes of unstructured log lines, e.g. "[WARN] retrying".
+var levelPrefixes = []struct {
+	prefix string
+	level  slog.Level
+}{
+	{"[TRACE]", LevelTrace},
+	{"[DEBUG]", slog.LevelDebug},
+	{"[INFO]", slog.LevelInfo},
+	{"[WARN]", slog.LevelWarn},
+	{"[WARNING]", slog.LevelWarn},
+	{"[ERROR]", slog.LevelError},
+	{"TRACE:", LevelTrace},
+	{"DEBUG:", slog.LevelDebug},
+	{"INFO:", slog.LevelInfo},
+	{"WARN:", slog.LevelWarn},
+	{"WARNING:", slog.LevelWarn},
+	{"ERROR:", slog.LevelError},
+}
+
+// NewStdLogger returns a *log.Logger writing every line as a record to the handler, e.g. for
+// dependencies which only accept a *log.Logger. Lines starting with a level prefix like
+// "[WARN]" or "error:" are logged with that level, all other lines with the given level.
+func NewStdLogger(handler slog.Handler, level slog.Level) *log.Logger {
+	return log.New(newLineWriter(handler, level, originStdLog), "", 0)
+}
+
+// NewHTTPErrorLog returns a logger for http.Server.ErrorLog writing to the handler,
+// so errors of the server, e.g. failed TLS handshakes, end up in the slog pipeline:
+//
+//	server.ErrorLog = logger.NewHTTPErrorLog(slog.Default().Handler())
+func NewHTTPErrorLog(handler slog.Handler) *log.Logger {
+	return log.New(newLineWriter(handler, slog.LevelError, originHTTP), "", 0)
+}
+
+// RedirectStdLog redirects the default logger of the standard library log package to the
+// handler. Lines without level prefix are logged with the given level.
+//
+// slog.SetDefault already redirects the log package, but always at info level and only to
+// the default handler; call RedirectStdLog after it to choose the level and handler.
+func RedirectStdLog(handler slog.Handler, level slog.Level) {
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(newLineWriter(handler, level, originStdLog))
+}
+
+// RedirectGRPCLog redirects the internal logs of gRPC to the handler, with the levels of
+// grpclog mapped to the slog levels. Info logs with a verbosity above the given verbosity
+// are dropped, see grpclog.LoggerV2.V.
+//
+// Like grpclog.SetLoggerV2 it must be called before any other gRPC function.
+func RedirectGRPCLog(handler slog.Handler, verbosity int) {
+	grpclog.SetLoggerV2(&grpcLogger{
+		handler:   handler.WithAttrs([]slog.Attr{slog.String(OriginAttribute, originGRPC)}),
+		verbosity: verbosity,
+	})
+}
+
+// lineWriter writes the lines of a *log.Logger as records to a handler.
+type lineWriter struct {
+	handler slog.Handler
+	level   slog.Level
+}
+
+func newLineWriter(handler slog.Handler, level slog.Level, origin string) *lineWriter {
+	return &lineWriter{
+		handler: handler.WithAttrs([]slog.Attr{slog.String(OriginAttribute, origin)}),
+		level:   level,
+	}
+}
+
+// Write is called by the *log.Logger once per line.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	level, msg := levelFromPrefix(strings.TrimRight(string(p), "\r\n"), w.level)
+
+	err := logRecord(w.handler, level, msg)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// levelFromPrefix returns the level of the level prefix of msg and the msg without it.
+func levelFromPrefix(msg string, level slog.Level) (slog.Level, string) {
+	for _, p := range levelPrefixes {
+		if len(msg) >= len(p.prefix) && strings.EqualFold(msg[:len(p.prefix)], p.prefix) {
+			return p.level, strings.TrimSpace(msg[len(p.prefix):])
+		}
+	}
+
+	return level, msg
+}
+
+func logRecord(handler slog.Handler, level slog.Level, msg string) error {
+	ctx := context.Background()
+	if !handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	return handler.Handle(ctx, slog.NewRecord(time.Now(), level, msg, 0))
+}
+
+// grpcLogger implements grpclog.LoggerV2 on top of a handler.
+type grpcLogger struct {
+	handler   slog.Handler
+	verbosity int
+}
+
+func (l *grpcLogger) log(level slog.Level, msg string) {
+	_ = logRecord(l.handler, level, strings.TrimSuffix(msg, "\n"))
+}
+
+func (l *grpcLogger) Info(args ...any) {
+	l.log(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+func (l *grpcLogger) Infoln(args ...any) {
+	l.log(slog.LevelInfo, fmt.Sprintln(args...))
+}
+
+func (l *grpcLogger) Infof(format string, args ...any) {
+	l.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *grpcLogger) Warning(args ...any) {
+	l.log(slog.LevelWarn, fmt.Sprint(args...))
+}
+
+func (l *grpcLogger) Warningln(args ...any) {
+	l.log(slog.LevelWarn, fmt.Sprintln(args...))
+}
+
+func (l *grpcLogger) Warningf(format string, args ...any) {
+	l.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (l *grpcLogger) Error(args ...any) {
+	l.log(slog.LevelError, fmt.Sprint(args...))
+}
+
+func (l *grpcLogger) Errorln(args ...any) {
+	l.log(slog.LevelError, fmt.Sprintln(args...))
+}
+
+func (l *grpcLogger) Errorf(format string, args ...any) {
+	l.log(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+// Fatal logs at error level and exits, as required by grpclog.LoggerV2.
+func (l *grpcLogger) Fatal(args ...any) {
+	l.log(slog.LevelError, fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+func (l *grpcLogger) Fatalln(args ...any) {
+	l.log(slog.LevelError, fmt.Sprintln(args...))
+	os.Exit(1)
+}
+
+func (l *grpcLogger) Fatalf(format string, args ...any) {
+	l.log(slog.LevelError, fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// V reports whether info logs of the verbosity level are logged.
+func (l *grpcLogger) V(level int) bool {
+	return level <= l.verbosity
+}
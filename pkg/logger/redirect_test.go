@@ -0,0 +1,147 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc/grpclog"
+
+	"github.com/openkcm/common-sdk/pkg/logger"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var record map[string]any
+
+		err := dec.Decode(&record)
+		if err != nil {
+			t.Fatalf("decoding record: %v", err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestNewStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	stdLogger := logger.NewStdLogger(handler, slog.LevelInfo)
+
+	stdLogger.Println("plain line")
+	stdLogger.Println("[WARN] retrying")
+	stdLogger.Println("error: connection refused")
+	stdLogger.Println("[debug] details")
+
+	want := []struct {
+		level string
+		msg   string
+	}{
+		{"INFO", "plain line"},
+		{"WARN", "retrying"},
+		{"ERROR", "connection refused"},
+		{"DEBUG", "details"},
+	}
+
+	records := decodeRecords(t, &buf)
+	if len(records) != len(want) {
+		t.Fatalf("expected %d records, got %d", len(want), len(records))
+	}
+
+	for i, w := range want {
+		if records[i]["level"] != w.level || records[i]["msg"] != w.msg {
+			t.Errorf("record %d: expected %s %q, got %v %q", i, w.level, w.msg, records[i]["level"], records[i]["msg"])
+		}
+
+		if records[i][logger.OriginAttribute] != "stdlib" {
+			t.Errorf("record %d: expected origin stdlib, got %v", i, records[i][logger.OriginAttribute])
+		}
+	}
+}
+
+func TestNewHTTPErrorLog(t *testing.T) {
+	var buf bytes.Buffer
+
+	errorLog := logger.NewHTTPErrorLog(slog.NewJSONHandler(&buf, nil))
+	errorLog.Printf("http: TLS handshake error from %s: EOF", "127.0.0.1:1234")
+
+	records := decodeRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+
+	if records[0]["level"] != "ERROR" || records[0][logger.OriginAttribute] != "http" {
+		t.Errorf("unexpected record %v", records[0])
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	flags, prefix, writer := log.Flags(), log.Prefix(), log.Writer()
+
+	t.Cleanup(func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(writer)
+	})
+
+	var buf bytes.Buffer
+
+	logger.RedirectStdLog(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}), slog.LevelInfo)
+
+	log.Print("dropped below the handler level")
+	log.Print("WARNING: kept")
+
+	records := decodeRecords(t, &buf)
+	if len(records) != 1 || records[0]["msg"] != "kept" || records[0]["level"] != "WARN" {
+		t.Errorf("unexpected records %v", records)
+	}
+}
+
+func TestRedirectGRPCLog(t *testing.T) {
+	t.Cleanup(func() {
+		grpclog.SetLoggerV2(grpclog.NewLoggerV2(os.Stderr, os.Stderr, os.Stderr))
+	})
+
+	var buf bytes.Buffer
+
+	logger.RedirectGRPCLog(slog.NewJSONHandler(&buf, nil), 1)
+
+	grpclog.Infof("channel %d created", 1)
+	grpclog.Warningln("transport closing")
+	grpclog.Error("connection failed")
+
+	if !grpclog.V(1) || grpclog.V(2) {
+		t.Errorf("expected verbosity 1")
+	}
+
+	want := []struct {
+		level string
+		msg   string
+	}{
+		{"INFO", "channel 1 created"},
+		{"WARN", "transport closing"},
+		{"ERROR", "connection failed"},
+	}
+
+	records := decodeRecords(t, &buf)
+	if len(records) != len(want) {
+		t.Fatalf("expected %d records, got %d: %v", len(want), len(records), records)
+	}
+
+	for i, w := range want {
+		if records[i]["level"] != w.level || records[i]["msg"] != w.msg || records[i][logger.OriginAttribute] != "grpc" {
+			t.Errorf("record %d: expected %s %q, got %v", i, w.level, w.msg, records[i])
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	// codeChallengeMethodS256 is the only PKCE method supported, as plain is discouraged.
+	codeChallengeMethodS256 = "S256"
+	// randomBytes is the entropy of the generated code verifiers, states and nonces.
+	randomBytes = 32
+)
+
+// AuthorizationRequest is a request to the authorization endpoint of the OpenID Provider,
+// starting the authorization code flow with PKCE. The client keeps the request, e.g. in
+// the session, until the callback, to validate the callback and exchange the code.
+// See https://datatracker.ietf.org/doc/html/rfc7636 for details on PKCE.
+type AuthorizationRequest struct {
+	ClientID    string
+	RedirectURI string
+	// Scopes are the requested scopes. The openid scope is always requested.
+	Scopes []string
+
+	// State protects the callback against CSRF, Nonce binds the ID token to the request
+	// and CodeVerifier is the secret of the code challenge. They are generated by
+	// NewAuthorizationRequest and must not be sent to the user agent.
+	State        string
+	Nonce        string
+	CodeVerifier string
+
+	// Parameters are additional parameters of the request, e.g. prompt or login_hint.
+	Parameters map[string]string
+}
+
+// NewAuthorizationRequest creates an authorization request with a random state, nonce
+// and code verifier.
+func NewAuthorizationRequest(clientID, redirectURI string, scopes ...string) (AuthorizationRequest, error) {
+	r := AuthorizationRequest{
+		ClientID:    clientID,
+		RedirectURI: redirectURI,
+		Scopes:      scopes,
+	}
+
+	for _, value := range []*string{&r.State, &r.Nonce, &r.CodeVerifier} {
+		random, err := randomString()
+		if err != nil {
+			return AuthorizationRequest{}, err
+		}
+
+		*value = random
+	}
+
+	return r, nil
+}
+
+// GenerateCodeVerifier returns a random PKCE code verifier.
+func GenerateCodeVerifier() (string, error) {
+	return randomString()
+}
+
+// CodeChallengeS256 returns the S256 PKCE code challenge of the code verifier.
+func CodeChallengeS256(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// AuthorizationURL returns the URL of the authorization endpoint the user agent is
+// redirected to, to authorize the request.
+func (p *Provider) AuthorizationURL(ctx context.Context, r AuthorizationRequest) (string, error) {
+	cfg, err := p.GetConfiguration(ctx)
+	if err != nil {
+		return "", errors.Join(ErrCouldNotGetWellKnownConfig, err)
+	}
+
+	if cfg.AuthorizationEndpoint == "" {
+		return "", ErrNoAuthorizationEndpoint
+	}
+
+	if len(cfg.CodeChallengeMethodsSupported) > 0 &&
+		!slices.Contains(cfg.CodeChallengeMethodsSupported, codeChallengeMethodS256) {
+		return "", fmt.Errorf("%w: provider does not support the S256 code challenge", ErrInvalidAuthorizationReq)
+	}
+
+	var missing []string
+
+	for param, value := range map[string]string{
+		"client_id":     r.ClientID,
+		"redirect_uri":  r.RedirectURI,
+		"state":         r.State,
+		"nonce":         r.Nonce,
+		"code_verifier": r.CodeVerifier,
+	} {
+		if value == "" {
+			missing = append(missing, param)
+		}
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+
+		return "", fmt.Errorf("%w: missing %s", ErrInvalidAuthorizationReq, strings.Join(missing, ", "))
+	}
+
+	u, err := url.Parse(cfg.AuthorizationEndpoint)
+	if err != nil {
+		return "", errors.Join(ErrCouldNotBuildURL, err)
+	}
+
+	scopes := r.Scopes
+	if !slices.Contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+
+	query := u.Query()
+	for k, v := range r.Parameters {
+		query.Set(k, v)
+	}
+
+	query.Set("response_type", "code")
+	query.Set("client_id", r.ClientID)
+	query.Set("redirect_uri", r.RedirectURI)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", r.State)
+	query.Set("nonce", r.Nonce)
+	query.Set("code_challenge", CodeChallengeS256(r.CodeVerifier))
+	query.Set("code_challenge_method", codeChallengeMethodS256)
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// ValidateCallback validates the query of the callback to the redirect URI of the request
+// and returns the authorization code. It returns an AuthorizationError if the provider
+// denied the request. The code is exchanged with the token request of TokenRequest, and
+// the nonce of the ID token is checked by VerifyIDToken with WithNonce.
+func (p *Provider) ValidateCallback(r AuthorizationRequest, query url.Values) (string, error) {
+	// RFC 9207 identifies the provider in the callback to prevent mix-up attacks
+	if iss := query.Get("iss"); iss != "" && iss != p.issuer {
+		return "", fmt.Errorf("%w: %s", ErrIssuerMismatch, iss)
+	}
+
+	state := query.Get("state")
+	if r.State == "" || subtle.ConstantTimeCompare([]byte(state), []byte(r.State)) != 1 {
+		return "", ErrStateMismatch
+	}
+
+	if errorCode := query.Get("error"); errorCode != "" {
+		return "", AuthorizationError{
+			ErrorCode:        errorCode,
+			ErrorDescription: query.Get("error_description"),
+		}
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return "", ErrMissingAuthorizationCode
+	}
+
+	return code, nil
+}
+
+// TokenRequest returns the request exchanging the authorization code of the callback.
+// The client authentication is added by the caller, e.g. the ClientSecret.
+func (r AuthorizationRequest) TokenRequest(code string) TokenRequest {
+	return TokenRequest{
+		GrantType:    GrantTypeAuthorizationCode,
+		ClientID:     r.ClientID,
+		Code:         code,
+		RedirectURI:  r.RedirectURI,
+		CodeVerifier: r.CodeVerifier,
+	}
+}
+
+// randomString returns a random URL safe string.
+func randomString() (string, error) {
+	b := make([]byte, randomBytes)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
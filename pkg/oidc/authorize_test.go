@@ -0,0 +1,146 @@
+package oidc
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeChallengeS256(t *testing.T) {
+	// test vector of https://datatracker.ietf.org/doc/html/rfc7636#appendix-B
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+		CodeChallengeS256("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
+
+func TestGenerateCodeVerifier(t *testing.T) {
+	verifier, err := GenerateCodeVerifier()
+	require.NoError(t, err)
+
+	// RFC 7636 requires 43 to 128 unreserved characters
+	assert.Len(t, verifier, 43)
+	assert.Regexp(t, `^[A-Za-z0-9\-._~]+$`, verifier)
+
+	other, err := GenerateCodeVerifier()
+	require.NoError(t, err)
+	assert.NotEqual(t, verifier, other)
+}
+
+var authorizeTestConfiguration = Configuration{AuthorizationEndpoint: "https://issuer/authorize?tenant=t1"}
+
+func TestAuthorizationURL(t *testing.T) {
+	request, err := NewAuthorizationRequest("client", "https://app/callback", "profile")
+	require.NoError(t, err)
+
+	request.Parameters = map[string]string{"prompt": "login"}
+
+	t.Run("builds the URL of the authorization endpoint", func(t *testing.T) {
+		provider := newTestProvider(t, Configuration{
+			AuthorizationEndpoint:         authorizeTestConfiguration.AuthorizationEndpoint,
+			CodeChallengeMethodsSupported: []string{"plain", "S256"},
+		}, nil)
+
+		rawURL, err := provider.AuthorizationURL(t.Context(), request)
+		require.NoError(t, err)
+
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		assert.Equal(t, "https://issuer/authorize", u.Scheme+"://"+u.Host+u.Path)
+
+		query := u.Query()
+		assert.Equal(t, "t1", query.Get("tenant"))
+		assert.Equal(t, "login", query.Get("prompt"))
+		assert.Equal(t, "code", query.Get("response_type"))
+		assert.Equal(t, "client", query.Get("client_id"))
+		assert.Equal(t, "https://app/callback", query.Get("redirect_uri"))
+		assert.Equal(t, "openid profile", query.Get("scope"))
+		assert.Equal(t, request.State, query.Get("state"))
+		assert.Equal(t, request.Nonce, query.Get("nonce"))
+		assert.Equal(t, CodeChallengeS256(request.CodeVerifier), query.Get("code_challenge"))
+		assert.Equal(t, "S256", query.Get("code_challenge_method"))
+		assert.False(t, query.Has("code_verifier"))
+	})
+
+	t.Run("rejects providers without S256 support", func(t *testing.T) {
+		provider := newTestProvider(t, Configuration{
+			AuthorizationEndpoint:         authorizeTestConfiguration.AuthorizationEndpoint,
+			CodeChallengeMethodsSupported: []string{"plain"},
+		}, nil)
+
+		_, err := provider.AuthorizationURL(t.Context(), request)
+		assert.ErrorIs(t, err, ErrInvalidAuthorizationReq)
+	})
+
+	t.Run("rejects requests without state", func(t *testing.T) {
+		provider := newTestProvider(t, authorizeTestConfiguration, nil)
+
+		_, err := provider.AuthorizationURL(t.Context(), AuthorizationRequest{ClientID: "client"})
+		require.ErrorIs(t, err, ErrInvalidAuthorizationReq)
+		assert.ErrorContains(t, err, "missing code_verifier, nonce, redirect_uri, state")
+	})
+
+	t.Run("fails without authorization endpoint", func(t *testing.T) {
+		provider := newTestProvider(t, authorizeTestConfiguration, nil)
+		provider.config.AuthorizationEndpoint = ""
+
+		_, err := provider.AuthorizationURL(t.Context(), request)
+		assert.ErrorIs(t, err, ErrNoAuthorizationEndpoint)
+	})
+}
+
+func TestValidateCallback(t *testing.T) {
+	provider := newTestProvider(t, authorizeTestConfiguration, nil)
+
+	request, err := NewAuthorizationRequest("client", "https://app/callback")
+	require.NoError(t, err)
+
+	t.Run("returns the code", func(t *testing.T) {
+		code, err := provider.ValidateCallback(request, url.Values{
+			"code": {"code"}, "state": {request.State}, "iss": {"https://issuer"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "code", code)
+
+		assert.Equal(t, TokenRequest{
+			GrantType:    GrantTypeAuthorizationCode,
+			ClientID:     "client",
+			Code:         "code",
+			RedirectURI:  "https://app/callback",
+			CodeVerifier: request.CodeVerifier,
+		}, request.TokenRequest(code))
+	})
+
+	t.Run("returns the error of the provider", func(t *testing.T) {
+		_, err := provider.ValidateCallback(request, url.Values{
+			"error": {"access_denied"}, "error_description": {"user denied"}, "state": {request.State},
+		})
+
+		var authErr AuthorizationError
+		require.ErrorAs(t, err, &authErr)
+		assert.Equal(t, "access_denied", authErr.ErrorCode)
+		assert.Equal(t, "authorization endpoint responded with error: access_denied: user denied", authErr.Error())
+	})
+
+	tests := []struct {
+		name    string
+		query   url.Values
+		wantErr error
+	}{
+		{name: "wrong state", query: url.Values{"code": {"code"}, "state": {"other"}}, wantErr: ErrStateMismatch},
+		{name: "missing state", query: url.Values{"code": {"code"}}, wantErr: ErrStateMismatch},
+		{name: "missing code", query: url.Values{"state": {request.State}}, wantErr: ErrMissingAuthorizationCode},
+		{
+			name:    "wrong issuer",
+			query:   url.Values{"code": {"code"}, "state": {request.State}, "iss": {"https://attacker"}},
+			wantErr: ErrIssuerMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.ValidateCallback(request, tt.query)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
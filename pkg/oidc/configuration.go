@@ -4,12 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
+	"time"
 )
 
-const wellKnownOpenIDConfigPath = "/.well-known/openid-configuration"
+const (
+	wellKnownOpenIDConfigPath = "/.well-known/openid-configuration"
+
+	configRefreshTimeout = 30 * time.Second
+	configRetryInterval  = time.Minute
+)
 
 // Configuration is the meta data describing the configuration of an OpenID Provider.
 // It can be onbtained from the .well-known/openid-configuration endpoint.
@@ -27,21 +37,32 @@ type Configuration struct {
 	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
 	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"`
 	ClaimsSupported                   []string `json:"claims_supported,omitempty"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported,omitempty"`
 
 	// From https://datatracker.ietf.org/doc/html/rfc7662
 	IntrospectionEndpoint string `json:"introspection_endpoint,omitempty"`
 
+	// From https://datatracker.ietf.org/doc/html/rfc8628#section-4
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint,omitempty"`
+
 	// From https://openid.net/specs/openid-connect-rpinitiated-1_0.html#OPMetadata
 	EndSessionEndpoint string `json:"end_session_endpoint,omitempty"`
 }
 
 // GetConfiguration fetches and stores the OpenID configuration for the provider.
+// With a configuration TTL, an expired configuration is still returned while it is
+// refreshed in the background, so callers are not blocked by the OpenID Provider.
 func (p *Provider) GetConfiguration(ctx context.Context) (*Configuration, error) {
 	// Fast path: check if config is already set with read lock
 	p.configMu.RLock()
 
 	if p.config != nil {
 		defer p.configMu.RUnlock()
+
+		if p.configTTL > 0 && time.Now().After(p.configExpiresAt) {
+			p.refreshConfiguration(ctx)
+		}
+
 		return p.config, nil
 	}
 
@@ -56,6 +77,68 @@ func (p *Provider) GetConfiguration(ctx context.Context) (*Configuration, error)
 		return p.config, nil
 	}
 
+	conf, err := p.fetchConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.config = conf
+	p.configExpiresAt = time.Now().Add(p.configTTL)
+
+	return p.config, nil
+}
+
+// Invalidate drops the cached OpenID configuration and signing keys, so they are fetched
+// again on their next use, e.g. after the provider was reconfigured.
+func (p *Provider) Invalidate() {
+	p.configMu.Lock()
+	p.config = nil
+	p.configMu.Unlock()
+
+	p.keysMu.Lock()
+	p.keys = nil
+	p.missingKeys = nil
+	p.keysMu.Unlock()
+}
+
+// refreshConfiguration fetches the expired configuration in the background, unless it
+// is already being refreshed. A failed refresh keeps the stale configuration and is
+// retried after the configuration retry interval at the latest.
+func (p *Provider) refreshConfiguration(ctx context.Context) {
+	if !p.configRefreshing.CompareAndSwap(false, true) {
+		return
+	}
+
+	// the refresh must not be canceled with the request which triggered it
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		defer p.configRefreshing.Store(false)
+
+		ctx, cancel := context.WithTimeout(ctx, configRefreshTimeout)
+		defer cancel()
+
+		conf, err := p.fetchConfiguration(ctx)
+
+		p.configMu.Lock()
+		defer p.configMu.Unlock()
+
+		if err != nil {
+			p.configExpiresAt = time.Now().Add(min(p.configTTL, configRetryInterval))
+			return
+		}
+
+		// the configuration may have been invalidated meanwhile
+		if p.config != nil {
+			p.config = conf
+		}
+
+		p.configExpiresAt = time.Now().Add(p.configTTL)
+	}()
+}
+
+// fetchConfiguration fetches and validates the OpenID configuration of the provider.
+func (p *Provider) fetchConfiguration(ctx context.Context) (*Configuration, error) {
 	u, err := url.JoinPath(p.issuerURI, wellKnownOpenIDConfigPath)
 	if err != nil {
 		return nil, errors.Join(ErrCouldNotBuildURL, err)
@@ -66,7 +149,7 @@ func (p *Provider) GetConfiguration(ctx context.Context) (*Configuration, error)
 		return nil, errors.Join(ErrCouldNotCreateHTTPRequest, err)
 	}
 
-	resp, err := p.publicHttpClient.Do(req)
+	resp, err := p.doWithRetry(p.publicHttpClient, req)
 	if err != nil {
 		return nil, errors.Join(ErrCouldNotDoHTTPRequest, err)
 	}
@@ -94,7 +177,89 @@ func (p *Provider) GetConfiguration(ctx context.Context) (*Configuration, error)
 		}
 	}
 
-	p.config = &conf
+	err = p.validateConfiguration(&conf)
+	if err != nil {
+		return nil, err
+	}
 
-	return p.config, nil
+	return &conf, nil
+}
+
+// validateConfiguration protects against IdP mix-up attacks: the configuration must be
+// published for the issuer of the provider, the endpoints must use HTTPS unless HTTP is
+// allowed, and the token, introspection and device authorization endpoints must share the
+// origin of the issuer.
+func (p *Provider) validateConfiguration(conf *Configuration) error {
+	if !p.skipIssuerValidation && conf.Issuer != p.issuer {
+		return fmt.Errorf("%w: expected %q, got %q", ErrIssuerMismatch, p.issuer, conf.Issuer)
+	}
+
+	endpoints := map[string]string{
+		"authorization_endpoint":        conf.AuthorizationEndpoint,
+		"token_endpoint":                conf.TokenEndpoint,
+		"userinfo_endpoint":             conf.UserinfoEndpoint,
+		"jwks_uri":                      conf.JwksURI,
+		"introspection_endpoint":        conf.IntrospectionEndpoint,
+		"end_session_endpoint":          conf.EndSessionEndpoint,
+		"device_authorization_endpoint": conf.DeviceAuthorizationEndpoint,
+	}
+
+	for name, endpoint := range endpoints {
+		if endpoint == "" {
+			continue
+		}
+
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return errors.Join(ErrInvalidURI, fmt.Errorf("%s: %w", name, err))
+		}
+
+		if !p.allowHttpScheme && u.Scheme != "https" {
+			return errors.Join(ErrInvalidURI, fmt.Errorf("%w: %s %s", ErrInvalidURLScheme, name, endpoint))
+		}
+
+		// the client credentials are sent to these endpoints
+		credentialEndpoint := name == "token_endpoint" || name == "introspection_endpoint" ||
+			name == "device_authorization_endpoint"
+		if p.skipEndpointOriginValidation || !credentialEndpoint {
+			continue
+		}
+
+		if !slices.Contains(p.trustedOrigins(), origin(u)) {
+			return fmt.Errorf("%w: %s %s", ErrEndpointOriginMismatch, name, endpoint)
+		}
+	}
+
+	return nil
+}
+
+// trustedOrigins returns the origins of the issuer, the issuer URI and the trusted endpoint origins.
+func (p *Provider) trustedOrigins() []string {
+	origins := make([]string, 0, 2+len(p.trustedEndpointOrigins))
+
+	for _, uri := range append([]string{p.issuer, p.issuerURI}, p.trustedEndpointOrigins...) {
+		u, err := url.Parse(uri)
+		if err == nil && u.Host != "" {
+			origins = append(origins, origin(u))
+		}
+	}
+
+	return origins
+}
+
+// origin returns the scheme, host and port of the URL, the port is omitted if it is the default one.
+func origin(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+
+	port := u.Port()
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	return scheme + "://" + host
 }
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -31,7 +34,8 @@ func TestConfiguration(t *testing.T) {
 		}))
 		defer server.Close()
 
-		provider, err := NewProvider(server.URL, []string{"aud1"}, WithAllowHttpScheme(true))
+		provider, err := NewProvider(config.Issuer, []string{"aud1"},
+			WithCustomIssuerURI(server.URL), WithAllowHttpScheme(true))
 		require.NoError(t, err)
 
 		result, err := provider.GetConfiguration(ctx)
@@ -55,7 +59,8 @@ func TestConfiguration(t *testing.T) {
 		}))
 		defer server.Close()
 
-		provider, err := NewProvider(server.URL, []string{"aud1"}, WithAllowHttpScheme(true))
+		provider, err := NewProvider("test", []string{"aud1"},
+			WithCustomIssuerURI(server.URL), WithAllowHttpScheme(true))
 		require.NoError(t, err)
 
 		_, err = provider.GetConfiguration(ctx)
@@ -66,6 +71,102 @@ func TestConfiguration(t *testing.T) {
 		assert.Equal(t, 1, callCount, "should only call the server once")
 	})
 
+	t.Run("validates configuration", func(t *testing.T) {
+		const issuer = "https://issuer.example.com"
+
+		tests := []struct {
+			name    string
+			config  Configuration
+			opts    []ProviderOption
+			wantErr error
+		}{
+			{
+				name:    "issuer mismatch",
+				config:  Configuration{Issuer: "https://attacker.example.com"},
+				wantErr: ErrIssuerMismatch,
+			},
+			{
+				name:    "missing issuer",
+				config:  Configuration{},
+				wantErr: ErrIssuerMismatch,
+			},
+			{
+				name:   "issuer mismatch skipped",
+				config: Configuration{Issuer: "https://other.example.com"},
+				opts:   []ProviderOption{WithSkipIssuerValidation(true)},
+			},
+			{
+				name:    "token endpoint on another origin",
+				config:  Configuration{Issuer: issuer, TokenEndpoint: "https://attacker.example.com/token"},
+				wantErr: ErrEndpointOriginMismatch,
+			},
+			{
+				name:    "introspection endpoint on another port",
+				config:  Configuration{Issuer: issuer, IntrospectionEndpoint: "https://issuer.example.com:8443/introspect"},
+				wantErr: ErrEndpointOriginMismatch,
+			},
+			{
+				name:   "default port matches the issuer origin",
+				config: Configuration{Issuer: issuer, TokenEndpoint: "https://ISSUER.example.com:443/token"},
+			},
+			{
+				name:   "trusted endpoint origin",
+				config: Configuration{Issuer: issuer, TokenEndpoint: "https://login.example.com/token"},
+				opts:   []ProviderOption{WithTrustedEndpointOrigins("https://login.example.com")},
+			},
+			{
+				name:   "endpoint origin check skipped",
+				config: Configuration{Issuer: issuer, TokenEndpoint: "https://login.example.com/token"},
+				opts:   []ProviderOption{WithSkipEndpointOriginValidation(true)},
+			},
+			{
+				name:   "jwks on another origin",
+				config: Configuration{Issuer: issuer, JwksURI: "https://keys.example.com/jwks"},
+			},
+			{
+				name:    "http endpoint",
+				config:  Configuration{Issuer: issuer, JwksURI: "http://issuer.example.com/jwks"},
+				wantErr: ErrInvalidURLScheme,
+			},
+			{
+				name:   "http endpoint allowed",
+				config: Configuration{Issuer: issuer, JwksURI: "http://issuer.example.com/jwks"},
+				opts:   []ProviderOption{WithAllowHttpScheme(true)},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					assert.NoError(t, json.NewEncoder(w).Encode(tt.config))
+				}))
+				defer server.Close()
+
+				opts := append([]ProviderOption{
+					WithCustomIssuerURI(server.URL),
+					WithPublicHTTPClient(server.Client()),
+				}, tt.opts...)
+
+				provider, err := NewProvider(issuer, []string{"aud1"}, opts...)
+				require.NoError(t, err)
+
+				_, err = provider.GetConfiguration(ctx)
+				if tt.wantErr == nil {
+					require.NoError(t, err)
+					return
+				}
+
+				require.ErrorIs(t, err, tt.wantErr)
+
+				// invalid configurations are not cached
+				provider.configMu.RLock()
+				defer provider.configMu.RUnlock()
+				assert.Nil(t, provider.config)
+			})
+		}
+	})
+
 	t.Run("handles non-200 response", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -130,3 +231,113 @@ func TestConfiguration(t *testing.T) {
 		assert.ErrorIs(t, err, ErrCouldNotDoHTTPRequest)
 	})
 }
+
+// newVersionedConfigServer serves a configuration with the number of the request as scope.
+// Requests fail while failing is set.
+func newVersionedConfigServer(t *testing.T, failing *atomic.Bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		call := calls.Add(1)
+		if failing != nil && failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		assert.NoError(t, json.NewEncoder(w).Encode(Configuration{
+			Issuer:          "test",
+			ScopesSupported: []string{strconv.Itoa(int(call))},
+		}))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func TestConfigurationTTL(t *testing.T) {
+	t.Run("refreshes the expired configuration in the background", func(t *testing.T) {
+		server, calls := newVersionedConfigServer(t, nil)
+
+		provider, err := NewProvider("test", nil, WithCustomIssuerURI(server.URL),
+			WithAllowHttpScheme(true), WithConfigurationTTL(50*time.Millisecond))
+		require.NoError(t, err)
+
+		conf, err := provider.GetConfiguration(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1"}, conf.ScopesSupported)
+
+		time.Sleep(100 * time.Millisecond)
+
+		conf, err = provider.GetConfiguration(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1"}, conf.ScopesSupported, "expected the stale configuration")
+
+		assert.Eventually(t, func() bool {
+			conf, err := provider.GetConfiguration(t.Context())
+			return err == nil && conf.ScopesSupported[0] == "2"
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("keeps the stale configuration if the refresh fails", func(t *testing.T) {
+		var failing atomic.Bool
+
+		server, calls := newVersionedConfigServer(t, &failing)
+
+		provider, err := NewProvider("test", nil, WithCustomIssuerURI(server.URL),
+			WithAllowHttpScheme(true), WithConfigurationTTL(time.Millisecond))
+		require.NoError(t, err)
+
+		_, err = provider.GetConfiguration(t.Context())
+		require.NoError(t, err)
+
+		failing.Store(true)
+		time.Sleep(10 * time.Millisecond)
+
+		conf, err := provider.GetConfiguration(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1"}, conf.ScopesSupported)
+
+		assert.Eventually(t, func() bool {
+			return calls.Load() == 2 && !provider.configRefreshing.Load()
+		}, time.Second, 10*time.Millisecond)
+
+		conf, err = provider.GetConfiguration(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1"}, conf.ScopesSupported)
+	})
+
+	t.Run("never refreshes the configuration without TTL", func(t *testing.T) {
+		server, calls := newVersionedConfigServer(t, nil)
+
+		provider, err := NewProvider("test", nil, WithCustomIssuerURI(server.URL), WithAllowHttpScheme(true))
+		require.NoError(t, err)
+
+		for range 3 {
+			_, err = provider.GetConfiguration(t.Context())
+			require.NoError(t, err)
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestInvalidate(t *testing.T) {
+	server, calls := newVersionedConfigServer(t, nil)
+
+	provider, err := NewProvider("test", nil, WithCustomIssuerURI(server.URL), WithAllowHttpScheme(true))
+	require.NoError(t, err)
+
+	_, err = provider.GetConfiguration(t.Context())
+	require.NoError(t, err)
+
+	provider.Invalidate()
+
+	conf, err := provider.GetConfiguration(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, conf.ScopesSupported)
+	assert.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,140 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultDevicePollInterval is the polling interval if the provider does not set one.
+	defaultDevicePollInterval = 5
+	// slowDownIncrease is the increase of the polling interval on a slow_down error.
+	slowDownIncrease = 5
+)
+
+// withDevicePollIntervalUnit sets the unit of the polling intervals and lifetimes of the
+// device authorization grant, which are seconds as of RFC 8628. It lets tests poll faster.
+func withDevicePollIntervalUnit(unit time.Duration) ProviderOption {
+	return func(provider *Provider) {
+		provider.devicePollIntervalUnit = unit
+	}
+}
+
+// DeviceAuthorization is the response of the device authorization endpoint. The user is
+// asked to enter the user code at the verification URI, while the device polls the token
+// endpoint with PollDeviceToken.
+// See https://datatracker.ietf.org/doc/html/rfc8628#section-3.2 for details.
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	// ExpiresIn is the lifetime of the codes in seconds.
+	ExpiresIn int64 `json:"expires_in"`
+	// Interval is the minimum number of seconds between two token requests.
+	Interval int64 `json:"interval,omitempty"`
+}
+
+// StartDeviceAuthorization starts the device authorization grant of RFC 8628, e.g. for
+// CLI tools without a browser. The client and the scopes of the request are used, its
+// grant type is ignored.
+func (p *Provider) StartDeviceAuthorization(ctx context.Context, tokenRequest TokenRequest) (DeviceAuthorization, error) {
+	cfg, err := p.GetConfiguration(ctx)
+	if err != nil {
+		return DeviceAuthorization{}, errors.Join(ErrCouldNotGetWellKnownConfig, err)
+	}
+
+	if cfg.DeviceAuthorizationEndpoint == "" {
+		return DeviceAuthorization{}, ErrNoDeviceEndpoint
+	}
+
+	form := make(url.Values, len(tokenRequest.Parameters)+1)
+	for k, v := range tokenRequest.Parameters {
+		form.Set(k, v)
+	}
+
+	if len(tokenRequest.Scopes) > 0 {
+		form.Set("scope", strings.Join(tokenRequest.Scopes, " "))
+	}
+
+	statusCode, responseBody, err := p.postClientForm(ctx, cfg.DeviceAuthorizationEndpoint, form, tokenRequest,
+		cfg.TokenEndpointAuthMethodsSupported)
+	if err != nil {
+		return DeviceAuthorization{}, err
+	}
+
+	if statusCode != http.StatusOK {
+		return DeviceAuthorization{}, newTokenError(statusCode, responseBody)
+	}
+
+	var authorization DeviceAuthorization
+
+	err = json.Unmarshal(responseBody, &authorization)
+	if err != nil {
+		return DeviceAuthorization{}, CouldNotUnmarshallResponseError{
+			Err:  err,
+			Body: string(responseBody),
+		}
+	}
+
+	return authorization, nil
+}
+
+// PollDeviceToken polls the token endpoint until the user authorized the device, and
+// returns the tokens. It waits the interval of the authorization between two requests
+// and increases it if the provider asks to slow down. It returns the TokenError if the
+// user denied the authorization, and ErrDeviceCodeExpired once the codes expired.
+// The client of the request is used, its grant type is ignored.
+func (p *Provider) PollDeviceToken(ctx context.Context, tokenRequest TokenRequest, authorization DeviceAuthorization) (TokenResponse, error) {
+	tokenRequest.GrantType = GrantTypeDeviceCode
+	tokenRequest.DeviceCode = authorization.DeviceCode
+
+	interval := authorization.Interval
+	if interval <= 0 {
+		interval = defaultDevicePollInterval
+	}
+
+	var expired <-chan time.Time
+
+	if authorization.ExpiresIn > 0 {
+		timer := time.NewTimer(time.Duration(authorization.ExpiresIn) * p.devicePollIntervalUnit)
+		defer timer.Stop()
+
+		expired = timer.C
+	}
+
+	for {
+		timer := time.NewTimer(time.Duration(interval) * p.devicePollIntervalUnit)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return TokenResponse{}, ctx.Err()
+		case <-expired:
+			timer.Stop()
+			return TokenResponse{}, ErrDeviceCodeExpired
+		case <-timer.C:
+		}
+
+		tokens, err := p.RequestToken(ctx, tokenRequest)
+
+		var tokenErr TokenError
+		if !errors.As(err, &tokenErr) {
+			return tokens, err
+		}
+
+		switch tokenErr.ErrorCode {
+		case "authorization_pending":
+		case "slow_down":
+			interval += slowDownIncrease
+		case "expired_token":
+			return TokenResponse{}, errors.Join(ErrDeviceCodeExpired, err)
+		default:
+			return TokenResponse{}, err
+		}
+	}
+}
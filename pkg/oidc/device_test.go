@@ -0,0 +1,118 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var deviceTestConfiguration = Configuration{TokenEndpoint: "/token", DeviceAuthorizationEndpoint: "/device"}
+
+func TestStartDeviceAuthorization(t *testing.T) {
+	authorization := DeviceAuthorization{
+		DeviceCode:      "device-code",
+		UserCode:        "ABCD-EFGH",
+		VerificationURI: "https://issuer/device",
+		ExpiresIn:       600,
+		Interval:        5,
+	}
+
+	provider := newTestProvider(t, deviceTestConfiguration, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/device", r.URL.Path)
+		assert.Equal(t, "cli", r.PostFormValue("client_id"))
+		assert.Equal(t, "openid offline_access", r.PostFormValue("scope"))
+
+		assert.NoError(t, json.NewEncoder(w).Encode(authorization))
+	})
+
+	got, err := provider.StartDeviceAuthorization(t.Context(), TokenRequest{
+		ClientID: "cli",
+		Scopes:   []string{"openid", "offline_access"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, authorization, got)
+
+	provider.config.DeviceAuthorizationEndpoint = ""
+
+	_, err = provider.StartDeviceAuthorization(t.Context(), TokenRequest{ClientID: "cli"})
+	assert.ErrorIs(t, err, ErrNoDeviceEndpoint)
+}
+
+func TestPollDeviceToken(t *testing.T) {
+	writeTokenError := func(w http.ResponseWriter, code string) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"` + code + `"}`))
+	}
+
+	t.Run("polls until the device is authorized", func(t *testing.T) {
+		var (
+			calls    atomic.Int32
+			lastPoll time.Time
+		)
+
+		provider := newTestProvider(t, deviceTestConfiguration, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, string(GrantTypeDeviceCode), r.PostFormValue("grant_type"))
+			assert.Equal(t, "device-code", r.PostFormValue("device_code"))
+			assert.Equal(t, "cli", r.PostFormValue("client_id"))
+
+			now := time.Now()
+
+			switch calls.Add(1) {
+			case 1:
+				writeTokenError(w, "authorization_pending")
+			case 2:
+				writeTokenError(w, "slow_down")
+			default:
+				// the interval is increased by 5 units after slow_down
+				assert.GreaterOrEqual(t, now.Sub(lastPoll), 6*time.Millisecond)
+				assert.NoError(t, json.NewEncoder(w).Encode(TokenResponse{AccessToken: "access"}))
+			}
+
+			lastPoll = now
+		}, withDevicePollIntervalUnit(time.Millisecond))
+
+		tokens, err := provider.PollDeviceToken(t.Context(), TokenRequest{ClientID: "cli"},
+			DeviceAuthorization{DeviceCode: "device-code", ExpiresIn: 1000, Interval: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "access", tokens.AccessToken)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("returns the denial of the user", func(t *testing.T) {
+		provider := newTestProvider(t, deviceTestConfiguration, func(w http.ResponseWriter, _ *http.Request) {
+			writeTokenError(w, "access_denied")
+		}, withDevicePollIntervalUnit(time.Millisecond))
+
+		_, err := provider.PollDeviceToken(t.Context(), TokenRequest{ClientID: "cli"},
+			DeviceAuthorization{DeviceCode: "device-code", Interval: 1})
+
+		var tokenErr TokenError
+		require.ErrorAs(t, err, &tokenErr)
+		assert.Equal(t, "access_denied", tokenErr.ErrorCode)
+	})
+
+	t.Run("fails once the device code expired", func(t *testing.T) {
+		provider := newTestProvider(t, deviceTestConfiguration, func(w http.ResponseWriter, _ *http.Request) {
+			writeTokenError(w, "authorization_pending")
+		}, withDevicePollIntervalUnit(time.Millisecond))
+
+		_, err := provider.PollDeviceToken(t.Context(), TokenRequest{ClientID: "cli"},
+			DeviceAuthorization{DeviceCode: "device-code", ExpiresIn: 20, Interval: 1})
+		assert.ErrorIs(t, err, ErrDeviceCodeExpired)
+	})
+
+	t.Run("fails on an expired token error", func(t *testing.T) {
+		provider := newTestProvider(t, deviceTestConfiguration, func(w http.ResponseWriter, _ *http.Request) {
+			writeTokenError(w, "expired_token")
+		}, withDevicePollIntervalUnit(time.Millisecond))
+
+		_, err := provider.PollDeviceToken(t.Context(), TokenRequest{ClientID: "cli"},
+			DeviceAuthorization{DeviceCode: "device-code", Interval: 1})
+		assert.ErrorIs(t, err, ErrDeviceCodeExpired)
+	})
+}
@@ -0,0 +1,98 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// EndSessionRequest is a request to the end session endpoint of the OpenID Provider,
+// logging out the user at the provider.
+// See https://openid.net/specs/openid-connect-rpinitiated-1_0.html for details.
+type EndSessionRequest struct {
+	// IDTokenHint is the ID token of the session, it identifies the user and the client.
+	IDTokenHint string
+	// ClientID identifies the client if no ID token hint is sent.
+	ClientID string
+	// PostLogoutRedirectURI is where the user agent is redirected to after the logout. It
+	// must be registered for the client at the provider.
+	PostLogoutRedirectURI string
+	// State is returned to the post logout redirect URI.
+	State string
+
+	// Parameters are additional parameters of the request, e.g. ui_locales or logout_hint.
+	Parameters map[string]string
+}
+
+// EndSessionURL returns the URL of the end session endpoint the user agent is redirected
+// to, to log out the user at the provider.
+func (p *Provider) EndSessionURL(ctx context.Context, r EndSessionRequest) (string, error) {
+	cfg, err := p.GetConfiguration(ctx)
+	if err != nil {
+		return "", errors.Join(ErrCouldNotGetWellKnownConfig, err)
+	}
+
+	if cfg.EndSessionEndpoint == "" {
+		return "", ErrNoEndSessionEndpoint
+	}
+
+	if r.PostLogoutRedirectURI != "" {
+		err = p.validatePostLogoutRedirectURI(r)
+		if err != nil {
+			return "", err
+		}
+	} else if r.State != "" {
+		return "", fmt.Errorf("%w: state requires a post_logout_redirect_uri", ErrInvalidEndSessionReq)
+	}
+
+	u, err := url.Parse(cfg.EndSessionEndpoint)
+	if err != nil {
+		return "", errors.Join(ErrCouldNotBuildURL, err)
+	}
+
+	query := u.Query()
+	for k, v := range r.Parameters {
+		query.Set(k, v)
+	}
+
+	for param, value := range map[string]string{
+		"id_token_hint":            r.IDTokenHint,
+		"client_id":                r.ClientID,
+		"post_logout_redirect_uri": r.PostLogoutRedirectURI,
+		"state":                    r.State,
+	} {
+		if value != "" {
+			query.Set(param, value)
+		}
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// validatePostLogoutRedirectURI checks that the redirect URI is an absolute HTTPS URI,
+// unless HTTP is allowed, and that the provider can identify the client to check that
+// the URI is registered for it.
+func (p *Provider) validatePostLogoutRedirectURI(r EndSessionRequest) error {
+	if r.IDTokenHint == "" && r.ClientID == "" {
+		return fmt.Errorf("%w: post_logout_redirect_uri requires an id_token_hint or a client_id", ErrInvalidEndSessionReq)
+	}
+
+	u, err := url.Parse(r.PostLogoutRedirectURI)
+	if err != nil {
+		return errors.Join(ErrInvalidEndSessionReq, err)
+	}
+
+	if u.Host == "" || u.Fragment != "" {
+		return fmt.Errorf("%w: post_logout_redirect_uri must be absolute without fragment", ErrInvalidEndSessionReq)
+	}
+
+	if u.Scheme != "https" && (!p.allowHttpScheme || u.Scheme != "http") {
+		return fmt.Errorf("%w: %w: post_logout_redirect_uri %s", ErrInvalidEndSessionReq, ErrInvalidURLScheme,
+			r.PostLogoutRedirectURI)
+	}
+
+	return nil
+}
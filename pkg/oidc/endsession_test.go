@@ -0,0 +1,86 @@
+package oidc
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var endSessionTestConfiguration = Configuration{EndSessionEndpoint: "https://issuer/logout?tenant=t1"}
+
+func TestEndSessionURL(t *testing.T) {
+	t.Run("builds the URL of the end session endpoint", func(t *testing.T) {
+		provider := newTestProvider(t, endSessionTestConfiguration, nil)
+
+		rawURL, err := provider.EndSessionURL(t.Context(), EndSessionRequest{
+			IDTokenHint:           "id-token",
+			PostLogoutRedirectURI: "https://app/logged-out",
+			State:                 "state",
+			Parameters:            map[string]string{"ui_locales": "de"},
+		})
+		require.NoError(t, err)
+
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/logout", u.Path)
+		assert.Equal(t, url.Values{
+			"tenant":                   {"t1"},
+			"ui_locales":               {"de"},
+			"id_token_hint":            {"id-token"},
+			"post_logout_redirect_uri": {"https://app/logged-out"},
+			"state":                    {"state"},
+		}, u.Query())
+	})
+
+	t.Run("omits empty parameters", func(t *testing.T) {
+		provider := newTestProvider(t, endSessionTestConfiguration, nil)
+
+		rawURL, err := provider.EndSessionURL(t.Context(), EndSessionRequest{ClientID: "client"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://issuer/logout?client_id=client&tenant=t1", rawURL)
+	})
+
+	tests := []struct {
+		name    string
+		request EndSessionRequest
+		wantErr error
+	}{
+		{
+			name:    "redirect without client",
+			request: EndSessionRequest{PostLogoutRedirectURI: "https://app/logged-out"},
+			wantErr: ErrInvalidEndSessionReq,
+		},
+		{
+			name:    "relative redirect",
+			request: EndSessionRequest{ClientID: "client", PostLogoutRedirectURI: "/logged-out"},
+			wantErr: ErrInvalidEndSessionReq,
+		},
+		{
+			name:    "HTTP redirect",
+			request: EndSessionRequest{ClientID: "client", PostLogoutRedirectURI: "http://app/logged-out"},
+			wantErr: ErrInvalidURLScheme,
+		},
+		{
+			name:    "state without redirect",
+			request: EndSessionRequest{ClientID: "client", State: "state"},
+			wantErr: ErrInvalidEndSessionReq,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestProvider(t, endSessionTestConfiguration, nil).EndSessionURL(t.Context(), tt.request)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("fails without end session endpoint", func(t *testing.T) {
+		provider := newTestProvider(t, endSessionTestConfiguration, nil)
+		provider.config.EndSessionEndpoint = ""
+
+		_, err := provider.EndSessionURL(t.Context(), EndSessionRequest{ClientID: "client"})
+		assert.ErrorIs(t, err, ErrNoEndSessionEndpoint)
+	})
+}
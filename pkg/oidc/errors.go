@@ -1,6 +1,7 @@
 package oidc
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 )
@@ -15,6 +16,27 @@ var (
 	ErrCouldNotReadResponseBody   = errors.New("could not read response body")
 	ErrNoIntrospectionEndpoint    = errors.New("no introspection endpoint in configuration")
 	ErrTokenIntrospectionDisabled = errors.New("token introspection is disabled")
+	ErrIssuerMismatch             = errors.New("issuer of the OpenID configuration does not match the configured issuer")
+	ErrEndpointOriginMismatch     = errors.New("endpoint does not share the origin of the issuer")
+	ErrNoTokenEndpoint            = errors.New("no token endpoint in configuration")
+	ErrUnsupportedGrantType       = errors.New("unsupported grant type")
+	ErrUnsupportedAuthMethod      = errors.New("unsupported client authentication method")
+	ErrMissingTokenRequestParam   = errors.New("missing token request parameter")
+	ErrUnsupportedKeyType         = errors.New("unsupported key type")
+	ErrInvalidIDToken             = errors.New("invalid ID token")
+	ErrNonceMismatch              = errors.New("nonce of the ID token does not match")
+	ErrAuthorizedPartyMismatch    = errors.New("authorized party of the ID token does not match the audience")
+	ErrNoExpectedAudience         = errors.New("no expected audience to verify the ID token against")
+	ErrUnknownIssuer              = errors.New("unknown issuer")
+	ErrInvalidIntrospectionAuth   = errors.New("invalid introspection client authentication")
+	ErrNoAuthorizationEndpoint    = errors.New("no authorization endpoint in configuration")
+	ErrInvalidAuthorizationReq    = errors.New("invalid authorization request")
+	ErrStateMismatch              = errors.New("state of the callback does not match the authorization request")
+	ErrMissingAuthorizationCode   = errors.New("missing authorization code in the callback")
+	ErrNoDeviceEndpoint           = errors.New("no device authorization endpoint in configuration")
+	ErrDeviceCodeExpired          = errors.New("device code expired")
+	ErrNoEndSessionEndpoint       = errors.New("no end session endpoint in configuration")
+	ErrInvalidEndSessionReq       = errors.New("invalid end session request")
 )
 
 type ProviderRespondedNon200Error struct {
@@ -42,3 +64,52 @@ type CouldNotFindKeyForKeyIDError struct {
 func (e CouldNotFindKeyForKeyIDError) Error() string {
 	return "could not find key for key ID: " + e.KeyID
 }
+
+// TokenError is the error response of the token endpoint.
+// See https://datatracker.ietf.org/doc/html/rfc6749#section-5.2 for details.
+type TokenError struct {
+	StatusCode       int
+	ErrorCode        string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+	Body             string
+}
+
+func newTokenError(statusCode int, body []byte) TokenError {
+	tokenErr := TokenError{}
+
+	// the body of gateways and proxies may not be a token error response
+	_ = json.Unmarshal(body, &tokenErr)
+
+	tokenErr.StatusCode = statusCode
+	tokenErr.Body = string(body)
+
+	return tokenErr
+}
+
+func (e TokenError) Error() string {
+	if e.ErrorCode == "" {
+		return fmt.Sprintf("token endpoint responded with status code: %d", e.StatusCode)
+	}
+
+	if e.ErrorDescription == "" {
+		return fmt.Sprintf("token endpoint responded with status code %d: %s", e.StatusCode, e.ErrorCode)
+	}
+
+	return fmt.Sprintf("token endpoint responded with status code %d: %s: %s", e.StatusCode, e.ErrorCode, e.ErrorDescription)
+}
+
+// AuthorizationError is the error response of the authorization endpoint, sent to the
+// redirect URI, e.g. if the user denied the request.
+// See https://datatracker.ietf.org/doc/html/rfc6749#section-4.1.2.1 for details.
+type AuthorizationError struct {
+	ErrorCode        string
+	ErrorDescription string
+}
+
+func (e AuthorizationError) Error() string {
+	if e.ErrorDescription == "" {
+		return "authorization endpoint responded with error: " + e.ErrorCode
+	}
+
+	return fmt.Sprintf("authorization endpoint responded with error: %s: %s", e.ErrorCode, e.ErrorDescription)
+}
@@ -0,0 +1,158 @@
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// defaultIDTokenSigningAlgs are the signing algorithms of ID tokens if the provider does
+// not publish them, see https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata.
+var defaultIDTokenSigningAlgs = []string{"RS256"}
+
+// IDTokenClaims are the verified claims of an ID token.
+// See https://openid.net/specs/openid-connect-core-1_0.html#IDToken for details.
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+
+	Nonce           string           `json:"nonce,omitempty"`
+	AuthTime        *jwt.NumericDate `json:"auth_time,omitempty"`
+	AuthorizedParty string           `json:"azp,omitempty"`
+
+	// Claims holds all claims of the token, including the provider specific ones,
+	// e.g. email or groups.
+	Claims map[string]any `json:"-"`
+}
+
+// IDTokenOption configures the verification of an ID token.
+type IDTokenOption func(*idTokenVerification)
+
+type idTokenVerification struct {
+	nonce     string
+	clockSkew time.Duration
+	now       func() time.Time
+}
+
+// WithNonce requires the nonce claim of the ID token to equal the nonce of the authentication request.
+func WithNonce(nonce string) IDTokenOption {
+	return func(v *idTokenVerification) {
+		v.nonce = nonce
+	}
+}
+
+// WithClockSkew sets the tolerated clock skew of the exp, nbf and iat claims.
+func WithClockSkew(skew time.Duration) IDTokenOption {
+	return func(v *idTokenVerification) {
+		v.clockSkew = skew
+	}
+}
+
+// WithTimeFunc sets the function returning the current time, e.g. to verify tokens in tests.
+func WithTimeFunc(now func() time.Time) IDTokenOption {
+	return func(v *idTokenVerification) {
+		v.now = now
+	}
+}
+
+// VerifyIDToken verifies the ID token issued by the OpenID Provider and returns its claims.
+// The token must be signed with one of the id_token_signing_alg_values_supported by a key of
+// the JWKS of the provider, which is fetched and cached by GetSigningKey. The iss claim must
+// equal the issuer of the provider, the aud claim must contain the expected audience, e.g.
+// the client ID, or one of the audiences of the provider if it is empty, and the token must
+// not be expired or used before its nbf time. It returns ErrNoExpectedAudience if neither
+// an expected audience nor audiences of the provider are set, as the token could have been
+// issued to any client of the provider.
+func (p *Provider) VerifyIDToken(
+	ctx context.Context, rawToken, expectedAudience string, opts ...IDTokenOption,
+) (IDTokenClaims, error) {
+	verification := idTokenVerification{now: time.Now}
+	for _, opt := range opts {
+		opt(&verification)
+	}
+
+	algs := defaultIDTokenSigningAlgs
+
+	cfg, err := p.GetConfiguration(ctx)
+	if err == nil && len(cfg.IDTokenSigningAlgValuesSupported) > 0 {
+		algs = cfg.IDTokenSigningAlgValuesSupported
+	}
+
+	audiences := p.audiences
+	if expectedAudience != "" {
+		audiences = []string{expectedAudience}
+	}
+
+	if len(audiences) == 0 {
+		return IDTokenClaims{}, errors.Join(ErrInvalidIDToken, ErrNoExpectedAudience)
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(algs),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(verification.clockSkew),
+		jwt.WithTimeFunc(verification.now),
+		jwt.WithAudience(audiences...),
+	}
+
+	var claims IDTokenClaims
+
+	_, err = jwt.ParseWithClaims(rawToken, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		key, err := p.GetSigningKey(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		return key.Key, nil
+	}, parserOpts...)
+	if err != nil {
+		return IDTokenClaims{}, errors.Join(ErrInvalidIDToken, err)
+	}
+
+	if verification.nonce != "" && claims.Nonce != verification.nonce {
+		return IDTokenClaims{}, errors.Join(ErrInvalidIDToken, ErrNonceMismatch)
+	}
+
+	if claims.AuthorizedParty != "" && expectedAudience != "" && claims.AuthorizedParty != expectedAudience {
+		return IDTokenClaims{}, errors.Join(ErrInvalidIDToken,
+			fmt.Errorf("%w: %s", ErrAuthorizedPartyMismatch, claims.AuthorizedParty))
+	}
+
+	claims.Claims, err = payloadClaims(rawToken)
+	if err != nil {
+		return IDTokenClaims{}, errors.Join(ErrInvalidIDToken, err)
+	}
+
+	return claims, nil
+}
+
+// payloadClaims decodes all claims of the verified token.
+func payloadClaims(rawToken string) (map[string]any, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, jwt.ErrTokenMalformed
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+
+	err = json.Unmarshal(payload, &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,228 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// idTokenTestIssuer serves the JWKS of its signing keys and issues ID tokens.
+type idTokenTestIssuer struct {
+	server   *httptest.Server
+	keys     map[string]*rsa.PrivateKey
+	fetches  atomic.Int32
+	provider *Provider
+}
+
+func newIDTokenTestIssuer(t *testing.T, kids ...string) *idTokenTestIssuer {
+	t.Helper()
+
+	issuer := &idTokenTestIssuer{keys: make(map[string]*rsa.PrivateKey)}
+	for _, kid := range kids {
+		issuer.addKey(t, kid)
+	}
+
+	issuer.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		issuer.fetches.Add(1)
+
+		var jwks jose.JSONWebKeySet
+		for kid, key := range issuer.keys {
+			jwks.Keys = append(jwks.Keys, jose.JSONWebKey{Key: &key.PublicKey, KeyID: kid, Algorithm: "RS256", Use: "sig"})
+		}
+
+		assert.NoError(t, json.NewEncoder(w).Encode(jwks))
+	}))
+	t.Cleanup(issuer.server.Close)
+
+	provider, err := NewProvider(issuer.server.URL, []string{"client"}, WithAllowHttpScheme(true))
+	require.NoError(t, err)
+
+	provider.config = &Configuration{Issuer: issuer.server.URL, JwksURI: issuer.server.URL + "/jwks"}
+	issuer.provider = provider
+
+	return issuer
+}
+
+func (i *idTokenTestIssuer) addKey(t *testing.T, kid string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	i.keys[kid] = key
+}
+
+func (i *idTokenTestIssuer) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(i.keys[kid])
+	require.NoError(t, err)
+
+	return signed
+}
+
+func (i *idTokenTestIssuer) claims() jwt.MapClaims {
+	now := time.Now()
+
+	return jwt.MapClaims{
+		"iss":   i.server.URL,
+		"sub":   "user",
+		"aud":   "client",
+		"exp":   now.Add(time.Hour).Unix(),
+		"iat":   now.Unix(),
+		"nonce": "nonce",
+		"email": "user@example.com",
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer := newIDTokenTestIssuer(t, "key-1")
+
+	t.Run("returns the claims of a valid token", func(t *testing.T) {
+		token := issuer.sign(t, "key-1", issuer.claims())
+
+		claims, err := issuer.provider.VerifyIDToken(t.Context(), token, "client", WithNonce("nonce"))
+		require.NoError(t, err)
+		assert.Equal(t, "user", claims.Subject)
+		assert.Equal(t, "nonce", claims.Nonce)
+		assert.Equal(t, "user@example.com", claims.Claims["email"])
+	})
+
+	t.Run("uses the audiences of the provider", func(t *testing.T) {
+		token := issuer.sign(t, "key-1", issuer.claims())
+
+		_, err := issuer.provider.VerifyIDToken(t.Context(), token, "")
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects tokens without an expected audience", func(t *testing.T) {
+		provider, err := NewProvider(issuer.server.URL, nil, WithAllowHttpScheme(true))
+		require.NoError(t, err)
+
+		provider.config = issuer.provider.config
+
+		_, err = provider.VerifyIDToken(t.Context(), issuer.sign(t, "key-1", issuer.claims()), "")
+		require.ErrorIs(t, err, ErrInvalidIDToken)
+		assert.ErrorIs(t, err, ErrNoExpectedAudience)
+	})
+
+	tests := []struct {
+		name     string
+		modify   func(jwt.MapClaims)
+		audience string
+		opts     []IDTokenOption
+		wantErr  error
+	}{
+		{
+			name:     "wrong audience",
+			audience: "other-client",
+			wantErr:  jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name:    "wrong issuer",
+			modify:  func(c jwt.MapClaims) { c["iss"] = "https://attacker" },
+			wantErr: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name:    "expired token",
+			modify:  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name:   "expired token within the clock skew",
+			modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+			opts:   []IDTokenOption{WithClockSkew(2 * time.Minute)},
+		},
+		{
+			name:    "missing expiry",
+			modify:  func(c jwt.MapClaims) { delete(c, "exp") },
+			wantErr: jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name:    "token used before nbf",
+			modify:  func(c jwt.MapClaims) { c["nbf"] = time.Now().Add(time.Hour).Unix() },
+			wantErr: jwt.ErrTokenNotValidYet,
+		},
+		{
+			name:    "wrong nonce",
+			opts:    []IDTokenOption{WithNonce("other-nonce")},
+			wantErr: ErrNonceMismatch,
+		},
+		{
+			name:    "wrong authorized party",
+			modify:  func(c jwt.MapClaims) { c["azp"] = "other-client" },
+			wantErr: ErrAuthorizedPartyMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.claims()
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+
+			audience := tt.audience
+			if audience == "" {
+				audience = "client"
+			}
+
+			_, err := issuer.provider.VerifyIDToken(t.Context(), issuer.sign(t, "key-1", claims), audience, tt.opts...)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidIDToken)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("rejects tokens signed with an unsupported algorithm", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, issuer.claims())
+		token.Header["kid"] = "key-1"
+
+		signed, err := token.SignedString([]byte("secret"))
+		require.NoError(t, err)
+
+		_, err = issuer.provider.VerifyIDToken(t.Context(), signed, "client")
+		assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	})
+
+	t.Run("rejects tokens with a wrong signature", func(t *testing.T) {
+		token := issuer.sign(t, "key-1", issuer.claims())
+
+		_, err := issuer.provider.VerifyIDToken(t.Context(), token[:len(token)-4]+"AAAA", "client")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+}
+
+func TestVerifyIDTokenKeyRotation(t *testing.T) {
+	issuer := newIDTokenTestIssuer(t, "key-1")
+
+	for range 2 {
+		_, err := issuer.provider.VerifyIDToken(t.Context(), issuer.sign(t, "key-1", issuer.claims()), "client")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), issuer.fetches.Load(), "expected the keys to be cached")
+
+	issuer.addKey(t, "key-2")
+
+	_, err := issuer.provider.VerifyIDToken(t.Context(), issuer.sign(t, "key-2", issuer.claims()), "client")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), issuer.fetches.Load(), "expected the keys to be fetched for the new key")
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 const (
@@ -16,16 +18,34 @@ const (
 )
 
 // Introspection represents the response from an introspection request.
+// See https://datatracker.ietf.org/doc/html/rfc7662#section-2.2 for details.
 type Introspection struct {
 	Active bool     `json:"active"`
 	Groups []string `json:"groups,omitempty"`
 
+	Scope     string           `json:"scope,omitempty"`
+	ClientID  string           `json:"client_id,omitempty"`
+	Username  string           `json:"username,omitempty"`
+	TokenType string           `json:"token_type,omitempty"`
+	Subject   string           `json:"sub,omitempty"`
+	Audience  jwt.ClaimStrings `json:"aud,omitempty"`
+	Issuer    string           `json:"iss,omitempty"`
+	ExpiresAt *jwt.NumericDate `json:"exp,omitempty"`
+	IssuedAt  *jwt.NumericDate `json:"iat,omitempty"`
+	NotBefore *jwt.NumericDate `json:"nbf,omitempty"`
+	JWTID     string           `json:"jti,omitempty"`
+
+	// Claims holds all members of the response, including the provider specific extensions.
+	Claims map[string]any `json:"-"`
+
 	// Error response fields e.g. bad credentials
 	Error            string `json:"error,omitempty"`
 	ErrorDescription string `json:"error_description,omitempty"`
 }
 
 // IntrospectToken introspects the given token using the OpenID Provider's introspection endpoint.
+// The token is sent form encoded in the request body, and the provider authenticates as
+// configured with WithIntrospectionAuth.
 func (p *Provider) IntrospectToken(ctx context.Context, token string) (Introspection, error) {
 	if p.disableTokenIntrospection {
 		return Introspection{}, ErrTokenIntrospectionDisabled
@@ -47,10 +67,20 @@ func (p *Provider) IntrospectToken(ctx context.Context, token string) (Introspec
 		requestBody.Set(k, v)
 	}
 
+	endpoint := cfg.IntrospectionEndpoint
+	if p.introspectQueryEncoding {
+		endpoint, err = withQuery(endpoint, requestBody)
+		if err != nil {
+			return Introspection{}, errors.Join(ErrCouldNotBuildURL, err)
+		}
+
+		requestBody = nil
+	}
+
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
-		cfg.IntrospectionEndpoint,
+		endpoint,
 		strings.NewReader(requestBody.Encode()),
 	)
 	if err != nil {
@@ -60,7 +90,7 @@ func (p *Provider) IntrospectToken(ctx context.Context, token string) (Introspec
 	req.Header.Set("Content-Type", urlencoded)
 	req.Header.Set("Accept", applicationJSON)
 
-	resp, err := p.secureHttpClient.Do(req)
+	resp, err := p.doWithRetry(p.introspectionHttpClient, req)
 	if err != nil {
 		return Introspection{}, errors.Join(ErrCouldNotDoHTTPRequest, err)
 	}
@@ -88,5 +118,35 @@ func (p *Provider) IntrospectToken(ctx context.Context, token string) (Introspec
 		}
 	}
 
+	err = json.Unmarshal(responseBody, &intr.Claims)
+	if err != nil {
+		return Introspection{}, CouldNotUnmarshallResponseError{
+			Err:  err,
+			Body: string(responseBody),
+		}
+	}
+
 	return intr, nil
 }
+
+// Scopes returns the space separated scopes of the token.
+func (i Introspection) Scopes() []string {
+	return strings.Fields(i.Scope)
+}
+
+// withQuery adds the values to the query of the URL.
+func withQuery(rawURL string, values url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	for k, v := range values {
+		query[k] = v
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
@@ -6,9 +6,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
 )
 
 func TestIntrospectToken(t *testing.T) {
@@ -54,6 +58,7 @@ func TestIntrospectToken(t *testing.T) {
 				assert.Equal(t, "test-token", r.PostFormValue("token"))
 				assert.Equal(t, "value1", r.PostFormValue("key1"))
 				assert.Equal(t, "value2", r.PostFormValue("key2"))
+				assert.Empty(t, r.URL.RawQuery, "expected no token in the URL")
 
 				w.Header().Set("Content-Type", "application/json")
 				err := json.NewEncoder(w).Encode(Introspection{Active: true})
@@ -82,6 +87,37 @@ func TestIntrospectToken(t *testing.T) {
 		assert.True(t, result.Active)
 	})
 
+	t.Run("sends the token in the query with the legacy encoding", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "test-token", r.URL.Query().Get("token"))
+			assert.Equal(t, "value1", r.URL.Query().Get("key1"))
+			assert.Equal(t, "tenant", r.URL.Query().Get("realm"))
+
+			err := r.ParseForm()
+			assert.NoError(t, err)
+			assert.Empty(t, r.PostForm)
+
+			err = json.NewEncoder(w).Encode(Introspection{Active: true})
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+
+		provider, err := NewProvider(server.URL, []string{"aud1"},
+			WithAllowHttpScheme(true),
+			WithIntrospectQueryEncoding(true),
+			WithIntrospectQueryParameters(map[string]string{"key1": "value1"}))
+		require.NoError(t, err)
+
+		provider.config = &Configuration{
+			IntrospectionEndpoint: server.URL + "/introspect?realm=tenant",
+		}
+
+		result, err := provider.IntrospectToken(context.Background(), "test-token")
+		require.NoError(t, err)
+		assert.True(t, result.Active)
+	})
+
 	t.Run("fails when no introspection endpoint", func(t *testing.T) {
 		provider, err := NewProvider("https://issuer.example.com", []string{"aud1"})
 		require.NoError(t, err)
@@ -248,3 +284,136 @@ func TestIntrospectToken(t *testing.T) {
 		assert.ErrorIs(t, err, ErrTokenIntrospectionDisabled)
 	})
 }
+
+func TestIntrospectTokenResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"active": true,
+			"scope": "read write",
+			"client_id": "client",
+			"username": "jdoe",
+			"token_type": "Bearer",
+			"sub": "user",
+			"aud": "api",
+			"iss": "https://issuer",
+			"exp": 1700003600,
+			"iat": 1700000000,
+			"nbf": 1700000000,
+			"jti": "token-id",
+			"tenant": "tenant1"
+		}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(server.URL, []string{"aud1"}, WithAllowHttpScheme(true))
+	require.NoError(t, err)
+
+	provider.config = &Configuration{IntrospectionEndpoint: server.URL + "/introspect"}
+
+	result, err := provider.IntrospectToken(t.Context(), "test-token")
+	require.NoError(t, err)
+
+	assert.True(t, result.Active)
+	assert.Equal(t, []string{"read", "write"}, result.Scopes())
+	assert.Equal(t, "client", result.ClientID)
+	assert.Equal(t, "jdoe", result.Username)
+	assert.Equal(t, "Bearer", result.TokenType)
+	assert.Equal(t, "user", result.Subject)
+	assert.Equal(t, jwt.ClaimStrings{"api"}, result.Audience)
+	assert.Equal(t, "https://issuer", result.Issuer)
+	assert.Equal(t, time.Unix(1700003600, 0), result.ExpiresAt.Time)
+	assert.Equal(t, time.Unix(1700000000, 0), result.IssuedAt.Time)
+	assert.Equal(t, time.Unix(1700000000, 0), result.NotBefore.Time)
+	assert.Equal(t, "token-id", result.JWTID)
+	assert.Equal(t, "tenant1", result.Claims["tenant"])
+}
+
+func TestIntrospectTokenClientAuth(t *testing.T) {
+	embedded := func(value string) commoncfg.SourceRef {
+		return commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: value}
+	}
+
+	bearer := embedded("service-token")
+	assertion := embedded("signed-assertion")
+	assertionType := embedded("urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+
+	tests := []struct {
+		name   string
+		auth   IntrospectionAuth
+		verify func(t *testing.T, r *http.Request)
+	}{
+		{
+			name: "basic auth",
+			auth: IntrospectionAuth{BasicAuth: &commoncfg.BasicAuth{Username: embedded("client"), Password: embedded("secret")}},
+			verify: func(t *testing.T, r *http.Request) {
+				t.Helper()
+
+				user, password, ok := r.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "client", user)
+				assert.Equal(t, "secret", password)
+			},
+		},
+		{
+			name: "bearer token",
+			auth: IntrospectionAuth{BearerToken: &bearer},
+			verify: func(t *testing.T, r *http.Request) {
+				t.Helper()
+
+				assert.Equal(t, "Bearer service-token", r.Header.Get("Authorization"))
+			},
+		},
+		{
+			name: "private key JWT",
+			auth: IntrospectionAuth{OAuth2: &commoncfg.OAuth2{Credentials: commoncfg.OAuth2Credentials{
+				ClientID:            embedded("client"),
+				AuthMethod:          commoncfg.OAuth2PrivateKeyJWT,
+				ClientAssertion:     &assertion,
+				ClientAssertionType: &assertionType,
+			}}},
+			verify: func(t *testing.T, r *http.Request) {
+				t.Helper()
+
+				assert.Equal(t, "client", r.PostFormValue("client_id"))
+				assert.Equal(t, "signed-assertion", r.PostFormValue("client_assertion"))
+				assert.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", r.PostFormValue("client_assertion_type"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.verify(t, r)
+				assert.Equal(t, "test-token", r.PostFormValue("token"))
+
+				assert.NoError(t, json.NewEncoder(w).Encode(Introspection{Active: true}))
+			}))
+			defer server.Close()
+
+			provider, err := NewProvider(server.URL, []string{"aud1"}, WithAllowHttpScheme(true),
+				WithIntrospectionAuth(tt.auth))
+			require.NoError(t, err)
+
+			provider.config = &Configuration{
+				Issuer:                server.URL,
+				IntrospectionEndpoint: server.URL + "/introspect",
+			}
+
+			result, err := provider.IntrospectToken(t.Context(), "test-token")
+			require.NoError(t, err)
+			assert.True(t, result.Active)
+		})
+	}
+
+	t.Run("rejects an invalid configuration", func(t *testing.T) {
+		for _, auth := range []IntrospectionAuth{
+			{},
+			{BasicAuth: &commoncfg.BasicAuth{}, BearerToken: &bearer},
+			{BearerToken: &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue}},
+		} {
+			_, err := NewProvider("https://issuer", nil, WithIntrospectionAuth(auth))
+			assert.ErrorIs(t, err, ErrInvalidIntrospectionAuth)
+		}
+	})
+}
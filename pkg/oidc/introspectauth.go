@@ -0,0 +1,84 @@
+package oidc
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commonhttp"
+)
+
+// IntrospectionAuth configures how the provider authenticates at the introspection
+// endpoint, which RFC 7662 requires. Exactly one of the methods must be set.
+type IntrospectionAuth struct {
+	// BasicAuth sends the client credentials in the Authorization header.
+	BasicAuth *commoncfg.BasicAuth
+	// BearerToken sends the token, e.g. an access token of the calling service, in the
+	// Authorization header.
+	BearerToken *commoncfg.SourceRef
+	// OAuth2 authenticates with an OAuth2 client authentication method, e.g. private_key_jwt,
+	// as the clients created by commonhttp.NewClientFromOAuth2.
+	OAuth2 *commoncfg.OAuth2
+}
+
+// newClient creates the client authenticating the introspection requests, which are
+// sent with the given transport.
+func (a *IntrospectionAuth) newClient(base http.RoundTripper) (*http.Client, error) {
+	var methods int
+
+	for _, set := range []bool{a.BasicAuth != nil, a.BearerToken != nil, a.OAuth2 != nil} {
+		if set {
+			methods++
+		}
+	}
+
+	if methods != 1 {
+		return nil, errors.Join(ErrInvalidIntrospectionAuth, errors.New("exactly one method must be set"))
+	}
+
+	if a.BearerToken != nil {
+		token, err := commoncfg.ExtractValueFromSourceRef(a.BearerToken)
+		if err != nil {
+			return nil, errors.Join(ErrInvalidIntrospectionAuth, err)
+		}
+
+		if len(token) == 0 {
+			return nil, errors.Join(ErrInvalidIntrospectionAuth, errors.New("bearer token is empty"))
+		}
+
+		return &http.Client{Transport: &bearerTokenRoundTripper{token: string(token), next: base}}, nil
+	}
+
+	var (
+		client *http.Client
+		err    error
+	)
+
+	if a.BasicAuth != nil {
+		client, err = commonhttp.NewClientFromBasic(a.BasicAuth)
+	} else {
+		client, err = commonhttp.NewClientFromOAuth2(a.OAuth2)
+	}
+
+	if err != nil {
+		return nil, errors.Join(ErrInvalidIntrospectionAuth, err)
+	}
+
+	// the credentials are sent with the transport of the secure client, e.g. its mTLS config
+	commonhttp.SetBaseTransport(client, base)
+
+	return client, nil
+}
+
+// bearerTokenRoundTripper sets the bearer token in the Authorization header of the requests.
+type bearerTokenRoundTripper struct {
+	token string
+	next  http.RoundTripper
+}
+
+func (t *bearerTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	newReq := req.Clone(req.Context())
+	newReq.Header.Set("Authorization", "Bearer "+t.token)
+
+	return t.next.RoundTrip(newReq)
+}
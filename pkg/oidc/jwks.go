@@ -2,16 +2,140 @@ package oidc
 
 import (
 	"context"
+	"crypto/rsa"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/go-jose/go-jose/v4"
+
+	"github.com/openkcm/common-sdk/pkg/jwtsigning"
 )
 
-// GetSigningKey returns the key for the given key.
+// maxMissingKeys is the maximum number of cached key IDs which were not found in the JWKS.
+const maxMissingKeys = 1000
+
+var _ jwtsigning.PublicKeyProvider = &Provider{}
+
+// GetSigningKey returns the signing key with the given key ID. The keys of the JWKS are
+// cached, the JWKS is fetched again if the key ID is unknown, e.g. after a key rotation,
+// unless the key ID was not found in the JWKS within the negative cache TTL (see
+// WithKeysNegativeCacheTTL). Concurrent calls share a single fetch of the JWKS.
 func (p *Provider) GetSigningKey(ctx context.Context, keyID string) (*jose.JSONWebKey, error) {
+	p.keysMu.RLock()
+	key, ok := p.keys[keyID]
+	missingUntil, missing := p.missingKeys[keyID]
+	p.keysMu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if missing && time.Now().Before(missingUntil) {
+		return nil, CouldNotFindKeyForKeyIDError{KeyID: keyID}
+	}
+
+	err := p.refreshKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+
+	key, ok = p.keys[keyID]
+	if !ok {
+		p.markKeyMissing(keyID)
+		return nil, CouldNotFindKeyForKeyIDError{KeyID: keyID}
+	}
+
+	return key, nil
+}
+
+// refreshKeys fetches the JWKS and replaces the cached signing keys, the keys removed from
+// the JWKS are dropped. The JWKS is fetched without holding keysMu, so the cached keys can
+// be read meanwhile. The fetch is shared by the concurrent calls and done with the context
+// of the call that started it, each call returns once its own context is done.
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	fetched := p.keyFetches.DoChan("", func() (any, error) {
+		jwks, err := p.fetchJWKS(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make(map[string]*jose.JSONWebKey, len(jwks.Keys))
+		for _, k := range jwks.Keys {
+			if k.Use == "sig" {
+				keys[k.KeyID] = &k
+			}
+		}
+
+		p.keysMu.Lock()
+		p.keys = keys
+		p.keysMu.Unlock()
+
+		return nil, nil
+	})
+
+	select {
+	case result := <-fetched:
+		return result.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// markKeyMissing remembers that the key ID was not found in the JWKS, and drops expired
+// entries. At most maxMissingKeys key IDs are remembered. The caller must hold keysMu.
+func (p *Provider) markKeyMissing(keyID string) {
+	if p.keysNegativeCacheTTL <= 0 {
+		return
+	}
+
+	now := time.Now()
+
+	if p.missingKeys == nil {
+		p.missingKeys = make(map[string]time.Time)
+	}
+
+	for missing, until := range p.missingKeys {
+		if !now.Before(until) {
+			delete(p.missingKeys, missing)
+		}
+	}
+
+	if len(p.missingKeys) >= maxMissingKeys {
+		return
+	}
+
+	p.missingKeys[keyID] = now.Add(p.keysNegativeCacheTTL)
+}
+
+// VerificationKey returns the RSA signing key with the given key ID of the provider, so
+// messages signed with the keys of the provider can be verified by a jwtsigning.Verifier.
+func (p *Provider) VerificationKey(ctx context.Context, iss, kid string) (*rsa.PublicKey, error) {
+	if iss != p.issuer {
+		return nil, fmt.Errorf("%w: expected %q, got %q", ErrIssuerMismatch, p.issuer, iss)
+	}
+
+	key, err := p.GetSigningKey(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, ok := key.Key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKeyType, kid)
+	}
+
+	return pub, nil
+}
+
+// fetchJWKS fetches the JWKS of the provider.
+func (p *Provider) fetchJWKS(ctx context.Context) (*jose.JSONWebKeySet, error) {
 	// If the provider was configured with a custom JWKS URI, use it.
 	// Otherwise get the JWKS URI from the provider's configuration.
 	jwksURI := p.customJWKSURI
@@ -57,13 +181,5 @@ func (p *Provider) GetSigningKey(ctx context.Context, keyID string) (*jose.JSONW
 		}
 	}
 
-	// find the key for the given key ID, cache it and return it
-	for _, k := range jwks.Keys {
-		if k.Use == "sig" && k.KeyID == keyID {
-			return &k, nil
-		}
-	}
-
-	// return an error if the key was not found
-	return nil, CouldNotFindKeyForKeyIDError{KeyID: keyID}
+	return &jwks, nil
 }
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/go-jose/go-jose/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -379,3 +383,90 @@ func TestGetSigningKey(t *testing.T) {
 		assert.ErrorAs(t, err, &keyErr)
 	})
 }
+
+func TestGetSigningKeyUnknownKeyID(t *testing.T) {
+	var fetches atomic.Int32
+
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		<-release
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testJWKSResponse))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(server.URL, []string{"aud1"}, WithAllowHttpScheme(true), WithKeysNegativeCacheTTL(time.Hour))
+	require.NoError(t, err)
+
+	provider.config = &Configuration{JwksURI: server.URL + "/jwks"}
+	provider.keys = map[string]*jose.JSONWebKey{"cached": {KeyID: "cached", Use: "sig"}}
+
+	var started, wg sync.WaitGroup
+
+	for range 10 {
+		started.Add(1)
+		wg.Go(func() {
+			started.Done()
+
+			_, err := provider.GetSigningKey(t.Context(), "unknown")
+
+			var keyErr CouldNotFindKeyForKeyIDError
+			assert.ErrorAs(t, err, &keyErr)
+		})
+	}
+
+	started.Wait()
+	require.Eventually(t, func() bool { return fetches.Load() == 1 }, time.Second, time.Millisecond)
+
+	// the cached keys are read while the JWKS is fetched
+	key, err := provider.GetSigningKey(t.Context(), "cached")
+	require.NoError(t, err)
+	assert.Equal(t, "cached", key.KeyID)
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), fetches.Load(), "expected the concurrent calls to share a fetch")
+
+	// the unknown key ID is not fetched again within the negative cache TTL
+	_, err = provider.GetSigningKey(t.Context(), "unknown")
+	require.Error(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// the fetched keys replaced the cached ones
+	_, err = provider.GetSigningKey(t.Context(), "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	provider.keysMu.Lock()
+	provider.missingKeys["unknown"] = time.Now().Add(-time.Second)
+	provider.keysMu.Unlock()
+
+	_, err = provider.GetSigningKey(t.Context(), "unknown")
+	require.Error(t, err)
+	assert.Equal(t, int32(2), fetches.Load(), "expected the JWKS to be fetched once the negative cache expired")
+}
+
+func TestVerificationKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testJWKSResponse))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(server.URL, []string{"aud1"}, WithAllowHttpScheme(true))
+	require.NoError(t, err)
+
+	provider.config = &Configuration{JwksURI: server.URL + "/jwks"}
+
+	key, err := provider.VerificationKey(context.Background(), server.URL, "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, 65537, key.E)
+
+	_, err = provider.VerificationKey(context.Background(), "https://other-issuer", "key-1")
+	require.ErrorIs(t, err, ErrIssuerMismatch)
+}
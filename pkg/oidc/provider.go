@@ -5,12 +5,22 @@ import (
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/openkcm/common-sdk/pkg/otlp"
 )
 
 var (
 	DefaultIssuerClaims = []string{"iss"}
 )
 
+// defaultKeysNegativeCacheTTL is the default time key IDs not found in the JWKS are cached.
+const defaultKeysNegativeCacheTTL = 5 * time.Second
+
 type Provider struct {
 	// According to RFC 7519, the issuer identifies the principal that issued the
 	// JWT, which is a case-sensitive string or URI.
@@ -29,19 +39,52 @@ type Provider struct {
 	// The audiences that are expected in the token's `aud` claim.
 	audiences []string
 
-	configMu sync.RWMutex   // guards config
-	config   *Configuration // the well known OpenID configuration
+	configMu        sync.RWMutex   // guards config and configExpiresAt
+	config          *Configuration // the well known OpenID configuration
+	configExpiresAt time.Time      // when the configuration is refreshed, if configTTL is set
+	// how long the configuration is used before it is refreshed, zero to never refresh it
+	configTTL        time.Duration
+	configRefreshing atomic.Bool
+
+	keysMu      sync.RWMutex                // guards keys and missingKeys
+	keys        map[string]*jose.JSONWebKey // the cached signing keys by key ID
+	keyFetches  singleflight.Group          // deduplicates concurrent fetches of the JWKS
+	missingKeys map[string]time.Time        // key IDs not found in the JWKS, until when they are not fetched again
+	// how long key IDs not found in the JWKS are cached, zero to fetch the JWKS on every miss
+	keysNegativeCacheTTL time.Duration
 
 	// Whether to disable token introspection.
 	disableTokenIntrospection bool
 	// Additional query parameters to be sent with the introspection request.
 	queryParametersIntrospect map[string]string
+	// Whether to send the token and parameters in the URL query instead of the body.
+	introspectQueryEncoding bool
+	// How the provider authenticates at the introspection endpoint, if at all.
+	introspectionAuth *IntrospectionAuth
 
 	// whether to allow HTTP scheme for issuer and JWKS URIs
 	allowHttpScheme bool
 
+	// whether to skip the check of the issuer of the OpenID configuration
+	skipIssuerValidation bool
+	// whether to skip the check of the token and introspection endpoint origins
+	skipEndpointOriginValidation bool
+	// origins trusted for the token and introspection endpoints besides the issuer origin
+	trustedEndpointOrigins []string
+
+	// whether to disable the telemetry of the requests to the provider
+	disableTelemetry bool
+
+	// the retries of the requests for the configuration and the introspection
+	retry RetryPolicy
+
+	// the unit of the polling intervals and lifetimes of the device authorization grant
+	devicePollIntervalUnit time.Duration
+
 	publicHttpClient *http.Client // client to be used for public endpoints
 	secureHttpClient *http.Client // client to be used for secured endpoints
+	// client to be used for the introspection endpoint, authenticated by introspectionAuth
+	introspectionHttpClient *http.Client
 }
 
 // UniqueID returns a unique identifier for the provider.
@@ -92,14 +135,58 @@ func WithCustomJWKSURI(customJWKSURI string) ProviderOption {
 	}
 }
 
-// WithAllowHttpScheme configures whether to allow HTTP scheme for URIs.
-// By default, the HTTPS scheme is enforced.
+// WithAllowHttpScheme configures whether to allow HTTP scheme for URIs, including the
+// endpoints of the OpenID configuration. By default, the HTTPS scheme is enforced;
+// allowing HTTP is meant for development setups only.
 func WithAllowHttpScheme(allowHttpScheme bool) ProviderOption {
 	return func(provider *Provider) {
 		provider.allowHttpScheme = allowHttpScheme
 	}
 }
 
+// WithSkipIssuerValidation disables the check that the issuer of the OpenID configuration
+// equals the issuer of the provider. Only use it for providers known to publish a different
+// issuer, as the check protects against IdP mix-up attacks.
+func WithSkipIssuerValidation(skip bool) ProviderOption {
+	return func(provider *Provider) {
+		provider.skipIssuerValidation = skip
+	}
+}
+
+// WithSkipEndpointOriginValidation disables the check that the token and introspection
+// endpoints of the OpenID configuration share the origin of the issuer.
+func WithSkipEndpointOriginValidation(skip bool) ProviderOption {
+	return func(provider *Provider) {
+		provider.skipEndpointOriginValidation = skip
+	}
+}
+
+// WithTrustedEndpointOrigins adds origins, e.g. https://login.example.com, the token and
+// introspection endpoints may be served from besides the origin of the issuer.
+func WithTrustedEndpointOrigins(origins ...string) ProviderOption {
+	return func(provider *Provider) {
+		provider.trustedEndpointOrigins = append(provider.trustedEndpointOrigins, origins...)
+	}
+}
+
+// WithConfigurationTTL sets how long the OpenID configuration is used before it is
+// refreshed. An expired configuration is still returned while it is refreshed in the
+// background. By default, the configuration is fetched once and never refreshed.
+func WithConfigurationTTL(ttl time.Duration) ProviderOption {
+	return func(provider *Provider) {
+		provider.configTTL = ttl
+	}
+}
+
+// WithKeysNegativeCacheTTL sets how long a key ID which is not found in the JWKS of the
+// provider is remembered, so tokens with the key ID do not fetch the JWKS again meanwhile.
+// It defaults to five seconds, zero disables the negative caching.
+func WithKeysNegativeCacheTTL(ttl time.Duration) ProviderOption {
+	return func(provider *Provider) {
+		provider.keysNegativeCacheTTL = ttl
+	}
+}
+
 // WithPublicHTTPClient let's you set the client to be used for public endpoints,
 // e.g. the well known OpenID configuration endpoint.
 func WithPublicHTTPClient(c *http.Client) ProviderOption {
@@ -124,20 +211,50 @@ func WithDisableTokenIntrospection(disableTokenIntrospection bool) ProviderOptio
 }
 
 // WithIntrospectQueryParameters let's you define addition query parameters
-// to be sent with the introspection request.
+// to be sent with the introspection request. They are sent in the form encoded body
+// along with the token, unless WithIntrospectQueryEncoding is enabled.
 func WithIntrospectQueryParameters(params map[string]string) ProviderOption {
 	return func(provider *Provider) {
 		provider.queryParametersIntrospect = params
 	}
 }
 
+// WithIntrospectQueryEncoding configures whether to send the token and the parameters of
+// the introspection request in the URL query instead of the form encoded body, for
+// providers which only support the legacy behavior. Avoid it, as the URL query of the
+// request, including the token, ends up in the access logs of proxies.
+func WithIntrospectQueryEncoding(enabled bool) ProviderOption {
+	return func(provider *Provider) {
+		provider.introspectQueryEncoding = enabled
+	}
+}
+
+// WithDisableTelemetry configures whether to disable the traces and metrics of the requests
+// to the OpenID Provider. By default, they are recorded with the global OpenTelemetry
+// providers, as the other HTTP and gRPC clients of the SDK.
+func WithDisableTelemetry(disable bool) ProviderOption {
+	return func(provider *Provider) {
+		provider.disableTelemetry = disable
+	}
+}
+
+// WithIntrospectionAuth configures how the provider authenticates at the introspection
+// endpoint. The authenticated requests are sent with the transport of the secure client.
+func WithIntrospectionAuth(auth IntrospectionAuth) ProviderOption {
+	return func(provider *Provider) {
+		provider.introspectionAuth = &auth
+	}
+}
+
 // NewProvider creates a new provider and applies the given options.
 func NewProvider(issuer string, audiences []string, opts ...ProviderOption) (*Provider, error) {
 	provider := &Provider{
-		issuer:           issuer,
-		audiences:        audiences,
-		publicHttpClient: http.DefaultClient,
-		secureHttpClient: http.DefaultClient,
+		issuer:                 issuer,
+		audiences:              audiences,
+		keysNegativeCacheTTL:   defaultKeysNegativeCacheTTL,
+		devicePollIntervalUnit: time.Second,
+		publicHttpClient:       http.DefaultClient,
+		secureHttpClient:       http.DefaultClient,
 	}
 
 	for _, opt := range opts {
@@ -169,5 +286,36 @@ func NewProvider(issuer string, audiences []string, opts ...ProviderOption) (*Pr
 		}
 	}
 
+	provider.introspectionHttpClient = provider.secureHttpClient
+
+	if provider.introspectionAuth != nil {
+		base := provider.secureHttpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+
+		client, err := provider.introspectionAuth.newClient(base)
+		if err != nil {
+			return nil, err
+		}
+
+		client.Timeout = provider.secureHttpClient.Timeout
+		provider.introspectionHttpClient = client
+	}
+
+	if !provider.disableTelemetry {
+		provider.publicHttpClient = instrumented(provider.publicHttpClient)
+		provider.secureHttpClient = instrumented(provider.secureHttpClient)
+		provider.introspectionHttpClient = instrumented(provider.introspectionHttpClient)
+	}
+
 	return provider, nil
 }
+
+// instrumented returns a copy of the client recording the telemetry of its requests.
+func instrumented(client *http.Client) *http.Client {
+	instrumentedClient := *client
+	instrumentedClient.Transport = otlp.NewTransport(client.Transport)
+
+	return &instrumentedClient
+}
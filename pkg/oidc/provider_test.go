@@ -1,13 +1,52 @@
 package oidc
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/openkcm/common-sdk/pkg/otlp/otlptest"
 )
 
+// newTestProvider creates a provider with the given OpenID configuration. Without a
+// handler the issuer is https://issuer. With a handler the issuer is a test server
+// serving it, and the endpoints of the configuration given as paths are on the server.
+func newTestProvider(t *testing.T, cfg Configuration, handler http.HandlerFunc, opts ...ProviderOption) *Provider {
+	t.Helper()
+
+	issuer := "https://issuer"
+
+	if handler != nil {
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+
+		issuer = server.URL
+		opts = append(opts, WithAllowHttpScheme(true))
+
+		for _, endpoint := range []*string{
+			&cfg.AuthorizationEndpoint, &cfg.TokenEndpoint,
+			&cfg.DeviceAuthorizationEndpoint, &cfg.EndSessionEndpoint,
+		} {
+			if strings.HasPrefix(*endpoint, "/") {
+				*endpoint = issuer + *endpoint
+			}
+		}
+	}
+
+	provider, err := NewProvider(issuer, []string{"client"}, opts...)
+	require.NoError(t, err)
+
+	cfg.Issuer = issuer
+	provider.config = &cfg
+
+	return provider
+}
+
 // --- Provider Tests ---
 
 func TestNewProvider(t *testing.T) {
@@ -187,6 +226,41 @@ func TestUniqueID(t *testing.T) {
 	})
 }
 
+// --- Telemetry Tests ---
+
+func TestProviderTelemetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		assert.NoError(t, json.NewEncoder(w).Encode(Configuration{Issuer: "test"}))
+	}))
+	defer server.Close()
+
+	t.Run("records the spans of the requests", func(t *testing.T) {
+		tel := otlptest.New(t)
+
+		provider, err := NewProvider("test", nil, WithCustomIssuerURI(server.URL), WithAllowHttpScheme(true))
+		require.NoError(t, err)
+
+		_, err = provider.GetConfiguration(t.Context())
+		require.NoError(t, err)
+
+		spans := tel.Spans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "HTTP GET", spans[0].Name)
+	})
+
+	t.Run("records nothing if disabled", func(t *testing.T) {
+		tel := otlptest.New(t)
+
+		provider, err := NewProvider("test", nil, WithCustomIssuerURI(server.URL), WithAllowHttpScheme(true),
+			WithDisableTelemetry(true))
+		require.NoError(t, err)
+
+		_, err = provider.GetConfiguration(t.Context())
+		require.NoError(t, err)
+		assert.Empty(t, tel.Spans())
+	})
+}
+
 // --- DefaultIssuerClaims Test ---
 
 func TestDefaultIssuerClaims(t *testing.T) {
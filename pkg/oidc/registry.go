@@ -0,0 +1,85 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ProviderRegistry holds the providers of the trusted issuers, e.g. to look up the
+// provider of the `iss` claim of a token. The providers cache their OpenID configuration
+// and signing keys, so a registry shared by all requests fetches them once per issuer.
+// Only registered issuers are looked up, as the issuer of a token is not trusted.
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	providers map[string]*Provider
+}
+
+// NewProviderRegistry creates a registry of the given providers.
+func NewProviderRegistry(providers ...*Provider) *ProviderRegistry {
+	registry := &ProviderRegistry{
+		providers: make(map[string]*Provider, len(providers)),
+	}
+
+	registry.Register(providers...)
+
+	return registry
+}
+
+// Register adds the providers to the registry, replacing those of the same issuers.
+func (r *ProviderRegistry) Register(providers ...*Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, provider := range providers {
+		if provider == nil {
+			continue
+		}
+
+		r.providers[provider.issuer] = provider
+	}
+}
+
+// Remove removes the provider of the issuer from the registry.
+func (r *ProviderRegistry) Remove(issuer string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.providers, issuer)
+}
+
+// Provider returns the provider of the issuer, ErrUnknownIssuer if it is not registered.
+func (r *ProviderRegistry) Provider(issuer string) (*Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, ok := r.providers[issuer]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownIssuer, issuer)
+	}
+
+	return provider, nil
+}
+
+// GetConfiguration returns the cached OpenID configuration of the issuer.
+func (r *ProviderRegistry) GetConfiguration(ctx context.Context, issuer string) (*Configuration, error) {
+	provider, err := r.Provider(issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.GetConfiguration(ctx)
+}
+
+// Invalidate drops the cached OpenID configuration and signing keys of the issuer, so they
+// are fetched again on their next use. It returns false if the issuer is not registered.
+func (r *ProviderRegistry) Invalidate(issuer string) bool {
+	provider, err := r.Provider(issuer)
+	if err != nil {
+		return false
+	}
+
+	provider.Invalidate()
+
+	return true
+}
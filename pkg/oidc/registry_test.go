@@ -0,0 +1,58 @@
+package oidc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderRegistry(t *testing.T) {
+	server, calls := newVersionedConfigServer(t, nil)
+
+	provider, err := NewProvider("test", nil, WithCustomIssuerURI(server.URL), WithAllowHttpScheme(true))
+	require.NoError(t, err)
+
+	registry := NewProviderRegistry(provider, nil)
+
+	t.Run("returns the provider of the issuer", func(t *testing.T) {
+		got, err := registry.Provider("test")
+		require.NoError(t, err)
+		assert.Same(t, provider, got)
+	})
+
+	t.Run("rejects unknown issuers", func(t *testing.T) {
+		_, err := registry.Provider("https://attacker")
+		require.ErrorIs(t, err, ErrUnknownIssuer)
+
+		_, err = registry.GetConfiguration(t.Context(), "https://attacker")
+		require.ErrorIs(t, err, ErrUnknownIssuer)
+
+		assert.False(t, registry.Invalidate("https://attacker"))
+	})
+
+	t.Run("caches the configuration of the issuer", func(t *testing.T) {
+		for range 2 {
+			conf, err := registry.GetConfiguration(t.Context(), "test")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"1"}, conf.ScopesSupported)
+		}
+
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("invalidates the configuration of the issuer", func(t *testing.T) {
+		assert.True(t, registry.Invalidate("test"))
+
+		conf, err := registry.GetConfiguration(t.Context(), "test")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2"}, conf.ScopesSupported)
+	})
+
+	t.Run("removes the provider of the issuer", func(t *testing.T) {
+		registry.Remove("test")
+
+		_, err := registry.Provider("test")
+		assert.ErrorIs(t, err, ErrUnknownIssuer)
+	})
+}
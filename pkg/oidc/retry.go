@@ -0,0 +1,92 @@
+package oidc
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures the retries of the requests to the OpenID Provider which failed
+// with a connection error or a 5xx status, e.g. while the provider restarts.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a request, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the backoff before the first retry, it is doubled for every further retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the backoff between two attempts.
+	MaxBackoff time.Duration
+}
+
+// WithRetry configures the retries of the requests fetching the OpenID configuration and
+// introspecting tokens. The requests are not retried by default.
+func WithRetry(policy RetryPolicy) ProviderOption {
+	return func(provider *Provider) {
+		provider.retry = policy
+	}
+}
+
+// doWithRetry sends the request with the client, retrying it as configured by the retry
+// policy. The response of the last attempt is returned, even if it has a 5xx status.
+func (p *Provider) doWithRetry(client *http.Client, req *http.Request) (*http.Response, error) {
+	// requests with a body can only be retried if the body can be sent again
+	rewindable := req.Body == nil || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		resp, err := client.Do(req)
+		if attempt >= p.retry.MaxAttempts || !rewindable || req.Context().Err() != nil || !retryable(resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			// drain the body, so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(p.retry.backoff(attempt))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether the request failed with a connection error or a 5xx status.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// backoff returns a random backoff of up to InitialBackoff * 2^(attempt-1), capped by MaxBackoff.
+func (r RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := r.InitialBackoff
+	for i := 1; i < attempt && (r.MaxBackoff <= 0 || backoff < r.MaxBackoff); i++ {
+		backoff *= 2
+	}
+
+	if r.MaxBackoff > 0 && backoff > r.MaxBackoff {
+		backoff = r.MaxBackoff
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(backoff))) //nolint:gosec
+}
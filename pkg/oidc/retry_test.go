@@ -0,0 +1,133 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+// newFlakyServer responds with the status to the first failures requests.
+func newFlakyServer(t *testing.T, failures int32, status int, handler http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func TestRetryConfiguration(t *testing.T) {
+	serveConfig := func(w http.ResponseWriter, _ *http.Request) {
+		assert.NoError(t, json.NewEncoder(w).Encode(Configuration{Issuer: "test"}))
+	}
+
+	t.Run("retries 5xx responses", func(t *testing.T) {
+		server, calls := newFlakyServer(t, 2, http.StatusBadGateway, serveConfig)
+
+		provider, err := NewProvider("test", nil, WithCustomIssuerURI(server.URL),
+			WithAllowHttpScheme(true), WithRetry(testRetryPolicy))
+		require.NoError(t, err)
+
+		_, err = provider.GetConfiguration(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("returns the last response after the max attempts", func(t *testing.T) {
+		server, calls := newFlakyServer(t, 3, http.StatusServiceUnavailable, serveConfig)
+
+		provider, err := NewProvider("test", nil, WithCustomIssuerURI(server.URL),
+			WithAllowHttpScheme(true), WithRetry(testRetryPolicy))
+		require.NoError(t, err)
+
+		_, err = provider.GetConfiguration(t.Context())
+
+		var non200 ProviderRespondedNon200Error
+		require.ErrorAs(t, err, &non200)
+		assert.Equal(t, http.StatusServiceUnavailable, non200.Code)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("does not retry 4xx responses", func(t *testing.T) {
+		server, calls := newFlakyServer(t, 1, http.StatusNotFound, serveConfig)
+
+		provider, err := NewProvider("test", nil, WithCustomIssuerURI(server.URL),
+			WithAllowHttpScheme(true), WithRetry(testRetryPolicy))
+		require.NoError(t, err)
+
+		_, err = provider.GetConfiguration(t.Context())
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("does not retry without policy", func(t *testing.T) {
+		server, calls := newFlakyServer(t, 1, http.StatusBadGateway, serveConfig)
+
+		provider, err := NewProvider("test", nil, WithCustomIssuerURI(server.URL), WithAllowHttpScheme(true))
+		require.NoError(t, err)
+
+		_, err = provider.GetConfiguration(t.Context())
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("retries connection errors", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		var attempts atomic.Int32
+
+		client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			attempts.Add(1)
+			return http.DefaultTransport.RoundTrip(r)
+		})}
+
+		provider, err := NewProvider(server.URL, nil, WithAllowHttpScheme(true),
+			WithPublicHTTPClient(client), WithRetry(testRetryPolicy))
+		require.NoError(t, err)
+
+		_, err = provider.GetConfiguration(t.Context())
+		require.ErrorIs(t, err, ErrCouldNotDoHTTPRequest)
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+}
+
+func TestRetryIntrospection(t *testing.T) {
+	server, calls := newFlakyServer(t, 1, http.StatusInternalServerError, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.PostFormValue("token"), "expected the body to be sent again")
+		assert.NoError(t, json.NewEncoder(w).Encode(Introspection{Active: true}))
+	})
+
+	provider, err := NewProvider(server.URL, nil, WithAllowHttpScheme(true), WithRetry(testRetryPolicy))
+	require.NoError(t, err)
+
+	provider.config = &Configuration{IntrospectionEndpoint: server.URL + "/introspect"}
+
+	result, err := provider.IntrospectToken(t.Context(), "test-token")
+	require.NoError(t, err)
+	assert.True(t, result.Active)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
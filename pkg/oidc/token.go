@@ -0,0 +1,276 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// GrantType is an OAuth 2.0 grant type of a token request.
+type GrantType string
+
+const (
+	GrantTypeAuthorizationCode GrantType = "authorization_code"
+	GrantTypeClientCredentials GrantType = "client_credentials"
+	GrantTypeRefreshToken      GrantType = "refresh_token"
+	// GrantTypeDeviceCode is the device authorization grant of RFC 8628, see PollDeviceToken.
+	GrantTypeDeviceCode GrantType = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+// AuthMethod is a method of the client to authenticate at the token endpoint.
+// See https://openid.net/specs/openid-connect-core-1_0.html#ClientAuthentication for details.
+type AuthMethod string
+
+const (
+	// AuthMethodClientSecretBasic sends the client credentials in the Authorization header.
+	AuthMethodClientSecretBasic AuthMethod = "client_secret_basic"
+	// AuthMethodClientSecretPost sends the client credentials in the request body.
+	AuthMethodClientSecretPost AuthMethod = "client_secret_post"
+	// AuthMethodNone sends only the client ID, for public clients, e.g. CLI tools using PKCE.
+	AuthMethodNone AuthMethod = "none"
+)
+
+// TokenRequest is a request to the token endpoint of the OpenID Provider.
+type TokenRequest struct {
+	GrantType GrantType
+
+	ClientID     string
+	ClientSecret string
+	// AuthMethod is the client authentication method. If empty, the first method supported
+	// by the provider out of client_secret_basic and client_secret_post is used, or none
+	// for clients without a secret.
+	AuthMethod AuthMethod
+
+	// Code and RedirectURI are required by the authorization_code grant. CodeVerifier is
+	// the PKCE code verifier of the authorization request, if it sent a code challenge.
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+
+	// RefreshToken is required by the refresh_token grant.
+	RefreshToken string
+
+	// DeviceCode is required by the device_code grant.
+	DeviceCode string
+
+	// Scopes are the requested scopes, e.g. for the client_credentials grant.
+	Scopes []string
+	// Parameters are additional parameters of the request, e.g. resource or audience.
+	Parameters map[string]string
+}
+
+// TokenResponse is the successful response of the token endpoint.
+// See https://datatracker.ietf.org/doc/html/rfc6749#section-5.1 for details.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	// IDToken is returned by the authorization_code grant of OpenID Connect.
+	IDToken string `json:"id_token,omitempty"`
+}
+
+// RequestToken obtains tokens from the token endpoint of the OpenID Provider with the
+// given grant. The client authenticates with the method of the request, which must be
+// supported by the provider.
+func (p *Provider) RequestToken(ctx context.Context, tokenRequest TokenRequest) (TokenResponse, error) {
+	cfg, err := p.GetConfiguration(ctx)
+	if err != nil {
+		return TokenResponse{}, errors.Join(ErrCouldNotGetWellKnownConfig, err)
+	}
+
+	if cfg.TokenEndpoint == "" {
+		return TokenResponse{}, ErrNoTokenEndpoint
+	}
+
+	form, err := tokenRequest.form()
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	if len(cfg.GrantTypesSupported) > 0 && !slices.Contains(cfg.GrantTypesSupported, string(tokenRequest.GrantType)) {
+		return TokenResponse{}, fmt.Errorf("%w: %s", ErrUnsupportedGrantType, tokenRequest.GrantType)
+	}
+
+	statusCode, responseBody, err := p.postClientForm(ctx, cfg.TokenEndpoint, form, tokenRequest,
+		cfg.TokenEndpointAuthMethodsSupported)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	if statusCode != http.StatusOK {
+		return TokenResponse{}, newTokenError(statusCode, responseBody)
+	}
+
+	var tokens TokenResponse
+
+	err = json.Unmarshal(responseBody, &tokens)
+	if err != nil {
+		return TokenResponse{}, CouldNotUnmarshallResponseError{
+			Err:  err,
+			Body: string(responseBody),
+		}
+	}
+
+	return tokens, nil
+}
+
+// postClientForm posts the form to the endpoint, authenticating with the client of the
+// request, and returns the status and body of the response.
+func (p *Provider) postClientForm(ctx context.Context, endpoint string, form url.Values, client TokenRequest,
+	supportedAuthMethods []string,
+) (int, []byte, error) {
+	method, err := client.authMethod(supportedAuthMethods)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var basicAuth bool
+
+	switch method {
+	case AuthMethodClientSecretBasic:
+		basicAuth = true
+	case AuthMethodClientSecretPost:
+		form.Set("client_id", client.ClientID)
+		form.Set("client_secret", client.ClientSecret)
+	case AuthMethodNone:
+		form.Set("client_id", client.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, nil, errors.Join(ErrCouldNotCreateHTTPRequest, err)
+	}
+
+	req.Header.Set("Content-Type", urlencoded)
+	req.Header.Set("Accept", applicationJSON)
+
+	if basicAuth {
+		// RFC 6749 requires the form encoding of the credentials before the basic encoding
+		req.SetBasicAuth(url.QueryEscape(client.ClientID), url.QueryEscape(client.ClientSecret))
+	}
+
+	resp, err := p.secureHttpClient.Do(req)
+	if err != nil {
+		return 0, nil, errors.Join(ErrCouldNotDoHTTPRequest, err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, errors.Join(ErrCouldNotReadResponseBody, err)
+	}
+
+	return resp.StatusCode, responseBody, nil
+}
+
+// form returns the grant parameters of the request.
+func (r TokenRequest) form() (url.Values, error) {
+	form := make(url.Values, len(r.Parameters)+4)
+	for k, v := range r.Parameters {
+		form.Set(k, v)
+	}
+
+	form.Set("grant_type", string(r.GrantType))
+
+	if len(r.Scopes) > 0 {
+		form.Set("scope", strings.Join(r.Scopes, " "))
+	}
+
+	var missing []string
+
+	switch r.GrantType {
+	case GrantTypeAuthorizationCode:
+		if r.Code == "" {
+			missing = append(missing, "code")
+		}
+
+		if r.RedirectURI == "" {
+			missing = append(missing, "redirect_uri")
+		}
+
+		form.Set("code", r.Code)
+		form.Set("redirect_uri", r.RedirectURI)
+
+		if r.CodeVerifier != "" {
+			form.Set("code_verifier", r.CodeVerifier)
+		}
+	case GrantTypeClientCredentials:
+		if r.ClientSecret == "" {
+			missing = append(missing, "client_secret")
+		}
+	case GrantTypeRefreshToken:
+		if r.RefreshToken == "" {
+			missing = append(missing, "refresh_token")
+		}
+
+		form.Set("refresh_token", r.RefreshToken)
+	case GrantTypeDeviceCode:
+		if r.DeviceCode == "" {
+			missing = append(missing, "device_code")
+		}
+
+		form.Set("device_code", r.DeviceCode)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedGrantType, r.GrantType)
+	}
+
+	if r.ClientID == "" {
+		missing = append(missing, "client_id")
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingTokenRequestParam, strings.Join(missing, ", "))
+	}
+
+	return form, nil
+}
+
+// authMethod returns the client authentication method of the request, which must be one
+// of the supported methods. The provider supports client_secret_basic if it does not
+// publish its methods.
+func (r TokenRequest) authMethod(supported []string) (AuthMethod, error) {
+	if len(supported) == 0 {
+		supported = []string{string(AuthMethodClientSecretBasic)}
+	}
+
+	method := r.AuthMethod
+	if method == "" && r.ClientSecret == "" {
+		method = AuthMethodNone
+	}
+
+	if method == "" {
+		for _, candidate := range []AuthMethod{AuthMethodClientSecretBasic, AuthMethodClientSecretPost} {
+			if slices.Contains(supported, string(candidate)) {
+				return candidate, nil
+			}
+		}
+
+		return "", fmt.Errorf("%w: provider supports %s", ErrUnsupportedAuthMethod, strings.Join(supported, ", "))
+	}
+
+	switch method {
+	case AuthMethodClientSecretBasic, AuthMethodClientSecretPost:
+		if r.ClientSecret == "" {
+			return "", fmt.Errorf("%w: client_secret", ErrMissingTokenRequestParam)
+		}
+	case AuthMethodNone:
+		// public clients are not listed by all providers, so none is not checked
+		return method, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedAuthMethod, method)
+	}
+
+	if !slices.Contains(supported, string(method)) {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedAuthMethod, method)
+	}
+
+	return method, nil
+}
@@ -0,0 +1,191 @@
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestToken(t *testing.T) {
+	tokens := TokenResponse{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 300, RefreshToken: "refresh"}
+
+	t.Run("authorization code with PKCE and basic auth", func(t *testing.T) {
+		provider := newTestProvider(t, Configuration{TokenEndpoint: "/token"}, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/token", r.URL.Path)
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+
+			user, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "client%3A1", user)
+			assert.Equal(t, "secret", password)
+
+			assert.Equal(t, "authorization_code", r.PostFormValue("grant_type"))
+			assert.Equal(t, "code", r.PostFormValue("code"))
+			assert.Equal(t, "https://app/callback", r.PostFormValue("redirect_uri"))
+			assert.Equal(t, "verifier", r.PostFormValue("code_verifier"))
+			assert.Empty(t, r.PostFormValue("client_secret"))
+
+			assert.NoError(t, json.NewEncoder(w).Encode(tokens))
+		})
+
+		got, err := provider.RequestToken(t.Context(), TokenRequest{
+			GrantType:    GrantTypeAuthorizationCode,
+			ClientID:     "client:1",
+			ClientSecret: "secret",
+			Code:         "code",
+			RedirectURI:  "https://app/callback",
+			CodeVerifier: "verifier",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, tokens, got)
+	})
+
+	t.Run("client credentials with the credentials in the body", func(t *testing.T) {
+		provider := newTestProvider(t, Configuration{
+			TokenEndpoint:                     "/token",
+			TokenEndpointAuthMethodsSupported: []string{"client_secret_post", "private_key_jwt"},
+		}, func(w http.ResponseWriter, r *http.Request) {
+			_, _, ok := r.BasicAuth()
+			assert.False(t, ok)
+
+			assert.Equal(t, "client_credentials", r.PostFormValue("grant_type"))
+			assert.Equal(t, "client", r.PostFormValue("client_id"))
+			assert.Equal(t, "secret", r.PostFormValue("client_secret"))
+			assert.Equal(t, "read write", r.PostFormValue("scope"))
+			assert.Equal(t, "https://api", r.PostFormValue("resource"))
+
+			assert.NoError(t, json.NewEncoder(w).Encode(tokens))
+		})
+
+		got, err := provider.RequestToken(t.Context(), TokenRequest{
+			GrantType:    GrantTypeClientCredentials,
+			ClientID:     "client",
+			ClientSecret: "secret",
+			Scopes:       []string{"read", "write"},
+			Parameters:   map[string]string{"resource": "https://api"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "access", got.AccessToken)
+	})
+
+	t.Run("refresh token of a public client", func(t *testing.T) {
+		provider := newTestProvider(t, Configuration{TokenEndpoint: "/token"}, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "refresh_token", r.PostFormValue("grant_type"))
+			assert.Equal(t, "refresh", r.PostFormValue("refresh_token"))
+			assert.Equal(t, "cli", r.PostFormValue("client_id"))
+
+			assert.NoError(t, json.NewEncoder(w).Encode(tokens))
+		})
+
+		got, err := provider.RequestToken(t.Context(), TokenRequest{
+			GrantType:    GrantTypeRefreshToken,
+			ClientID:     "cli",
+			RefreshToken: "refresh",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "refresh", got.RefreshToken)
+	})
+
+	t.Run("returns the error response", func(t *testing.T) {
+		provider := newTestProvider(t, Configuration{TokenEndpoint: "/token"}, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"code expired"}`))
+		})
+
+		_, err := provider.RequestToken(t.Context(), TokenRequest{
+			GrantType:    GrantTypeAuthorizationCode,
+			ClientID:     "client",
+			ClientSecret: "secret",
+			Code:         "code",
+			RedirectURI:  "https://app/callback",
+		})
+
+		var tokenErr TokenError
+		require.ErrorAs(t, err, &tokenErr)
+		assert.Equal(t, http.StatusBadRequest, tokenErr.StatusCode)
+		assert.Equal(t, "invalid_grant", tokenErr.ErrorCode)
+		assert.Equal(t, "token endpoint responded with status code 400: invalid_grant: code expired", tokenErr.Error())
+	})
+}
+
+func TestRequestTokenInvalidRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     TokenRequest
+		authMethods []string
+		grantTypes  []string
+		wantErr     error
+	}{
+		{
+			name:    "unknown grant type",
+			request: TokenRequest{GrantType: "password", ClientID: "client"},
+			wantErr: ErrUnsupportedGrantType,
+		},
+		{
+			name:       "grant type not supported by the provider",
+			request:    TokenRequest{GrantType: GrantTypeRefreshToken, ClientID: "client", RefreshToken: "refresh"},
+			grantTypes: []string{"authorization_code"},
+			wantErr:    ErrUnsupportedGrantType,
+		},
+		{
+			name:    "missing code",
+			request: TokenRequest{GrantType: GrantTypeAuthorizationCode, ClientID: "client", RedirectURI: "https://app"},
+			wantErr: ErrMissingTokenRequestParam,
+		},
+		{
+			name:    "missing device code",
+			request: TokenRequest{GrantType: GrantTypeDeviceCode, ClientID: "client"},
+			wantErr: ErrMissingTokenRequestParam,
+		},
+		{
+			name:    "client credentials without secret",
+			request: TokenRequest{GrantType: GrantTypeClientCredentials, ClientID: "client"},
+			wantErr: ErrMissingTokenRequestParam,
+		},
+		{
+			name: "auth method not supported by the provider",
+			request: TokenRequest{
+				GrantType: GrantTypeClientCredentials, ClientID: "client", ClientSecret: "secret",
+				AuthMethod: AuthMethodClientSecretPost,
+			},
+			authMethods: []string{"client_secret_basic"},
+			wantErr:     ErrUnsupportedAuthMethod,
+		},
+		{
+			name:        "no secret based auth method supported",
+			request:     TokenRequest{GrantType: GrantTypeClientCredentials, ClientID: "client", ClientSecret: "secret"},
+			authMethods: []string{"private_key_jwt"},
+			wantErr:     ErrUnsupportedAuthMethod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestProvider(t, Configuration{
+				TokenEndpoint:                     "/token",
+				TokenEndpointAuthMethodsSupported: tt.authMethods,
+				GrantTypesSupported:               tt.grantTypes,
+			}, func(http.ResponseWriter, *http.Request) {
+				t.Error("unexpected token request")
+			})
+
+			_, err := provider.RequestToken(t.Context(), tt.request)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRequestTokenNoTokenEndpoint(t *testing.T) {
+	provider, err := NewProvider("https://issuer", nil)
+	require.NoError(t, err)
+
+	provider.config = &Configuration{Issuer: "https://issuer"}
+
+	_, err = provider.RequestToken(t.Context(), TokenRequest{GrantType: GrantTypeRefreshToken})
+	assert.ErrorIs(t, err, ErrNoTokenEndpoint)
+}
@@ -0,0 +1,106 @@
+package otlpaudit
+
+import (
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// EventBatch collects the audit events of e.g. one request into a single plog.Logs, so
+// they are sent with a single payload:
+//
+//	batch := otlpaudit.NewEventBatch()
+//
+//	err := batch.Add(otlpaudit.NewKeyCreateEvent(metadata, keyID, systemID, cmkID, otlpaudit.KEYTYPE_DATA))
+//	if err != nil {
+//	    return err
+//	}
+//
+//	err = batch.Add(otlpaudit.Event(otlpaudit.CmkSwitchEvent).Metadata(metadata).Object(systemID).CmkSwitch(oldID, newID).Build())
+//	if err != nil {
+//	    return err
+//	}
+//
+//	err = auditLogger.SendEvent(ctx, batch.Logs())
+//
+// The log records of the events share one ResourceLogs and ScopeLogs, as long as the
+// resources and scopes of the events are equal, which holds for the events created by
+// this package.
+type EventBatch struct {
+	logs plog.Logs
+}
+
+// NewEventBatch creates an empty batch.
+func NewEventBatch() *EventBatch {
+	return &EventBatch{logs: plog.NewLogs()}
+}
+
+// Add moves the log records of the event into the batch. It takes the results of an event
+// constructor or EventBuilder.Build, and returns their error without adding the event.
+func (b *EventBatch) Add(logs plog.Logs, err error) error {
+	if err != nil {
+		return err
+	}
+
+	mergeLogs(b.logs, logs)
+
+	return nil
+}
+
+// Len returns the number of events in the batch.
+func (b *EventBatch) Len() int {
+	return b.logs.LogRecordCount()
+}
+
+// Logs returns the events of the batch.
+func (b *EventBatch) Logs() plog.Logs {
+	return b.logs
+}
+
+// mergeLogs moves the log records of src into dst. The records are appended to the
+// ResourceLogs and ScopeLogs of dst with the same resource and scope, which are created
+// if dst has none.
+func mergeLogs(dst, src plog.Logs) {
+	for _, srcRL := range src.ResourceLogs().All() {
+		dstRL := findResourceLogs(dst, srcRL)
+
+		for _, srcSL := range srcRL.ScopeLogs().All() {
+			dstSL := findScopeLogs(dstRL, srcSL)
+			srcSL.LogRecords().MoveAndAppendTo(dstSL.LogRecords())
+		}
+	}
+
+	src.ResourceLogs().RemoveIf(func(plog.ResourceLogs) bool { return true })
+}
+
+func findResourceLogs(logs plog.Logs, rl plog.ResourceLogs) plog.ResourceLogs {
+	for _, candidate := range logs.ResourceLogs().All() {
+		if candidate.SchemaUrl() == rl.SchemaUrl() &&
+			candidate.Resource().DroppedAttributesCount() == rl.Resource().DroppedAttributesCount() &&
+			candidate.Resource().Attributes().Equal(rl.Resource().Attributes()) {
+			return candidate
+		}
+	}
+
+	created := logs.ResourceLogs().AppendEmpty()
+	created.SetSchemaUrl(rl.SchemaUrl())
+	rl.Resource().CopyTo(created.Resource())
+
+	return created
+}
+
+func findScopeLogs(rl plog.ResourceLogs, sl plog.ScopeLogs) plog.ScopeLogs {
+	for _, candidate := range rl.ScopeLogs().All() {
+		if candidate.SchemaUrl() == sl.SchemaUrl() &&
+			candidate.Scope().Name() == sl.Scope().Name() &&
+			candidate.Scope().Version() == sl.Scope().Version() &&
+			candidate.Scope().DroppedAttributesCount() == sl.Scope().DroppedAttributesCount() &&
+			candidate.Scope().Attributes().Equal(sl.Scope().Attributes()) {
+			return candidate
+		}
+	}
+
+	created := rl.ScopeLogs().AppendEmpty()
+	created.SetSchemaUrl(sl.SchemaUrl())
+	sl.Scope().CopyTo(created.Scope())
+
+	return created
+}
@@ -0,0 +1,88 @@
+package otlpaudit
+
+import (
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestEventBatch(t *testing.T) {
+	metadata := EventMetadata{UserInitiatorIDKey: "userInitiatorID", TenantIDKey: "tenantID"}
+
+	t.Run("shares the resource and scope of the events", func(t *testing.T) {
+		batch := NewEventBatch()
+
+		err := batch.Add(NewCmkCreateEvent(metadata, "cmk1"))
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+
+		err = batch.Add(Event(CmkEnableEvent).Metadata(metadata).Object("cmk1").Build())
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+
+		logs := batch.Logs()
+		if batch.Len() != 2 || logs.ResourceLogs().Len() != 1 || logs.ResourceLogs().At(0).ScopeLogs().Len() != 1 {
+			t.Errorf("expected 2 records in a single ResourceLogs and ScopeLogs, got %d in %d ResourceLogs",
+				batch.Len(), logs.ResourceLogs().Len())
+		}
+	})
+
+	t.Run("returns the error of the event", func(t *testing.T) {
+		batch := NewEventBatch()
+
+		err := batch.Add(NewCmkCreateEvent(metadata, ""))
+		if !errors.Is(err, errEventCreation) {
+			t.Fatalf("expected errEventCreation, got '%v'", err)
+		}
+
+		if batch.Len() != 0 {
+			t.Errorf("expected an empty batch, got %d records", batch.Len())
+		}
+	})
+
+	t.Run("keeps different resources apart", func(t *testing.T) {
+		other := newTestLogs()
+		other.ResourceLogs().At(0).Resource().Attributes().PutStr("service.name", "other")
+
+		batch := NewEventBatch()
+
+		for _, logs := range []plog.Logs{newTestLogs(), other, newTestLogs()} {
+			err := batch.Add(logs, nil)
+			if err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+		}
+
+		if batch.Len() != 3 || batch.Logs().ResourceLogs().Len() != 2 {
+			t.Errorf("expected 3 records in 2 ResourceLogs, got %d in %d", batch.Len(), batch.Logs().ResourceLogs().Len())
+		}
+	})
+}
+
+func TestEnrichLogsOfBatch(t *testing.T) {
+	auditLogger := &AuditLogger{additionalProps: map[string]any{"region": "eu"}}
+
+	batch := NewEventBatch()
+	for range 2 {
+		err := batch.Add(newTestLogs(), nil)
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	logs := batch.Logs()
+
+	err := auditLogger.enrichLogs(&logs)
+	if err != nil {
+		t.Fatalf("enrichLogs() error = %v", err)
+	}
+
+	for _, lr := range logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().All() {
+		if region, _ := lr.Attributes().Get("region"); region.Str() != "eu" {
+			t.Errorf("expected the region of all records, got %q", region.Str())
+		}
+	}
+}
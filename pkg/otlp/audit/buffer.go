@@ -0,0 +1,266 @@
+package otlpaudit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	defBufferFileSize = 16 << 20
+	bufferFileSuffix  = ".wal"
+)
+
+// writeAheadBuffer persists the queued events of the audit sender in append-only segment
+// files, one JSON encoded event per line. A segment is removed once it was rotated and all
+// its events were exported, so the segments left after a crash or shutdown hold the events
+// which still have to be exported.
+type writeAheadBuffer struct {
+	dir         string
+	maxFileSize int64
+
+	mu      sync.Mutex
+	file    *os.File
+	segment uint64
+	size    int64
+	pending map[uint64]int
+}
+
+// openWriteAheadBuffer opens the buffer of the directory and returns the segments left by
+// the previous process, ordered by their creation. New events are appended to a new segment.
+func openWriteAheadBuffer(dir string, maxFileSize int64) (*writeAheadBuffer, []uint64, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var segments []uint64
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), bufferFileSuffix)
+		if entry.IsDir() || !ok {
+			continue
+		}
+
+		segment, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		segments = append(segments, segment)
+	}
+
+	slices.Sort(segments)
+
+	if maxFileSize <= 0 {
+		maxFileSize = defBufferFileSize
+	}
+
+	b := &writeAheadBuffer{
+		dir:         dir,
+		maxFileSize: maxFileSize,
+		pending:     map[uint64]int{},
+	}
+
+	if len(segments) > 0 {
+		b.segment = segments[len(segments)-1]
+	}
+
+	err = b.rotate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return b, segments, nil
+}
+
+// add appends the event to the current segment and syncs it, then calls enqueue with the
+// segment. If enqueue returns false, the event is removed from the segment again.
+func (b *writeAheadBuffer) add(event []byte, enqueue func(segment uint64) bool) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.size >= b.maxFileSize {
+		err := b.rotate()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	offset := b.size
+
+	n, err := b.file.Write(append(event, '\n'))
+	b.size += int64(n)
+
+	if err == nil {
+		err = b.file.Sync()
+	}
+
+	if err != nil {
+		return false, errors.Join(err, b.truncate(offset))
+	}
+
+	if !enqueue(b.segment) {
+		return false, b.truncate(offset)
+	}
+
+	b.pending[b.segment]++
+
+	return true, nil
+}
+
+// ack marks the events of the segments as exported and removes the rotated segments
+// without pending events.
+func (b *writeAheadBuffer) ack(segments []uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var errs []error
+
+	for _, segment := range segments {
+		b.pending[segment]--
+		if b.pending[segment] > 0 || segment == b.segment {
+			continue
+		}
+
+		delete(b.pending, segment)
+		errs = append(errs, b.remove(segment))
+	}
+
+	return errors.Join(errs...)
+}
+
+// close closes the current segment, which is removed if all its events were exported.
+func (b *writeAheadBuffer) close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.file == nil {
+		return nil
+	}
+
+	err := b.file.Close()
+	b.file = nil
+
+	if err != nil {
+		return err
+	}
+
+	if b.pending[b.segment] > 0 {
+		return nil
+	}
+
+	return b.remove(b.segment)
+}
+
+// rotate closes the current segment and creates the next one. The closed segment is
+// removed if all its events were exported.
+func (b *writeAheadBuffer) rotate() error {
+	if b.file != nil {
+		err := b.file.Close()
+		if err != nil {
+			return err
+		}
+
+		if b.pending[b.segment] == 0 {
+			delete(b.pending, b.segment)
+
+			err = b.remove(b.segment)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	file, err := os.OpenFile(b.path(b.segment+1), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	b.file = file
+	b.segment++
+	b.size = 0
+
+	return syncDir(b.dir)
+}
+
+// truncate removes the partially or not enqueued event written at the offset.
+func (b *writeAheadBuffer) truncate(offset int64) error {
+	err := b.file.Truncate(offset)
+	if err != nil {
+		return err
+	}
+
+	b.size = offset
+
+	return nil
+}
+
+func (b *writeAheadBuffer) remove(segment uint64) error {
+	err := os.Remove(b.path(segment))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (b *writeAheadBuffer) path(segment uint64) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%020d%s", segment, bufferFileSuffix))
+}
+
+// readSegment returns the events of a segment left by the previous process. An event
+// which was only partially written before a crash is skipped, as it was never queued.
+func (b *writeAheadBuffer) readSegment(segment uint64) ([]plog.Logs, error) {
+	file, err := os.Open(b.path(segment))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []plog.Logs
+
+	unmarshaler := plog.JSONUnmarshaler{}
+	reader := bufio.NewReader(file)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		logs, err := unmarshaler.UnmarshalLogs(bytes.TrimSpace(line))
+		if err != nil {
+			continue
+		}
+
+		events = append(events, logs)
+	}
+}
+
+// removeSegment removes a segment left by the previous process once its events were exported.
+func (b *writeAheadBuffer) removeSegment(segment uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.remove(segment)
+}
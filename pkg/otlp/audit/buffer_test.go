@@ -0,0 +1,196 @@
+package otlpaudit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func bufferFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+bufferFileSuffix))
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+
+	return files
+}
+
+func TestAuditSenderWriteAheadBuffer(t *testing.T) {
+	t.Run("exports the buffered events after a restart", func(t *testing.T) {
+		dir := t.TempDir()
+
+		// the requests of the first sender go to an unavailable collector, so its aborted
+		// requests are not counted by the collector of the second sender
+		unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer unavailable.Close()
+
+		var counter recordCounter
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			counter.count(t, r)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		cfg := commoncfg.AuditSender{BufferDir: dir, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+		sender := newTestSender(t, unavailable.URL, cfg)
+
+		for range 2 {
+			err := sender.Send(t.Context(), newTestLogs())
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+		}
+
+		// the collector is unavailable, so the events are left in the buffer
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+
+		err := sender.Close(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got '%v'", err)
+		}
+
+		if len(bufferFiles(t, dir)) != 1 {
+			t.Fatalf("expected 1 buffer file, got %v", bufferFiles(t, dir))
+		}
+
+		sender = newTestSender(t, server.URL, cfg)
+
+		err = sender.Flush(t.Context())
+		if err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+
+		if counter.records.Load() != 2 {
+			t.Errorf("expected 2 replayed records, got %d", counter.records.Load())
+		}
+
+		err = sender.Close(t.Context())
+		if err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		if files := bufferFiles(t, dir); len(files) != 0 {
+			t.Errorf("expected an empty buffer, got %v", files)
+		}
+	})
+
+	t.Run("removes the rotated files once exported", func(t *testing.T) {
+		dir := t.TempDir()
+
+		var counter recordCounter
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			counter.count(t, r)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		sender := newTestSender(t, server.URL, commoncfg.AuditSender{BufferDir: dir, MaxBufferFileSize: 1})
+
+		for range 3 {
+			err := sender.Send(t.Context(), newTestLogs())
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+		}
+
+		err := sender.Flush(t.Context())
+		if err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+
+		if counter.records.Load() != 3 {
+			t.Errorf("expected 3 records, got %d", counter.records.Load())
+		}
+
+		// only the current file is left
+		if files := bufferFiles(t, dir); len(files) != 1 {
+			t.Errorf("expected 1 buffer file, got %v", files)
+		}
+	})
+
+	t.Run("skips a partially written event", func(t *testing.T) {
+		dir := t.TempDir()
+
+		event, err := marshalLogs(newTestLogs())
+		if err != nil {
+			t.Fatalf("marshalLogs() error = %v", err)
+		}
+
+		content := event + "\n" + event[:len(event)/2]
+
+		err = os.WriteFile(filepath.Join(dir, "00000000000000000007"+bufferFileSuffix), []byte(content), 0o600)
+		if err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+
+		var counter recordCounter
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			counter.count(t, r)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		sender := newTestSender(t, server.URL, commoncfg.AuditSender{BufferDir: dir})
+
+		err = sender.Flush(t.Context())
+		if err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+
+		if counter.records.Load() != 1 {
+			t.Errorf("expected 1 replayed record, got %d", counter.records.Load())
+		}
+
+		files := bufferFiles(t, dir)
+		if len(files) != 1 || !strings.HasSuffix(files[0], "00000000000000000008"+bufferFileSuffix) {
+			t.Errorf("expected only the new buffer file, got %v", files)
+		}
+	})
+}
+
+func TestWriteAheadBufferRemovesRejectedEvents(t *testing.T) {
+	buffer, segments, err := openWriteAheadBuffer(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("openWriteAheadBuffer() error = %v", err)
+	}
+
+	if len(segments) != 0 {
+		t.Errorf("expected no segments to replay, got %v", segments)
+	}
+
+	queued, err := buffer.add([]byte(`{"resourceLogs":[]}`), func(uint64) bool { return false })
+	if err != nil || queued {
+		t.Fatalf("expected a rejected event, got %v, %v", queued, err)
+	}
+
+	info, err := buffer.file.Stat()
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+
+	if info.Size() != 0 {
+		t.Errorf("expected an empty buffer file, got %d bytes", info.Size())
+	}
+
+	err = buffer.close()
+	if err != nil {
+		t.Fatalf("close() error = %v", err)
+	}
+}
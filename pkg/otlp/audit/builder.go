@@ -0,0 +1,392 @@
+package otlpaudit
+
+import (
+	"strings"
+
+	"github.com/samber/oops"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// EventBuilder builds an audit event of any type from named attributes, as an alternative
+// to the New*Event constructors and their positional parameters:
+//
+//	logs, err := otlpaudit.Event(otlpaudit.KeyCreateEvent).
+//	    Metadata(metadata).
+//	    Object(keyID).
+//	    System(systemID).
+//	    Cmk(cmkID).
+//	    KeyType(otlpaudit.KEYTYPE_DATA).
+//	    Build()
+//
+// Build validates that the attributes required by the event type are set and names the
+// missing ones in its error. The attributes not used by the event type are ignored.
+type EventBuilder struct {
+	eventType  string
+	metadata   EventMetadata
+	properties eventProperties
+
+	keyType        KeyType
+	loginMethod    LoginMethod
+	mfaType        MfaType
+	userType       UserType
+	failReason     FailReason
+	credentialType CredentialType
+	cmkAction      CmkAction
+	outcome        Outcome
+	severity       Severity
+	errorReason    string
+}
+
+// builderRequirements are the attributes required by the event types in addition to the
+// object, except for the request events, which refer to the initiating user instead.
+var builderRequirements = map[string][]string{
+	KeyCreateEvent:             {SystemIDKey, CmkIDKey},
+	KeyDeleteEvent:             {SystemIDKey, CmkIDKey},
+	KeyRestoreEvent:            {SystemIDKey, CmkIDKey},
+	KeyPurgeEvent:              {SystemIDKey, CmkIDKey},
+	KeyRotateEvent:             {SystemIDKey, CmkIDKey},
+	KeyEnableEvent:             {SystemIDKey, CmkIDKey},
+	KeyDisableEvent:            {SystemIDKey, CmkIDKey},
+	WorkflowStartEvent:         {ChannelIDKey, ChannelTypeKey},
+	WorkflowExecuteEvent:       {ChannelIDKey, ChannelTypeKey},
+	WorkflowTerminateEvent:     {ChannelIDKey, ChannelTypeKey},
+	GroupReadEvent:             {ChannelIDKey, ChannelTypeKey},
+	GroupUpdateEvent:           {PropertyNameKey},
+	TenantUpdateEvent:          {PropertyNameKey, OldValueKey, NewValueKey},
+	ConfigCreateEvent:          {ValueKey},
+	ConfigReadEvent:            {ChannelIDKey, ChannelTypeKey, ValueKey},
+	ConfigUpdateEvent:          {OldValueKey, NewValueKey},
+	ConfigDeleteEvent:          {ValueKey},
+	CmkOnboardingEvent:         {SystemIDKey},
+	CmkOffboardingEvent:        {SystemIDKey},
+	CmkSwitchEvent:             {CmkIDOldKey, CmkIDNewKey},
+	CmkTenantModificationEvent: {SystemIDKey},
+	UnauthorizedRequestEvent:   {ResourceKey, ActionKey},
+	RoleAssignedEvent:          {AssigneeIDKey},
+	RoleRevokedEvent:           {AssigneeIDKey},
+	APIKeyCreateEvent:          {OwnerIDKey},
+	APIKeyRotateEvent:          {OwnerIDKey},
+	APIKeyRevokeEvent:          {OwnerIDKey},
+	DataExportRequestEvent:     {ScopeKey},
+	DataExportCompleteEvent:    {ScopeKey},
+	BackupCreateEvent:          {ScopeKey},
+	BackupRestoreEvent:         {ScopeKey},
+}
+
+// severityNumbers are the severities of the log records of the event severities.
+var severityNumbers = map[Severity]plog.SeverityNumber{
+	SEVERITY_INFO:     plog.SeverityNumberInfo,
+	SEVERITY_WARNING:  plog.SeverityNumberWarn,
+	SEVERITY_ERROR:    plog.SeverityNumberError,
+	SEVERITY_CRITICAL: plog.SeverityNumberFatal,
+}
+
+// Event starts building an audit event of the type, e.g. KeyCreateEvent.
+func Event(eventType string) *EventBuilder {
+	return &EventBuilder{
+		eventType:  eventType,
+		properties: eventProperties{},
+	}
+}
+
+// Metadata sets the initiating user, tenant and correlation ID of the event.
+func (b *EventBuilder) Metadata(metadata EventMetadata) *EventBuilder {
+	b.metadata = metadata
+	return b
+}
+
+// Object sets the ID of the object the event refers to, e.g. the key, the CMK of the CMK
+// events, the system of CmkSwitchEvent, the tenant of the tenant events or the credential
+// of the credential events.
+func (b *EventBuilder) Object(objectID string) *EventBuilder {
+	b.properties[ObjectIDKey] = objectID
+	return b
+}
+
+// System sets the ID of the system the event refers to.
+func (b *EventBuilder) System(systemID string) *EventBuilder {
+	b.properties[SystemIDKey] = systemID
+	return b
+}
+
+// Cmk sets the ID of the customer managed key of a key event.
+func (b *EventBuilder) Cmk(cmkID string) *EventBuilder {
+	b.properties[CmkIDKey] = cmkID
+	return b
+}
+
+// CmkSwitch sets the IDs of the previous and the new customer managed key of CmkSwitchEvent.
+func (b *EventBuilder) CmkSwitch(oldCmkID, newCmkID string) *EventBuilder {
+	b.properties[CmkIDOldKey] = oldCmkID
+	b.properties[CmkIDNewKey] = newCmkID
+
+	return b
+}
+
+// Channel sets the type and ID of the channel a workflow runs on or an object is read from.
+func (b *EventBuilder) Channel(channelType, channelID string) *EventBuilder {
+	b.properties[ChannelTypeKey] = channelType
+	b.properties[ChannelIDKey] = channelID
+
+	return b
+}
+
+// Property sets the name of the changed property of an update event.
+func (b *EventBuilder) Property(name string) *EventBuilder {
+	b.properties[PropertyNameKey] = name
+	return b
+}
+
+// Value sets the value of the object the event refers to.
+func (b *EventBuilder) Value(value any) *EventBuilder {
+	b.properties[ValueKey] = value
+	return b
+}
+
+// Change sets the values of the changed property before and after an update.
+func (b *EventBuilder) Change(oldValue, newValue any) *EventBuilder {
+	b.properties[OldValueKey] = oldValue
+	b.properties[NewValueKey] = newValue
+
+	return b
+}
+
+// Dpp marks the values as data protection and privacy relevant.
+func (b *EventBuilder) Dpp(dpp bool) *EventBuilder {
+	b.properties[DppKey] = dpp
+	return b
+}
+
+// Request sets the resource and action of a request rejected due to missing permissions.
+func (b *EventBuilder) Request(resource, action string) *EventBuilder {
+	b.properties[ResourceKey] = resource
+	b.properties[ActionKey] = action
+
+	return b
+}
+
+// Assignee sets the user or group of RoleAssignedEvent and RoleRevokedEvent.
+func (b *EventBuilder) Assignee(assigneeID string) *EventBuilder {
+	b.properties[AssigneeIDKey] = assigneeID
+	return b
+}
+
+// Owner sets the owner of the API key of an API key event.
+func (b *EventBuilder) Owner(ownerID string) *EventBuilder {
+	b.properties[OwnerIDKey] = ownerID
+	return b
+}
+
+// Scope sets the data covered by a data export or backup event.
+func (b *EventBuilder) Scope(scope string) *EventBuilder {
+	b.properties[ScopeKey] = scope
+	return b
+}
+
+// KeyType sets the type of the key of a key event.
+func (b *EventBuilder) KeyType(t KeyType) *EventBuilder {
+	b.keyType = t
+	return b
+}
+
+// Login sets the login method, MFA type and user type of UserLoginSuccessEvent.
+func (b *EventBuilder) Login(l LoginMethod, t MfaType, u UserType) *EventBuilder {
+	b.loginMethod = l
+	b.mfaType = t
+	b.userType = u
+
+	return b
+}
+
+// LoginFailure sets the login method and failure reason of UserLoginFailureEvent.
+func (b *EventBuilder) LoginFailure(l LoginMethod, f FailReason) *EventBuilder {
+	b.loginMethod = l
+	b.failReason = f
+
+	return b
+}
+
+// CredentialType sets the type of the credential of a credential event.
+func (b *EventBuilder) CredentialType(c CredentialType) *EventBuilder {
+	b.credentialType = c
+	return b
+}
+
+// CmkAction sets the action of CmkTenantModificationEvent.
+func (b *EventBuilder) CmkAction(c CmkAction) *EventBuilder {
+	b.cmkAction = c
+	return b
+}
+
+// Outcome sets whether the operation succeeded, failed or was denied, so attempted and
+// successful operations can be told apart. The login and request events imply their outcome.
+func (b *EventBuilder) Outcome(o Outcome) *EventBuilder {
+	b.outcome = o
+	return b
+}
+
+// Severity sets the severity of the event, which is also the severity of the log record.
+func (b *EventBuilder) Severity(s Severity) *EventBuilder {
+	b.severity = s
+	return b
+}
+
+// ErrorReason sets why a failed or denied operation did not succeed.
+func (b *EventBuilder) ErrorReason(reason string) *EventBuilder {
+	b.errorReason = reason
+	return b
+}
+
+// Build validates the attributes required by the event type and creates the event.
+func (b *EventBuilder) Build() (plog.Logs, error) {
+	required, ok := builderRequirements[b.eventType]
+	if !ok && !IsKnownEventType(b.eventType) {
+		return plog.Logs{}, oops.In(domain).
+			With("event_type", b.eventType).
+			Wrapf(errEventCreation, "unknown event type %q", b.eventType)
+	}
+
+	if b.eventType != UnauthorizedRequestEvent && b.eventType != UnauthenticatedRequestEvent {
+		required = append([]string{ObjectIDKey}, required...)
+	}
+
+	var missing []string
+
+	for _, key := range []string{UserInitiatorIDKey, TenantIDKey} {
+		if b.metadata[key] == "" {
+			missing = append(missing, key)
+		}
+	}
+
+	for _, key := range required {
+		if !b.properties.hasValues(key) {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) > 0 {
+		return plog.Logs{}, oops.In(domain).
+			With("event_type", b.eventType).
+			Wrapf(errEventCreation, "%s event requires %s", b.eventType, strings.Join(missing, ", "))
+	}
+
+	if !b.outcome.IsValid() || !b.severity.IsValid() {
+		return plog.Logs{}, oops.In(domain).
+			With("event_type", b.eventType).
+			Wrapf(errEventCreation, "invalid outcome %q or severity %q", b.outcome, b.severity)
+	}
+
+	logs, err := b.build()
+	if err != nil {
+		return logs, err
+	}
+
+	b.applyOutcome(logs)
+
+	return logs, nil
+}
+
+// applyOutcome sets the outcome, severity and error reason on the record of the event.
+func (b *EventBuilder) applyOutcome(logs plog.Logs) {
+	lr := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+
+	if b.outcome != "" {
+		lr.Attributes().PutStr(OutcomeKey, string(b.outcome))
+	}
+
+	if b.severity != "" {
+		lr.Attributes().PutStr(SeverityKey, string(b.severity))
+		lr.SetSeverityText(string(b.severity))
+		lr.SetSeverityNumber(severityNumbers[b.severity])
+	}
+
+	if b.errorReason != "" {
+		lr.Attributes().PutStr(ErrorReasonKey, b.errorReason)
+	}
+}
+
+// build creates the event with the constructor of its type, which validates the metadata
+// and the enum values.
+func (b *EventBuilder) build() (plog.Logs, error) {
+	md := b.metadata
+	objectID := b.str(ObjectIDKey)
+	dpp, _ := b.properties[DppKey].(bool)
+
+	switch b.eventType {
+	case KeyCreateEvent, KeyDeleteEvent, KeyRestoreEvent, KeyPurgeEvent, KeyRotateEvent, KeyEnableEvent, KeyDisableEvent:
+		m, err := newKeyEvent(b.eventType, md, objectID, b.str(SystemIDKey), b.str(CmkIDKey), b.keyType)
+		if err != nil {
+			return plog.Logs{}, err
+		}
+
+		return createEvent(m)
+	case WorkflowStartEvent:
+		return NewWorkflowStartEvent(md, objectID, b.str(ChannelIDKey), b.str(ChannelTypeKey), b.properties[ValueKey], dpp)
+	case WorkflowUpdateEvent:
+		return NewWorkflowUpdateEvent(md, objectID, b.properties[OldValueKey], b.properties[NewValueKey], dpp)
+	case WorkflowExecuteEvent:
+		return NewWorkflowExecuteEvent(md, objectID, b.str(ChannelIDKey), b.str(ChannelTypeKey), b.properties[ValueKey], dpp)
+	case WorkflowTerminateEvent:
+		return NewWorkflowTerminateEvent(md, objectID, b.str(ChannelIDKey), b.str(ChannelTypeKey), b.properties[ValueKey], dpp)
+	case GroupCreateEvent:
+		return NewGroupCreateEvent(md, objectID, b.properties[ValueKey], dpp)
+	case GroupReadEvent:
+		return NewGroupReadEvent(md, objectID, b.str(ChannelIDKey), b.str(ChannelTypeKey), b.properties[ValueKey], dpp)
+	case GroupUpdateEvent:
+		return NewGroupUpdateEvent(md, objectID, b.str(PropertyNameKey), b.properties[OldValueKey], b.properties[NewValueKey], dpp)
+	case GroupDeleteEvent:
+		return NewGroupDeleteEvent(md, objectID, b.properties[ValueKey], dpp)
+	case UserLoginSuccessEvent:
+		return NewUserLoginSuccessEvent(md, objectID, b.loginMethod, b.mfaType, b.userType, b.properties[ValueKey])
+	case UserLoginFailureEvent:
+		return NewUserLoginFailureEvent(md, objectID, b.loginMethod, b.failReason, b.properties[ValueKey])
+	case TenantOnboardingEvent:
+		return NewTenantOnboardingEvent(md, objectID)
+	case TenantOffboardingEvent:
+		return NewTenantOffboardingEvent(md, objectID)
+	case TenantUpdateEvent:
+		return NewTenantUpdateEvent(md, objectID, b.str(PropertyNameKey), b.properties[OldValueKey], b.properties[NewValueKey])
+	case ConfigCreateEvent:
+		return NewConfigurationCreateEvent(md, objectID, b.properties[ValueKey])
+	case ConfigReadEvent:
+		return NewConfigurationReadEvent(md, objectID, b.str(ChannelTypeKey), b.str(ChannelIDKey), b.properties[ValueKey])
+	case ConfigUpdateEvent:
+		return NewConfigurationUpdateEvent(md, objectID, b.properties[OldValueKey], b.properties[NewValueKey])
+	case ConfigDeleteEvent:
+		return NewConfigurationDeleteEvent(md, objectID, b.properties[ValueKey])
+	case CredentialCreateEvent:
+		return NewCredentialCreateEvent(md, objectID, b.credentialType)
+	case CredentialExpirationEvent:
+		return NewCredentialExpirationEvent(md, objectID, b.credentialType)
+	case CredentialRevokationEvent:
+		return NewCredentialRevokationEvent(md, objectID, b.credentialType)
+	case CredentialDeleteEvent:
+		return NewCredentialDeleteEvent(md, objectID, b.credentialType)
+	case CmkOnboardingEvent:
+		return NewCmkOnboardingEvent(md, objectID, b.str(SystemIDKey))
+	case CmkOffboardingEvent:
+		return NewCmkOffboardingEvent(md, objectID, b.str(SystemIDKey))
+	case CmkSwitchEvent:
+		return NewCmkSwitchEvent(md, objectID, b.str(CmkIDOldKey), b.str(CmkIDNewKey))
+	case CmkTenantModificationEvent:
+		return NewCmkTenantModificationEvent(md, objectID, b.str(SystemIDKey), b.cmkAction)
+	case UnauthorizedRequestEvent:
+		return NewUnauthorizedRequestEvent(md, b.str(ResourceKey), b.str(ActionKey))
+	case UnauthenticatedRequestEvent:
+		return NewUnauthenticatedRequestEvent(md)
+	case RoleAssignedEvent, RoleRevokedEvent:
+		return newEventWithAttribute(b.eventType, md, objectID, AssigneeIDKey, b.str(AssigneeIDKey))
+	case APIKeyCreateEvent, APIKeyRotateEvent, APIKeyRevokeEvent:
+		return newEventWithAttribute(b.eventType, md, objectID, OwnerIDKey, b.str(OwnerIDKey))
+	case DataExportRequestEvent, DataExportCompleteEvent, BackupCreateEvent, BackupRestoreEvent:
+		return newEventWithAttribute(b.eventType, md, objectID, ScopeKey, b.str(ScopeKey))
+	default:
+		// the CMK lifecycle events only refer to the CMK
+		return createEvent(newEventProperties(objectID, b.eventType, md))
+	}
+}
+
+func (b *EventBuilder) str(key string) string {
+	s, _ := b.properties[key].(string)
+	return s
+}
@@ -0,0 +1,198 @@
+package otlpaudit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestEventBuilder(t *testing.T) {
+	metadata := EventMetadata{
+		UserInitiatorIDKey:    "userInitiatorID",
+		TenantIDKey:           "tenantID",
+		EventCorrelationIDKey: "eventCorrelationID",
+	}
+
+	tests := []struct {
+		name       string
+		builder    *EventBuilder
+		wantErr    string
+		wantAttrs  map[string]string
+		wantObject string
+	}{
+		{
+			name: "T300_KeyCreate_Success",
+			builder: Event(KeyCreateEvent).Metadata(metadata).
+				Object("keyID").System("systemID").Cmk("cmkID").KeyType(KEYTYPE_DATA),
+			wantAttrs: map[string]string{
+				EventTypeKey:  KeyCreateEvent,
+				ObjectTypeKey: string(KEYTYPE_DATA),
+				SystemIDKey:   "systemID",
+				CmkIDKey:      "cmkID",
+			},
+			wantObject: "keyID",
+		},
+		{
+			name:    "T301_KeyCreate_MissingAttributes_Fail",
+			builder: Event(KeyCreateEvent).Metadata(metadata).Object("keyID"),
+			wantErr: "keyCreate event requires systemID, cmkID",
+		},
+		{
+			name:    "T302_KeyCreate_InvalidKeyType_Fail",
+			builder: Event(KeyCreateEvent).Metadata(metadata).Object("keyID").System("systemID").Cmk("cmkID").KeyType("unknown"),
+			wantErr: errEventCreation.Error(),
+		},
+		{
+			name:    "T303_MissingMetadata_Fail",
+			builder: Event(CmkCreateEvent).Object("cmkID"),
+			wantErr: "cmkCreate event requires userInitiatorID, tenantID",
+		},
+		{
+			name:    "T304_UnknownEventType_Fail",
+			builder: Event("keyExport").Metadata(metadata).Object("keyID"),
+			wantErr: `unknown event type "keyExport"`,
+		},
+		{
+			name: "T305_ConfigUpdate_Success",
+			builder: Event(ConfigUpdateEvent).Metadata(metadata).
+				Object("configID").Change("old", "new"),
+			wantAttrs: map[string]string{
+				OldValueKey:     "old",
+				NewValueKey:     "new",
+				PropertyNameKey: configPropertyName,
+			},
+			wantObject: "configID",
+		},
+		{
+			name: "T306_CmkSwitch_Success",
+			builder: Event(CmkSwitchEvent).Metadata(metadata).
+				Object("systemID").CmkSwitch("cmkOld", "cmkNew"),
+			wantAttrs: map[string]string{
+				CmkIDOldKey: "cmkOld",
+				CmkIDNewKey: "cmkNew",
+			},
+			wantObject: "systemID",
+		},
+		{
+			name:    "T307_UnauthorizedRequest_Success",
+			builder: Event(UnauthorizedRequestEvent).Metadata(metadata).Request("/keys", "delete"),
+			wantAttrs: map[string]string{
+				ResourceKey: "/keys",
+				ActionKey:   "delete",
+			},
+			wantObject: "userInitiatorID",
+		},
+		{
+			name:       "T308_CmkRotate_Success",
+			builder:    Event(CmkRotateEvent).Metadata(metadata).Object("cmkID"),
+			wantAttrs:  map[string]string{EventTypeKey: CmkRotateEvent},
+			wantObject: "cmkID",
+		},
+		{
+			name:       "T309_RoleAssigned_Success",
+			builder:    Event(RoleAssignedEvent).Metadata(metadata).Object("roleID").Assignee("groupID"),
+			wantAttrs:  map[string]string{AssigneeIDKey: "groupID"},
+			wantObject: "roleID",
+		},
+		{
+			name:       "T310_APIKeyRotate_Success",
+			builder:    Event(APIKeyRotateEvent).Metadata(metadata).Object("apiKeyID").Owner("userID"),
+			wantAttrs:  map[string]string{OwnerIDKey: "userID"},
+			wantObject: "apiKeyID",
+		},
+		{
+			name:    "T311_BackupRestore_MissingScope_Fail",
+			builder: Event(BackupRestoreEvent).Metadata(metadata).Object("backupID"),
+			wantErr: "backupRestore event requires scope",
+		},
+		{
+			name:       "T312_DataExportRequest_Success",
+			builder:    Event(DataExportRequestEvent).Metadata(metadata).Object("exportID").Scope("tenant keys"),
+			wantAttrs:  map[string]string{ScopeKey: "tenant keys"},
+			wantObject: "exportID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, err := tt.builder.Build()
+			if tt.wantErr != "" {
+				if !errors.Is(err, errEventCreation) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got '%v'", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			record, err := firstLogRecord(logs)
+			if err != nil {
+				t.Fatalf("firstLogRecord() error = %v", err)
+			}
+
+			if got, _ := record.Attributes().Get(ObjectIDKey); got.Str() != tt.wantObject {
+				t.Errorf("expected object %q, got %q", tt.wantObject, got.Str())
+			}
+
+			for key, want := range tt.wantAttrs {
+				if got, _ := record.Attributes().Get(key); got.Str() != want {
+					t.Errorf("expected %s %q, got %q", key, want, got.Str())
+				}
+			}
+		})
+	}
+}
+
+func TestEventBuilderOutcome(t *testing.T) {
+	metadata := EventMetadata{UserInitiatorIDKey: "userInitiatorID", TenantIDKey: "tenantID"}
+
+	t.Run("sets the outcome, severity and error reason", func(t *testing.T) {
+		logs, err := Event(KeyPurgeEvent).Metadata(metadata).
+			Object("keyID").System("systemID").Cmk("cmkID").
+			Outcome(OUTCOME_FAILURE).Severity(SEVERITY_ERROR).ErrorReason("key is in use").
+			Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+
+		record, _ := firstLogRecord(logs)
+
+		for key, want := range map[string]string{
+			OutcomeKey:     string(OUTCOME_FAILURE),
+			SeverityKey:    string(SEVERITY_ERROR),
+			ErrorReasonKey: "key is in use",
+		} {
+			if got, _ := record.Attributes().Get(key); got.Str() != want {
+				t.Errorf("expected %s %q, got %q", key, want, got.Str())
+			}
+		}
+
+		if record.SeverityNumber() != plog.SeverityNumberError || record.SeverityText() != string(SEVERITY_ERROR) {
+			t.Errorf("expected the error severity, got %v %q", record.SeverityNumber(), record.SeverityText())
+		}
+	})
+
+	t.Run("implies the outcome of denied requests", func(t *testing.T) {
+		logs, err := Event(UnauthenticatedRequestEvent).Metadata(metadata).Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+
+		record, _ := firstLogRecord(logs)
+		if got, _ := record.Attributes().Get(OutcomeKey); got.Str() != string(OUTCOME_DENIED) {
+			t.Errorf("expected the denied outcome, got %q", got.Str())
+		}
+	})
+
+	t.Run("rejects an unknown outcome", func(t *testing.T) {
+		_, err := Event(CmkCreateEvent).Metadata(metadata).Object("cmkID").Outcome("MAYBE").Build()
+		if !errors.Is(err, errEventCreation) {
+			t.Fatalf("expected errEventCreation, got '%v'", err)
+		}
+	})
+}
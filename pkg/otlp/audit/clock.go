@@ -0,0 +1,97 @@
+package otlpaudit
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock provides the timestamps of the events.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock, e.g. to return a fixed time in tests.
+type ClockFunc func() time.Time
+
+// Now returns the time of the function.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// IDGenerator generates unique IDs, e.g. uuid.NewString.
+type IDGenerator func() string
+
+// systemClock is the default clock returning the current time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// eventSettings control how the events are created, see ConfigureEvents.
+type eventSettings struct {
+	clock         Clock
+	eventID       IDGenerator
+	correlationID IDGenerator
+}
+
+var currentEventSettings atomic.Pointer[eventSettings]
+
+func init() {
+	currentEventSettings.Store(&eventSettings{clock: systemClock{}})
+}
+
+// EventOption configures how the events are created.
+type EventOption func(*eventSettings)
+
+// WithClock sets the clock providing the timestamps of the events. Defaults to the system clock.
+func WithClock(clock Clock) EventOption {
+	return func(s *eventSettings) {
+		if clock != nil {
+			s.clock = clock
+		}
+	}
+}
+
+// WithEventIDGenerator adds an ID generated by the generator to each event as EventIDKey.
+// The events have no ID by default.
+func WithEventIDGenerator(generator IDGenerator) EventOption {
+	return func(s *eventSettings) {
+		s.eventID = generator
+	}
+}
+
+// WithCorrelationIDGenerator sets the correlation ID of the events whose metadata has none
+// to an ID generated by the generator. The correlation ID is left empty by default.
+func WithCorrelationIDGenerator(generator IDGenerator) EventOption {
+	return func(s *eventSettings) {
+		s.correlationID = generator
+	}
+}
+
+// ConfigureEvents changes how the events of all event constructors and the EventBuilder are
+// created, e.g. with a fixed clock and deterministic IDs in tests or replay tooling:
+//
+//	restore := otlpaudit.ConfigureEvents(
+//	    otlpaudit.WithClock(otlpaudit.ClockFunc(func() time.Time { return fixed })),
+//	    otlpaudit.WithEventIDGenerator(func() string { return "event-1" }),
+//	)
+//	defer restore()
+//
+// The options apply on top of the current settings. It returns a function restoring
+// the previous settings. As the settings are global, tests changing them must not run
+// in parallel with other tests creating events.
+func ConfigureEvents(opts ...EventOption) (restore func()) {
+	previous := currentEventSettings.Load()
+
+	settings := *previous
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	currentEventSettings.Store(&settings)
+
+	return func() {
+		currentEventSettings.Store(previous)
+	}
+}
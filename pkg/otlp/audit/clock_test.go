@@ -0,0 +1,68 @@
+package otlpaudit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigureEvents(t *testing.T) {
+	fixed := time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC)
+	metadata := EventMetadata{UserInitiatorIDKey: "userInitiatorID", TenantIDKey: "tenantID"}
+
+	restore := ConfigureEvents(
+		WithClock(ClockFunc(func() time.Time { return fixed })),
+		WithEventIDGenerator(func() string { return "event-1" }),
+		WithCorrelationIDGenerator(func() string { return "correlation-1" }),
+	)
+
+	logs, err := NewCmkCreateEvent(metadata, "cmk1")
+	if err != nil {
+		t.Fatalf("NewCmkCreateEvent() error = %v", err)
+	}
+
+	restore()
+
+	record, _ := firstLogRecord(logs)
+	if !record.Timestamp().AsTime().Equal(fixed) {
+		t.Errorf("expected the timestamp %v, got %v", fixed, record.Timestamp().AsTime())
+	}
+
+	for key, want := range map[string]string{EventIDKey: "event-1", EventCorrelationIDKey: "correlation-1"} {
+		if got, _ := record.Attributes().Get(key); got.Str() != want {
+			t.Errorf("expected %s %q, got %q", key, want, got.Str())
+		}
+	}
+
+	t.Run("keeps the correlation ID of the metadata", func(t *testing.T) {
+		restore := ConfigureEvents(WithCorrelationIDGenerator(func() string { return "generated" }))
+		defer restore()
+
+		logs, err := NewCmkCreateEvent(EventMetadata{
+			UserInitiatorIDKey: "userInitiatorID", TenantIDKey: "tenantID", EventCorrelationIDKey: "request",
+		}, "cmk1")
+		if err != nil {
+			t.Fatalf("NewCmkCreateEvent() error = %v", err)
+		}
+
+		record, _ := firstLogRecord(logs)
+		if got, _ := record.Attributes().Get(EventCorrelationIDKey); got.Str() != "request" {
+			t.Errorf("expected the correlation ID of the metadata, got %q", got.Str())
+		}
+	})
+
+	t.Run("restores the defaults", func(t *testing.T) {
+		logs, err := NewCmkCreateEvent(metadata, "cmk1")
+		if err != nil {
+			t.Fatalf("NewCmkCreateEvent() error = %v", err)
+		}
+
+		record, _ := firstLogRecord(logs)
+		if _, ok := record.Attributes().Get(EventIDKey); ok {
+			t.Errorf("expected no event ID by default")
+		}
+
+		if record.Timestamp().AsTime().Equal(fixed) {
+			t.Errorf("expected the current time by default")
+		}
+	})
+}
@@ -3,13 +3,21 @@ package otlpaudit
 import (
 	"bytes"
 	"context"
+	"maps"
 	"net/http"
+	"slices"
 
 	"github.com/samber/oops"
 	"go.opentelemetry.io/collector/pdata/plog"
 )
 
+// send sends the OTLP JSON payload.
 func (o *otlpClient) send(ctx context.Context, payload string) error {
+	return o.sendAs(ctx, otlpContentType, payload)
+}
+
+// sendAs sends the payload with the given content type.
+func (o *otlpClient) sendAs(ctx context.Context, contentType, payload string) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.Endpoint, bytes.NewBufferString(payload))
 	if err != nil {
 		return oops.In(domain).
@@ -17,7 +25,7 @@ func (o *otlpClient) send(ctx context.Context, payload string) error {
 			Wrap(err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := o.Client.Do(req)
@@ -40,42 +48,89 @@ func (o *otlpClient) send(ctx context.Context, payload string) error {
 }
 
 func (auditLogger *AuditLogger) SendEvent(ctx context.Context, logs plog.Logs) error {
-	err := auditLogger.enrichLogs(&logs)
+	payload, err := auditLogger.payload(ctx, logs)
+	if err != nil {
+		return err
+	}
+
+	err = auditLogger.client.send(ctx, payload)
 	if err != nil {
 		return oops.In(domain).
-			Hint("enrich failed").
+			Hint("failed to send audit logs").
 			Wrap(err)
 	}
 
-	marshaller := plog.JSONMarshaler{}
+	return nil
+}
 
-	marshaledLogs, err := marshaller.MarshalLogs(logs)
+// payload enriches the logs, truncates their large values and marshals them into the OTLP JSON payload.
+func (auditLogger *AuditLogger) payload(ctx context.Context, logs plog.Logs) (string, error) {
+	err := auditLogger.prepare(ctx, logs)
 	if err != nil {
-		return oops.In(domain).
-			Hint("failed to marshal audit logs").
-			Wrap(err)
+		return "", err
+	}
+
+	err = auditLogger.link(logs)
+	if err != nil {
+		return "", err
 	}
 
-	err = auditLogger.client.send(ctx, string(marshaledLogs))
+	return marshalLogs(logs)
+}
+
+// prepare enriches the logs, masks the personal data of their values and truncates their
+// large values. The logs are linked into the hash chain when they are sent, see link.
+func (auditLogger *AuditLogger) prepare(ctx context.Context, logs plog.Logs) error {
+	err := auditLogger.enrichLogs(&logs)
 	if err != nil {
 		return oops.In(domain).
-			Hint("failed to send audit logs").
+			Hint("enrich failed").
 			Wrap(err)
 	}
 
+	if auditLogger.masker != nil {
+		maskLogs(auditLogger.masker, logs)
+	}
+
+	auditLogger.limiter.limitLogs(ctx, logs)
+
 	return nil
 }
 
+func marshalLogs(logs plog.Logs) (string, error) {
+	marshaller := plog.JSONMarshaler{}
+
+	marshaledLogs, err := marshaller.MarshalLogs(logs)
+	if err != nil {
+		return "", oops.In(domain).
+			Hint("failed to marshal audit logs").
+			Wrap(err)
+	}
+
+	return string(marshaledLogs), nil
+}
+
+// enrichLogs adds the additional properties to all log records, e.g. of an EventBatch.
+// The attributes of the events take precedence over the additional properties of the
+// same name, and structured properties keep their structure, see putValue.
 func (auditLogger *AuditLogger) enrichLogs(logs *plog.Logs) error {
-	logRecord, err := firstLogRecord(*logs)
+	_, err := firstLogRecord(*logs)
 	if err != nil {
 		return oops.In(domain).
 			Hint("failed to find audit record log").
 			Wrap(err)
 	}
 
-	for k, v := range auditLogger.additionalProps {
-		logRecord.Attributes().PutStr(k, v)
+	for _, rl := range logs.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				for _, k := range slices.Sorted(maps.Keys(auditLogger.additionalProps)) {
+					if _, ok := lr.Attributes().Get(k); !ok {
+						putValue(lr.Attributes(), k, auditLogger.additionalProps[k])
+					}
+				}
+			}
+		}
 	}
 
 	return nil
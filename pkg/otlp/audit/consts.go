@@ -47,10 +47,20 @@ const (
 	CmkUnavailableEvent         = "cmkUnavailable"
 	UnauthorizedRequestEvent    = "unauthorizedRequest"
 	UnauthenticatedRequestEvent = "unauthenticatedRequest"
+	RoleAssignedEvent           = "roleAssigned"
+	RoleRevokedEvent            = "roleRevoked"
+	APIKeyCreateEvent           = "apiKeyCreate"
+	APIKeyRotateEvent           = "apiKeyRotate"
+	APIKeyRevokeEvent           = "apiKeyRevoke"
+	DataExportRequestEvent      = "dataExportRequest"
+	DataExportCompleteEvent     = "dataExportComplete"
+	BackupCreateEvent           = "backupCreate"
+	BackupRestoreEvent          = "backupRestore"
 )
 
 const (
 	EventTypeKey          = "eventType"
+	EventIDKey            = "eventID"
 	ObjectIDKey           = "objectID"
 	ObjectTypeKey         = "objectType"
 	ActionTypeKey         = "actionType"
@@ -75,6 +85,25 @@ const (
 	CmkIDNewKey           = "cmkIDNew"
 	ResourceKey           = "resource"
 	ActionKey             = "action"
+	AssigneeIDKey         = "assigneeID"
+	OwnerIDKey            = "ownerID"
+	ScopeKey              = "scope"
+	// SourceIPKey, UserAgentKey and SessionIDKey are the optional origin of the event, which
+	// are added to the events if they are set in the EventMetadata.
+	SourceIPKey  = "sourceIP"
+	UserAgentKey = "userAgent"
+	SessionIDKey = "sessionID"
+	// OutcomeKey distinguishes successful from failed or denied operations, see [Outcome].
+	OutcomeKey = "outcome"
+	// SeverityKey is the [Severity] of the event, which also sets the severity of the log record.
+	SeverityKey = "severity"
+	// ErrorReasonKey describes why a failed or denied operation did not succeed.
+	ErrorReasonKey = "errorReason"
+	// PrevHashKey and HashKey link the events into a hash chain, see [VerifyHashChain].
+	PrevHashKey = "prevHash"
+	HashKey     = "hash"
+	// TruncatedAttributesKey lists the attributes whose values were truncated, separated by commas.
+	TruncatedAttributesKey = "truncatedAttributes"
 )
 
 const (
@@ -0,0 +1,42 @@
+package otlpaudit
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// DeadLetterHandler receives the events whose export exhausted the retries of the
+// AuditSender, together with the error of the last attempt, e.g. to store them in a
+// database or forward them to a fallback queue. The events are counted as dropped if
+// it returns an error.
+type DeadLetterHandler func(ctx context.Context, logs plog.Logs, cause error) error
+
+// WithDeadLetterHandler sets the handler of the events whose export failed. It takes
+// precedence over the dead-letter directory of the AuditSender configuration.
+func WithDeadLetterHandler(handler DeadLetterHandler) LoggerOption {
+	return func(auditLogger *AuditLogger) {
+		if handler != nil {
+			auditLogger.deadLetter = handler
+		}
+	}
+}
+
+// deadLetter hands the events of a failed export to the dead-letter handler or writes them
+// to the dead-letter directory as OTLP JSON. It returns false if the events are lost.
+func (s *AuditSender) deadLetter(ctx context.Context, logs plog.Logs, cause error) bool {
+	if s.logger.deadLetter != nil {
+		return s.logger.deadLetter(ctx, logs, cause) == nil
+	}
+
+	if s.cfg.DeadLetterDir == "" {
+		return false
+	}
+
+	payload, err := marshalLogs(logs)
+	if err != nil {
+		return false
+	}
+
+	return writeSpoolFile(s.cfg.DeadLetterDir, payload) == nil
+}
@@ -0,0 +1,227 @@
+package otlpaudit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// newMeteredSender creates a sender recording its metrics in the returned reader.
+func newMeteredSender(t *testing.T, endpoint string, cfg commoncfg.AuditSender,
+	opts ...LoggerOption,
+) (*AuditSender, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	sender, err := NewSender(&commoncfg.Audit{
+		Endpoint:   endpoint,
+		HTTPClient: commoncfg.HTTPClient{Timeout: time.Second},
+		Sender:     cfg,
+	}, append(opts, WithMeterProvider(provider))...)
+	if err != nil {
+		t.Fatalf("NewSender() error = %v", err)
+	}
+
+	t.Cleanup(func() { _ = sender.Close(t.Context()) })
+
+	return sender, reader
+}
+
+// sendAndFlush sends the number of events and returns the error of their export.
+func sendAndFlush(t *testing.T, sender *AuditSender, events int) error {
+	t.Helper()
+
+	for range events {
+		err := sender.Send(t.Context(), newTestLogs())
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	return sender.Flush(t.Context())
+}
+
+// metricValue returns the sum of the data points of the counter or gauge.
+func metricValue(t *testing.T, reader *sdkmetric.ManualReader, name string) int64 {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+
+	err := reader.Collect(t.Context(), &rm)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	var total int64
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					total += dp.Value
+				}
+			case metricdata.Gauge[int64]:
+				for _, dp := range data.DataPoints {
+					total += dp.Value
+				}
+			}
+		}
+	}
+
+	return total
+}
+
+func TestAuditSenderDeadLetter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := commoncfg.AuditSender{MaxRetries: 1, MinBackoff: time.Millisecond}
+
+	t.Run("hands the failed events to the handler", func(t *testing.T) {
+		var (
+			received plog.Logs
+			cause    error
+		)
+
+		sender, reader := newMeteredSender(t, server.URL, cfg,
+			WithDeadLetterHandler(func(_ context.Context, logs plog.Logs, err error) error {
+				received, cause = logs, err
+				return nil
+			}))
+
+		err := sendAndFlush(t, sender, 2)
+		if err == nil {
+			t.Fatal("expected the export to fail")
+		}
+
+		if received.LogRecordCount() != 2 || cause == nil {
+			t.Errorf("expected 2 dead-lettered records with the cause, got %d and %v", received.LogRecordCount(), cause)
+		}
+
+		if failed := metricValue(t, reader, failedEventsMetric); failed != 2 {
+			t.Errorf("expected 2 failed events, got %d", failed)
+		}
+
+		if dropped := metricValue(t, reader, droppedEventsMetric); dropped != 0 {
+			t.Errorf("expected no dropped events, got %d", dropped)
+		}
+	})
+
+	t.Run("writes the failed events to the directory", func(t *testing.T) {
+		dir := t.TempDir()
+
+		cfg := cfg
+		cfg.DeadLetterDir = dir
+
+		sender, _ := newMeteredSender(t, server.URL, cfg)
+
+		err := sendAndFlush(t, sender, 1)
+		if err == nil {
+			t.Fatal("expected the export to fail")
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, "*"+spoolFileSuffix))
+		if err != nil || len(files) != 1 {
+			t.Fatalf("expected one dead-letter file, got %v (%v)", files, err)
+		}
+
+		data, err := os.ReadFile(files[0])
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+
+		logs, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(data)
+		if err != nil || logs.LogRecordCount() != 1 {
+			t.Errorf("expected the dead-lettered event as OTLP JSON, got %d records (%v)", logs.LogRecordCount(), err)
+		}
+	})
+
+	t.Run("drops the failed events without a sink", func(t *testing.T) {
+		sender, reader := newMeteredSender(t, server.URL, cfg)
+
+		err := sendAndFlush(t, sender, 1)
+		if err == nil {
+			t.Fatal("expected the export to fail")
+		}
+
+		if failed := metricValue(t, reader, failedEventsMetric); failed != 1 {
+			t.Errorf("expected 1 failed event, got %d", failed)
+		}
+
+		if dropped := metricValue(t, reader, droppedEventsMetric); dropped != 1 {
+			t.Errorf("expected 1 dropped event, got %d", dropped)
+		}
+	})
+}
+
+func TestAuditSenderMetrics(t *testing.T) {
+	received := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender, reader := newMeteredSender(t, server.URL, commoncfg.AuditSender{BatchSize: 1})
+
+	err := sender.Send(t.Context(), newTestLogs())
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// the worker blocks in the export of the first event, so the next events stay queued
+	<-received
+
+	for range 2 {
+		err = sender.Send(t.Context(), newTestLogs())
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	if depth := metricValue(t, reader, queueDepthMetric); depth != 2 {
+		t.Errorf("expected a queue depth of 2, got %d", depth)
+	}
+
+	close(release)
+
+	err = sender.Flush(t.Context())
+	if err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if sent := metricValue(t, reader, sentEventsMetric); sent != 3 {
+		t.Errorf("expected 3 sent events, got %d", sent)
+	}
+
+	if depth := metricValue(t, reader, queueDepthMetric); depth != 0 {
+		t.Errorf("expected an empty queue, got %d", depth)
+	}
+}
@@ -0,0 +1,183 @@
+package otlpaudit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/oops"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+const (
+	otlpContentType        = "application/json"
+	cloudEventsContentType = "application/cloudevents-batch+json"
+	jsonContentType        = "application/json"
+
+	cloudEventsSpecVersion = "1.0"
+	// cloudEventsTypePrefix prefixes the event type of the CloudEvents, e.g. org.openkcm.audit.keyCreate
+	cloudEventsTypePrefix = "org.openkcm.audit."
+	// defCloudEventsSource is the source of the CloudEvents of resources without a service name
+	defCloudEventsSource = "urn:openkcm:audit"
+	serviceNameKey       = "service.name"
+)
+
+// encoder renders a batch of audit events into the payload of an export request.
+type encoder interface {
+	contentType() string
+	encode(logs plog.Logs) (string, error)
+}
+
+func newEncoder(encoding commoncfg.AuditEncoding) (encoder, error) {
+	switch encoding {
+	case "", commoncfg.AuditOTLPEncoding:
+		return otlpEncoder{}, nil
+	case commoncfg.AuditCloudEventsEncoding:
+		return cloudEventsEncoder{}, nil
+	case commoncfg.AuditJSONEncoding:
+		return jsonEncoder{}, nil
+	default:
+		return nil, oops.In(domain).
+			With("encoding", encoding).
+			Wrap(errUnknownEncoding)
+	}
+}
+
+// otlpEncoder renders the events as OTLP logs in JSON.
+type otlpEncoder struct{}
+
+func (otlpEncoder) contentType() string {
+	return otlpContentType
+}
+
+func (otlpEncoder) encode(logs plog.Logs) (string, error) {
+	return marshalLogs(logs)
+}
+
+// cloudEvent is the JSON format of a CloudEvent 1.0 with the attributes of the event as data.
+type cloudEvent struct {
+	SpecVersion     string         `json:"specversion"`
+	ID              string         `json:"id"`
+	Source          string         `json:"source"`
+	Type            string         `json:"type"`
+	Subject         string         `json:"subject,omitempty"`
+	Time            time.Time      `json:"time"`
+	DataContentType string         `json:"datacontenttype"`
+	Data            map[string]any `json:"data"`
+}
+
+// cloudEventsEncoder renders the events as a JSON batch of CloudEvents. The source is the
+// service name of the resource, the subject the object ID and the ID is derived from the
+// content of the event, so an event exported twice can be deduplicated.
+type cloudEventsEncoder struct{}
+
+func (cloudEventsEncoder) contentType() string {
+	return cloudEventsContentType
+}
+
+func (cloudEventsEncoder) encode(logs plog.Logs) (string, error) {
+	events := make([]cloudEvent, 0, logs.LogRecordCount())
+
+	err := eachLogRecord(logs, func(resource pcommon.Resource, lr plog.LogRecord) error {
+		canonical, err := canonicalRecord(lr)
+		if err != nil {
+			return err
+		}
+
+		source := defCloudEventsSource
+		if name, ok := resource.Attributes().Get(serviceNameKey); ok && name.AsString() != "" {
+			source = name.AsString()
+		}
+
+		eventType, _ := lr.Attributes().Get(EventTypeKey)
+		objectID, _ := lr.Attributes().Get(ObjectIDKey)
+
+		events = append(events, cloudEvent{
+			SpecVersion:     cloudEventsSpecVersion,
+			ID:              uuid.NewSHA1(uuid.NameSpaceOID, canonical).String(),
+			Source:          source,
+			Type:            cloudEventsTypePrefix + eventType.AsString(),
+			Subject:         objectID.AsString(),
+			Time:            lr.Timestamp().AsTime(),
+			DataContentType: jsonContentType,
+			Data:            lr.Attributes().AsRaw(),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return marshalEvents(events)
+}
+
+// jsonEvent is the plain JSON format of an event.
+type jsonEvent struct {
+	Timestamp  time.Time      `json:"timestamp"`
+	Severity   string         `json:"severity,omitempty"`
+	Body       any            `json:"body,omitempty"`
+	Attributes map[string]any `json:"attributes"`
+	Resource   map[string]any `json:"resource,omitempty"`
+}
+
+// jsonEncoder renders the events as a JSON array of plain objects with the attributes and
+// resource attributes of the events.
+type jsonEncoder struct{}
+
+func (jsonEncoder) contentType() string {
+	return jsonContentType
+}
+
+func (jsonEncoder) encode(logs plog.Logs) (string, error) {
+	events := make([]jsonEvent, 0, logs.LogRecordCount())
+
+	_ = eachLogRecord(logs, func(resource pcommon.Resource, lr plog.LogRecord) error {
+		event := jsonEvent{
+			Timestamp:  lr.Timestamp().AsTime(),
+			Severity:   lr.SeverityText(),
+			Body:       lr.Body().AsRaw(),
+			Attributes: lr.Attributes().AsRaw(),
+		}
+
+		if resource.Attributes().Len() > 0 {
+			event.Resource = resource.Attributes().AsRaw()
+		}
+
+		events = append(events, event)
+
+		return nil
+	})
+
+	return marshalEvents(events)
+}
+
+func marshalEvents[T any](events []T) (string, error) {
+	payload, err := json.Marshal(events)
+	if err != nil {
+		return "", oops.In(domain).
+			Hint("failed to marshal audit logs").
+			Wrap(err)
+	}
+
+	return string(payload), nil
+}
+
+// eachLogRecord calls fn with the log records of all ResourceLogs and ScopeLogs and their resource.
+func eachLogRecord(logs plog.Logs, fn func(pcommon.Resource, plog.LogRecord) error) error {
+	for _, rl := range logs.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				err := fn(rl.Resource(), lr)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
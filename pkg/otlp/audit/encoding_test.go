@@ -0,0 +1,127 @@
+package otlpaudit
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func newEncodingTestLogs(t *testing.T) plog.Logs {
+	t.Helper()
+
+	metadata, _ := NewEventMetadata("user", "tenant", "correlation")
+
+	logs, err := NewCmkCreateEvent(metadata, "cmk1")
+	if err != nil {
+		t.Fatalf("NewCmkCreateEvent() error = %v", err)
+	}
+
+	logs.ResourceLogs().At(0).Resource().Attributes().PutStr(serviceNameKey, "keystore")
+
+	return logs
+}
+
+func TestCloudEventsEncoder(t *testing.T) {
+	enc, err := newEncoder(commoncfg.AuditCloudEventsEncoding)
+	if err != nil {
+		t.Fatalf("newEncoder() error = %v", err)
+	}
+
+	logs := newEncodingTestLogs(t)
+
+	payload, err := enc.encode(logs)
+	if err != nil {
+		t.Fatalf("encode() error = %v", err)
+	}
+
+	var events []cloudEvent
+
+	err = json.Unmarshal([]byte(payload), &events)
+	if err != nil {
+		t.Fatalf("failed to unmarshal the payload: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	event := events[0]
+	if event.SpecVersion != "1.0" || event.Source != "keystore" || event.Subject != "cmk1" ||
+		event.Type != "org.openkcm.audit.cmkCreate" || event.ID == "" {
+		t.Errorf("unexpected CloudEvent %+v", event)
+	}
+
+	if event.Data[TenantIDKey] != "tenant" {
+		t.Errorf("expected the attributes as data, got %v", event.Data)
+	}
+
+	again, _ := enc.encode(logs)
+	if again != payload {
+		t.Errorf("expected the same id for the same event")
+	}
+}
+
+func TestJSONEncoder(t *testing.T) {
+	enc, err := newEncoder(commoncfg.AuditJSONEncoding)
+	if err != nil {
+		t.Fatalf("newEncoder() error = %v", err)
+	}
+
+	payload, err := enc.encode(newEncodingTestLogs(t))
+	if err != nil {
+		t.Fatalf("encode() error = %v", err)
+	}
+
+	var events []jsonEvent
+
+	err = json.Unmarshal([]byte(payload), &events)
+	if err != nil {
+		t.Fatalf("failed to unmarshal the payload: %v", err)
+	}
+
+	if len(events) != 1 || events[0].Attributes[ObjectIDKey] != "cmk1" || events[0].Resource[serviceNameKey] != "keystore" {
+		t.Errorf("unexpected events %+v", events)
+	}
+}
+
+func TestNewEncoderUnknown(t *testing.T) {
+	_, err := newEncoder("xml")
+	if !errors.Is(err, errUnknownEncoding) {
+		t.Fatalf("expected errUnknownEncoding, got '%v'", err)
+	}
+}
+
+func TestAuditSenderEncoding(t *testing.T) {
+	received := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r.Header.Get("Content-Type") + " " + string(body[:1])
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newTestSender(t, server.URL, commoncfg.AuditSender{Encoding: commoncfg.AuditCloudEventsEncoding})
+
+	err := sender.Send(t.Context(), newEncodingTestLogs(t))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	err = sender.Flush(t.Context())
+	if err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if got := <-received; got != cloudEventsContentType+" [" {
+		t.Errorf("expected a batch of CloudEvents, got %q", got)
+	}
+}
@@ -12,6 +12,8 @@ type FailReason string
 type CredentialType string
 type KeyType string
 type CmkAction string
+type Outcome string
+type Severity string
 
 const (
 	KEYTYPE_SYSTEM  KeyType = "SYSTEM"
@@ -71,6 +73,19 @@ const (
 	CMKACTION_RESTORE      CmkAction = "RESTORE"
 )
 
+const (
+	OUTCOME_SUCCESS Outcome = "SUCCESS"
+	OUTCOME_FAILURE Outcome = "FAILURE"
+	OUTCOME_DENIED  Outcome = "DENIED"
+)
+
+const (
+	SEVERITY_INFO     Severity = "INFO"
+	SEVERITY_WARNING  Severity = "WARNING"
+	SEVERITY_ERROR    Severity = "ERROR"
+	SEVERITY_CRITICAL Severity = "CRITICAL"
+)
+
 const UNSPECIFIED = "UNSPECIFIED"
 
 func (l KeyType) IsValid() bool {
@@ -120,3 +135,11 @@ func (c CredentialType) IsValid() bool {
 func (c CmkAction) IsValid() bool {
 	return isOneOf(c, CMKACTION_ONBOARD, CMKACTION_BLOCK, CMKACTION_SHUTDOWN, CMKACTION_CSEKFALLBACK, CMKACTION_RESTORE, "")
 }
+
+func (o Outcome) IsValid() bool {
+	return isOneOf(o, OUTCOME_SUCCESS, OUTCOME_FAILURE, OUTCOME_DENIED, "")
+}
+
+func (s Severity) IsValid() bool {
+	return isOneOf(s, SEVERITY_INFO, SEVERITY_WARNING, SEVERITY_ERROR, SEVERITY_CRITICAL, "")
+}
@@ -7,3 +7,20 @@ import (
 var domain = "audit-logger:otlp"
 var errEventCreation = errors.New("event creation failed")
 var errNoLogRecord = errors.New("no log record present in the plog.Logs struct")
+
+// ErrAuditNotConfirmed is returned by MustAudit if the event could neither be exported nor spooled.
+var ErrAuditNotConfirmed = errors.New("audit event not confirmed")
+var errSpoolDirMissing = errors.New("spool directory is required by the spool failure policy")
+var errUnknownEncoding = errors.New("unknown audit encoding")
+var errUnknownTransport = errors.New("unknown audit transport")
+var errUnsupportedSecretType = errors.New("unsupported secret type of the audit transport")
+var errUnsupportedSASLMechanism = errors.New("unsupported SASL mechanism of the kafka transport")
+
+// ErrAuditQueueFull is returned by AuditSender.Send if the queue of the events to export is full.
+var ErrAuditQueueFull = errors.New("audit event queue is full")
+
+// ErrHashChainBroken is returned by VerifyHashChain if an event was altered, removed or reordered.
+var ErrHashChainBroken = errors.New("audit hash chain is broken")
+
+// ErrAuditSenderClosed is returned by the AuditSender once it is closed.
+var ErrAuditSenderClosed = errors.New("audit sender is closed")
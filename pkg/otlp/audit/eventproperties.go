@@ -1,6 +1,7 @@
 package otlpaudit
 
 import (
+	"maps"
 	"reflect"
 	"slices"
 )
@@ -24,16 +25,12 @@ func hasValues(values ...any) bool {
 	return !slices.ContainsFunc(values, isZeroVal[any])
 }
 
-func isZeroVal[T comparable](v T) bool {
-	valueType := reflect.TypeOf(v)
-	if valueType == nil {
-		return true
-	}
-
-	zeroValue := reflect.Zero(valueType).Interface()
-	comparableZero, _ := zeroValue.(T)
+// isZeroVal reports if the value is nil or the zero value of its type. Unlike a comparison
+// with the zero value, it does not panic on maps, slices and other uncomparable values.
+func isZeroVal[T any](v T) bool {
+	value := reflect.ValueOf(v)
 
-	return v == comparableZero
+	return !value.IsValid() || value.IsZero()
 }
 
 func NewEventMetadata(userInitiatorID, tenantID, eventCorrelationID string) (EventMetadata, error) {
@@ -48,6 +45,27 @@ func NewEventMetadata(userInitiatorID, tenantID, eventCorrelationID string) (Eve
 	}, nil
 }
 
+// WithOrigin returns a copy of the metadata with the optional origin of the event, i.e.
+// the source IP, user agent and session ID. Empty values are not set.
+func (m EventMetadata) WithOrigin(sourceIP, userAgent, sessionID string) EventMetadata {
+	origin := maps.Clone(m)
+	if origin == nil {
+		origin = EventMetadata{}
+	}
+
+	for key, value := range map[string]string{
+		SourceIPKey:  sourceIP,
+		UserAgentKey: userAgent,
+		SessionIDKey: sessionID,
+	} {
+		if value != "" {
+			origin[key] = value
+		}
+	}
+
+	return origin
+}
+
 func newEventProperties(objectID, eventType string, eventMetadata EventMetadata) eventProperties {
 	return eventProperties{
 		ObjectIDKey:           objectID,
@@ -55,5 +73,8 @@ func newEventProperties(objectID, eventType string, eventMetadata EventMetadata)
 		UserInitiatorIDKey:    eventMetadata[UserInitiatorIDKey],
 		TenantIDKey:           eventMetadata[TenantIDKey],
 		EventCorrelationIDKey: eventMetadata[EventCorrelationIDKey],
+		SourceIPKey:           eventMetadata[SourceIPKey],
+		UserAgentKey:          eventMetadata[UserAgentKey],
+		SessionIDKey:          eventMetadata[SessionIDKey],
 	}
 }
@@ -63,3 +63,33 @@ func TestNewEventMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestEventMetadataWithOrigin(t *testing.T) {
+	metadata := EventMetadata{UserInitiatorIDKey: "userInitiatorID", TenantIDKey: "tenantID"}
+
+	origin := metadata.WithOrigin("10.0.0.1", "", "session")
+	if _, ok := metadata[SourceIPKey]; ok {
+		t.Errorf("expected the metadata to be unchanged, got %v", metadata)
+	}
+
+	if _, ok := origin[UserAgentKey]; ok {
+		t.Errorf("expected no empty user agent, got %v", origin)
+	}
+
+	logs, err := NewUserLoginSuccessEvent(origin, "userID", LOGINMETHOD_OPENIDCONNECT, MFATYPE_NONE, USERTYPE_BUSINESS, nil)
+	if err != nil {
+		t.Fatalf("NewUserLoginSuccessEvent() error = %v", err)
+	}
+
+	record, _ := firstLogRecord(logs)
+
+	for key, want := range map[string]string{SourceIPKey: "10.0.0.1", SessionIDKey: "session"} {
+		if got, _ := record.Attributes().Get(key); got.Str() != want {
+			t.Errorf("expected %s %q, got %q", key, want, got.Str())
+		}
+	}
+
+	if _, ok := record.Attributes().Get(UserAgentKey); ok {
+		t.Errorf("expected no user agent attribute")
+	}
+}
@@ -2,7 +2,6 @@ package otlpaudit
 
 import (
 	"fmt"
-	"time"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
@@ -455,6 +454,65 @@ func NewUnauthenticatedRequestEvent(metadata EventMetadata) (plog.Logs, error) {
 	return createEvent(m)
 }
 
+// NewRoleAssignedEvent creates the event of a role assigned to a user or group.
+func NewRoleAssignedEvent(metadata EventMetadata, roleID, assigneeID string) (plog.Logs, error) {
+	return newEventWithAttribute(RoleAssignedEvent, metadata, roleID, AssigneeIDKey, assigneeID)
+}
+
+// NewRoleRevokedEvent creates the event of a role revoked from a user or group.
+func NewRoleRevokedEvent(metadata EventMetadata, roleID, assigneeID string) (plog.Logs, error) {
+	return newEventWithAttribute(RoleRevokedEvent, metadata, roleID, AssigneeIDKey, assigneeID)
+}
+
+// NewAPIKeyCreateEvent creates the event of an API key created for its owner.
+func NewAPIKeyCreateEvent(metadata EventMetadata, apiKeyID, ownerID string) (plog.Logs, error) {
+	return newEventWithAttribute(APIKeyCreateEvent, metadata, apiKeyID, OwnerIDKey, ownerID)
+}
+
+// NewAPIKeyRotateEvent creates the event of a rotated API key.
+func NewAPIKeyRotateEvent(metadata EventMetadata, apiKeyID, ownerID string) (plog.Logs, error) {
+	return newEventWithAttribute(APIKeyRotateEvent, metadata, apiKeyID, OwnerIDKey, ownerID)
+}
+
+// NewAPIKeyRevokeEvent creates the event of a revoked API key.
+func NewAPIKeyRevokeEvent(metadata EventMetadata, apiKeyID, ownerID string) (plog.Logs, error) {
+	return newEventWithAttribute(APIKeyRevokeEvent, metadata, apiKeyID, OwnerIDKey, ownerID)
+}
+
+// NewDataExportRequestEvent creates the event of a requested data export, the scope
+// describes the exported data, e.g. the keys of a tenant.
+func NewDataExportRequestEvent(metadata EventMetadata, exportID, scope string) (plog.Logs, error) {
+	return newEventWithAttribute(DataExportRequestEvent, metadata, exportID, ScopeKey, scope)
+}
+
+// NewDataExportCompleteEvent creates the event of a completed data export.
+func NewDataExportCompleteEvent(metadata EventMetadata, exportID, scope string) (plog.Logs, error) {
+	return newEventWithAttribute(DataExportCompleteEvent, metadata, exportID, ScopeKey, scope)
+}
+
+// NewBackupCreateEvent creates the event of a created backup, the scope describes the
+// data in the backup.
+func NewBackupCreateEvent(metadata EventMetadata, backupID, scope string) (plog.Logs, error) {
+	return newEventWithAttribute(BackupCreateEvent, metadata, backupID, ScopeKey, scope)
+}
+
+// NewBackupRestoreEvent creates the event of a restored backup.
+func NewBackupRestoreEvent(metadata EventMetadata, backupID, scope string) (plog.Logs, error) {
+	return newEventWithAttribute(BackupRestoreEvent, metadata, backupID, ScopeKey, scope)
+}
+
+// newEventWithAttribute creates an event of the type with a single required attribute.
+func newEventWithAttribute(eventType string, metadata EventMetadata, objectID, key, value string) (plog.Logs, error) {
+	if !hasValues(value) {
+		return plog.Logs{}, errEventCreation
+	}
+
+	m := newEventProperties(objectID, eventType, metadata)
+	m[key] = value
+
+	return createEvent(m)
+}
+
 func newKeyEvent(keyEventType string, metadata EventMetadata, objectID string, systemID string, cmkID string, t KeyType) (eventProperties, error) {
 	if !hasValues(systemID, cmkID) || !t.IsValid() {
 		return nil, errEventCreation
@@ -468,6 +526,14 @@ func newKeyEvent(keyEventType string, metadata EventMetadata, objectID string, s
 	return m, nil
 }
 
+// defaultOutcomes are the outcomes of the event types which imply one.
+var defaultOutcomes = map[string]Outcome{
+	UserLoginSuccessEvent:       OUTCOME_SUCCESS,
+	UserLoginFailureEvent:       OUTCOME_FAILURE,
+	UnauthorizedRequestEvent:    OUTCOME_DENIED,
+	UnauthenticatedRequestEvent: OUTCOME_DENIED,
+}
+
 func unspecifiedIfEmpty(input string) string {
 	if input == "" {
 		return UNSPECIFIED
@@ -484,8 +550,24 @@ func createEvent(properties eventProperties) (plog.Logs, error) {
 	logs := plog.NewLogs()
 	lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
 
+	settings := currentEventSettings.Load()
+
 	lr.SetEventName(fmt.Sprint(properties[ObjectIDKey]))
-	lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(settings.clock.Now()))
+
+	if settings.eventID != nil {
+		properties[EventIDKey] = settings.eventID()
+	}
+
+	if settings.correlationID != nil && !properties.hasValues(EventCorrelationIDKey) {
+		properties[EventCorrelationIDKey] = settings.correlationID()
+	}
+
+	if !properties.hasValues(OutcomeKey) {
+		if outcome, ok := defaultOutcomes[fmt.Sprint(properties[EventTypeKey])]; ok {
+			properties[OutcomeKey] = string(outcome)
+		}
+	}
 
 	lr.Attributes().PutStr(EventTypeKey, fmt.Sprint(properties[EventTypeKey]))
 	lr.Attributes().PutStr(ObjectIDKey, fmt.Sprint(properties[ObjectIDKey]))
@@ -493,6 +575,7 @@ func createEvent(properties eventProperties) (plog.Logs, error) {
 	lr.Attributes().PutStr(TenantIDKey, fmt.Sprint(properties[TenantIDKey]))
 
 	addAttributesForKeys(properties, &lr,
+		EventIDKey,
 		EventCorrelationIDKey,
 		ObjectTypeKey,
 		PropertyNameKey,
@@ -514,6 +597,13 @@ func createEvent(properties eventProperties) (plog.Logs, error) {
 		ValueKey,
 		ResourceKey,
 		ActionKey,
+		AssigneeIDKey,
+		OwnerIDKey,
+		ScopeKey,
+		OutcomeKey,
+		SourceIPKey,
+		UserAgentKey,
+		SessionIDKey,
 	)
 
 	return logs, nil
@@ -522,7 +612,7 @@ func createEvent(properties eventProperties) (plog.Logs, error) {
 func addAttributesForKeys(properties eventProperties, lr *plog.LogRecord, keys ...string) {
 	for _, key := range keys {
 		if properties.hasValues(key) {
-			lr.Attributes().PutStr(key, fmt.Sprint(properties[key]))
+			putValue(lr.Attributes(), key, properties[key])
 		}
 	}
 }
@@ -3,6 +3,8 @@ package otlpaudit
 import (
 	"strings"
 	"testing"
+
+	"go.opentelemetry.io/collector/pdata/plog"
 )
 
 func TestNewConfigurationCreateEvent(t *testing.T) {
@@ -2172,3 +2174,50 @@ func TestRequestEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestEventsWithAttribute(t *testing.T) {
+	metadata := EventMetadata{
+		UserInitiatorIDKey:    "userInitiatorID",
+		TenantIDKey:           "tenantID",
+		EventCorrelationIDKey: "eventCorrelationID",
+	}
+
+	tests := []struct {
+		name        string
+		constructor func(EventMetadata, string, string) (plog.Logs, error)
+		key         string
+	}{
+		{name: "T3100_NewRoleAssignedEvent", constructor: NewRoleAssignedEvent, key: AssigneeIDKey},
+		{name: "T3101_NewRoleRevokedEvent", constructor: NewRoleRevokedEvent, key: AssigneeIDKey},
+		{name: "T3102_NewAPIKeyCreateEvent", constructor: NewAPIKeyCreateEvent, key: OwnerIDKey},
+		{name: "T3103_NewAPIKeyRotateEvent", constructor: NewAPIKeyRotateEvent, key: OwnerIDKey},
+		{name: "T3104_NewAPIKeyRevokeEvent", constructor: NewAPIKeyRevokeEvent, key: OwnerIDKey},
+		{name: "T3105_NewDataExportRequestEvent", constructor: NewDataExportRequestEvent, key: ScopeKey},
+		{name: "T3106_NewDataExportCompleteEvent", constructor: NewDataExportCompleteEvent, key: ScopeKey},
+		{name: "T3107_NewBackupCreateEvent", constructor: NewBackupCreateEvent, key: ScopeKey},
+		{name: "T3108_NewBackupRestoreEvent", constructor: NewBackupRestoreEvent, key: ScopeKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, err := tt.constructor(metadata, "objectID", "value")
+			if err != nil {
+				t.Fatalf("constructor error = %v", err)
+			}
+
+			record, _ := firstLogRecord(logs)
+			if got, _ := record.Attributes().Get(tt.key); got.Str() != "value" {
+				t.Errorf("expected %s %q, got %q", tt.key, "value", got.Str())
+			}
+
+			_, err = tt.constructor(metadata, "objectID", "")
+			if err == nil {
+				t.Errorf("expected an error without %s", tt.key)
+			}
+
+			_, err = tt.constructor(metadata, "", "value")
+			if err == nil {
+				t.Errorf("expected an error without the object ID")
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package otlpaudit
+
+import (
+	"math/rand/v2"
+	"slices"
+
+	"github.com/samber/oops"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// mandatoryEventTypes are the event types which are never dropped by the filter of the
+// AuditSender, as security reviews rely on them.
+var mandatoryEventTypes = []string{
+	UserLoginFailureEvent,
+	UnauthorizedRequestEvent,
+	UnauthenticatedRequestEvent,
+	KeyDeleteEvent,
+	KeyPurgeEvent,
+	CmkDeleteEvent,
+	CmkTenantDeleteEvent,
+	TenantOffboardingEvent,
+	CredentialRevokationEvent,
+	CredentialDeleteEvent,
+	RoleAssignedEvent,
+	RoleRevokedEvent,
+	APIKeyRevokeEvent,
+	DataExportRequestEvent,
+}
+
+// readEventTypes are the event types dropped by the DropReads filter.
+var readEventTypes = []string{
+	ConfigReadEvent,
+	GroupReadEvent,
+}
+
+// eventFilter drops the events of the AuditSender which are not needed, see commoncfg.AuditFilter.
+type eventFilter struct {
+	dropReads   bool
+	sampleRates map[string]int
+	tenants     []string
+}
+
+func newEventFilter(cfg commoncfg.AuditFilter) (*eventFilter, error) {
+	for eventType := range cfg.SampleRates {
+		if !IsKnownEventType(eventType) {
+			return nil, oops.In(domain).
+				With("event_type", eventType).
+				Errorf("unknown event type %q in the sample rates", eventType)
+		}
+	}
+
+	return &eventFilter{
+		dropReads:   cfg.DropReads,
+		sampleRates: cfg.SampleRates,
+		tenants:     cfg.Tenants,
+	}, nil
+}
+
+// filter removes the dropped log records and returns their number.
+func (f *eventFilter) filter(logs plog.Logs) int {
+	dropped := 0
+
+	for _, rl := range logs.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				drop := !f.keep(lr)
+				if drop {
+					dropped++
+				}
+
+				return drop
+			})
+		}
+	}
+
+	return dropped
+}
+
+func (f *eventFilter) keep(lr plog.LogRecord) bool {
+	eventType, _ := lr.Attributes().Get(EventTypeKey)
+	if slices.Contains(mandatoryEventTypes, eventType.Str()) {
+		return true
+	}
+
+	outcome, _ := lr.Attributes().Get(OutcomeKey)
+	if isOneOf(Outcome(outcome.Str()), OUTCOME_FAILURE, OUTCOME_DENIED) {
+		return true
+	}
+
+	if f.dropReads && slices.Contains(readEventTypes, eventType.Str()) {
+		return false
+	}
+
+	tenantID, _ := lr.Attributes().Get(TenantIDKey)
+	if len(f.tenants) > 0 && !slices.Contains(f.tenants, tenantID.Str()) {
+		return false
+	}
+
+	rate, ok := f.sampleRates[eventType.Str()]
+
+	return !ok || rand.IntN(100) < rate
+}
@@ -0,0 +1,135 @@
+package otlpaudit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestEventFilter(t *testing.T) {
+	metadata := EventMetadata{UserInitiatorIDKey: "userInitiatorID", TenantIDKey: "tenant1"}
+	other := EventMetadata{UserInitiatorIDKey: "userInitiatorID", TenantIDKey: "tenant2"}
+
+	newBatch := func(t *testing.T) plog.Logs {
+		t.Helper()
+
+		batch := NewEventBatch()
+		for _, event := range []func() (plog.Logs, error){
+			func() (plog.Logs, error) {
+				return NewConfigurationReadEvent(metadata, "config", "channelType", "channelID", "value")
+			},
+			func() (plog.Logs, error) { return NewCmkRotateEvent(metadata, "cmk1") },
+			func() (plog.Logs, error) { return NewCmkEnableEvent(other, "cmk2") },
+			func() (plog.Logs, error) { return NewKeyPurgeEvent(other, "key", "system", "cmk2", KEYTYPE_DATA) },
+			func() (plog.Logs, error) {
+				return Event(CmkDisableEvent).Metadata(other).Object("cmk2").Outcome(OUTCOME_FAILURE).Build()
+			},
+		} {
+			err := batch.Add(event())
+			if err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+		}
+
+		return batch.Logs()
+	}
+
+	tests := []struct {
+		name string
+		cfg  commoncfg.AuditFilter
+		want []string
+	}{
+		{
+			name: "keeps all events by default",
+			want: []string{ConfigReadEvent, CmkRotateEvent, CmkEnableEvent, KeyPurgeEvent, CmkDisableEvent},
+		},
+		{
+			name: "drops read events",
+			cfg:  commoncfg.AuditFilter{DropReads: true},
+			want: []string{CmkRotateEvent, CmkEnableEvent, KeyPurgeEvent, CmkDisableEvent},
+		},
+		{
+			name: "samples event types",
+			cfg:  commoncfg.AuditFilter{SampleRates: map[string]int{CmkRotateEvent: 0, CmkEnableEvent: 100}},
+			want: []string{ConfigReadEvent, CmkEnableEvent, KeyPurgeEvent, CmkDisableEvent},
+		},
+		{
+			name: "keeps the allowed tenants and mandatory events",
+			cfg:  commoncfg.AuditFilter{Tenants: []string{"tenant1"}},
+			want: []string{ConfigReadEvent, CmkRotateEvent, KeyPurgeEvent, CmkDisableEvent},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newEventFilter(tt.cfg)
+			if err != nil {
+				t.Fatalf("newEventFilter() error = %v", err)
+			}
+
+			logs := newBatch(t)
+			dropped := filter.filter(logs)
+
+			var got []string
+
+			for _, lr := range logRecords(logs) {
+				eventType, _ := lr.Attributes().Get(EventTypeKey)
+				got = append(got, eventType.Str())
+			}
+
+			if len(got) != len(tt.want) || dropped != 5-len(tt.want) {
+				t.Fatalf("expected %v, got %v with %d dropped", tt.want, got, dropped)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewEventFilterUnknownEventType(t *testing.T) {
+	_, err := newEventFilter(commoncfg.AuditFilter{SampleRates: map[string]int{"keyCopy": 10}})
+	if err == nil {
+		t.Fatal("expected an error for the unknown event type")
+	}
+}
+
+func TestAuditSenderFilter(t *testing.T) {
+	var counter recordCounter
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter.count(t, r)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := newTestSender(t, server.URL, commoncfg.AuditSender{Filter: commoncfg.AuditFilter{DropReads: true}})
+
+	metadata := EventMetadata{UserInitiatorIDKey: "userInitiatorID", TenantIDKey: "tenantID"}
+
+	event, err := NewGroupReadEvent(metadata, "group", "channelID", "channelType", "value", false)
+	if err != nil {
+		t.Fatalf("NewGroupReadEvent() error = %v", err)
+	}
+
+	err = sender.Send(t.Context(), event)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	err = sender.Flush(t.Context())
+	if err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if counter.requests.Load() != 0 {
+		t.Errorf("expected the read event to be dropped, got %d requests", counter.requests.Load())
+	}
+}
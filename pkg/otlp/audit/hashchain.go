@@ -0,0 +1,193 @@
+package otlpaudit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"sync"
+
+	"github.com/samber/oops"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// hashChain links the log records of the sent events: each record gets the hash of the
+// previous record as prevHash and its own hash, computed over the record including its
+// prevHash. The chain starts with an empty prevHash when the logger is created.
+//
+// The AuditSender queues the events in the order of the chain, and events it cannot queue
+// do not advance the chain. Events which are not stored, e.g. because their export failed,
+// leave a gap in the chain, as if they were deleted from the audit store. Concurrent
+// synchronous sends, e.g. with SendEvent, may reach the audit store in a different order
+// than the chain.
+type hashChain struct {
+	mu   sync.Mutex
+	key  []byte
+	prev string
+}
+
+func newHashChain(cfg commoncfg.AuditHashChain) (*hashChain, error) {
+	chain := &hashChain{}
+
+	if cfg.HMACKey != nil {
+		key, err := commoncfg.LoadValueFromSourceRef(*cfg.HMACKey)
+		if err != nil {
+			return nil, oops.In(domain).
+				Hint("failed to load the HMAC key of the audit hash chain").
+				Wrap(err)
+		}
+
+		chain.key = key
+	}
+
+	return chain, nil
+}
+
+// link links the logs into the hash chain, if it is enabled.
+func (auditLogger *AuditLogger) link(logs plog.Logs) error {
+	_, err := auditLogger.linkAndCommit(logs, func() (bool, error) { return true, nil })
+	return err
+}
+
+// linkAndCommit links the logs into the hash chain, if it is enabled, and hands them on,
+// e.g. queues them, with commit while holding the chain, so the events are handed on in
+// the order of the chain. It returns the result of commit.
+func (auditLogger *AuditLogger) linkAndCommit(logs plog.Logs, commit func() (bool, error)) (bool, error) {
+	if auditLogger.hashChain == nil {
+		return commit()
+	}
+
+	return auditLogger.hashChain.linkAndCommit(logs, commit)
+}
+
+// linkAndCommit sets the prevHash and hash of the log records in their order and calls
+// commit. The chain only advances if commit reports that the records were handed on.
+func (c *hashChain) linkAndCommit(logs plog.Logs, commit func() (bool, error)) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev := c.prev
+
+	for _, lr := range logRecords(logs) {
+		lr.Attributes().PutStr(PrevHashKey, prev)
+
+		sum, err := recordHash(c.key, lr)
+		if err != nil {
+			return false, oops.In(domain).
+				Hint("failed to link audit logs into the hash chain").
+				Wrap(err)
+		}
+
+		lr.Attributes().PutStr(HashKey, sum)
+		prev = sum
+	}
+
+	committed, err := commit()
+	if committed {
+		c.prev = prev
+	}
+
+	return committed, err
+}
+
+// VerifyHashChain verifies the hash chain of the log records in their order, e.g. read back
+// from the audit store. prevHash is the hash of the record before the first one, or empty
+// if the first record starts the chain, and key is the HMAC key of the chain, if any.
+// It returns the hash of the last record, so the chain can be verified in several parts,
+// or an error wrapping ErrHashChainBroken.
+//
+// The hash is the hex encoded SHA-256, or HMAC-SHA256 with a key, of the JSON object with
+// the timestamp in nanoseconds, severityNumber, severityText, body and attributes of the
+// record, without the hash attribute. The keys of the object are sorted.
+func VerifyHashChain(logs plog.Logs, prevHash string, key []byte) (string, error) {
+	for i, lr := range logRecords(logs) {
+		prev, _ := lr.Attributes().Get(PrevHashKey)
+		if prev.AsString() != prevHash {
+			return "", oops.In(domain).
+				With("record", i).
+				Wrapf(ErrHashChainBroken, "unexpected previous hash")
+		}
+
+		sum, err := recordHash(key, lr)
+		if err != nil {
+			return "", err
+		}
+
+		got, _ := lr.Attributes().Get(HashKey)
+		if !hmac.Equal([]byte(got.AsString()), []byte(sum)) {
+			return "", oops.In(domain).
+				With("record", i).
+				Wrapf(ErrHashChainBroken, "hash mismatch")
+		}
+
+		prevHash = sum
+	}
+
+	return prevHash, nil
+}
+
+// chainedRecord is the canonical form of a log record the hash is computed over.
+type chainedRecord struct {
+	Timestamp      uint64         `json:"timestamp"`
+	SeverityNumber int32          `json:"severityNumber"`
+	SeverityText   string         `json:"severityText"`
+	Body           any            `json:"body"`
+	Attributes     map[string]any `json:"attributes"`
+}
+
+// canonicalRecord marshals the log record without its hash into its canonical form.
+func canonicalRecord(lr plog.LogRecord) ([]byte, error) {
+	attributes := lr.Attributes().AsRaw()
+	delete(attributes, HashKey)
+
+	canonical, err := json.Marshal(chainedRecord{
+		Timestamp:      uint64(lr.Timestamp()),
+		SeverityNumber: int32(lr.SeverityNumber()),
+		SeverityText:   lr.SeverityText(),
+		Body:           lr.Body().AsRaw(),
+		Attributes:     attributes,
+	})
+	if err != nil {
+		return nil, oops.In(domain).
+			Hint("failed to marshal the audit log record").
+			Wrap(err)
+	}
+
+	return canonical, nil
+}
+
+func recordHash(key []byte, lr plog.LogRecord) (string, error) {
+	canonical, err := canonicalRecord(lr)
+	if err != nil {
+		return "", err
+	}
+
+	var h hash.Hash
+	if len(key) > 0 {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+
+	h.Write(canonical)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// logRecords returns the log records of all ResourceLogs and ScopeLogs in their order.
+func logRecords(logs plog.Logs) []plog.LogRecord {
+	records := make([]plog.LogRecord, 0, logs.LogRecordCount())
+
+	for _, rl := range logs.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				records = append(records, lr)
+			}
+		}
+	}
+
+	return records
+}
@@ -0,0 +1,259 @@
+package otlpaudit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func newChainedEvents(t *testing.T, hashChain commoncfg.AuditHashChain) plog.Logs {
+	t.Helper()
+
+	auditLogger, err := NewLogger(&commoncfg.Audit{
+		Endpoint:   "http://localhost:1234/logs",
+		HTTPClient: commoncfg.HTTPClient{Timeout: time.Second},
+		HashChain:  hashChain,
+	})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	metadata, _ := NewEventMetadata("user", "tenant", "correlation")
+
+	batch := NewEventBatch()
+
+	for _, cmkID := range []string{"cmk1", "cmk2", "cmk3"} {
+		event, err := NewCmkCreateEvent(metadata, cmkID)
+		if err != nil {
+			t.Fatalf("NewCmkCreateEvent() error = %v", err)
+		}
+
+		err = auditLogger.prepare(t.Context(), event)
+		if err != nil {
+			t.Fatalf("prepare() error = %v", err)
+		}
+
+		err = auditLogger.link(event)
+		if err != nil {
+			t.Fatalf("link() error = %v", err)
+		}
+
+		_ = batch.Add(event, nil)
+	}
+
+	return batch.Logs()
+}
+
+func TestHashChain(t *testing.T) {
+	t.Run("links the events", func(t *testing.T) {
+		logs := newChainedEvents(t, commoncfg.AuditHashChain{Enabled: true})
+
+		last, err := VerifyHashChain(logs, "", nil)
+		if err != nil {
+			t.Fatalf("VerifyHashChain() error = %v", err)
+		}
+
+		records := logRecords(logs)
+
+		hash, _ := records[2].Attributes().Get(HashKey)
+		if last != hash.Str() {
+			t.Errorf("expected the hash of the last event %q, got %q", hash.Str(), last)
+		}
+
+		prev, _ := records[1].Attributes().Get(PrevHashKey)
+		if first, _ := records[0].Attributes().Get(HashKey); prev.Str() != first.Str() {
+			t.Errorf("expected the previous hash %q, got %q", first.Str(), prev.Str())
+		}
+	})
+
+	t.Run("detects altered events", func(t *testing.T) {
+		logs := newChainedEvents(t, commoncfg.AuditHashChain{Enabled: true})
+		logRecords(logs)[1].Attributes().PutStr(ObjectIDKey, "cmk4")
+
+		_, err := VerifyHashChain(logs, "", nil)
+		if !errors.Is(err, ErrHashChainBroken) {
+			t.Fatalf("expected ErrHashChainBroken, got '%v'", err)
+		}
+	})
+
+	t.Run("detects removed events", func(t *testing.T) {
+		logs := newChainedEvents(t, commoncfg.AuditHashChain{Enabled: true})
+		removed := logRecords(logs)[1]
+		logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+			return lr == removed
+		})
+
+		_, err := VerifyHashChain(logs, "", nil)
+		if !errors.Is(err, ErrHashChainBroken) {
+			t.Fatalf("expected ErrHashChainBroken, got '%v'", err)
+		}
+	})
+
+	t.Run("keys the hashes with the HMAC key", func(t *testing.T) {
+		logs := newChainedEvents(t, commoncfg.AuditHashChain{
+			Enabled: true,
+			HMACKey: &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "secret"},
+		})
+
+		_, err := VerifyHashChain(logs, "", []byte("secret"))
+		if err != nil {
+			t.Fatalf("VerifyHashChain() error = %v", err)
+		}
+
+		_, err = VerifyHashChain(logs, "", nil)
+		if !errors.Is(err, ErrHashChainBroken) {
+			t.Fatalf("expected ErrHashChainBroken without the key, got '%v'", err)
+		}
+	})
+
+	t.Run("is disabled by default", func(t *testing.T) {
+		logs := newChainedEvents(t, commoncfg.AuditHashChain{})
+
+		if _, ok := logRecords(logs)[0].Attributes().Get(HashKey); ok {
+			t.Errorf("expected no hash attribute")
+		}
+	})
+}
+
+// blockingTransport blocks the sends until release is closed.
+type blockingTransport struct {
+	recordingTransport
+
+	sending chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingTransport) Send(ctx context.Context, contentType string, payload []byte) error {
+	select {
+	case b.sending <- struct{}{}:
+	default:
+	}
+
+	<-b.release
+
+	return b.recordingTransport.Send(ctx, contentType, payload)
+}
+
+// verifyExportedHashChain verifies the hash chain of the exported events in the order of their
+// export and returns their number.
+func verifyExportedHashChain(t *testing.T, payloads chan []byte) int {
+	t.Helper()
+
+	close(payloads)
+
+	prevHash, exported := "", 0
+
+	for payload := range payloads {
+		logs, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(payload)
+		if err != nil {
+			t.Fatalf("UnmarshalLogs() error = %v", err)
+		}
+
+		prevHash, err = VerifyHashChain(logs, prevHash, nil)
+		if err != nil {
+			t.Fatalf("VerifyHashChain() of the exported events error = %v", err)
+		}
+
+		exported += logs.LogRecordCount()
+	}
+
+	return exported
+}
+
+func TestAuditSenderHashChainConcurrentSend(t *testing.T) {
+	const events = 100
+
+	transport := &recordingTransport{payloads: make(chan []byte, events)}
+
+	sender, err := NewSender(&commoncfg.Audit{
+		Endpoint:  "http://localhost:1234/logs",
+		HashChain: commoncfg.AuditHashChain{Enabled: true},
+		Sender:    commoncfg.AuditSender{BatchSize: 7},
+	}, WithTransport(transport))
+	if err != nil {
+		t.Fatalf("NewSender() error = %v", err)
+	}
+
+	start := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range events {
+		wg.Go(func() {
+			<-start
+
+			if err := sender.Send(t.Context(), newTestLogs()); err != nil {
+				t.Errorf("Send() error = %v", err)
+			}
+		})
+	}
+
+	close(start)
+	wg.Wait()
+
+	err = sender.Close(t.Context())
+	if err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if exported := verifyExportedHashChain(t, transport.payloads); exported != events {
+		t.Errorf("expected %d exported events, got %d", events, exported)
+	}
+}
+
+func TestAuditSenderHashChainQueueFull(t *testing.T) {
+	transport := &blockingTransport{
+		recordingTransport: recordingTransport{payloads: make(chan []byte, 3)},
+		sending:            make(chan struct{}, 1),
+		release:            make(chan struct{}),
+	}
+
+	sender, err := NewSender(&commoncfg.Audit{
+		Endpoint:  "http://localhost:1234/logs",
+		HashChain: commoncfg.AuditHashChain{Enabled: true},
+		Sender:    commoncfg.AuditSender{QueueSize: 1, BatchSize: 1},
+	}, WithTransport(transport))
+	if err != nil {
+		t.Fatalf("NewSender() error = %v", err)
+	}
+
+	// the first event blocks the worker, the second one fills the queue
+	if err := sender.Send(t.Context(), newTestLogs()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	<-transport.sending
+
+	if err := sender.Send(t.Context(), newTestLogs()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	err = sender.Send(t.Context(), newTestLogs())
+	if !errors.Is(err, ErrAuditQueueFull) {
+		t.Fatalf("expected ErrAuditQueueFull, got '%v'", err)
+	}
+
+	close(transport.release)
+
+	if err := sender.Flush(t.Context()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	// the dropped event does not leave a gap before the next one
+	if err := sender.Send(t.Context(), newTestLogs()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if err := sender.Close(t.Context()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if exported := verifyExportedHashChain(t, transport.payloads); exported != 3 {
+		t.Errorf("expected 3 exported events, got %d", exported)
+	}
+}
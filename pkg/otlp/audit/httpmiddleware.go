@@ -0,0 +1,179 @@
+package otlpaudit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// SendFunc sends an audit event, e.g. AuditSender.Send or AuditLogger.SendEvent.
+type SendFunc func(ctx context.Context, logs plog.Logs) error
+
+// RequestEventFunc creates the audit event of a request which was neither rejected as
+// unauthenticated nor as unauthorized, e.g. a key deletion audited for all DELETE requests
+// on keys. It returns false if the request is not audited.
+type RequestEventFunc func(r *http.Request, status int, metadata EventMetadata) (plog.Logs, bool)
+
+// HTTPMiddlewareOption configures the middleware created by NewHTTPMiddleware.
+type HTTPMiddlewareOption func(*httpMiddleware)
+
+// WithRequestEvents sets the function creating the audit events of the other requests.
+// Only the rejected requests are audited by default.
+func WithRequestEvents(fn RequestEventFunc) HTTPMiddlewareOption {
+	return func(m *httpMiddleware) {
+		m.requestEvent = fn
+	}
+}
+
+// WithHTTPMetadataKeys sets the request headers the event metadata is read from.
+func WithHTTPMetadataKeys(opts ...MetadataOption) HTTPMiddlewareOption {
+	return func(m *httpMiddleware) {
+		m.keys = newMetadataKeys(opts)
+	}
+}
+
+// WithSendErrorHandler sets the handler of the events which could not be created or sent.
+// The errors are logged with the default logger by default.
+func WithSendErrorHandler(handler func(r *http.Request, err error)) HTTPMiddlewareOption {
+	return func(m *httpMiddleware) {
+		if handler != nil {
+			m.onError = handler
+		}
+	}
+}
+
+type httpMiddleware struct {
+	send         SendFunc
+	requestEvent RequestEventFunc
+	keys         metadataKeys
+	onError      func(r *http.Request, err error)
+}
+
+// NewHTTPMiddleware creates net/http middleware emitting the audit events of the requests
+// once they are handled, based on their response status:
+//
+//   - 401 Unauthorized emits an UnauthenticatedRequestEvent
+//   - 403 Forbidden emits an UnauthorizedRequestEvent with the path as resource and the
+//     method as action
+//   - all other requests emit the event created by the RequestEventFunc set with
+//     WithRequestEvents, if any
+//
+// The event metadata is read from the request headers as by EventMetadataFromRequest.
+// As rejected requests often lack the user or tenant, they are set to UNSPECIFIED if missing.
+// The events are sent with the given function, which should not block the response, e.g.:
+//
+//	handler = otlpaudit.NewHTTPMiddleware(sender.Send)(handler)
+func NewHTTPMiddleware(send SendFunc, opts ...HTTPMiddlewareOption) func(http.Handler) http.Handler {
+	m := &httpMiddleware{
+		send: send,
+		keys: newMetadataKeys(nil),
+		onError: func(r *http.Request, err error) {
+			slog.ErrorContext(r.Context(), "failed to send the audit event of the request",
+				slog.String("method", r.Method), slog.String("path", r.URL.Path), slog.Any("error", err))
+		},
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m.wrap
+}
+
+func (m *httpMiddleware) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(recorder, r)
+
+		m.audit(r, recorder.statusCode())
+	})
+}
+
+// audit creates and sends the event of the handled request.
+func (m *httpMiddleware) audit(r *http.Request, status int) {
+	metadata := m.metadata(r)
+
+	var (
+		logs plog.Logs
+		err  error
+	)
+
+	switch status {
+	case http.StatusUnauthorized:
+		logs, err = NewUnauthenticatedRequestEvent(metadata)
+	case http.StatusForbidden:
+		logs, err = NewUnauthorizedRequestEvent(metadata, r.URL.Path, r.Method)
+	default:
+		if m.requestEvent == nil {
+			return
+		}
+
+		var ok bool
+
+		logs, ok = m.requestEvent(r, status, metadata)
+		if !ok {
+			return
+		}
+	}
+
+	if err == nil {
+		// the event is sent after the response, when the client may already be gone
+		err = m.send(context.WithoutCancel(r.Context()), logs)
+	}
+
+	if err != nil {
+		m.onError(r, err)
+	}
+}
+
+// metadata reads the event metadata from the request headers.
+func (m *httpMiddleware) metadata(r *http.Request) EventMetadata {
+	metadata := EventMetadata{
+		UserInitiatorIDKey:    unspecifiedIfEmpty(r.Header.Get(m.keys.userInitiatorID)),
+		TenantIDKey:           unspecifiedIfEmpty(r.Header.Get(m.keys.tenantID)),
+		EventCorrelationIDKey: r.Header.Get(m.keys.eventCorrelationID),
+	}
+
+	return metadata.WithOrigin(hostOf(r.RemoteAddr), r.UserAgent(), r.Header.Get(m.keys.sessionID))
+}
+
+// statusRecorder records the status of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	// informational responses precede the final status
+	if s.status == 0 && status >= http.StatusOK {
+		s.status = status
+	}
+
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+
+	return s.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController can flush the response.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// statusCode returns the recorded status, 200 if the handler wrote no response.
+func (s *statusRecorder) statusCode() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+
+	return s.status
+}
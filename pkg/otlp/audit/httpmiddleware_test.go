@@ -0,0 +1,133 @@
+package otlpaudit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		headers       map[string]string
+		requestEvents bool
+		wantEvent     string
+		wantAttrs     map[string]string
+	}{
+		{
+			name:      "unauthenticated request",
+			status:    http.StatusUnauthorized,
+			wantEvent: UnauthenticatedRequestEvent,
+			wantAttrs: map[string]string{UserInitiatorIDKey: UNSPECIFIED, TenantIDKey: UNSPECIFIED, SourceIPKey: "192.0.2.1"},
+		},
+		{
+			name:      "unauthorized request",
+			status:    http.StatusForbidden,
+			headers:   map[string]string{DefUserInitiatorIDKey: "user", DefTenantIDKey: "tenant"},
+			wantEvent: UnauthorizedRequestEvent,
+			wantAttrs: map[string]string{UserInitiatorIDKey: "user", ResourceKey: "/keys/key1", ActionKey: http.MethodDelete},
+		},
+		{
+			name:   "other requests are not audited by default",
+			status: http.StatusNoContent,
+		},
+		{
+			name:          "request event",
+			status:        http.StatusNoContent,
+			headers:       map[string]string{DefUserInitiatorIDKey: "user", DefTenantIDKey: "tenant"},
+			requestEvents: true,
+			wantEvent:     KeyDeleteEvent,
+			wantAttrs:     map[string]string{ObjectIDKey: "key1", TenantIDKey: "tenant"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []plog.Logs
+
+			send := func(_ context.Context, logs plog.Logs) error {
+				sent = append(sent, logs)
+				return nil
+			}
+
+			var opts []HTTPMiddlewareOption
+			if tt.requestEvents {
+				opts = append(opts, WithRequestEvents(func(_ *http.Request, status int, metadata EventMetadata) (plog.Logs, bool) {
+					if status != http.StatusNoContent {
+						return plog.Logs{}, false
+					}
+
+					logs, err := NewKeyDeleteEvent(metadata, "key1", "system", "cmk", KEYTYPE_DATA)
+
+					return logs, err == nil
+				}))
+			}
+
+			handler := NewHTTPMiddleware(send, opts...)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+
+			req := httptest.NewRequest(http.MethodDelete, "/keys/key1", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("expected the status %d, got %d", tt.status, rec.Code)
+			}
+
+			if tt.wantEvent == "" {
+				if len(sent) != 0 {
+					t.Errorf("expected no event, got %d", len(sent))
+				}
+
+				return
+			}
+
+			if len(sent) != 1 {
+				t.Fatalf("expected one event, got %d", len(sent))
+			}
+
+			record, _ := firstLogRecord(sent[0])
+
+			eventType, _ := record.Attributes().Get(EventTypeKey)
+			if eventType.Str() != tt.wantEvent {
+				t.Errorf("expected the event %s, got %s", tt.wantEvent, eventType.Str())
+			}
+
+			for key, want := range tt.wantAttrs {
+				got, _ := record.Attributes().Get(key)
+				if got.AsString() != want {
+					t.Errorf("expected %s = %q, got %q", key, want, got.AsString())
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPMiddlewareSendError(t *testing.T) {
+	sendErr := errors.New("collector unavailable")
+
+	var got error
+
+	handler := NewHTTPMiddleware(
+		func(context.Context, plog.Logs) error { return sendErr },
+		WithSendErrorHandler(func(_ *http.Request, err error) { got = err }),
+	)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/keys", nil))
+
+	if !errors.Is(got, sendErr) {
+		t.Errorf("expected the send error, got %v", got)
+	}
+}
@@ -0,0 +1,140 @@
+package otlpaudit
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/samber/oops"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// kafkaTimeout bounds the connection and each produce request to the Kafka brokers
+const kafkaTimeout = 10 * time.Second
+
+// kafkaTransport produces the events to a Kafka topic, one message per batch of the
+// AuditSender with the content type as header. A send returns once all in-sync replicas
+// acknowledged the message; it is not retried by the producer, the AuditSender retries it.
+type kafkaTransport struct {
+	writer *kafka.Writer
+}
+
+func newKafkaTransport(cfg commoncfg.AuditKafka) (*kafkaTransport, error) {
+	transport := &kafka.Transport{
+		ClientID:    auditInstrumentationID,
+		DialTimeout: kafkaTimeout,
+	}
+
+	if cfg.TLS {
+		transport.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if cfg.SecretRef != nil {
+		switch cfg.SecretRef.Type {
+		case commoncfg.InsecureSecretType:
+		case commoncfg.MTLSSecretType:
+			tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.SecretRef.MTLS)
+			if err != nil {
+				return nil, oops.In(domain).
+					Hint("failed to load the Kafka TLS configuration").
+					Wrap(err)
+			}
+
+			transport.TLS = tlsConfig
+		case commoncfg.BasicSecretType:
+			mechanism, err := kafkaSASLMechanism(cfg.SASLMechanism, &cfg.SecretRef.Basic)
+			if err != nil {
+				return nil, err
+			}
+
+			transport.SASL = mechanism
+		default:
+			return nil, oops.In(domain).
+				With("type", cfg.SecretRef.Type).
+				Wrap(errUnsupportedSecretType)
+		}
+	}
+
+	return &kafkaTransport{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Topic:        cfg.Topic,
+			Transport:    transport,
+			RequiredAcks: kafka.RequireAll,
+			MaxAttempts:  1,
+			// each send is a batch of events, it must not wait for further messages
+			BatchSize:    1,
+			WriteTimeout: kafkaTimeout,
+		},
+	}, nil
+}
+
+// kafkaSASLMechanism returns the SASL mechanism authenticating with the basic credentials.
+func kafkaSASLMechanism(mechanism commoncfg.AuditKafkaSASLMechanism, basic *commoncfg.BasicAuth) (sasl.Mechanism, error) {
+	username, err := commoncfg.LoadValueFromSourceRef(basic.Username)
+	if err != nil {
+		return nil, oops.In(domain).
+			Hint("failed to load the Kafka username").
+			Wrap(err)
+	}
+
+	password, err := commoncfg.LoadValueFromSourceRef(basic.Password)
+	if err != nil {
+		return nil, oops.In(domain).
+			Hint("failed to load the Kafka password").
+			Wrap(err)
+	}
+
+	user, pass := strings.TrimSpace(string(username)), strings.TrimSpace(string(password))
+
+	var algorithm scram.Algorithm
+
+	switch mechanism {
+	case "", commoncfg.AuditKafkaSASLPlain:
+		return plain.Mechanism{Username: user, Password: pass}, nil
+	case commoncfg.AuditKafkaSASLSCRAMSHA256:
+		algorithm = scram.SHA256
+	case commoncfg.AuditKafkaSASLSCRAMSHA512:
+		algorithm = scram.SHA512
+	default:
+		return nil, oops.In(domain).
+			With("mechanism", mechanism).
+			Wrap(errUnsupportedSASLMechanism)
+	}
+
+	scramMechanism, err := scram.Mechanism(algorithm, user, pass)
+	if err != nil {
+		return nil, oops.In(domain).
+			Hint("failed to create the Kafka SASL mechanism").
+			Wrap(err)
+	}
+
+	return scramMechanism, nil
+}
+
+// Send produces the payload and waits for the brokers to acknowledge it.
+func (t *kafkaTransport) Send(ctx context.Context, contentType string, payload []byte) error {
+	err := t.writer.WriteMessages(ctx, kafka.Message{
+		Value:   payload,
+		Headers: []kafka.Header{{Key: "content-type", Value: []byte(contentType)}},
+	})
+	if err != nil {
+		return oops.In(domain).
+			With("topic", t.writer.Topic).
+			Hint("failed to produce audit logs to Kafka").
+			Wrap(err)
+	}
+
+	return nil
+}
+
+// Close closes the connections to the brokers.
+func (t *kafkaTransport) Close() error {
+	return t.writer.Close()
+}
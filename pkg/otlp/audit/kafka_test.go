@@ -0,0 +1,146 @@
+package otlpaudit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/segmentio/kafka-go/protocol/metadata"
+	"github.com/segmentio/kafka-go/protocol/produce"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// kafkaMessage is a message produced to the fake Kafka broker.
+type kafkaMessage struct {
+	topic   string
+	headers map[string]string
+	value   string
+}
+
+// fakeKafkaBroker answers the metadata and produce requests of the writer of the kafka
+// transport with a topic of a single partition, and records the produced messages.
+type fakeKafkaBroker struct {
+	messages  chan kafkaMessage
+	errorCode int16
+}
+
+func (b *fakeKafkaBroker) RoundTrip(_ context.Context, _ net.Addr, req protocol.Message) (protocol.Message, error) {
+	switch req := req.(type) {
+	case *metadata.Request:
+		topics := make([]metadata.ResponseTopic, 0, len(req.TopicNames))
+		for _, name := range req.TopicNames {
+			topics = append(topics, metadata.ResponseTopic{
+				Name:       name,
+				Partitions: []metadata.ResponsePartition{{PartitionIndex: 0, LeaderID: 1}},
+			})
+		}
+
+		return &metadata.Response{
+			Brokers: []metadata.ResponseBroker{{NodeID: 1, Host: "127.0.0.1", Port: 9092}},
+			Topics:  topics,
+		}, nil
+	case *produce.Request:
+		res := &produce.Response{}
+
+		for _, topic := range req.Topics {
+			resTopic := produce.ResponseTopic{Topic: topic.Topic}
+
+			for _, partition := range topic.Partitions {
+				for {
+					record, err := partition.RecordSet.Records.ReadRecord()
+					if err != nil {
+						break
+					}
+
+					value, err := protocol.ReadAll(record.Value)
+					if err != nil {
+						return nil, err
+					}
+
+					headers := make(map[string]string, len(record.Headers))
+					for _, header := range record.Headers {
+						headers[header.Key] = string(header.Value)
+					}
+
+					b.messages <- kafkaMessage{topic: topic.Topic, headers: headers, value: string(value)}
+				}
+
+				resTopic.Partitions = append(resTopic.Partitions, produce.ResponsePartition{
+					Partition: partition.Partition,
+					ErrorCode: b.errorCode,
+				})
+			}
+
+			res.Topics = append(res.Topics, resTopic)
+		}
+
+		return res, nil
+	default:
+		return nil, errors.New("unexpected kafka request")
+	}
+}
+
+func TestKafkaTransport(t *testing.T) {
+	newTransport := func(t *testing.T, broker *fakeKafkaBroker) *kafkaTransport {
+		t.Helper()
+
+		transport, err := newKafkaTransport(commoncfg.AuditKafka{
+			Brokers: []string{"127.0.0.1:9092"},
+			Topic:   "audit.events",
+			SecretRef: &commoncfg.SecretRef{
+				Type: commoncfg.BasicSecretType,
+				Basic: commoncfg.BasicAuth{
+					Username: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "audit"},
+					Password: commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "secret"},
+				},
+			},
+			SASLMechanism: commoncfg.AuditKafkaSASLSCRAMSHA512,
+		})
+		if err != nil {
+			t.Fatalf("newKafkaTransport() error = %v", err)
+		}
+
+		transport.writer.Transport = broker
+
+		t.Cleanup(func() { _ = transport.Close() })
+
+		return transport
+	}
+
+	t.Run("produces the events to the topic", func(t *testing.T) {
+		broker := &fakeKafkaBroker{messages: make(chan kafkaMessage, 1)}
+
+		err := newTransport(t, broker).Send(t.Context(), cloudEventsContentType, []byte(`[{"id":"1"}]`))
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+
+		msg := <-broker.messages
+		if msg.topic != "audit.events" || msg.value != `[{"id":"1"}]` || msg.headers["content-type"] != cloudEventsContentType {
+			t.Errorf("unexpected message %+v", msg)
+		}
+	})
+
+	t.Run("returns the error of the broker", func(t *testing.T) {
+		broker := &fakeKafkaBroker{messages: make(chan kafkaMessage, 1), errorCode: 29} // TOPIC_AUTHORIZATION_FAILED
+
+		err := newTransport(t, broker).Send(t.Context(), otlpContentType, []byte("{}"))
+		if err == nil {
+			t.Fatalf("expected the error of the broker")
+		}
+	})
+}
+
+func TestNewKafkaTransportUnsupportedSecretType(t *testing.T) {
+	_, err := newKafkaTransport(commoncfg.AuditKafka{
+		Brokers:   []string{"127.0.0.1:9092"},
+		Topic:     "audit.events",
+		SecretRef: &commoncfg.SecretRef{Type: commoncfg.ApiTokenSecretType},
+	})
+	if !errors.Is(err, errUnsupportedSecretType) {
+		t.Fatalf("expected errUnsupportedSecretType, got '%v'", err)
+	}
+}
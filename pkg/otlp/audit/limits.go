@@ -0,0 +1,132 @@
+package otlpaudit
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+const (
+	defTruncationMarker        = "...[truncated]"
+	auditInstrumentationID     = "github.com/openkcm/common-sdk/pkg/otlp/audit"
+	truncatedEventsMetric      = "audit.events.truncated"
+	truncatedEventsDescription = "Number of audit events with truncated attribute values"
+)
+
+// LoggerOption configures an AuditLogger.
+type LoggerOption func(*AuditLogger)
+
+// WithMeterProvider sets the meter provider of the audit logger metrics.
+// Defaults to the global meter provider.
+func WithMeterProvider(provider metric.MeterProvider) LoggerOption {
+	return func(auditLogger *AuditLogger) {
+		if provider != nil {
+			auditLogger.meterProvider = provider
+		}
+	}
+}
+
+// valueLimiter truncates the attribute values exceeding the configured size.
+type valueLimiter struct {
+	maxSize   int
+	marker    string
+	truncated metric.Int64Counter
+}
+
+func newValueLimiter(limits commoncfg.AuditLimits, provider metric.MeterProvider) valueLimiter {
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+
+	counter, err := provider.Meter(auditInstrumentationID).Int64Counter(truncatedEventsMetric,
+		metric.WithDescription(truncatedEventsDescription))
+	if err != nil {
+		counter = noop.Int64Counter{}
+	}
+
+	marker := limits.TruncationMarker
+	if marker == "" {
+		marker = defTruncationMarker
+	}
+
+	return valueLimiter{
+		maxSize:   int(limits.MaxValueSize),
+		marker:    marker,
+		truncated: counter,
+	}
+}
+
+// limitLogs truncates the string attribute values of all log records which exceed the
+// maximum size. Structured values exceeding it are replaced by their truncated JSON. The truncated attributes are listed in TruncatedAttributesKey and each
+// truncated event is counted by the audit.events.truncated metric.
+func (l valueLimiter) limitLogs(ctx context.Context, logs plog.Logs) {
+	if l.maxSize <= 0 {
+		return
+	}
+
+	for _, rl := range logs.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				l.limitRecord(ctx, lr)
+			}
+		}
+	}
+}
+
+func (l valueLimiter) limitRecord(ctx context.Context, lr plog.LogRecord) {
+	var truncated []string
+
+	for key, val := range lr.Attributes().All() {
+		if key == TruncatedAttributesKey {
+			continue
+		}
+
+		var value string
+
+		switch val.Type() {
+		case pcommon.ValueTypeStr, pcommon.ValueTypeMap, pcommon.ValueTypeSlice:
+			value = val.AsString()
+		default:
+			continue
+		}
+
+		if len(value) <= l.maxSize {
+			continue
+		}
+
+		val.SetStr(l.truncate(value))
+		truncated = append(truncated, key)
+	}
+
+	if len(truncated) == 0 {
+		return
+	}
+
+	lr.Attributes().PutStr(TruncatedAttributesKey, strings.Join(truncated, ","))
+
+	eventType, _ := lr.Attributes().Get(EventTypeKey)
+	l.truncated.Add(ctx, 1, metric.WithAttributes(attribute.String(EventTypeKey, eventType.Str())))
+}
+
+// truncate cuts the value at a rune boundary, so the value including the marker fits the maximum size.
+func (l valueLimiter) truncate(value string) string {
+	if len(l.marker) >= l.maxSize {
+		return l.marker
+	}
+
+	n := l.maxSize - len(l.marker)
+	for n > 0 && !utf8.RuneStart(value[n]) {
+		n--
+	}
+
+	return value[:n] + l.marker
+}
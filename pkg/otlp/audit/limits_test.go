@@ -0,0 +1,157 @@
+package otlpaudit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestValueLimiterTruncate(t *testing.T) {
+	limiter := newValueLimiter(commoncfg.AuditLimits{MaxValueSize: 10, TruncationMarker: "..."}, nil)
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "ascii", value: "abcdefghijklmnop", want: "abcdefg..."},
+		{name: "multi-byte runes are not split", value: "ääääääää", want: "äää..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := limiter.truncate(tt.value)
+			if got != tt.want {
+				t.Errorf("truncate() = %q, want %q", got, tt.want)
+			}
+
+			if len(got) > 10 {
+				t.Errorf("truncate() size = %d, want at most 10", len(got))
+			}
+		})
+	}
+
+	short := newValueLimiter(commoncfg.AuditLimits{MaxValueSize: 2, TruncationMarker: "..."}, nil)
+	if got := short.truncate("abcdef"); got != "..." {
+		t.Errorf("truncate() = %q, want the marker only", got)
+	}
+}
+
+func TestPayloadTruncatesLargeValues(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	auditLogger, err := NewLogger(&commoncfg.Audit{
+		Endpoint:   "http://localhost:1234/logs",
+		HTTPClient: commoncfg.HTTPClient{Timeout: time.Second},
+		Limits:     commoncfg.AuditLimits{MaxValueSize: 64},
+	}, WithMeterProvider(provider))
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	metadata, _ := NewEventMetadata("user", "tenant", "correlation")
+	large := strings.Repeat("x", 1024)
+
+	event, err := NewConfigurationUpdateEvent(metadata, "objectID", large, "small")
+	if err != nil {
+		t.Fatalf("NewConfigurationUpdateEvent() error = %v", err)
+	}
+
+	payload, err := auditLogger.payload(t.Context(), event)
+	if err != nil {
+		t.Fatalf("payload() error = %v", err)
+	}
+
+	attrs := event.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+
+	oldValue, _ := attrs.Get(OldValueKey)
+	if len(oldValue.Str()) != 64 || !strings.HasSuffix(oldValue.Str(), defTruncationMarker) {
+		t.Errorf("Expected the old value to be truncated with the marker, got %q", oldValue.Str())
+	}
+
+	newValue, _ := attrs.Get(NewValueKey)
+	if newValue.Str() != "small" {
+		t.Errorf("Expected the new value to be kept, got %q", newValue.Str())
+	}
+
+	truncated, ok := attrs.Get(TruncatedAttributesKey)
+	if !ok || truncated.Str() != OldValueKey {
+		t.Errorf("Expected %s to list %s, got %q", TruncatedAttributesKey, OldValueKey, truncated.Str())
+	}
+
+	if strings.Contains(payload, large) {
+		t.Errorf("Expected the payload not to contain the complete value")
+	}
+
+	var rm metricdata.ResourceMetrics
+
+	err = reader.Collect(t.Context(), &rm)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	if got := truncatedEvents(rm); got != 1 {
+		t.Errorf("Expected 1 truncated event, got %d", got)
+	}
+}
+
+func TestPayloadWithoutLimit(t *testing.T) {
+	auditLogger, err := NewLogger(&commoncfg.Audit{
+		Endpoint:   "http://localhost:1234/logs",
+		HTTPClient: commoncfg.HTTPClient{Timeout: time.Second},
+	})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	metadata, _ := NewEventMetadata("user", "tenant", "correlation")
+	large := strings.Repeat("x", 1024)
+
+	event, _ := NewConfigurationUpdateEvent(metadata, "objectID", large, "small")
+
+	_, err = auditLogger.payload(t.Context(), event)
+	if err != nil {
+		t.Fatalf("payload() error = %v", err)
+	}
+
+	attrs := event.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+
+	oldValue, _ := attrs.Get(OldValueKey)
+	if oldValue.Str() != large {
+		t.Errorf("Expected the old value not to be truncated")
+	}
+
+	if _, ok := attrs.Get(TruncatedAttributesKey); ok {
+		t.Errorf("Expected no %s attribute", TruncatedAttributesKey)
+	}
+}
+
+func truncatedEvents(rm metricdata.ResourceMetrics) int64 {
+	var total int64
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != truncatedEventsMetric {
+				continue
+			}
+
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				continue
+			}
+
+			for _, dp := range sum.DataPoints {
+				total += dp.Value
+			}
+		}
+	}
+
+	return total
+}
@@ -2,16 +2,27 @@ package otlpaudit
 
 import (
 	"net/http"
+	"os"
 
 	"github.com/goccy/go-yaml"
+	"github.com/samber/oops"
+	"go.opentelemetry.io/otel/metric"
 
 	"github.com/openkcm/common-sdk/pkg/commoncfg"
 	"github.com/openkcm/common-sdk/pkg/commonhttp"
+	"github.com/openkcm/common-sdk/pkg/logger"
 )
 
 type AuditLogger struct {
 	client          otlpClient
-	additionalProps map[string]string
+	additionalProps map[string]any
+	mustAudit       commoncfg.AuditMustAudit
+	limiter         valueLimiter
+	meterProvider   metric.MeterProvider
+	hashChain       *hashChain
+	transport       Transport
+	deadLetter      DeadLetterHandler
+	masker          *logger.FieldMasker
 }
 
 type otlpClient struct {
@@ -19,24 +30,64 @@ type otlpClient struct {
 	Client   *http.Client
 }
 
-func NewLogger(config *commoncfg.Audit) (*AuditLogger, error) {
+// NewLogger creates an audit logger sending the events to the configured endpoint.
+// Attribute values exceeding config.Limits.MaxValueSize are truncated before the events are sent.
+func NewLogger(config *commoncfg.Audit, opts ...LoggerOption) (*AuditLogger, error) {
 	client, err := commonhttp.NewHTTPClient(&config.HTTPClient)
 	if err != nil {
 		return nil, err
 	}
 
-	var m map[string]string
+	// the properties are YAML or JSON, which is a subset of YAML
+	var m map[string]any
 
 	err = yaml.Unmarshal([]byte(config.AdditionalProperties), &m)
 	if err != nil {
-		return nil, err
+		return nil, oops.In(domain).
+			Hint("failed to parse the additional properties").
+			Wrap(err)
+	}
+
+	if config.MustAudit.FailurePolicy == commoncfg.AuditSpool {
+		if config.MustAudit.SpoolDir == "" {
+			return nil, errSpoolDirMissing
+		}
+
+		err = os.MkdirAll(config.MustAudit.SpoolDir, 0o700)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return &AuditLogger{
+	var chain *hashChain
+	if config.HashChain.Enabled {
+		chain, err = newHashChain(config.HashChain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var masker *logger.FieldMasker
+	if len(config.Masking.PII) > 0 || len(config.Masking.Other) > 0 {
+		masker = logger.NewFieldMasker(config.Masking)
+	}
+
+	auditLogger := &AuditLogger{
 		client: otlpClient{
 			Endpoint: config.Endpoint,
 			Client:   client,
 		},
 		additionalProps: m,
-	}, nil
+		mustAudit:       config.MustAudit,
+		hashChain:       chain,
+		masker:          masker,
+	}
+
+	for _, opt := range opts {
+		opt(auditLogger)
+	}
+
+	auditLogger.limiter = newValueLimiter(config.Limits, auditLogger.meterProvider)
+
+	return auditLogger, nil
 }
@@ -200,6 +200,53 @@ func Test_EnrichLogs(t *testing.T) {
 	}
 }
 
+func TestEnrichLogsWithJSONProperties(t *testing.T) {
+	auditLogger, err := NewLogger(&commoncfg.Audit{
+		Endpoint:             "http://localhost:1234/logs",
+		HTTPClient:           commoncfg.HTTPClient{Timeout: time.Second},
+		AdditionalProperties: `{"region": "eu10", "replicas": 3, "labels": {"team": "kms"}, "eventType": "other"}`,
+	})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	metadata := EventMetadata{UserInitiatorIDKey: "userInitiatorID", TenantIDKey: "tenantID"}
+
+	logs, err := NewCmkCreateEvent(metadata, "cmk1")
+	if err != nil {
+		t.Fatalf("NewCmkCreateEvent() error = %v", err)
+	}
+
+	err = auditLogger.enrichLogs(&logs)
+	if err != nil {
+		t.Fatalf("enrichLogs() error = %v", err)
+	}
+
+	record, _ := firstLogRecord(logs)
+
+	for key, want := range map[string]string{
+		"region":     "eu10",
+		"replicas":   "3",
+		"labels":     `{"team":"kms"}`,
+		EventTypeKey: CmkCreateEvent,
+	} {
+		got, _ := record.Attributes().Get(key)
+		if got.AsString() != want {
+			t.Errorf("expected %s = %s, got %s", key, want, got.AsString())
+		}
+	}
+}
+
+func TestNewLoggerInvalidAdditionalProperties(t *testing.T) {
+	_, err := NewLogger(&commoncfg.Audit{
+		Endpoint:             "http://localhost:1234/logs",
+		AdditionalProperties: `{"region": "eu10"`,
+	})
+	if err == nil {
+		t.Error("expected an error for the malformed additional properties")
+	}
+}
+
 func valuesPresent(m pcommon.Map, keys ...string) bool {
 	for _, k := range keys {
 		_, ok := m.Get(k)
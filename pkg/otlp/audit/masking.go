@@ -0,0 +1,49 @@
+package otlpaudit
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/openkcm/common-sdk/pkg/logger"
+)
+
+// maskedValueKeys are the attributes whose map and struct values are masked.
+var maskedValueKeys = []string{ValueKey, OldValueKey, NewValueKey}
+
+// maskLogs masks the fields of the map and struct values of all log records with the
+// masking rules of the audit configuration, see commoncfg.Audit.Masking.
+func maskLogs(masker *logger.FieldMasker, logs plog.Logs) {
+	for _, lr := range logRecords(logs) {
+		for _, key := range maskedValueKeys {
+			value, ok := lr.Attributes().Get(key)
+			if ok {
+				maskValue(masker, value)
+			}
+		}
+	}
+}
+
+// maskValue masks the scalar fields of the nested maps of the value, the fields holding
+// maps or slices are masked recursively.
+func maskValue(masker *logger.FieldMasker, value pcommon.Value) {
+	switch value.Type() {
+	case pcommon.ValueTypeMap:
+		for key, field := range value.Map().All() {
+			switch field.Type() {
+			case pcommon.ValueTypeMap, pcommon.ValueTypeSlice:
+				maskValue(masker, field)
+			case pcommon.ValueTypeEmpty:
+			default:
+				masked, ok := masker.Mask(key, field.AsString())
+				if ok {
+					field.SetStr(masked)
+				}
+			}
+		}
+	case pcommon.ValueTypeSlice:
+		for _, elem := range value.Slice().All() {
+			maskValue(masker, elem)
+		}
+	default:
+	}
+}
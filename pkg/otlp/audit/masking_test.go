@@ -0,0 +1,52 @@
+package otlpaudit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestPayloadMasksPersonalData(t *testing.T) {
+	auditLogger, err := NewLogger(&commoncfg.Audit{
+		Endpoint:   "http://localhost:1234/logs",
+		HTTPClient: commoncfg.HTTPClient{Timeout: time.Second},
+		Masking: commoncfg.LoggerFieldsMasking{
+			PII:   []string{"email"},
+			Other: map[string]string{"phone": "[redacted]"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	metadata := EventMetadata{UserInitiatorIDKey: "userInitiatorID", TenantIDKey: "tenantID"}
+
+	event, err := NewConfigurationUpdateEvent(metadata, "objectID",
+		map[string]any{"email": "john@example.com", "status": "invited"},
+		map[string]any{
+			"email":    "jane@example.com",
+			"contacts": []any{map[string]any{"phone": "+49 123 456"}},
+			"status":   "active",
+		})
+	if err != nil {
+		t.Fatalf("NewConfigurationUpdateEvent() error = %v", err)
+	}
+
+	_, err = auditLogger.payload(t.Context(), event)
+	if err != nil {
+		t.Fatalf("payload() error = %v", err)
+	}
+
+	record, _ := firstLogRecord(event)
+
+	oldValue, _ := record.Attributes().Get(OldValueKey)
+	if want := `{"email":"john*******","status":"invited"}`; oldValue.AsString() != want {
+		t.Errorf("expected %s, got %s", want, oldValue.AsString())
+	}
+
+	newValue, _ := record.Attributes().Get(NewValueKey)
+	if want := `{"contacts":[{"phone":"[redacted]"}],"email":"jane*******","status":"active"}`; newValue.AsString() != want {
+		t.Errorf("expected %s, got %s", want, newValue.AsString())
+	}
+}
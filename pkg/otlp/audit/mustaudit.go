@@ -0,0 +1,213 @@
+package otlpaudit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samber/oops"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+const (
+	defMustAuditTimeout = 10 * time.Second
+	mustAuditMinBackoff = 100 * time.Millisecond
+	mustAuditMaxBackoff = 2 * time.Second
+	spoolFileSuffix     = ".json"
+)
+
+// MustAudit exports the event of a critical operation, e.g. a key purge or a tenant
+// offboarding, and only returns nil once the event is safe: either the collector
+// confirmed it, or, with the spool failure policy, it was durably written to the
+// spool directory. Failed exports are retried until the configured timeout.
+//
+// The caller must not complete the operation if an error is returned:
+//
+//	err := auditLogger.MustAudit(ctx, event)
+//	if err != nil {
+//	    return err // the purge cannot be audited, so it must not happen
+//	}
+//	purgeKey(ctx, keyID)
+func (auditLogger *AuditLogger) MustAudit(ctx context.Context, logs plog.Logs) error {
+	payload, err := auditLogger.payload(ctx, logs)
+	if err != nil {
+		return err
+	}
+
+	timeout := auditLogger.mustAudit.Timeout
+	if timeout <= 0 {
+		timeout = defMustAuditTimeout
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	sendErr := auditLogger.sendWithRetry(sendCtx, payload)
+	if sendErr == nil {
+		return nil
+	}
+
+	if auditLogger.mustAudit.FailurePolicy != commoncfg.AuditSpool {
+		return oops.In(domain).
+			Hint("the operation must not proceed").
+			Wrap(errors.Join(ErrAuditNotConfirmed, sendErr))
+	}
+
+	err = auditLogger.spool(payload)
+	if err != nil {
+		return oops.In(domain).
+			Hint("the operation must not proceed").
+			Wrap(errors.Join(ErrAuditNotConfirmed, sendErr, err))
+	}
+
+	return nil
+}
+
+// ReplaySpool exports the events of the spool directory in the order they were
+// spooled and removes them once the collector confirmed them. It stops at the
+// first failed export and returns the number of exported events, so it can be
+// called periodically, e.g. on startup and from a background job.
+func (auditLogger *AuditLogger) ReplaySpool(ctx context.Context) (int, error) {
+	dir := auditLogger.mustAudit.SpoolDir
+	if dir == "" {
+		return 0, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, oops.In(domain).
+			Hint("failed to read the audit spool").
+			Wrap(err)
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), spoolFileSuffix) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	// the file names start with the spool time, so they sort in spool order
+	slices.Sort(files)
+
+	replayed := 0
+
+	for _, file := range files {
+		payload, err := os.ReadFile(file)
+		if err != nil {
+			return replayed, oops.In(domain).
+				Hint("failed to read spooled audit event").
+				Wrap(err)
+		}
+
+		err = auditLogger.client.send(ctx, string(payload))
+		if err != nil {
+			return replayed, oops.In(domain).
+				Hint("failed to send spooled audit event").
+				Wrap(err)
+		}
+
+		err = os.Remove(file)
+		if err != nil {
+			return replayed, oops.In(domain).
+				Hint("failed to remove spooled audit event").
+				Wrap(err)
+		}
+
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+// sendWithRetry sends the payload until it is confirmed or the context is done.
+func (auditLogger *AuditLogger) sendWithRetry(ctx context.Context, payload string) error {
+	backoff := mustAuditMinBackoff
+
+	for {
+		err := auditLogger.client.send(ctx, payload)
+		if err == nil {
+			return nil
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*2, mustAuditMaxBackoff)
+	}
+}
+
+// spool writes the payload durably to the spool directory.
+func (auditLogger *AuditLogger) spool(payload string) error {
+	return writeSpoolFile(auditLogger.mustAudit.SpoolDir, payload)
+}
+
+// writeSpoolFile writes the payload durably: the event is written to a temporary file which
+// is synced and then renamed, so the directory never contains partially written events.
+func writeSpoolFile(dir, payload string) error {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+
+	name := fmt.Sprintf("%020d-%s", time.Now().UnixNano(), hex.EncodeToString(suffix))
+
+	tmp, err := os.CreateTemp(dir, name+"-*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = tmp.WriteString(payload)
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	closeErr := tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	if closeErr != nil {
+		return closeErr
+	}
+
+	err = os.Rename(tmp.Name(), filepath.Join(dir, name+spoolFileSuffix))
+	if err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir persists the directory entry of a renamed file.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	err = d.Sync()
+	closeErr := d.Close()
+
+	if err != nil {
+		return err
+	}
+
+	return closeErr
+}
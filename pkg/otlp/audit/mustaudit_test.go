@@ -0,0 +1,172 @@
+package otlpaudit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func newMustAuditLogger(t *testing.T, endpoint string, mustAudit commoncfg.AuditMustAudit) *AuditLogger {
+	t.Helper()
+
+	auditLogger, err := NewLogger(&commoncfg.Audit{
+		Endpoint:   endpoint,
+		HTTPClient: commoncfg.HTTPClient{Timeout: time.Second},
+		MustAudit:  mustAudit,
+	})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	return auditLogger
+}
+
+func newTestLogs() plog.Logs {
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+
+	return logs
+}
+
+func TestMustAudit(t *testing.T) {
+	t.Run("retries until the collector confirms the event", func(t *testing.T) {
+		var calls atomic.Int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		auditLogger := newMustAuditLogger(t, server.URL, commoncfg.AuditMustAudit{Timeout: 5 * time.Second})
+
+		err := auditLogger.MustAudit(t.Context(), newTestLogs())
+		if err != nil {
+			t.Fatalf("Expected no error, got '%v'", err)
+		}
+
+		if calls.Load() != 3 {
+			t.Errorf("expected 3 calls, got %d", calls.Load())
+		}
+	})
+
+	t.Run("fails closed after the timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		auditLogger := newMustAuditLogger(t, server.URL, commoncfg.AuditMustAudit{
+			Timeout:       300 * time.Millisecond,
+			FailurePolicy: commoncfg.AuditFailClosed,
+		})
+
+		err := auditLogger.MustAudit(t.Context(), newTestLogs())
+		if !errors.Is(err, ErrAuditNotConfirmed) {
+			t.Fatalf("expected ErrAuditNotConfirmed, got '%v'", err)
+		}
+	})
+
+	t.Run("spools the event and replays it", func(t *testing.T) {
+		var available atomic.Bool
+
+		var received atomic.Int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if !available.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			received.Add(1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		spoolDir := t.TempDir()
+		auditLogger := newMustAuditLogger(t, server.URL, commoncfg.AuditMustAudit{
+			Timeout:       200 * time.Millisecond,
+			FailurePolicy: commoncfg.AuditSpool,
+			SpoolDir:      spoolDir,
+		})
+
+		for range 2 {
+			err := auditLogger.MustAudit(t.Context(), newTestLogs())
+			if err != nil {
+				t.Fatalf("Expected no error, got '%v'", err)
+			}
+		}
+
+		entries, _ := os.ReadDir(spoolDir)
+		if len(entries) != 2 {
+			t.Fatalf("expected 2 spooled events, got %d", len(entries))
+		}
+
+		_, err := auditLogger.ReplaySpool(t.Context())
+		if err == nil {
+			t.Fatalf("expected replay to fail while the collector is unavailable")
+		}
+
+		available.Store(true)
+
+		replayed, err := auditLogger.ReplaySpool(t.Context())
+		if err != nil {
+			t.Fatalf("Expected no error, got '%v'", err)
+		}
+
+		if replayed != 2 || received.Load() != 2 {
+			t.Errorf("expected 2 replayed events, got %d replayed and %d received", replayed, received.Load())
+		}
+
+		entries, _ = os.ReadDir(spoolDir)
+		if len(entries) != 0 {
+			t.Errorf("expected an empty spool, got %d entries", len(entries))
+		}
+	})
+
+	t.Run("fails if the event can neither be sent nor spooled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		spoolDir := t.TempDir()
+		auditLogger := newMustAuditLogger(t, server.URL, commoncfg.AuditMustAudit{
+			Timeout:       100 * time.Millisecond,
+			FailurePolicy: commoncfg.AuditSpool,
+			SpoolDir:      spoolDir,
+		})
+
+		err := os.RemoveAll(spoolDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = auditLogger.MustAudit(t.Context(), newTestLogs())
+		if !errors.Is(err, ErrAuditNotConfirmed) {
+			t.Fatalf("expected ErrAuditNotConfirmed, got '%v'", err)
+		}
+	})
+}
+
+func TestNewLoggerSpoolDirMissing(t *testing.T) {
+	_, err := NewLogger(&commoncfg.Audit{
+		Endpoint:  "http://localhost:1234/logs",
+		MustAudit: commoncfg.AuditMustAudit{FailurePolicy: commoncfg.AuditSpool},
+	})
+	if !errors.Is(err, errSpoolDirMissing) {
+		t.Fatalf("expected errSpoolDirMissing, got '%v'", err)
+	}
+}
@@ -0,0 +1,123 @@
+package otlpaudit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/samber/oops"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// natsTimeout bounds the connection and each publication to the NATS server
+const natsTimeout = 10 * time.Second
+
+// natsTransport publishes the events to a NATS subject. Each publication is followed by a
+// flush, so the PONG of the server confirms it was accepted. The connection is reconnected
+// in the background; publications fail while it is disconnected instead of being buffered,
+// so the AuditSender retries them.
+type natsTransport struct {
+	conn    *nats.Conn
+	subject string
+}
+
+func newNATSTransport(cfg commoncfg.AuditNATS) (*natsTransport, error) {
+	opts := []nats.Option{
+		nats.Name(auditInstrumentationID),
+		nats.Timeout(natsTimeout),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.ReconnectBufSize(-1),
+	}
+
+	var token, user, password string
+
+	for _, secret := range []struct {
+		ref   *commoncfg.SourceRef
+		value *string
+	}{
+		{cfg.Token, &token},
+		{cfg.User, &user},
+		{cfg.Password, &password},
+	} {
+		if secret.ref == nil {
+			continue
+		}
+
+		value, err := commoncfg.LoadValueFromSourceRef(*secret.ref)
+		if err != nil {
+			return nil, oops.In(domain).
+				Hint("failed to load the NATS credentials").
+				Wrap(err)
+		}
+
+		*secret.value = strings.TrimSpace(string(value))
+	}
+
+	if token != "" {
+		opts = append(opts, nats.Token(token))
+	}
+
+	if user != "" || password != "" {
+		opts = append(opts, nats.UserInfo(user, password))
+	}
+
+	if cfg.MTLS != nil {
+		tlsConfig, err := commoncfg.LoadMTLSConfig(cfg.MTLS)
+		if err != nil {
+			return nil, oops.In(domain).
+				Hint("failed to load the NATS TLS configuration").
+				Wrap(err)
+		}
+
+		opts = append(opts, nats.Secure(tlsConfig))
+	}
+
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, oops.In(domain).
+			Hint("failed to connect to NATS").
+			Wrap(err)
+	}
+
+	return &natsTransport{conn: conn, subject: cfg.Subject}, nil
+}
+
+// Send publishes the payload and waits for the server to confirm it.
+func (t *natsTransport) Send(ctx context.Context, contentType string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, natsTimeout)
+	defer cancel()
+
+	msg := nats.NewMsg(t.subject)
+	msg.Header.Set("Content-Type", contentType)
+	msg.Data = payload
+
+	err := t.conn.PublishMsg(msg)
+	if err == nil {
+		err = t.conn.FlushWithContext(ctx)
+	}
+
+	if err != nil {
+		// the last error of the connection tells why it is disconnected, e.g. an authorization violation
+		if !t.conn.IsConnected() {
+			err = errors.Join(err, t.conn.LastError())
+		}
+
+		return oops.In(domain).
+			With("subject", t.subject).
+			Hint("failed to publish audit logs to NATS").
+			Wrap(err)
+	}
+
+	return nil
+}
+
+// Close closes the connection to the server.
+func (t *natsTransport) Close() error {
+	t.conn.Close()
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package otlpaudit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// natsMessage is a message published to the fake NATS server.
+type natsMessage struct {
+	subject string
+	header  string
+	payload string
+}
+
+// newFakeNATSServer starts a NATS server answering CONNECT with connectResponse, which
+// may be empty, and sends the CONNECT options and published messages to the channels.
+func newFakeNATSServer(t *testing.T, connectResponse string) (string, chan string, chan natsMessage) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	connects := make(chan string, 1)
+	messages := make(chan natsMessage, 10)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = io.WriteString(conn, "INFO {\"server_id\":\"test\",\"headers\":true,\"max_payload\":1048576}\r\n")
+		reader := bufio.NewReader(conn)
+
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+
+			switch fields[0] {
+			case "CONNECT":
+				connects <- strings.TrimSpace(strings.TrimPrefix(line, "CONNECT "))
+
+				if connectResponse != "" {
+					_, _ = io.WriteString(conn, connectResponse+"\r\n")
+					return
+				}
+			case "PING":
+				_, _ = io.WriteString(conn, "PONG\r\n")
+			case "HPUB":
+				var headerSize, totalSize int
+
+				_, _ = fmt.Sscan(fields[2], &headerSize)
+				_, _ = fmt.Sscan(fields[3], &totalSize)
+
+				body := make([]byte, totalSize+2)
+
+				_, err = io.ReadFull(reader, body)
+				if err != nil {
+					return
+				}
+
+				messages <- natsMessage{
+					subject: fields[1],
+					header:  string(body[:headerSize]),
+					payload: string(body[headerSize:totalSize]),
+				}
+			}
+		}
+	}()
+
+	return listener.Addr().String(), connects, messages
+}
+
+func TestNATSTransport(t *testing.T) {
+	t.Run("publishes the events to the subject", func(t *testing.T) {
+		address, connects, messages := newFakeNATSServer(t, "")
+
+		transport, err := newNATSTransport(commoncfg.AuditNATS{
+			URL:     "nats://" + address,
+			Subject: "audit.events",
+			Token:   &commoncfg.SourceRef{Source: commoncfg.EmbeddedSourceValue, Value: "secret"},
+		})
+		if err != nil {
+			t.Fatalf("newNATSTransport() error = %v", err)
+		}
+		defer transport.Close()
+
+		err = transport.Send(t.Context(), cloudEventsContentType, []byte(`[{"id":"1"}]`))
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+
+		if connect := <-connects; !strings.Contains(connect, `"auth_token":"secret"`) {
+			t.Errorf("expected the token in the CONNECT options, got %s", connect)
+		}
+
+		msg := <-messages
+		if msg.subject != "audit.events" || msg.payload != `[{"id":"1"}]` ||
+			!strings.Contains(msg.header, "Content-Type: "+cloudEventsContentType) {
+			t.Errorf("unexpected message %+v", msg)
+		}
+	})
+
+	t.Run("returns the error of the server", func(t *testing.T) {
+		address, _, _ := newFakeNATSServer(t, "-ERR 'Authorization Violation'")
+
+		transport, err := newNATSTransport(commoncfg.AuditNATS{URL: "nats://" + address, Subject: "audit.events"})
+		if err != nil {
+			t.Fatalf("newNATSTransport() error = %v", err)
+		}
+		defer transport.Close()
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+
+		err = transport.Send(ctx, otlpContentType, []byte("{}"))
+		if !errors.Is(err, nats.ErrAuthorization) {
+			t.Fatalf("expected nats.ErrAuthorization, got '%v'", err)
+		}
+	})
+}
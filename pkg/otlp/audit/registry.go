@@ -0,0 +1,257 @@
+package otlpaudit
+
+import (
+	"iter"
+	"slices"
+)
+
+// Descriptor documents a single audit vocabulary value, such as an event type,
+// a property key or an enum value, together with a human readable description.
+// It allows SIEM parsers and documentation to be generated from this package.
+type Descriptor struct {
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+var eventTypeRegistry = []Descriptor{
+	{ConfigCreateEvent, "A configuration object was created."},
+	{ConfigReadEvent, "A configuration object was read."},
+	{ConfigUpdateEvent, "A property of a configuration object was changed."},
+	{ConfigDeleteEvent, "A configuration object was deleted."},
+	{GroupCreateEvent, "A user group was created."},
+	{GroupReadEvent, "A user group was read."},
+	{GroupUpdateEvent, "A property of a user group was changed."},
+	{GroupDeleteEvent, "A user group was deleted."},
+	{KeyCreateEvent, "A key was created."},
+	{KeyDeleteEvent, "A key was deleted."},
+	{KeyRestoreEvent, "A deleted key was restored."},
+	{KeyPurgeEvent, "A key was permanently purged."},
+	{KeyRotateEvent, "A key was rotated to a new version."},
+	{KeyEnableEvent, "A key was enabled."},
+	{KeyDisableEvent, "A key was disabled."},
+	{WorkflowStartEvent, "An approval workflow was started."},
+	{WorkflowUpdateEvent, "A property of an approval workflow was changed."},
+	{WorkflowExecuteEvent, "An approved workflow was executed."},
+	{WorkflowTerminateEvent, "An approval workflow was terminated."},
+	{UserLoginSuccessEvent, "A user logged in successfully."},
+	{UserLoginFailureEvent, "A user login attempt failed."},
+	{TenantOnboardingEvent, "A tenant was onboarded."},
+	{TenantOffboardingEvent, "A tenant was offboarded."},
+	{TenantUpdateEvent, "A tenant setting was changed."},
+	{CredentialExpirationEvent, "A credential expired."},
+	{CredentialCreateEvent, "A credential was created."},
+	{CredentialRevokationEvent, "A credential was revoked."},
+	{CredentialDeleteEvent, "A credential was deleted."},
+	{CmkOnboardingEvent, "A customer managed key was onboarded."},
+	{CmkOffboardingEvent, "A customer managed key was offboarded."},
+	{CmkSwitchEvent, "A system was switched to another customer managed key."},
+	{CmkTenantModificationEvent, "A tenant level action was applied to a customer managed key."},
+	{CmkTenantDeleteEvent, "All customer managed keys of a tenant were deleted."},
+	{CmkCreateEvent, "A customer managed key was created."},
+	{CmkDeleteEvent, "A customer managed key was deleted."},
+	{CmkDetachEvent, "A customer managed key was detached."},
+	{CmkRestoreEvent, "A customer managed key was restored."},
+	{CmkEnableEvent, "A customer managed key was enabled."},
+	{CmkDisableEvent, "A customer managed key was disabled."},
+	{CmkRotateEvent, "A customer managed key was rotated."},
+	{CmkAvailableEvent, "A customer managed key became available."},
+	{CmkUnavailableEvent, "A customer managed key became unavailable."},
+	{UnauthorizedRequestEvent, "An authenticated request was denied due to missing permissions."},
+	{UnauthenticatedRequestEvent, "A request without valid authentication was rejected."},
+	{RoleAssignedEvent, "A role was assigned to a user or group."},
+	{RoleRevokedEvent, "A role was revoked from a user or group."},
+	{APIKeyCreateEvent, "An API key was created."},
+	{APIKeyRotateEvent, "An API key was rotated."},
+	{APIKeyRevokeEvent, "An API key was revoked."},
+	{DataExportRequestEvent, "An export of data was requested."},
+	{DataExportCompleteEvent, "A requested export of data was completed."},
+	{BackupCreateEvent, "A backup was created."},
+	{BackupRestoreEvent, "A backup was restored."},
+}
+
+var propertyKeyRegistry = []Descriptor{
+	{EventTypeKey, "The type of the audit event."},
+	{ObjectIDKey, "The identifier of the object the event refers to."},
+	{ObjectTypeKey, "The type of the object the event refers to."},
+	{ActionTypeKey, "The action performed on the object."},
+	{ChannelTypeKey, "The type of the channel a workflow runs on."},
+	{ChannelIDKey, "The identifier of the channel a workflow runs on."},
+	{LoginMethodKey, "The method used to log in."},
+	{MfaTypeKey, "The multi-factor authentication type used to log in."},
+	{UserTypeKey, "The type of the user logging in."},
+	{FailureReasonKey, "The reason a login failed."},
+	{CredentialTypeKey, "The type of the credential the event refers to."},
+	{ValueKey, "The value of the object the event refers to."},
+	{PropertyNameKey, "The name of the changed property."},
+	{OldValueKey, "The value of the property before the change."},
+	{NewValueKey, "The value of the property after the change."},
+	{DppKey, "Whether the value contains data protection and privacy relevant data."},
+	{UserInitiatorIDKey, "The identifier of the user who initiated the event."},
+	{TenantIDKey, "The identifier of the tenant the event belongs to."},
+	{EventIDKey, "The unique ID of the event, set if an event ID generator is configured."},
+	{EventCorrelationIDKey, "The identifier correlating related events."},
+	{SystemIDKey, "The identifier of the system the event refers to."},
+	{CmkIDKey, "The identifier of the customer managed key."},
+	{CmkIDOldKey, "The identifier of the previously used customer managed key."},
+	{CmkIDNewKey, "The identifier of the newly used customer managed key."},
+	{ResourceKey, "The resource a rejected request targeted."},
+	{ActionKey, "The action a rejected request attempted."},
+	{AssigneeIDKey, "The identifier of the user or group a role was assigned to or revoked from."},
+	{OwnerIDKey, "The identifier of the user or technical user owning an API key."},
+	{ScopeKey, "The data covered by a data export or backup, e.g. the keys of a tenant."},
+	{SourceIPKey, "The IP address the request causing the event originated from."},
+	{UserAgentKey, "The user agent of the client causing the event."},
+	{SessionIDKey, "The identifier of the session of the user who initiated the event."},
+	{OutcomeKey, "Whether the operation succeeded, failed or was denied."},
+	{SeverityKey, "The severity of the event."},
+	{ErrorReasonKey, "The reason a failed or denied operation did not succeed."},
+	{PrevHashKey, "The hash of the previous event of the hash chain, empty for the first event."},
+	{HashKey, "The hash of the event and the previous hash, computed with SHA-256 or HMAC-SHA256."},
+	{TruncatedAttributesKey, "The attributes whose values exceeded the size limit and were truncated."},
+}
+
+var keyTypeRegistry = []Descriptor{
+	{string(KEYTYPE_SYSTEM), "A key bound to a system."},
+	{string(KEYTYPE_SERVICE), "A key used internally by a service."},
+	{string(KEYTYPE_DATA), "A key encrypting payload data."},
+	{string(KEYTYPE_KEK), "A key encryption key wrapping other keys."},
+}
+
+var tenantUpdateActionRegistry = []Descriptor{
+	{string(TENANTUPDATE_TESTMODE), "The tenant test mode was changed."},
+	{string(TENANTUPDATE_WORKFLOWENABLE), "Approval workflows were enabled for the tenant."},
+	{string(TENANTUPDATE_WORKFLOWDISABLE), "Approval workflows were disabled for the tenant."},
+}
+
+var loginMethodRegistry = []Descriptor{
+	{string(LOGINMETHOD_OPENIDCONNECT), "Login via OpenID Connect."},
+	{string(LOGINMETHOD_X509CERT), "Login via X.509 client certificate."},
+}
+
+var mfaTypeRegistry = []Descriptor{
+	{string(MFATYPE_WEBAUTHN), "WebAuthn based multi-factor authentication."},
+	{string(MFATYPE_NONE), "No multi-factor authentication."},
+}
+
+var userTypeRegistry = []Descriptor{
+	{string(USERTYPE_BUSINESS), "A human business user."},
+	{string(USERTYPE_TECHNICAL), "A technical user acting on behalf of a system."},
+}
+
+var failReasonRegistry = []Descriptor{
+	{string(FAILREASON_PASSWORD), "The password was wrong."},
+	{string(FAILREASON_MFAFAIL), "The multi-factor authentication failed."},
+	{string(FAILREASON_USERNOTFOUND), "The user does not exist."},
+	{string(FAILREASON_USERLOCKED), "The user is locked."},
+	{string(FAILREASON_USERBLOCKED), "The user is blocked."},
+	{string(FAILREASON_USERUNVERIFIED), "The user is not verified."},
+	{string(FAILREASON_USEREXPIRED), "The user has expired."},
+	{string(FAILREASON_USERINVALID), "The user is invalid."},
+	{string(FAILREASON_INSECURECONNECT), "The connection was not secure."},
+	{string(FAILREASON_METHODDISABLED), "The login method is disabled."},
+	{string(FAILREASON_TOKENEXPIRED), "The token has expired."},
+	{string(FAILREASON_TOKENREVOKED), "The token was revoked."},
+	{string(FAILREASON_TOKENINVALID), "The token is invalid."},
+	{string(FAILREASON_SESSIONEXPIRED), "The session has expired."},
+	{string(FAILREASON_SESSIONREVOKED), "The session was revoked."},
+	{string(FAILREASON_CERTEXPIRED), "The certificate has expired."},
+	{string(FAILREASON_CERTREVOKED), "The certificate was revoked."},
+	{string(FAILREASON_CERTINVALID), "The certificate is invalid."},
+}
+
+var credentialTypeRegistry = []Descriptor{
+	{string(CREDTYPE_X509CERT), "An X.509 certificate."},
+	{string(CREDTYPE_KEY), "A cryptographic key."},
+	{string(CREDTYPE_SECRET), "A shared secret."},
+}
+
+var cmkActionRegistry = []Descriptor{
+	{string(CMKACTION_ONBOARD), "The customer managed key was onboarded for the tenant."},
+	{string(CMKACTION_BLOCK), "The customer managed key was blocked for the tenant."},
+	{string(CMKACTION_SHUTDOWN), "The tenant was shut down due to the customer managed key."},
+	{string(CMKACTION_CSEKFALLBACK), "The tenant fell back to a service managed key."},
+	{string(CMKACTION_RESTORE), "The customer managed key was restored for the tenant."},
+}
+
+var outcomeRegistry = []Descriptor{
+	{string(OUTCOME_SUCCESS), "The operation succeeded."},
+	{string(OUTCOME_FAILURE), "The operation was attempted but failed."},
+	{string(OUTCOME_DENIED), "The operation was denied, e.g. due to missing permissions."},
+}
+
+var severityRegistry = []Descriptor{
+	{string(SEVERITY_INFO), "A regular operation."},
+	{string(SEVERITY_WARNING), "An operation which may require attention."},
+	{string(SEVERITY_ERROR), "A failed operation which requires attention."},
+	{string(SEVERITY_CRITICAL), "An operation which requires immediate attention."},
+}
+
+// EventTypes iterates over all supported audit event types.
+func EventTypes() iter.Seq[Descriptor] { return slices.Values(eventTypeRegistry) }
+
+// PropertyKeys iterates over all property keys that may appear in audit events.
+func PropertyKeys() iter.Seq[Descriptor] { return slices.Values(propertyKeyRegistry) }
+
+// KeyTypes iterates over all [KeyType] values.
+func KeyTypes() iter.Seq[Descriptor] { return slices.Values(keyTypeRegistry) }
+
+// TenantUpdateActionTypes iterates over all [TenantUpdateActionType] values.
+func TenantUpdateActionTypes() iter.Seq[Descriptor] { return slices.Values(tenantUpdateActionRegistry) }
+
+// LoginMethods iterates over all [LoginMethod] values.
+func LoginMethods() iter.Seq[Descriptor] { return slices.Values(loginMethodRegistry) }
+
+// MfaTypes iterates over all [MfaType] values.
+func MfaTypes() iter.Seq[Descriptor] { return slices.Values(mfaTypeRegistry) }
+
+// UserTypes iterates over all [UserType] values.
+func UserTypes() iter.Seq[Descriptor] { return slices.Values(userTypeRegistry) }
+
+// FailReasons iterates over all [FailReason] values.
+func FailReasons() iter.Seq[Descriptor] { return slices.Values(failReasonRegistry) }
+
+// CredentialTypes iterates over all [CredentialType] values.
+func CredentialTypes() iter.Seq[Descriptor] { return slices.Values(credentialTypeRegistry) }
+
+// CmkActions iterates over all [CmkAction] values.
+func CmkActions() iter.Seq[Descriptor] { return slices.Values(cmkActionRegistry) }
+
+// Outcomes iterates over all [Outcome] values.
+func Outcomes() iter.Seq[Descriptor] { return slices.Values(outcomeRegistry) }
+
+// Severities iterates over all [Severity] values.
+func Severities() iter.Seq[Descriptor] { return slices.Values(severityRegistry) }
+
+// Enums iterates over all enum types keyed by their Go type name.
+func Enums() iter.Seq2[string, iter.Seq[Descriptor]] {
+	return func(yield func(string, iter.Seq[Descriptor]) bool) {
+		enums := []struct {
+			name   string
+			values iter.Seq[Descriptor]
+		}{
+			{"KeyType", KeyTypes()},
+			{"TenantUpdateActionType", TenantUpdateActionTypes()},
+			{"LoginMethod", LoginMethods()},
+			{"MfaType", MfaTypes()},
+			{"UserType", UserTypes()},
+			{"FailReason", FailReasons()},
+			{"CredentialType", CredentialTypes()},
+			{"CmkAction", CmkActions()},
+			{"Outcome", Outcomes()},
+			{"Severity", Severities()},
+		}
+
+		for _, e := range enums {
+			if !yield(e.name, e.values) {
+				return
+			}
+		}
+	}
+}
+
+// IsKnownEventType reports whether eventType is one of the supported audit event types.
+func IsKnownEventType(eventType string) bool {
+	return slices.ContainsFunc(eventTypeRegistry, func(d Descriptor) bool {
+		return d.Value == eventType
+	})
+}
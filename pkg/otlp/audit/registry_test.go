@@ -0,0 +1,92 @@
+package otlpaudit
+
+import (
+	"iter"
+	"testing"
+)
+
+func TestRegistryDescriptors(t *testing.T) {
+	tests := []struct {
+		name   string
+		values iter.Seq[Descriptor]
+		want   int
+	}{
+		{name: "T3000_EventTypes", values: EventTypes(), want: 53},
+		{name: "T3001_PropertyKeys", values: PropertyKeys(), want: 38},
+		{name: "T3002_KeyTypes", values: KeyTypes(), want: 4},
+		{name: "T3003_LoginMethods", values: LoginMethods(), want: 2},
+		{name: "T3004_CredentialTypes", values: CredentialTypes(), want: 3},
+		{name: "T3005_CmkActions", values: CmkActions(), want: 5},
+		{name: "T3006_FailReasons", values: FailReasons(), want: 18},
+		{name: "T3007_Outcomes", values: Outcomes(), want: 3},
+		{name: "T3008_Severities", values: Severities(), want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := map[string]bool{}
+
+			for d := range tt.values {
+				if d.Value == "" || d.Description == "" {
+					t.Errorf("descriptor %+v is incomplete", d)
+				}
+
+				if seen[d.Value] {
+					t.Errorf("duplicate descriptor %q", d.Value)
+				}
+
+				seen[d.Value] = true
+			}
+
+			if len(seen) != tt.want {
+				t.Errorf("got %d descriptors, want %d", len(seen), tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryEnumsAreValid(t *testing.T) {
+	validators := map[string]func(string) bool{
+		"KeyType":                func(v string) bool { return KeyType(v).IsValid() },
+		"TenantUpdateActionType": func(v string) bool { return TenantUpdateActionType(v).IsValid() },
+		"LoginMethod":            func(v string) bool { return LoginMethod(v).IsValid() },
+		"MfaType":                func(v string) bool { return MfaType(v).IsValid() },
+		"UserType":               func(v string) bool { return UserType(v).IsValid() },
+		"FailReason":             func(v string) bool { return FailReason(v).IsValid() },
+		"CredentialType":         func(v string) bool { return CredentialType(v).IsValid() },
+		"CmkAction":              func(v string) bool { return CmkAction(v).IsValid() },
+		"Outcome":                func(v string) bool { return Outcome(v).IsValid() },
+		"Severity":               func(v string) bool { return Severity(v).IsValid() },
+	}
+
+	count := 0
+
+	for name, values := range Enums() {
+		count++
+
+		isValid, ok := validators[name]
+		if !ok {
+			t.Fatalf("unexpected enum %q", name)
+		}
+
+		for d := range values {
+			if !isValid(d.Value) {
+				t.Errorf("%s value %q is not valid", name, d.Value)
+			}
+		}
+	}
+
+	if count != len(validators) {
+		t.Errorf("got %d enums, want %d", count, len(validators))
+	}
+}
+
+func TestIsKnownEventType(t *testing.T) {
+	if !IsKnownEventType(CmkRotateEvent) {
+		t.Errorf("expected %q to be known", CmkRotateEvent)
+	}
+
+	if IsKnownEventType("somethingElse") {
+		t.Error("expected unknown event type")
+	}
+}
@@ -0,0 +1,133 @@
+package otlpaudit
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Default keys of the request headers and gRPC metadata holding the event metadata.
+const (
+	DefUserInitiatorIDKey    = "x-user-id"
+	DefTenantIDKey           = "x-tenant-id"
+	DefEventCorrelationIDKey = "x-correlation-id"
+	DefSessionIDKey          = "x-session-id"
+)
+
+// userAgentKey is the gRPC metadata key of the user agent.
+const userAgentKey = "user-agent"
+
+// metadataKeys are the request headers or gRPC metadata keys the event metadata is read from.
+type metadataKeys struct {
+	userInitiatorID    string
+	tenantID           string
+	eventCorrelationID string
+	sessionID          string
+}
+
+// MetadataOption configures the keys the event metadata is read from.
+type MetadataOption func(*metadataKeys)
+
+// WithUserInitiatorIDKey sets the key of the initiating user, defaults to DefUserInitiatorIDKey.
+func WithUserInitiatorIDKey(key string) MetadataOption {
+	return func(keys *metadataKeys) {
+		keys.userInitiatorID = key
+	}
+}
+
+// WithTenantIDKey sets the key of the tenant, defaults to DefTenantIDKey.
+func WithTenantIDKey(key string) MetadataOption {
+	return func(keys *metadataKeys) {
+		keys.tenantID = key
+	}
+}
+
+// WithEventCorrelationIDKey sets the key of the correlation ID, defaults to DefEventCorrelationIDKey.
+func WithEventCorrelationIDKey(key string) MetadataOption {
+	return func(keys *metadataKeys) {
+		keys.eventCorrelationID = key
+	}
+}
+
+// WithSessionIDKey sets the key of the session ID, defaults to DefSessionIDKey.
+func WithSessionIDKey(key string) MetadataOption {
+	return func(keys *metadataKeys) {
+		keys.sessionID = key
+	}
+}
+
+func newMetadataKeys(opts []MetadataOption) metadataKeys {
+	keys := metadataKeys{
+		userInitiatorID:    DefUserInitiatorIDKey,
+		tenantID:           DefTenantIDKey,
+		eventCorrelationID: DefEventCorrelationIDKey,
+		sessionID:          DefSessionIDKey,
+	}
+
+	for _, opt := range opts {
+		opt(&keys)
+	}
+
+	return keys
+}
+
+// EventMetadataFromIncomingContext reads the event metadata from the metadata of an incoming
+// gRPC call. The source IP is the address of the peer, the user agent and session ID are
+// read from the metadata if present. It returns an error if the initiating user or the
+// tenant is missing.
+func EventMetadataFromIncomingContext(ctx context.Context, opts ...MetadataOption) (EventMetadata, error) {
+	keys := newMetadataKeys(opts)
+
+	value := func(key string) string {
+		values := metadata.ValueFromIncomingContext(ctx, key)
+		if len(values) == 0 {
+			return ""
+		}
+
+		return values[0]
+	}
+
+	eventMetadata, err := NewEventMetadata(value(keys.userInitiatorID), value(keys.tenantID), value(keys.eventCorrelationID))
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceIP string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		sourceIP = hostOf(p.Addr.String())
+	}
+
+	return eventMetadata.WithOrigin(sourceIP, value(userAgentKey), value(keys.sessionID)), nil
+}
+
+// EventMetadataFromRequest reads the event metadata from the headers of an HTTP request.
+// The source IP is the remote address of the request, so a service behind a proxy has to
+// restore it before, e.g. from a trusted X-Forwarded-For header. It returns an error if the
+// initiating user or the tenant is missing.
+func EventMetadataFromRequest(r *http.Request, opts ...MetadataOption) (EventMetadata, error) {
+	keys := newMetadataKeys(opts)
+
+	eventMetadata, err := NewEventMetadata(
+		r.Header.Get(keys.userInitiatorID),
+		r.Header.Get(keys.tenantID),
+		r.Header.Get(keys.eventCorrelationID),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return eventMetadata.WithOrigin(hostOf(r.RemoteAddr), r.UserAgent(), r.Header.Get(keys.sessionID)), nil
+}
+
+// hostOf strips the port from an address, addresses without a port are returned as is.
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
@@ -0,0 +1,163 @@
+package otlpaudit
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestEventMetadataFromIncomingContext(t *testing.T) {
+	t.Run("reads the default keys", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(
+			DefUserInitiatorIDKey, "user",
+			DefTenantIDKey, "tenant",
+			DefEventCorrelationIDKey, "correlation",
+		))
+
+		got, err := EventMetadataFromIncomingContext(ctx)
+		if err != nil {
+			t.Fatalf("EventMetadataFromIncomingContext() error = %v", err)
+		}
+
+		want := EventMetadata{UserInitiatorIDKey: "user", TenantIDKey: "tenant", EventCorrelationIDKey: "correlation"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("reads the configured keys", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(
+			"x-subject", "user",
+			"x-zone", "tenant",
+		))
+
+		got, err := EventMetadataFromIncomingContext(ctx, WithUserInitiatorIDKey("X-Subject"), WithTenantIDKey("x-zone"))
+		if err != nil {
+			t.Fatalf("EventMetadataFromIncomingContext() error = %v", err)
+		}
+
+		want := EventMetadata{UserInitiatorIDKey: "user", TenantIDKey: "tenant", EventCorrelationIDKey: ""}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("reads the origin", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(
+			DefUserInitiatorIDKey, "user",
+			DefTenantIDKey, "tenant",
+			"user-agent", "grpc-go",
+			DefSessionIDKey, "session",
+		))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4711}})
+
+		got, err := EventMetadataFromIncomingContext(ctx)
+		if err != nil {
+			t.Fatalf("EventMetadataFromIncomingContext() error = %v", err)
+		}
+
+		want := EventMetadata{
+			UserInitiatorIDKey: "user", TenantIDKey: "tenant", EventCorrelationIDKey: "",
+			SourceIPKey: "10.0.0.1", UserAgentKey: "grpc-go", SessionIDKey: "session",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("fails without the tenant", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(DefUserInitiatorIDKey, "user"))
+
+		_, err := EventMetadataFromIncomingContext(ctx)
+		if !errors.Is(err, errEventCreation) {
+			t.Fatalf("expected errEventCreation, got '%v'", err)
+		}
+	})
+
+	t.Run("fails without metadata", func(t *testing.T) {
+		_, err := EventMetadataFromIncomingContext(t.Context())
+		if !errors.Is(err, errEventCreation) {
+			t.Fatalf("expected errEventCreation, got '%v'", err)
+		}
+	})
+}
+
+func TestEventMetadataFromRequest(t *testing.T) {
+	t.Run("reads the default headers", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/keys", nil)
+		r.Header.Set("X-User-Id", "user")
+		r.Header.Set("X-Tenant-Id", "tenant")
+		r.Header.Set("X-Correlation-Id", "correlation")
+
+		got, err := EventMetadataFromRequest(r)
+		if err != nil {
+			t.Fatalf("EventMetadataFromRequest() error = %v", err)
+		}
+
+		want := EventMetadata{
+			UserInitiatorIDKey: "user", TenantIDKey: "tenant", EventCorrelationIDKey: "correlation",
+			SourceIPKey: "192.0.2.1",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("reads the configured headers", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/keys", nil)
+		r.Header.Set("X-Subject", "user")
+		r.Header.Set("X-Tenant-Id", "tenant")
+		r.Header.Set("X-Request-Id", "request")
+
+		got, err := EventMetadataFromRequest(r, WithUserInitiatorIDKey("x-subject"), WithEventCorrelationIDKey("X-Request-Id"))
+		if err != nil {
+			t.Fatalf("EventMetadataFromRequest() error = %v", err)
+		}
+
+		want := EventMetadata{
+			UserInitiatorIDKey: "user", TenantIDKey: "tenant", EventCorrelationIDKey: "request",
+			SourceIPKey: "192.0.2.1",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("reads the origin", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/keys", nil)
+		r.RemoteAddr = "[2001:db8::1]:443"
+		r.Header.Set("X-User-Id", "user")
+		r.Header.Set("X-Tenant-Id", "tenant")
+		r.Header.Set("User-Agent", "curl/8.0")
+		r.Header.Set("X-Session", "session")
+
+		got, err := EventMetadataFromRequest(r, WithSessionIDKey("X-Session"))
+		if err != nil {
+			t.Fatalf("EventMetadataFromRequest() error = %v", err)
+		}
+
+		want := EventMetadata{
+			UserInitiatorIDKey: "user", TenantIDKey: "tenant", EventCorrelationIDKey: "",
+			SourceIPKey: "2001:db8::1", UserAgentKey: "curl/8.0", SessionIDKey: "session",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("fails without the user", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/keys", nil)
+		r.Header.Set("X-Tenant-Id", "tenant")
+
+		_, err := EventMetadataFromRequest(r)
+		if !errors.Is(err, errEventCreation) {
+			t.Fatalf("expected errEventCreation, got '%v'", err)
+		}
+	})
+}
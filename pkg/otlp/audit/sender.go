@@ -0,0 +1,507 @@
+package otlpaudit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/samber/oops"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+const (
+	defSenderQueueSize     = 1000
+	defSenderBatchSize     = 100
+	defSenderFlushInterval = time.Second
+	defSenderMinBackoff    = 100 * time.Millisecond
+	defSenderMaxBackoff    = 30 * time.Second
+
+	sentEventsMetric         = "audit.events.sent"
+	sentEventsDescription    = "Number of audit events exported by the audit sender"
+	failedEventsMetric       = "audit.events.failed"
+	failedEventsDescription  = "Number of audit events whose export exhausted the retries of the audit sender"
+	droppedEventsMetric      = "audit.events.dropped"
+	droppedEventsDescription = "Number of audit events dropped by the audit sender"
+	queueDepthMetric         = "audit.queue.depth"
+	queueDepthDescription    = "Number of audit events queued for export by the audit sender"
+	dropReasonKey            = "reason"
+	dropReasonQueueFull      = "queue_full"
+	dropReasonExportFailed   = "export_failed"
+	dropReasonFiltered       = "filtered"
+)
+
+// senderItem is either an event or, with flushed set, a flush marker. The segment is the
+// segment of the write-ahead buffer holding the event.
+type senderItem struct {
+	logs    plog.Logs
+	segment uint64
+	flushed chan error
+}
+
+// AuditSender exports audit events asynchronously: the events are queued in a bounded
+// in-memory queue and exported in batches by a background worker, which retries failed
+// exports with an exponential backoff. The events are exported as OTLP logs, or as
+// CloudEvents or plain JSON objects for endpoints which cannot ingest OTLP, and delivered
+// to the endpoint of the audit logger, a NATS subject, a Kafka topic or a Transport set with
+// WithTransport.
+//
+// Events whose export exhausted the retries are counted by the audit.events.failed metric and
+// handed to the dead-letter handler set with WithDeadLetterHandler or written to the configured
+// dead-letter directory. Events which cannot be queued, failed without a dead-letter sink, or
+// are dropped by the configured filter are counted by the audit.events.dropped metric. The
+// exported events are counted by audit.events.sent and the queued ones by audit.queue.depth.
+// Events of critical operations must be exported with AuditLogger.MustAudit instead.
+//
+// If a buffer directory is configured, the queued events are also written to a write-ahead
+// buffer on disk, so they survive a crash or restart: the events left in the buffer are
+// exported again on the next start, before the new events. The failed exports are retried
+// until they succeed instead of being dropped, while new events are rejected once the queue
+// is full. An event may be exported twice if the process stops during its export.
+type AuditSender struct {
+	logger    *AuditLogger
+	cfg       commoncfg.AuditSender
+	encoder   encoder
+	transport Transport
+	filter    *eventFilter
+
+	sent       metric.Int64Counter
+	failed     metric.Int64Counter
+	dropped    metric.Int64Counter
+	queueDepth metric.Registration
+
+	buffer *writeAheadBuffer
+	// replay holds the segments of the write-ahead buffer left by the previous process
+	replay []uint64
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan senderItem
+
+	// ctx aborts the retries of the worker if Close times out
+	ctx    context.Context //nolint:containedctx
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSender creates an audit sender exporting the events to the configured endpoint
+// and starts its background worker, which runs until Close is called.
+func NewSender(config *commoncfg.Audit, opts ...LoggerOption) (*AuditSender, error) {
+	auditLogger, err := NewLogger(config, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := newEncoder(config.Sender.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := newEventFilter(config.Sender.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := auditLogger.transport
+	if transport == nil {
+		transport, err = newTransport(config.Sender, &auditLogger.client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := config.Sender
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defSenderQueueSize
+	}
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defSenderBatchSize
+	}
+
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defSenderFlushInterval
+	}
+
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = defSenderMinBackoff
+	}
+
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defSenderMaxBackoff
+	}
+
+	provider := auditLogger.meterProvider
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+
+	meter := provider.Meter(auditInstrumentationID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sender := &AuditSender{
+		logger:    auditLogger,
+		cfg:       cfg,
+		encoder:   enc,
+		transport: transport,
+		filter:    filter,
+		sent:      int64Counter(meter, sentEventsMetric, sentEventsDescription),
+		failed:    int64Counter(meter, failedEventsMetric, failedEventsDescription),
+		dropped:   int64Counter(meter, droppedEventsMetric, droppedEventsDescription),
+		queue:     make(chan senderItem, cfg.QueueSize),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+
+	sender.queueDepth = sender.registerQueueDepth(meter)
+
+	if cfg.BufferDir != "" {
+		sender.buffer, sender.replay, err = openWriteAheadBuffer(cfg.BufferDir, int64(cfg.MaxBufferFileSize))
+		if err != nil {
+			cancel()
+
+			if sender.queueDepth != nil {
+				_ = sender.queueDepth.Unregister()
+			}
+
+			return nil, oops.In(domain).
+				Hint("failed to open the audit write-ahead buffer").
+				Wrap(err)
+		}
+	}
+
+	go sender.run()
+
+	return sender, nil
+}
+
+// int64Counter creates the counter, or a no-op counter if the meter rejects it.
+func int64Counter(meter metric.Meter, name, description string) metric.Int64Counter {
+	counter, err := meter.Int64Counter(name, metric.WithDescription(description))
+	if err != nil {
+		return noop.Int64Counter{}
+	}
+
+	return counter
+}
+
+// registerQueueDepth observes the number of queued events. It returns nil if the meter
+// rejects the gauge.
+func (s *AuditSender) registerQueueDepth(meter metric.Meter) metric.Registration {
+	gauge, err := meter.Int64ObservableGauge(queueDepthMetric, metric.WithDescription(queueDepthDescription))
+	if err != nil {
+		return nil
+	}
+
+	registration, err := meter.RegisterCallback(func(_ context.Context, observer metric.Observer) error {
+		observer.ObserveInt64(gauge, int64(len(s.queue)))
+		return nil
+	}, gauge)
+	if err != nil {
+		return nil
+	}
+
+	return registration
+}
+
+// Send enriches the event and queues it for export. It does not wait for the export and
+// returns ErrAuditQueueFull if the queue is full, e.g. because the collector is unavailable.
+// Events dropped by the configured filter are counted, but not reported as an error.
+func (s *AuditSender) Send(ctx context.Context, logs plog.Logs) error {
+	if filtered := s.filter.filter(logs); filtered > 0 {
+		s.dropped.Add(ctx, int64(filtered), metric.WithAttributes(attribute.String(dropReasonKey, dropReasonFiltered)))
+
+		if logs.LogRecordCount() == 0 {
+			return nil
+		}
+	}
+
+	err := s.logger.prepare(ctx, logs)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return oops.In(domain).Wrap(ErrAuditSenderClosed)
+	}
+
+	// the events are linked into the hash chain and queued at once, so they are exported in the order of the chain
+	queued, err := s.logger.linkAndCommit(logs, func() (bool, error) {
+		queued, err := s.enqueue(logs)
+		if err != nil {
+			return false, oops.In(domain).
+				Hint("failed to write the audit event to the write-ahead buffer").
+				Wrap(err)
+		}
+
+		return queued, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !queued {
+		s.dropped.Add(ctx, 1, metric.WithAttributes(attribute.String(dropReasonKey, dropReasonQueueFull)))
+
+		return oops.In(domain).Wrap(ErrAuditQueueFull)
+	}
+
+	return nil
+}
+
+// enqueue queues the event unless the queue is full. With the write-ahead buffer, the
+// event is written to the buffer first.
+func (s *AuditSender) enqueue(logs plog.Logs) (bool, error) {
+	tryEnqueue := func(segment uint64) bool {
+		select {
+		case s.queue <- senderItem{logs: logs, segment: segment}:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if s.buffer == nil {
+		return tryEnqueue(0), nil
+	}
+
+	event, err := (&plog.JSONMarshaler{}).MarshalLogs(logs)
+	if err != nil {
+		return false, err
+	}
+
+	return s.buffer.add(event, tryEnqueue)
+}
+
+// Flush exports all events queued before the call and returns the error of their export.
+func (s *AuditSender) Flush(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return oops.In(domain).Wrap(ErrAuditSenderClosed)
+	}
+
+	flushed := make(chan error, 1)
+
+	select {
+	case s.queue <- senderItem{flushed: flushed}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-flushed:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting events and exports the queued ones. If the context is done before,
+// the pending retries are aborted, the remaining events are dropped and the context error
+// is returned.
+func (s *AuditSender) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	var err error
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		s.cancel()
+		<-s.done
+
+		err = ctx.Err()
+	}
+
+	s.cancel()
+
+	if s.queueDepth != nil {
+		err = errors.Join(err, s.queueDepth.Unregister())
+	}
+
+	err = errors.Join(err, s.transport.Close())
+
+	if s.buffer != nil {
+		err = errors.Join(err, s.buffer.close())
+	}
+
+	return err
+}
+
+// run exports the events left in the write-ahead buffer and then batches the queued events
+// until the queue is closed.
+func (s *AuditSender) run() {
+	defer close(s.done)
+
+	s.replayBuffer()
+
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]senderItem, 0, s.cfg.BatchSize)
+
+	for {
+		select {
+		case item, ok := <-s.queue:
+			if !ok {
+				_ = s.export(batch)
+				return
+			}
+
+			if item.flushed != nil {
+				item.flushed <- s.export(batch)
+				batch = batch[:0]
+
+				continue
+			}
+
+			batch = append(batch, item)
+			if len(batch) >= s.cfg.BatchSize {
+				_ = s.export(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			_ = s.export(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// replayBuffer exports the events left in the write-ahead buffer by the previous process
+// and removes their segments. The replay stops at the first failed export, the remaining
+// segments are exported on the next start.
+func (s *AuditSender) replayBuffer() {
+	for _, segment := range s.replay {
+		events, err := s.buffer.readSegment(segment)
+		if err != nil {
+			return
+		}
+
+		for batch := range slices.Chunk(events, s.cfg.BatchSize) {
+			err = s.sendBatch(mergeEvents(batch))
+			if err != nil {
+				return
+			}
+
+			s.sent.Add(s.ctx, int64(len(batch)))
+		}
+
+		err = s.buffer.removeSegment(segment)
+		if err != nil {
+			return
+		}
+	}
+
+	s.replay = nil
+}
+
+// export sends the queued events in a single request, which is retried with an exponential
+// backoff. If the retries are exhausted, the events are handed to the dead-letter sink, or
+// dropped without one, unless they are kept in the write-ahead buffer.
+func (s *AuditSender) export(batch []senderItem) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	events := make([]plog.Logs, 0, len(batch))
+	segments := make([]uint64, 0, len(batch))
+
+	for _, item := range batch {
+		events = append(events, item.logs)
+		segments = append(segments, item.segment)
+	}
+
+	clear(batch)
+
+	merged := mergeEvents(events)
+
+	err := s.sendBatch(merged)
+	if err != nil {
+		if s.buffer == nil {
+			// the context of the sender is canceled if Close timed out, the sink must still get the events
+			ctx := context.WithoutCancel(s.ctx)
+
+			s.failed.Add(ctx, int64(len(events)))
+
+			if !s.deadLetter(ctx, merged, err) {
+				s.dropped.Add(ctx, int64(len(events)),
+					metric.WithAttributes(attribute.String(dropReasonKey, dropReasonExportFailed)))
+			}
+		}
+
+		return oops.In(domain).
+			Hint("failed to send audit logs").
+			Wrap(err)
+	}
+
+	s.sent.Add(s.ctx, int64(len(events)))
+
+	if s.buffer != nil {
+		return s.buffer.ack(segments)
+	}
+
+	return nil
+}
+
+// mergeEvents merges the events into a single request, with one ResourceLogs and ScopeLogs
+// for the events of the same resource and scope.
+func mergeEvents(events []plog.Logs) plog.Logs {
+	merged := plog.NewLogs()
+	for _, logs := range events {
+		mergeLogs(merged, logs)
+	}
+
+	return merged
+}
+
+// sendBatch sends the merged events in the configured encoding with retries.
+func (s *AuditSender) sendBatch(merged plog.Logs) error {
+	payload, err := s.encoder.encode(merged)
+	if err != nil {
+		return err
+	}
+
+	return s.sendWithRetry([]byte(payload))
+}
+
+// sendWithRetry sends the payload until it is confirmed or the retries are exhausted.
+// With the write-ahead buffer, it is retried until the sender is closed.
+func (s *AuditSender) sendWithRetry(payload []byte) error {
+	backoff := s.cfg.MinBackoff
+
+	for retry := 0; ; retry++ {
+		err := s.transport.Send(s.ctx, s.encoder.contentType(), payload)
+		if err == nil || (s.buffer == nil && retry >= s.cfg.MaxRetries) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return errors.Join(err, s.ctx.Err())
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*2, s.cfg.MaxBackoff)
+	}
+}
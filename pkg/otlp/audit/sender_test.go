@@ -0,0 +1,240 @@
+package otlpaudit
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func newTestSender(t *testing.T, endpoint string, cfg commoncfg.AuditSender) *AuditSender {
+	t.Helper()
+
+	sender, err := NewSender(&commoncfg.Audit{
+		Endpoint:   endpoint,
+		HTTPClient: commoncfg.HTTPClient{Timeout: time.Second},
+		Sender:     cfg,
+	})
+	if err != nil {
+		t.Fatalf("NewSender() error = %v", err)
+	}
+
+	t.Cleanup(func() { _ = sender.Close(t.Context()) })
+
+	return sender
+}
+
+// recordCounter counts the requests and the log records received by a test collector.
+type recordCounter struct {
+	requests atomic.Int32
+	records  atomic.Int32
+}
+
+func (c *recordCounter) count(t *testing.T, r *http.Request) {
+	t.Helper()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Errorf("failed to read the request: %v", err)
+		return
+	}
+
+	logs, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(body)
+	if err != nil {
+		t.Errorf("failed to unmarshal the request: %v", err)
+		return
+	}
+
+	c.requests.Add(1)
+	c.records.Add(int32(logs.LogRecordCount())) //nolint:gosec // the test batches are small
+}
+
+func TestAuditSender(t *testing.T) {
+	t.Run("exports the events in batches", func(t *testing.T) {
+		var counter recordCounter
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			counter.count(t, r)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		sender := newTestSender(t, server.URL, commoncfg.AuditSender{BatchSize: 2, FlushInterval: time.Hour})
+
+		for range 3 {
+			err := sender.Send(t.Context(), newTestLogs())
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+		}
+
+		err := sender.Flush(t.Context())
+		if err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+
+		if counter.requests.Load() != 2 || counter.records.Load() != 3 {
+			t.Errorf("expected 3 records in 2 requests, got %d in %d", counter.records.Load(), counter.requests.Load())
+		}
+	})
+
+	t.Run("exports the events after the flush interval", func(t *testing.T) {
+		received := make(chan struct{}, 1)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			received <- struct{}{}
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		sender := newTestSender(t, server.URL, commoncfg.AuditSender{FlushInterval: 10 * time.Millisecond})
+
+		err := sender.Send(t.Context(), newTestLogs())
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+
+		select {
+		case <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected an export request")
+		}
+	})
+
+	t.Run("retries failed exports", func(t *testing.T) {
+		var calls atomic.Int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		sender := newTestSender(t, server.URL, commoncfg.AuditSender{MaxRetries: 3, MinBackoff: time.Millisecond})
+
+		err := sender.Send(t.Context(), newTestLogs())
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+
+		err = sender.Flush(t.Context())
+		if err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+
+		if calls.Load() != 3 {
+			t.Errorf("expected 3 calls, got %d", calls.Load())
+		}
+	})
+
+	t.Run("drops the events once the retries are exhausted", func(t *testing.T) {
+		var calls atomic.Int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		sender := newTestSender(t, server.URL, commoncfg.AuditSender{MaxRetries: 1, MinBackoff: time.Millisecond})
+
+		err := sender.Send(t.Context(), newTestLogs())
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+
+		err = sender.Flush(t.Context())
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+
+		if calls.Load() != 2 {
+			t.Errorf("expected 2 calls, got %d", calls.Load())
+		}
+	})
+
+	t.Run("rejects events while the queue is full", func(t *testing.T) {
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		defer close(release)
+
+		sender := newTestSender(t, server.URL, commoncfg.AuditSender{QueueSize: 1, BatchSize: 1})
+
+		// the worker blocks on the export of the first event
+		err := sender.Send(t.Context(), newTestLogs())
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+
+		<-started
+
+		err = sender.Send(t.Context(), newTestLogs())
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+
+		err = sender.Send(t.Context(), newTestLogs())
+		if !errors.Is(err, ErrAuditQueueFull) {
+			t.Fatalf("expected ErrAuditQueueFull, got '%v'", err)
+		}
+	})
+
+	t.Run("exports the queued events on close", func(t *testing.T) {
+		var counter recordCounter
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			counter.count(t, r)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		sender := newTestSender(t, server.URL, commoncfg.AuditSender{FlushInterval: time.Hour})
+
+		err := sender.Send(t.Context(), newTestLogs())
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+
+		err = sender.Close(t.Context())
+		if err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		if counter.records.Load() != 1 {
+			t.Errorf("expected 1 exported record, got %d", counter.records.Load())
+		}
+
+		err = sender.Send(t.Context(), newTestLogs())
+		if !errors.Is(err, ErrAuditSenderClosed) {
+			t.Fatalf("expected ErrAuditSenderClosed, got '%v'", err)
+		}
+
+		err = sender.Flush(t.Context())
+		if !errors.Is(err, ErrAuditSenderClosed) {
+			t.Fatalf("expected ErrAuditSenderClosed, got '%v'", err)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package otlpaudit
+
+import (
+	"context"
+
+	"github.com/samber/oops"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// Transport delivers the encoded events of the AuditSender, e.g. to a message broker.
+// Send must only return nil once the events are confirmed, failed sends are retried.
+// The transport is closed by AuditSender.Close.
+//
+// The http, nats and kafka transports are built in, other brokers are plugged in with
+// WithTransport by wrapping their producer.
+type Transport interface {
+	Send(ctx context.Context, contentType string, payload []byte) error
+	Close() error
+}
+
+// WithTransport sets the transport of the AuditSender, which then ignores the configured one.
+func WithTransport(transport Transport) LoggerOption {
+	return func(auditLogger *AuditLogger) {
+		if transport != nil {
+			auditLogger.transport = transport
+		}
+	}
+}
+
+func newTransport(cfg commoncfg.AuditSender, client *otlpClient) (Transport, error) {
+	switch cfg.Transport {
+	case "", commoncfg.AuditHTTPTransport:
+		return httpTransport{client: client}, nil
+	case commoncfg.AuditNATSTransport:
+		return newNATSTransport(cfg.NATS)
+	case commoncfg.AuditKafkaTransport:
+		return newKafkaTransport(cfg.Kafka)
+	default:
+		return nil, oops.In(domain).
+			With("transport", cfg.Transport).
+			Wrap(errUnknownTransport)
+	}
+}
+
+// httpTransport posts the events to the endpoint of the audit logger.
+type httpTransport struct {
+	client *otlpClient
+}
+
+func (t httpTransport) Send(ctx context.Context, contentType string, payload []byte) error {
+	return t.client.sendAs(ctx, contentType, string(payload))
+}
+
+func (httpTransport) Close() error {
+	return nil
+}
@@ -0,0 +1,55 @@
+package otlpaudit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// recordingTransport records the payloads sent by the AuditSender.
+type recordingTransport struct {
+	payloads chan []byte
+	closed   bool
+}
+
+func (r *recordingTransport) Send(_ context.Context, _ string, payload []byte) error {
+	r.payloads <- payload
+	return nil
+}
+
+func (r *recordingTransport) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestAuditSenderWithTransport(t *testing.T) {
+	transport := &recordingTransport{payloads: make(chan []byte, 1)}
+
+	sender, err := NewSender(&commoncfg.Audit{Endpoint: "http://localhost:1234/logs"}, WithTransport(transport))
+	if err != nil {
+		t.Fatalf("NewSender() error = %v", err)
+	}
+
+	err = sender.Send(t.Context(), newTestLogs())
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	err = sender.Close(t.Context())
+	if err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(<-transport.payloads) == 0 || !transport.closed {
+		t.Errorf("expected the events to be sent over the transport, which is closed")
+	}
+}
+
+func TestNewTransportUnknown(t *testing.T) {
+	_, err := newTransport(commoncfg.AuditSender{Transport: "amqp"}, &otlpClient{})
+	if !errors.Is(err, errUnknownTransport) {
+		t.Fatalf("expected errUnknownTransport, got '%v'", err)
+	}
+}
@@ -0,0 +1,100 @@
+package otlpaudit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// putValue sets the attribute to the value. Maps, structs, slices and arrays keep their
+// structure as nested map and slice values, with the keys of maps sorted, so old and new
+// values can be diffed and queried downstream. All other values are set as strings.
+func putValue(attributes pcommon.Map, key string, value any) {
+	if !isStructured(value) {
+		attributes.PutStr(key, fmt.Sprint(value))
+		return
+	}
+
+	decoded, err := decodeJSON(value)
+	if err != nil {
+		attributes.PutStr(key, fmt.Sprint(value))
+		return
+	}
+
+	setValue(attributes.PutEmpty(key), decoded)
+}
+
+// isStructured reports if the value, or the value it points to, is a map, struct, slice or array.
+// Byte slices are not structured, they are set as strings.
+func isStructured(value any) bool {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Array:
+		return true
+	case reflect.Slice:
+		return v.Type().Elem().Kind() != reflect.Uint8
+	default:
+		return false
+	}
+}
+
+// decodeJSON converts the value into its JSON representation of maps, slices and scalars,
+// honoring the json tags and json.Marshaler implementations of the value.
+func decodeJSON(value any) (any, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var decoded any
+
+	err = decoder.Decode(&decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+func setValue(dst pcommon.Value, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		m := dst.SetEmptyMap()
+		m.EnsureCapacity(len(v))
+
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			setValue(m.PutEmpty(key), v[key])
+		}
+	case []any:
+		s := dst.SetEmptySlice()
+		s.EnsureCapacity(len(v))
+
+		for _, elem := range v {
+			setValue(s.AppendEmpty(), elem)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			dst.SetInt(i)
+		} else if f, err := v.Float64(); err == nil {
+			dst.SetDouble(f)
+		} else {
+			dst.SetStr(v.String())
+		}
+	case string:
+		dst.SetStr(v)
+	case bool:
+		dst.SetBool(v)
+	}
+}
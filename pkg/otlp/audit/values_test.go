@@ -0,0 +1,80 @@
+package otlpaudit
+
+import (
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+func TestPutValue(t *testing.T) {
+	type settings struct {
+		Name    string   `json:"name"`
+		Retries int      `json:"retries"`
+		Ratio   float64  `json:"ratio"`
+		Tags    []string `json:"tags"`
+	}
+
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{name: "string", value: "value", want: "value"},
+		{name: "number", value: 42, want: "42"},
+		{name: "bytes", value: []byte("raw"), want: "[114 97 119]"},
+		{name: "map with sorted keys", value: map[string]any{"b": 2, "a": true, "c": nil}, want: `{"a":true,"b":2,"c":null}`},
+		{
+			name:  "struct",
+			value: &settings{Name: "cmk", Retries: 3, Ratio: 0.5, Tags: []string{"x", "y"}},
+			want:  `{"name":"cmk","ratio":0.5,"retries":3,"tags":["x","y"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attributes := pcommon.NewMap()
+			putValue(attributes, ValueKey, tt.value)
+
+			got, _ := attributes.Get(ValueKey)
+			if got.AsString() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got.AsString())
+			}
+		})
+	}
+}
+
+func TestStructuredEventValues(t *testing.T) {
+	metadata := EventMetadata{UserInitiatorIDKey: "userInitiatorID", TenantIDKey: "tenantID"}
+
+	logs, err := NewConfigurationUpdateEvent(metadata, "objectID",
+		map[string]any{"rotation": "30d", "enabled": true},
+		map[string]any{"rotation": "90d", "enabled": true})
+	if err != nil {
+		t.Fatalf("NewConfigurationUpdateEvent() error = %v", err)
+	}
+
+	record, _ := firstLogRecord(logs)
+
+	oldValue, _ := record.Attributes().Get(OldValueKey)
+	newValue, _ := record.Attributes().Get(NewValueKey)
+
+	if oldValue.Type() != pcommon.ValueTypeMap || newValue.Type() != pcommon.ValueTypeMap {
+		t.Fatalf("expected map values, got %s and %s", oldValue.Type(), newValue.Type())
+	}
+
+	rotation, _ := newValue.Map().Get("rotation")
+	if rotation.Str() != "90d" {
+		t.Errorf("expected the nested rotation 90d, got %q", rotation.Str())
+	}
+
+	limiter := newValueLimiter(commoncfg.AuditLimits{MaxValueSize: 20, TruncationMarker: "..."}, nil)
+	limiter.limitLogs(t.Context(), logs)
+
+	oldValue, _ = record.Attributes().Get(OldValueKey)
+	if oldValue.Type() != pcommon.ValueTypeStr || !strings.HasPrefix(oldValue.Str(), `{"enabled":true`) {
+		t.Errorf("expected the truncated JSON of the large value, got %s", oldValue.AsString())
+	}
+}
@@ -0,0 +1,297 @@
+package otlp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials/local"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commonhttp"
+	"github.com/openkcm/common-sdk/pkg/health"
+)
+
+// HealthCheckName is the name of the health check returned by NewHealthCheck.
+const HealthCheckName = "telemetry"
+
+var (
+	// ErrExporterUnauthenticated is returned by HealthCheck if an OTLP endpoint rejects the
+	// credentials of the exporter.
+	ErrExporterUnauthenticated = errors.New("telemetry exporter is not authenticated")
+	// ErrExporterUnavailable is returned by HealthCheck if an OTLP endpoint cannot be reached
+	// or fails to accept the export.
+	ErrExporterUnavailable = errors.New("telemetry exporter is unavailable")
+)
+
+// healthSignal sends an empty export request of the signal to probe its OTLP endpoints.
+type healthSignal struct {
+	name    string
+	urlPath string
+	export  func(ctx context.Context, conn *grpc.ClientConn) error
+}
+
+var (
+	traceHealthSignal = healthSignal{
+		name:    "traces",
+		urlPath: "/v1/traces",
+		export: func(ctx context.Context, conn *grpc.ClientConn) error {
+			_, err := coltracepb.NewTraceServiceClient(conn).Export(ctx, &coltracepb.ExportTraceServiceRequest{})
+			return err
+		},
+	}
+	metricHealthSignal = healthSignal{
+		name:    "metrics",
+		urlPath: "/v1/metrics",
+		export: func(ctx context.Context, conn *grpc.ClientConn) error {
+			_, err := colmetricpb.NewMetricsServiceClient(conn).Export(ctx, &colmetricpb.ExportMetricsServiceRequest{})
+			return err
+		},
+	}
+	logHealthSignal = healthSignal{
+		name:    "logs",
+		urlPath: "/v1/logs",
+		export: func(ctx context.Context, conn *grpc.ClientConn) error {
+			_, err := collogspb.NewLogsServiceClient(conn).Export(ctx, &collogspb.ExportLogsServiceRequest{})
+			return err
+		},
+	}
+)
+
+// HealthCheck verifies that the OTLP endpoints of the enabled signals are reachable and
+// accept the credentials of their exporters, by sending an empty export request to each
+// endpoint. The exporters without endpoint, i.e. stdout, file and prometheus, are skipped.
+func HealthCheck(ctx context.Context, telCfg *commoncfg.Telemetry) error {
+	var errs []error
+
+	if telCfg.Traces.Enabled {
+		errs = append(errs, probeExporters(ctx, traceHealthSignal, traceExporters(&telCfg.Traces))...)
+	}
+
+	if telCfg.Metrics.Enabled {
+		errs = append(errs, probeExporters(ctx, metricHealthSignal, metricExporters(&telCfg.Metrics))...)
+	}
+
+	if telCfg.Logs.Enabled {
+		errs = append(errs, probeExporters(ctx, logHealthSignal, loggerExporters(&telCfg.Logs))...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// NewHealthCheck returns a health check running HealthCheck, e.g. to let the readiness of
+// the service reflect a broken telemetry pipeline:
+//
+//	checker := health.NewChecker(
+//	    health.WithPeriodicCheck(time.Minute, 0, otlp.NewHealthCheck(&cfg.Telemetry)),
+//	)
+func NewHealthCheck(telCfg *commoncfg.Telemetry) health.Check {
+	return health.Check{
+		Name: HealthCheckName,
+		Check: func(ctx context.Context) error {
+			return HealthCheck(ctx, telCfg)
+		},
+	}
+}
+
+func probeExporters(ctx context.Context, signal healthSignal, exporters []commoncfg.TelemetryExporter) []error {
+	var errs []error
+
+	for _, exp := range exporters {
+		var err error
+
+		switch exp.Protocol {
+		case commoncfg.GRPCProtocol:
+			err = probeGRPCExporter(ctx, signal, &exp)
+		case commoncfg.HTTPProtocol:
+			err = probeHTTPExporter(ctx, signal, &exp)
+		default:
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s exporter %s: %w", signal.name, exp.Name, err))
+		}
+	}
+
+	return errs
+}
+
+// probeGRPCExporter sends an empty export request with the credentials of the gRPC exporter.
+func probeGRPCExporter(ctx context.Context, signal healthSignal, cfg *commoncfg.TelemetryExporter) error {
+	options := make([]grpc.DialOption, 0, 2)
+
+	var authorization string
+
+	switch cfg.SecretRef.Type {
+	case commoncfg.ApiTokenSecretType:
+		token, err := computeAPITokenAuthorizationHeader(&cfg.SecretRef.APIToken)
+		if err != nil {
+			return err
+		}
+
+		authorization = token
+	case commoncfg.BasicSecretType:
+		value, err := computeBasicAuthorizationHeader(&cfg.SecretRef.Basic)
+		if err != nil {
+			return err
+		}
+
+		authorization = value
+	case commoncfg.MTLSSecretType:
+		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.SecretRef.MTLS)
+		if err != nil {
+			return err
+		}
+
+		options = append(options, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	case commoncfg.OAuth2SecretType:
+		source, err := newOAuth2TokenSource(&cfg.SecretRef.OAuth2)
+		if err != nil {
+			return err
+		}
+
+		options = append(options, grpc.WithPerRPCCredentials(source))
+	case commoncfg.InsecureSecretType:
+		options = append(options, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	default:
+		return fmt.Errorf("telemetry health check doesn't support secret type: %s", cfg.SecretRef.Type)
+	}
+
+	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Host)
+	if err != nil {
+		return err
+	}
+
+	// the exporters use TLS unless they are insecure, reach a unix socket or have their own
+	// TLS configuration
+	switch {
+	case isUnixEndpoint(string(host)) && cfg.SecretRef.Type != commoncfg.MTLSSecretType:
+		options = append(options, grpc.WithTransportCredentials(local.NewCredentials()))
+	case len(options) == 0 || cfg.SecretRef.Type == commoncfg.OAuth2SecretType:
+		options = append(options, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})))
+	}
+
+	headers, err := exporterHeaders(cfg)
+	if err != nil {
+		return err
+	}
+
+	if authorization != "" {
+		headers[AuthorizationHeader] = authorization
+	}
+
+	conn, err := grpc.NewClient(string(host), options...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrExporterUnavailable, err)
+	}
+	defer conn.Close()
+
+	err = signal.export(metadata.NewOutgoingContext(ctx, metadata.New(headers)), conn)
+
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return fmt.Errorf("%w: %w", ErrExporterUnauthenticated, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrExporterUnavailable, err)
+	}
+}
+
+// probeHTTPExporter sends an empty export request with the credentials of the HTTP exporter.
+func probeHTTPExporter(ctx context.Context, signal healthSignal, cfg *commoncfg.TelemetryExporter) error {
+	client := &http.Client{}
+	scheme := "https"
+
+	switch cfg.SecretRef.Type {
+	case commoncfg.ApiTokenSecretType:
+		c, err := commonhttp.NewClientFromAPIToken(&cfg.SecretRef.APIToken)
+		if err != nil {
+			return err
+		}
+
+		client = c
+	case commoncfg.BasicSecretType:
+		c, err := commonhttp.NewClientFromBasic(&cfg.SecretRef.Basic)
+		if err != nil {
+			return err
+		}
+
+		client = c
+	case commoncfg.MTLSSecretType:
+		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.SecretRef.MTLS)
+		if err != nil {
+			return err
+		}
+
+		client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	case commoncfg.OAuth2SecretType:
+		source, err := newOAuth2TokenSource(&cfg.SecretRef.OAuth2)
+		if err != nil {
+			return err
+		}
+
+		client = source.HTTPClient()
+	case commoncfg.InsecureSecretType:
+		scheme = "http"
+	default:
+		return fmt.Errorf("telemetry health check doesn't support secret type: %s", cfg.SecretRef.Type)
+	}
+
+	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Host)
+	if err != nil {
+		return err
+	}
+
+	headers, err := exporterHeaders(cfg)
+	if err != nil {
+		return err
+	}
+
+	urlPath := cfg.URL
+	if urlPath == "" {
+		urlPath = signal.urlPath
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, scheme+"://"+string(host)+urlPath, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrExporterUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: status %d", ErrExporterUnauthenticated, resp.StatusCode)
+	case resp.StatusCode >= http.StatusMultipleChoices:
+		return fmt.Errorf("%w: status %d", ErrExporterUnavailable, resp.StatusCode)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package otlp_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+
+	config "github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/otlp"
+)
+
+// traceService accepts the exports with the expected tenant header.
+type traceService struct {
+	coltracepb.UnimplementedTraceServiceServer
+}
+
+func (traceService) Export(ctx context.Context, _ *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if tenant := md.Get("x-tenant"); len(tenant) != 1 || tenant[0] != "tenant-a" {
+		return nil, status.Error(codes.Unauthenticated, "unknown tenant")
+	}
+
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func Test_OTLP_HealthCheck_GRPC(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, traceService{})
+
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	telemetry := func(tenant string) *config.Telemetry {
+		return &config.Telemetry{
+			Traces: config.Trace{
+				Enabled:   true,
+				Protocol:  config.GRPCProtocol,
+				Host:      config.SourceRef{Source: config.EmbeddedSourceValue, Value: listener.Addr().String()},
+				SecretRef: config.SecretRef{Type: config.InsecureSecretType},
+				Headers: map[string]config.SourceRef{
+					"X-Tenant": {Source: config.EmbeddedSourceValue, Value: tenant},
+				},
+			},
+		}
+	}
+
+	require.NoError(t, otlp.HealthCheck(t.Context(), telemetry("tenant-a")))
+	require.ErrorIs(t, otlp.HealthCheck(t.Context(), telemetry("tenant-b")), otlp.ErrExporterUnauthenticated)
+}
+
+func Test_OTLP_HealthCheck_HTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("X-Tenant") {
+		case "tenant-a":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	telemetry := func(addr, tenant string) *config.Telemetry {
+		return &config.Telemetry{
+			Logs: config.Log{
+				Enabled:   true,
+				Protocol:  config.HTTPProtocol,
+				Host:      config.SourceRef{Source: config.EmbeddedSourceValue, Value: addr},
+				SecretRef: config.SecretRef{Type: config.InsecureSecretType},
+				Headers: map[string]config.SourceRef{
+					"X-Tenant": {Source: config.EmbeddedSourceValue, Value: tenant},
+				},
+			},
+			Metrics: config.Metric{
+				Enabled:  true,
+				Protocol: config.StdoutProtocol,
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		telCfg  *config.Telemetry
+		wantErr error
+	}{
+		{
+			name:   "reachable and authenticated",
+			telCfg: telemetry(server.Listener.Addr().String(), "tenant-a"),
+		},
+		{
+			name:    "rejected credentials",
+			telCfg:  telemetry(server.Listener.Addr().String(), "tenant-b"),
+			wantErr: otlp.ErrExporterUnauthenticated,
+		},
+		{
+			name:    "unreachable",
+			telCfg:  telemetry(closed.Listener.Addr().String(), "tenant-a"),
+			wantErr: otlp.ErrExporterUnavailable,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			check := otlp.NewHealthCheck(tc.telCfg)
+			require.Equal(t, otlp.HealthCheckName, check.Name)
+
+			err := check.Check(t.Context())
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, tc.wantErr)
+			require.ErrorContains(t, err, "logs exporter default")
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/shirou/gopsutil/v4/net"
+	"github.com/shirou/gopsutil/v4/process"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/semconv/v1.41.0/processconv"
+	"go.opentelemetry.io/otel/semconv/v1.41.0/systemconv"
+)
+
+// instrumentationName is the name of the meter of the host and process metrics.
+const instrumentationName = "github.com/openkcm/common-sdk/pkg/otlp"
+
+// startHostMetrics collects the CPU, memory and network metrics of the host, so the basic
+// infrastructure dashboards do not require a node agent.
+func startHostMetrics(mp metric.MeterProvider) error {
+	meter := mp.Meter(instrumentationName)
+
+	cpuTime, err := systemconv.NewCPUTime(meter)
+	if err != nil {
+		return err
+	}
+
+	memoryUsage, err := systemconv.NewMemoryUsage(meter)
+	if err != nil {
+		return err
+	}
+
+	memoryUtilization, err := systemconv.NewMemoryUtilization(meter)
+	if err != nil {
+		return err
+	}
+
+	networkIO, err := systemconv.NewNetworkIO(meter)
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		var errs []error
+
+		times, err := cpu.TimesWithContext(ctx, false)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read host cpu times: %w", err))
+		}
+
+		for _, t := range times {
+			for mode, seconds := range map[systemconv.CPUModeAttr]float64{
+				systemconv.CPUModeUser:      t.User,
+				systemconv.CPUModeSystem:    t.System,
+				systemconv.CPUModeNice:      t.Nice,
+				systemconv.CPUModeIdle:      t.Idle,
+				systemconv.CPUModeIOWait:    t.Iowait,
+				systemconv.CPUModeInterrupt: t.Irq + t.Softirq,
+				systemconv.CPUModeSteal:     t.Steal,
+			} {
+				o.ObserveFloat64(cpuTime.Inst(), seconds, metric.WithAttributes(cpuTime.AttrCPUMode(mode)))
+			}
+		}
+
+		vm, err := mem.VirtualMemoryWithContext(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read host memory: %w", err))
+		} else {
+			for state, bytes := range map[systemconv.MemoryStateAttr]uint64{
+				systemconv.MemoryStateUsed:    vm.Used,
+				systemconv.MemoryStateFree:    vm.Free,
+				systemconv.MemoryStateBuffers: vm.Buffers,
+				systemconv.MemoryStateCached:  vm.Cached,
+			} {
+				o.ObserveInt64(memoryUsage.Inst(), clampInt64(bytes),
+					metric.WithAttributes(memoryUsage.AttrMemoryState(state)))
+
+				if vm.Total > 0 {
+					o.ObserveFloat64(memoryUtilization.Inst(), float64(bytes)/float64(vm.Total),
+						metric.WithAttributes(memoryUtilization.AttrMemoryState(state)))
+				}
+			}
+		}
+
+		counters, err := net.IOCountersWithContext(ctx, true)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read host network counters: %w", err))
+		}
+
+		for _, c := range counters {
+			o.ObserveInt64(networkIO.Inst(), clampInt64(c.BytesSent), metric.WithAttributes(
+				networkIO.AttrNetworkInterfaceName(c.Name),
+				networkIO.AttrNetworkIODirection(systemconv.NetworkIODirectionTransmit),
+			))
+			o.ObserveInt64(networkIO.Inst(), clampInt64(c.BytesRecv), metric.WithAttributes(
+				networkIO.AttrNetworkInterfaceName(c.Name),
+				networkIO.AttrNetworkIODirection(systemconv.NetworkIODirectionReceive),
+			))
+		}
+
+		return errors.Join(errs...)
+	}, cpuTime.Inst(), memoryUsage.Inst(), memoryUtilization.Inst(), networkIO.Inst())
+
+	return err
+}
+
+// startProcessMetrics collects the CPU, memory and thread metrics of the current process.
+func startProcessMetrics(mp metric.MeterProvider) error {
+	meter := mp.Meter(instrumentationName)
+
+	proc, err := process.NewProcess(int32(os.Getpid())) //nolint:gosec // process ids fit into int32
+	if err != nil {
+		return fmt.Errorf("failed to inspect the current process: %w", err)
+	}
+
+	cpuTime, err := processconv.NewCPUTime(meter)
+	if err != nil {
+		return err
+	}
+
+	memoryUsage, err := processconv.NewMemoryUsageObservable(meter)
+	if err != nil {
+		return err
+	}
+
+	memoryVirtual, err := processconv.NewMemoryVirtualObservable(meter)
+	if err != nil {
+		return err
+	}
+
+	threadCount, err := processconv.NewThreadCountObservable(meter)
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		var errs []error
+
+		times, err := proc.TimesWithContext(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read process cpu times: %w", err))
+		} else {
+			o.ObserveFloat64(cpuTime.Inst(), times.User,
+				metric.WithAttributes(cpuTime.AttrCPUMode(processconv.CPUModeUser)))
+			o.ObserveFloat64(cpuTime.Inst(), times.System,
+				metric.WithAttributes(cpuTime.AttrCPUMode(processconv.CPUModeSystem)))
+		}
+
+		info, err := proc.MemoryInfoWithContext(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read process memory: %w", err))
+		} else {
+			o.ObserveInt64(memoryUsage.Inst(), clampInt64(info.RSS))
+			o.ObserveInt64(memoryVirtual.Inst(), clampInt64(info.VMS))
+		}
+
+		threads, err := proc.NumThreadsWithContext(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read process threads: %w", err))
+		} else {
+			o.ObserveInt64(threadCount.Inst(), int64(threads))
+		}
+
+		return errors.Join(errs...)
+	}, cpuTime.Inst(), memoryUsage.Inst(), memoryVirtual.Inst(), threadCount.Inst())
+
+	return err
+}
+
+// clampInt64 converts the counters read from the operating system to the int64 of the instruments.
+func clampInt64(v uint64) int64 {
+	if v > math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return int64(v)
+}
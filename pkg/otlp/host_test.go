@@ -0,0 +1,70 @@
+package otlp_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	config "github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/otlp"
+)
+
+func Test_OTLP_Init_HostAndProcessMetrics(t *testing.T) {
+	tests := []struct {
+		name      string
+		host      bool
+		process   bool
+		collected []string
+		missing   []string
+	}{
+		{
+			name:    "disabled",
+			missing: []string{"system.cpu.time", "process.cpu.time"},
+		},
+		{
+			name:      "host metrics",
+			host:      true,
+			collected: []string{"system.cpu.time", "system.memory.usage", "system.memory.utilization", "system.network.io"},
+			missing:   []string{"process.cpu.time"},
+		},
+		{
+			name:      "process metrics",
+			process:   true,
+			collected: []string{"process.cpu.time", "process.memory.usage", "process.memory.virtual", "process.thread.count"},
+			missing:   []string{"system.cpu.time"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "metrics.json")
+			appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "host"}}
+			telemetry := &config.Telemetry{
+				Metrics: config.Metric{
+					Enabled:        true,
+					Protocol:       config.FileProtocol,
+					Path:           path,
+					HostMetrics:    tc.host,
+					ProcessMetrics: tc.process,
+				},
+			}
+
+			providers, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+			require.NoError(t, err)
+			require.NoError(t, providers.Shutdown(t.Context()))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			for _, name := range tc.collected {
+				require.Contains(t, string(data), `"Name":"`+name+`"`)
+			}
+
+			for _, name := range tc.missing {
+				require.NotContains(t, string(data), `"Name":"`+name+`"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package otlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewTransport(t *testing.T) {
+	transport := NewTransport(nil)
+	assert.NotNil(t, transport)
+}
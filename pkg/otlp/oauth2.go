@@ -0,0 +1,153 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commonhttp"
+)
+
+// tokenExpiryMargin is the time before the expiry of a token at which it is refreshed.
+const tokenExpiryMargin = 30 * time.Second
+
+// ErrOAuth2Token is returned when no access token can be obtained from the OAuth2 token endpoint.
+var ErrOAuth2Token = errors.New("failed to obtain oauth2 access token")
+
+// oauth2TokenSource obtains access tokens with the OAuth2 client credentials flow and
+// caches them until shortly before they expire. The client credentials are injected into
+// the token requests by the commonhttp OAuth2 client, so all its authentication methods
+// are supported.
+type oauth2TokenSource struct {
+	tokenURL string
+	client   *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func newOAuth2TokenSource(cfg *commoncfg.OAuth2) (*oauth2TokenSource, error) {
+	if cfg.URL == nil {
+		return nil, fmt.Errorf("%w: oauth2.url is missing", ErrOAuth2Token)
+	}
+
+	client, err := commonhttp.NewClientFromOAuth2(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenURL, err := commoncfg.ExtractValueFromSourceRef(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract oauth2 token url: %w", err)
+	}
+
+	return &oauth2TokenSource{
+		tokenURL: string(tokenURL),
+		client:   client,
+	}, nil
+}
+
+// Token returns the cached access token, or requests a new one if it is about to expire.
+func (s *oauth2TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.expiresAt.IsZero() || time.Now().Before(s.expiresAt)) {
+		return s.token, nil
+	}
+
+	body := url.Values{"grant_type": {"client_credentials"}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrOAuth2Token, err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrOAuth2Token, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: token endpoint responded with status %d", ErrOAuth2Token, resp.StatusCode)
+	}
+
+	var token tokenResponse
+
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrOAuth2Token, err)
+	}
+
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("%w: token endpoint responded without access token", ErrOAuth2Token)
+	}
+
+	s.token = token.AccessToken
+	s.expiresAt = time.Time{}
+
+	// tokens without expiry are kept until the exporter is shut down
+	if token.ExpiresIn > 0 {
+		s.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpiryMargin)
+	}
+
+	return s.token, nil
+}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials for the gRPC exporters.
+func (s *oauth2TokenSource) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := s.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{strings.ToLower(AuthorizationHeader): "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials, the tokens are
+// only sent over TLS.
+func (s *oauth2TokenSource) RequireTransportSecurity() bool {
+	return true
+}
+
+// HTTPClient returns a client for the HTTP exporters, which authorizes the requests
+// with the access token.
+func (s *oauth2TokenSource) HTTPClient() *http.Client {
+	return &http.Client{Transport: &bearerRoundTripper{source: s, next: http.DefaultTransport}}
+}
+
+// bearerRoundTripper sets the access token of the token source as bearer token.
+type bearerRoundTripper struct {
+	source *oauth2TokenSource
+	next   http.RoundTripper
+}
+
+func (t *bearerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	newReq := req.Clone(req.Context())
+	newReq.Header.Set(AuthorizationHeader, "Bearer "+token)
+
+	return t.next.RoundTrip(newReq)
+}
@@ -1,15 +1,20 @@
 package otlp
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
@@ -22,13 +27,17 @@ import (
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
 	"go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/exemplar"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/local"
+	"google.golang.org/grpc/encoding/gzip"
 
 	dtsdk "github.com/Dynatrace/OneAgent-SDK-for-Go/sdk"
 	slogmulti "github.com/samber/slog-multi"
@@ -36,17 +45,35 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
 
 	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commonfs/notifier"
 	"github.com/openkcm/common-sdk/pkg/commonhttp"
 	"github.com/openkcm/common-sdk/pkg/logger"
 	"github.com/openkcm/common-sdk/pkg/utils"
 )
 
+// Defaults of the telemetry signals which are not configured.
 const (
 	DefPeriodicReaderInterval = 2 * time.Second
 	DefBatchTimeout           = 2 * time.Second
 	DefShutdownTimeout        = 5 * time.Second
 
 	AuthorizationHeader = "Authorization"
+
+	// AttrServiceInstanceRestartCount is the resource attribute holding the restart counter of the instance.
+	AttrServiceInstanceRestartCount = "service.instance.restart_count"
+)
+
+var (
+	// ErrUnsupportedSampler is returned by Init for an unknown trace sampler type.
+	ErrUnsupportedSampler = errors.New("unsupported trace sampler")
+	// ErrUnsupportedProtocol is returned by Init for an exporter with an unknown protocol.
+	ErrUnsupportedProtocol = errors.New("unsupported telemetry exporter protocol")
+	// ErrUnsupportedPropagator is returned by Init for an unknown propagator.
+	ErrUnsupportedPropagator = errors.New("unsupported propagator")
+	// ErrUnsupportedCompression is returned by Init for an exporter with an unknown compression.
+	ErrUnsupportedCompression = errors.New("unsupported telemetry exporter compression")
+	// ErrUnsupportedTemporality is returned by Init for a metric exporter with an unknown temporality.
+	ErrUnsupportedTemporality = errors.New("unsupported metric temporality")
 )
 
 type registry struct {
@@ -61,6 +88,10 @@ type registry struct {
 
 	logger           *slog.Logger
 	shutdownComplete chan struct{}
+
+	reloadCredentials bool
+	reloadInterval    time.Duration
+	notifiers         []*notifier.Notifier
 }
 
 type Option func(*registry)
@@ -87,13 +118,88 @@ func WithShutdownComplete(shutdownComplete chan struct{}) Option {
 	}
 }
 
+// Providers is returned by Init to flush and shut down the telemetry providers, e.g. in a
+// deferred call of main, instead of waiting for the context of Init to be done.
+type Providers struct {
+	reg *registry
+
+	stopped      chan struct{}
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// TracerProvider returns the provider of the traces, which is also set as global provider.
+// It is nil if the traces are disabled.
+func (p *Providers) TracerProvider() *trace.TracerProvider {
+	return p.reg.traceProvider
+}
+
+// MeterProvider returns the provider of the metrics, which is also set as global provider.
+// It is nil if the metrics are disabled.
+func (p *Providers) MeterProvider() *metric.MeterProvider {
+	return p.reg.meterProvider
+}
+
+// LoggerProvider returns the provider of the logs, which is also set as global provider.
+// It is nil if the logs are disabled.
+func (p *Providers) LoggerProvider() *log.LoggerProvider {
+	return p.reg.loggerProvider
+}
+
+// ForceFlush immediately exports the pending telemetry of all providers.
+func (p *Providers) ForceFlush(ctx context.Context) error {
+	var errs []error
+
+	if p.reg.traceProvider != nil {
+		errs = append(errs, p.reg.traceProvider.ForceFlush(ctx))
+	}
+
+	if p.reg.meterProvider != nil {
+		errs = append(errs, p.reg.meterProvider.ForceFlush(ctx))
+	}
+
+	if p.reg.loggerProvider != nil {
+		errs = append(errs, p.reg.loggerProvider.ForceFlush(ctx))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Shutdown flushes and shuts down all providers, each within the shutdown timeout of its
+// signal. It is called when the context of Init is done, only the first call has an effect.
+func (p *Providers) Shutdown(ctx context.Context) error {
+	p.shutdownOnce.Do(func() {
+		defer close(p.stopped)
+
+		// revert the default logger from the fan out multi logger to a standard logger
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+
+		p.reg.closeNotifiers()
+		p.shutdownErr = p.reg.forceFlush(ctx)
+
+		slogctx.Info(ctx, "Completed graceful shutdown of telemetries")
+
+		// signal that the shutdown is complete
+		if p.reg.shutdownComplete != nil {
+			close(p.reg.shutdownComplete)
+		}
+	})
+
+	return p.shutdownErr
+}
+
 // Init creates a registry, applies all options and startss the initialization.
+// The telemetry is shut down when the context is done, or by the returned Providers.
+//
+// If appCfg.Instance is not set, the instance identity is loaded with commoncfg.LoadInstance
+// and stored into appCfg.Instance, so it can be published elsewhere, e.g. in the health info.
+// This modifies the caller's appCfg; pass a copy to keep it unchanged.
 func Init(ctx context.Context,
 	appCfg *commoncfg.Application,
 	telCfg *commoncfg.Telemetry,
 	logCfg *commoncfg.Logger,
 	options ...Option,
-) error {
+) (*Providers, error) {
 	reg := &registry{
 		logger: slog.Default(),
 		appCfg: appCfg,
@@ -111,70 +217,65 @@ func Init(ctx context.Context,
 }
 
 // init initializes loader, trace, metrics and logger based on the given configs.
-func (reg *registry) init(ctx context.Context) error {
-	err := reg.initResource(ctx)
+func (reg *registry) init(ctx context.Context) (*Providers, error) {
+	propagator, err := newPropagator(reg.telCfg.Propagators)
+	if err != nil {
+		return nil, reg.abortInit(err)
+	}
+
+	err = reg.initResource(ctx)
 	if err != nil {
-		return reg.abortInit(err)
+		return nil, reg.abortInit(err)
 	}
 
 	// Tracing configuration
 	err = reg.initTrace(ctx)
 	if err != nil {
-		return reg.abortInit(err)
+		return nil, reg.abortInit(err)
 	}
 
 	// Metrics configuration
 	err = reg.initMetric(ctx)
 	if err != nil {
-		return reg.abortInit(err)
+		return nil, reg.abortInit(err)
 	}
 
 	// Logs Configuration
 	err = reg.initLogger(ctx)
 	if err != nil {
-		return reg.abortInit(err)
+		return nil, reg.abortInit(err)
 	}
 
+	providers := &Providers{reg: reg, stopped: make(chan struct{})}
+
 	if reg.telCfg.Traces.Enabled || reg.telCfg.Logs.Enabled ||
-		(reg.telCfg.Metrics.Enabled && !reg.telCfg.Metrics.Prometheus.Enabled) {
-		otel.SetTextMapPropagator(
-			propagation.NewCompositeTextMapPropagator(
-				propagation.TraceContext{},
-				propagation.Baggage{},
-			),
-		)
+		(reg.telCfg.Metrics.Enabled && (!reg.telCfg.Metrics.Prometheus.Enabled || len(reg.telCfg.Metrics.Exporters) > 0)) {
+		otel.SetTextMapPropagator(propagator)
 
 		go func() {
-			<-ctx.Done()
-
-			// revert the default logger from the fan out multi logger to a standard logger
-			slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, nil)))
-
-			// flush and shutdown all telemetry providers within a timeout
-			shutdownCtx, shutdownRelease := context.WithTimeout(ctx, DefShutdownTimeout)
-			defer shutdownRelease()
-
-			reg.forceFlush(shutdownCtx)
-
-			slogctx.Info(ctx, "Completed graceful shutdown of telemetries")
-
-			// signal that the shutdown is complete
-			if reg.shutdownComplete != nil {
-				close(reg.shutdownComplete)
+			select {
+			case <-ctx.Done():
+				_ = providers.Shutdown(context.WithoutCancel(ctx))
+			case <-providers.stopped:
 			}
 		}()
 	} else {
+		// there is nothing to shut down
+		providers.shutdownOnce.Do(func() { close(providers.stopped) })
+
 		if reg.shutdownComplete != nil {
 			close(reg.shutdownComplete)
 		}
 	}
 
-	return nil
+	return providers, nil
 }
 
 // abortInit is called when an error occurs during initialization.
 // It closes the shutdownComplete channel if it was set, and returns the error.
 func (reg *registry) abortInit(err error) error {
+	reg.closeNotifiers()
+
 	if reg.shutdownComplete != nil {
 		close(reg.shutdownComplete)
 	}
@@ -183,13 +284,14 @@ func (reg *registry) abortInit(err error) error {
 }
 
 // forceFlush forces to immediately export all OpenTelemetry pending data and shutdowns.
-func (reg *registry) forceFlush(ctx context.Context) {
+func (reg *registry) forceFlush(ctx context.Context) error {
+	var traceErr, meterErr, loggerErr error
+
 	wg := sync.WaitGroup{}
 	if reg.traceProvider != nil {
 		wg.Go(func() {
 			slogctx.Info(ctx, "Stopping trace telemetry ...")
-			_ = reg.traceProvider.ForceFlush(ctx)
-			_ = reg.traceProvider.Shutdown(ctx)
+			traceErr = shutdownProvider(ctx, reg.telCfg.Traces.ShutdownTimeout, reg.traceProvider)
 			slogctx.Info(ctx, "Stopped and flushed the trace telemetry")
 		})
 	}
@@ -197,28 +299,58 @@ func (reg *registry) forceFlush(ctx context.Context) {
 	if reg.meterProvider != nil {
 		wg.Go(func() {
 			slogctx.Info(ctx, "Stopping meter telemetry ...")
-			_ = reg.meterProvider.ForceFlush(ctx)
-			_ = reg.meterProvider.Shutdown(ctx)
+			meterErr = shutdownProvider(ctx, reg.telCfg.Metrics.ShutdownTimeout, reg.meterProvider)
 			slogctx.Info(ctx, "Stopped and flushed the meter telemetry")
 		})
 	}
 
 	if reg.loggerProvider != nil {
 		slogctx.Info(ctx, "Stopping logs telemetry ...")
-		_ = reg.loggerProvider.ForceFlush(ctx)
-		_ = reg.loggerProvider.Shutdown(ctx)
+		loggerErr = shutdownProvider(ctx, reg.telCfg.Logs.ShutdownTimeout, reg.loggerProvider)
 		slogctx.Info(ctx, "Stopped and flushed the logs telemetry")
 	}
 
 	wg.Wait()
+
+	return errors.Join(traceErr, meterErr, loggerErr)
+}
+
+type provider interface {
+	ForceFlush(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// shutdownProvider flushes and shuts down the provider within the timeout, which defaults to
+// DefShutdownTimeout. It returns when the timeout expires, as the exporters do not abort
+// an export in progress.
+func shutdownProvider(ctx context.Context, timeout time.Duration, p provider) error {
+	ctx, cancel := context.WithTimeout(ctx, cmp.Or(timeout, DefShutdownTimeout))
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- errors.Join(p.ForceFlush(ctx), p.Shutdown(ctx))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // initResource creates and sets a merged OpenTelemetry loader.
 func (reg *registry) initResource(ctx context.Context) error {
-	attrs := make([]attribute.KeyValue, 0, 2+len(CreateAttributesFrom(*reg.appCfg)))
+	reg.initInstance(ctx)
+
+	attrs := make([]attribute.KeyValue, 0, 4+len(CreateAttributesFrom(*reg.appCfg)))
 	attrs = append(attrs,
 		semconv.ServiceVersion(reg.appCfg.BuildInfo.Version),
 		semconv.ServiceName(reg.appCfg.Name),
+		semconv.ServiceInstanceID(reg.appCfg.Instance.ID),
+		attribute.Int64(AttrServiceInstanceRestartCount, reg.appCfg.Instance.RestartCount),
 	)
 	attrs = append(attrs, CreateAttributesFrom(*reg.appCfg)...)
 
@@ -253,11 +385,80 @@ func (reg *registry) initResource(ctx context.Context) error {
 		}
 	}
 
+	res, err = mergeResourceAttributes(res, reg.telCfg.ResourceAttributes)
+	if err != nil {
+		return err
+	}
+
 	reg.res = res
 
 	return nil
 }
 
+// mergeResourceAttributes resolves the configured attributes and merges them into the
+// resource, the configured attributes take precedence.
+func mergeResourceAttributes(res *resource.Resource, attrs map[string]commoncfg.SourceRef) (*resource.Resource, error) {
+	if len(attrs) == 0 {
+		return res, nil
+	}
+
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+
+	for key, ref := range attrs {
+		value, err := commoncfg.ExtractValueFromSourceRef(&ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract value of resource attribute %s: %w", key, err)
+		}
+
+		kvs = append(kvs, attribute.String(key, string(value)))
+	}
+
+	return resource.Merge(res, resource.NewSchemaless(kvs...))
+}
+
+// initInstance loads the instance identity unless it was provided by the application, and
+// stores it into the appCfg of the caller of Init.
+func (reg *registry) initInstance(ctx context.Context) {
+	if reg.appCfg.Instance.ID != "" {
+		return
+	}
+
+	instance, err := commoncfg.LoadInstance(commoncfg.WithInstanceName(reg.appCfg.Name))
+	if err != nil {
+		slogctx.Warn(ctx, "Failed to persist the instance identity, restarts are not counted", "error", err)
+	}
+
+	reg.appCfg.Instance = instance
+}
+
+// newPropagator composes the propagators, which default to the W3C Trace Context and Baggage.
+func newPropagator(names []commoncfg.Propagator) (propagation.TextMapPropagator, error) {
+	if len(names) == 0 {
+		names = []commoncfg.Propagator{commoncfg.TraceContextPropagator, commoncfg.BaggagePropagator}
+	}
+
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+
+	for _, name := range names {
+		switch name {
+		case commoncfg.TraceContextPropagator:
+			propagators = append(propagators, propagation.TraceContext{})
+		case commoncfg.BaggagePropagator:
+			propagators = append(propagators, propagation.Baggage{})
+		case commoncfg.B3Propagator:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case commoncfg.B3MultiPropagator:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case commoncfg.JaegerPropagator:
+			propagators = append(propagators, jaeger.Jaeger{})
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedPropagator, name)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
 // initTrace initializes the OpenTelemetry trace provider for the application.
 func (reg *registry) initTrace(ctx context.Context) error {
 	if !reg.telCfg.Traces.Enabled {
@@ -266,30 +467,36 @@ func (reg *registry) initTrace(ctx context.Context) error {
 
 	slogctx.Info(ctx, "Starting traces telemetry ...")
 
-	var option trace.TracerProviderOption
+	sampler, err := newSampler(reg.telCfg.Traces.Sampler)
+	if err != nil {
+		return err
+	}
+
+	cfg := &reg.telCfg.Traces
+	exporters := traceExporters(cfg)
 
-	switch reg.telCfg.Traces.Protocol {
-	case commoncfg.GRPCProtocol:
-		exporter, err := initTraceGrpcExporter(ctx, reg.telCfg)
-		if err != nil {
-			return err
-		}
+	res, err := mergeResourceAttributes(reg.res, cfg.ResourceAttributes)
+	if err != nil {
+		return err
+	}
 
-		option = trace.WithBatcher(exporter, trace.WithBatchTimeout(DefBatchTimeout))
-	case commoncfg.HTTPProtocol:
-		exporter, err := initTraceHTTPExporter(ctx, reg.telCfg)
+	options := make([]trace.TracerProviderOption, 0, 2+len(exporters))
+	options = append(options,
+		trace.WithResource(res),
+		trace.WithSampler(sampler),
+	)
+
+	// the spans are exported to all exporters by a batcher each
+	for _, exp := range exporters {
+		exporter, err := reg.traceExporter(ctx, &exp)
 		if err != nil {
 			return err
 		}
 
-		option = trace.WithBatcher(exporter, trace.WithBatchTimeout(DefBatchTimeout))
+		options = append(options, trace.WithBatcher(exporter, traceBatchOptions(cfg.Batch)...))
 	}
 
-	reg.traceProvider = trace.NewTracerProvider(
-		option,
-		trace.WithResource(reg.res),
-		trace.WithSampler(trace.AlwaysSample()),
-	)
+	reg.traceProvider = trace.NewTracerProvider(options...)
 	otel.SetTracerProvider(reg.traceProvider)
 
 	slogctx.Info(ctx, "Started successfully traces telemetry")
@@ -297,248 +504,471 @@ func (reg *registry) initTrace(ctx context.Context) error {
 	return nil
 }
 
-// initTraceHttpExporter initializes an OTLP trace exporter over HTTP based on the provided telemetry configuration.
+// newSampler creates the sampler of the configuration; without a sampler type all traces are recorded.
+func newSampler(cfg commoncfg.TraceSampler) (trace.Sampler, error) {
+	switch cfg.Type {
+	case "", commoncfg.AlwaysOnSampler:
+		return trace.AlwaysSample(), nil
+	case commoncfg.AlwaysOffSampler:
+		return trace.NeverSample(), nil
+	case commoncfg.TraceIDRatioSampler:
+		return trace.TraceIDRatioBased(cfg.Ratio), nil
+	case commoncfg.ParentBasedAlwaysOnSampler:
+		return trace.ParentBased(trace.AlwaysSample()), nil
+	case commoncfg.ParentBasedAlwaysOffSampler:
+		return trace.ParentBased(trace.NeverSample()), nil
+	case commoncfg.ParentBasedTraceIDRatioSampler:
+		return trace.ParentBased(trace.TraceIDRatioBased(cfg.Ratio)), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSampler, cfg.Type)
+	}
+}
+
+// traceBatchOptions returns the options of the span batcher, the batch timeout defaults to DefBatchTimeout.
+func traceBatchOptions(cfg commoncfg.TelemetryBatch) []trace.BatchSpanProcessorOption {
+	opts := make([]trace.BatchSpanProcessorOption, 0, 3)
+	opts = append(opts, trace.WithBatchTimeout(cmp.Or(cfg.Timeout, DefBatchTimeout)))
+
+	if cfg.MaxQueueSize > 0 {
+		opts = append(opts, trace.WithMaxQueueSize(cfg.MaxQueueSize))
+	}
+
+	if cfg.MaxExportBatchSize > 0 {
+		opts = append(opts, trace.WithMaxExportBatchSize(cfg.MaxExportBatchSize))
+	}
+
+	return opts
+}
+
+// initTraceExporter initializes a trace exporter with the protocol of the exporter configuration.
+func initTraceExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (trace.SpanExporter, error) {
+	switch cfg.Protocol {
+	case commoncfg.GRPCProtocol:
+		return initTraceGrpcExporter(ctx, cfg)
+	case commoncfg.HTTPProtocol:
+		return initTraceHTTPExporter(ctx, cfg)
+	case commoncfg.StdoutProtocol, commoncfg.FileProtocol:
+		return initTraceWriterExporter(cfg)
+	default:
+		return nil, fmt.Errorf("%w: trace exporter %s: %s", ErrUnsupportedProtocol, cfg.Name, cfg.Protocol)
+	}
+}
+
+// initTraceGrpcExporter initializes an OTLP trace exporter over gRPC based on the provided telemetry configuration.
 // It supports different authentication methods depending on the secret type.
-func initTraceGrpcExporter(ctx context.Context, cfg *commoncfg.Telemetry) (*otlptrace.Exporter, error) {
-	var sec otlptracegrpc.Option
+func initTraceGrpcExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (*otlptrace.Exporter, error) {
+	options := make([]otlptracegrpc.Option, 0, 3)
+
+	var authorization string
 
-	switch cfg.Traces.SecretRef.Type {
+	switch cfg.SecretRef.Type {
 	case commoncfg.ApiTokenSecretType:
-		token, err := computeAPITokenAuthorizationHeader(&cfg.Traces.SecretRef.APIToken)
+		token, err := computeAPITokenAuthorizationHeader(&cfg.SecretRef.APIToken)
 		if err != nil {
 			return nil, err
 		}
 
-		sec = otlptracegrpc.WithHeaders(map[string]string{AuthorizationHeader: token})
+		authorization = token
 	case commoncfg.BasicSecretType:
-		value, err := computeBasicAuthorizationHeader(&cfg.Traces.SecretRef.Basic)
+		value, err := computeBasicAuthorizationHeader(&cfg.SecretRef.Basic)
 		if err != nil {
 			return nil, err
 		}
 
-		sec = otlptracegrpc.WithHeaders(map[string]string{AuthorizationHeader: value})
+		authorization = value
 	case commoncfg.MTLSSecretType:
-		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.Traces.SecretRef.MTLS)
+		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.SecretRef.MTLS)
 		if err != nil {
 			return nil, err
 		}
 
-		sec = otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig))
+		options = append(options, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	case commoncfg.OAuth2SecretType:
+		source, err := newOAuth2TokenSource(&cfg.SecretRef.OAuth2)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, otlptracegrpc.WithDialOption(grpc.WithPerRPCCredentials(source)))
 	case commoncfg.InsecureSecretType:
-		sec = otlptracegrpc.WithInsecure()
+		options = append(options, otlptracegrpc.WithInsecure())
 	default:
-		return nil, fmt.Errorf("trace grpc doesn't unsupport secret type: %s", cfg.Traces.SecretRef.Type)
+		return nil, fmt.Errorf("trace grpc doesn't unsupport secret type: %s", cfg.SecretRef.Type)
 	}
 
-	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Traces.Host)
+	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Host)
 	if err != nil {
 		return nil, err
 	}
 
-	options := make([]otlptracegrpc.Option, 0, 2)
-	options = append(options, sec)
-	options = append(options, otlptracegrpc.WithEndpoint(string(host)))
+	if isUnixEndpoint(string(host)) && cfg.SecretRef.Type != commoncfg.MTLSSecretType {
+		options = append(options, otlptracegrpc.WithTLSCredentials(local.NewCredentials()))
+	}
+
+	headers, err := exporterHeaders(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if authorization != "" {
+		headers[AuthorizationHeader] = authorization
+	}
+
+	gzipCompressed, err := isGzipCompressed(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	options = append(options,
+		otlptracegrpc.WithEndpoint(string(host)),
+		otlptracegrpc.WithHeaders(headers),
+	)
+
+	if gzipCompressed {
+		options = append(options, otlptracegrpc.WithCompressor(gzip.Name))
+	}
 
 	return otlptracegrpc.New(ctx, options...)
 }
 
 // initTraceHttpExporter initializes an OTLP trace exporter over HTTP based on the provided telemetry configuration.
 // It supports different authentication methods depending on the secret type.
-func initTraceHTTPExporter(ctx context.Context, cfg *commoncfg.Telemetry) (*otlptrace.Exporter, error) {
+func initTraceHTTPExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (*otlptrace.Exporter, error) {
 	var sec otlptracehttp.Option
 
-	switch cfg.Traces.SecretRef.Type {
+	switch cfg.SecretRef.Type {
 	case commoncfg.ApiTokenSecretType:
-		client, err := commonhttp.NewClientFromAPIToken(&cfg.Traces.SecretRef.APIToken)
+		client, err := commonhttp.NewClientFromAPIToken(&cfg.SecretRef.APIToken)
 		if err != nil {
 			return nil, err
 		}
 
 		sec = otlptracehttp.WithHTTPClient(client)
 	case commoncfg.MTLSSecretType:
-		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.Traces.SecretRef.MTLS)
+		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.SecretRef.MTLS)
 		if err != nil {
 			return nil, err
 		}
 
 		sec = otlptracehttp.WithTLSClientConfig(tlsConfig)
 	case commoncfg.BasicSecretType:
-		httpClient, err := commonhttp.NewClientFromBasic(&cfg.Traces.SecretRef.Basic)
+		httpClient, err := commonhttp.NewClientFromBasic(&cfg.SecretRef.Basic)
 		if err != nil {
 			return nil, err
 		}
 
-		otlptracehttp.WithHTTPClient(httpClient)
+		sec = otlptracehttp.WithHTTPClient(httpClient)
 	case commoncfg.OAuth2SecretType:
-		httpClient, err := commonhttp.NewClientFromOAuth2(&cfg.Traces.SecretRef.OAuth2)
+		source, err := newOAuth2TokenSource(&cfg.SecretRef.OAuth2)
 		if err != nil {
 			return nil, err
 		}
 
-		otlptracehttp.WithHTTPClient(httpClient)
+		sec = otlptracehttp.WithHTTPClient(source.HTTPClient())
 	case commoncfg.InsecureSecretType:
 		sec = otlptracehttp.WithInsecure()
+	default:
+		return nil, fmt.Errorf("trace http doesn't unsupport secret type: %s", cfg.SecretRef.Type)
 	}
 
-	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Traces.Host)
+	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Host)
 	if err != nil {
 		return nil, err
 	}
 
+	headers, err := exporterHeaders(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	gzipCompressed, err := isGzipCompressed(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	compression := otlptracehttp.NoCompression
+	if gzipCompressed {
+		compression = otlptracehttp.GzipCompression
+	}
+
 	return otlptracehttp.New(ctx,
 		otlptracehttp.WithEndpoint(string(host)),
-		otlptracehttp.WithURLPath(cfg.Traces.URL),
+		otlptracehttp.WithURLPath(cfg.URL),
+		otlptracehttp.WithHeaders(headers),
+		otlptracehttp.WithCompression(compression),
 		sec,
 	)
 }
 
-// initMetric initializes Prometheus metrics using chosen protocol.
+// initMetric initializes the metrics, exported to Prometheus or using chosen protocol.
 func (reg *registry) initMetric(ctx context.Context) error {
 	if !reg.telCfg.Metrics.Enabled {
 		return nil
 	}
 
-	if reg.telCfg.Metrics.Prometheus.Enabled {
-		prometheusExporter, err := prometheus.New()
+	slogctx.Info(ctx, "Starting meters telemetry ...")
+
+	cfg := &reg.telCfg.Metrics
+	exporters := metricExporters(cfg)
+
+	res, err := mergeResourceAttributes(reg.res, cfg.ResourceAttributes)
+	if err != nil {
+		return err
+	}
+
+	opts := make([]metric.Option, 0, 3+len(exporters))
+	opts = append(opts,
+		metric.WithResource(res),
+		metric.WithExemplarFilter(exemplar.AlwaysOnFilter),
+		metric.WithView(metricViews(cfg.Views)...),
+	)
+
+	// the metrics are collected for all exporters by a reader each
+	for _, exp := range exporters {
+		reader, err := reg.initMetricReader(ctx, &exp, cmp.Or(cfg.Interval, DefPeriodicReaderInterval))
 		if err != nil {
 			return err
 		}
 
-		reg.meterProvider = metric.NewMeterProvider(
-			metric.WithResource(reg.res),
-			metric.WithReader(prometheusExporter),
-		)
-		otel.SetMeterProvider(reg.meterProvider)
-
-		return nil
+		opts = append(opts, metric.WithReader(reader))
 	}
 
-	slogctx.Info(ctx, "Starting meters telemetry ...")
+	reg.meterProvider = metric.NewMeterProvider(opts...)
+	otel.SetMeterProvider(reg.meterProvider)
 
-	var periodicReader *metric.PeriodicReader
+	// Start collecting Go runtime metrics (GC, heap, CPU)
+	err = runtime.Start(runtime.WithMeterProvider(reg.meterProvider))
+	if err != nil {
+		return err
+	}
 
-	switch reg.telCfg.Metrics.Protocol {
-	case commoncfg.GRPCProtocol:
-		exporter, err := initMetricGrpcExporter(ctx, reg.telCfg)
+	if cfg.HostMetrics {
+		err = startHostMetrics(reg.meterProvider)
 		if err != nil {
 			return err
 		}
+	}
 
-		periodicReader = metric.NewPeriodicReader(exporter, metric.WithInterval(DefPeriodicReaderInterval))
-	case commoncfg.HTTPProtocol:
-		exporter, err := initMetricHTTPExporter(ctx, reg.telCfg)
+	if cfg.ProcessMetrics {
+		err = startProcessMetrics(reg.meterProvider)
 		if err != nil {
 			return err
 		}
+	}
+
+	slogctx.Info(ctx, "Started successfully meters telemetry")
+
+	return nil
+}
+
+// metricViews creates the views of the configured metric views.
+func metricViews(cfgs []commoncfg.MetricView) []metric.View {
+	views := make([]metric.View, 0, len(cfgs))
+
+	for _, cfg := range cfgs {
+		mask := metric.Stream{
+			Name:        cfg.Name,
+			Description: cfg.Description,
+		}
+
+		switch {
+		case cfg.Drop:
+			mask.Aggregation = metric.AggregationDrop{}
+		case len(cfg.Buckets) > 0:
+			mask.Aggregation = metric.AggregationExplicitBucketHistogram{Boundaries: cfg.Buckets}
+		}
 
-		periodicReader = metric.NewPeriodicReader(exporter, metric.WithInterval(DefPeriodicReaderInterval))
+		if len(cfg.Attributes) > 0 {
+			keys := make([]attribute.Key, 0, len(cfg.Attributes))
+			for _, key := range cfg.Attributes {
+				keys = append(keys, attribute.Key(key))
+			}
+
+			mask.AttributeFilter = attribute.NewAllowKeysFilter(keys...)
+		}
+
+		views = append(views, metric.NewView(metric.Instrument{
+			Name:  cfg.Instrument,
+			Scope: instrumentation.Scope{Name: cfg.Meter},
+		}, mask))
 	}
 
-	opts := make([]metric.Option, 0, 3)
-	opts = append(opts,
-		metric.WithResource(reg.res),
-		metric.WithReader(periodicReader),
-		metric.WithExemplarFilter(exemplar.AlwaysOnFilter),
-	)
+	return views
+}
 
-	reg.meterProvider = metric.NewMeterProvider(opts...)
-	otel.SetMeterProvider(reg.meterProvider)
+// initMetricReader initializes the reader of a metric exporter. The metrics are pulled by
+// Prometheus, and pushed to the other exporters periodically at the interval.
+func (reg *registry) initMetricReader(ctx context.Context, cfg *commoncfg.TelemetryExporter, interval time.Duration) (metric.Reader, error) {
+	if cfg.Protocol == commoncfg.PrometheusProtocol {
+		return prometheus.New()
+	}
 
-	// Start collecting Go runtime metrics (GC, heap, CPU)
-	err := runtime.Start(runtime.WithMeterProvider(reg.meterProvider))
+	exporter, err := reg.metricExporter(ctx, cfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	slogctx.Info(ctx, "Started successfully meters telemetry")
+	return metric.NewPeriodicReader(exporter, metric.WithInterval(interval)), nil
+}
 
-	return nil
+// initMetricExporter initializes a metric exporter with the protocol of the exporter configuration.
+func initMetricExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (metric.Exporter, error) {
+	switch cfg.Protocol {
+	case commoncfg.GRPCProtocol:
+		return initMetricGrpcExporter(ctx, cfg)
+	case commoncfg.HTTPProtocol:
+		return initMetricHTTPExporter(ctx, cfg)
+	case commoncfg.StdoutProtocol, commoncfg.FileProtocol:
+		return initMetricWriterExporter(cfg)
+	default:
+		return nil, fmt.Errorf("%w: metric exporter %s: %s", ErrUnsupportedProtocol, cfg.Name, cfg.Protocol)
+	}
 }
 
 // initMetricGrpcExporter initializes metrics gRPC exporter.
 // It supports different authentication methods depending on the secret type.
-func initMetricGrpcExporter(ctx context.Context, cfg *commoncfg.Telemetry) (*otlpmetricgrpc.Exporter, error) {
-	var sec otlpmetricgrpc.Option
+func initMetricGrpcExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (*otlpmetricgrpc.Exporter, error) {
+	options := make([]otlpmetricgrpc.Option, 0, 3)
+
+	var authorization string
 
-	switch cfg.Metrics.SecretRef.Type {
+	switch cfg.SecretRef.Type {
 	case commoncfg.ApiTokenSecretType:
-		token, err := computeAPITokenAuthorizationHeader(&cfg.Metrics.SecretRef.APIToken)
+		token, err := computeAPITokenAuthorizationHeader(&cfg.SecretRef.APIToken)
 		if err != nil {
 			return nil, err
 		}
 
-		sec = otlpmetricgrpc.WithHeaders(map[string]string{AuthorizationHeader: token})
+		authorization = token
 	case commoncfg.BasicSecretType:
-		value, err := computeBasicAuthorizationHeader(&cfg.Metrics.SecretRef.Basic)
+		value, err := computeBasicAuthorizationHeader(&cfg.SecretRef.Basic)
 		if err != nil {
 			return nil, err
 		}
 
-		sec = otlpmetricgrpc.WithHeaders(map[string]string{AuthorizationHeader: value})
+		authorization = value
 	case commoncfg.MTLSSecretType:
-		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.Metrics.SecretRef.MTLS)
+		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.SecretRef.MTLS)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	case commoncfg.OAuth2SecretType:
+		source, err := newOAuth2TokenSource(&cfg.SecretRef.OAuth2)
 		if err != nil {
 			return nil, err
 		}
 
-		sec = otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig))
+		options = append(options, otlpmetricgrpc.WithDialOption(grpc.WithPerRPCCredentials(source)))
 	case commoncfg.InsecureSecretType:
-		sec = otlpmetricgrpc.WithInsecure()
+		options = append(options, otlpmetricgrpc.WithInsecure())
 	default:
-		return nil, fmt.Errorf("metric grpc doesn't unsupport secret type: %s", cfg.Metrics.SecretRef.Type)
+		return nil, fmt.Errorf("metric grpc doesn't unsupport secret type: %s", cfg.SecretRef.Type)
 	}
 
-	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Metrics.Host)
+	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Host)
 	if err != nil {
 		return nil, err
 	}
 
-	options := make([]otlpmetricgrpc.Option, 0, 2)
-	options = append(options, sec)
-	options = append(options, otlpmetricgrpc.WithEndpoint(string(host)))
+	if isUnixEndpoint(string(host)) && cfg.SecretRef.Type != commoncfg.MTLSSecretType {
+		options = append(options, otlpmetricgrpc.WithTLSCredentials(local.NewCredentials()))
+	}
+
+	headers, err := exporterHeaders(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if authorization != "" {
+		headers[AuthorizationHeader] = authorization
+	}
+
+	gzipCompressed, err := isGzipCompressed(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	temporality, err := temporalitySelector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	options = append(options,
+		otlpmetricgrpc.WithEndpoint(string(host)),
+		otlpmetricgrpc.WithHeaders(headers),
+		otlpmetricgrpc.WithTemporalitySelector(temporality),
+	)
+
+	if gzipCompressed {
+		options = append(options, otlpmetricgrpc.WithCompressor(gzip.Name))
+	}
 
 	return otlpmetricgrpc.New(ctx, options...)
 }
 
 // initMetricHttpExporter initializes metrics http exporter.
 // It supports different authentication methods depending on the secret type.
-func initMetricHTTPExporter(ctx context.Context, cfg *commoncfg.Telemetry) (*otlpmetrichttp.Exporter, error) {
+func initMetricHTTPExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (*otlpmetrichttp.Exporter, error) {
 	var sec otlpmetrichttp.Option
 
-	switch cfg.Metrics.SecretRef.Type {
+	switch cfg.SecretRef.Type {
 	case commoncfg.ApiTokenSecretType:
-		client, err := commonhttp.NewClientFromAPIToken(&cfg.Metrics.SecretRef.APIToken)
+		client, err := commonhttp.NewClientFromAPIToken(&cfg.SecretRef.APIToken)
 		if err != nil {
 			return nil, err
 		}
 
 		sec = otlpmetrichttp.WithHTTPClient(client)
 	case commoncfg.MTLSSecretType:
-		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.Metrics.SecretRef.MTLS)
+		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.SecretRef.MTLS)
 		if err != nil {
 			return nil, err
 		}
 
 		sec = otlpmetrichttp.WithTLSClientConfig(tlsConfig)
 	case commoncfg.BasicSecretType:
-		httpClient, err := commonhttp.NewClientFromBasic(&cfg.Metrics.SecretRef.Basic)
+		httpClient, err := commonhttp.NewClientFromBasic(&cfg.SecretRef.Basic)
 		if err != nil {
 			return nil, err
 		}
 
-		otlpmetrichttp.WithHTTPClient(httpClient)
+		sec = otlpmetrichttp.WithHTTPClient(httpClient)
 	case commoncfg.OAuth2SecretType:
-		httpClient, err := commonhttp.NewClientFromOAuth2(&cfg.Metrics.SecretRef.OAuth2)
+		source, err := newOAuth2TokenSource(&cfg.SecretRef.OAuth2)
 		if err != nil {
 			return nil, err
 		}
 
-		otlpmetrichttp.WithHTTPClient(httpClient)
+		sec = otlpmetrichttp.WithHTTPClient(source.HTTPClient())
 	case commoncfg.InsecureSecretType:
 		sec = otlpmetrichttp.WithInsecure()
+	default:
+		return nil, fmt.Errorf("metric http doesn't unsupport secret type: %s", cfg.SecretRef.Type)
+	}
+
+	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := exporterHeaders(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	gzipCompressed, err := isGzipCompressed(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	compression := otlpmetrichttp.NoCompression
+	if gzipCompressed {
+		compression = otlpmetrichttp.GzipCompression
 	}
 
-	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Metrics.Host)
+	temporality, err := temporalitySelector(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -546,10 +976,10 @@ func initMetricHTTPExporter(ctx context.Context, cfg *commoncfg.Telemetry) (*otl
 	return otlpmetrichttp.New(
 		ctx,
 		otlpmetrichttp.WithEndpoint(string(host)),
-		otlpmetrichttp.WithURLPath(cfg.Metrics.URL),
-		otlpmetrichttp.WithTemporalitySelector(
-			func(metric.InstrumentKind) metricdata.Temporality { return metricdata.DeltaTemporality },
-		),
+		otlpmetrichttp.WithURLPath(cfg.URL),
+		otlpmetrichttp.WithHeaders(headers),
+		otlpmetrichttp.WithCompression(compression),
+		otlpmetrichttp.WithTemporalitySelector(temporality),
 		sec,
 	)
 }
@@ -562,29 +992,28 @@ func (reg *registry) initLogger(ctx context.Context) error {
 
 	slogctx.Info(ctx, "Starting logs telemetry ...")
 
-	var processor *log.BatchProcessor
+	cfg := &reg.telCfg.Logs
+	exporters := loggerExporters(cfg)
 
-	switch reg.telCfg.Logs.Protocol {
-	case commoncfg.GRPCProtocol:
-		exporter, err := initLoggerGrpcExporter(ctx, reg.telCfg)
-		if err != nil {
-			return err
-		}
+	opts := make([]log.LoggerProviderOption, 0, 1+len(exporters))
+	res, err := mergeResourceAttributes(reg.res, cfg.ResourceAttributes)
+	if err != nil {
+		return err
+	}
 
-		processor = log.NewBatchProcessor(exporter)
-	case commoncfg.HTTPProtocol:
-		exporter, err := initLoggerHTTPExporter(ctx, reg.telCfg)
+	opts = append(opts, log.WithResource(res))
+
+	// the records are exported to all exporters by a batch processor each
+	for _, exp := range exporters {
+		exporter, err := reg.loggerExporter(ctx, &exp)
 		if err != nil {
 			return err
 		}
 
-		processor = log.NewBatchProcessor(exporter)
+		opts = append(opts, log.WithProcessor(log.NewBatchProcessor(exporter, logBatchOptions(cfg.Batch)...)))
 	}
 
-	reg.loggerProvider = log.NewLoggerProvider(
-		log.WithProcessor(processor),
-		log.WithResource(reg.res),
-	)
+	reg.loggerProvider = log.NewLoggerProvider(opts...)
 	global.SetLoggerProvider(reg.loggerProvider)
 
 	otelLogger := otelslog.NewLogger(reg.appCfg.Name, otelslog.WithLoggerProvider(reg.loggerProvider)).
@@ -612,100 +1041,304 @@ func (reg *registry) initLogger(ctx context.Context) error {
 	return nil
 }
 
+// logBatchOptions returns the options of the log batch processor.
+func logBatchOptions(cfg commoncfg.TelemetryBatch) []log.BatchProcessorOption {
+	opts := make([]log.BatchProcessorOption, 0, 3)
+
+	if cfg.Timeout > 0 {
+		opts = append(opts, log.WithExportInterval(cfg.Timeout))
+	}
+
+	if cfg.MaxQueueSize > 0 {
+		opts = append(opts, log.WithMaxQueueSize(cfg.MaxQueueSize))
+	}
+
+	if cfg.MaxExportBatchSize > 0 {
+		opts = append(opts, log.WithExportMaxBatchSize(cfg.MaxExportBatchSize))
+	}
+
+	return opts
+}
+
+// initLoggerExporter initializes a log exporter with the protocol of the exporter configuration.
+func initLoggerExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (log.Exporter, error) {
+	switch cfg.Protocol {
+	case commoncfg.GRPCProtocol:
+		return initLoggerGrpcExporter(ctx, cfg)
+	case commoncfg.HTTPProtocol:
+		return initLoggerHTTPExporter(ctx, cfg)
+	case commoncfg.StdoutProtocol, commoncfg.FileProtocol:
+		return initLoggerWriterExporter(cfg)
+	default:
+		return nil, fmt.Errorf("%w: log exporter %s: %s", ErrUnsupportedProtocol, cfg.Name, cfg.Protocol)
+	}
+}
+
 // initLoggerGrpcExporter initializes logger gRPC exporter.
 // It supports different authentication methods depending on the secret type.
-func initLoggerGrpcExporter(ctx context.Context, cfg *commoncfg.Telemetry) (*otlploggrpc.Exporter, error) {
-	var sec otlploggrpc.Option
+func initLoggerGrpcExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (*otlploggrpc.Exporter, error) {
+	options := make([]otlploggrpc.Option, 0, 3)
 
-	switch cfg.Logs.SecretRef.Type {
+	var authorization string
+
+	switch cfg.SecretRef.Type {
 	case commoncfg.ApiTokenSecretType:
-		token, err := computeAPITokenAuthorizationHeader(&cfg.Logs.SecretRef.APIToken)
+		token, err := computeAPITokenAuthorizationHeader(&cfg.SecretRef.APIToken)
 		if err != nil {
 			return nil, err
 		}
 
-		sec = otlploggrpc.WithHeaders(map[string]string{AuthorizationHeader: token})
+		authorization = token
 	case commoncfg.BasicSecretType:
-		value, err := computeBasicAuthorizationHeader(&cfg.Logs.SecretRef.Basic)
+		value, err := computeBasicAuthorizationHeader(&cfg.SecretRef.Basic)
 		if err != nil {
 			return nil, err
 		}
 
-		sec = otlploggrpc.WithHeaders(map[string]string{AuthorizationHeader: value})
+		authorization = value
 	case commoncfg.MTLSSecretType:
-		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.Logs.SecretRef.MTLS)
+		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.SecretRef.MTLS)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, otlploggrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	case commoncfg.OAuth2SecretType:
+		source, err := newOAuth2TokenSource(&cfg.SecretRef.OAuth2)
 		if err != nil {
 			return nil, err
 		}
 
-		sec = otlploggrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig))
+		options = append(options, otlploggrpc.WithDialOption(grpc.WithPerRPCCredentials(source)))
 	case commoncfg.InsecureSecretType:
-		sec = otlploggrpc.WithInsecure()
+		options = append(options, otlploggrpc.WithInsecure())
 	default:
-		return nil, fmt.Errorf("logger grpc doesn't unsupport secret type: %s", cfg.Logs.SecretRef.Type)
+		return nil, fmt.Errorf("logger grpc doesn't unsupport secret type: %s", cfg.SecretRef.Type)
 	}
 
-	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Logs.Host)
+	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Host)
 	if err != nil {
 		return nil, err
 	}
 
-	options := make([]otlploggrpc.Option, 0, 2)
-	options = append(options, sec)
-	options = append(options, otlploggrpc.WithEndpoint(string(host)))
+	if isUnixEndpoint(string(host)) && cfg.SecretRef.Type != commoncfg.MTLSSecretType {
+		options = append(options, otlploggrpc.WithTLSCredentials(local.NewCredentials()))
+	}
+
+	headers, err := exporterHeaders(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if authorization != "" {
+		headers[AuthorizationHeader] = authorization
+	}
+
+	gzipCompressed, err := isGzipCompressed(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	options = append(options,
+		otlploggrpc.WithEndpoint(string(host)),
+		otlploggrpc.WithHeaders(headers),
+	)
+
+	if gzipCompressed {
+		options = append(options, otlploggrpc.WithCompressor(gzip.Name))
+	}
 
 	return otlploggrpc.New(ctx, options...)
 }
 
-func initLoggerHTTPExporter(ctx context.Context, cfg *commoncfg.Telemetry) (*otlploghttp.Exporter, error) {
+func initLoggerHTTPExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (*otlploghttp.Exporter, error) {
 	var sec otlploghttp.Option
 
-	switch cfg.Logs.SecretRef.Type {
+	switch cfg.SecretRef.Type {
 	case commoncfg.ApiTokenSecretType:
-		client, err := commonhttp.NewClientFromAPIToken(&cfg.Logs.SecretRef.APIToken)
+		client, err := commonhttp.NewClientFromAPIToken(&cfg.SecretRef.APIToken)
 		if err != nil {
 			return nil, err
 		}
 
 		sec = otlploghttp.WithHTTPClient(client)
 	case commoncfg.MTLSSecretType:
-		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.Logs.SecretRef.MTLS)
+		tlsConfig, err := commoncfg.LoadMTLSConfig(&cfg.SecretRef.MTLS)
 		if err != nil {
 			return nil, err
 		}
 
 		sec = otlploghttp.WithTLSClientConfig(tlsConfig)
 	case commoncfg.BasicSecretType:
-		httpClient, err := commonhttp.NewClientFromBasic(&cfg.Logs.SecretRef.Basic)
+		httpClient, err := commonhttp.NewClientFromBasic(&cfg.SecretRef.Basic)
 		if err != nil {
 			return nil, err
 		}
 
-		otlploghttp.WithHTTPClient(httpClient)
+		sec = otlploghttp.WithHTTPClient(httpClient)
 	case commoncfg.OAuth2SecretType:
-		httpClient, err := commonhttp.NewClientFromOAuth2(&cfg.Logs.SecretRef.OAuth2)
+		source, err := newOAuth2TokenSource(&cfg.SecretRef.OAuth2)
 		if err != nil {
 			return nil, err
 		}
 
-		otlploghttp.WithHTTPClient(httpClient)
+		sec = otlploghttp.WithHTTPClient(source.HTTPClient())
 	case commoncfg.InsecureSecretType:
 		sec = otlploghttp.WithInsecure()
+	default:
+		return nil, fmt.Errorf("logger http doesn't unsupport secret type: %s", cfg.SecretRef.Type)
+	}
+
+	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Host)
+	if err != nil {
+		return nil, err
 	}
 
-	host, err := commoncfg.ExtractValueFromSourceRef(&cfg.Logs.Host)
+	headers, err := exporterHeaders(cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	gzipCompressed, err := isGzipCompressed(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	compression := otlploghttp.NoCompression
+	if gzipCompressed {
+		compression = otlploghttp.GzipCompression
+	}
+
 	return otlploghttp.New(
 		ctx,
 		otlploghttp.WithEndpoint(string(host)),
-		otlploghttp.WithURLPath(cfg.Logs.URL),
+		otlploghttp.WithURLPath(cfg.URL),
+		otlploghttp.WithHeaders(headers),
+		otlploghttp.WithCompression(compression),
 		sec,
 	)
 }
 
+// signalExporters returns the exporters of a signal: the endpoint configured on the signal
+// itself, named default, followed by the additional exporters.
+func signalExporters(primary commoncfg.TelemetryExporter, additional []commoncfg.TelemetryExporter) []commoncfg.TelemetryExporter {
+	primary.Name = "default"
+
+	exporters := make([]commoncfg.TelemetryExporter, 0, 1+len(additional))
+	exporters = append(exporters, primary)
+
+	return append(exporters, additional...)
+}
+
+// traceExporters returns the exporters of the traces.
+func traceExporters(cfg *commoncfg.Trace) []commoncfg.TelemetryExporter {
+	return signalExporters(commoncfg.TelemetryExporter{
+		Protocol:    cfg.Protocol,
+		Host:        cfg.Host,
+		URL:         cfg.URL,
+		Path:        cfg.Path,
+		SecretRef:   cfg.SecretRef,
+		Headers:     cfg.Headers,
+		Compression: cfg.Compression,
+	}, cfg.Exporters)
+}
+
+// metricExporters returns the exporters of the metrics.
+func metricExporters(cfg *commoncfg.Metric) []commoncfg.TelemetryExporter {
+	primary := commoncfg.TelemetryExporter{
+		Protocol:    cfg.Protocol,
+		Host:        cfg.Host,
+		URL:         cfg.URL,
+		Path:        cfg.Path,
+		SecretRef:   cfg.SecretRef,
+		Headers:     cfg.Headers,
+		Compression: cfg.Compression,
+		Temporality: cfg.Temporality,
+	}
+
+	// the enabled Prometheus integration replaces the endpoint of the signal
+	if cfg.Prometheus.Enabled {
+		primary = commoncfg.TelemetryExporter{Protocol: commoncfg.PrometheusProtocol}
+	}
+
+	return signalExporters(primary, cfg.Exporters)
+}
+
+// loggerExporters returns the exporters of the logs.
+func loggerExporters(cfg *commoncfg.Log) []commoncfg.TelemetryExporter {
+	return signalExporters(commoncfg.TelemetryExporter{
+		Protocol:    cfg.Protocol,
+		Host:        cfg.Host,
+		URL:         cfg.URL,
+		Path:        cfg.Path,
+		SecretRef:   cfg.SecretRef,
+		Headers:     cfg.Headers,
+		Compression: cfg.Compression,
+	}, cfg.Exporters)
+}
+
+// isGzipCompressed returns whether the exporter compresses the telemetry with gzip.
+func isGzipCompressed(cfg *commoncfg.TelemetryExporter) (bool, error) {
+	switch cfg.Compression {
+	case commoncfg.GzipCompression:
+		return true, nil
+	case "", commoncfg.NoCompression:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: exporter %s: %s", ErrUnsupportedCompression, cfg.Name, cfg.Compression)
+	}
+}
+
+// temporalitySelector returns the selector of the configured temporality of the metric
+// exporter. As recommended by the OTLP exporter specification, the up-down counters and
+// gauges are exported cumulative with the delta temporality, their deltas are meaningless.
+func temporalitySelector(cfg *commoncfg.TelemetryExporter) (metric.TemporalitySelector, error) {
+	switch cfg.Temporality {
+	case "", commoncfg.CumulativeTemporality:
+		return metric.DefaultTemporalitySelector, nil
+	case commoncfg.DeltaTemporality:
+		return deltaTemporality, nil
+	default:
+		return nil, fmt.Errorf("%w: exporter %s: %s", ErrUnsupportedTemporality, cfg.Name, cfg.Temporality)
+	}
+}
+
+func deltaTemporality(kind metric.InstrumentKind) metricdata.Temporality {
+	switch kind {
+	case metric.InstrumentKindUpDownCounter,
+		metric.InstrumentKindObservableUpDownCounter,
+		metric.InstrumentKindGauge,
+		metric.InstrumentKindObservableGauge:
+		return metricdata.CumulativeTemporality
+	default:
+		return metricdata.DeltaTemporality
+	}
+}
+
+// isUnixEndpoint reports whether the gRPC endpoint is a unix domain socket, e.g.
+// unix:///var/run/otel/otlp.sock of a node-local collector. The exporters reach such an
+// endpoint without TLS unless mTLS is configured, while the credentials of the other secret
+// types are still sent, as the socket is local.
+func isUnixEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "unix:") || strings.HasPrefix(endpoint, "unix-abstract:")
+}
+
+// exporterHeaders resolves the values of the headers sent by the exporter.
+func exporterHeaders(cfg *commoncfg.TelemetryExporter) (map[string]string, error) {
+	headers := make(map[string]string, len(cfg.Headers)+1)
+
+	for name, ref := range cfg.Headers {
+		value, err := commoncfg.ExtractValueFromSourceRef(&ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract value of header %s: %w", name, err)
+		}
+
+		headers[name] = string(value)
+	}
+
+	return headers, nil
+}
+
 func computeBasicAuthorizationHeader(basicAuth *commoncfg.BasicAuth) (string, error) {
 	username, err := commoncfg.ExtractValueFromSourceRef(&basicAuth.Username)
 	if err != nil {
@@ -9,11 +9,29 @@ import (
 	"encoding/pem"
 	"log/slog"
 	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	prometheusclient "github.com/prometheus/client_golang/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 
 	config "github.com/openkcm/common-sdk/pkg/commoncfg"
 	"github.com/openkcm/common-sdk/pkg/otlp"
@@ -150,6 +168,8 @@ func Test_OTLP_Init_Log(t *testing.T) {
 					SecretRef: tt.secRef,
 					Host:      tt.host,
 					Protocol:  tt.protocol,
+					// there is no collector to flush the logs to
+					ShutdownTimeout: 100 * time.Millisecond,
 				},
 			}
 
@@ -157,7 +177,7 @@ func Test_OTLP_Init_Log(t *testing.T) {
 			shutdownComplete := make(chan struct{})
 			ctx, cancel := context.WithCancel(t.Context())
 			// Act
-			err := otlp.Init(ctx, appCfg, telCfg, logCfg,
+			_, err := otlp.Init(ctx, appCfg, telCfg, logCfg,
 				otlp.WithLogger(minimalLogger),
 				otlp.WithShutdownComplete(shutdownComplete),
 			)
@@ -306,7 +326,7 @@ func Test_OTLP_Init_Trace(t *testing.T) {
 				Logs:    config.Log{Enabled: false},
 			}
 
-			err := otlp.Init(ctx, appCfg, telCfg, logCfg)
+			_, err := otlp.Init(ctx, appCfg, telCfg, logCfg)
 			if tt.err {
 				require.Error(t, err)
 			} else {
@@ -446,7 +466,7 @@ func Test_OTLP_Init_Metric(t *testing.T) {
 				Logs: config.Log{Enabled: false},
 			}
 
-			err := otlp.Init(ctx, appCfg, telCfg, logCfg)
+			_, err := otlp.Init(ctx, appCfg, telCfg, logCfg)
 			if tt.err {
 				require.Error(t, err)
 			} else {
@@ -458,6 +478,785 @@ func Test_OTLP_Init_Metric(t *testing.T) {
 	}
 }
 
+func Test_OTLP_Init_Instance(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv(config.PodUIDEnv, "")
+
+	appCfg := &config.Application{Name: "instance-service"}
+
+	_, err := otlp.Init(t.Context(), appCfg, &config.Telemetry{}, &config.Logger{})
+	require.NoError(t, err)
+	require.NotEmpty(t, appCfg.Instance.ID)
+	require.Equal(t, int64(0), appCfg.Instance.RestartCount)
+
+	restarted := &config.Application{Name: "instance-service"}
+
+	_, err = otlp.Init(t.Context(), restarted, &config.Telemetry{}, &config.Logger{})
+	require.NoError(t, err)
+	require.Equal(t, appCfg.Instance.ID, restarted.Instance.ID)
+	require.Equal(t, int64(1), restarted.Instance.RestartCount)
+
+	// an instance provided by the application is kept
+	provided := &config.Application{Name: "instance-service", Instance: config.Instance{ID: "provided"}}
+
+	_, err = otlp.Init(t.Context(), provided, &config.Telemetry{}, &config.Logger{})
+	require.NoError(t, err)
+	require.Equal(t, config.Instance{ID: "provided"}, provided.Instance)
+}
+
+func Test_OTLP_Init_TraceSampler(t *testing.T) {
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "sampler"}}
+	traces := func(sampler config.TraceSampler) *config.Telemetry {
+		return &config.Telemetry{
+			Traces: config.Trace{
+				Enabled:   true,
+				Protocol:  config.GRPCProtocol,
+				SecretRef: config.SecretRef{Type: config.InsecureSecretType},
+				Host:      config.SourceRef{Source: config.EmbeddedSourceValue, Value: "localhost:4317"},
+				Sampler:   sampler,
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		sampler config.TraceSampler
+		sampled bool
+	}{
+		{name: "default", sampler: config.TraceSampler{}, sampled: true},
+		{name: "always on", sampler: config.TraceSampler{Type: config.AlwaysOnSampler}, sampled: true},
+		{name: "always off", sampler: config.TraceSampler{Type: config.AlwaysOffSampler}, sampled: false},
+		{name: "ratio of 1", sampler: config.TraceSampler{Type: config.TraceIDRatioSampler, Ratio: 1}, sampled: true},
+		{name: "ratio of 0", sampler: config.TraceSampler{Type: config.TraceIDRatioSampler}, sampled: false},
+		{name: "parent based", sampler: config.TraceSampler{Type: config.ParentBasedAlwaysOffSampler}, sampled: false},
+		{name: "parent based ratio", sampler: config.TraceSampler{Type: config.ParentBasedTraceIDRatioSampler, Ratio: 1}, sampled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			_, err := otlp.Init(ctx, appCfg, traces(tt.sampler), &config.Logger{})
+			require.NoError(t, err)
+
+			_, span := otel.Tracer("test").Start(ctx, "operation")
+			defer span.End()
+
+			require.Equal(t, tt.sampled, span.SpanContext().IsSampled())
+		})
+	}
+
+	t.Run("unsupported sampler", func(t *testing.T) {
+		_, err := otlp.Init(t.Context(), appCfg, traces(config.TraceSampler{Type: "sometimes"}), &config.Logger{})
+		require.ErrorIs(t, err, otlp.ErrUnsupportedSampler)
+	})
+}
+
+func Test_OTLP_Init_Exporters(t *testing.T) {
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "exporters"}}
+	insecure := config.SecretRef{Type: config.InsecureSecretType}
+	host := config.SourceRef{Source: config.EmbeddedSourceValue, Value: "localhost:4317"}
+	exporters := func(protocol config.Protocol) []config.TelemetryExporter {
+		return []config.TelemetryExporter{
+			{Name: "internal", Protocol: config.HTTPProtocol, Host: host, URL: "/otlp", SecretRef: insecure},
+			{Name: "external", Protocol: protocol, Host: host, SecretRef: insecure},
+		}
+	}
+	telemetry := func(protocol config.Protocol) *config.Telemetry {
+		return &config.Telemetry{
+			Traces: config.Trace{
+				Enabled: true, Protocol: config.GRPCProtocol, Host: host, SecretRef: insecure,
+				Exporters: exporters(protocol),
+			},
+			Metrics: config.Metric{
+				Enabled: true, Protocol: config.GRPCProtocol, Host: host, SecretRef: insecure,
+				Exporters: exporters(protocol),
+			},
+			Logs: config.Log{
+				Enabled: true, Protocol: config.GRPCProtocol, Host: host, SecretRef: insecure,
+				Exporters: exporters(protocol),
+			},
+		}
+	}
+
+	t.Run("fans out to all exporters", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		_, err := otlp.Init(ctx, appCfg, telemetry(config.GRPCProtocol), &config.Logger{})
+		require.NoError(t, err)
+	})
+
+	t.Run("unsupported protocol", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		_, err := otlp.Init(ctx, appCfg, telemetry("udp"), &config.Logger{})
+		require.ErrorIs(t, err, otlp.ErrUnsupportedProtocol)
+	})
+}
+
+func Test_OTLP_Init_OAuth2(t *testing.T) {
+	var tokenRequests, exports atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("client_id") != "client" ||
+			r.FormValue("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access-token","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("/v1/traces", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(otlp.AuthorizationHeader) != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		exports.Add(1)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	// the exporters and the token requests use the default transport
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+
+	t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+	oauth2 := func(secret string) config.SecretRef {
+		return config.SecretRef{
+			Type: config.OAuth2SecretType,
+			OAuth2: config.OAuth2{
+				URL: &config.SourceRef{Source: config.EmbeddedSourceValue, Value: server.URL + "/token"},
+				Credentials: config.OAuth2Credentials{
+					ClientID:     config.SourceRef{Source: config.EmbeddedSourceValue, Value: "client"},
+					AuthMethod:   config.OAuth2ClientSecretPost,
+					ClientSecret: &config.SourceRef{Source: config.EmbeddedSourceValue, Value: secret},
+				},
+			},
+		}
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "oauth2"}}
+
+	t.Run("exports with a bearer token over HTTP", func(t *testing.T) {
+		telemetry := &config.Telemetry{
+			Traces: config.Trace{
+				Enabled:   true,
+				Protocol:  config.HTTPProtocol,
+				Host:      config.SourceRef{Source: config.EmbeddedSourceValue, Value: server.Listener.Addr().String()},
+				URL:       "/v1/traces",
+				SecretRef: oauth2("secret"),
+			},
+		}
+
+		_, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+		require.NoError(t, err)
+
+		provider, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+		require.True(t, ok)
+
+		for range 2 {
+			_, span := otel.Tracer("test").Start(t.Context(), "operation")
+			span.End()
+
+			require.NoError(t, provider.ForceFlush(t.Context()))
+		}
+
+		require.NoError(t, provider.Shutdown(t.Context()))
+		require.Equal(t, int32(2), exports.Load())
+		// the token is cached until it expires
+		require.Equal(t, int32(1), tokenRequests.Load())
+	})
+
+	t.Run("initializes over GRPC", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		telemetry := &config.Telemetry{
+			Traces: config.Trace{
+				Enabled:   true,
+				Protocol:  config.GRPCProtocol,
+				Host:      config.SourceRef{Source: config.EmbeddedSourceValue, Value: "localhost:4317"},
+				SecretRef: oauth2("secret"),
+			},
+		}
+
+		_, err := otlp.Init(ctx, appCfg, telemetry, &config.Logger{})
+		require.NoError(t, err)
+	})
+
+	t.Run("errors without token url", func(t *testing.T) {
+		secretRef := oauth2("secret")
+		secretRef.OAuth2.URL = nil
+
+		telemetry := &config.Telemetry{
+			Traces: config.Trace{
+				Enabled:   true,
+				Protocol:  config.HTTPProtocol,
+				Host:      config.SourceRef{Source: config.EmbeddedSourceValue, Value: server.Listener.Addr().String()},
+				SecretRef: secretRef,
+			},
+		}
+
+		_, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+		require.ErrorIs(t, err, otlp.ErrOAuth2Token)
+	})
+}
+
+func Test_OTLP_Init_Headers(t *testing.T) {
+	received := make(chan http.Header, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case received <- r.Header.Clone():
+		default:
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv("OTLP_TENANT", "tenant-a")
+
+	telemetry := &config.Telemetry{
+		Traces: config.Trace{
+			Enabled:   true,
+			Protocol:  config.HTTPProtocol,
+			Host:      config.SourceRef{Source: config.EmbeddedSourceValue, Value: server.Listener.Addr().String()},
+			URL:       "/v1/traces",
+			SecretRef: config.SecretRef{Type: config.InsecureSecretType},
+			Headers: map[string]config.SourceRef{
+				"X-Scope-OrgID": {Source: config.EnvSourceValue, Env: "OTLP_TENANT"},
+				"X-Route":       {Source: config.EmbeddedSourceValue, Value: "eu"},
+			},
+		},
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "headers"}}
+
+	_, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+	require.NoError(t, err)
+
+	provider, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	require.True(t, ok)
+
+	_, span := otel.Tracer("test").Start(t.Context(), "operation")
+	span.End()
+
+	require.NoError(t, provider.Shutdown(t.Context()))
+
+	select {
+	case headers := <-received:
+		require.Equal(t, "tenant-a", headers.Get("X-Scope-OrgID"))
+		require.Equal(t, "eu", headers.Get("X-Route"))
+	default:
+		t.Fatal("expected an export request")
+	}
+
+	t.Run("errors on unresolvable header", func(t *testing.T) {
+		telemetry.Traces.Headers = map[string]config.SourceRef{
+			"X-Scope-OrgID": {Source: config.EnvSourceValue, Env: "OTLP_UNDEFINED_TENANT"},
+		}
+
+		_, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+		require.ErrorContains(t, err, "X-Scope-OrgID")
+	})
+}
+
+func Test_OTLP_Init_Batch(t *testing.T) {
+	var traceExports, metricExports atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/traces", func(w http.ResponseWriter, _ *http.Request) {
+		traceExports.Add(1)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/v1/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		metricExports.Add(1)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	host := config.SourceRef{Source: config.EmbeddedSourceValue, Value: server.Listener.Addr().String()}
+	insecure := config.SecretRef{Type: config.InsecureSecretType}
+	telemetry := &config.Telemetry{
+		Traces: config.Trace{
+			Enabled: true, Protocol: config.HTTPProtocol, Host: host, URL: "/v1/traces", SecretRef: insecure,
+			Batch: config.TelemetryBatch{Timeout: time.Hour, MaxQueueSize: 16, MaxExportBatchSize: 1},
+		},
+		Metrics: config.Metric{
+			Enabled: true, Protocol: config.HTTPProtocol, Host: host, URL: "/v1/metrics", SecretRef: insecure,
+			Interval: 10 * time.Millisecond,
+		},
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "batch"}}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	_, err := otlp.Init(ctx, appCfg, telemetry, &config.Logger{})
+	require.NoError(t, err)
+
+	// the metrics are exported periodically at the configured interval
+	require.Eventually(t, func() bool { return metricExports.Load() >= 2 }, time.Second, 10*time.Millisecond)
+
+	provider, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	require.True(t, ok)
+
+	for range 3 {
+		_, span := otel.Tracer("test").Start(ctx, "operation")
+		span.End()
+	}
+
+	// the batch timeout is not reached, the flush exports one span per request
+	require.Equal(t, int32(0), traceExports.Load())
+	require.NoError(t, provider.ForceFlush(ctx))
+	require.Equal(t, int32(3), traceExports.Load())
+}
+
+func Test_OTLP_Init_PrometheusAndOTLP(t *testing.T) {
+	var metricExports atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		metricExports.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tracesPath := filepath.Join(t.TempDir(), "traces.json")
+	host := config.SourceRef{Source: config.EmbeddedSourceValue, Value: server.Listener.Addr().String()}
+	insecure := config.SecretRef{Type: config.InsecureSecretType}
+	telemetry := &config.Telemetry{
+		Traces: config.Trace{
+			Enabled: true, Protocol: config.HTTPProtocol, Host: host, URL: "/v1/traces", SecretRef: insecure,
+			Exporters: []config.TelemetryExporter{{Name: "debug", Protocol: config.FileProtocol, Path: tracesPath}},
+		},
+		Metrics: config.Metric{
+			Enabled: true, Protocol: config.HTTPProtocol, Host: host, URL: "/v1/metrics", SecretRef: insecure,
+			Exporters: []config.TelemetryExporter{{Name: "scrape", Protocol: config.PrometheusProtocol}},
+		},
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "prometheus"}}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	_, err := otlp.Init(ctx, appCfg, telemetry, &config.Logger{})
+	require.NoError(t, err)
+
+	counter, err := otel.Meter("test").Int64Counter("fanout.counter")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+
+	_, span := otel.Tracer("test").Start(ctx, "fanout-operation")
+	span.End()
+
+	meterProvider, ok := otel.GetMeterProvider().(*sdkmetric.MeterProvider)
+	require.True(t, ok)
+	require.NoError(t, meterProvider.ForceFlush(ctx))
+	require.Positive(t, metricExports.Load())
+
+	families, err := prometheusclient.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	exposed := false
+	for _, family := range families {
+		exposed = exposed || family.GetName() == "fanout_counter_total"
+	}
+
+	require.True(t, exposed, "expected the counter to be exposed to Prometheus")
+
+	tracerProvider, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	require.True(t, ok)
+	require.NoError(t, tracerProvider.ForceFlush(ctx))
+
+	traces, err := os.ReadFile(tracesPath)
+	require.NoError(t, err)
+	require.Contains(t, string(traces), "fanout-operation")
+}
+
+func Test_OTLP_Init_Shutdown(t *testing.T) {
+	var exports atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		exports.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	telemetry := &config.Telemetry{
+		Traces: config.Trace{
+			Enabled:   true,
+			Protocol:  config.HTTPProtocol,
+			Host:      config.SourceRef{Source: config.EmbeddedSourceValue, Value: server.Listener.Addr().String()},
+			URL:       "/v1/traces",
+			SecretRef: config.SecretRef{Type: config.InsecureSecretType},
+			Batch:     config.TelemetryBatch{Timeout: time.Hour},
+		},
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "shutdown"}}
+	shutdownComplete := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	providers, err := otlp.Init(ctx, appCfg, telemetry, &config.Logger{}, otlp.WithShutdownComplete(shutdownComplete))
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(ctx, "operation")
+	span.End()
+
+	require.NoError(t, providers.ForceFlush(ctx))
+	require.Equal(t, int32(1), exports.Load())
+
+	_, span = otel.Tracer("test").Start(ctx, "operation")
+	span.End()
+
+	// the shutdown flushes the pending spans before the context is done
+	require.NoError(t, providers.Shutdown(ctx))
+	require.Equal(t, int32(2), exports.Load())
+
+	select {
+	case <-shutdownComplete:
+	default:
+		t.Fatal("expected the shutdown to be complete")
+	}
+
+	// only the first shutdown has an effect
+	require.NoError(t, providers.Shutdown(ctx))
+	cancel()
+
+	t.Run("without telemetry", func(t *testing.T) {
+		providers, err := otlp.Init(t.Context(), appCfg, &config.Telemetry{}, &config.Logger{})
+		require.NoError(t, err)
+		require.NoError(t, providers.ForceFlush(t.Context()))
+		require.NoError(t, providers.Shutdown(t.Context()))
+	})
+}
+
+func Test_OTLP_Init_Compression(t *testing.T) {
+	encodings := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case encodings <- r.Header.Get("Content-Encoding"):
+		default:
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	telemetry := func(compression config.Compression) *config.Telemetry {
+		return &config.Telemetry{
+			Traces: config.Trace{
+				Enabled:     true,
+				Protocol:    config.HTTPProtocol,
+				Host:        config.SourceRef{Source: config.EmbeddedSourceValue, Value: server.Listener.Addr().String()},
+				URL:         "/v1/traces",
+				SecretRef:   config.SecretRef{Type: config.InsecureSecretType},
+				Compression: compression,
+			},
+		}
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "compression"}}
+
+	tests := []struct {
+		compression config.Compression
+		encoding    string
+	}{
+		{compression: config.GzipCompression, encoding: "gzip"},
+		{compression: config.NoCompression, encoding: ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.compression), func(t *testing.T) {
+			providers, err := otlp.Init(t.Context(), appCfg, telemetry(tt.compression), &config.Logger{})
+			require.NoError(t, err)
+
+			_, span := otel.Tracer("test").Start(t.Context(), "operation")
+			span.End()
+
+			require.NoError(t, providers.Shutdown(t.Context()))
+			require.Equal(t, tt.encoding, <-encodings)
+		})
+	}
+
+	t.Run("unsupported compression", func(t *testing.T) {
+		_, err := otlp.Init(t.Context(), appCfg, telemetry("zstd"), &config.Logger{})
+		require.ErrorIs(t, err, otlp.ErrUnsupportedCompression)
+	})
+
+	t.Run("gzip over GRPC", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		cfg := telemetry(config.GzipCompression)
+		cfg.Traces.Protocol = config.GRPCProtocol
+
+		_, err := otlp.Init(ctx, appCfg, cfg, &config.Logger{})
+		require.NoError(t, err)
+	})
+}
+
+func Test_OTLP_Init_Propagators(t *testing.T) {
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "propagators"}}
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanCtx := trace.ContextWithSpanContext(t.Context(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	tests := []struct {
+		name        string
+		propagators []config.Propagator
+		headers     []string
+	}{
+		{name: "default", headers: []string{"traceparent"}},
+		{name: "b3 single", propagators: []config.Propagator{config.B3Propagator}, headers: []string{"b3"}},
+		{name: "b3 multi", propagators: []config.Propagator{config.B3MultiPropagator}, headers: []string{"x-b3-traceid", "x-b3-spanid"}},
+		{name: "jaeger", propagators: []config.Propagator{config.JaegerPropagator}, headers: []string{"uber-trace-id"}},
+		{
+			name:        "w3c and b3",
+			propagators: []config.Propagator{config.TraceContextPropagator, config.BaggagePropagator, config.B3Propagator},
+			headers:     []string{"traceparent", "b3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			telemetry := &config.Telemetry{
+				Traces:      config.Trace{Enabled: true, Protocol: config.StdoutProtocol},
+				Propagators: tt.propagators,
+			}
+
+			providers, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+			require.NoError(t, err)
+
+			defer func() { _ = providers.Shutdown(t.Context()) }()
+
+			carrier := propagation.MapCarrier{}
+			otel.GetTextMapPropagator().Inject(spanCtx, carrier)
+
+			for _, header := range tt.headers {
+				require.NotEmpty(t, carrier.Get(header), "expected header %s", header)
+			}
+
+			extracted := trace.SpanContextFromContext(otel.GetTextMapPropagator().Extract(context.Background(), carrier))
+			require.Equal(t, traceID, extracted.TraceID())
+		})
+	}
+
+	t.Run("unsupported propagator", func(t *testing.T) {
+		telemetry := &config.Telemetry{Propagators: []config.Propagator{"xray"}}
+
+		_, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+		require.ErrorIs(t, err, otlp.ErrUnsupportedPropagator)
+	})
+}
+
+func Test_OTLP_Init_ResourceAttributes(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("POD_NAME", "pod-0")
+
+	telemetry := &config.Telemetry{
+		ResourceAttributes: map[string]config.SourceRef{
+			"k8s.pod.name": {Source: config.EnvSourceValue, Env: "POD_NAME"},
+			"region":       {Source: config.EmbeddedSourceValue, Value: "eu10"},
+		},
+		Traces: config.Trace{
+			Enabled:  true,
+			Protocol: config.FileProtocol,
+			Path:     filepath.Join(dir, "traces.json"),
+			ResourceAttributes: map[string]config.SourceRef{
+				"region": {Source: config.EmbeddedSourceValue, Value: "eu20"},
+			},
+		},
+		Metrics: config.Metric{
+			Enabled:  true,
+			Protocol: config.FileProtocol,
+			Path:     filepath.Join(dir, "metrics.json"),
+		},
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "resource"}}
+
+	providers, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(t.Context(), "operation")
+	span.End()
+
+	counter, err := otel.Meter("test").Int64Counter("resource.counter")
+	require.NoError(t, err)
+	counter.Add(t.Context(), 1)
+
+	require.NoError(t, providers.Shutdown(t.Context()))
+
+	traces, err := os.ReadFile(telemetry.Traces.Path)
+	require.NoError(t, err)
+	require.Contains(t, string(traces), `{"Key":"k8s.pod.name","Value":{"Type":"STRING","Value":"pod-0"}}`)
+	require.Contains(t, string(traces), `{"Key":"region","Value":{"Type":"STRING","Value":"eu20"}}`)
+
+	metrics, err := os.ReadFile(telemetry.Metrics.Path)
+	require.NoError(t, err)
+	require.Contains(t, string(metrics), `{"Key":"k8s.pod.name","Value":{"Type":"STRING","Value":"pod-0"}}`)
+	require.Contains(t, string(metrics), `{"Key":"region","Value":{"Type":"STRING","Value":"eu10"}}`)
+
+	t.Run("errors on unresolvable attribute", func(t *testing.T) {
+		telemetry := &config.Telemetry{
+			ResourceAttributes: map[string]config.SourceRef{
+				"tenant": {Source: config.EnvSourceValue, Env: "UNDEFINED_TENANT"},
+			},
+		}
+
+		_, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+		require.ErrorContains(t, err, "tenant")
+	})
+}
+
+func Test_OTLP_Init_ProviderGetters(t *testing.T) {
+	dir := t.TempDir()
+	telemetry := &config.Telemetry{
+		Traces: config.Trace{
+			Enabled:  true,
+			Protocol: config.FileProtocol,
+			Path:     filepath.Join(dir, "traces.json"),
+		},
+		Metrics: config.Metric{
+			Enabled:  true,
+			Protocol: config.FileProtocol,
+			Path:     filepath.Join(dir, "metrics.json"),
+		},
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "getters"}}
+
+	providers, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, providers.Shutdown(t.Context())) }()
+
+	require.NotNil(t, providers.TracerProvider())
+	require.Equal(t, otel.GetTracerProvider(), providers.TracerProvider())
+	require.NotNil(t, providers.MeterProvider())
+	require.Equal(t, otel.GetMeterProvider(), providers.MeterProvider())
+	require.Nil(t, providers.LoggerProvider())
+}
+
+func Test_OTLP_Init_MetricViews(t *testing.T) {
+	telemetry := &config.Telemetry{
+		Metrics: config.Metric{
+			Enabled:  true,
+			Protocol: config.FileProtocol,
+			Path:     filepath.Join(t.TempDir(), "metrics.json"),
+			Views: []config.MetricView{
+				{Instrument: "request.duration", Buckets: []float64{0.1, 0.5, 1}, Attributes: []string{"route"}},
+				{Instrument: "internal.*", Meter: "views", Drop: true},
+				{Instrument: "legacy.counter", Name: "renamed.counter"},
+			},
+		},
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "views"}}
+
+	providers, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+	require.NoError(t, err)
+
+	meter := otel.Meter("views")
+
+	histogram, err := meter.Float64Histogram("request.duration")
+	require.NoError(t, err)
+	histogram.Record(t.Context(), 0.3, metric.WithAttributes(
+		attribute.String("route", "/keys"),
+		attribute.String("tenant", "tenant-a"),
+	))
+
+	internal, err := meter.Int64Counter("internal.counter")
+	require.NoError(t, err)
+	internal.Add(t.Context(), 1)
+
+	legacy, err := meter.Int64Counter("legacy.counter")
+	require.NoError(t, err)
+	legacy.Add(t.Context(), 1)
+
+	require.NoError(t, providers.Shutdown(t.Context()))
+
+	data, err := os.ReadFile(telemetry.Metrics.Path)
+	require.NoError(t, err)
+
+	metrics := string(data)
+	require.Contains(t, metrics, `"Bounds":[0.1,0.5,1]`)
+	// the filtered attributes are only kept on the exemplars
+	require.Contains(t, metrics, `"Attributes":[{"Key":"route","Value":{"Type":"STRING","Value":"/keys"}}],"StartTime"`)
+	require.NotContains(t, metrics, "internal.counter")
+	require.Contains(t, metrics, `"Name":"renamed.counter"`)
+	require.NotContains(t, metrics, `"Name":"legacy.counter"`)
+}
+
+func Test_OTLP_Init_Temporality(t *testing.T) {
+	dir := t.TempDir()
+	telemetry := &config.Telemetry{
+		Metrics: config.Metric{
+			Enabled:     true,
+			Protocol:    config.FileProtocol,
+			Path:        filepath.Join(dir, "cumulative.json"),
+			Temporality: config.CumulativeTemporality,
+			Exporters: []config.TelemetryExporter{
+				{
+					Name:        "delta",
+					Protocol:    config.FileProtocol,
+					Path:        filepath.Join(dir, "delta.json"),
+					Temporality: config.DeltaTemporality,
+				},
+			},
+		},
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "temporality"}}
+
+	providers, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+	require.NoError(t, err)
+
+	counter, err := otel.Meter("temporality").Int64Counter("requests")
+	require.NoError(t, err)
+
+	counter.Add(t.Context(), 1)
+	require.NoError(t, providers.ForceFlush(t.Context()))
+	counter.Add(t.Context(), 1)
+	require.NoError(t, providers.Shutdown(t.Context()))
+
+	requests := regexp.QuoteMeta(`{"Name":"requests","Description":"","Unit":"","Data":{"DataPoints":[{"Attributes":[],`)
+
+	cumulative, err := os.ReadFile(filepath.Join(dir, "cumulative.json"))
+	require.NoError(t, err)
+	require.NotContains(t, string(cumulative), "DeltaTemporality")
+	require.Regexp(t, requests+`[^{]*"Value":2,`, string(cumulative))
+
+	delta, err := os.ReadFile(filepath.Join(dir, "delta.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(delta), "DeltaTemporality")
+	require.NotRegexp(t, requests+`[^{]*"Value":2,`, string(delta))
+	require.Regexp(t, requests+`[^{]*"Value":1,`, string(delta))
+
+	t.Run("unsupported temporality", func(t *testing.T) {
+		telemetry := &config.Telemetry{
+			Metrics: config.Metric{
+				Enabled:     true,
+				Protocol:    config.StdoutProtocol,
+				Temporality: "sometimes",
+			},
+		}
+
+		_, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+		require.ErrorIs(t, err, otlp.ErrUnsupportedTemporality)
+	})
+}
+
 // generatePEMs generates cert, key, and CA in PEM format.
 func generatePEMs() ([]byte, []byte, []byte, error) {
 	var certPEM, keyPEM, caPEM []byte
@@ -490,3 +1289,66 @@ func generatePEMs() ([]byte, []byte, []byte, error) {
 
 	return certPEM, keyPEM, caPEM, nil
 }
+
+// recordingTraceService records the metadata of the received exports.
+type recordingTraceService struct {
+	coltracepb.UnimplementedTraceServiceServer
+
+	received chan metadata.MD
+}
+
+func (s recordingTraceService) Export(ctx context.Context, _ *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	select {
+	case s.received <- md:
+	default:
+	}
+
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func Test_OTLP_Init_UnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "otlp.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	service := recordingTraceService{received: make(chan metadata.MD, 1)}
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, service)
+
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	telemetry := &config.Telemetry{
+		Traces: config.Trace{
+			Enabled:  true,
+			Protocol: config.GRPCProtocol,
+			Host:     config.SourceRef{Source: config.EmbeddedSourceValue, Value: "unix://" + socket},
+			SecretRef: config.SecretRef{
+				Type:     config.ApiTokenSecretType,
+				APIToken: config.SourceRef{Source: config.EmbeddedSourceValue, Value: "token"},
+			},
+		},
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "unix-socket"}}
+
+	require.NoError(t, otlp.HealthCheck(t.Context(), telemetry))
+	require.Equal(t, []string{"Api-Token token"}, (<-service.received).Get("authorization"))
+
+	providers, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(t.Context(), "operation")
+	span.End()
+
+	require.NoError(t, providers.Shutdown(t.Context()))
+
+	select {
+	case md := <-service.received:
+		require.Equal(t, []string{"Api-Token token"}, md.Get("authorization"))
+	default:
+		t.Fatal("expected an export request")
+	}
+}
@@ -0,0 +1,191 @@
+// Package otlptest installs in-memory telemetry providers to unit test the instrumentation
+// of a service without a collector.
+//
+// The providers are set as global providers, as by otlp.Init, so the code under test
+// records its spans, metrics and logs with otel.Tracer, otel.Meter and the global logger
+// provider as in production:
+//
+//	tel := otlptest.New(t) // restores the previous global providers on cleanup
+//
+//	// ... run the code under test
+//
+//	spans := tel.Spans()
+//	assert.Equal(t, "GET /keys", spans[0].Name)
+//
+//	requests, ok := tel.Metric(t.Context(), "http.server.request.count")
+//	require.True(t, ok)
+//
+// As the providers are global, tests using otlptest must not run in parallel.
+package otlptest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Telemetry holds the in-memory providers and the telemetry recorded by them.
+// The spans and logs are recorded synchronously when they end or are emitted.
+//
+// Telemetry is safe for concurrent use.
+type Telemetry struct {
+	spans  *tracetest.InMemoryExporter
+	reader *metric.ManualReader
+	logs   *logRecorder
+
+	tracerProvider *trace.TracerProvider
+	meterProvider  *metric.MeterProvider
+	loggerProvider *log.LoggerProvider
+}
+
+// New creates the in-memory providers and sets them as global providers. The providers
+// are shut down and the previous global providers are restored on the cleanup of the test.
+func New(tb testing.TB) *Telemetry {
+	tb.Helper()
+
+	tel := &Telemetry{
+		spans:  tracetest.NewInMemoryExporter(),
+		reader: metric.NewManualReader(),
+		logs:   &logRecorder{},
+	}
+
+	tel.tracerProvider = trace.NewTracerProvider(trace.WithSyncer(tel.spans))
+	tel.meterProvider = metric.NewMeterProvider(metric.WithReader(tel.reader))
+	tel.loggerProvider = log.NewLoggerProvider(log.WithProcessor(tel.logs))
+
+	previousTracerProvider := otel.GetTracerProvider()
+	previousMeterProvider := otel.GetMeterProvider()
+	previousLoggerProvider := global.GetLoggerProvider()
+
+	otel.SetTracerProvider(tel.tracerProvider)
+	otel.SetMeterProvider(tel.meterProvider)
+	global.SetLoggerProvider(tel.loggerProvider)
+
+	tb.Cleanup(func() {
+		otel.SetTracerProvider(previousTracerProvider)
+		otel.SetMeterProvider(previousMeterProvider)
+		global.SetLoggerProvider(previousLoggerProvider)
+
+		err := errors.Join(
+			tel.tracerProvider.Shutdown(context.Background()),
+			tel.meterProvider.Shutdown(context.Background()),
+			tel.loggerProvider.Shutdown(context.Background()),
+		)
+		if err != nil {
+			tb.Errorf("failed to shut down the in-memory telemetry: %v", err)
+		}
+	})
+
+	return tel
+}
+
+// TracerProvider returns the in-memory provider of the traces.
+func (tel *Telemetry) TracerProvider() *trace.TracerProvider {
+	return tel.tracerProvider
+}
+
+// MeterProvider returns the in-memory provider of the metrics.
+func (tel *Telemetry) MeterProvider() *metric.MeterProvider {
+	return tel.meterProvider
+}
+
+// LoggerProvider returns the in-memory provider of the logs.
+func (tel *Telemetry) LoggerProvider() *log.LoggerProvider {
+	return tel.loggerProvider
+}
+
+// Spans returns the ended spans in the order they ended.
+func (tel *Telemetry) Spans() tracetest.SpanStubs {
+	return tel.spans.GetSpans()
+}
+
+// Metrics collects the current values of all metrics.
+func (tel *Telemetry) Metrics(ctx context.Context) (metricdata.ResourceMetrics, error) {
+	var rm metricdata.ResourceMetrics
+
+	err := tel.reader.Collect(ctx, &rm)
+
+	return rm, err
+}
+
+// Metric collects the current values of all metrics and returns the metric with the name.
+// It returns false if no such metric was recorded.
+func (tel *Telemetry) Metric(ctx context.Context, name string) (metricdata.Metrics, bool) {
+	rm, err := tel.Metrics(ctx)
+	if err != nil {
+		return metricdata.Metrics{}, false
+	}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m, true
+			}
+		}
+	}
+
+	return metricdata.Metrics{}, false
+}
+
+// Logs returns the emitted log records in the order they were emitted.
+func (tel *Telemetry) Logs() []log.Record {
+	return tel.logs.records()
+}
+
+// Reset removes the recorded spans and logs, e.g. between the subtests sharing the telemetry.
+// The metrics are cumulative and are not reset.
+func (tel *Telemetry) Reset() {
+	tel.spans.Reset()
+	tel.logs.reset()
+}
+
+// logRecorder is a log processor keeping the emitted records in memory.
+type logRecorder struct {
+	mu   sync.Mutex
+	logs []log.Record
+}
+
+func (r *logRecorder) OnEmit(_ context.Context, record *log.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logs = append(r.logs, record.Clone())
+
+	return nil
+}
+
+func (r *logRecorder) Enabled(context.Context, log.EnabledParameters) bool {
+	return true
+}
+
+func (r *logRecorder) Shutdown(context.Context) error {
+	return nil
+}
+
+func (r *logRecorder) ForceFlush(context.Context) error {
+	return nil
+}
+
+func (r *logRecorder) records() []log.Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.logs)
+}
+
+func (r *logRecorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logs = nil
+}
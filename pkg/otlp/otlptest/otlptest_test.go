@@ -0,0 +1,84 @@
+package otlptest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/openkcm/common-sdk/pkg/otlp/otlptest"
+)
+
+func TestTelemetryRecordsSpans(t *testing.T) {
+	tel := otlptest.New(t)
+
+	_, span := otel.Tracer("test").Start(t.Context(), "operation")
+	span.SetAttributes(attribute.String("tenant", "t1"))
+	span.End()
+
+	spans := tel.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "operation", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, attribute.String("tenant", "t1"))
+
+	tel.Reset()
+	assert.Empty(t, tel.Spans())
+}
+
+func TestTelemetryRecordsMetrics(t *testing.T) {
+	tel := otlptest.New(t)
+
+	counter, err := otel.Meter("test").Int64Counter("requests")
+	require.NoError(t, err)
+
+	counter.Add(t.Context(), 2, metric.WithAttributes(attribute.String("route", "/keys")))
+
+	requests, ok := tel.Metric(t.Context(), "requests")
+	require.True(t, ok)
+
+	sum, ok := requests.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, int64(2), sum.DataPoints[0].Value)
+
+	_, ok = tel.Metric(t.Context(), "unknown")
+	assert.False(t, ok)
+}
+
+func TestTelemetryRecordsLogs(t *testing.T) {
+	tel := otlptest.New(t)
+
+	var record log.Record
+	record.SetBody(log.StringValue("key rotated"))
+	record.SetSeverity(log.SeverityInfo)
+
+	global.GetLoggerProvider().Logger("test").Emit(t.Context(), record)
+
+	logs := tel.Logs()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "key rotated", logs[0].Body().AsString())
+	assert.Equal(t, log.SeverityInfo, logs[0].Severity())
+
+	tel.Reset()
+	assert.Empty(t, tel.Logs())
+}
+
+func TestTelemetryRestoresGlobalProviders(t *testing.T) {
+	previous := otel.GetTracerProvider()
+
+	t.Run("installs", func(t *testing.T) {
+		tel := otlptest.New(t)
+
+		assert.Equal(t, tel.TracerProvider(), otel.GetTracerProvider())
+		assert.Equal(t, tel.MeterProvider(), otel.GetMeterProvider())
+		assert.Equal(t, tel.LoggerProvider(), global.GetLoggerProvider())
+	})
+
+	assert.Equal(t, previous, otel.GetTracerProvider())
+}
@@ -0,0 +1,318 @@
+package otlp
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/commonfs/notifier"
+)
+
+// WithCredentialsReload rebuilds an exporter each time one of the files of its credentials
+// or headers changes, e.g. a rotated API token or mTLS certificate, so the service does not
+// need to be restarted. The throttleInterval is the minimum time between two rebuilds of
+// an exporter, so a certificate and key updated together cause a single rebuild.
+//
+// The credentials are only watched if they are read from files.
+func WithCredentialsReload(throttleInterval time.Duration) Option {
+	return func(reg *registry) {
+		reg.reloadCredentials = true
+		reg.reloadInterval = throttleInterval
+	}
+}
+
+// shutdowner is implemented by the exporters of all signals.
+type shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// reloadable holds the current exporter, which is replaced when the credentials change.
+// The exports hold the read lock, so an exporter is not shut down during an export.
+type reloadable[E shutdowner] struct {
+	mu      sync.RWMutex
+	current E
+}
+
+// replace sets the new exporter and shuts down the previous one.
+func (r *reloadable[E]) replace(ctx context.Context, exporter E) error {
+	r.mu.Lock()
+	previous := r.current
+	r.current = exporter
+	r.mu.Unlock()
+
+	return previous.Shutdown(ctx)
+}
+
+func (r *reloadable[E]) Shutdown(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current.Shutdown(ctx)
+}
+
+type reloadableSpanExporter struct {
+	reloadable[trace.SpanExporter]
+}
+
+func (r *reloadableSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current.ExportSpans(ctx, spans)
+}
+
+type reloadableMetricExporter struct {
+	reloadable[metric.Exporter]
+}
+
+func (r *reloadableMetricExporter) Temporality(kind metric.InstrumentKind) metricdata.Temporality {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current.Temporality(kind)
+}
+
+func (r *reloadableMetricExporter) Aggregation(kind metric.InstrumentKind) metric.Aggregation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current.Aggregation(kind)
+}
+
+func (r *reloadableMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current.Export(ctx, rm)
+}
+
+func (r *reloadableMetricExporter) ForceFlush(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current.ForceFlush(ctx)
+}
+
+type reloadableLogExporter struct {
+	reloadable[log.Exporter]
+}
+
+func (r *reloadableLogExporter) Export(ctx context.Context, records []log.Record) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current.Export(ctx, records)
+}
+
+func (r *reloadableLogExporter) ForceFlush(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current.ForceFlush(ctx)
+}
+
+// traceExporter initializes the trace exporter, which is rebuilt on credential changes if
+// the credentials reload is enabled.
+func (reg *registry) traceExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (trace.SpanExporter, error) {
+	exporter, err := initTraceExporter(ctx, cfg)
+	if err != nil || !reg.reloadCredentials {
+		return exporter, err
+	}
+
+	r := &reloadableSpanExporter{reloadable[trace.SpanExporter]{current: exporter}}
+
+	return r, reg.watchCredentials(ctx, cfg, func() error {
+		exporter, err := initTraceExporter(ctx, cfg)
+		if err != nil {
+			return err
+		}
+
+		return r.replace(ctx, exporter)
+	})
+}
+
+// metricExporter initializes the metric exporter, which is rebuilt on credential changes if
+// the credentials reload is enabled.
+func (reg *registry) metricExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (metric.Exporter, error) {
+	exporter, err := initMetricExporter(ctx, cfg)
+	if err != nil || !reg.reloadCredentials {
+		return exporter, err
+	}
+
+	r := &reloadableMetricExporter{reloadable[metric.Exporter]{current: exporter}}
+
+	return r, reg.watchCredentials(ctx, cfg, func() error {
+		exporter, err := initMetricExporter(ctx, cfg)
+		if err != nil {
+			return err
+		}
+
+		return r.replace(ctx, exporter)
+	})
+}
+
+// loggerExporter initializes the log exporter, which is rebuilt on credential changes if
+// the credentials reload is enabled.
+func (reg *registry) loggerExporter(ctx context.Context, cfg *commoncfg.TelemetryExporter) (log.Exporter, error) {
+	exporter, err := initLoggerExporter(ctx, cfg)
+	if err != nil || !reg.reloadCredentials {
+		return exporter, err
+	}
+
+	r := &reloadableLogExporter{reloadable[log.Exporter]{current: exporter}}
+
+	return r, reg.watchCredentials(ctx, cfg, func() error {
+		exporter, err := initLoggerExporter(ctx, cfg)
+		if err != nil {
+			return err
+		}
+
+		return r.replace(ctx, exporter)
+	})
+}
+
+// watchCredentials calls rebuild each time one of the credential files of the exporter
+// changes. A failed rebuild keeps the previous exporter.
+func (reg *registry) watchCredentials(ctx context.Context, cfg *commoncfg.TelemetryExporter, rebuild func() error) error {
+	paths := credentialPaths(cfg)
+	if len(paths) == 0 {
+		return nil
+	}
+
+	// the exporters are rebuilt after the Init context is done, e.g. with its values
+	ctx = context.WithoutCancel(ctx)
+
+	nt, err := notifier.Create(
+		notifier.OnPaths(paths...),
+		notifier.WithEventHandler(func(path string, _ []fsnotify.Event) {
+			err := rebuild()
+			if err != nil {
+				reg.logger.ErrorContext(ctx, "Failed to reload the credentials of the telemetry exporter",
+					slog.String("exporter", cfg.Name), slog.String("watched-path", path), slog.Any("error", err))
+
+				return
+			}
+
+			reg.logger.InfoContext(ctx, "Reloaded the credentials of the telemetry exporter",
+				slog.String("exporter", cfg.Name), slog.String("watched-path", path))
+		}),
+		notifier.WithThrottleInterval(reg.reloadInterval),
+		notifier.WithBurstNumber(0),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = nt.Start()
+	if err != nil {
+		return err
+	}
+
+	reg.notifiers = append(reg.notifiers, nt)
+
+	return nil
+}
+
+// closeNotifiers stops watching the credentials of the exporters.
+func (reg *registry) closeNotifiers() {
+	for _, nt := range reg.notifiers {
+		_ = nt.Close()
+	}
+
+	reg.notifiers = nil
+}
+
+// credentialPaths returns the directories of the credential and header files of the
+// exporter. The directories are watched instead of the files, as mounted secrets are
+// replaced by swapping a symlink.
+func credentialPaths(cfg *commoncfg.TelemetryExporter) []string {
+	refs := make([]*commoncfg.SourceRef, 0, len(cfg.Headers)+4)
+
+	for _, ref := range cfg.Headers {
+		refs = append(refs, &ref)
+	}
+
+	var caRefs []*commoncfg.SourceRef
+
+	switch cfg.SecretRef.Type {
+	case commoncfg.ApiTokenSecretType:
+		refs = append(refs, &cfg.SecretRef.APIToken)
+	case commoncfg.BasicSecretType:
+		refs = append(refs, &cfg.SecretRef.Basic.Username, &cfg.SecretRef.Basic.Password)
+	case commoncfg.MTLSSecretType:
+		refs, caRefs = appendMTLSRefs(refs, caRefs, &cfg.SecretRef.MTLS)
+	case commoncfg.OAuth2SecretType:
+		oauth2 := &cfg.SecretRef.OAuth2
+		refs = append(refs,
+			oauth2.URL,
+			&oauth2.Credentials.ClientID,
+			oauth2.Credentials.ClientSecret,
+			oauth2.Credentials.ClientAssertion,
+		)
+
+		if oauth2.MTLS != nil {
+			refs, caRefs = appendMTLSRefs(refs, caRefs, oauth2.MTLS)
+		}
+	}
+
+	pathMap := map[string]struct{}{}
+
+	for _, ref := range refs {
+		if ref != nil && ref.Source == commoncfg.FileSourceValue && strings.TrimSpace(ref.File.Path) != "" {
+			pathMap[filepath.Dir(strings.TrimSpace(ref.File.Path))] = struct{}{}
+		}
+	}
+
+	// a CA file source may reference a directory of PEM files, which is watched itself
+	for _, ref := range caRefs {
+		if ref != nil && ref.Source == commoncfg.FileSourceValue && strings.TrimSpace(ref.File.Path) != "" {
+			pathMap[caWatchPath(strings.TrimSpace(ref.File.Path))] = struct{}{}
+		}
+	}
+
+	paths := make([]string, 0, len(pathMap))
+	for path := range pathMap {
+		paths = append(paths, path)
+	}
+
+	return paths
+}
+
+func appendMTLSRefs(refs, caRefs []*commoncfg.SourceRef, mtls *commoncfg.MTLS) ([]*commoncfg.SourceRef, []*commoncfg.SourceRef) {
+	refs = append(refs, &mtls.Cert, &mtls.CertKey, mtls.PKCS12, mtls.PKCS12Passphrase)
+	caRefs = append(caRefs, mtls.ServerCA)
+
+	for i := range mtls.RootCAs {
+		caRefs = append(caRefs, &mtls.RootCAs[i])
+	}
+
+	if mtls.Revocation != nil {
+		for i := range mtls.Revocation.CRLs {
+			refs = append(refs, &mtls.Revocation.CRLs[i])
+		}
+	}
+
+	return refs, caRefs
+}
+
+// caWatchPath returns the directory to watch for a CA path, which is either a
+// bundle file or a directory of PEM files.
+func caWatchPath(caPath string) string {
+	info, err := os.Stat(caPath)
+	if err == nil && info.IsDir() {
+		return caPath
+	}
+
+	return filepath.Dir(caPath)
+}
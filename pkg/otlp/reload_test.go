@@ -0,0 +1,89 @@
+package otlp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	config "github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/otlp"
+)
+
+func Test_OTLP_Init_CredentialsReload(t *testing.T) {
+	var (
+		mu            sync.Mutex
+		authorization string
+	)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorization = r.Header.Get("Authorization")
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// the api token client sends the requests with the default transport
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+
+	t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+	lastAuthorization := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return authorization
+	}
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("first"), 0o600))
+
+	telemetry := &config.Telemetry{
+		Traces: config.Trace{
+			Enabled:  true,
+			Protocol: config.HTTPProtocol,
+			Host:     config.SourceRef{Source: config.EmbeddedSourceValue, Value: server.Listener.Addr().String()},
+			URL:      "/v1/traces",
+			SecretRef: config.SecretRef{
+				Type:     config.ApiTokenSecretType,
+				APIToken: config.SourceRef{Source: config.FileSourceValue, File: config.CredentialFile{Path: tokenPath}},
+			},
+			Batch: config.TelemetryBatch{Timeout: time.Hour},
+		},
+	}
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "reload"}}
+
+	providers, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{},
+		otlp.WithCredentialsReload(10*time.Millisecond))
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, providers.Shutdown(t.Context())) }()
+
+	export := func() {
+		_, span := otel.Tracer("test").Start(t.Context(), "operation")
+		span.End()
+
+		require.NoError(t, providers.ForceFlush(t.Context()))
+	}
+
+	export()
+	require.Contains(t, lastAuthorization(), "first")
+
+	require.NoError(t, os.WriteFile(tokenPath, []byte("second"), 0o600))
+
+	require.Eventually(t, func() bool {
+		export()
+
+		return strings.Contains(lastAuthorization(), "second")
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,93 @@
+package otlp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/openkcm/common-sdk/pkg/commoncfg"
+)
+
+// ErrMissingPath is returned by Init for an exporter with the file protocol without path.
+var ErrMissingPath = errors.New("file exporter requires a path")
+
+// exporterWriter returns the writer of the stdout and file exporters, and whether the
+// telemetry is pretty printed for a developer reading stdout. The files are appended to
+// with one JSON document per line.
+func exporterWriter(cfg *commoncfg.TelemetryExporter) (io.Writer, bool, error) {
+	if cfg.Protocol == commoncfg.StdoutProtocol {
+		return os.Stdout, true, nil
+	}
+
+	if cfg.Path == "" {
+		return nil, false, fmt.Errorf("%w: exporter %s", ErrMissingPath, cfg.Name)
+	}
+
+	file, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open telemetry file of exporter %s: %w", cfg.Name, err)
+	}
+
+	return file, false, nil
+}
+
+// initTraceWriterExporter initializes a trace exporter writing the spans to stdout or a file.
+func initTraceWriterExporter(cfg *commoncfg.TelemetryExporter) (trace.SpanExporter, error) {
+	w, pretty, err := exporterWriter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []stdouttrace.Option{stdouttrace.WithWriter(w)}
+	if pretty {
+		options = append(options, stdouttrace.WithPrettyPrint())
+	}
+
+	return stdouttrace.New(options...)
+}
+
+// initMetricWriterExporter initializes a metric exporter writing the metrics to stdout or a file.
+func initMetricWriterExporter(cfg *commoncfg.TelemetryExporter) (metric.Exporter, error) {
+	w, pretty, err := exporterWriter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	temporality, err := temporalitySelector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []stdoutmetric.Option{
+		stdoutmetric.WithWriter(w),
+		stdoutmetric.WithTemporalitySelector(temporality),
+	}
+	if pretty {
+		options = append(options, stdoutmetric.WithPrettyPrint())
+	}
+
+	return stdoutmetric.New(options...)
+}
+
+// initLoggerWriterExporter initializes a log exporter writing the records to stdout or a file.
+func initLoggerWriterExporter(cfg *commoncfg.TelemetryExporter) (log.Exporter, error) {
+	w, pretty, err := exporterWriter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []stdoutlog.Option{stdoutlog.WithWriter(w)}
+	if pretty {
+		options = append(options, stdoutlog.WithPrettyPrint())
+	}
+
+	return stdoutlog.New(options...)
+}
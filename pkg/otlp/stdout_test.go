@@ -0,0 +1,84 @@
+package otlp_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	config "github.com/openkcm/common-sdk/pkg/commoncfg"
+	"github.com/openkcm/common-sdk/pkg/otlp"
+)
+
+func Test_OTLP_Init_File(t *testing.T) {
+	dir := t.TempDir()
+	appCfg := &config.Application{Name: "test-service", Instance: config.Instance{ID: "file"}}
+	telemetry := &config.Telemetry{
+		Traces: config.Trace{
+			Enabled:  true,
+			Protocol: config.FileProtocol,
+			Path:     filepath.Join(dir, "traces.json"),
+		},
+		Metrics: config.Metric{
+			Enabled:  true,
+			Protocol: config.FileProtocol,
+			Path:     filepath.Join(dir, "metrics.json"),
+		},
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	_, err := otlp.Init(ctx, appCfg, telemetry, &config.Logger{})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(ctx, "file-operation")
+	span.End()
+
+	counter, err := otel.Meter("test").Int64Counter("file.counter")
+	require.NoError(t, err)
+	counter.Add(ctx, 1)
+
+	tracerProvider, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	require.True(t, ok)
+	require.NoError(t, tracerProvider.ForceFlush(ctx))
+
+	meterProvider, ok := otel.GetMeterProvider().(*sdkmetric.MeterProvider)
+	require.True(t, ok)
+	require.NoError(t, meterProvider.ForceFlush(ctx))
+
+	traces, err := os.ReadFile(telemetry.Traces.Path)
+	require.NoError(t, err)
+	require.Contains(t, string(traces), `"Name":"file-operation"`)
+
+	metrics, err := os.ReadFile(telemetry.Metrics.Path)
+	require.NoError(t, err)
+	require.Contains(t, string(metrics), `"Name":"file.counter"`)
+
+	t.Run("errors without path", func(t *testing.T) {
+		telemetry := &config.Telemetry{
+			Logs: config.Log{Enabled: true, Protocol: config.FileProtocol},
+		}
+
+		_, err := otlp.Init(t.Context(), appCfg, telemetry, &config.Logger{})
+		require.ErrorIs(t, err, otlp.ErrMissingPath)
+	})
+
+	t.Run("writes to stdout", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		telemetry := &config.Telemetry{
+			Logs: config.Log{Enabled: true, Protocol: config.StdoutProtocol},
+		}
+
+		_, err := otlp.Init(ctx, appCfg, telemetry, &config.Logger{})
+		require.NoError(t, err)
+	})
+}
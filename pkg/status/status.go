@@ -24,7 +24,8 @@ const (
 )
 
 type probesConfig struct {
-	handlers map[string]func(http.ResponseWriter, *http.Request)
+	handlers  map[string]func(http.ResponseWriter, *http.Request)
+	endpoints map[string]http.Handler
 }
 
 // ProbeOption configures a [Handler].
@@ -56,6 +57,18 @@ func WithCustom(name string, handler func(http.ResponseWriter, *http.Request)) P
 	}
 }
 
+// WithEndpoint registers an additional handler on the status server under the given path,
+// e.g. debugging endpoints such as the gRPC debug capture (see commongrpc.DebugCapture).
+func WithEndpoint(path string, handler http.Handler) ProbeOption {
+	return func(c *probesConfig) {
+		if handler == nil {
+			return
+		}
+
+		c.endpoints[path] = handler
+	}
+}
+
 // versionHandlerFunc returns a handler function that writes the version information
 // to the response. This is used in the status server.
 func versionHandlerFunc(cfg *commoncfg.Application) func(w http.ResponseWriter, r *http.Request) {
@@ -80,6 +93,7 @@ func versionHandlerFunc(cfg *commoncfg.Application) func(w http.ResponseWriter,
 func registerDefaultHandlers(cfg *commoncfg.BaseConfig,
 	mux *http.ServeMux,
 	probeHandlers map[string]func(http.ResponseWriter, *http.Request),
+	endpoints map[string]http.Handler,
 ) {
 	if cfg.Telemetry.Metrics.Prometheus.Enabled {
 		mux.Handle("/metrics", promhttp.Handler())
@@ -94,15 +108,19 @@ func registerDefaultHandlers(cfg *commoncfg.BaseConfig,
 	for name, fn := range probeHandlers {
 		mux.HandleFunc("/probe/"+name, fn)
 	}
+
+	for path, handler := range endpoints {
+		mux.Handle(path, handler)
+	}
 }
 
 // createStatusServer creates a status http server using the given probesConfig
 func createStatusServer(ctx context.Context,
 	cfg *commoncfg.BaseConfig,
 	mux *http.ServeMux,
-	probeHandlers map[string]func(http.ResponseWriter, *http.Request),
+	prCfg *probesConfig,
 ) *http.Server {
-	registerDefaultHandlers(cfg, mux, probeHandlers)
+	registerDefaultHandlers(cfg, mux, prCfg.handlers, prCfg.endpoints)
 
 	slogctx.Info(ctx, "Creating status server", "address", cfg.Status.Address)
 
@@ -122,7 +140,8 @@ func Start(ctx context.Context, cfg *commoncfg.BaseConfig, probes ...ProbeOption
 	mux := http.NewServeMux()
 
 	prCfg := &probesConfig{
-		handlers: make(map[string]func(http.ResponseWriter, *http.Request)),
+		handlers:  make(map[string]func(http.ResponseWriter, *http.Request)),
+		endpoints: make(map[string]http.Handler),
 	}
 
 	for _, pr := range probes {
@@ -131,7 +150,7 @@ func Start(ctx context.Context, cfg *commoncfg.BaseConfig, probes ...ProbeOption
 		}
 	}
 
-	server := createStatusServer(ctx, cfg, mux, prCfg.handlers)
+	server := createStatusServer(ctx, cfg, mux, prCfg)
 
 	var lc net.ListenConfig
 
@@ -184,7 +203,7 @@ func Start(ctx context.Context, cfg *commoncfg.BaseConfig, probes ...ProbeOption
 // Behavior:
 //  1. Constructs a liveness handler using disabled autostart semantics.
 //  2. Builds a readiness handler composed of the provided health options, timeout,
-//     and a status-logging listener.
+//     build information (see health.WithBuildInfo) and a status-logging listener.
 //  3. Delegates to Start(...) to launch the health server with both handlers.
 //  4. Returns an error if the server startup fails.
 //
@@ -202,10 +221,11 @@ func Serve(ctx context.Context, baseConfig *commoncfg.BaseConfig, ops ...health.
 		),
 	)
 
-	healthOptions := make([]health.Option, 0, len(ops)+3)
+	healthOptions := make([]health.Option, 0, len(ops)+4)
 	healthOptions = append(healthOptions,
 		health.WithDisabledAutostart(),
 		health.WithTimeout(baseConfig.Status.Timeout),
+		health.WithBuildInfo(baseConfig),
 		health.WithStatusListener(func(ctx context.Context, state health.State) {
 			subctx := slogctx.With(ctx, "status", state.Status)
 			//nolint:fatcontext
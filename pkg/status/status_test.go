@@ -76,6 +76,8 @@ func TestStart(t *testing.T) {
 		status.WithLiveness(fn),
 		status.WithHealthZ(fn),
 		status.WithReadiness(fn),
+		status.WithEndpoint("/debug/nil", nil),
+		status.WithEndpoint("/debug/capture", http.HandlerFunc(fn)),
 	}
 
 	// Act
@@ -118,6 +120,7 @@ func TestStart(t *testing.T) {
 		"/probe/healthz",
 		"/probe/readiness",
 		"/version",
+		"/debug/capture",
 	} {
 		resp, err := http.Get("http://localhost:8080" + endpoint)
 		if err != nil {
@@ -0,0 +1,403 @@
+package keyvalue
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+const (
+	// LayerHitsMetric counts the reads answered by a layer.
+	LayerHitsMetric = "keyvalue.layer.hits"
+	// LayerMissesMetric counts the reads a layer could not answer.
+	LayerMissesMetric = "keyvalue.layer.misses"
+	// LayerWritesMetric counts the values stored into a layer, including backfills.
+	LayerWritesMetric = "keyvalue.layer.writes"
+
+	layeredStorageInstrumentID = "github.com/openkcm/common-sdk/pkg/storage/keyvalue"
+	layerAttribute             = "keyvalue.layer"
+)
+
+// Consistency defines how writes are propagated through the layers of a LayeredStorage.
+type Consistency int
+
+const (
+	// WriteThrough stores values into all writable layers before Store returns.
+	WriteThrough Consistency = iota
+	// WriteBack stores values into the first writable layer before Store returns
+	// and propagates them to the remaining layers in the background, in the order
+	// of the Store calls. Use Flush to wait for the pending propagations.
+	WriteBack
+)
+
+// LayerStats holds the counters of a single layer of a LayeredStorage.
+type LayerStats struct {
+	Name    string
+	Hits    int64
+	Misses  int64
+	Writes  int64
+	Removes int64
+}
+
+// LayeredStorage is a composite key–value store made of ordered layers, e.g. an
+// in-memory cache over a shared cache (Redis) over a persistent store (disk).
+//
+// Reads fall through the layers, starting with the first one, until a layer holds
+// the key. Unless disabled with WithoutBackfill, the value found is then stored
+// into the writable layers above it, so later reads are answered by the faster
+// layers. Writes are propagated to all writable layers according to the configured
+// Consistency; removals and cleanups are always applied to all writable layers
+// synchronously so stale values cannot resurface from a lower layer.
+//
+// LayeredStorage is safe for concurrent use as long as its layers are.
+type LayeredStorage[K comparable, V any] struct {
+	layers      []*layer[K, V]
+	consistency Consistency
+	backfill    bool
+	meter       metric.MeterProvider
+
+	// mu guards the write-back queue, which is propagated by a single worker
+	// running while the queue is not empty.
+	mu       sync.Mutex
+	drained  *sync.Cond // signalled when inFlight drops to zero
+	queue    []writeBack[K, V]
+	inFlight int // queued writes plus the write being propagated
+	running  bool
+}
+
+// writeBack is a value waiting to be propagated to the lower layers.
+type writeBack[K comparable, V any] struct {
+	key    K
+	value  V
+	layers []*layer[K, V]
+}
+
+// layer is a single named layer with its counters and metric instruments.
+type layer[K comparable, V any] struct {
+	name    string
+	read    ReadStorage[K, V]
+	write   Storage[K, V] // nil for read-only layers
+	attrs   metric.MeasurementOption
+	hits    atomic.Int64
+	misses  atomic.Int64
+	writes  atomic.Int64
+	removes atomic.Int64
+
+	hitCounter   metric.Int64Counter
+	missCounter  metric.Int64Counter
+	writeCounter metric.Int64Counter
+}
+
+// LayeredOption configures a LayeredStorage.
+type LayeredOption[K comparable, V any] func(*LayeredStorage[K, V])
+
+// WithLayer appends a writable layer. Layers are consulted in the order they are added,
+// so the fastest layer should be added first.
+func WithLayer[K comparable, V any](name string, storage Storage[K, V]) LayeredOption[K, V] {
+	return func(s *LayeredStorage[K, V]) {
+		if storage == nil {
+			return
+		}
+
+		s.layers = append(s.layers, &layer[K, V]{name: name, read: storage, write: storage})
+	}
+}
+
+// WithReadOnlyLayer appends a layer which is only read from, e.g. a snapshot
+// provisioned by another process. Values are neither stored nor removed from it.
+func WithReadOnlyLayer[K comparable, V any](name string, storage ReadStorage[K, V]) LayeredOption[K, V] {
+	return func(s *LayeredStorage[K, V]) {
+		if storage == nil {
+			return
+		}
+
+		s.layers = append(s.layers, &layer[K, V]{name: name, read: storage})
+	}
+}
+
+// WithConsistency sets how writes are propagated. Defaults to WriteThrough.
+func WithConsistency[K comparable, V any](consistency Consistency) LayeredOption[K, V] {
+	return func(s *LayeredStorage[K, V]) {
+		s.consistency = consistency
+	}
+}
+
+// WithoutBackfill disables storing values found in a lower layer into the layers above it.
+func WithoutBackfill[K comparable, V any]() LayeredOption[K, V] {
+	return func(s *LayeredStorage[K, V]) {
+		s.backfill = false
+	}
+}
+
+// WithMeterProvider sets the meter provider used for the per layer metrics.
+// Defaults to the global meter provider.
+func WithMeterProvider[K comparable, V any](provider metric.MeterProvider) LayeredOption[K, V] {
+	return func(s *LayeredStorage[K, V]) {
+		if provider != nil {
+			s.meter = provider
+		}
+	}
+}
+
+// NewLayeredStorage creates a LayeredStorage from the given options.
+//
+// Example:
+//
+//	storage := keyvalue.NewLayeredStorage(
+//	    keyvalue.WithLayer[string, []byte]("memory", keyvalue.NewMemoryStorage[string, []byte]()),
+//	    keyvalue.WithLayer[string, []byte]("redis", redisStorage),
+//	    keyvalue.WithLayer[string, []byte]("disk", diskStorage),
+//	)
+func NewLayeredStorage[K comparable, V any](opts ...LayeredOption[K, V]) *LayeredStorage[K, V] {
+	s := &LayeredStorage[K, V]{
+		consistency: WriteThrough,
+		backfill:    true,
+		meter:       otel.GetMeterProvider(),
+	}
+	s.drained = sync.NewCond(&s.mu)
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+
+	meter := s.meter.Meter(layeredStorageInstrumentID)
+	hits := layerCounter(meter, LayerHitsMetric, "Number of reads answered by the storage layer")
+	misses := layerCounter(meter, LayerMissesMetric, "Number of reads not answered by the storage layer")
+	writes := layerCounter(meter, LayerWritesMetric, "Number of values stored into the storage layer")
+
+	for _, l := range s.layers {
+		l.attrs = metric.WithAttributes(attribute.String(layerAttribute, l.name))
+		l.hitCounter = hits
+		l.missCounter = misses
+		l.writeCounter = writes
+	}
+
+	return s
+}
+
+// Get retrieves the value from the first layer holding the key and backfills
+// the writable layers above it.
+func (s *LayeredStorage[K, V]) Get(key K) (V, bool) {
+	for i, l := range s.layers {
+		value, ok := l.read.Get(key)
+		if !ok {
+			l.recordMiss()
+			continue
+		}
+
+		l.recordHit()
+
+		if s.backfill {
+			for _, upper := range s.layers[:i] {
+				upper.store(key, value)
+			}
+		}
+
+		return value, true
+	}
+
+	var zero V
+
+	return zero, false
+}
+
+// Store inserts or updates the value in the writable layers according to the configured Consistency.
+func (s *LayeredStorage[K, V]) Store(key K, value V) {
+	writable := s.writableLayers()
+	if len(writable) == 0 {
+		return
+	}
+
+	if s.consistency != WriteBack {
+		for _, l := range writable {
+			l.store(key, value)
+		}
+
+		return
+	}
+
+	// the first layer is written under the lock, so the lower layers receive the
+	// values in the same order
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writable[0].store(key, value)
+
+	if len(writable) == 1 {
+		return
+	}
+
+	s.queue = append(s.queue, writeBack[K, V]{key: key, value: value, layers: writable[1:]})
+	s.inFlight++
+
+	if !s.running {
+		s.running = true
+
+		go s.propagate()
+	}
+}
+
+// propagate stores the queued values into the lower layers until the queue is empty.
+func (s *LayeredStorage[K, V]) propagate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.queue) > 0 {
+		w := s.queue[0]
+		s.queue[0] = writeBack[K, V]{}
+		s.queue = s.queue[1:]
+
+		s.mu.Unlock()
+
+		for _, l := range w.layers {
+			l.store(w.key, w.value)
+		}
+
+		s.mu.Lock()
+
+		s.inFlight--
+	}
+
+	s.queue = nil
+	s.running = false
+	s.drained.Broadcast()
+}
+
+// Remove deletes the key from all writable layers and reports whether any layer held it.
+func (s *LayeredStorage[K, V]) Remove(key K) bool {
+	s.Flush()
+
+	removed := false
+
+	for _, l := range s.writableLayers() {
+		if l.write.Remove(key) {
+			l.removes.Add(1)
+
+			removed = true
+		}
+	}
+
+	return removed
+}
+
+// Clean removes all entries from the writable layers and reports whether any layer had data.
+func (s *LayeredStorage[K, V]) Clean() bool {
+	s.Flush()
+
+	cleaned := false
+	for _, l := range s.writableLayers() {
+		cleaned = l.write.Clean() || cleaned
+	}
+
+	return cleaned
+}
+
+// IsEmpty reports whether none of the layers contains entries.
+func (s *LayeredStorage[K, V]) IsEmpty() bool {
+	for _, l := range s.layers {
+		if !l.read.IsEmpty() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// List returns the distinct keys of all layers.
+func (s *LayeredStorage[K, V]) List() []K {
+	seen := make(map[K]struct{})
+	keys := make([]K, 0)
+
+	for _, l := range s.layers {
+		for _, k := range l.read.List() {
+			if _, ok := seen[k]; ok {
+				continue
+			}
+
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
+// Flush waits until all values stored with WriteBack consistency have been propagated.
+func (s *LayeredStorage[K, V]) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.inFlight > 0 {
+		s.drained.Wait()
+	}
+}
+
+// Stats returns the counters of every layer, in layer order.
+func (s *LayeredStorage[K, V]) Stats() []LayerStats {
+	stats := make([]LayerStats, 0, len(s.layers))
+	for _, l := range s.layers {
+		stats = append(stats, LayerStats{
+			Name:    l.name,
+			Hits:    l.hits.Load(),
+			Misses:  l.misses.Load(),
+			Writes:  l.writes.Load(),
+			Removes: l.removes.Load(),
+		})
+	}
+
+	return stats
+}
+
+// AsReadStorage exposes this LayeredStorage as a read-only storage interface.
+func (s *LayeredStorage[K, V]) AsReadStorage() ReadStorage[K, V] {
+	return s
+}
+
+func (s *LayeredStorage[K, V]) writableLayers() []*layer[K, V] {
+	writable := make([]*layer[K, V], 0, len(s.layers))
+	for _, l := range s.layers {
+		if l.write != nil {
+			writable = append(writable, l)
+		}
+	}
+
+	return writable
+}
+
+// layerCounter creates the counter, falling back to a no-op counter so
+// a misbehaving meter provider never breaks the storage.
+func layerCounter(meter metric.Meter, name, description string) metric.Int64Counter {
+	counter, err := meter.Int64Counter(name, metric.WithDescription(description))
+	if err != nil {
+		return noop.Int64Counter{}
+	}
+
+	return counter
+}
+
+func (l *layer[K, V]) store(key K, value V) {
+	if l.write == nil {
+		return
+	}
+
+	l.write.Store(key, value)
+	l.writes.Add(1)
+	l.writeCounter.Add(context.Background(), 1, l.attrs)
+}
+
+func (l *layer[K, V]) recordHit() {
+	l.hits.Add(1)
+	l.hitCounter.Add(context.Background(), 1, l.attrs)
+}
+
+func (l *layer[K, V]) recordMiss() {
+	l.misses.Add(1)
+	l.missCounter.Add(context.Background(), 1, l.attrs)
+}
@@ -0,0 +1,217 @@
+package keyvalue_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+
+	"github.com/openkcm/common-sdk/pkg/storage/keyvalue"
+)
+
+type layers struct {
+	memory *keyvalue.MemoryStorage[string, []byte]
+	redis  *keyvalue.MemoryStorage[string, []byte]
+	disk   *keyvalue.MemoryStorage[string, []byte]
+}
+
+func newLayered(opts ...keyvalue.LayeredOption[string, []byte]) (*keyvalue.LayeredStorage[string, []byte], layers) {
+	l := layers{
+		memory: keyvalue.NewMemoryStorage[string, []byte](),
+		redis:  keyvalue.NewMemoryStorage[string, []byte](),
+		disk:   keyvalue.NewMemoryStorage[string, []byte](),
+	}
+
+	all := append([]keyvalue.LayeredOption[string, []byte]{
+		keyvalue.WithLayer[string, []byte]("memory", l.memory),
+		keyvalue.WithLayer[string, []byte]("redis", l.redis),
+		keyvalue.WithLayer[string, []byte]("disk", l.disk),
+	}, opts...)
+
+	return keyvalue.NewLayeredStorage(all...), l
+}
+
+func TestLayeredStorageGetFallsThroughAndBackfills(t *testing.T) {
+	st, l := newLayered()
+	l.disk.Store("foo", []byte("bar"))
+
+	val, ok := st.Get("foo")
+	require.True(t, ok)
+	assert.Equal(t, []byte("bar"), val)
+
+	val, ok = l.memory.Get("foo")
+	require.True(t, ok)
+	assert.Equal(t, []byte("bar"), val)
+
+	_, ok = l.redis.Get("foo")
+	require.True(t, ok)
+
+	// the second read is answered by the first layer
+	_, ok = st.Get("foo")
+	require.True(t, ok)
+
+	_, ok = st.Get("missing")
+	require.False(t, ok)
+
+	assert.Equal(t, []keyvalue.LayerStats{
+		{Name: "memory", Hits: 1, Misses: 2, Writes: 1},
+		{Name: "redis", Misses: 2, Writes: 1},
+		{Name: "disk", Hits: 1, Misses: 1},
+	}, st.Stats())
+}
+
+func TestLayeredStorageWithoutBackfill(t *testing.T) {
+	st, l := newLayered(keyvalue.WithoutBackfill[string, []byte]())
+	l.disk.Store("foo", []byte("bar"))
+
+	_, ok := st.Get("foo")
+	require.True(t, ok)
+	assert.True(t, l.memory.IsEmpty())
+	assert.True(t, l.redis.IsEmpty())
+}
+
+func TestLayeredStorageStore(t *testing.T) {
+	for _, consistency := range []keyvalue.Consistency{keyvalue.WriteThrough, keyvalue.WriteBack} {
+		st, l := newLayered(keyvalue.WithConsistency[string, []byte](consistency))
+
+		st.Store("foo", []byte("bar"))
+
+		_, ok := l.memory.Get("foo")
+		require.True(t, ok)
+
+		st.Flush()
+
+		for _, layer := range []*keyvalue.MemoryStorage[string, []byte]{l.redis, l.disk} {
+			val, ok := layer.Get("foo")
+			require.True(t, ok)
+			assert.Equal(t, []byte("bar"), val)
+		}
+	}
+}
+
+func TestLayeredStorageRemoveAndClean(t *testing.T) {
+	st, l := newLayered(keyvalue.WithConsistency[string, []byte](keyvalue.WriteBack))
+
+	require.False(t, st.Remove("foo"))
+	require.False(t, st.Clean())
+	require.True(t, st.IsEmpty())
+
+	st.Store("foo", []byte("bar"))
+	st.Store("baz", []byte("qux"))
+	l.disk.Store("disk-only", []byte("x"))
+
+	assert.ElementsMatch(t, []string{"foo", "baz", "disk-only"}, st.List())
+
+	require.True(t, st.Remove("foo"))
+
+	_, ok := st.Get("foo")
+	require.False(t, ok)
+
+	require.True(t, st.Clean())
+	require.True(t, st.IsEmpty())
+	assert.True(t, l.disk.IsEmpty())
+}
+
+func TestLayeredStorageWriteBackConcurrent(t *testing.T) {
+	st, l := newLayered(keyvalue.WithConsistency[string, []byte](keyvalue.WriteBack))
+
+	var wg sync.WaitGroup
+
+	for i := range 8 {
+		wg.Go(func() {
+			key := fmt.Sprintf("key-%d", i)
+
+			for j := range 200 {
+				st.Store(key, []byte(strconv.Itoa(j)))
+
+				switch j % 20 {
+				case 5:
+					st.Remove(key)
+				case 10:
+					st.Flush()
+				}
+			}
+		})
+	}
+
+	wg.Wait()
+	st.Flush()
+
+	// the last value of each key reaches the lower layers, regardless of earlier values in flight
+	for i := range 8 {
+		key := fmt.Sprintf("key-%d", i)
+
+		for _, layer := range []*keyvalue.MemoryStorage[string, []byte]{l.memory, l.redis, l.disk} {
+			val, ok := layer.Get(key)
+			require.True(t, ok)
+			assert.Equal(t, []byte("199"), val)
+		}
+	}
+}
+
+func TestLayeredStorageReadOnlyLayer(t *testing.T) {
+	snapshot := keyvalue.NewMemoryStorage[string, []byte]()
+	snapshot.Store("foo", []byte("bar"))
+
+	memory := keyvalue.NewMemoryStorage[string, []byte]()
+	st := keyvalue.NewLayeredStorage(
+		keyvalue.WithLayer[string, []byte]("memory", memory),
+		keyvalue.WithReadOnlyLayer("snapshot", snapshot.AsReadStorage()),
+	)
+
+	st.Store("new", []byte("value"))
+	_, ok := snapshot.Get("new")
+	require.False(t, ok)
+
+	_, ok = st.Get("foo")
+	require.True(t, ok)
+
+	_, ok = memory.Get("foo")
+	require.True(t, ok)
+
+	require.True(t, st.Clean())
+	require.False(t, st.IsEmpty())
+
+	val, ok := st.Get("foo")
+	require.True(t, ok)
+	assert.Equal(t, []byte("bar"), val)
+}
+
+func TestLayeredStorageMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	st, _ := newLayered(keyvalue.WithMeterProvider[string, []byte](provider))
+	st.Store("foo", []byte("bar"))
+	st.Get("foo")
+	st.Get("missing")
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	got := map[string]map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			require.True(t, ok)
+
+			got[m.Name] = map[string]int64{}
+			for _, dp := range sum.DataPoints {
+				layer, _ := dp.Attributes.Value(attribute.Key("keyvalue.layer"))
+				got[m.Name][layer.AsString()] = dp.Value
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]int64{"memory": 1}, got[keyvalue.LayerHitsMetric])
+	assert.Equal(t, map[string]int64{"memory": 1, "redis": 1, "disk": 1}, got[keyvalue.LayerMissesMetric])
+	assert.Equal(t, map[string]int64{"memory": 1, "redis": 1, "disk": 1}, got[keyvalue.LayerWritesMetric])
+}